@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path"
 	"runtime"
@@ -15,6 +16,7 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/agent/server/modes/host/command"
 	"github.com/shellhub-io/shellhub/pkg/envs"
 	"github.com/shellhub-io/shellhub/pkg/loglevel"
+	"github.com/shellhub-io/shellhub/pkg/version"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -231,12 +233,34 @@ func main() {
 			logger.Info("Starting ShellHub Agent Connector")
 
 			connector.ConnectorVersion = AgentVersion
-			connector, err := connector.NewDockerConnector(cfg.ServerAddress, cfg.TenantID, cfg.PrivateKeys)
+			if version.Version == "" {
+				version.Version = AgentVersion
+			}
+			conn, err := connector.NewDockerConnector(cfg)
 			if err != nil {
-				logger.Fatal("Failed to create ShellHub Agent Connector")
+				logger.WithError(err).Fatal("Failed to create ShellHub Agent Connector")
 			}
 
-			if err := connector.Listen(cmd.Context()); err != nil {
+			if cfg.HTTPAddress != "" {
+				handler := connector.NewHealthHandler(conn, cfg.HealthPath, cfg.MetricsPath, cfg.EnableToken)
+
+				go func() {
+					if err := http.ListenAndServe(cfg.HTTPAddress, handler); err != nil { //nolint:gosec
+						logger.WithError(err).Error("Failed to serve the ShellHub Agent Connector health")
+					}
+				}()
+			}
+
+			if cfg.LockRedisURI != "" {
+				lock, err := connector.NewRedisLock(cfg.LockRedisURI, cfg.TenantID)
+				if err != nil {
+					logger.WithError(err).Fatal("Failed to create the lock of the ShellHub Agent Connector")
+				}
+
+				if err := connector.RunLocked(cmd.Context(), conn, lock, cfg.LockTTL); err != nil {
+					logger.Fatal("Failed to listen for connections")
+				}
+			} else if err := conn.Listen(cmd.Context()); err != nil {
 				logger.Fatal("Failed to listen for connections")
 			}
 
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	// NOTICE: the production image has no timezone database, so it is embedded to resolve users' timezones.
+	_ "time/tzdata"
 
 	"github.com/shellhub-io/shellhub/pkg/envs"
 	"github.com/shellhub-io/shellhub/pkg/loglevel"
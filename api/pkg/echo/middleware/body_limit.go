@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BodyLimit refuses, with [http.StatusRequestEntityTooLarge], the requests whose body is larger than limit bytes. As
+// the body is only read up to the limit, the requests whose size isn't known in advance, like the chunked ones, are
+// also refused once they exceed it, without being held in memory. When limit is not greater than zero, the body isn't
+// limited.
+func BodyLimit(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if limit <= 0 {
+				return next(c)
+			}
+
+			req := c.Request()
+			if req.ContentLength > limit {
+				return c.NoContent(http.StatusRequestEntityTooLarge)
+			}
+
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+
+			err := next(c)
+
+			var exceeded *http.MaxBytesError
+			if errors.As(err, &exceeded) && !c.Response().Committed {
+				return c.NoContent(http.StatusRequestEntityTooLarge)
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	cases := []struct {
+		description string
+		limit       int64
+		body        string
+		chunked     bool
+		expected    int
+	}{
+		{
+			description: "succeeds when the body is within the limit",
+			limit:       8,
+			body:        "12345678",
+			expected:    http.StatusOK,
+		},
+		{
+			description: "succeeds when the body isn't limited",
+			limit:       0,
+			body:        strings.Repeat("1", 1024),
+			expected:    http.StatusOK,
+		},
+		{
+			description: "fails when the content length exceeds the limit",
+			limit:       8,
+			body:        "123456789",
+			expected:    http.StatusRequestEntityTooLarge,
+		},
+		{
+			description: "fails when the chunked body exceeds the limit",
+			limit:       8,
+			body:        "123456789",
+			chunked:     true,
+			expected:    http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			e := echo.New()
+			e.POST("/", func(c echo.Context) error {
+				if _, err := io.ReadAll(c.Request().Body); err != nil {
+					return err
+				}
+
+				return c.NoContent(http.StatusOK)
+			}, BodyLimit(tc.limit))
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			if tc.chunked {
+				req.ContentLength = -1
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+const (
+	// RateLimitLimitHeader is the header with the maximum number of requests the namespace can make at once.
+	RateLimitLimitHeader = "X-RateLimit-Limit"
+	// RateLimitRemainingHeader is the header with the number of requests the namespace can still make at once.
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	// RateLimitResetHeader is the header with the Unix timestamp, in seconds, when the namespace's limit will be fully
+	// available again.
+	RateLimitResetHeader = "X-RateLimit-Reset"
+)
+
+// RateLimiter evaluates the API requests made on behalf of a namespace against its rate limit.
+type RateLimiter interface {
+	// EvaluateAPIRateLimit returns the outcome of evaluating a request made on behalf of the namespace with the
+	// specified tenant ID, or nil when its requests aren't rate limited.
+	EvaluateAPIRateLimit(ctx context.Context, tenantID string) (*models.APIRateLimitStatus, error)
+}
+
+// RateLimit blocks the API requests made on behalf of a namespace, identified by the tenant set by the gateway from the
+// JWT or the API key, that exceed its rate limit, responding with the limit's status on the X-RateLimit headers.
+// Internal requests, as the ones without a namespace, are not limited. Neither are the requests the gateway didn't
+// authenticate, as the X-ID nor the X-Api-Key headers were set, since their X-Tenant-ID may come from the client and
+// would allow draining the bucket of any namespace.
+//
+// When the rate limit cannot be evaluated, the request is let through, as the API must not be unavailable because of
+// the rate limiting itself.
+func RateLimit(limiter RateLimiter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			tenant := req.Header.Get("X-Tenant-ID")
+			if tenant == "" || strings.HasPrefix(req.URL.Path, "/internal") {
+				return next(c)
+			}
+
+			if req.Header.Get("X-ID") == "" && req.Header.Get("X-Api-Key") == "" {
+				return next(c)
+			}
+
+			status, err := limiter.EvaluateAPIRateLimit(req.Context(), tenant)
+			if err != nil {
+				logger.FromContext(req.Context()).
+					WithError(err).
+					WithField("tenant_id", tenant).
+					Warn("unable to evaluate the API rate limit")
+
+				return next(c)
+			}
+
+			if status == nil {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(RateLimitLimitHeader, strconv.Itoa(status.Limit))
+			header.Set(RateLimitRemainingHeader, strconv.Itoa(status.Remaining))
+			header.Set(RateLimitResetHeader, strconv.FormatInt(status.Reset.Unix(), 10))
+
+			if !status.Allowed {
+				return c.NoContent(http.StatusTooManyRequests)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type rateLimiterFunc func(ctx context.Context, tenantID string) (*models.APIRateLimitStatus, error)
+
+func (f rateLimiterFunc) EvaluateAPIRateLimit(ctx context.Context, tenantID string) (*models.APIRateLimitStatus, error) {
+	return f(ctx, tenantID)
+}
+
+func TestRateLimit(t *testing.T) {
+	reset := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := func(status *models.APIRateLimitStatus, err error) rateLimiterFunc {
+		return func(_ context.Context, tenantID string) (*models.APIRateLimitStatus, error) {
+			if tenantID != "00000000-0000-4000-0000-000000000000" {
+				return nil, errors.New("unexpected tenant")
+			}
+
+			return status, err
+		}
+	}
+
+	type Expected struct {
+		status  int
+		headers map[string]string
+	}
+
+	cases := []struct {
+		description string
+		path        string
+		tenant      string
+		headers     map[string]string
+		limiter     rateLimiterFunc
+		expected    Expected
+	}{
+		{
+			description: "succeeds when the request has no namespace",
+			path:        "/api/users",
+			tenant:      "",
+			limiter:     limiter(&models.APIRateLimitStatus{Allowed: false}, nil),
+			expected:    Expected{status: http.StatusOK, headers: map[string]string{RateLimitLimitHeader: ""}},
+		},
+		{
+			description: "succeeds when the request is internal",
+			path:        "/internal/lookup",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			limiter:     limiter(&models.APIRateLimitStatus{Allowed: false}, nil),
+			expected:    Expected{status: http.StatusOK, headers: map[string]string{RateLimitLimitHeader: ""}},
+		},
+		{
+			description: "succeeds when the request was not authenticated by the gateway",
+			path:        "/api/invite/token",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			limiter:     limiter(&models.APIRateLimitStatus{Allowed: false}, nil),
+			expected:    Expected{status: http.StatusOK, headers: map[string]string{RateLimitLimitHeader: ""}},
+		},
+		{
+			description: "fails when the request authenticated by an API key exceeds the rate limit",
+			path:        "/api/devices",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			headers:     map[string]string{"X-Api-Key": "00000000-0000-4000-0000-000000000000"},
+			limiter:     limiter(&models.APIRateLimitStatus{Allowed: false, Limit: 10, Remaining: 0, Reset: reset}, nil),
+			expected: Expected{
+				status: http.StatusTooManyRequests,
+				headers: map[string]string{
+					RateLimitLimitHeader:     "10",
+					RateLimitRemainingHeader: "0",
+					RateLimitResetHeader:     "1704067200",
+				},
+			},
+		},
+		{
+			description: "succeeds when the namespace has no rate limit",
+			path:        "/api/devices",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			headers:     map[string]string{"X-ID": "507f1f77bcf86cd799439011"},
+			limiter:     limiter(nil, nil),
+			expected:    Expected{status: http.StatusOK, headers: map[string]string{RateLimitLimitHeader: ""}},
+		},
+		{
+			description: "succeeds when the rate limit cannot be evaluated",
+			path:        "/api/devices",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			headers:     map[string]string{"X-ID": "507f1f77bcf86cd799439011"},
+			limiter:     limiter(nil, errors.New("error")),
+			expected:    Expected{status: http.StatusOK, headers: map[string]string{RateLimitLimitHeader: ""}},
+		},
+		{
+			description: "succeeds when the request is within the rate limit",
+			path:        "/api/devices",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			headers:     map[string]string{"X-ID": "507f1f77bcf86cd799439011"},
+			limiter:     limiter(&models.APIRateLimitStatus{Allowed: true, Limit: 10, Remaining: 9, Reset: reset}, nil),
+			expected: Expected{
+				status: http.StatusOK,
+				headers: map[string]string{
+					RateLimitLimitHeader:     "10",
+					RateLimitRemainingHeader: "9",
+					RateLimitResetHeader:     "1704067200",
+				},
+			},
+		},
+		{
+			description: "fails when the request exceeds the rate limit",
+			path:        "/api/devices",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			headers:     map[string]string{"X-ID": "507f1f77bcf86cd799439011"},
+			limiter:     limiter(&models.APIRateLimitStatus{Allowed: false, Limit: 10, Remaining: 0, Reset: reset}, nil),
+			expected: Expected{
+				status: http.StatusTooManyRequests,
+				headers: map[string]string{
+					RateLimitLimitHeader:     "10",
+					RateLimitRemainingHeader: "0",
+					RateLimitResetHeader:     "1704067200",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			e := echo.New()
+			e.Use(RateLimit(tc.limiter))
+			e.GET(tc.path, func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.tenant != "" {
+				req.Header.Set("X-Tenant-ID", tc.tenant)
+			}
+
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Result().StatusCode)
+			for key, value := range tc.expected.headers {
+				assert.Equal(t, value, rec.Result().Header.Get(key))
+			}
+		})
+	}
+}
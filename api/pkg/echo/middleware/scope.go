@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// APIKeyHeader is the header set by the gateway when the request is authenticated through an API key.
+	APIKeyHeader = "X-API-Key"
+	// APIKeyScopesHeader is the header set by the gateway with the scopes of the API key used to authenticate the
+	// request, as a comma-separated list.
+	APIKeyScopesHeader = "X-API-Scopes"
+)
+
+// EncodeScopes encodes scopes to the format expected in [APIKeyScopesHeader].
+func EncodeScopes(scopes []int) string {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = strconv.Itoa(scope)
+	}
+
+	return strings.Join(values, ",")
+}
+
+// DecodeScopes decodes scopes from the format used in [APIKeyScopesHeader]. Invalid values are ignored.
+func DecodeScopes(header string) []int {
+	scopes := make([]int, 0)
+	for _, value := range strings.Split(header, ",") {
+		scope, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		scopes = append(scopes, scope)
+	}
+
+	return scopes
+}
+
+// RequiresAPIKeyScope blocks requests authenticated through an API key that has none of the required scopes. Requests
+// authenticated in other ways, like JWT, are not affected, as well as API keys without any scope.
+func RequiresAPIKeyScope(scopes ...int) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if key := c.Request().Header.Get(APIKeyHeader); key == "" {
+				return next(c)
+			}
+
+			granted := DecodeScopes(c.Request().Header.Get(APIKeyScopesHeader))
+			if len(granted) == 0 {
+				return next(c)
+			}
+
+			for _, scope := range scopes {
+				for _, g := range granted {
+					if scope == g {
+						return next(c)
+					}
+				}
+			}
+
+			return c.NoContent(http.StatusForbidden)
+		}
+	}
+}
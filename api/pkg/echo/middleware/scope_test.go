@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiresAPIKeyScope(t *testing.T) {
+	cases := []struct {
+		description string
+		required    []int
+		headers     map[string]string
+		expected    int
+	}{
+		{
+			description: "succeeds when request is not authenticated through an API key",
+			required:    []int{5},
+			headers:     map[string]string{},
+			expected:    http.StatusOK,
+		},
+		{
+			description: "succeeds when API key has no scopes",
+			required:    []int{5},
+			headers: map[string]string{
+				APIKeyHeader: "key",
+			},
+			expected: http.StatusOK,
+		},
+		{
+			description: "succeeds when API key has the exact scope",
+			required:    []int{5},
+			headers: map[string]string{
+				APIKeyHeader:       "key",
+				APIKeyScopesHeader: "5",
+			},
+			expected: http.StatusOK,
+		},
+		{
+			description: "succeeds when API key has a superset of the scopes",
+			required:    []int{5},
+			headers: map[string]string{
+				APIKeyHeader:       "key",
+				APIKeyScopesHeader: "1,5,7",
+			},
+			expected: http.StatusOK,
+		},
+		{
+			description: "succeeds when API key has one of the required scopes",
+			required:    []int{4, 5},
+			headers: map[string]string{
+				APIKeyHeader:       "key",
+				APIKeyScopesHeader: "4",
+			},
+			expected: http.StatusOK,
+		},
+		{
+			description: "fails when API key has disjoint scopes",
+			required:    []int{5},
+			headers: map[string]string{
+				APIKeyHeader:       "key",
+				APIKeyScopesHeader: "1,2,3",
+			},
+			expected: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			e := echo.New()
+			e.GET("/", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}, RequiresAPIKeyScope(tc.required...))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected, rec.Result().StatusCode)
+		})
+	}
+}
+
+func TestScopesEncoding(t *testing.T) {
+	cases := []struct {
+		description string
+		scopes      []int
+		encoded     string
+	}{
+		{
+			description: "empty scopes",
+			scopes:      []int{},
+			encoded:     "",
+		},
+		{
+			description: "multiple scopes",
+			scopes:      []int{1, 5, 20},
+			encoded:     "1,5,20",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.encoded, EncodeScopes(tc.scopes))
+			assert.Equal(t, tc.scopes, DecodeScopes(tc.encoded))
+		})
+	}
+}
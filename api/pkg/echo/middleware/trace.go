@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/getsentry/sentry-go"
+	"github.com/labstack/echo/v4"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// Trace starts a Sentry transaction for each request, continuing the trace propagated by the caller, if any, and adds
+// its trace and span IDs to the request-scoped logger. It must run after the middleware that sets that logger.
+func Trace(reporter *sentry.Client) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			hub := sentry.NewHub(reporter, sentry.NewScope())
+			ctx := sentry.SetHubOnContext(req.Context(), hub)
+
+			transaction := sentry.StartTransaction(
+				ctx,
+				req.Method+" "+c.Path(),
+				sentry.ContinueFromRequest(req),
+				sentry.WithOpName("http.server"),
+			)
+			defer transaction.Finish()
+
+			ctx = logger.WithFields(transaction.Context(), logrus.Fields{
+				"trace_id": transaction.TraceID.String(),
+				"span_id":  transaction.SpanID.String(),
+			})
+
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+
+			// NOTICE: when the handler fails, the response is only written later by the error handler.
+			if c.Response().Committed {
+				transaction.Status = sentry.HTTPtoSpanStatus(c.Response().Status)
+			}
+
+			return err
+		}
+	}
+}
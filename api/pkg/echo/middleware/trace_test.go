@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/middleware"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrace(t *testing.T) {
+	reporter, err := sentry.NewClient(sentry.ClientOptions{ //nolint:exhaustruct
+		EnableTracing:    true,
+		TracesSampleRate: 1,
+	})
+	require.NoError(t, err)
+
+	cases := []struct {
+		description string
+		headers     map[string]string
+		requestID   string
+		traceID     string
+	}{
+		{
+			description: "uses the generated request ID when the request does not carry one",
+			headers:     map[string]string{},
+		},
+		{
+			description: "starts a new trace when the request does not carry one",
+			headers:     map[string]string{echo.HeaderXRequestID: "request"},
+			requestID:   "request",
+		},
+		{
+			description: "continues the trace carried by the request",
+			headers: map[string]string{
+				echo.HeaderXRequestID:    "request",
+				sentry.SentryTraceHeader: "d49d9bf66f13450b81f65bc51cf49c03-1cc4b26ab9094ef0-1",
+			},
+			requestID: "request",
+			traceID:   "d49d9bf66f13450b81f65bc51cf49c03",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var fields logrus.Fields
+
+			e := echo.New()
+			e.Use(echomiddleware.RequestID())
+			e.Use(middleware.Log)
+			e.Use(Trace(reporter))
+			e.GET("/", func(c echo.Context) error {
+				fields = logger.FromContext(c.Request().Context()).Data
+
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			if tc.requestID != "" {
+				assert.Equal(t, tc.requestID, fields["request_id"])
+			} else {
+				assert.Equal(t, rec.Header().Get(echo.HeaderXRequestID), fields["request_id"])
+				assert.NotEmpty(t, fields["request_id"])
+			}
+			assert.NotEmpty(t, fields["trace_id"])
+			assert.NotEmpty(t, fields["span_id"])
+
+			if tc.traceID != "" {
+				assert.Equal(t, tc.traceID, fields["trace_id"])
+			}
+		})
+	}
+}
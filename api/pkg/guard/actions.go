@@ -14,11 +14,11 @@ type AllActions struct {
 }
 
 type DeviceActions struct {
-	Accept, Reject, Update, Remove, Connect, Rename, CreateTag, UpdateTag, RemoveTag, RenameTag, DeleteTag int
+	Accept, Reject, Update, Remove, Connect, Rename, Details, CreateTag, UpdateTag, RemoveTag, RenameTag, DeleteTag, CreateGroup, UpdateGroup int
 }
 
 type SessionActions struct {
-	Play, Close, Remove, Details int
+	Play, Close, Remove, Details, Export int
 }
 
 type FirewallActions struct {
@@ -51,17 +51,22 @@ var Actions = AllActions{
 		Remove:    DeviceRemove,
 		Connect:   DeviceConnect,
 		Rename:    DeviceRename,
+		Details:   DeviceDetails,
 		CreateTag: DeviceCreateTag,
 		UpdateTag: DeviceUpdateTag,
 		RemoveTag: DeviceRemoveTag,
 		RenameTag: DeviceRenameTag,
 		DeleteTag: DeviceDeleteTag,
+
+		CreateGroup: DeviceCreateGroup,
+		UpdateGroup: DeviceUpdateGroup,
 	},
 	Session: SessionActions{
 		Play:    SessionPlay,
 		Close:   SessionClose,
 		Remove:  SessionRemove,
 		Details: SessionDetails,
+		Export:  SessionExport,
 	},
 	Firewall: FirewallActions{
 		Create: FirewallCreate,
@@ -236,6 +236,9 @@ func TestCheckPermission(t *testing.T) {
 				Actions.Device.RenameTag,
 				Actions.Device.DeleteTag,
 
+				Actions.Device.CreateGroup,
+				Actions.Device.UpdateGroup,
+
 				Actions.Session.Details,
 			},
 			requiredMocks: func() {
@@ -259,10 +262,14 @@ func TestCheckPermission(t *testing.T) {
 				Actions.Device.RenameTag,
 				Actions.Device.DeleteTag,
 
+				Actions.Device.CreateGroup,
+				Actions.Device.UpdateGroup,
+
 				Actions.Session.Play,
 				Actions.Session.Close,
 				Actions.Session.Remove,
 				Actions.Session.Details,
+				Actions.Session.Export,
 
 				Actions.Firewall.Create,
 				Actions.Firewall.Edit,
@@ -299,10 +306,14 @@ func TestCheckPermission(t *testing.T) {
 				Actions.Device.RenameTag,
 				Actions.Device.DeleteTag,
 
+				Actions.Device.CreateGroup,
+				Actions.Device.UpdateGroup,
+
 				Actions.Session.Play,
 				Actions.Session.Close,
 				Actions.Session.Remove,
 				Actions.Session.Details,
+				Actions.Session.Export,
 
 				Actions.Firewall.Create,
 				Actions.Firewall.Edit,
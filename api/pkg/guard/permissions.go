@@ -58,6 +58,14 @@ const (
 	APIKeyCreate
 	APIKeyEdit
 	APIKeyDelete
+
+	// NOTICE: the permissions are persisted as the API keys' scopes, so new ones must be appended to keep the values
+	// of the existing ones.
+
+	DeviceCreateGroup
+	DeviceUpdateGroup
+
+	SessionExport
 )
 
 var observerPermissions = Permissions{
@@ -80,6 +88,9 @@ var operatorPermissions = Permissions{
 	DeviceRenameTag,
 	DeviceDeleteTag,
 
+	DeviceCreateGroup,
+	DeviceUpdateGroup,
+
 	SessionDetails,
 }
 
@@ -98,12 +109,16 @@ var adminPermissions = Permissions{
 	DeviceRenameTag,
 	DeviceDeleteTag,
 
+	DeviceCreateGroup,
+	DeviceUpdateGroup,
+
 	DeviceUpdate,
 
 	SessionPlay,
 	SessionClose,
 	SessionRemove,
 	SessionDetails,
+	SessionExport,
 
 	FirewallCreate,
 	FirewallEdit,
@@ -145,12 +160,16 @@ var ownerPermissions = Permissions{
 	DeviceRenameTag,
 	DeviceDeleteTag,
 
+	DeviceCreateGroup,
+	DeviceUpdateGroup,
+
 	DeviceUpdate,
 
 	SessionPlay,
 	SessionClose,
 	SessionRemove,
 	SessionDetails,
+	SessionExport,
 
 	FirewallCreate,
 	FirewallEdit,
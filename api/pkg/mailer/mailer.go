@@ -0,0 +1,131 @@
+// Package mailer sends the emails of ShellHub, like the usage reports of the namespaces, through an SMTP server.
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidHeader = errors.New("email header must not contain line breaks")
+
+// Config is the configuration of the SMTP server the emails are sent through.
+type Config struct {
+	Host string
+	Port int
+	// Username and Password authenticate on the SMTP server. When Username is empty, the emails are sent without
+	// authentication.
+	Username string
+	Password string
+	// From is the address the emails are sent from.
+	From string
+}
+
+// Configured reports whether the SMTP server is configured, what is required to send any email.
+func (c *Config) Configured() bool {
+	return c != nil && c.Host != "" && c.From != ""
+}
+
+//go:generate mockery --name Mailer --filename mailer.go
+type Mailer interface {
+	// Send sends an email with the subject and the HTML body to the address to.
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+type smtpMailer struct {
+	cfg Config
+}
+
+// NewSMTP creates a [Mailer] that sends the emails through the SMTP server configured by cfg.
+func NewSMTP(cfg *Config) Mailer {
+	return &smtpMailer{cfg: *cfg}
+}
+
+// Send sends the email as [smtp.SendMail] does, but on a connection closed when ctx is done, interrupting the
+// conversation with the SMTP server.
+func (m *smtpMailer) Send(ctx context.Context, to, subject, body string) error {
+	msg, err := message(m.cfg.From, to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	conn, err := new(net.Dialer).DialContext(ctx, "tcp", net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port)))
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	c, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		return err
+	}
+
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.cfg.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+
+	if m.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(m.cfg.From); err != nil {
+		return err
+	}
+
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// message builds the email, with its headers, sent from the address from to the address to.
+func message(from, to, subject, body string) ([]byte, error) {
+	for _, header := range []string{from, to, subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, ErrInvalidHeader
+		}
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(body)
+
+	return []byte(b.String()), nil
+}
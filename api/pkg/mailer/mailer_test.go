@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage(t *testing.T) {
+	t.Run("builds the email with its headers", func(t *testing.T) {
+		msg, err := message("shellhub@shellhub.io", "owner@shellhub.io", "Subject", "<p>body</p>")
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Join([]string{
+			"From: shellhub@shellhub.io",
+			"To: owner@shellhub.io",
+			"Subject: Subject",
+			"MIME-Version: 1.0",
+			"Content-Type: text/html; charset=\"UTF-8\"",
+			"",
+			"<p>body</p>",
+		}, "\r\n"), string(msg))
+	})
+
+	t.Run("fails when a header has a line break", func(t *testing.T) {
+		_, err := message("shellhub@shellhub.io", "owner@shellhub.io\r\nBcc: other@shellhub.io", "Subject", "<p>body</p>")
+		assert.ErrorIs(t, err, ErrInvalidHeader)
+	})
+}
+
+func TestSMTPSend(t *testing.T) {
+	t.Run("stops when the context is done", func(t *testing.T) {
+		// NOTICE: the server accepts the connection but never greets the client, what would block it forever.
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer listener.Close()
+
+		go func() {
+			conn, err := listener.Accept()
+			if err == nil {
+				defer conn.Close()
+				_, _ = io.Copy(io.Discard, conn)
+			}
+		}()
+
+		addr := listener.Addr().(*net.TCPAddr)
+		m := NewSMTP(&Config{Host: addr.IP.String(), Port: addr.Port, From: "shellhub@shellhub.io"})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		assert.Error(t, m.Send(ctx, "owner@shellhub.io", "Subject", "<p>body</p>"))
+	})
+}
@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+// NamespaceUsageReport is the data rendered by [TemplateNamespaceUsageReport].
+type NamespaceUsageReport struct {
+	Namespace   string
+	TenantID    string
+	GeneratedAt time.Time
+	Stats       models.Stats
+}
+
+// TemplateNamespaceUsageReport is the body of the email with the usage report of a namespace.
+var TemplateNamespaceUsageReport = template.Must(template.New("namespace_usage_report").Parse(`<!DOCTYPE html>
+<html>
+<body>
+<h1>Usage report of {{ .Namespace }}</h1>
+<p>Generated at {{ .GeneratedAt.UTC.Format "2006-01-02 15:04 MST" }} for the namespace {{ .TenantID }}.</p>
+<table>
+<tr><th>Registered devices</th><td>{{ .Stats.RegisteredDevices }}</td></tr>
+<tr><th>Online devices</th><td>{{ .Stats.OnlineDevices }}</td></tr>
+<tr><th>Pending devices</th><td>{{ .Stats.PendingDevices }}</td></tr>
+<tr><th>Rejected devices</th><td>{{ .Stats.RejectedDevices }}</td></tr>
+<tr><th>Active sessions</th><td>{{ .Stats.ActiveSessions }}</td></tr>
+</table>
+</body>
+</html>
+`))
+
+// SendNamespaceUsageReport renders the report with [TemplateNamespaceUsageReport] and sends it, through m, to the
+// address to.
+func SendNamespaceUsageReport(ctx context.Context, m Mailer, to string, report *NamespaceUsageReport) error {
+	var body bytes.Buffer
+	if err := TemplateNamespaceUsageReport.Execute(&body, report); err != nil {
+		return err
+	}
+
+	return m.Send(ctx, to, fmt.Sprintf("Usage report of %s", report.Namespace), body.String())
+}
+
+// NamespaceExpiryWarning is the data rendered by [TemplateNamespaceExpiryWarning].
+type NamespaceExpiryWarning struct {
+	Namespace    string
+	TenantID     string
+	LastActiveAt time.Time
+	ExpiresAt    time.Time
+}
+
+// TemplateNamespaceExpiryWarning is the body of the email warning that an inactive namespace will expire.
+var TemplateNamespaceExpiryWarning = template.Must(template.New("namespace_expiry_warning").Parse(`<!DOCTYPE html>
+<html>
+<body>
+<h1>{{ .Namespace }} will expire</h1>
+<p>The namespace {{ .TenantID }} is inactive since {{ .LastActiveAt.UTC.Format "2006-01-02" }} and will be deleted at {{ .ExpiresAt.UTC.Format "2006-01-02" }}.</p>
+<p>Connect to any of its devices, or sign in to it, to keep it.</p>
+</body>
+</html>
+`))
+
+// SendNamespaceExpiryWarning renders the warning with [TemplateNamespaceExpiryWarning] and sends it, through m, to the
+// address to.
+func SendNamespaceExpiryWarning(ctx context.Context, m Mailer, to string, warning *NamespaceExpiryWarning) error {
+	var body bytes.Buffer
+	if err := TemplateNamespaceExpiryWarning.Execute(&body, warning); err != nil {
+		return err
+	}
+
+	return m.Send(ctx, to, fmt.Sprintf("%s will expire", warning.Namespace), body.String())
+}
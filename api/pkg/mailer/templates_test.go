@@ -0,0 +1,94 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/mailer/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendNamespaceUsageReport(t *testing.T) {
+	ctx := context.Background()
+
+	report := &NamespaceUsageReport{
+		Namespace:   "<namespace>",
+		TenantID:    "00000000-0000-4000-0000-000000000000",
+		GeneratedAt: time.Date(2024, time.June, 1, 12, 30, 0, 0, time.UTC),
+		Stats: models.Stats{
+			RegisteredDevices: 10,
+			OnlineDevices:     7,
+			PendingDevices:    2,
+			RejectedDevices:   1,
+			ActiveSessions:    3,
+		},
+	}
+
+	t.Run("renders the report and sends it", func(t *testing.T) {
+		m := mocks.NewMailer(t)
+
+		var body string
+		m.On("Send", ctx, "owner@shellhub.io", "Usage report of <namespace>", mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { body = args.String(3) }).
+			Return(nil).
+			Once()
+
+		assert.NoError(t, SendNamespaceUsageReport(ctx, m, "owner@shellhub.io", report))
+
+		assert.Contains(t, body, "<h1>Usage report of &lt;namespace&gt;</h1>")
+		assert.Contains(t, body, "Generated at 2024-06-01 12:30 UTC for the namespace 00000000-0000-4000-0000-000000000000.")
+		assert.Contains(t, body, "<tr><th>Registered devices</th><td>10</td></tr>")
+		assert.Contains(t, body, "<tr><th>Online devices</th><td>7</td></tr>")
+		assert.Contains(t, body, "<tr><th>Pending devices</th><td>2</td></tr>")
+		assert.Contains(t, body, "<tr><th>Rejected devices</th><td>1</td></tr>")
+		assert.Contains(t, body, "<tr><th>Active sessions</th><td>3</td></tr>")
+	})
+
+	t.Run("fails when the report could not be sent", func(t *testing.T) {
+		m := mocks.NewMailer(t)
+		m.On("Send", ctx, "owner@shellhub.io", "Usage report of <namespace>", mock.AnythingOfType("string")).
+			Return(errors.New("error")).
+			Once()
+
+		assert.EqualError(t, SendNamespaceUsageReport(ctx, m, "owner@shellhub.io", report), "error")
+	})
+}
+
+func TestSendNamespaceExpiryWarning(t *testing.T) {
+	ctx := context.Background()
+
+	warning := &NamespaceExpiryWarning{
+		Namespace:    "<namespace>",
+		TenantID:     "00000000-0000-4000-0000-000000000000",
+		LastActiveAt: time.Date(2024, time.March, 18, 12, 30, 0, 0, time.UTC),
+		ExpiresAt:    time.Date(2024, time.June, 16, 12, 30, 0, 0, time.UTC),
+	}
+
+	t.Run("renders the warning and sends it", func(t *testing.T) {
+		m := mocks.NewMailer(t)
+
+		var body string
+		m.On("Send", ctx, "owner@shellhub.io", "<namespace> will expire", mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { body = args.String(3) }).
+			Return(nil).
+			Once()
+
+		assert.NoError(t, SendNamespaceExpiryWarning(ctx, m, "owner@shellhub.io", warning))
+
+		assert.Contains(t, body, "<h1>&lt;namespace&gt; will expire</h1>")
+		assert.Contains(t, body, "The namespace 00000000-0000-4000-0000-000000000000 is inactive since 2024-03-18 and will be deleted at 2024-06-16.")
+	})
+
+	t.Run("fails when the warning could not be sent", func(t *testing.T) {
+		m := mocks.NewMailer(t)
+		m.On("Send", ctx, "owner@shellhub.io", "<namespace> will expire", mock.AnythingOfType("string")).
+			Return(errors.New("error")).
+			Once()
+
+		assert.EqualError(t, SendNamespaceExpiryWarning(ctx, m, "owner@shellhub.io", warning), "error")
+	})
+}
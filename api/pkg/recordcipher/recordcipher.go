@@ -0,0 +1,137 @@
+// Package recordcipher encrypts, at rest, the frames recorded on the sessions with keys held by the server.
+package recordcipher
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+var (
+	ErrInvalidKeys = errors.New("record encryption keys must be a list of id:key, where the key is a base64 encoded AES key of 16, 24 or 32 bytes")
+	ErrUnknownKey  = errors.New("frame was encrypted with an unknown key")
+	ErrCorrupted   = errors.New("frame could not be decrypted")
+)
+
+// Keyring holds the keys used to encrypt and decrypt the frames. The current key encrypts the new frames, while the
+// others are kept to decrypt the frames encrypted before a rotation.
+//
+// A nil Keyring leaves the frames unencrypted, so the encryption is opt-in.
+type Keyring struct {
+	current string
+	aeads   map[string]cipher.AEAD
+}
+
+// Parse parses the keys from spec, a comma-separated list of id:key, where key is a base64 encoded AES key. The first
+// key is the current one; to rotate it, a new key is prepended, keeping the old ones to decrypt the frames already
+// stored. When spec is empty, it returns a nil Keyring.
+func Parse(spec string) (*Keyring, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	keyring := &Keyring{aeads: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.Split(spec, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, ErrInvalidKeys
+		}
+
+		if _, ok := keyring.aeads[id]; ok {
+			return nil, ErrInvalidKeys
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, ErrInvalidKeys
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, ErrInvalidKeys
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		if keyring.current == "" {
+			keyring.current = id
+		}
+
+		keyring.aeads[id] = aead
+	}
+
+	return keyring, nil
+}
+
+// Current returns the ID of the key encrypting the new frames.
+func (k *Keyring) Current() string {
+	if k == nil {
+		return ""
+	}
+
+	return k.current
+}
+
+// Seal encrypts the frame's message with the current key, setting [models.RecordedSession.KeyID] to it. It does
+// nothing when the Keyring is nil.
+func (k *Keyring) Seal(frame *models.RecordedSession) error {
+	if k == nil {
+		return nil
+	}
+
+	aead := k.aeads[k.current]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	// NOTICE: the session's UID is authenticated with the message, so a frame can't be moved to another session.
+	sealed := aead.Seal(nonce, nonce, []byte(frame.Message), []byte(frame.UID))
+
+	frame.Message = base64.StdEncoding.EncodeToString(sealed)
+	frame.KeyID = k.current
+
+	return nil
+}
+
+// Open decrypts the frame's message with the key it was encrypted with, clearing [models.RecordedSession.KeyID].
+// Frames stored unencrypted are left untouched.
+func (k *Keyring) Open(frame *models.RecordedSession) error {
+	if frame.KeyID == "" {
+		return nil
+	}
+
+	if k == nil {
+		return ErrUnknownKey
+	}
+
+	aead, ok := k.aeads[frame.KeyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(frame.Message)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return ErrCorrupted
+	}
+
+	message, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(frame.UID))
+	if err != nil {
+		return ErrCorrupted
+	}
+
+	frame.Message = string(message)
+	frame.KeyID = ""
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package recordcipher
+
+import (
+	"testing"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	keyV1 = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+	keyV2 = "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE="
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		description string
+		spec        string
+		current     string
+		err         error
+	}{
+		{
+			description: "returns a nil keyring when no key is set",
+			spec:        "",
+			current:     "",
+			err:         nil,
+		},
+		{
+			description: "fails when the key has no ID",
+			spec:        keyV1,
+			err:         ErrInvalidKeys,
+		},
+		{
+			description: "fails when the key isn't base64 encoded",
+			spec:        "v1:key",
+			err:         ErrInvalidKeys,
+		},
+		{
+			description: "fails when the key has an invalid size",
+			spec:        "v1:AAAA",
+			err:         ErrInvalidKeys,
+		},
+		{
+			description: "fails when an ID is repeated",
+			spec:        "v1:" + keyV1 + ",v1:" + keyV2,
+			err:         ErrInvalidKeys,
+		},
+		{
+			description: "succeeds using the first key as the current one",
+			spec:        "v2:" + keyV2 + ", v1:" + keyV1,
+			current:     "v2",
+			err:         nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			keyring, err := Parse(tc.spec)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.current, keyring.Current())
+		})
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	old, err := Parse("v1:" + keyV1)
+	require.NoError(t, err)
+
+	rotated, err := Parse("v2:" + keyV2 + ",v1:" + keyV1)
+	require.NoError(t, err)
+
+	t.Run("leaves the frames unencrypted when the keyring is nil", func(t *testing.T) {
+		var keyring *Keyring
+
+		frame := &models.RecordedSession{UID: "uid", Message: "message"}
+		require.NoError(t, keyring.Seal(frame))
+		assert.Equal(t, &models.RecordedSession{UID: "uid", Message: "message"}, frame)
+
+		require.NoError(t, keyring.Open(frame))
+		assert.Equal(t, &models.RecordedSession{UID: "uid", Message: "message"}, frame)
+	})
+
+	t.Run("fails to open an encrypted frame when the keyring is nil", func(t *testing.T) {
+		var keyring *Keyring
+
+		frame := &models.RecordedSession{UID: "uid", Message: "message"}
+		require.NoError(t, old.Seal(frame))
+
+		assert.Equal(t, ErrUnknownKey, keyring.Open(frame))
+	})
+
+	t.Run("opens the frames encrypted before a rotation", func(t *testing.T) {
+		frame := &models.RecordedSession{UID: "uid", Message: "message"}
+		require.NoError(t, old.Seal(frame))
+		assert.Equal(t, "v1", frame.KeyID)
+		assert.NotEqual(t, "message", frame.Message)
+
+		require.NoError(t, rotated.Open(frame))
+		assert.Equal(t, &models.RecordedSession{UID: "uid", Message: "message"}, frame)
+	})
+
+	t.Run("seals the frames with the current key", func(t *testing.T) {
+		frame := &models.RecordedSession{UID: "uid", Message: "message"}
+		require.NoError(t, rotated.Seal(frame))
+		assert.Equal(t, "v2", frame.KeyID)
+
+		assert.Equal(t, ErrUnknownKey, old.Open(frame))
+	})
+
+	t.Run("fails to open a frame moved to another session", func(t *testing.T) {
+		frame := &models.RecordedSession{UID: "uid", Message: "message"}
+		require.NoError(t, old.Seal(frame))
+
+		frame.UID = "other"
+		assert.Equal(t, ErrCorrupted, old.Open(frame))
+	})
+
+	t.Run("leaves the frames stored before the encryption untouched", func(t *testing.T) {
+		frame := &models.RecordedSession{UID: "uid", Message: "message"}
+		require.NoError(t, rotated.Open(frame))
+		assert.Equal(t, &models.RecordedSession{UID: "uid", Message: "message"}, frame)
+	})
+}
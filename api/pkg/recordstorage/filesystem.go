@@ -0,0 +1,208 @@
+package recordstorage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+var ErrInvalidUID = errors.New("session uid cannot be used as a file name")
+
+// FileSystemRecordingStorage keeps the recording of each session as a file of newline-delimited JSON frames on a
+// directory.
+//
+// NOTICE: the appends and the deletions are only serialized within the process. Replicas of the API sharing the
+// directory don't coordinate them, so the uploads of a session, and the cleanup, must not run on more than one replica
+// at once.
+type FileSystemRecordingStorage struct {
+	dir string
+	// mu serializes the appends and the deletions, so the frames of concurrent uploads aren't interleaved.
+	mu sync.Mutex
+}
+
+var _ RecordingStorage = (*FileSystemRecordingStorage)(nil)
+
+// NewFileSystemRecordingStorage creates a storage on dir, creating it when it doesn't exist.
+func NewFileSystemRecordingStorage(dir string) (*FileSystemRecordingStorage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &FileSystemRecordingStorage{dir: dir}, nil
+}
+
+// fileFrame is a line of a recording's file. Unlike [models.RecordedSession], it keeps the ID of the key that
+// encrypted the message.
+//
+// NOTICE: its fields must follow the ones of [models.RecordedSession], as both are converted into each other.
+type fileFrame struct {
+	UID      models.UID `json:"uid"`
+	Message  string     `json:"message"`
+	TenantID string     `json:"tenant_id,omitempty"`
+	Time     time.Time  `json:"time"`
+	Width    int        `json:"width,omitempty"`
+	Height   int        `json:"height,omitempty"`
+	KeyID    string     `json:"key_id,omitempty"`
+}
+
+func (f *FileSystemRecordingStorage) path(uid models.UID) (string, error) {
+	if uid == "" || strings.ContainsAny(string(uid), `/\`) || strings.HasPrefix(string(uid), ".") {
+		return "", ErrInvalidUID
+	}
+
+	return filepath.Join(f.dir, string(uid)+".ndjson"), nil
+}
+
+func (f *FileSystemRecordingStorage) Store(_ context.Context, uid models.UID, frames []models.RecordedSession) error {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	path, err := f.path(uid)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, frame := range frames {
+		if err := encoder.Encode(fileFrame(frame)); err != nil {
+			file.Close()
+
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
+
+func (f *FileSystemRecordingStorage) Retrieve(ctx context.Context, uid models.UID) ([]models.RecordedSession, error) {
+	frames := make([]models.RecordedSession, 0)
+	if err := f.Stream(ctx, uid, func(frame *models.RecordedSession) error {
+		frames = append(frames, *frame)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return frames, nil
+}
+
+func (f *FileSystemRecordingStorage) Stream(ctx context.Context, uid models.UID, fn func(frame *models.RecordedSession) error) error {
+	path, err := f.path(uid)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for decoder.More() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var line fileFrame
+		if err := decoder.Decode(&line); err != nil {
+			return err
+		}
+
+		frame := models.RecordedSession(line)
+		if err := fn(&frame); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *FileSystemRecordingStorage) Delete(_ context.Context, uid models.UID) error {
+	path, err := f.path(uid)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteBefore deletes the recordings whose last frame was written before or at lte, returning the number of deleted
+// recordings. As a recording is kept on a single file, the recordings written after lte are kept whole.
+func (f *FileSystemRecordingStorage) DeleteBefore(ctx context.Context, lte time.Time) (int64, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var deleted int64
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".ndjson" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return deleted, err
+		}
+
+		if info.ModTime().After(lte) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(f.dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return deleted, err
+		}
+
+		deleted++
+	}
+
+	return deleted, nil
+}
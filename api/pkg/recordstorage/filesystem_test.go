@@ -0,0 +1,99 @@
+package recordstorage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSystemRecordingStorage(t *testing.T) {
+	ctx := context.Background()
+
+	dir := filepath.Join(t.TempDir(), "recordings")
+
+	storage, err := NewFileSystemRecordingStorage(dir)
+	require.NoError(t, err)
+
+	frames := []models.RecordedSession{
+		{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000", Message: "first", Time: time.Unix(0, 0).UTC(), Width: 80, Height: 24},
+		{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000", Message: "second", Time: time.Unix(1, 0).UTC(), Width: 80, Height: 24, KeyID: "v1"},
+	}
+
+	t.Run("retrieves no frames when the session has no recording", func(t *testing.T) {
+		retrieved, err := storage.Retrieve(ctx, "uid")
+		require.NoError(t, err)
+		assert.Equal(t, []models.RecordedSession{}, retrieved)
+	})
+
+	t.Run("fails when the uid is not a file name", func(t *testing.T) {
+		for _, uid := range []models.UID{"", "../uid", "dir/uid", ".uid"} {
+			assert.Equal(t, ErrInvalidUID, storage.Store(ctx, uid, frames))
+
+			_, err := storage.Retrieve(ctx, uid)
+			assert.Equal(t, ErrInvalidUID, err)
+		}
+	})
+
+	t.Run("retrieves the frames stored in batches", func(t *testing.T) {
+		require.NoError(t, storage.Store(ctx, "uid", frames[:1]))
+		require.NoError(t, storage.Store(ctx, "uid", frames[1:]))
+		require.NoError(t, storage.Store(ctx, "uid", nil))
+
+		retrieved, err := storage.Retrieve(ctx, "uid")
+		require.NoError(t, err)
+		assert.Equal(t, frames, retrieved)
+
+		_, err = os.Stat(filepath.Join(dir, "uid.ndjson"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("stops streaming when fn fails", func(t *testing.T) {
+		calls := 0
+		err := storage.Stream(ctx, "uid", func(*models.RecordedSession) error {
+			calls++
+
+			return errors.New("error")
+		})
+
+		assert.Equal(t, errors.New("error"), err)
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("deletes the recording of a session", func(t *testing.T) {
+		require.NoError(t, storage.Store(ctx, "deleted", frames))
+
+		require.NoError(t, storage.Delete(ctx, "deleted"))
+		require.NoError(t, storage.Delete(ctx, "deleted"))
+		assert.Equal(t, ErrInvalidUID, storage.Delete(ctx, "../uid"))
+
+		retrieved, err := storage.Retrieve(ctx, "deleted")
+		require.NoError(t, err)
+		assert.Equal(t, []models.RecordedSession{}, retrieved)
+	})
+
+	t.Run("deletes the recordings written before a date", func(t *testing.T) {
+		require.NoError(t, storage.Store(ctx, "old", frames))
+		require.NoError(t, storage.Store(ctx, "new", frames))
+
+		now := time.Now()
+		require.NoError(t, os.Chtimes(filepath.Join(dir, "old.ndjson"), now.Add(-time.Hour), now.Add(-time.Hour)))
+		require.NoError(t, os.Chtimes(filepath.Join(dir, "new.ndjson"), now.Add(time.Hour), now.Add(time.Hour)))
+		require.NoError(t, os.Chtimes(filepath.Join(dir, "uid.ndjson"), now.Add(time.Hour), now.Add(time.Hour)))
+
+		deleted, err := storage.DeleteBefore(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		_, err = os.Stat(filepath.Join(dir, "old.ndjson"))
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+
+		_, err = os.Stat(filepath.Join(dir, "new.ndjson"))
+		assert.NoError(t, err)
+	})
+}
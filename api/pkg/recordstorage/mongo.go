@@ -0,0 +1,43 @@
+package recordstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+// MongoRecordingStorage keeps the recordings on the store's recorded sessions.
+type MongoRecordingStorage struct {
+	store store.Store
+}
+
+var _ RecordingStorage = (*MongoRecordingStorage)(nil)
+
+func NewMongoRecordingStorage(store store.Store) *MongoRecordingStorage {
+	return &MongoRecordingStorage{store: store}
+}
+
+func (m *MongoRecordingStorage) Store(ctx context.Context, _ models.UID, frames []models.RecordedSession) error {
+	return m.store.SessionCreateRecordFrames(ctx, frames)
+}
+
+func (m *MongoRecordingStorage) Retrieve(ctx context.Context, uid models.UID) ([]models.RecordedSession, error) {
+	return m.store.SessionListRecordFrames(ctx, uid)
+}
+
+func (m *MongoRecordingStorage) Stream(ctx context.Context, uid models.UID, fn func(frame *models.RecordedSession) error) error {
+	return m.store.SessionStreamRecordFrames(ctx, uid, fn)
+}
+
+func (m *MongoRecordingStorage) Delete(ctx context.Context, uid models.UID) error {
+	return m.store.SessionDeleteRecordFrames(ctx, uid)
+}
+
+// DeleteBefore deletes every frame recorded before or at lte, returning the number of deleted frames.
+func (m *MongoRecordingStorage) DeleteBefore(ctx context.Context, lte time.Time) (int64, error) {
+	deleted, _, err := m.store.SessionDeleteRecordFrameByDate(ctx, lte, 0)
+
+	return deleted, err
+}
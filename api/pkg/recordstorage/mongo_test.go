@@ -0,0 +1,47 @@
+package recordstorage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+func TestMongoRecordingStorage(t *testing.T) {
+	ctx := context.Background()
+
+	storeMock := new(mocks.Store)
+	storage := NewMongoRecordingStorage(storeMock)
+
+	frames := []models.RecordedSession{{UID: "uid", Message: "message"}}
+
+	storeMock.On("SessionCreateRecordFrames", ctx, frames).Return(errors.New("error")).Once()
+	assert.Equal(t, errors.New("error"), storage.Store(ctx, "uid", frames))
+
+	storeMock.On("SessionCreateRecordFrames", ctx, frames).Return(nil).Once()
+	assert.NoError(t, storage.Store(ctx, "uid", frames))
+
+	storeMock.On("SessionListRecordFrames", ctx, models.UID("uid")).Return(frames, nil).Once()
+	retrieved, err := storage.Retrieve(ctx, "uid")
+	assert.NoError(t, err)
+	assert.Equal(t, frames, retrieved)
+
+	storeMock.On("SessionStreamRecordFrames", ctx, models.UID("uid"), testifymock.Anything).Return(nil).Once()
+	assert.NoError(t, storage.Stream(ctx, "uid", func(*models.RecordedSession) error { return nil }))
+
+	storeMock.On("SessionDeleteRecordFrames", ctx, models.UID("uid")).Return(nil).Once()
+	assert.NoError(t, storage.Delete(ctx, "uid"))
+
+	lte := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	storeMock.On("SessionDeleteRecordFrameByDate", ctx, lte, int64(0)).Return(int64(2), int64(1), nil).Once()
+	deleted, err := storage.DeleteBefore(ctx, lte)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	storeMock.AssertExpectations(t)
+}
@@ -0,0 +1,36 @@
+// Package recordstorage defines where the frames recorded on the sessions are kept.
+package recordstorage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+const (
+	// BackendMongo keeps the recordings on the MongoDB, along with the rest of the data.
+	BackendMongo = "mongo"
+	// BackendFileSystem keeps the recordings as files on a directory.
+	BackendFileSystem = "filesystem"
+)
+
+var ErrUnknownBackend = errors.New("recording backend must be mongo or filesystem")
+
+// RecordingStorage stores, retrieves and deletes the frames recorded on the sessions.
+type RecordingStorage interface {
+	// Store appends the frames to the recording of the session with uid.
+	Store(ctx context.Context, uid models.UID, frames []models.RecordedSession) error
+	// Retrieve returns the frames recorded on the session with uid, ordered by their time. A session without
+	// recording has no frames.
+	Retrieve(ctx context.Context, uid models.UID) ([]models.RecordedSession, error)
+	// Stream calls fn for each frame recorded on the session with uid, ordered by their time, without loading all of
+	// them in memory.
+	Stream(ctx context.Context, uid models.UID, fn func(frame *models.RecordedSession) error) error
+	// Delete deletes the frames recorded on the session with uid. Deleting a session without recording succeeds.
+	Delete(ctx context.Context, uid models.UID) error
+	// DeleteBefore deletes the frames recorded before or at lte, returning how many recordings, or frames, depending on
+	// the storage, were deleted.
+	DeleteBefore(ctx context.Context, lte time.Time) (int64, error)
+}
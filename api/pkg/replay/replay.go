@@ -0,0 +1,308 @@
+// Package replay builds asciinema v2 recordings from the frames recorded on a session, adjusting them to the playback
+// speed requested.
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+const (
+	// MinSpeed is the minimum playback speed allowed.
+	MinSpeed = 0.1
+	// MaxSpeed is the maximum playback speed allowed.
+	MaxSpeed = 10.0
+	// DefaultSpeed is the playback speed used when none is informed.
+	DefaultSpeed = 1.0
+	// SkipThreshold is the inter-frame delay below which frames are merged into the next one when the playback is
+	// faster than the original, avoiding overwhelming the client with tiny frames.
+	SkipThreshold = 50 * time.Millisecond
+)
+
+// ContentType is the media type of an asciinema v2 recording.
+const ContentType = "application/x-asciicast+json"
+
+var ErrInvalidSpeed = errors.New("playback speed must be between 0.1 and 10.0")
+
+// Header is the first line of an asciinema v2 recording.
+type Header struct {
+	Version   int   `json:"version"`
+	Width     int   `json:"width"`
+	Height    int   `json:"height"`
+	Timestamp int64 `json:"timestamp"`
+	// Time is the start of the recording formatted as RFC 3339 in the viewer's timezone. As the asciinema format
+	// defines Timestamp as a Unix time, it is kept untouched and this field carries the localized value.
+	Time string `json:"time,omitempty"`
+}
+
+// Localize formats the start of the recording in the timezone loc, filling [Header.Time]. It does nothing when the
+// recording has no frames.
+func (h *Header) Localize(loc *time.Location) {
+	if h.Timestamp == 0 {
+		return
+	}
+
+	h.Time = time.Unix(h.Timestamp, 0).In(loc).Format(time.RFC3339)
+}
+
+// Frame is an output event of an asciinema v2 recording.
+type Frame struct {
+	// Time is the offset, in seconds, of the frame from the start of the recording.
+	Time float64
+	// Data is the data written to the terminal.
+	Data string
+}
+
+// MarshalJSON encodes the frame as an asciinema v2 event, that is, a `[time, "o", data]` array.
+func (f Frame) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{f.Time, "o", f.Data})
+}
+
+// FromRecordedSession converts the frames recorded on a session, ordered by time, to an asciinema v2 header and its
+// frames.
+func FromRecordedSession(records []models.RecordedSession) (*Header, []Frame) {
+	header := &Header{Version: 2}
+	frames := make([]Frame, 0, len(records))
+	if len(records) == 0 {
+		return header, frames
+	}
+
+	start := records[0].Time
+	header.Timestamp = start.Unix()
+
+	for _, record := range records {
+		if record.Width > 0 && record.Height > 0 {
+			header.Width = record.Width
+			header.Height = record.Height
+		}
+
+		frames = append(frames, Frame{Time: record.Time.Sub(start).Seconds(), Data: record.Message})
+	}
+
+	return header, frames
+}
+
+// Writer writes an asciinema v2 recording frame by frame, without holding the whole recording in memory. As the header
+// is written with the first frame, its dimensions are the ones of the first frame that has them.
+type Writer struct {
+	encoder *json.Encoder
+	// start is the time of the first frame written.
+	start   time.Time
+	started bool
+	// pending holds the frames read while waiting for the terminal dimensions to write the header.
+	pending []models.RecordedSession
+	// loc is the timezone the header is localized to, if any.
+	loc *time.Location
+	// throttler adjusts the frames to the playback speed, if any.
+	throttler *Throttler
+}
+
+// NewWriter creates a [Writer] that writes the recording to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{encoder: json.NewEncoder(w)}
+}
+
+// NewPlaybackWriter creates a [Writer] that writes the recording to w adjusted to a playback speed, like [Throttler],
+// with its header localized to loc.
+func NewPlaybackWriter(w io.Writer, speed float64, maxGapMS int, loc *time.Location) (*Writer, error) {
+	throttler, err := NewThrottler(speed, maxGapMS)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{encoder: json.NewEncoder(w), loc: loc, throttler: throttler}, nil
+}
+
+// WriteFrame writes a frame recorded on a session. Frames must be written ordered by time.
+func (w *Writer) WriteFrame(record *models.RecordedSession) error {
+	if !w.started {
+		w.pending = append(w.pending, *record)
+		if record.Width == 0 || record.Height == 0 {
+			return nil
+		}
+
+		return w.flush()
+	}
+
+	return w.encode(Frame{Time: record.Time.Sub(w.start).Seconds(), Data: record.Message})
+}
+
+// Close writes the frames still waiting for the header or merged by the throttler. It must be called after the last
+// frame is written.
+func (w *Writer) Close() error {
+	if !w.started {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+
+	if w.throttler == nil {
+		return nil
+	}
+
+	if frame, ok := w.throttler.Flush(); ok {
+		return w.encoder.Encode(frame)
+	}
+
+	return nil
+}
+
+// flush writes the header followed by the pending frames.
+func (w *Writer) flush() error {
+	header, frames := FromRecordedSession(w.pending)
+	if w.loc != nil {
+		header.Localize(w.loc)
+	}
+
+	if err := w.encoder.Encode(header); err != nil {
+		return err
+	}
+
+	for _, frame := range frames {
+		if err := w.encode(frame); err != nil {
+			return err
+		}
+	}
+
+	if len(w.pending) > 0 {
+		w.start = w.pending[0].Time
+	}
+
+	w.started = true
+	w.pending = nil
+
+	return nil
+}
+
+// encode writes a frame, adjusted to the playback speed when there is a throttler.
+func (w *Writer) encode(frame Frame) error {
+	if w.throttler != nil {
+		var ok bool
+		if frame, ok = w.throttler.Push(frame); !ok {
+			return nil
+		}
+	}
+
+	return w.encoder.Encode(frame)
+}
+
+// Throttler adjusts the timestamps of the frames of a recording to a playback speed as they are read, so the recording
+// doesn't need to be held in memory. The delay between frames is multiplied by 1/speed and, when maxGapMS is greater
+// than zero, capped to it.
+//
+// When speed is greater than one, frames whose adjusted delay is below [SkipThreshold] are merged into the next frame
+// returned, except the first and the last ones.
+type Throttler struct {
+	speed    float64
+	maxGapMS int
+	// read is the number of frames read.
+	read int
+	// last is the original time of the last frame read.
+	last float64
+	// elapsed is the adjusted time of the last frame read.
+	elapsed float64
+	// merged holds the data of the frames merged into the next one.
+	merged  strings.Builder
+	pending bool
+}
+
+// NewThrottler creates a [Throttler] to the playback speed, returning [ErrInvalidSpeed] when it's out of range.
+func NewThrottler(speed float64, maxGapMS int) (*Throttler, error) {
+	if speed < MinSpeed || speed > MaxSpeed {
+		return nil, ErrInvalidSpeed
+	}
+
+	return &Throttler{speed: speed, maxGapMS: maxGapMS}, nil
+}
+
+// Push reads the next frame, which must be ordered by time, returning it adjusted to the playback speed. It reports
+// false when the frame is merged into the next one.
+func (t *Throttler) Push(frame Frame) (Frame, bool) {
+	gap := (frame.Time - t.last) / t.speed
+	if gap < 0 {
+		gap = 0
+	}
+
+	if max := float64(t.maxGapMS) / 1000; t.maxGapMS > 0 && gap > max {
+		gap = max
+	}
+
+	t.last = frame.Time
+	t.elapsed += gap
+	t.read++
+
+	t.merged.WriteString(frame.Data)
+	t.pending = true
+
+	if t.speed > 1 && t.read > 1 && gap < SkipThreshold.Seconds() {
+		return Frame{}, false
+	}
+
+	return t.take(), true
+}
+
+// Flush returns the frames merged while waiting for the next one, as the last frame of the recording. It reports false
+// when there are none.
+func (t *Throttler) Flush() (Frame, bool) {
+	if !t.pending {
+		return Frame{}, false
+	}
+
+	return t.take(), true
+}
+
+// take returns the merged frames at the adjusted time of the last frame read.
+func (t *Throttler) take() Frame {
+	frame := Frame{Time: t.elapsed, Data: t.merged.String()}
+
+	t.merged.Reset()
+	t.pending = false
+
+	return frame
+}
+
+// FrameThrottler walks through the frames of a recording adjusting their timestamps to a playback speed.
+type FrameThrottler struct {
+	frames []Frame
+	// pos is the position of the next frame to be read.
+	pos       int
+	throttler *Throttler
+}
+
+// NewFrameThrottler creates a [FrameThrottler] to walk through frames, which must be ordered by time.
+func NewFrameThrottler(frames []Frame) *FrameThrottler {
+	return &FrameThrottler{frames: frames, throttler: new(Throttler)}
+}
+
+// Next returns the next frame with its timestamp adjusted to speed, what means the delay between frames is multiplied
+// by 1/speed. When maxGapMS is greater than zero, the adjusted delay is capped to it.
+//
+// When speed is greater than one, frames whose adjusted delay is below [SkipThreshold] are not returned by their own;
+// their data is merged into the next frame returned instead. Next returns [io.EOF] when there are no frames left.
+func (t *FrameThrottler) Next(speed float64, maxGapMS int) (*Frame, error) {
+	if speed < MinSpeed || speed > MaxSpeed {
+		return nil, ErrInvalidSpeed
+	}
+
+	t.throttler.speed, t.throttler.maxGapMS = speed, maxGapMS
+
+	for t.pos < len(t.frames) {
+		frame := t.frames[t.pos]
+		t.pos++
+
+		if adjusted, ok := t.throttler.Push(frame); ok {
+			return &adjusted, nil
+		}
+	}
+
+	if adjusted, ok := t.throttler.Flush(); ok {
+		return &adjusted, nil
+	}
+
+	return nil, io.EOF
+}
@@ -0,0 +1,309 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrameThrottlerNext(t *testing.T) {
+	frames := []Frame{
+		{Time: 0, Data: "a"},
+		{Time: 1, Data: "b"},
+		{Time: 1.02, Data: "c"},
+		{Time: 1.5, Data: "d"},
+		{Time: 11.5, Data: "e"},
+	}
+
+	type Expected struct {
+		frames []Frame
+		err    error
+	}
+
+	cases := []struct {
+		description string
+		speed       float64
+		maxGapMS    int
+		expected    Expected
+	}{
+		{
+			description: "fails when speed is lower than the minimum",
+			speed:       0.05,
+			maxGapMS:    0,
+			expected:    Expected{frames: []Frame{}, err: ErrInvalidSpeed},
+		},
+		{
+			description: "fails when speed is greater than the maximum",
+			speed:       11,
+			maxGapMS:    0,
+			expected:    Expected{frames: []Frame{}, err: ErrInvalidSpeed},
+		},
+		{
+			description: "keeps the original timestamps at normal speed",
+			speed:       1,
+			maxGapMS:    0,
+			expected: Expected{
+				frames: []Frame{
+					{Time: 0, Data: "a"},
+					{Time: 1, Data: "b"},
+					{Time: 1.02, Data: "c"},
+					{Time: 1.5, Data: "d"},
+					{Time: 11.5, Data: "e"},
+				},
+				err: io.EOF,
+			},
+		},
+		{
+			description: "doubles the delays at half speed",
+			speed:       0.5,
+			maxGapMS:    0,
+			expected: Expected{
+				frames: []Frame{
+					{Time: 0, Data: "a"},
+					{Time: 2, Data: "b"},
+					{Time: 2.04, Data: "c"},
+					{Time: 3, Data: "d"},
+					{Time: 23, Data: "e"},
+				},
+				err: io.EOF,
+			},
+		},
+		{
+			description: "halves the delays and merges frames below the threshold at double speed",
+			speed:       2,
+			maxGapMS:    0,
+			expected: Expected{
+				frames: []Frame{
+					{Time: 0, Data: "a"},
+					{Time: 0.5, Data: "b"},
+					{Time: 0.75, Data: "cd"},
+					{Time: 5.75, Data: "e"},
+				},
+				err: io.EOF,
+			},
+		},
+		{
+			description: "caps long pauses to the maximum gap",
+			speed:       1,
+			maxGapMS:    2000,
+			expected: Expected{
+				frames: []Frame{
+					{Time: 0, Data: "a"},
+					{Time: 1, Data: "b"},
+					{Time: 1.02, Data: "c"},
+					{Time: 1.5, Data: "d"},
+					{Time: 3.5, Data: "e"},
+				},
+				err: io.EOF,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			throttler := NewFrameThrottler(frames)
+
+			result := make([]Frame, 0)
+			var err error
+			for {
+				var frame *Frame
+				if frame, err = throttler.Next(tc.speed, tc.maxGapMS); err != nil {
+					break
+				}
+
+				result = append(result, *frame)
+			}
+
+			assert.ErrorIs(t, err, tc.expected.err)
+			assert.Equal(t, len(tc.expected.frames), len(result))
+			for i := range result {
+				assert.InDelta(t, tc.expected.frames[i].Time, result[i].Time, 1e-9)
+				assert.Equal(t, tc.expected.frames[i].Data, result[i].Data)
+			}
+		})
+	}
+}
+
+func TestFromRecordedSession(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	header, frames := FromRecordedSession([]models.RecordedSession{
+		{Message: "a", Time: start, Width: 80, Height: 24},
+		{Message: "b", Time: start.Add(1500 * time.Millisecond)},
+	})
+
+	assert.Equal(t, &Header{Version: 2, Width: 80, Height: 24, Timestamp: start.Unix()}, header)
+	assert.Equal(t, []Frame{{Time: 0, Data: "a"}, {Time: 1.5, Data: "b"}}, frames)
+
+	data, err := json.Marshal(frames[1])
+	assert.NoError(t, err)
+	assert.Equal(t, `[1.5,"o","b"]`, string(data))
+}
+
+func TestHeaderLocalize(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	cases := []struct {
+		description string
+		header      Header
+		loc         *time.Location
+		expected    string
+	}{
+		{
+			description: "keeps the time empty when the recording has no frames",
+			header:      Header{Version: 2},
+			loc:         time.UTC,
+			expected:    "",
+		},
+		{
+			description: "formats the time in UTC",
+			header:      Header{Version: 2, Timestamp: 1672574400},
+			loc:         time.UTC,
+			expected:    "2023-01-01T12:00:00Z",
+		},
+		{
+			description: "formats the time in a non-UTC timezone",
+			header:      Header{Version: 2, Timestamp: 1672574400},
+			loc:         newYork,
+			expected:    "2023-01-01T07:00:00-05:00",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			header := tc.header
+			header.Localize(tc.loc)
+
+			assert.Equal(t, tc.expected, header.Time)
+			assert.Equal(t, tc.header.Timestamp, header.Timestamp)
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		description string
+		records     []models.RecordedSession
+		expected    string
+	}{
+		{
+			description: "writes only the header when there are no frames",
+			records:     []models.RecordedSession{},
+			expected:    `{"version":2,"width":0,"height":0,"timestamp":0}` + "\n",
+		},
+		{
+			description: "writes the header with the dimensions of the first frame that has them",
+			records: []models.RecordedSession{
+				{Message: "a", Time: start},
+				{Message: "b", Time: start.Add(500 * time.Millisecond), Width: 80, Height: 24},
+				{Message: "c", Time: start.Add(1500 * time.Millisecond), Width: 100, Height: 30},
+			},
+			expected: `{"version":2,"width":80,"height":24,"timestamp":1672574400}` + "\n" +
+				`[0,"o","a"]` + "\n" +
+				`[0.5,"o","b"]` + "\n" +
+				`[1.5,"o","c"]` + "\n",
+		},
+		{
+			description: "writes the pending frames on close when no frame has dimensions",
+			records: []models.RecordedSession{
+				{Message: "a", Time: start},
+				{Message: "b", Time: start.Add(time.Second)},
+			},
+			expected: `{"version":2,"width":0,"height":0,"timestamp":1672574400}` + "\n" +
+				`[0,"o","a"]` + "\n" +
+				`[1,"o","b"]` + "\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var buffer bytes.Buffer
+
+			writer := NewWriter(&buffer)
+			for i := range tc.records {
+				assert.NoError(t, writer.WriteFrame(&tc.records[i]))
+			}
+
+			assert.NoError(t, writer.Close())
+			assert.Equal(t, tc.expected, buffer.String())
+		})
+	}
+}
+
+func TestPlaybackWriter(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	records := []models.RecordedSession{
+		{Message: "a", Time: start},
+		{Message: "b", Time: start.Add(time.Second), Width: 80, Height: 24},
+		{Message: "c", Time: start.Add(1020 * time.Millisecond)},
+		{Message: "d", Time: start.Add(1500 * time.Millisecond)},
+		{Message: "e", Time: start.Add(1520 * time.Millisecond)},
+	}
+
+	cases := []struct {
+		description string
+		speed       float64
+		maxGapMS    int
+		loc         *time.Location
+		expected    string
+		err         error
+	}{
+		{
+			description: "fails when speed is out of range",
+			speed:       11,
+			err:         ErrInvalidSpeed,
+		},
+		{
+			description: "keeps the original timestamps at normal speed",
+			speed:       1,
+			loc:         time.UTC,
+			expected: `{"version":2,"width":80,"height":24,"timestamp":1672574400,"time":"2023-01-01T12:00:00Z"}` + "\n" +
+				`[0,"o","a"]` + "\n" +
+				`[1,"o","b"]` + "\n" +
+				`[1.02,"o","c"]` + "\n" +
+				`[1.5,"o","d"]` + "\n" +
+				`[1.52,"o","e"]` + "\n",
+		},
+		{
+			description: "merges the frames below the threshold, writing the last one on close, at double speed",
+			speed:       2,
+			loc:         newYork,
+			expected: `{"version":2,"width":80,"height":24,"timestamp":1672574400,"time":"2023-01-01T07:00:00-05:00"}` + "\n" +
+				`[0,"o","a"]` + "\n" +
+				`[0.5,"o","b"]` + "\n" +
+				`[0.75,"o","cd"]` + "\n" +
+				`[0.76,"o","e"]` + "\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var buffer bytes.Buffer
+
+			writer, err := NewPlaybackWriter(&buffer, tc.speed, tc.maxGapMS, tc.loc)
+			assert.ErrorIs(t, err, tc.err)
+			if err != nil {
+				return
+			}
+
+			for i := range records {
+				assert.NoError(t, writer.WriteFrame(&records[i]))
+			}
+
+			assert.NoError(t, writer.Close())
+			assert.Equal(t, tc.expected, buffer.String())
+		})
+	}
+}
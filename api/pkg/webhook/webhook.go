@@ -0,0 +1,271 @@
+// Package webhook posts the events of the namespaces, like a device being accepted, to the webhooks they configured,
+// signing each one so the receiver can verify it was sent by ShellHub.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// EventHeader is the header carrying the type of the event posted.
+	EventHeader = "X-ShellHub-Event"
+	// DeliveryHeader is the header carrying the ID of the event posted, the same on every attempt to deliver it.
+	DeliveryHeader = "X-ShellHub-Delivery"
+	// SignatureHeader is the header carrying the signature of the body posted, as "sha256=" followed by the hex encoded
+	// HMAC-SHA256 of the body keyed by the webhook's secret.
+	SignatureHeader = "X-ShellHub-Signature"
+)
+
+// Sign returns the signature of body keyed by secret, as sent on [SignatureHeader].
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Config is how the events are delivered to the webhooks.
+type Config struct {
+	// Attempts is the maximum number of times the delivery of an event is attempted, including the first one.
+	Attempts int
+	// Delay is the pause before the first retry, doubled on every next one up to MaxDelay.
+	Delay    time.Duration
+	MaxDelay time.Duration
+	// Timeout is the time limit of each attempt.
+	Timeout time.Duration
+	// QueueSize is the maximum number of events waiting to be delivered. The events dispatched when it's full are
+	// dead-lettered right away.
+	QueueSize int
+	// Workers is the number of events delivered at the same time.
+	Workers int
+	// AllowPrivateNetworks allows posting the events to loopback, private and link-local addresses, what is refused by
+	// default so the namespaces can't reach the instance's internal services through their webhooks.
+	AllowPrivateNetworks bool
+}
+
+var (
+	// ErrAddressNotAllowed is returned when a webhook resolves to a loopback, private or link-local address.
+	ErrAddressNotAllowed = errors.New("webhook address is not allowed")
+	// ErrRedirect is returned when a webhook responds with a redirect, what isn't followed.
+	ErrRedirect = errors.New("webhook redirects are not followed")
+)
+
+// allowedAddress reports whether the events can be posted to the IP, refusing the addresses that aren't publicly
+// routable.
+func allowedAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// newClient creates the HTTP client that posts the events, never following redirects and, unless allowed by cfg,
+// refusing to connect to addresses that aren't publicly routable. The address is checked once resolved, right before
+// connecting, so a webhook's host can't resolve to another address after being checked.
+func newClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil || !allowedAddress(addr.Addr()) {
+				return ErrAddressNotAllowed
+			}
+
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return ErrRedirect
+		},
+	}
+}
+
+// DeadLetter keeps an event that couldn't be delivered, after every attempt failed.
+type DeadLetter func(ctx context.Context, letter *models.WebhookDeadLetter) error
+
+//go:generate mockery --name Dispatcher --filename dispatcher.go
+type Dispatcher interface {
+	// Dispatch enqueues the event to be posted to the webhook, without waiting for its delivery.
+	Dispatch(webhook models.NamespaceWebhook, event models.WebhookEvent)
+}
+
+type delivery struct {
+	webhook models.NamespaceWebhook
+	event   models.WebhookEvent
+}
+
+// HTTPDispatcher is a [Dispatcher] that posts the events from a queue kept in memory, so the events still queued are
+// lost when the API is stopped.
+type HTTPDispatcher struct {
+	cfg        Config
+	client     *http.Client
+	queue      chan delivery
+	deadLetter DeadLetter
+}
+
+var _ Dispatcher = (*HTTPDispatcher)(nil)
+
+// NewDispatcher creates an [HTTPDispatcher] delivering the events as configured by cfg, keeping through deadLetter the
+// ones that couldn't be delivered. The events are only delivered once it's started.
+func NewDispatcher(cfg Config, deadLetter DeadLetter) *HTTPDispatcher {
+	if cfg.Attempts < 1 {
+		cfg.Attempts = 1
+	}
+
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+
+	return &HTTPDispatcher{
+		cfg:        cfg,
+		client:     newClient(cfg),
+		queue:      make(chan delivery, cfg.QueueSize),
+		deadLetter: deadLetter,
+	}
+}
+
+// Start delivers the dispatched events until ctx is done.
+func (d *HTTPDispatcher) Start(ctx context.Context) {
+	for i := 0; i < d.cfg.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery := <-d.queue:
+					d.deliver(ctx, delivery)
+				}
+			}
+		}()
+	}
+}
+
+func (d *HTTPDispatcher) Dispatch(webhook models.NamespaceWebhook, event models.WebhookEvent) {
+	select {
+	case d.queue <- delivery{webhook: webhook, event: event}:
+	default:
+		d.bury(context.Background(), delivery{webhook: webhook, event: event}, 0, fmt.Errorf("the queue of events is full"))
+	}
+}
+
+// deliver posts the event to the webhook until it succeeds or the attempts are exhausted, backing off between them.
+// When every attempt fails, the event is dead-lettered.
+func (d *HTTPDispatcher) deliver(ctx context.Context, delivery delivery) {
+	delay := d.cfg.Delay
+	for attempt := 1; ; attempt++ {
+		err := d.post(ctx, delivery)
+		if err == nil {
+			return
+		}
+
+		if attempt >= d.cfg.Attempts {
+			d.bury(ctx, delivery, attempt, err)
+
+			return
+		}
+
+		log.WithError(err).
+			WithFields(log.Fields{
+				"tenant_id": delivery.event.TenantID,
+				"event":     delivery.event.ID,
+				"attempt":   attempt,
+				"delay":     delay.String(),
+			}).
+			Warn("Failed to post the event to the webhook; retrying.")
+
+		select {
+		case <-ctx.Done():
+			d.bury(context.Background(), delivery, attempt, ctx.Err())
+
+			return
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; d.cfg.MaxDelay > 0 && delay > d.cfg.MaxDelay {
+			delay = d.cfg.MaxDelay
+		}
+	}
+}
+
+// post posts the event, encoded as JSON and signed, to the webhook, failing when it doesn't respond with a 2xx status.
+func (d *HTTPDispatcher) post(ctx context.Context, delivery delivery) error {
+	body, err := json.Marshal(delivery.event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(delivery.event.Type))
+	req.Header.Set(DeliveryHeader, delivery.event.ID)
+	req.Header.Set(SignatureHeader, Sign(delivery.webhook.Secret, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// bury dead-letters the event that couldn't be delivered after attempts, failing with err.
+func (d *HTTPDispatcher) bury(ctx context.Context, delivery delivery, attempts int, err error) {
+	logger := log.WithFields(log.Fields{
+		"tenant_id": delivery.event.TenantID,
+		"event":     delivery.event.ID,
+		"attempts":  attempts,
+	})
+
+	logger.WithError(err).Error("Failed to post the event to the webhook")
+
+	if d.deadLetter == nil {
+		return
+	}
+
+	if err := d.deadLetter(ctx, &models.WebhookDeadLetter{
+		TenantID: delivery.event.TenantID,
+		URL:      delivery.webhook.URL,
+		Event:    delivery.event,
+		Attempts: attempts,
+		Error:    err.Error(),
+	}); err != nil {
+		logger.WithError(err).Error("Failed to dead-letter the event")
+	}
+}
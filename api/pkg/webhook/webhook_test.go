@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, "sha256=dc46983557fea127b43af721467eb9b3fde2338fe3e14f51952aa8478c13d355", Sign("secret", []byte("body")))
+}
+
+func TestDispatcher(t *testing.T) {
+	event := models.WebhookEvent{
+		ID:       "id",
+		Type:     models.WebhookEventDeviceAccepted,
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Data:     map[string]string{"uid": "uid"},
+	}
+
+	t.Run("posts the signed event to the webhook", func(t *testing.T) {
+		received := make(chan *http.Request, 1)
+		bodies := make(chan []byte, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received <- r
+			bodies <- body
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		dispatcher := NewDispatcher(Config{Attempts: 1, Timeout: time.Second, QueueSize: 1, AllowPrivateNetworks: true}, nil)
+		dispatcher.Start(ctx)
+		dispatcher.Dispatch(models.NamespaceWebhook{URL: server.URL, Secret: "secret"}, event)
+
+		select {
+		case r := <-received:
+			body := <-bodies
+
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, string(models.WebhookEventDeviceAccepted), r.Header.Get(EventHeader))
+			assert.Equal(t, "id", r.Header.Get(DeliveryHeader))
+			assert.Equal(t, Sign("secret", body), r.Header.Get(SignatureHeader))
+
+			var posted models.WebhookEvent
+			require.NoError(t, json.Unmarshal(body, &posted))
+			assert.Equal(t, event, posted)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the event wasn't posted")
+		}
+	})
+
+	t.Run("dead-letters the event after every attempt fails", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		letters := make(chan *models.WebhookDeadLetter, 1)
+		dispatcher := NewDispatcher(Config{Attempts: 3, Delay: time.Millisecond, MaxDelay: 2 * time.Millisecond, Timeout: time.Second, QueueSize: 1, AllowPrivateNetworks: true}, func(_ context.Context, letter *models.WebhookDeadLetter) error {
+			letters <- letter
+
+			return nil
+		})
+		dispatcher.Start(ctx)
+		dispatcher.Dispatch(models.NamespaceWebhook{URL: server.URL, Secret: "secret"}, event)
+
+		select {
+		case letter := <-letters:
+			assert.Equal(t, int32(3), attempts.Load())
+			assert.Equal(t, event.TenantID, letter.TenantID)
+			assert.Equal(t, server.URL, letter.URL)
+			assert.Equal(t, event, letter.Event)
+			assert.Equal(t, 3, letter.Attempts)
+			assert.Equal(t, "webhook responded with status 500", letter.Error)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the event wasn't dead-lettered")
+		}
+	})
+
+	t.Run("dead-letters the event when the queue is full", func(t *testing.T) {
+		letters := make(chan *models.WebhookDeadLetter, 1)
+		dispatcher := NewDispatcher(Config{Attempts: 1, QueueSize: 1}, func(_ context.Context, letter *models.WebhookDeadLetter) error {
+			letters <- letter
+
+			return nil
+		})
+
+		// As the dispatcher isn't started, the first event stays on the queue.
+		dispatcher.Dispatch(models.NamespaceWebhook{URL: "http://localhost"}, event)
+		dispatcher.Dispatch(models.NamespaceWebhook{URL: "http://localhost"}, event)
+
+		letter := <-letters
+		assert.Equal(t, 0, letter.Attempts)
+		assert.Equal(t, "the queue of events is full", letter.Error)
+	})
+
+	t.Run("refuses to post to a private address", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			attempts.Add(1)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		letters := make(chan *models.WebhookDeadLetter, 1)
+		dispatcher := NewDispatcher(Config{Attempts: 1, Timeout: time.Second, QueueSize: 1}, func(_ context.Context, letter *models.WebhookDeadLetter) error {
+			letters <- letter
+
+			return nil
+		})
+		dispatcher.Start(ctx)
+		dispatcher.Dispatch(models.NamespaceWebhook{URL: server.URL, Secret: "secret"}, event)
+
+		select {
+		case letter := <-letters:
+			assert.Equal(t, int32(0), attempts.Load())
+			assert.Contains(t, letter.Error, ErrAddressNotAllowed.Error())
+		case <-time.After(5 * time.Second):
+			t.Fatal("the event wasn't dead-lettered")
+		}
+	})
+
+	t.Run("doesn't follow redirects", func(t *testing.T) {
+		var followed atomic.Int32
+		target := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			followed.Add(1)
+		}))
+		defer target.Close()
+
+		server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		letters := make(chan *models.WebhookDeadLetter, 1)
+		dispatcher := NewDispatcher(Config{Attempts: 1, Timeout: time.Second, QueueSize: 1, AllowPrivateNetworks: true}, func(_ context.Context, letter *models.WebhookDeadLetter) error {
+			letters <- letter
+
+			return nil
+		})
+		dispatcher.Start(ctx)
+		dispatcher.Dispatch(models.NamespaceWebhook{URL: server.URL, Secret: "secret"}, event)
+
+		select {
+		case letter := <-letters:
+			assert.Equal(t, int32(0), followed.Load())
+			assert.Contains(t, letter.Error, ErrRedirect.Error())
+		case <-time.After(5 * time.Second):
+			t.Fatal("the event wasn't dead-lettered")
+		}
+	})
+}
+
+func TestAllowedAddress(t *testing.T) {
+	cases := []struct {
+		address  string
+		expected bool
+	}{
+		{address: "203.0.113.7", expected: true},
+		{address: "2001:db8::1", expected: true},
+		{address: "127.0.0.1", expected: false},
+		{address: "::1", expected: false},
+		{address: "10.0.0.1", expected: false},
+		{address: "172.16.0.1", expected: false},
+		{address: "192.168.0.1", expected: false},
+		{address: "fd00::1", expected: false},
+		{address: "169.254.169.254", expected: false},
+		{address: "fe80::1", expected: false},
+		{address: "0.0.0.0", expected: false},
+		{address: "::ffff:127.0.0.1", expected: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.address, func(t *testing.T) {
+			assert.Equal(t, tc.expected, allowedAddress(netip.MustParseAddr(tc.address)))
+		})
+	}
+}
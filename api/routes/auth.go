@@ -9,6 +9,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/mitchellh/mapstructure"
+	echomiddleware "github.com/shellhub-io/shellhub/api/pkg/echo/middleware"
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
 	errs "github.com/shellhub-io/shellhub/api/routes/errors"
 	svc "github.com/shellhub-io/shellhub/api/services"
@@ -53,6 +54,11 @@ func (h *Handler) AuthRequest(c gateway.Context) error {
 		c.Response().Header().Set("X-Tenant-ID", apiKey.TenantID)
 		c.Response().Header().Set("X-Role", apiKey.Role)
 		c.Response().Header().Set("X-API-KEY", key)
+		if len(apiKey.Scopes) > 0 {
+			c.Response().Header().Set(echomiddleware.APIKeyScopesHeader, echomiddleware.EncodeScopes(apiKey.Scopes))
+		}
+
+		h.service.RecordNamespaceActivity(c.Ctx(), apiKey.TenantID)
 
 		return c.NoContent(http.StatusOK)
 	}
@@ -108,12 +114,30 @@ func (h *Handler) AuthRequest(c gateway.Context) error {
 			}
 		}
 
+		// A token bound to a user session is rejected as soon as the session is revoked.
+		if claims.Session != "" {
+			if ok, err := h.service.AuthUserSession(c.Ctx(), claims.ID, claims.Session); err != nil || !ok {
+				return svc.NewErrAuthUnathorized(err)
+			}
+		}
+
+		// A token of a deleted user is rejected, even when it isn't bound to a user session.
+		if ok, err := h.service.AuthUserActive(c.Ctx(), claims.ID); err != nil || !ok {
+			return svc.NewErrAuthUnathorized(err)
+		}
+
 		// Extract datas of user from JWT
+		c.Response().Header().Set("X-Session-ID", claims.Session)
 		c.Response().Header().Set("X-Tenant-ID", claims.Tenant)
 		c.Response().Header().Set("X-Username", claims.Username)
 		c.Response().Header().Set("X-ID", claims.ID)
 		c.Response().Header().Set("X-Role", claims.Role)
 
+		// An authenticated request keeps the namespace from expiring due inactivity.
+		if claims.Tenant != "" {
+			h.service.RecordNamespaceActivity(c.Ctx(), claims.Tenant)
+		}
+
 		return c.NoContent(http.StatusOK)
 	case AuthRequestDeviceToken:
 		var claims models.DeviceAuthClaims
@@ -163,6 +187,8 @@ func (h *Handler) AuthUser(c gateway.Context) error {
 		return err
 	}
 
+	req.UserAgent = c.Request().UserAgent()
+
 	res, lockout, mfaToken, err := h.service.AuthUser(c.Ctx(), req, c.RealIP())
 	c.Response().Header().Set("X-Account-Lockout", strconv.FormatInt(lockout, 10))
 	c.Response().Header().Set("X-MFA-Token", mfaToken)
@@ -211,7 +237,7 @@ func (h *Handler) AuthGetToken(c gateway.Context) error {
 		return err
 	}
 
-	res, err := h.service.AuthGetToken(c.Ctx(), req.ID)
+	res, err := h.service.AuthGetToken(c.Ctx(), req.ID, c.RealIP(), c.Request().UserAgent())
 	if err != nil {
 		return err
 	}
@@ -233,7 +259,7 @@ func (h *Handler) AuthSwapToken(c gateway.Context) error {
 		id = v.ID
 	}
 
-	res, err := h.service.AuthSwapToken(c.Ctx(), id, req.Tenant)
+	res, err := h.service.AuthSwapToken(c.Ctx(), id, req.Tenant, c.Request().Header.Get("X-Session-ID"))
 	if err != nil {
 		return err
 	}
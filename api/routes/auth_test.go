@@ -50,7 +50,7 @@ func TestAuthGetToken(t *testing.T) {
 			title: "success when trying to get a token",
 			id:    requests.AuthTokenGet{UserParam: requests.UserParam{ID: "id"}},
 			requiredMocks: func() {
-				mock.On("AuthGetToken", gomock.Anything, "id").Return(&models.UserAuthResponse{}, nil).Once()
+				mock.On("AuthGetToken", gomock.Anything, "id", gomock.Anything, "Mozilla/5.0").Return(&models.UserAuthResponse{}, nil).Once()
 			},
 			expected: Expected{
 				expectedSession: &models.UserAuthResponse{},
@@ -72,6 +72,7 @@ func TestAuthGetToken(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Set("X-Role", guard.RoleOwner)
 			req.Header.Set("X-ID", string(jsonData))
+			req.Header.Set("User-Agent", "Mozilla/5.0")
 			rec := httptest.NewRecorder()
 
 			e := NewRouter(mock)
@@ -514,7 +515,7 @@ func TestAuthSwapToken(t *testing.T) {
 			title:       "success when try to swap token",
 			requestBody: "00000000-0000-4000-0000-000000000000",
 			requiredMocks: func() {
-				mock.On("AuthSwapToken", gomock.Anything, "id", "00000000-0000-4000-0000-000000000000").Return(&models.UserAuthResponse{}, nil).Once()
+				mock.On("AuthSwapToken", gomock.Anything, "id", "00000000-0000-4000-0000-000000000000", "").Return(&models.UserAuthResponse{}, nil).Once()
 			},
 			expected: Expected{
 				expectedResponse: &models.UserAuthResponse{},
@@ -666,6 +667,8 @@ func TestAuthRequest(t *testing.T) {
 			requiredMocks: func() {
 				mock.On("PublicKey").Return(&privateKey.PublicKey).Once()
 				mock.On("AuthIsCacheToken", gomock.Anything, "tenant", "id").Return(true, nil).Once()
+				mock.On("AuthUserActive", gomock.Anything, "id").Return(true, nil).Once()
+				mock.On("RecordNamespaceActivity", gomock.Anything, "tenant").Once()
 				mock.On("GetAPIKeyByUID", gomock.Anything, "").Return(&models.APIKey{
 					TenantID: "tenant",
 				}, nil).Once()
@@ -675,6 +678,17 @@ func TestAuthRequest(t *testing.T) {
 				expectedStatus: http.StatusOK,
 			},
 		},
+		{
+			title: "fails when the user was deleted",
+			requiredMocks: func() {
+				mock.On("PublicKey").Return(&privateKey.PublicKey).Once()
+				mock.On("AuthIsCacheToken", gomock.Anything, "tenant", "id").Return(true, nil).Once()
+				mock.On("AuthUserActive", gomock.Anything, "id").Return(false, nil).Once()
+			},
+			expected: Expected{
+				expectedStatus: http.StatusUnauthorized,
+			},
+		},
 		{
 			title: "fails when token dont have cache",
 			requiredMocks: func() {
@@ -709,3 +723,97 @@ func TestAuthRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthRequestWithUserSession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, models.UserAuthClaims{
+		Username: "username",
+		Tenant:   "tenant",
+		Role:     "role",
+		ID:       "id",
+		Session:  "session",
+		AuthClaims: models.AuthClaims{
+			Claims: "user",
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(clock.Now().Add(time.Hour * 72)),
+		},
+	})
+
+	cases := []struct {
+		title           string
+		requiredMocks   func()
+		expectedStatus  int
+		expectedSession string
+	}{
+		{
+			title: "fails when the session was revoked",
+			requiredMocks: func() {
+				mock.On("PublicKey").Return(&privateKey.PublicKey).Once()
+				mock.On("AuthIsCacheToken", gomock.Anything, "tenant", "id").Return(true, nil).Once()
+				mock.On("AuthUserSession", gomock.Anything, "id", "session").Return(false, nil).Once()
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			title: "succeeds when the session is active",
+			requiredMocks: func() {
+				mock.On("PublicKey").Return(&privateKey.PublicKey).Once()
+				mock.On("AuthIsCacheToken", gomock.Anything, "tenant", "id").Return(true, nil).Once()
+				mock.On("AuthUserSession", gomock.Anything, "id", "session").Return(true, nil).Once()
+				mock.On("AuthUserActive", gomock.Anything, "id").Return(true, nil).Once()
+				mock.On("RecordNamespaceActivity", gomock.Anything, "tenant").Once()
+			},
+			expectedStatus:  http.StatusOK,
+			expectedSession: "session",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/auth", nil)
+
+			tokenStr, err := token.SignedString(privateKey)
+			assert.NoError(t, err)
+
+			req.Header.Add("Authorization", "Bearer "+tokenStr)
+
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			assert.Equal(t, tc.expectedSession, rec.Result().Header.Get("X-Session-ID"))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthRequestWithAPIKey(t *testing.T) {
+	mock := new(mocks.Service)
+
+	mock.On("AuthAPIKey", gomock.Anything, "key").
+		Return(&models.APIKey{TenantID: "00000000-0000-4000-0000-000000000000", Role: guard.RoleAdministrator}, nil).
+		Once()
+	mock.On("RecordNamespaceActivity", gomock.Anything, "00000000-0000-4000-0000-000000000000").Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/internal/auth", nil)
+	req.Header.Set("X-API-Key", "key")
+	rec := httptest.NewRecorder()
+
+	e := NewRouter(mock)
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.Equal(t, "00000000-0000-4000-0000-000000000000", rec.Result().Header.Get("X-Tenant-ID"))
+
+	mock.AssertExpectations(t)
+}
@@ -3,27 +3,42 @@ package routes
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
 	"github.com/shellhub-io/shellhub/pkg/models"
 )
 
 const (
-	GetDeviceListURL            = "/devices"
-	GetDeviceURL                = "/devices/:uid"
-	GetDeviceByPublicURLAddress = "/devices/public/:address"
-	DeleteDeviceURL             = "/devices/:uid"
-	RenameDeviceURL             = "/devices/:uid"
-	OfflineDeviceURL            = "/devices/:uid/offline"
-	LookupDeviceURL             = "/lookup"
-	UpdateDeviceStatusURL       = "/devices/:uid/:status"
-	CreateTagURL                = "/devices/:uid/tags"      // Add a tag to a device.
-	UpdateTagURL                = "/devices/:uid/tags"      // Update device's tags with a new set.
-	RemoveTagURL                = "/devices/:uid/tags/:tag" // Delete a tag from a device.
-	UpdateDevice                = "/devices/:uid"
+	GetDeviceListURL               = "/devices"
+	GetDeviceURL                   = "/devices/:uid"
+	GetDeviceByPublicURLAddress    = "/devices/public/:address"
+	DeleteDeviceURL                = "/devices/:uid"
+	RenameDeviceURL                = "/devices/:uid"
+	OfflineDeviceURL               = "/devices/:uid/offline"
+	LookupDeviceURL                = "/lookup"
+	UpdateDeviceStatusURL          = "/devices/:uid/:status"
+	CreateTagURL                   = "/devices/:uid/tags"      // Add a tag to a device.
+	UpdateTagURL                   = "/devices/:uid/tags"      // Update device's tags with a new set.
+	RemoveTagURL                   = "/devices/:uid/tags/:tag" // Delete a tag from a device.
+	UpdateDevice                   = "/devices/:uid"
+	PingDeviceURL                  = "/devices/:uid/ping"
+	CleanupConnectorDevicesURL     = "/devices/connector"
+	TrustDeviceHostKeyURL          = "/devices/:uid/trust-key"
+	SetDeviceSessionPolicyURL      = "/devices/:uid/session-policy"
+	DeleteDeviceSessionPolicyURL   = "/devices/:uid/session-policy"
+	UpdateDeviceAllowedCommandsURL = "/devices/:uid/allowed-commands"
+	ListDeviceEventsURL            = "/devices/:uid/events"
+	ListDeviceConnectionsURL       = "/devices/:uid/connections"
+	UpdateDeviceExpiryURL          = "/devices/:uid/expiry"
+	CheckDeviceExpiryURL           = "/devices/:uid/expiry"
+	ListPendingDevicesURL          = "/devices/pending"
+	BulkAcceptDevicesURL           = "/devices/bulk/accept"
+	BulkRejectDevicesURL           = "/devices/bulk/reject"
 )
 
 const (
@@ -32,6 +47,9 @@ const (
 	ParamTagName      = "name"
 )
 
+// DefaultDevicePingTimeout is the timeout used to ping a device when the request doesn't inform one.
+const DefaultDevicePingTimeout = 5 * time.Second
+
 func (h *Handler) GetDeviceList(c gateway.Context) error {
 	type Query struct {
 		Status models.DeviceStatus `query:"status"`
@@ -138,6 +156,37 @@ func (h *Handler) DeleteDevice(c gateway.Context) error {
 	return c.NoContent(http.StatusOK)
 }
 
+// CleanupConnectorDevices removes the offline devices created by connectors on the namespace, responding with the
+// removed devices. With the dry_run query param, it only responds with the devices that would be removed.
+func (h *Handler) CleanupConnectorDevices(c gateway.Context) error {
+	var req requests.DeviceCleanupConnector
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var devices []models.Device
+	err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Remove, func() error {
+		var err error
+		devices, err = h.service.CleanupConnectorDevices(c.Ctx(), tenant, req.DryRun)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, devices)
+}
+
 func (h *Handler) RenameDevice(c gateway.Context) error {
 	var req requests.DeviceRename
 	if err := c.Bind(&req); err != nil {
@@ -316,3 +365,340 @@ func (h *Handler) UpdateDevice(c gateway.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+func (h *Handler) PingDevice(c gateway.Context) error {
+	var req requests.DevicePing
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	timeout := DefaultDevicePingTimeout
+	if req.TimeoutMS > 0 {
+		timeout = time.Duration(req.TimeoutMS) * time.Millisecond
+	}
+
+	var result *models.DevicePingResult
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Connect, func() error {
+		var err error
+		result, err = h.service.PingDevice(c.Ctx(), req.UID, tenant, timeout)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) TrustDeviceHostKey(c gateway.Context) error {
+	var req requests.DeviceTrustHostKey
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Update, func() error {
+		return h.service.PreShareDeviceHostKey(c.Ctx(), req.UID, tenant, req.PublicKey)
+	}); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) SetDeviceSessionPolicy(c gateway.Context) error {
+	var req requests.DeviceSessionPolicySet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Update, func() error {
+		return h.service.SetDeviceSessionPolicy(c.Ctx(), req.UID, tenant, &req.DeviceSessionPolicy)
+	}); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) DeleteDeviceSessionPolicy(c gateway.Context) error {
+	var req requests.DeviceSessionPolicyDelete
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Update, func() error {
+		return h.service.SetDeviceSessionPolicy(c.Ctx(), req.UID, tenant, nil)
+	}); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) UpdateDeviceAllowedCommands(c gateway.Context) error {
+	var req requests.DeviceAllowedCommandsUpdate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Update, func() error {
+		return h.service.UpdateDeviceAllowedCommands(c.Ctx(), req.UID, tenant, req.AllowedCommands)
+	}); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) UpdateDeviceExpiry(c gateway.Context) error {
+	var req requests.DeviceExpiryUpdate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Update, func() error {
+		return h.service.SetDeviceExpiry(c.Ctx(), req.UID, tenant, req.ExpiresAt)
+	}); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// CheckDeviceExpiry responds with 200 when the device's access to its namespace hasn't ended, and with 403 when it
+// has.
+func (h *Handler) CheckDeviceExpiry(c gateway.Context) error {
+	var req requests.DeviceExpiryCheck
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.service.CheckDeviceExpiry(c.Ctx(), models.UID(req.UID)); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// ListDeviceEvents responds with the timeline of a device, from its newest to its oldest lifecycle event.
+func (h *Handler) ListDeviceEvents(c gateway.Context) error {
+	var req requests.DeviceEventsList
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	req.Paginator.Normalize()
+	req.Sorter.Normalize()
+
+	if err := req.Filters.Unmarshal(); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var events []models.DeviceEvent
+	var count int
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Details, func() error {
+		var err error
+		events, count, err = h.service.ListDeviceEvents(c.Ctx(), tenant, models.UID(req.UID), req.Paginator, req.Filters, req.Sorter)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(count))
+
+	return c.JSON(http.StatusOK, events)
+}
+
+// ListDeviceConnections responds with the connection history of a device, from its most recent to its oldest
+// connection.
+func (h *Handler) ListDeviceConnections(c gateway.Context) error {
+	var req requests.DeviceConnectionsList
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	req.Paginator.Normalize()
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var connections []models.DeviceConnection
+	var count int
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Details, func() error {
+		var err error
+		connections, count, err = h.service.ListDeviceConnections(c.Ctx(), tenant, models.UID(req.UID), req.Paginator)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(count))
+
+	return c.JSON(http.StatusOK, connections)
+}
+
+// ListPendingDevices lists the devices of the namespace awaiting acceptance, the oldest ones first.
+func (h *Handler) ListPendingDevices(c gateway.Context) error {
+	var req query.Paginator
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	req.Normalize()
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	devices, count, err := h.service.ListPendingDevices(c.Ctx(), tenant, req)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(count))
+
+	return c.JSON(http.StatusOK, devices)
+}
+
+// BulkAcceptDevices accepts a batch of devices, responding with the result of each one.
+func (h *Handler) BulkAcceptDevices(c gateway.Context) error {
+	var req requests.DeviceBulkUpdateStatus
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var results []responses.DeviceBulkResult
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Accept, func() error {
+		var err error
+		results, err = h.service.BulkAcceptDevices(c.Ctx(), tenant, deviceUIDs(req.UIDs))
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
+
+// BulkRejectDevices rejects a batch of devices, responding with the result of each one.
+func (h *Handler) BulkRejectDevices(c gateway.Context) error {
+	var req requests.DeviceBulkUpdateStatus
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var results []responses.DeviceBulkResult
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Reject, func() error {
+		var err error
+		results, err = h.service.BulkRejectDevices(c.Ctx(), tenant, deviceUIDs(req.UIDs))
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
+
+func deviceUIDs(uids []string) []models.UID {
+	converted := make([]models.UID, 0, len(uids))
+	for _, uid := range uids {
+		converted = append(converted, models.UID(uid))
+	}
+
+	return converted
+}
@@ -0,0 +1,183 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+const (
+	CreateDeviceGroupURL = "/device-groups"
+	GetDeviceGroupURL    = "/device-groups/:uid"
+	UpdateDeviceGroupURL = "/device-groups/:uid"
+
+	ListDeviceGroupDevicesURL = "/device-groups/:uid/devices"
+	AddDeviceToGroupURL       = "/device-groups/:uid/devices/:device"
+	RemoveDeviceFromGroupURL  = "/device-groups/:uid/devices/:device"
+)
+
+func (h *Handler) CreateDeviceGroup(c gateway.Context) error {
+	var req requests.DeviceGroupCreate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var group *models.DeviceGroup
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.CreateGroup, func() error {
+		var err error
+		group, err = h.service.CreateDeviceGroup(c.Ctx(), tenant, &req)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, group)
+}
+
+func (h *Handler) GetDeviceGroup(c gateway.Context) error {
+	var req requests.DeviceGroupGet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	group, err := h.service.GetDeviceGroup(c.Ctx(), tenant, req.UID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, group)
+}
+
+func (h *Handler) UpdateDeviceGroup(c gateway.Context) error {
+	var req requests.DeviceGroupUpdate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var group *models.DeviceGroup
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.UpdateGroup, func() error {
+		var err error
+		group, err = h.service.UpdateDeviceGroup(c.Ctx(), tenant, &req)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, group)
+}
+
+func (h *Handler) AddDeviceToGroup(c gateway.Context) error {
+	var req requests.DeviceGroupDevice
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var group *models.DeviceGroup
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.UpdateGroup, func() error {
+		var err error
+		group, err = h.service.AddDeviceToGroup(c.Ctx(), tenant, &req)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, group)
+}
+
+func (h *Handler) RemoveDeviceFromGroup(c gateway.Context) error {
+	var req requests.DeviceGroupDevice
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var group *models.DeviceGroup
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.UpdateGroup, func() error {
+		var err error
+		group, err = h.service.RemoveDeviceFromGroup(c.Ctx(), tenant, &req)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, group)
+}
+
+func (h *Handler) ListDeviceGroupDevices(c gateway.Context) error {
+	var req requests.DeviceGroupDevicesList
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	req.Paginator.Normalize()
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	devices, count, err := h.service.ListDeviceGroupDevices(c.Ctx(), tenant, &req)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(count))
+
+	return c.JSON(http.StatusOK, devices)
+}
@@ -0,0 +1,369 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestCreateDeviceGroup(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		role           string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when role is observer",
+			role:           guard.RoleObserver,
+			body:           `{"name": "servers"}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description:    "fails when the name is missing",
+			role:           guard.RoleOwner,
+			body:           `{"devices": ["device"]}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "fails when the devices are duplicated",
+			role:           guard.RoleOwner,
+			body:           `{"name": "servers", "devices": ["device", "device"]}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when a device is not found",
+			role:        guard.RoleOwner,
+			body:        `{"name": "servers", "devices": ["device"]}`,
+			requiredMocks: func() {
+				mock.
+					On("CreateDeviceGroup", gomock.Anything, "tenant-id", &requests.DeviceGroupCreate{Name: "servers", Devices: []string{"device"}}).
+					Return(nil, svc.ErrDeviceNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to create the group",
+			role:        guard.RoleOperator,
+			body:        `{"name": "servers", "devices": ["device"]}`,
+			requiredMocks: func() {
+				mock.
+					On("CreateDeviceGroup", gomock.Anything, "tenant-id", &requests.DeviceGroupCreate{Name: "servers", Devices: []string{"device"}}).
+					Return(&models.DeviceGroup{UID: "group", Name: "servers", Devices: []string{"device"}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/device-groups", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestGetDeviceGroup(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description: "fails when the group is not found",
+			requiredMocks: func() {
+				mock.On("GetDeviceGroup", gomock.Anything, "tenant-id", "group").Return(nil, svc.ErrDeviceGroupNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to get the group",
+			requiredMocks: func() {
+				mock.On("GetDeviceGroup", gomock.Anything, "tenant-id", "group").Return(&models.DeviceGroup{UID: "group"}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/device-groups/group", nil)
+			req.Header.Set("X-Role", guard.RoleObserver)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestUpdateDeviceGroup(t *testing.T) {
+	mock := new(mocks.Service)
+
+	name := "databases"
+
+	cases := []struct {
+		description    string
+		role           string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when role is observer",
+			role:           guard.RoleObserver,
+			body:           `{"name": "databases"}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description:    "fails when the name is too short",
+			role:           guard.RoleOwner,
+			body:           `{"name": "db"}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when there is nothing to update",
+			role:        guard.RoleOwner,
+			body:        `{}`,
+			requiredMocks: func() {
+				mock.
+					On("UpdateDeviceGroup", gomock.Anything, "tenant-id", &requests.DeviceGroupUpdate{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}}).
+					Return(nil, svc.ErrDeviceGroupInvalid).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "succeeds to update the group",
+			role:        guard.RoleOwner,
+			body:        `{"name": "databases", "devices": []}`,
+			requiredMocks: func() {
+				mock.
+					On("UpdateDeviceGroup", gomock.Anything, "tenant-id", &requests.DeviceGroupUpdate{
+						DeviceGroupParam: requests.DeviceGroupParam{UID: "group"},
+						Name:             &name,
+						Devices:          []string{},
+					}).
+					Return(&models.DeviceGroup{UID: "group", Name: name, Devices: []string{}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/device-groups/group", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAddDeviceToGroup(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		role           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when role is observer",
+			role:           guard.RoleObserver,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when the group is not found",
+			role:        guard.RoleOwner,
+			requiredMocks: func() {
+				mock.
+					On("AddDeviceToGroup", gomock.Anything, "tenant-id", &requests.DeviceGroupDevice{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}, Device: "device"}).
+					Return(nil, svc.ErrDeviceGroupNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to add the device",
+			role:        guard.RoleOperator,
+			requiredMocks: func() {
+				mock.
+					On("AddDeviceToGroup", gomock.Anything, "tenant-id", &requests.DeviceGroupDevice{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}, Device: "device"}).
+					Return(&models.DeviceGroup{UID: "group", Devices: []string{"device"}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/device-groups/group/devices/device", nil)
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestRemoveDeviceFromGroup(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		role           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when role is observer",
+			role:           guard.RoleObserver,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds to remove the device",
+			role:        guard.RoleOperator,
+			requiredMocks: func() {
+				mock.
+					On("RemoveDeviceFromGroup", gomock.Anything, "tenant-id", &requests.DeviceGroupDevice{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}, Device: "device"}).
+					Return(&models.DeviceGroup{UID: "group", Devices: []string{}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/device-groups/group/devices/device", nil)
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListDeviceGroupDevices(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		requiredMocks  func()
+		expectedStatus int
+		expectedCount  string
+	}{
+		{
+			description: "fails when the group is not found",
+			requiredMocks: func() {
+				mock.
+					On("ListDeviceGroupDevices", gomock.Anything, "tenant-id", gomock.AnythingOfType("*requests.DeviceGroupDevicesList")).
+					Return(nil, 0, svc.ErrDeviceGroupNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to list the group's devices",
+			requiredMocks: func() {
+				mock.
+					On("ListDeviceGroupDevices", gomock.Anything, "tenant-id", gomock.AnythingOfType("*requests.DeviceGroupDevicesList")).
+					Return([]models.Device{{UID: "device"}}, 1, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  "1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/device-groups/group/devices?page=1&per_page=10", nil)
+			req.Header.Set("X-Role", guard.RoleObserver)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			assert.Equal(t, tc.expectedCount, rec.Result().Header.Get("X-Total-Count"))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	svc "github.com/shellhub-io/shellhub/api/services"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/shellhub-io/shellhub/api/services/mocks"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
 	gomock "github.com/stretchr/testify/mock"
@@ -952,3 +954,813 @@ func TestUpdateDevice(t *testing.T) {
 		})
 	}
 }
+
+func TestPingDevice(t *testing.T) {
+	mock := new(mocks.Service)
+
+	type Expected struct {
+		result *models.DevicePingResult
+		status int
+	}
+
+	cases := []struct {
+		description   string
+		uid           string
+		role          string
+		body          string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description:   "fails when role is invalid",
+			uid:           "1234",
+			role:          "invalid",
+			body:          `{"timeout_ms": 5000}`,
+			requiredMocks: func() {},
+			expected:      Expected{status: http.StatusForbidden},
+		},
+		{
+			description:   "fails when timeout is greater than the maximum allowed",
+			uid:           "1234",
+			role:          guard.RoleOwner,
+			body:          `{"timeout_ms": 60000}`,
+			requiredMocks: func() {},
+			expected:      Expected{status: http.StatusBadRequest},
+		},
+		{
+			description: "fails when device is not found",
+			uid:         "1234",
+			role:        guard.RoleOwner,
+			body:        `{"timeout_ms": 5000}`,
+			requiredMocks: func() {
+				mock.
+					On("PingDevice", gomock.Anything, "1234", "tenant-id", 5*time.Second).
+					Return(nil, svc.ErrNotFound).
+					Once()
+			},
+			expected: Expected{status: http.StatusNotFound},
+		},
+		{
+			description: "succeeds with the default timeout when none is informed",
+			uid:         "1234",
+			role:        guard.RoleOperator,
+			body:        `{}`,
+			requiredMocks: func() {
+				mock.
+					On("PingDevice", gomock.Anything, "1234", "tenant-id", DefaultDevicePingTimeout).
+					Return(&models.DevicePingResult{Reachable: true, LatencyMS: 10}, nil).
+					Once()
+			},
+			expected: Expected{
+				result: &models.DevicePingResult{Reachable: true, LatencyMS: 10},
+				status: http.StatusOK,
+			},
+		},
+		{
+			description: "succeeds when device does not answer before the timeout",
+			uid:         "1234",
+			role:        guard.RoleOwner,
+			body:        `{"timeout_ms": 100}`,
+			requiredMocks: func() {
+				mock.
+					On("PingDevice", gomock.Anything, "1234", "tenant-id", 100*time.Millisecond).
+					Return(&models.DevicePingResult{Reachable: false, Error: "context deadline exceeded"}, nil).
+					Once()
+			},
+			expected: Expected{
+				result: &models.DevicePingResult{Reachable: false, Error: "context deadline exceeded"},
+				status: http.StatusOK,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/devices/%s/ping", tc.uid), strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Result().StatusCode)
+
+			if tc.expected.result != nil {
+				var result *models.DevicePingResult
+				assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&result))
+				assert.Equal(t, tc.expected.result, result)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestCleanupConnectorDevices(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		query          string
+		role           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when role is invalid",
+			query:          "",
+			role:           "invalid",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when namespace is not found",
+			query:       "",
+			role:        guard.RoleOwner,
+			requiredMocks: func() {
+				mock.On("CleanupConnectorDevices", gomock.Anything, "tenant-id", false).Return(nil, svc.ErrNamespaceNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds on dry run",
+			query:       "?dry_run=true",
+			role:        guard.RoleAdministrator,
+			requiredMocks: func() {
+				mock.On("CleanupConnectorDevices", gomock.Anything, "tenant-id", true).Return([]models.Device{{UID: "uid"}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			description: "succeeds",
+			query:       "",
+			role:        guard.RoleOwner,
+			requiredMocks: func() {
+				mock.On("CleanupConnectorDevices", gomock.Anything, "tenant-id", false).Return([]models.Device{{UID: "uid"}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/devices/connector"+tc.query, nil)
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestTrustDeviceHostKey(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		uid            string
+		role           string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when role is invalid",
+			uid:            "1234",
+			role:           "invalid",
+			body:           `{"public_key": "key"}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description:    "fails when role is observer",
+			uid:            "1234",
+			role:           guard.RoleObserver,
+			body:           `{"public_key": "key"}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description:    "fails when the public key is missing",
+			uid:            "1234",
+			role:           guard.RoleOwner,
+			body:           `{}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when the public key is invalid",
+			uid:         "1234",
+			role:        guard.RoleOwner,
+			body:        `{"public_key": "key"}`,
+			requiredMocks: func() {
+				mock.
+					On("PreShareDeviceHostKey", gomock.Anything, "1234", "tenant-id", "key").
+					Return(svc.ErrDeviceHostKeyInvalid).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when device is not found",
+			uid:         "1234",
+			role:        guard.RoleOwner,
+			body:        `{"public_key": "key"}`,
+			requiredMocks: func() {
+				mock.
+					On("PreShareDeviceHostKey", gomock.Anything, "1234", "tenant-id", "key").
+					Return(svc.ErrDeviceNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to trust the host key",
+			uid:         "1234",
+			role:        guard.RoleOperator,
+			body:        `{"public_key": "key"}`,
+			requiredMocks: func() {
+				mock.
+					On("PreShareDeviceHostKey", gomock.Anything, "1234", "tenant-id", "key").
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/devices/%s/trust-key", tc.uid), strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSetDeviceSessionPolicy(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		method         string
+		role           string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when role is observer",
+			method:         http.MethodPut,
+			role:           guard.RoleObserver,
+			body:           `{"max_idle": 300}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description:    "fails when max idle is negative",
+			method:         http.MethodPut,
+			role:           guard.RoleOwner,
+			body:           `{"max_idle": -1}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when device is not found",
+			method:      http.MethodPut,
+			role:        guard.RoleOwner,
+			body:        `{"max_idle": 300}`,
+			requiredMocks: func() {
+				mock.
+					On("SetDeviceSessionPolicy", gomock.Anything, "1234", "tenant-id", &models.DeviceSessionPolicy{MaxIdle: 300}).
+					Return(svc.ErrDeviceNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to set the session policy",
+			method:      http.MethodPut,
+			role:        guard.RoleOperator,
+			body:        `{"max_idle": 300, "force_recording": true, "allowed_subsystems": ["sftp"]}`,
+			requiredMocks: func() {
+				mock.
+					On("SetDeviceSessionPolicy", gomock.Anything, "1234", "tenant-id", &models.DeviceSessionPolicy{
+						MaxIdle:           300,
+						ForceRecording:    true,
+						AllowedSubsystems: []string{"sftp"},
+					}).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			description: "succeeds to remove the session policy",
+			method:      http.MethodDelete,
+			role:        guard.RoleOperator,
+			body:        ``,
+			requiredMocks: func() {
+				mock.
+					On("SetDeviceSessionPolicy", gomock.Anything, "1234", "tenant-id", (*models.DeviceSessionPolicy)(nil)).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(tc.method, "/api/devices/1234/session-policy", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestUpdateDeviceAllowedCommands(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		role           string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when role is observer",
+			role:           guard.RoleObserver,
+			body:           `{"allowed_commands": ["uptime"]}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description:    "fails when a pattern is empty",
+			role:           guard.RoleOwner,
+			body:           `{"allowed_commands": [""]}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when device is not found",
+			role:        guard.RoleOwner,
+			body:        `{"allowed_commands": ["uptime"]}`,
+			requiredMocks: func() {
+				mock.
+					On("UpdateDeviceAllowedCommands", gomock.Anything, "1234", "tenant-id", []string{"uptime"}).
+					Return(svc.ErrDeviceNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to update the allowed commands",
+			role:        guard.RoleOperator,
+			body:        `{"allowed_commands": ["uptime", "systemctl status *"]}`,
+			requiredMocks: func() {
+				mock.
+					On("UpdateDeviceAllowedCommands", gomock.Anything, "1234", "tenant-id", []string{"uptime", "systemctl status *"}).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/devices/1234/allowed-commands", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestUpdateDeviceExpiry(t *testing.T) {
+	mock := new(mocks.Service)
+
+	expiresAt := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		description    string
+		role           string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when role is observer",
+			role:           guard.RoleObserver,
+			body:           `{"expires_at": "2024-06-01T12:00:00Z"}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when device is not found",
+			role:        guard.RoleOwner,
+			body:        `{"expires_at": "2024-06-01T12:00:00Z"}`,
+			requiredMocks: func() {
+				mock.
+					On("SetDeviceExpiry", gomock.Anything, "1234", "tenant-id", &expiresAt).
+					Return(svc.ErrDeviceNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to set the expiry",
+			role:        guard.RoleOperator,
+			body:        `{"expires_at": "2024-06-01T12:00:00Z"}`,
+			requiredMocks: func() {
+				mock.
+					On("SetDeviceExpiry", gomock.Anything, "1234", "tenant-id", &expiresAt).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			description: "succeeds to remove the expiry",
+			role:        guard.RoleOwner,
+			body:        `{"expires_at": null}`,
+			requiredMocks: func() {
+				mock.
+					On("SetDeviceExpiry", gomock.Anything, "1234", "tenant-id", (*time.Time)(nil)).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/devices/1234/expiry", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestCheckDeviceExpiry(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description: "fails when device is not found",
+			requiredMocks: func() {
+				mock.
+					On("CheckDeviceExpiry", gomock.Anything, models.UID("1234")).
+					Return(svc.ErrDeviceNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "fails when the device access has expired",
+			requiredMocks: func() {
+				mock.
+					On("CheckDeviceExpiry", gomock.Anything, models.UID("1234")).
+					Return(svc.NewErrDeviceExpired(nil)).
+					Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds when the device access has not expired",
+			requiredMocks: func() {
+				mock.
+					On("CheckDeviceExpiry", gomock.Anything, models.UID("1234")).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/devices/1234/expiry", nil)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListDeviceEvents(t *testing.T) {
+	mock := new(mocks.Service)
+
+	events := []models.DeviceEvent{
+		{TenantID: "tenant-id", DeviceUID: "1234", Type: models.DeviceEventRenamed, Actor: "user", Data: map[string]string{"from": "old", "to": "new"}},
+		{TenantID: "tenant-id", DeviceUID: "1234", Type: models.DeviceEventAccepted, Actor: "user"},
+	}
+
+	cases := []struct {
+		description    string
+		query          string
+		requiredMocks  func()
+		expectedEvents []models.DeviceEvent
+		expectedCount  string
+		expectedStatus int
+	}{
+		{
+			description: "fails when the events cannot be listed",
+			requiredMocks: func() {
+				mock.
+					On("ListDeviceEvents", gomock.Anything, "tenant-id", models.UID("1234"), query.Paginator{Page: 1, PerPage: 10}, gomock.Anything, query.Sorter{Order: query.OrderDesc}).
+					Return(nil, 0, svc.ErrNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds listing the events of the page",
+			query:       "?page=2&per_page=20&order_by=asc",
+			requiredMocks: func() {
+				mock.
+					On("ListDeviceEvents", gomock.Anything, "tenant-id", models.UID("1234"), query.Paginator{Page: 2, PerPage: 20}, gomock.Anything, query.Sorter{Order: query.OrderAsc}).
+					Return(events, 12, nil).
+					Once()
+			},
+			expectedEvents: events,
+			expectedCount:  "12",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/devices/1234/events"+tc.query, nil)
+			req.Header.Set("X-Role", guard.RoleObserver)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var events []models.DeviceEvent
+			assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&events))
+			assert.Equal(t, tc.expectedEvents, events)
+			assert.Equal(t, tc.expectedCount, rec.Result().Header.Get("X-Total-Count"))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListDeviceConnections(t *testing.T) {
+	mock := new(mocks.Service)
+
+	connections := []models.DeviceConnection{
+		{SessionUID: "session-2", Username: "root", IP: "192.168.0.2", AuthMethod: models.DeviceConnectionAuthPublicKey},
+		{SessionUID: "session-1", Username: "root", IP: "192.168.0.1", Duration: 60000, AuthMethod: models.DeviceConnectionAuthPassword},
+	}
+
+	cases := []struct {
+		description         string
+		query               string
+		requiredMocks       func()
+		expectedConnections []models.DeviceConnection
+		expectedCount       string
+		expectedStatus      int
+	}{
+		{
+			description: "fails when the device isn't on the namespace",
+			requiredMocks: func() {
+				mock.
+					On("ListDeviceConnections", gomock.Anything, "tenant-id", models.UID("1234"), query.Paginator{Page: 1, PerPage: 10}).
+					Return(nil, 0, svc.ErrNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description:    "fails when the page is invalid",
+			query:          "?page=invalid",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			description: "succeeds listing the connections of the page",
+			query:       "?page=2&per_page=20",
+			requiredMocks: func() {
+				mock.
+					On("ListDeviceConnections", gomock.Anything, "tenant-id", models.UID("1234"), query.Paginator{Page: 2, PerPage: 20}).
+					Return(connections, 22, nil).
+					Once()
+			},
+			expectedConnections: connections,
+			expectedCount:       "22",
+			expectedStatus:      http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/devices/1234/connections"+tc.query, nil)
+			req.Header.Set("X-Role", guard.RoleObserver)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var connections []models.DeviceConnection
+			assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&connections))
+			assert.Equal(t, tc.expectedConnections, connections)
+			assert.Equal(t, tc.expectedCount, rec.Result().Header.Get("X-Total-Count"))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListPendingDevices(t *testing.T) {
+	mock := new(mocks.Service)
+
+	mock.On("ListPendingDevices", gomock.Anything, "00000000-0000-4000-0000-000000000000", query.Paginator{Page: 1, PerPage: 10}).
+		Return([]models.Device{{UID: "uid", Status: models.DeviceStatusPending}}, 1, nil).
+		Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/devices/pending?page=1&per_page=10", nil)
+	req.Header.Set("X-Role", guard.RoleOwner)
+	req.Header.Set("X-Tenant-ID", "00000000-0000-4000-0000-000000000000")
+	rec := httptest.NewRecorder()
+
+	e := NewRouter(mock)
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.Equal(t, "1", rec.Header().Get("X-Total-Count"))
+
+	mock.AssertExpectations(t)
+}
+
+func TestBulkUpdateDevicesStatus(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		url            string
+		role           string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when no device is informed",
+			url:            "/api/devices/bulk/accept",
+			role:           guard.RoleOwner,
+			body:           `{"uids":[]}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "fails when the role cannot accept devices",
+			url:            "/api/devices/bulk/accept",
+			role:           guard.RoleObserver,
+			body:           `{"uids":["a","b"]}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when accepting the devices would exceed the maximum number of devices",
+			url:         "/api/devices/bulk/accept",
+			role:        guard.RoleOwner,
+			body:        `{"uids":["a","b"]}`,
+			requiredMocks: func() {
+				mock.On("BulkAcceptDevices", gomock.Anything, "00000000-0000-4000-0000-000000000000", []models.UID{"a", "b"}).
+					Return(nil, svc.NewErrDeviceMaxDevicesReached(3)).
+					Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds accepting the devices",
+			url:         "/api/devices/bulk/accept",
+			role:        guard.RoleOwner,
+			body:        `{"uids":["a","b"]}`,
+			requiredMocks: func() {
+				mock.On("BulkAcceptDevices", gomock.Anything, "00000000-0000-4000-0000-000000000000", []models.UID{"a", "b"}).
+					Return([]responses.DeviceBulkResult{{UID: "a"}, {UID: "b", Error: responses.DeviceBulkErrorNotFound}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			description: "succeeds rejecting the devices",
+			url:         "/api/devices/bulk/reject",
+			role:        guard.RoleOwner,
+			body:        `{"uids":["a"]}`,
+			requiredMocks: func() {
+				mock.On("BulkRejectDevices", gomock.Anything, "00000000-0000-4000-0000-000000000000", []models.UID{"a"}).
+					Return([]responses.DeviceBulkResult{{UID: "a"}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, tc.url, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "00000000-0000-4000-0000-000000000000")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
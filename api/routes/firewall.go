@@ -0,0 +1,170 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+const (
+	BulkCreateFirewallRulesURL = "/namespaces/:tenant/firewall/bulk"
+	EvaluateFirewallURL        = "/firewall/rules/evaluate"
+	ListFirewallConflictsURL   = "/namespaces/:tenant/firewall/conflicts"
+	PreviewFirewallRuleURL     = "/namespaces/:tenant/firewall/preview"
+)
+
+// BulkCreateFirewallRulesResponse is the response of the bulk import of firewall rules.
+type BulkCreateFirewallRulesResponse struct {
+	Created   int                     `json:"created"`
+	Conflicts []services.BulkConflict `json:"conflicts"`
+	// Warnings are the conflicts between the namespace's rules after the import. They don't prevent the import.
+	Warnings []models.FirewallConflict `json:"warnings,omitempty"`
+}
+
+func (h *Handler) BulkCreateFirewallRules(c gateway.Context) error {
+	var req requests.FirewallRuleBulkCreate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	res := BulkCreateFirewallRulesResponse{}
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.Firewall.Create, func() error {
+		var err error
+		res.Created, res.Conflicts, err = h.service.BulkCreateFirewallRules(c.Ctx(), ns.TenantID, uid, req.Rules, req.Mode)
+
+		return err
+	})
+	if err != nil {
+		// When the import was aborted due conflicts, the conflicts are sent back to allow the client to fix them.
+		if len(res.Conflicts) > 0 {
+			return c.JSON(http.StatusConflict, res)
+		}
+
+		return err
+	}
+
+	// NOTICE: the rules were already imported, so failing to look for conflicts just leaves the warnings out.
+	if warnings, err := h.service.ValidateFirewallRules(c.Ctx(), ns.TenantID); err == nil {
+		res.Warnings = warnings
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) ListFirewallConflicts(c gateway.Context) error {
+	var req requests.FirewallConflictList
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	// NOTICE: requests authenticated by an API key don't carry a user, so they're only allowed on the key's namespace.
+	if uid != "" {
+		if _, ok := ns.FindMember(uid); !ok {
+			return c.NoContent(http.StatusForbidden)
+		}
+	} else if c.Tenant() == nil || c.Tenant().ID != ns.TenantID {
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	conflicts, err := h.service.ValidateFirewallRules(c.Ctx(), ns.TenantID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, conflicts)
+}
+
+// PreviewFirewallRule responds with the devices the firewall rule on the request's body would allow or deny for the
+// connection on it, without saving the rule.
+func (h *Handler) PreviewFirewallRule(c gateway.Context) error {
+	var req requests.FirewallRulePreview
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var preview *models.FirewallRulePreview
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.Firewall.Create, func() error {
+		var err error
+		preview, err = h.service.PreviewFirewallRule(c.Ctx(), ns.TenantID, req.FirewallRuleFields, req.Connection)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+// EvaluateFirewall responds with 200 when the connection described by the query is allowed by the namespace's
+// firewall and 403 otherwise. When a rule decided it, its ID is set on the X-Firewall-Rule header.
+func (h *Handler) EvaluateFirewall(c gateway.Context) error {
+	var req requests.FirewallEvaluate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	allowed, rule, err := h.service.FirewallEvaluate(c.Ctx(), req)
+	if err != nil {
+		return err
+	}
+
+	if rule != nil {
+		c.Response().Header().Set("X-Firewall-Rule", rule.ID)
+	}
+
+	if !allowed {
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
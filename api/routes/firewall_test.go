@@ -0,0 +1,378 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	echomiddleware "github.com/shellhub-io/shellhub/api/pkg/echo/middleware"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestBulkCreateFirewallRules(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := func(role string) *models.Namespace {
+		return &models.Namespace{
+			TenantID: "00000000-0000-4000-0000-000000000000",
+			Members:  []models.Member{{ID: "507f1f77bcf86cd799439011", Role: role}},
+		}
+	}
+
+	rules := []requests.FirewallRuleCreate{
+		{
+			FirewallRuleFields: models.FirewallRuleFields{
+				Priority: 1,
+				Action:   "allow",
+				Active:   true,
+				SourceIP: ".*",
+				Username: ".*",
+				Filter:   models.FirewallFilter{Hostname: "device"},
+			},
+		},
+	}
+
+	cases := []struct {
+		description    string
+		tenant         string
+		body           map[string]interface{}
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when mode is invalid",
+			tenant:         "00000000-0000-4000-0000-000000000000",
+			body:           map[string]interface{}{"mode": "invalid", "rules": rules},
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when namespace is not found",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			body:        map[string]interface{}{"mode": svc.FirewallBulkModeSkip, "rules": rules},
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNamespaceNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "fails when member has no permission",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			body:        map[string]interface{}{"mode": svc.FirewallBulkModeSkip, "rules": rules},
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace(guard.RoleObserver), nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when rules conflict",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			body:        map[string]interface{}{"mode": svc.FirewallBulkModeFailOnConflict, "rules": rules},
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace(guard.RoleOwner), nil).Once()
+				mock.On("BulkCreateFirewallRules", gomock.Anything, "00000000-0000-4000-0000-000000000000", "507f1f77bcf86cd799439011", rules, svc.FirewallBulkModeFailOnConflict).
+					Return(0, []svc.BulkConflict{{Index: 0, ExistingID: "id", Priority: 1, Hostname: "device"}}, svc.NewErrFirewallRuleDuplicated(nil)).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			description: "succeeds",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			body:        map[string]interface{}{"mode": svc.FirewallBulkModeSkip, "rules": rules},
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace(guard.RoleAdministrator), nil).Once()
+				mock.On("BulkCreateFirewallRules", gomock.Anything, "00000000-0000-4000-0000-000000000000", "507f1f77bcf86cd799439011", rules, svc.FirewallBulkModeSkip).
+					Return(1, []svc.BulkConflict{}, nil).Once()
+				mock.On("ValidateFirewallRules", gomock.Anything, "00000000-0000-4000-0000-000000000000").
+					Return([]models.FirewallConflict{{Rule1UID: "1", Rule2UID: "2", ConflictType: models.FirewallConflictShadowed}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			jsonData, err := json.Marshal(tc.body)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/namespaces/"+tc.tenant+"/firewall/bulk", strings.NewReader(string(jsonData)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", "507f1f77bcf86cd799439011")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListFirewallConflicts(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members:  []models.Member{{ID: "507f1f77bcf86cd799439011", Role: guard.RoleObserver}},
+	}
+
+	cases := []struct {
+		description    string
+		id             string
+		headers        map[string]string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description: "fails when namespace is not found",
+			id:          "507f1f77bcf86cd799439011",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNamespaceNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "fails when the API key belongs to another namespace",
+			headers: map[string]string{
+				echomiddleware.APIKeyHeader: "00000000-0000-4000-0000-000000000000",
+				"X-Tenant-ID":               "00000000-0000-4001-0000-000000000000",
+			},
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when the API key does not have a firewall scope",
+			headers: map[string]string{
+				echomiddleware.APIKeyHeader:       "00000000-0000-4000-0000-000000000000",
+				echomiddleware.APIKeyScopesHeader: echomiddleware.EncodeScopes([]int{guard.DeviceConnect}),
+				"X-Tenant-ID":                     "00000000-0000-4000-0000-000000000000",
+			},
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds when the API key belongs to the namespace",
+			headers: map[string]string{
+				echomiddleware.APIKeyHeader: "00000000-0000-4000-0000-000000000000",
+				"X-Tenant-ID":               "00000000-0000-4000-0000-000000000000",
+			},
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("ValidateFirewallRules", gomock.Anything, "00000000-0000-4000-0000-000000000000").
+					Return([]models.FirewallConflict{}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			description: "fails when user is not a member",
+			id:          "6509e169ae6144b2f56bf288",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds",
+			id:          "507f1f77bcf86cd799439011",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("ValidateFirewallRules", gomock.Anything, "00000000-0000-4000-0000-000000000000").
+					Return([]models.FirewallConflict{{Rule1UID: "1", Rule2UID: "2", ConflictType: models.FirewallConflictDuplicate}}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/namespaces/00000000-0000-4000-0000-000000000000/firewall/conflicts", nil)
+			if tc.id != "" {
+				req.Header.Set("X-ID", tc.id)
+			}
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestEvaluateFirewall(t *testing.T) {
+	mock := new(mocks.Service)
+
+	lookup := requests.FirewallEvaluate{
+		Domain:    "namespace",
+		Name:      "device",
+		Username:  "root",
+		IPAddress: "192.168.0.1",
+	}
+
+	cases := []struct {
+		description    string
+		query          string
+		requiredMocks  func()
+		expectedStatus int
+		expectedRule   string
+	}{
+		{
+			description:    "fails when domain is missing",
+			query:          "name=device&username=root&ip_address=192.168.0.1",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when namespace is not found",
+			query:       "domain=namespace&name=device&username=root&ip_address=192.168.0.1",
+			requiredMocks: func() {
+				mock.On("FirewallEvaluate", gomock.Anything, lookup).Return(false, nil, svc.ErrNamespaceNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "fails when the connection is denied",
+			query:       "domain=namespace&name=device&username=root&ip_address=192.168.0.1",
+			requiredMocks: func() {
+				mock.On("FirewallEvaluate", gomock.Anything, lookup).Return(false, &models.FirewallRule{ID: "rule"}, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedRule:   "rule",
+		},
+		{
+			description: "succeeds when the connection is allowed",
+			query:       "domain=namespace&name=device&username=root&ip_address=192.168.0.1",
+			requiredMocks: func() {
+				mock.On("FirewallEvaluate", gomock.Anything, lookup).Return(true, nil, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/firewall/rules/evaluate?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			assert.Equal(t, tc.expectedRule, rec.Result().Header.Get("X-Firewall-Rule"))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestPreviewFirewallRule(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := func(role string) *models.Namespace {
+		return &models.Namespace{
+			TenantID: "00000000-0000-4000-0000-000000000000",
+			Members:  []models.Member{{ID: "507f1f77bcf86cd799439011", Role: role}},
+		}
+	}
+
+	rule := models.FirewallRuleFields{
+		Priority: 1,
+		Action:   "deny",
+		Active:   true,
+		SourceIP: ".*",
+		Username: "root",
+		Filter:   models.FirewallFilter{Hostname: "prod-.*"},
+	}
+
+	connection := models.FirewallPreviewConnection{Username: "root", IPAddress: "192.168.1.1"}
+
+	cases := []struct {
+		description    string
+		rule           models.FirewallRuleFields
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when the rule is invalid",
+			rule:           models.FirewallRuleFields{Action: "drop", SourceIP: ".*", Username: ".*", Filter: models.FirewallFilter{Hostname: ".*"}},
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when namespace is not found",
+			rule:        rule,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNamespaceNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "fails when member has no permission",
+			rule:        rule,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace(guard.RoleObserver), nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds",
+			rule:        rule,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace(guard.RoleAdministrator), nil).Once()
+				mock.On("PreviewFirewallRule", gomock.Anything, "00000000-0000-4000-0000-000000000000", rule, connection).
+					Return(&models.FirewallRulePreview{
+						Action:  "deny",
+						Devices: []models.FirewallPreviewDevice{{UID: "uid", Name: "prod-1"}},
+					}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			jsonData, err := json.Marshal(struct {
+				models.FirewallRuleFields
+				Connection models.FirewallPreviewConnection `json:"connection"`
+			}{tc.rule, connection})
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/namespaces/00000000-0000-4000-0000-000000000000/firewall/preview", strings.NewReader(string(jsonData)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", "507f1f77bcf86cd799439011")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
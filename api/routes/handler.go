@@ -1,13 +1,50 @@
 package routes
 
 import (
+	"github.com/shellhub-io/shellhub/api/pkg/mailer"
 	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/pkg/models"
 )
 
 type Handler struct {
 	service svc.Service
+	// s3 is the object storage where session recordings are exported to. When nil, the export is disabled.
+	s3 *models.S3Config
+	// mailer sends the emails, like the usage reports of the namespaces. When nil, no email is sent.
+	mailer mailer.Mailer
+	// sessionPayloadLimit is the maximum size, in bytes, of the bodies sent to the internal session routes.
+	sessionPayloadLimit int64
+}
+
+// DefaultSessionPayloadLimit is the default maximum size, in bytes, of the bodies sent to the internal session routes.
+const DefaultSessionPayloadLimit = 1 << 20
+
+// Option configures the routes' [Handler].
+type Option func(h *Handler)
+
+// WithSessionExport enables exporting session recordings to the S3-compatible object storage configured by cfg.
+func WithSessionExport(cfg *models.S3Config) Option {
+	return func(h *Handler) {
+		h.s3 = cfg
+	}
+}
+
+// WithMailer enables sending emails, like the usage reports of the namespaces, through m.
+func WithMailer(m mailer.Mailer) Option {
+	return func(h *Handler) {
+		h.mailer = m
+	}
+}
+
+// WithSessionPayloadLimit limits to limit bytes the bodies sent to the internal session routes, refusing the larger
+// ones. When limit is not greater than zero, the bodies aren't limited. The recordings streamed to the server are
+// limited by [RecordSessionStreamMaxSize] instead.
+func WithSessionPayloadLimit(limit int64) Option {
+	return func(h *Handler) {
+		h.sessionPayloadLimit = limit
+	}
 }
 
 func NewHandler(s svc.Service) *Handler {
-	return &Handler{service: s}
+	return &Handler{service: s, sessionPayloadLimit: DefaultSessionPayloadLimit}
 }
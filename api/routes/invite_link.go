@@ -0,0 +1,155 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+const (
+	CreateInviteLinkURL = "/namespaces/:tenant/invite-links"
+	ListInviteLinksURL  = "/namespaces/:tenant/invite-links"
+	DeleteInviteLinkURL = "/namespaces/:tenant/invite-links/:token"
+	GetInviteLinkURL    = "/invite/:token"
+	JoinInviteLinkURL   = "/invite/:token/join"
+)
+
+func (h *Handler) CreateInviteLink(c gateway.Context) error {
+	var req requests.InviteLinkCreate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var link *models.InviteLink
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.AddMember, func() error {
+		var err error
+		link, err = h.service.CreateInviteLink(c.Ctx(), ns.TenantID, uid, req.Role, req.MaxUses, time.Duration(req.ExpiresIn)*time.Second)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, link)
+}
+
+func (h *Handler) ListInviteLinks(c gateway.Context) error {
+	var req requests.InviteLinkList
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	// NOTICE: the links' tokens let anyone join the namespace, so only who can add members may see them.
+	var links []models.InviteLink
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.AddMember, func() error {
+		var err error
+		links, err = h.service.ListInviteLinks(c.Ctx(), ns.TenantID)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, links)
+}
+
+func (h *Handler) DeleteInviteLink(c gateway.Context) error {
+	var req requests.InviteLinkDelete
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.AddMember, func() error {
+		return h.service.DeleteInviteLink(c.Ctx(), ns.TenantID, req.Token)
+	}); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) GetInviteLink(c gateway.Context) error {
+	var req requests.InviteLinkGet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	link, err := h.service.GetInviteLink(c.Ctx(), req.Token)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, link)
+}
+
+func (h *Handler) JoinInviteLink(c gateway.Context) error {
+	var req requests.InviteLinkJoin
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if c.ID() == nil {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	namespace, err := h.service.JoinInviteLink(c.Ctx(), req.Token, c.ID().ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, namespace)
+}
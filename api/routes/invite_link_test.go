@@ -0,0 +1,214 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestCreateInviteLink(t *testing.T) {
+	mock := new(mocks.Service)
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	namespace := &models.Namespace{
+		TenantID: tenantID,
+		Members: []models.Member{
+			{ID: "owner", Role: "owner"},
+			{ID: "observer", Role: "observer"},
+		},
+	}
+
+	cases := []struct {
+		description    string
+		userID         string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when the max uses is missing",
+			userID:         "owner",
+			body:           `{"role": "observer", "expires_in": 3600}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "fails when the role is invalid",
+			userID:         "owner",
+			body:           `{"role": "owner", "max_uses": 5, "expires_in": 3600}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when the user cannot add members",
+			userID:      "observer",
+			body:        `{"role": "observer", "max_uses": 5, "expires_in": 3600}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, tenantID).Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds to create the link",
+			userID:      "owner",
+			body:        `{"role": "observer", "max_uses": 5, "expires_in": 3600}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, tenantID).Return(namespace, nil).Once()
+				mock.
+					On("CreateInviteLink", gomock.Anything, tenantID, "owner", "observer", 5, time.Hour).
+					Return(&models.InviteLink{Token: "token", TenantID: tenantID, Role: "observer", MaxUses: 5}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/namespaces/"+tenantID+"/invite-links", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", tc.userID)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestGetInviteLink(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description: "fails when the link is not found",
+			requiredMocks: func() {
+				mock.On("GetInviteLink", gomock.Anything, "token").Return(nil, svc.ErrInviteLinkNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "fails when the link is expired",
+			requiredMocks: func() {
+				mock.On("GetInviteLink", gomock.Anything, "token").Return(nil, svc.ErrInviteLinkExpired).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds to get the link without being logged in",
+			requiredMocks: func() {
+				mock.
+					On("GetInviteLink", gomock.Anything, "token").
+					Return(&responses.InviteLink{TenantID: "00000000-0000-4000-0000-000000000000", Namespace: "dev", Role: "observer"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/invite/token", nil)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestJoinInviteLink(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		headers        map[string]string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when the user is not logged in",
+			headers:        map[string]string{},
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			description:    "fails when authenticated by an API key",
+			headers:        map[string]string{"X-ID": "user", "X-API-KEY": "key"},
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when the link is exhausted",
+			headers:     map[string]string{"X-ID": "user"},
+			requiredMocks: func() {
+				mock.On("JoinInviteLink", gomock.Anything, "token", "user").Return(nil, svc.ErrInviteLinkExhausted).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when the user is already a member",
+			headers:     map[string]string{"X-ID": "user"},
+			requiredMocks: func() {
+				mock.On("JoinInviteLink", gomock.Anything, "token", "user").Return(nil, svc.ErrNamespaceMemberDuplicated).Once()
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			description: "succeeds to join the namespace",
+			headers:     map[string]string{"X-ID": "user"},
+			requiredMocks: func() {
+				mock.
+					On("JoinInviteLink", gomock.Anything, "token", "user").
+					Return(&models.Namespace{TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/invite/token/join", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
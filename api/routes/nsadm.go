@@ -4,10 +4,12 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/labstack/echo/v4"
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
 	"github.com/shellhub-io/shellhub/pkg/models"
 )
 
@@ -15,13 +17,39 @@ const (
 	ListNamespaceURL           = "/namespaces"
 	CreateNamespaceURL         = "/namespaces"
 	GetNamespaceURL            = "/namespaces/:tenant"
+	LookupNamespaceURL         = "/namespaces/lookup"
 	DeleteNamespaceURL         = "/namespaces/:tenant"
 	EditNamespaceURL           = "/namespaces/:tenant"
 	AddNamespaceUserURL        = "/namespaces/:tenant/members"
 	RemoveNamespaceUserURL     = "/namespaces/:tenant/members/:uid"
 	EditNamespaceUserURL       = "/namespaces/:tenant/members/:uid"
-	GetSessionRecordURL        = "/users/security"
-	EditSessionRecordStatusURL = "/users/security/:tenant"
+	ExportNamespaceMembersURL  = "/namespaces/:tenant/members/export"
+	ListNamespaceMembersURL    = "/namespaces/:tenant/members"
+	ListNamespaceMemberTagsURL = "/namespaces/:tenant/members/tags"
+	SetNamespaceMemberTagsURL  = "/namespaces/:tenant/members/:uid/tags"
+	// SetNamespaceMemberAccessScheduleURL sets the access schedule of a namespace's member.
+	SetNamespaceMemberAccessScheduleURL = "/namespaces/:tenant/members/:uid/access-schedule"
+	// DeleteNamespaceMemberAccessScheduleURL removes the access schedule of a namespace's member.
+	DeleteNamespaceMemberAccessScheduleURL = "/namespaces/:tenant/members/:uid/access-schedule"
+	GetNamespaceSettingsURL                = "/namespaces/:tenant/settings"
+	UpdateNamespaceSettingsURL             = "/namespaces/:tenant/settings"
+	SetNamespaceAPIRateLimitURL            = "/namespaces/:tenant/rate-limit"
+	// SetNamespaceDefaultEnvVarsURL sets the environment variables injected into the sessions of a namespace.
+	SetNamespaceDefaultEnvVarsURL = "/namespaces/:tenant/settings/env-vars"
+	// RenewNamespaceURL marks an inactive namespace as active, canceling its expiration.
+	RenewNamespaceURL = "/namespaces/:tenant/renew"
+	// RequestNamespaceDeletionURL issues the token that confirms the deletion of a namespace.
+	RequestNamespaceDeletionURL = "/namespaces/:tenant/delete-request"
+	// SendNamespaceUsageReportURL sends the usage report of a namespace right away, regardless of its schedule.
+	SendNamespaceUsageReportURL = "/namespaces/:tenant/usage-report/send-now"
+	// RotateNamespaceEnrollmentKeyURL generates a new key to enroll devices in a namespace.
+	RotateNamespaceEnrollmentKeyURL = "/namespaces/:tenant/enrollment-key/rotate"
+	// RefreshNamespaceBillingCacheURL caches again whether a namespace has a subscription, called by the billing
+	// when the namespace's subscription changes.
+	RefreshNamespaceBillingCacheURL = "/namespaces/:tenant/billing/refresh"
+	GetSessionRecordURL             = "/users/security"
+	EditSessionRecordStatusURL      = "/users/security/:tenant"
+	BulkEditSessionRecordURL        = "/users/security"
 )
 
 const (
@@ -31,6 +59,10 @@ const (
 
 func (h *Handler) GetNamespaceList(c gateway.Context) error {
 	type Query struct {
+		// Role restricts the list to the namespaces where the user has it.
+		Role string `query:"role" validate:"omitempty,oneof=owner administrator operator observer"`
+		// OwnerID restricts the list to the namespaces owned by the user with it.
+		OwnerID string `query:"owner_id"`
 		query.Paginator
 		query.Filters
 	}
@@ -41,13 +73,26 @@ func (h *Handler) GetNamespaceList(c gateway.Context) error {
 		return err
 	}
 
+	if err := c.Validate(&query); err != nil {
+		return err
+	}
+
 	query.Paginator.Normalize()
 
 	if err := query.Filters.Unmarshal(); err != nil {
 		return err
 	}
 
-	namespaces, count, err := h.service.ListNamespaces(c.Ctx(), query.Paginator, query.Filters, false)
+	if query.OwnerID != "" {
+		// A user can only list the namespaces owned by itself.
+		if id := c.ID(); id != nil && id.ID != query.OwnerID {
+			return c.NoContent(http.StatusForbidden)
+		}
+
+		query.Filters.Data = append(query.Filters.Data, ownerFilter(query.OwnerID))
+	}
+
+	namespaces, count, err := h.service.ListNamespaces(c.Ctx(), query.Paginator, query.Filters, query.Role, false)
 	if err != nil {
 		return err
 	}
@@ -57,6 +102,14 @@ func (h *Handler) GetNamespaceList(c gateway.Context) error {
 	return c.JSON(http.StatusOK, namespaces)
 }
 
+// ownerFilter returns a filter that matches the namespaces owned by the user with the specified ID.
+func ownerFilter(id string) query.Filter {
+	return query.Filter{
+		Type:   query.FilterTypeOwnerID,
+		Params: &query.FilterOwnerID{ID: id},
+	}
+}
+
 func (h *Handler) CreateNamespace(c gateway.Context) error {
 	var req requests.NamespaceCreate
 	if err := c.Bind(&req); err != nil {
@@ -126,7 +179,7 @@ func (h *Handler) DeleteNamespace(c gateway.Context) error {
 	}
 
 	err = guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.Delete, func() error {
-		err := h.service.DeleteNamespace(c.Ctx(), ns.TenantID)
+		err := h.service.DeleteNamespace(c.Ctx(), ns.TenantID, req.ConfirmationToken, req.ConfirmationText)
 
 		return err
 	})
@@ -137,6 +190,40 @@ func (h *Handler) DeleteNamespace(c gateway.Context) error {
 	return c.NoContent(http.StatusOK)
 }
 
+// RequestNamespaceDeletion responds with the token that must be sent to delete the namespace.
+func (h *Handler) RequestNamespaceDeletion(c gateway.Context) error {
+	var req requests.NamespaceDeletionRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var deletion *responses.NamespaceDeletionRequest
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.Delete, func() error {
+		var err error
+		deletion, err = h.service.RequestNamespaceDeletion(c.Ctx(), ns.TenantID)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, deletion)
+}
+
 func (h *Handler) EditNamespace(c gateway.Context) error {
 	req := new(requests.NamespaceEdit)
 
@@ -172,6 +259,104 @@ func (h *Handler) EditNamespace(c gateway.Context) error {
 	return c.JSON(http.StatusOK, nns)
 }
 
+func (h *Handler) GetNamespaceSettings(c gateway.Context) error {
+	var req requests.NamespaceSettingsGet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if uid != "" {
+		if _, ok := ns.FindMember(uid); !ok {
+			return c.NoContent(http.StatusForbidden)
+		}
+	}
+
+	settings, err := h.service.GetNamespaceSettings(c.Ctx(), req.Tenant)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+func (h *Handler) UpdateNamespaceSettings(c gateway.Context) error {
+	req := new(requests.NamespaceSettingsUpdate)
+	if err := c.Bind(req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	namespace, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || namespace == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var settings *models.NamespaceSettings
+	err = guard.EvaluateNamespace(namespace, uid, guard.Actions.Namespace.Update, func() error {
+		var err error
+		settings, err = h.service.UpdateNamespaceSettings(c.Ctx(), req)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// SendNamespaceUsageReport sends the usage report of the namespace to its usage report email right away.
+func (h *Handler) SendNamespaceUsageReport(c gateway.Context) error {
+	var req requests.TenantParam
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	namespace, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || namespace == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if err := guard.EvaluateNamespace(namespace, uid, guard.Actions.Namespace.Update, func() error {
+		return h.service.SendNamespaceUsageReport(c.Ctx(), req.Tenant, h.mailer)
+	}); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
 func (h *Handler) AddNamespaceUser(c gateway.Context) error {
 	var req requests.NamespaceAddUser
 	if err := c.Bind(&req); err != nil {
@@ -206,6 +391,307 @@ func (h *Handler) AddNamespaceUser(c gateway.Context) error {
 	return c.JSON(http.StatusOK, namespace)
 }
 
+// ListNamespaceMembers lists the namespace's members, optionally only the ones with the tag in the tag query param.
+func (h *Handler) ListNamespaceMembers(c gateway.Context) error {
+	var req requests.NamespaceMembersList
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if uid != "" {
+		if _, ok := ns.FindMember(uid); !ok {
+			return c.NoContent(http.StatusForbidden)
+		}
+	}
+
+	members, err := h.service.ListNamespaceMembers(c.Ctx(), req.Tenant, req.Tag)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// RenewNamespace marks the namespace as active, canceling its expiration. Any member of the namespace can renew it.
+func (h *Handler) RenewNamespace(c gateway.Context) error {
+	var req requests.TenantParam
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if uid != "" {
+		if _, ok := ns.FindMember(uid); !ok {
+			return c.NoContent(http.StatusForbidden)
+		}
+	}
+
+	if err := h.service.RenewNamespace(c.Ctx(), req.Tenant); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// RotateNamespaceEnrollmentKey responds with a new key to enroll devices in the namespace, shown only once. The
+// replaced key is still accepted for a grace period and the devices already enrolled aren't affected.
+func (h *Handler) RotateNamespaceEnrollmentKey(c gateway.Context) error {
+	var req requests.TenantParam
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var key *responses.NamespaceEnrollmentKey
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.Update, func() error {
+		var err error
+		key, err = h.service.RotateNamespaceEnrollmentKey(c.Ctx(), ns.TenantID, uid)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, key)
+}
+
+// ListNamespaceMemberTags lists the unique tags of the namespace's members.
+func (h *Handler) ListNamespaceMemberTags(c gateway.Context) error {
+	var req requests.NamespaceMemberTagsList
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if uid != "" {
+		if _, ok := ns.FindMember(uid); !ok {
+			return c.NoContent(http.StatusForbidden)
+		}
+	}
+
+	tags, err := h.service.ListMemberTags(c.Ctx(), req.Tenant)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, tags)
+}
+
+// SetNamespaceMemberTags replaces the tags of a namespace's member.
+func (h *Handler) SetNamespaceMemberTags(c gateway.Context) error {
+	var req requests.NamespaceMemberTagsSet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.EditMember, func() error {
+		return h.service.SetMemberTags(c.Ctx(), req.Tenant, req.MemberUID, req.Tags)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// SetNamespaceMemberAccessSchedule sets the access schedule of a namespace's member.
+func (h *Handler) SetNamespaceMemberAccessSchedule(c gateway.Context) error {
+	var req requests.NamespaceMemberAccessScheduleSet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	return h.setNamespaceMemberAccessSchedule(c, req.Tenant, req.MemberUID, &req.AccessSchedule)
+}
+
+// DeleteNamespaceMemberAccessSchedule removes the access schedule of a namespace's member.
+func (h *Handler) DeleteNamespaceMemberAccessSchedule(c gateway.Context) error {
+	var req requests.NamespaceMemberAccessScheduleDelete
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	return h.setNamespaceMemberAccessSchedule(c, req.Tenant, req.MemberUID, nil)
+}
+
+func (h *Handler) setNamespaceMemberAccessSchedule(c gateway.Context, tenant, member string, schedule *models.AccessSchedule) error {
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.EditMember, func() error {
+		return h.service.SetMemberAccessSchedule(c.Ctx(), tenant, member, schedule)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// SetNamespaceAPIRateLimit sets the rate limit of the API requests made on behalf of a namespace. It's only exposed
+// on the internal API, as the limit protects the instance and must not be raised by the namespace's own members.
+func (h *Handler) SetNamespaceAPIRateLimit(c gateway.Context) error {
+	var req requests.NamespaceAPIRateLimitSet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.service.SetNamespaceAPIRateLimit(c.Ctx(), req.Tenant, &req.APIRateLimitConfig); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// SetNamespaceDefaultEnvVars sets the environment variables injected into the sessions of a namespace.
+func (h *Handler) SetNamespaceDefaultEnvVars(c gateway.Context) error {
+	var req requests.NamespaceDefaultEnvVarsSet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.Update, func() error {
+		return h.service.SetDefaultEnvVars(c.Ctx(), req.Tenant, req.DefaultEnvVars)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// ExportNamespaceMembers streams the namespace's members as a CSV file.
+func (h *Handler) ExportNamespaceMembers(c gateway.Context) error {
+	var req requests.NamespaceMembersExport
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	// NOTICE: the rows are written while the members are fetched, so a failure in the middle of the export can only
+	// truncate the file, as the status was already sent.
+	return guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.AddMember, func() error {
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv")
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="members.csv"`)
+		c.Response().WriteHeader(http.StatusOK)
+
+		return h.service.ExportNamespaceMembers(c.Ctx(), ns.TenantID, c.Response())
+	})
+}
+
 func (h *Handler) RemoveNamespaceUser(c gateway.Context) error {
 	var req requests.NamespaceRemoveUser
 	if err := c.Bind(&req); err != nil {
@@ -304,6 +790,35 @@ func (h *Handler) EditSessionRecordStatus(c gateway.Context) error {
 	return c.NoContent(http.StatusOK)
 }
 
+// BulkEditSessionRecordStatus changes the session record status of many namespaces owned by the authenticated user at
+// once, responding with the outcome of each one.
+func (h *Handler) BulkEditSessionRecordStatus(c gateway.Context) error {
+	var req requests.SessionBulkEditRecordStatus
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	if uid == "" {
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	results, err := h.service.SetSessionRecordForNamespaces(c.Ctx(), uid, req.Tenants, req.SessionRecord)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
+
 func (h *Handler) GetSessionRecord(c gateway.Context) error {
 	var tenant string
 	if v := c.Tenant(); v != nil {
@@ -317,3 +832,40 @@ func (h *Handler) GetSessionRecord(c gateway.Context) error {
 
 	return c.JSON(http.StatusOK, status)
 }
+
+func (h *Handler) LookupNamespace(c gateway.Context) error {
+	var req requests.NamespaceLookup
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	namespace, err := h.service.LookupNamespace(c.Ctx(), req.Name)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, namespace)
+}
+
+// RefreshNamespaceBillingCache caches again whether the namespace has a subscription, so a change on its billing is seen
+// before the cached status expires.
+func (h *Handler) RefreshNamespaceBillingCache(c gateway.Context) error {
+	var req requests.TenantParam
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.service.RefreshBillingCache(c.Ctx(), req.Tenant); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
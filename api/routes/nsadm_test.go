@@ -11,9 +11,12 @@ import (
 	"time"
 
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	mailermocks "github.com/shellhub-io/shellhub/api/pkg/mailer/mocks"
 	svc "github.com/shellhub-io/shellhub/api/services"
 	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
 	gomock "github.com/stretchr/testify/mock"
@@ -85,6 +88,75 @@ func TestCreateNamespace(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
+func TestGetNamespaceList(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title          string
+		query          string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when the role is invalid",
+			query:          "role=admin",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title: "success when listing every namespace",
+			query: "",
+			requiredMocks: func() {
+				mock.On("ListNamespaces", gomock.Anything, gomock.Anything, gomock.Anything, "", false).Return([]models.Namespace{}, 0, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			title: "success when listing the namespaces where the user has a role",
+			query: "role=owner",
+			requiredMocks: func() {
+				mock.On("ListNamespaces", gomock.Anything, gomock.Anything, gomock.Anything, guard.RoleOwner, false).Return([]models.Namespace{}, 0, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			title:          "fails when filtering by the namespaces owned by another user",
+			query:          "owner_id=456",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "success when filtering by the namespaces owned by the user",
+			query: "owner_id=123",
+			requiredMocks: func() {
+				filters := query.Filters{
+					Data: []query.Filter{{Type: query.FilterTypeOwnerID, Params: &query.FilterOwnerID{ID: "123"}}},
+				}
+
+				mock.On("ListNamespaces", gomock.Anything, gomock.Anything, filters, "", false).Return([]models.Namespace{}, 0, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/namespaces?"+tc.query, nil)
+			req.Header.Set("X-ID", "123")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
 func TestGetNamespace(t *testing.T) {
 	mock := new(mocks.Service)
 
@@ -171,6 +243,7 @@ func TestDeleteNamespace(t *testing.T) {
 		title          string
 		uid            string
 		req            string
+		token          string
 		requiredMocks  func()
 		expectedStatus int
 	}{
@@ -202,10 +275,31 @@ func TestDeleteNamespace(t *testing.T) {
 			expectedStatus: http.StatusNotFound,
 			requiredMocks:  func() {},
 		},
+		{
+			title: "fails when the confirmation token is missing and required",
+			uid:   "123",
+			req:   "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(&models.Namespace{
+					Name:     "namespace-name",
+					Owner:    "owner-name",
+					TenantID: "00000000-0000-4000-0000-000000000000",
+					Members: []models.Member{
+						{ID: "123", Username: "userexemple", Role: "owner"},
+					},
+				}, nil).Once()
+
+				mock.On("DeleteNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000", "", "").
+					Return(svc.NewErrNamespaceDeletionInvalid(nil)).
+					Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
 		{
 			title: "fails when try to deleting a existing namespace",
 			uid:   "123",
 			req:   "00000000-0000-4000-0000-000000000000",
+			token: "token",
 			requiredMocks: func() {
 				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(&models.Namespace{
 					Name:     "namespace-name",
@@ -223,7 +317,7 @@ func TestDeleteNamespace(t *testing.T) {
 					Billing:      &models.Billing{},
 				}, nil).Once()
 
-				mock.On("DeleteNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(svc.ErrNotFound).Once()
+				mock.On("DeleteNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000", "token", "").Return(svc.ErrNotFound).Once()
 			},
 			expectedStatus: http.StatusNotFound,
 		},
@@ -231,6 +325,7 @@ func TestDeleteNamespace(t *testing.T) {
 			title: "success when try to deleting a existing namespace",
 			uid:   "123",
 			req:   "00000000-0000-4000-0000-000000000000",
+			token: "token",
 			requiredMocks: func() {
 				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(&models.Namespace{
 					Name:     "namespace-name",
@@ -248,7 +343,7 @@ func TestDeleteNamespace(t *testing.T) {
 					Billing:      &models.Billing{},
 				}, nil).Once()
 
-				mock.On("DeleteNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil).Once()
+				mock.On("DeleteNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000", "token", "").Return(nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -258,7 +353,7 @@ func TestDeleteNamespace(t *testing.T) {
 		t.Run(tc.title, func(t *testing.T) {
 			tc.requiredMocks()
 
-			req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/namespaces/%s", tc.req), nil)
+			req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/namespaces/%s?confirmation_token=%s", tc.req, tc.token), nil)
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Set("X-Role", guard.RoleOwner)
 			req.Header.Set("X-ID", tc.uid)
@@ -274,6 +369,83 @@ func TestDeleteNamespace(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
+func TestRequestNamespaceDeletion(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		Name:     "namespace-name",
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "owner", Role: guard.RoleOwner},
+			{ID: "observer", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		tenant         string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when the tenant is invalid",
+			uid:            "owner",
+			tenant:         "tg",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:  "fails when the namespace does not exist",
+			uid:    "owner",
+			tenant: "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title:  "fails when the member is not allowed to delete the namespace",
+			uid:    "observer",
+			tenant: "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title:  "succeeds",
+			uid:    "owner",
+			tenant: "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("RequestNamespaceDeletion", gomock.Anything, "00000000-0000-4000-0000-000000000000").
+					Return(&responses.NamespaceDeletionRequest{ConfirmationToken: "token"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/namespaces/%s/delete-request", tc.tenant), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
 func TestGetSessionRecord(t *testing.T) {
 	mock := new(mocks.Service)
 
@@ -321,6 +493,62 @@ func TestGetSessionRecord(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
+func TestBulkEditSessionRecordStatus(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		id             string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when tenants are missing",
+			id:             "507f1f77bcf86cd799439011",
+			body:           `{"tenants": [], "session_record": true}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "fails when the user is not authenticated",
+			id:             "",
+			body:           `{"tenants": ["tenant-1"], "session_record": true}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds",
+			id:          "507f1f77bcf86cd799439011",
+			body:        `{"tenants": ["tenant-1", "tenant-2"], "session_record": true}`,
+			requiredMocks: func() {
+				mock.On("SetSessionRecordForNamespaces", gomock.Anything, "507f1f77bcf86cd799439011", []string{"tenant-1", "tenant-2"}, true).
+					Return([]svc.SessionRecordResult{{TenantID: "tenant-1", Updated: true}, {TenantID: "tenant-2", Error: "forbidden"}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/users/security", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", tc.id)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
 func TestEditNamespace(t *testing.T) {
 	mock := new(mocks.Service)
 
@@ -429,3 +657,853 @@ func TestEditNamespace(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestLookupNamespace(t *testing.T) {
+	mock := new(mocks.Service)
+
+	type Expected struct {
+		namespace *models.Namespace
+		status    int
+	}
+
+	cases := []struct {
+		description   string
+		name          string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description:   "fails when the name is missing",
+			name:          "",
+			requiredMocks: func() {},
+			expected:      Expected{status: http.StatusBadRequest},
+		},
+		{
+			description: "fails when the namespace is not found",
+			name:        "namespace",
+			requiredMocks: func() {
+				mock.On("LookupNamespace", gomock.Anything, "namespace").Return(nil, svc.ErrNamespaceNotFound).Once()
+			},
+			expected: Expected{status: http.StatusNotFound},
+		},
+		{
+			description: "succeeds when the namespace exists",
+			name:        "namespace",
+			requiredMocks: func() {
+				mock.On("LookupNamespace", gomock.Anything, "namespace").
+					Return(&models.Namespace{Name: "namespace", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+			},
+			expected: Expected{
+				namespace: &models.Namespace{Name: "namespace", TenantID: "00000000-0000-4000-0000-000000000000"},
+				status:    http.StatusOK,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/namespaces/lookup?name="+tc.name, nil)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Result().StatusCode)
+
+			if tc.expected.namespace != nil {
+				var namespace *models.Namespace
+				assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&namespace))
+				assert.Equal(t, tc.expected.namespace, namespace)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestExportNamespaceMembers(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		Name:     "namespace-name",
+		Owner:    "owner",
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "owner", Username: "owner", Role: guard.RoleOwner},
+			{ID: "observer", Username: "observer", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		requiredMocks  func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			title: "fails when the namespace is not found",
+			uid:   "owner",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "fails when the member cannot manage members",
+			uid:   "observer",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "success when exporting the members",
+			uid:   "owner",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("ExportNamespaceMembers", gomock.Anything, "00000000-0000-4000-0000-000000000000", gomock.Anything).
+					Run(func(args gomock.Arguments) {
+						io.WriteString(args.Get(2).(io.Writer), "username,email,role,joined_at,last_seen_at\n") //nolint:errcheck
+					}).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "username,email,role,joined_at,last_seen_at\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/namespaces/00000000-0000-4000-0000-000000000000/members/export", nil)
+			req.Header.Set("X-Role", guard.RoleOwner)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, `attachment; filename="members.csv"`, rec.Result().Header.Get("Content-Disposition"))
+				assert.Equal(t, tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestUpdateNamespaceSettings(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		Name:     "namespace-name",
+		Owner:    "123",
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "owner", Role: guard.RoleOwner},
+			{ID: "456", Username: "observer", Role: guard.RoleObserver},
+		},
+		Settings: &models.NamespaceSettings{},
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		req            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when the default firewall policy is invalid",
+			uid:            "123",
+			req:            `{"default_firewall_policy": "reject"}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when the minimum RSA size is too small",
+			uid:            "123",
+			req:            `{"min_rsa_bits": 512}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title: "fails when the namespace does not exist",
+			uid:   "123",
+			req:   `{"session_record": true}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNamespaceNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "fails when the member cannot update the namespace",
+			uid:   "456",
+			req:   `{"session_record": true}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "succeeds",
+			uid:   "123",
+			req:   `{"session_record": true}`,
+			requiredMocks: func() {
+				enabled := true
+
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("UpdateNamespaceSettings", gomock.Anything, &requests.NamespaceSettingsUpdate{
+					TenantParam:   requests.TenantParam{Tenant: "00000000-0000-4000-0000-000000000000"},
+					SessionRecord: &enabled,
+				}).Return(&models.NamespaceSettings{SessionRecord: true, DefaultFirewallPolicy: models.FirewallPolicyAllow, MinRSABits: models.DefaultMinRSABits}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/namespaces/00000000-0000-4000-0000-000000000000/settings", strings.NewReader(tc.req))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", guard.RoleOwner)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListNamespaceMembers(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "owner", Role: guard.RoleOwner, Tags: []string{"team:infra"}},
+			{ID: "456", Username: "observer", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		query          string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title: "fails when the user is not a member of the namespace",
+			uid:   "789",
+			query: "",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "succeeds listing every member",
+			uid:   "456",
+			query: "",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("ListNamespaceMembers", gomock.Anything, "00000000-0000-4000-0000-000000000000", "").Return(namespace.Members, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			title: "succeeds listing the members with the tag",
+			uid:   "456",
+			query: "?tag=team:infra",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("ListNamespaceMembers", gomock.Anything, "00000000-0000-4000-0000-000000000000", "team:infra").Return(namespace.Members[:1], nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/namespaces/00000000-0000-4000-0000-000000000000/members"+tc.query, nil)
+			req.Header.Set("X-Role", guard.RoleOwner)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestRenewNamespace(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "owner", Role: guard.RoleOwner},
+			{ID: "456", Username: "observer", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title: "fails when the namespace is not found",
+			uid:   "123",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "fails when the user is not a member of the namespace",
+			uid:   "789",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "succeeds",
+			uid:   "456",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("RenewNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/namespaces/00000000-0000-4000-0000-000000000000/renew", nil)
+			req.Header.Set("X-Role", guard.RoleObserver)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestRotateNamespaceEnrollmentKey(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "administrator", Role: guard.RoleAdministrator},
+			{ID: "456", Username: "operator", Role: guard.RoleOperator},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title: "fails when the namespace is not found",
+			uid:   "123",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "fails when the user is not an administrator of the namespace",
+			uid:   "456",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "succeeds",
+			uid:   "123",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("RotateNamespaceEnrollmentKey", gomock.Anything, "00000000-0000-4000-0000-000000000000", "123").
+					Return(&responses.NamespaceEnrollmentKey{EnrollmentKey: "key"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/namespaces/00000000-0000-4000-0000-000000000000/enrollment-key/rotate", nil)
+			req.Header.Set("X-Role", guard.RoleAdministrator)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSendNamespaceUsageReport(t *testing.T) {
+	mock := new(mocks.Service)
+	mailerMock := new(mailermocks.Mailer)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "owner", Role: guard.RoleOwner},
+			{ID: "456", Username: "observer", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title: "fails when the namespace is not found",
+			uid:   "123",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "fails when the user can not update the namespace",
+			uid:   "456",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "fails when the namespace has no usage report email",
+			uid:   "123",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("SendNamespaceUsageReport", gomock.Anything, "00000000-0000-4000-0000-000000000000", mailerMock).
+					Return(svc.NewErrNamespaceUsageReportInvalid(nil)).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title: "succeeds",
+			uid:   "123",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("SendNamespaceUsageReport", gomock.Anything, "00000000-0000-4000-0000-000000000000", mailerMock).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/namespaces/00000000-0000-4000-0000-000000000000/usage-report/send-now", nil)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock, WithMailer(mailerMock))
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSetNamespaceMemberTags(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "owner", Role: guard.RoleOwner},
+			{ID: "456", Username: "observer", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		req            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when there are more than 10 tags",
+			uid:            "123",
+			req:            `{"tags": ["t01", "t02", "t03", "t04", "t05", "t06", "t07", "t08", "t09", "t10", "t11"]}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when the tags are duplicated",
+			uid:            "123",
+			req:            `{"tags": ["team:infra", "team:infra"]}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title: "fails when the member cannot edit members",
+			uid:   "456",
+			req:   `{"tags": ["team:infra"]}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "succeeds",
+			uid:   "123",
+			req:   `{"tags": ["team:infra", "region:eu"]}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("SetMemberTags", gomock.Anything, "00000000-0000-4000-0000-000000000000", "456", []string{"team:infra", "region:eu"}).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/namespaces/00000000-0000-4000-0000-000000000000/members/456/tags", strings.NewReader(tc.req))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", guard.RoleOwner)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSetNamespaceMemberAccessSchedule(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "owner", Role: guard.RoleOwner},
+			{ID: "456", Username: "observer", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		method         string
+		uid            string
+		req            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:  "fails when the member cannot edit members",
+			method: http.MethodPut,
+			uid:    "456",
+			req:    `{"timezone": "UTC", "allowed_windows": [{"days_of_week": [1], "start_time": "09:00", "end_time": "18:00"}]}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title:  "succeeds to set the schedule",
+			method: http.MethodPut,
+			uid:    "123",
+			req:    `{"timezone": "UTC", "allowed_windows": [{"days_of_week": [1], "start_time": "09:00", "end_time": "18:00"}]}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("SetMemberAccessSchedule", gomock.Anything, "00000000-0000-4000-0000-000000000000", "456", &models.AccessSchedule{
+					Timezone:       "UTC",
+					AllowedWindows: []models.TimeWindow{{DaysOfWeek: []int{1}, StartTime: "09:00", EndTime: "18:00"}},
+				}).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			title:  "succeeds to remove the schedule",
+			method: http.MethodDelete,
+			uid:    "123",
+			req:    ``,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("SetMemberAccessSchedule", gomock.Anything, "00000000-0000-4000-0000-000000000000", "456", (*models.AccessSchedule)(nil)).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(tc.method, "/api/namespaces/00000000-0000-4000-0000-000000000000/members/456/access-schedule", strings.NewReader(tc.req))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", guard.RoleOwner)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSetNamespaceDefaultEnvVars(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "owner", Role: guard.RoleOwner},
+			{ID: "456", Username: "observer", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		req            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title: "fails when the namespace does not exist",
+			uid:   "123",
+			req:   `{"default_env_vars": {"TERM": "xterm-256color"}}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNamespaceNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "fails when the member cannot update the namespace",
+			uid:   "456",
+			req:   `{"default_env_vars": {"TERM": "xterm-256color"}}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "succeeds",
+			uid:   "123",
+			req:   `{"default_env_vars": {"TERM": "xterm-256color"}}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("SetDefaultEnvVars", gomock.Anything, "00000000-0000-4000-0000-000000000000", map[string]string{"TERM": "xterm-256color"}).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/namespaces/00000000-0000-4000-0000-000000000000/settings/env-vars", strings.NewReader(tc.req))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", guard.RoleOwner)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSetNamespaceAPIRateLimit(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title          string
+		url            string
+		req            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when the requests per minute are missing",
+			url:            "/internal/namespaces/00000000-0000-4000-0000-000000000000/rate-limit",
+			req:            `{"burst_size": 10}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when the burst size is invalid",
+			url:            "/internal/namespaces/00000000-0000-4000-0000-000000000000/rate-limit",
+			req:            `{"requests_per_minute": 60, "burst_size": 0}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when requested through the public API",
+			url:            "/api/namespaces/00000000-0000-4000-0000-000000000000/rate-limit",
+			req:            `{"requests_per_minute": 60, "burst_size": 10}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "fails when the namespace does not exist",
+			url:   "/internal/namespaces/00000000-0000-4000-0000-000000000000/rate-limit",
+			req:   `{"requests_per_minute": 60, "burst_size": 10}`,
+			requiredMocks: func() {
+				mock.On("SetNamespaceAPIRateLimit", gomock.Anything, "00000000-0000-4000-0000-000000000000", &models.APIRateLimitConfig{RequestsPerMinute: 60, BurstSize: 10}).
+					Return(svc.ErrNamespaceNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "succeeds",
+			url:   "/internal/namespaces/00000000-0000-4000-0000-000000000000/rate-limit",
+			req:   `{"requests_per_minute": 60, "burst_size": 10}`,
+			requiredMocks: func() {
+				mock.On("SetNamespaceAPIRateLimit", gomock.Anything, "00000000-0000-4000-0000-000000000000", &models.APIRateLimitConfig{RequestsPerMinute: 60, BurstSize: 10}).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPut, tc.url, strings.NewReader(tc.req))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestRefreshNamespaceBillingCache(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		tenant         string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when the tenant is not valid",
+			tenant:         "tenant",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "fails when the namespace is not found",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				mock.On("RefreshBillingCache", gomock.Anything, "00000000-0000-4000-0000-000000000000").
+					Return(svc.ErrNamespaceNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				mock.On("RefreshBillingCache", gomock.Anything, "00000000-0000-4000-0000-000000000000").
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/internal/namespaces/"+tc.tenant+"/billing/refresh", nil)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+)
+
+const (
+	ListPlansURL = "/plans"
+)
+
+func (h *Handler) ListPlans(c gateway.Context) error {
+	plans, err := h.service.ListPlans(c.Ctx())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, plans)
+}
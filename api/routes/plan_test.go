@@ -0,0 +1,72 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestListPlans(t *testing.T) {
+	mock := new(mocks.Service)
+
+	type Expected struct {
+		plans  []models.Plan
+		status int
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when cannot list the plans",
+			requiredMocks: func() {
+				mock.On("ListPlans", gomock.Anything).Return(nil, errors.New("error")).Once()
+			},
+			expected: Expected{
+				plans:  nil,
+				status: http.StatusInternalServerError,
+			},
+		},
+		{
+			description: "succeeds",
+			requiredMocks: func() {
+				mock.On("ListPlans", gomock.Anything).Return([]models.Plan{{Name: models.PlanFree}}, nil).Once()
+			},
+			expected: Expected{
+				plans:  []models.Plan{{Name: models.PlanFree}},
+				status: http.StatusOK,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/plans", nil)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Result().StatusCode)
+
+			if tc.expected.status == http.StatusOK {
+				var plans []models.Plan
+				assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&plans))
+				assert.Equal(t, tc.expected.plans, plans)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
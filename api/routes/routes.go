@@ -3,12 +3,14 @@ package routes
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/shellhub-io/shellhub/api/pkg/echo/handlers"
+	echomiddleware "github.com/shellhub-io/shellhub/api/pkg/echo/middleware"
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
 	apiMiddleware "github.com/shellhub-io/shellhub/api/routes/middleware"
 	"github.com/shellhub-io/shellhub/api/services"
 )
 
-func NewRouter(service services.Service) *echo.Echo {
+func NewRouter(service services.Service, opts ...Option) *echo.Echo {
 	e := echo.New()
 	e.Binder = handlers.NewBinder()
 	e.Validator = handlers.NewValidator()
@@ -24,6 +26,9 @@ func NewRouter(service services.Service) *echo.Echo {
 	})
 
 	handler := NewHandler(service)
+	for _, opt := range opts {
+		opt(handler)
+	}
 
 	// Internal routes only accessible by other services in the local container network
 	internalAPI := e.Group("/internal")
@@ -34,17 +39,27 @@ func NewRouter(service services.Service) *echo.Echo {
 	internalAPI.GET(GetDeviceByPublicURLAddress, gateway.Handler(handler.GetDeviceByPublicURLAddress))
 	internalAPI.POST(OfflineDeviceURL, gateway.Handler(handler.OfflineDevice))
 	internalAPI.GET(LookupDeviceURL, gateway.Handler(handler.LookupDevice))
+	internalAPI.GET(CheckDeviceExpiryURL, gateway.Handler(handler.CheckDeviceExpiry))
 
-	internalAPI.PATCH(UpdateSessionURL, gateway.Handler(handler.UpdateSession))
-	internalAPI.POST(CreateSessionURL, gateway.Handler(handler.CreateSession))
-	internalAPI.POST(FinishSessionURL, gateway.Handler(handler.FinishSession))
-	internalAPI.POST(KeepAliveSessionURL, gateway.Handler(handler.KeepAliveSession))
-	internalAPI.POST(RecordSessionURL, gateway.Handler(handler.RecordSession))
+	sessionPayloadLimit := echomiddleware.BodyLimit(handler.sessionPayloadLimit)
+	internalAPI.PATCH(UpdateSessionURL, gateway.Handler(handler.UpdateSession), sessionPayloadLimit)
+	internalAPI.POST(CreateSessionURL, gateway.Handler(handler.CreateSession), sessionPayloadLimit)
+	internalAPI.POST(FinishSessionURL, gateway.Handler(handler.FinishSession), sessionPayloadLimit)
+	internalAPI.POST(KeepAliveSessionURL, gateway.Handler(handler.KeepAliveSession), sessionPayloadLimit)
+	internalAPI.POST(RecordSessionURL, gateway.Handler(handler.RecordSession), sessionPayloadLimit)
+	internalAPI.POST(RecordSessionStreamURL, gateway.Handler(handler.RecordSessionStream))
 
 	internalAPI.GET(GetPublicKeyURL, gateway.Handler(handler.GetPublicKey))
 	internalAPI.POST(CreatePrivateKeyURL, gateway.Handler(handler.CreatePrivateKey))
 	internalAPI.POST(EvaluateKeyURL, gateway.Handler(handler.EvaluateKey))
 
+	internalAPI.GET(EvaluateFirewallURL, gateway.Handler(handler.EvaluateFirewall))
+
+	internalAPI.GET(LookupNamespaceURL, gateway.Handler(handler.LookupNamespace))
+	internalAPI.POST(RefreshNamespaceBillingCacheURL, gateway.Handler(handler.RefreshNamespaceBillingCache))
+	internalAPI.PUT(SetNamespaceAPIRateLimitURL, gateway.Handler(handler.SetNamespaceAPIRateLimit))
+	internalAPI.PUT(AssignUserPlanURL, gateway.Handler(handler.AssignUserPlan))
+
 	// Public routes for external access through API gateway
 	publicAPI := e.Group("/api")
 
@@ -63,15 +78,39 @@ func NewRouter(service services.Service) *echo.Echo {
 
 	publicAPI.PATCH(UpdateUserDataURL, gateway.Handler(handler.UpdateUserData), apiMiddleware.BlockAPIKey)
 	publicAPI.PATCH(UpdateUserPasswordURL, gateway.Handler(handler.UpdateUserPassword), apiMiddleware.BlockAPIKey)
+	publicAPI.DELETE(DeleteUserURL, gateway.Handler(handler.DeleteUser), apiMiddleware.BlockAPIKey)
+	publicAPI.PATCH(CompleteUserOnboardingURL, gateway.Handler(handler.CompleteUserOnboarding), apiMiddleware.BlockAPIKey)
+	publicAPI.GET(ListUserSessionsURL, gateway.Handler(handler.ListUserSessions), apiMiddleware.BlockAPIKey)
+	publicAPI.DELETE(RevokeUserSessionURL, gateway.Handler(handler.RevokeUserSession), apiMiddleware.BlockAPIKey)
 	publicAPI.PUT(EditSessionRecordStatusURL, gateway.Handler(handler.EditSessionRecordStatus))
+	publicAPI.PUT(BulkEditSessionRecordURL, gateway.Handler(handler.BulkEditSessionRecordStatus))
 	publicAPI.GET(GetSessionRecordURL, gateway.Handler(handler.GetSessionRecord))
 
 	publicAPI.GET(GetDeviceListURL, apiMiddleware.Authorize(gateway.Handler(handler.GetDeviceList)))
 	publicAPI.GET(GetDeviceURL, apiMiddleware.Authorize(gateway.Handler(handler.GetDevice)))
-	publicAPI.DELETE(DeleteDeviceURL, gateway.Handler(handler.DeleteDevice))
+	publicAPI.DELETE(DeleteDeviceURL, gateway.Handler(handler.DeleteDevice), echomiddleware.RequiresAPIKeyScope(guard.DeviceRemove))
+	publicAPI.DELETE(CleanupConnectorDevicesURL, gateway.Handler(handler.CleanupConnectorDevices), echomiddleware.RequiresAPIKeyScope(guard.DeviceRemove))
 	publicAPI.PUT(UpdateDevice, gateway.Handler(handler.UpdateDevice))
 	publicAPI.PATCH(RenameDeviceURL, gateway.Handler(handler.RenameDevice))
-	publicAPI.PATCH(UpdateDeviceStatusURL, gateway.Handler(handler.UpdateDeviceStatus))
+	publicAPI.PATCH(UpdateDeviceStatusURL, gateway.Handler(handler.UpdateDeviceStatus), echomiddleware.RequiresAPIKeyScope(guard.DeviceAccept, guard.DeviceReject))
+	publicAPI.POST(PingDeviceURL, gateway.Handler(handler.PingDevice), echomiddleware.RequiresAPIKeyScope(guard.DeviceConnect))
+	publicAPI.POST(TrustDeviceHostKeyURL, gateway.Handler(handler.TrustDeviceHostKey), echomiddleware.RequiresAPIKeyScope(guard.DeviceUpdate))
+	publicAPI.PUT(SetDeviceSessionPolicyURL, gateway.Handler(handler.SetDeviceSessionPolicy), echomiddleware.RequiresAPIKeyScope(guard.DeviceUpdate))
+	publicAPI.DELETE(DeleteDeviceSessionPolicyURL, gateway.Handler(handler.DeleteDeviceSessionPolicy), echomiddleware.RequiresAPIKeyScope(guard.DeviceUpdate))
+	publicAPI.PATCH(UpdateDeviceAllowedCommandsURL, gateway.Handler(handler.UpdateDeviceAllowedCommands), echomiddleware.RequiresAPIKeyScope(guard.DeviceUpdate))
+	publicAPI.PATCH(UpdateDeviceExpiryURL, gateway.Handler(handler.UpdateDeviceExpiry), echomiddleware.RequiresAPIKeyScope(guard.DeviceUpdate))
+	publicAPI.GET(ListDeviceEventsURL, gateway.Handler(handler.ListDeviceEvents), echomiddleware.RequiresAPIKeyScope(guard.DeviceDetails))
+	publicAPI.GET(ListDeviceConnectionsURL, gateway.Handler(handler.ListDeviceConnections), echomiddleware.RequiresAPIKeyScope(guard.DeviceDetails))
+	publicAPI.GET(ListPendingDevicesURL, apiMiddleware.Authorize(gateway.Handler(handler.ListPendingDevices)))
+	publicAPI.POST(BulkAcceptDevicesURL, gateway.Handler(handler.BulkAcceptDevices), echomiddleware.RequiresAPIKeyScope(guard.DeviceAccept))
+	publicAPI.POST(BulkRejectDevicesURL, gateway.Handler(handler.BulkRejectDevices), echomiddleware.RequiresAPIKeyScope(guard.DeviceReject))
+
+	publicAPI.POST(CreateDeviceGroupURL, gateway.Handler(handler.CreateDeviceGroup), echomiddleware.RequiresAPIKeyScope(guard.DeviceUpdate, guard.DeviceCreateGroup))
+	publicAPI.GET(GetDeviceGroupURL, gateway.Handler(handler.GetDeviceGroup))
+	publicAPI.PATCH(UpdateDeviceGroupURL, gateway.Handler(handler.UpdateDeviceGroup), echomiddleware.RequiresAPIKeyScope(guard.DeviceUpdate, guard.DeviceUpdateGroup))
+	publicAPI.GET(ListDeviceGroupDevicesURL, gateway.Handler(handler.ListDeviceGroupDevices))
+	publicAPI.POST(AddDeviceToGroupURL, gateway.Handler(handler.AddDeviceToGroup), echomiddleware.RequiresAPIKeyScope(guard.DeviceUpdate, guard.DeviceUpdateGroup))
+	publicAPI.DELETE(RemoveDeviceFromGroupURL, gateway.Handler(handler.RemoveDeviceFromGroup), echomiddleware.RequiresAPIKeyScope(guard.DeviceUpdate, guard.DeviceUpdateGroup))
 
 	publicAPI.POST(CreateTagURL, gateway.Handler(handler.CreateDeviceTag))
 	publicAPI.DELETE(RemoveTagURL, gateway.Handler(handler.RemoveDeviceTag))
@@ -82,9 +121,17 @@ func NewRouter(service services.Service) *echo.Echo {
 	publicAPI.DELETE(DeleteTagsURL, gateway.Handler(handler.DeleteTag))
 
 	publicAPI.GET(GetSessionsURL, apiMiddleware.Authorize(gateway.Handler(handler.GetSessionList)))
+	publicAPI.GET(GetLiveSessionsURL, gateway.Handler(handler.GetLiveSessions), echomiddleware.RequiresAPIKeyScope(guard.SessionDetails))
+	publicAPI.GET(GetTopViewedSessionsURL, gateway.Handler(handler.GetTopViewedSessions), echomiddleware.RequiresAPIKeyScope(guard.SessionDetails))
 	publicAPI.GET(GetSessionURL, apiMiddleware.Authorize(gateway.Handler(handler.GetSession)))
 	publicAPI.GET(PlaySessionURL, gateway.Handler(handler.PlaySession))
-	publicAPI.DELETE(RecordSessionURL, gateway.Handler(handler.DeleteRecordedSession))
+	publicAPI.GET(RecordingAnalysisURL, gateway.Handler(handler.GetRecordingAnalysis), echomiddleware.RequiresAPIKeyScope(guard.SessionPlay))
+	publicAPI.DELETE(RecordSessionURL, gateway.Handler(handler.DeleteRecordedSession), echomiddleware.RequiresAPIKeyScope(guard.SessionRemove))
+	publicAPI.POST(TransferSessionURL, gateway.Handler(handler.TransferSession), apiMiddleware.BlockAPIKey)
+	publicAPI.GET(WatchSessionURL, gateway.Handler(handler.WatchSession), echomiddleware.RequiresAPIKeyScope(guard.SessionDetails))
+	if handler.s3 != nil {
+		publicAPI.POST(ExportSessionURL, gateway.Handler(handler.ExportSession), echomiddleware.RequiresAPIKeyScope(guard.SessionExport))
+	}
 
 	publicAPI.GET(GetStatsURL, apiMiddleware.Authorize(gateway.Handler(handler.GetStats)))
 	publicAPI.GET(GetSystemInfoURL, gateway.Handler(handler.GetSystemInfo))
@@ -98,16 +145,46 @@ func NewRouter(service services.Service) *echo.Echo {
 	publicAPI.POST(AddPublicKeyTagURL, gateway.Handler(handler.AddPublicKeyTag))
 	publicAPI.DELETE(RemovePublicKeyTagURL, gateway.Handler(handler.RemovePublicKeyTag))
 	publicAPI.PUT(UpdatePublicKeyTagsURL, gateway.Handler(handler.UpdatePublicKeyTags))
+	publicAPI.POST(ImportPublicKeysURL, gateway.Handler(handler.ImportPublicKeys), apiMiddleware.BlockAPIKey)
 
 	publicAPI.GET(ListNamespaceURL, gateway.Handler(handler.GetNamespaceList))
 	publicAPI.GET(GetNamespaceURL, gateway.Handler(handler.GetNamespace))
 	publicAPI.POST(CreateNamespaceURL, gateway.Handler(handler.CreateNamespace))
+	publicAPI.POST(RequestNamespaceDeletionURL, gateway.Handler(handler.RequestNamespaceDeletion))
 	publicAPI.DELETE(DeleteNamespaceURL, gateway.Handler(handler.DeleteNamespace))
 	publicAPI.PUT(EditNamespaceURL, gateway.Handler(handler.EditNamespace))
 	publicAPI.POST(AddNamespaceUserURL, gateway.Handler(handler.AddNamespaceUser))
 	publicAPI.DELETE(RemoveNamespaceUserURL, gateway.Handler(handler.RemoveNamespaceUser))
 	publicAPI.PATCH(EditNamespaceUserURL, gateway.Handler(handler.EditNamespaceUser))
+	publicAPI.GET(ExportNamespaceMembersURL, gateway.Handler(handler.ExportNamespaceMembers))
+	publicAPI.GET(ListNamespaceMembersURL, gateway.Handler(handler.ListNamespaceMembers))
+	publicAPI.GET(ListNamespaceMemberTagsURL, gateway.Handler(handler.ListNamespaceMemberTags))
+	publicAPI.PUT(SetNamespaceMemberTagsURL, gateway.Handler(handler.SetNamespaceMemberTags))
+	publicAPI.PUT(SetNamespaceMemberAccessScheduleURL, gateway.Handler(handler.SetNamespaceMemberAccessSchedule))
+	publicAPI.DELETE(DeleteNamespaceMemberAccessScheduleURL, gateway.Handler(handler.DeleteNamespaceMemberAccessSchedule))
+	publicAPI.GET(GetNamespaceSettingsURL, gateway.Handler(handler.GetNamespaceSettings))
+	publicAPI.PATCH(UpdateNamespaceSettingsURL, gateway.Handler(handler.UpdateNamespaceSettings))
+	publicAPI.PUT(SetNamespaceDefaultEnvVarsURL, gateway.Handler(handler.SetNamespaceDefaultEnvVars))
+	publicAPI.POST(RenewNamespaceURL, gateway.Handler(handler.RenewNamespace))
+	publicAPI.POST(SendNamespaceUsageReportURL, gateway.Handler(handler.SendNamespaceUsageReport))
+	publicAPI.POST(RotateNamespaceEnrollmentKeyURL, gateway.Handler(handler.RotateNamespaceEnrollmentKey), apiMiddleware.BlockAPIKey)
+	publicAPI.GET(GetNamespaceWebhookURL, gateway.Handler(handler.GetNamespaceWebhook), apiMiddleware.BlockAPIKey)
+	publicAPI.PUT(SetNamespaceWebhookURL, gateway.Handler(handler.SetNamespaceWebhook), apiMiddleware.BlockAPIKey)
+	publicAPI.DELETE(DeleteNamespaceWebhookURL, gateway.Handler(handler.DeleteNamespaceWebhook), apiMiddleware.BlockAPIKey)
+	publicAPI.GET(ListWebhookDeadLettersURL, gateway.Handler(handler.ListWebhookDeadLetters), apiMiddleware.BlockAPIKey)
+	publicAPI.POST(CreateInviteLinkURL, gateway.Handler(handler.CreateInviteLink), apiMiddleware.BlockAPIKey)
+	publicAPI.GET(ListInviteLinksURL, gateway.Handler(handler.ListInviteLinks), apiMiddleware.BlockAPIKey)
+	publicAPI.DELETE(DeleteInviteLinkURL, gateway.Handler(handler.DeleteInviteLink), apiMiddleware.BlockAPIKey)
+	publicAPI.GET(GetInviteLinkURL, gateway.Handler(handler.GetInviteLink))
+	publicAPI.POST(JoinInviteLinkURL, gateway.Handler(handler.JoinInviteLink), apiMiddleware.BlockAPIKey)
 	publicAPI.GET(HealthCheckURL, gateway.Handler(handler.EvaluateHealth))
+	publicAPI.GET(VersionURL, gateway.Handler(handler.GetVersion))
+
+	publicAPI.GET(ListPlansURL, gateway.Handler(handler.ListPlans))
+
+	publicAPI.POST(BulkCreateFirewallRulesURL, gateway.Handler(handler.BulkCreateFirewallRules), echomiddleware.RequiresAPIKeyScope(guard.FirewallCreate))
+	publicAPI.GET(ListFirewallConflictsURL, gateway.Handler(handler.ListFirewallConflicts), echomiddleware.RequiresAPIKeyScope(guard.FirewallCreate, guard.FirewallEdit))
+	publicAPI.POST(PreviewFirewallRuleURL, gateway.Handler(handler.PreviewFirewallRule), echomiddleware.RequiresAPIKeyScope(guard.FirewallCreate))
 
 	return e
 }
@@ -1,10 +1,18 @@
 package routes
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/labstack/echo/v4"
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/replay"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	"github.com/shellhub-io/shellhub/pkg/models"
@@ -18,23 +26,57 @@ const (
 	FinishSessionURL    = "/sessions/:uid/finish"
 	KeepAliveSessionURL = "/sessions/:uid/keepalive"
 	RecordSessionURL    = "/sessions/:uid/record"
-	PlaySessionURL      = "/sessions/:uid/play"
+	// RecordSessionStreamURL streams a session's recording, encoded by [recording.WriteFrame], to the server.
+	RecordSessionStreamURL = "/sessions/:uid/record/stream"
+	PlaySessionURL         = "/sessions/:uid/play"
+	// RecordingAnalysisURL analyzes the idle gaps and the activity of a session's recording.
+	RecordingAnalysisURL = "/sessions/:uid/recording/analysis"
+	GetLiveSessionsURL   = "/sessions/live"
+	// GetTopViewedSessionsURL lists the sessions of the namespace with the most viewed recordings.
+	GetTopViewedSessionsURL = "/sessions/top-viewed"
+	ExportSessionURL        = "/sessions/:uid/export"
+	TransferSessionURL      = "/sessions/:uid/transfer"
+	// WatchSessionURL streams, as server-sent events, the frames of an active session's recording as they're received.
+	WatchSessionURL = "/sessions/:uid/live"
 )
 
 const (
 	ParamSessionID = "uid"
 )
 
+// RecordSessionStreamMaxSize is the maximum number of bytes of a recording streamed on a single request. Larger
+// recordings are streamed on subsequent requests, resuming from the bytes already received.
+const RecordSessionStreamMaxSize = 1 << 30
+
+// PlaySessionFlushFrames is the number of frames of a recording written to the client before the response is flushed.
+const PlaySessionFlushFrames = 64
+
 func (h *Handler) GetSessionList(c gateway.Context) error {
-	paginator := query.NewPaginator()
-	if err := c.Bind(paginator); err != nil {
+	type Query struct {
+		query.Paginator
+		query.Sorter
+		query.Filters
+	}
+
+	query := Query{
+		Paginator: *query.NewPaginator(),
+		Sorter:    *query.NewSorter(),
+		Filters:   *query.NewFilters(),
+	}
+
+	if err := c.Bind(&query); err != nil {
 		return err
 	}
 
 	// TODO: normalize is not required when request is privileged
-	paginator.Normalize()
+	query.Paginator.Normalize()
+	query.Sorter.Normalize()
 
-	sessions, count, err := h.service.ListSessions(c.Ctx(), *paginator)
+	if err := query.Filters.Unmarshal(); err != nil {
+		return err
+	}
+
+	sessions, count, err := h.service.ListSessions(c.Ctx(), query.Paginator, query.Filters, query.Sorter)
 	if err != nil {
 		return err
 	}
@@ -44,6 +86,25 @@ func (h *Handler) GetSessionList(c gateway.Context) error {
 	return c.JSON(http.StatusOK, sessions)
 }
 
+func (h *Handler) GetLiveSessions(c gateway.Context) error {
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var sessions []models.LiveSession
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Session.Details, func() error {
+		var err error
+		sessions, err = h.service.ListLiveSessions(c.Ctx(), tenant)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, sessions)
+}
+
 func (h *Handler) GetSession(c gateway.Context) error {
 	var req requests.SessionGet
 	if err := c.Bind(&req); err != nil {
@@ -73,8 +134,11 @@ func (h *Handler) UpdateSession(c gateway.Context) error {
 	}
 
 	return h.service.UpdateSession(c.Ctx(), models.UID(req.UID), models.SessionUpdate{
-		Authenticated: req.Authenticated,
-		Type:          req.Type,
+		Authenticated:           req.Authenticated,
+		Type:                    req.Type,
+		RecordingPausedDuration: req.RecordingPausedDuration,
+		ClientFingerprint:       req.ClientFingerprint,
+		MaxDurationExceeded:     req.MaxDurationExceeded,
 	})
 }
 
@@ -126,10 +190,270 @@ func (h *Handler) RecordSession(c gateway.Context) error {
 	return c.NoContent(http.StatusOK)
 }
 
+// RecordSessionStream stores the recording streamed on the request's body. The Range header, formatted as
+// `bytes=<offset>-`, resumes an interrupted upload from the bytes already received; when the offset doesn't match them,
+// it responds with [http.StatusRequestedRangeNotSatisfiable] and the upload's state.
+func (h *Handler) RecordSessionStream(c gateway.Context) error {
+	if !strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEOctetStream) {
+		return c.NoContent(http.StatusUnsupportedMediaType)
+	}
+
+	req := requests.SessionIDParam{UID: c.Param(ParamSessionID)}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var offset int64
+	if value := c.Request().Header.Get("Range"); value != "" {
+		var err error
+		if offset, err = strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(value, "bytes="), "-"), 10, 64); err != nil || offset < 0 {
+			return c.NoContent(http.StatusBadRequest)
+		}
+	}
+
+	session, err := h.service.GetSession(c.Ctx(), models.UID(req.UID))
+	if err != nil {
+		return err
+	}
+
+	state := new(models.RecordingUploadState)
+	if session.RecordUpload != nil {
+		state = session.RecordUpload
+	}
+
+	if offset != state.BytesReceived {
+		c.Response().Header().Set("Content-Range", fmt.Sprintf("bytes */%d", state.BytesReceived))
+
+		return c.JSON(http.StatusRequestedRangeNotSatisfiable, state)
+	}
+
+	// NOTICE: the frames are decoded while the body is read, so the memory used is bounded by the largest frame and the
+	// batch being inserted, not by the size of the recording.
+	body := &io.LimitedReader{R: c.Request().Body, N: RecordSessionStreamMaxSize}
+
+	state, err = h.service.UploadSessionRecord(c.Ctx(), models.UID(req.UID), offset, body)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, state)
+}
+
+func (h *Handler) GetTopViewedSessions(c gateway.Context) error {
+	var req requests.SessionTopViewed
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if req.Limit == 0 {
+		req.Limit = requests.SessionTopViewedDefaultLimit
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var sessions []models.Session
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Session.Details, func() error {
+		var err error
+		sessions, err = h.service.ListTopViewedSessions(c.Ctx(), tenant, req.Limit)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, sessions)
+}
+
 func (h *Handler) PlaySession(c gateway.Context) error {
-	return c.NoContent(http.StatusOK)
+	var req requests.SessionPlay
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if req.Speed == 0 {
+		req.Speed = replay.DefaultSpeed
+	}
+
+	loc := time.UTC
+	if req.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(req.Timezone); err != nil {
+			return err
+		}
+	}
+
+	return guard.EvaluatePermission(c.Role(), guard.Actions.Session.Play, func() error {
+		// NOTICE: the session is read first, scoped to the request's tenant, so the view count is only incremented for
+		// sessions the user is allowed to see.
+		if _, err := h.service.GetSession(c.Ctx(), models.UID(req.UID)); err != nil {
+			return err
+		}
+
+		writer, err := replay.NewPlaybackWriter(c.Response(), req.Speed, req.MaxFrameGapMS, loc)
+		if err != nil {
+			return err
+		}
+
+		// NOTICE: the frames are written while read from the store, so the recording is never held in memory. The
+		// status is only sent with the first frame written, what still allows an error to be sent when the recording
+		// can't be read. When the client goes away, the request's context is canceled, stopping the store's cursor.
+		c.Response().Header().Set(echo.HeaderContentType, replay.ContentType)
+
+		var written int
+		if err := h.service.StreamSessionRecordFrames(c.Ctx(), models.UID(req.UID), func(frame *models.RecordedSession) error {
+			if err := writer.WriteFrame(frame); err != nil {
+				return err
+			}
+
+			if written++; written%PlaySessionFlushFrames == 0 {
+				c.Response().Flush()
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if err := writer.Close(); err != nil {
+			return err
+		}
+
+		c.Response().Flush()
+
+		// NOTICE: the view is only counted once the whole recording was streamed, so a playback that fails, or that
+		// the client gives up on, isn't counted.
+		return h.service.IncrementSessionViewCount(c.Ctx(), models.UID(req.UID))
+	})
+}
+
+func (h *Handler) GetRecordingAnalysis(c gateway.Context) error {
+	var req requests.SessionGet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var analysis *models.RecordingAnalysis
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Session.Play, func() error {
+		var err error
+		analysis, err = h.service.AnalyzeRecording(c.Ctx(), req.UID)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, analysis)
 }
 
 func (h *Handler) DeleteRecordedSession(c gateway.Context) error {
 	return c.NoContent(http.StatusOK)
 }
+
+func (h *Handler) ExportSession(c gateway.Context) error {
+	var req requests.SessionExport
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var location string
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Session.Export, func() error {
+		var err error
+		location, err = h.service.SessionExportS3(c.Ctx(), req.UID, h.s3)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"location": location})
+}
+
+// TransferSession transfers an active session from the authenticated user to another member of the namespace.
+func (h *Handler) TransferSession(c gateway.Context) error {
+	var req requests.SessionTransfer
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Session.Close, func() error {
+		return h.service.TransferSession(c.Ctx(), req.UID, uid, req.MemberID)
+	}); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// WatchSession streams the frames of an active session's recording to the client as server-sent events, each one with
+// a JSON-encoded [models.SessionFrame], until the client disconnects.
+func (h *Handler) WatchSession(c gateway.Context) error {
+	var req requests.SessionWatch
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var frames <-chan models.SessionFrame
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.Session.Details, func() error {
+		var err error
+		frames, err = h.service.WatchSession(c.Ctx(), req.UID)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/event-stream")
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+	c.Response().Header().Set(echo.HeaderConnection, "keep-alive")
+	c.Response().WriteHeader(http.StatusOK)
+	c.Response().Flush()
+
+	// NOTICE: the frames' channel is closed by the service when the request's context is done, what happens when the
+	// client disconnects.
+	for frame := range frames {
+		data, err := json.Marshal(frame)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(c.Response(), "event: frame\ndata: %s\n\n", data); err != nil {
+			return err
+		}
+
+		c.Response().Flush()
+	}
+
+	return nil
+}
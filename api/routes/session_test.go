@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,12 +9,14 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	svc "github.com/shellhub-io/shellhub/api/services"
 
 	"github.com/shellhub-io/shellhub/api/store"
 
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/replay"
 	"github.com/shellhub-io/shellhub/api/services/mocks"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
@@ -42,7 +45,7 @@ func TestGetSessionList(t *testing.T) {
 				PerPage: 10,
 			},
 			requiredMocks: func(paginator query.Paginator) {
-				mock.On("ListSessions", gomock.Anything, paginator).Return(nil, 0, svc.ErrNotFound).Once()
+				mock.On("ListSessions", gomock.Anything, paginator, query.Filters{}, query.Sorter{Order: query.OrderDesc}).Return(nil, 0, svc.ErrNotFound).Once()
 			},
 			expected: Expected{
 				expectedSession: nil,
@@ -57,7 +60,7 @@ func TestGetSessionList(t *testing.T) {
 			},
 			requiredMocks: func(paginator query.Paginator) {
 				ss := []models.Session{}
-				mock.On("ListSessions", gomock.Anything, paginator).Return(ss, 1, nil).Once()
+				mock.On("ListSessions", gomock.Anything, paginator, query.Filters{}, query.Sorter{Order: query.OrderDesc}).Return(ss, 1, nil).Once()
 			},
 			expected: Expected{
 				expectedSession: []models.Session{},
@@ -189,6 +192,19 @@ func TestCreateSession(t *testing.T) {
 			requiredMocks:  func() {},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			title: "fails when the body exceeds the payload limit",
+			request: requests.SessionCreate{
+				UID:       "1234",
+				DeviceUID: "xyz789",
+				Username:  "johndoe",
+				IPAddress: "192.168.0.1",
+				Type:      "session",
+				Term:      strings.Repeat("x", DefaultSessionPayloadLimit),
+			},
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
 		{
 			title: "fails when try to creating a non-existing session",
 			request: requests.SessionCreate{
@@ -312,3 +328,784 @@ func TestFinishSession(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestKeepAliveSession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title          string
+		uid            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title: "fails when try to keep alive a non-existing session",
+			uid:   "1234",
+			requiredMocks: func() {
+				mock.On("KeepAliveSession", gomock.Anything, models.UID("1234")).Return(svc.NewErrSessionNotFound(models.UID("1234"), store.ErrNoDocuments)).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "success when try to keep alive an existing session",
+			uid:   "123",
+			requiredMocks: func() {
+				mock.On("KeepAliveSession", gomock.Anything, models.UID("123")).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/internal/sessions/%s/keepalive", tc.uid), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", guard.RoleOwner)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestUpdateSession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title          string
+		uid            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title: "fails when try to update a non-existing session",
+			uid:   "1234",
+			requiredMocks: func() {
+				mock.On("UpdateSession", gomock.Anything, models.UID("1234"), models.SessionUpdate{}).Return(svc.NewErrSessionNotFound(models.UID("1234"), store.ErrNoDocuments)).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "success when try to update an existing session",
+			uid:   "123",
+			requiredMocks: func() {
+				mock.On("UpdateSession", gomock.Anything, models.UID("123"), models.SessionUpdate{}).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/internal/sessions/%s", tc.uid), strings.NewReader("{}"))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", guard.RoleOwner)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestRecordSessionStream(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title          string
+		contentType    string
+		rangeHeader    string
+		requiredMocks  func()
+		expectedStatus int
+		expectedRange  string
+	}{
+		{
+			title:          "fails when the body isn't a binary stream",
+			contentType:    "application/json",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			title:          "fails when the range is malformed",
+			contentType:    "application/octet-stream",
+			rangeHeader:    "bytes=abc-",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:       "fails when the session doesn't exist",
+			contentType: "application/octet-stream",
+			requiredMocks: func() {
+				mock.On("GetSession", gomock.Anything, models.UID("uid")).Return(nil, svc.NewErrSessionNotFound(models.UID("uid"), store.ErrNoDocuments)).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title:       "fails when the range doesn't match the bytes received",
+			contentType: "application/octet-stream",
+			rangeHeader: "bytes=10-",
+			requiredMocks: func() {
+				mock.On("GetSession", gomock.Anything, models.UID("uid")).Return(&models.Session{RecordUpload: &models.RecordingUploadState{BytesReceived: 42}}, nil).Once()
+			},
+			expectedStatus: http.StatusRequestedRangeNotSatisfiable,
+			expectedRange:  "bytes */42",
+		},
+		{
+			title:       "success when resuming the upload",
+			contentType: "application/octet-stream",
+			rangeHeader: "bytes=42-",
+			requiredMocks: func() {
+				mock.On("GetSession", gomock.Anything, models.UID("uid")).Return(&models.Session{RecordUpload: &models.RecordingUploadState{BytesReceived: 42}}, nil).Once()
+				mock.On("UploadSessionRecord", gomock.Anything, models.UID("uid"), int64(42), gomock.Anything).Return(&models.RecordingUploadState{BytesReceived: 63, FramesStored: 3}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/internal/sessions/uid/record/stream", strings.NewReader("frames"))
+			req.Header.Set("Content-Type", tc.contentType)
+			if tc.rangeHeader != "" {
+				req.Header.Set("Range", tc.rangeHeader)
+			}
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			assert.Equal(t, tc.expectedRange, rec.Result().Header.Get("Content-Range"))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestPlaySession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	records := []models.RecordedSession{
+		{UID: "123", Message: "a", Time: start, Width: 80, Height: 24},
+		{UID: "123", Message: "b", Time: start.Add(2 * time.Second)},
+		{UID: "123", Message: "c", Time: start.Add(12 * time.Second)},
+	}
+
+	stream := func(_ context.Context, _ models.UID, fn func(*models.RecordedSession) error) error {
+		for i := range records {
+			if err := fn(&records[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		query          string
+		role           string
+		requiredMocks  func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			title:          "fails when speed is out of range",
+			uid:            "123",
+			query:          "?speed=20",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when timezone is invalid",
+			uid:            "123",
+			query:          "?tz=Mars/Olympus_Mons",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when the role can't play sessions",
+			uid:            "123",
+			role:           guard.RoleObserver,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "fails when try to play a non-existing session",
+			uid:   "1234",
+			requiredMocks: func() {
+				mock.On("GetSession", gomock.Anything, models.UID("1234")).Return(nil, svc.ErrSessionNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "fails when the recording can't be read",
+			uid:   "123",
+			requiredMocks: func() {
+				mock.On("GetSession", gomock.Anything, models.UID("123")).Return(&models.Session{UID: "123"}, nil).Once()
+				mock.On("StreamSessionRecordFrames", gomock.Anything, models.UID("123"), gomock.Anything).Return(svc.ErrSessionNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "success when try to play an existing session",
+			uid:   "123",
+			requiredMocks: func() {
+				mock.On("GetSession", gomock.Anything, models.UID("123")).Return(&models.Session{UID: "123"}, nil).Once()
+				mock.On("IncrementSessionViewCount", gomock.Anything, models.UID("123")).Return(nil).Once()
+				mock.On("StreamSessionRecordFrames", gomock.Anything, models.UID("123"), gomock.Anything).Return(stream).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"version":2,"width":80,"height":24,"timestamp":1672574400,"time":"2023-01-01T12:00:00Z"}
+[0,"o","a"]
+[2,"o","b"]
+[12,"o","c"]
+`,
+		},
+		{
+			title: "success when try to play an existing session with speed and maximum gap",
+			uid:   "123",
+			query: "?speed=2&max_gap=3000",
+			requiredMocks: func() {
+				mock.On("GetSession", gomock.Anything, models.UID("123")).Return(&models.Session{UID: "123"}, nil).Once()
+				mock.On("IncrementSessionViewCount", gomock.Anything, models.UID("123")).Return(nil).Once()
+				mock.On("StreamSessionRecordFrames", gomock.Anything, models.UID("123"), gomock.Anything).Return(stream).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"version":2,"width":80,"height":24,"timestamp":1672574400,"time":"2023-01-01T12:00:00Z"}
+[0,"o","a"]
+[1,"o","b"]
+[4,"o","c"]
+`,
+		},
+		{
+			title: "success when try to play an existing session in a non-UTC timezone",
+			uid:   "123",
+			query: "?tz=America/New_York",
+			requiredMocks: func() {
+				mock.On("GetSession", gomock.Anything, models.UID("123")).Return(&models.Session{UID: "123"}, nil).Once()
+				mock.On("IncrementSessionViewCount", gomock.Anything, models.UID("123")).Return(nil).Once()
+				mock.On("StreamSessionRecordFrames", gomock.Anything, models.UID("123"), gomock.Anything).Return(stream).Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"version":2,"width":80,"height":24,"timestamp":1672574400,"time":"2023-01-01T07:00:00-05:00"}
+[0,"o","a"]
+[2,"o","b"]
+[12,"o","c"]
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/sessions/%s/play%s", tc.uid, tc.query), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", guard.RoleOwner)
+			if tc.role != "" {
+				req.Header.Set("X-Role", tc.role)
+			}
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			if tc.expectedBody != "" {
+				assert.Equal(t, replay.ContentType, rec.Header().Get("Content-Type"))
+				assert.Equal(t, tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestGetLiveSessions(t *testing.T) {
+	mock := new(mocks.Service)
+
+	type Expected struct {
+		sessions []models.LiveSession
+		status   int
+	}
+
+	cases := []struct {
+		description   string
+		role          string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description:   "fails when role is invalid",
+			role:          "invalid",
+			requiredMocks: func() {},
+			expected:      Expected{status: http.StatusForbidden},
+		},
+		{
+			description: "fails when the service fails",
+			role:        guard.RoleOwner,
+			requiredMocks: func() {
+				mock.
+					On("ListLiveSessions", gomock.Anything, "00000000-0000-4000-0000-000000000000").
+					Return(nil, svc.ErrNotFound).
+					Once()
+			},
+			expected: Expected{status: http.StatusNotFound},
+		},
+		{
+			description: "succeeds",
+			role:        guard.RoleObserver,
+			requiredMocks: func() {
+				mock.
+					On("ListLiveSessions", gomock.Anything, "00000000-0000-4000-0000-000000000000").
+					Return([]models.LiveSession{
+						{UID: "memory", InMemory: true, Stored: false},
+						{UID: "stored", InMemory: false, Stored: true},
+					}, nil).
+					Once()
+			},
+			expected: Expected{
+				sessions: []models.LiveSession{
+					{UID: "memory", InMemory: true, Stored: false},
+					{UID: "stored", InMemory: false, Stored: true},
+				},
+				status: http.StatusOK,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/sessions/live", nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "00000000-0000-4000-0000-000000000000")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Result().StatusCode)
+
+			if tc.expected.sessions != nil {
+				var sessions []models.LiveSession
+				assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&sessions))
+				assert.Equal(t, tc.expected.sessions, sessions)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestGetTopViewedSessions(t *testing.T) {
+	mock := new(mocks.Service)
+
+	type Expected struct {
+		sessions []models.Session
+		status   int
+	}
+
+	cases := []struct {
+		description   string
+		query         string
+		role          string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description:   "fails when role is invalid",
+			role:          "invalid",
+			requiredMocks: func() {},
+			expected:      Expected{status: http.StatusForbidden},
+		},
+		{
+			description:   "fails when the limit is out of range",
+			query:         "?limit=1000",
+			role:          guard.RoleOwner,
+			requiredMocks: func() {},
+			expected:      Expected{status: http.StatusBadRequest},
+		},
+		{
+			description: "succeeds with the default limit",
+			role:        guard.RoleObserver,
+			requiredMocks: func() {
+				mock.
+					On("ListTopViewedSessions", gomock.Anything, "00000000-0000-4000-0000-000000000000", requests.SessionTopViewedDefaultLimit).
+					Return([]models.Session{{UID: "viewed", ViewCount: 3}}, nil).
+					Once()
+			},
+			expected: Expected{
+				sessions: []models.Session{{UID: "viewed", ViewCount: 3}},
+				status:   http.StatusOK,
+			},
+		},
+		{
+			description: "succeeds with the requested limit",
+			query:       "?limit=1",
+			role:        guard.RoleOwner,
+			requiredMocks: func() {
+				mock.
+					On("ListTopViewedSessions", gomock.Anything, "00000000-0000-4000-0000-000000000000", 1).
+					Return([]models.Session{{UID: "viewed", ViewCount: 3}}, nil).
+					Once()
+			},
+			expected: Expected{
+				sessions: []models.Session{{UID: "viewed", ViewCount: 3}},
+				status:   http.StatusOK,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/sessions/top-viewed"+tc.query, nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "00000000-0000-4000-0000-000000000000")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Result().StatusCode)
+
+			if tc.expected.sessions != nil {
+				var sessions []models.Session
+				assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&sessions))
+				assert.Equal(t, tc.expected.sessions, sessions)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestExportSession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cfg := &models.S3Config{Bucket: "sessions"}
+
+	cases := []struct {
+		description    string
+		uid            string
+		role           string
+		options        []Option
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when the export is disabled",
+			uid:            "1234",
+			role:           guard.RoleOwner,
+			options:        nil,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description:    "fails when role is observer",
+			uid:            "1234",
+			role:           guard.RoleObserver,
+			options:        []Option{WithSessionExport(cfg)},
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description:    "fails when role is operator",
+			uid:            "1234",
+			role:           guard.RoleOperator,
+			options:        []Option{WithSessionExport(cfg)},
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when the session is not found",
+			uid:         "1234",
+			role:        guard.RoleOwner,
+			options:     []Option{WithSessionExport(cfg)},
+			requiredMocks: func() {
+				mock.
+					On("SessionExportS3", gomock.Anything, "1234", cfg).
+					Return("", svc.ErrSessionNotFound).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to export the session",
+			uid:         "1234",
+			role:        guard.RoleOwner,
+			options:     []Option{WithSessionExport(cfg)},
+			requiredMocks: func() {
+				mock.
+					On("SessionExportS3", gomock.Anything, "1234", cfg).
+					Return("http://minio:9000/sessions/tenant/1234.cast.gz", nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/sessions/%s/export", tc.uid), nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock, tc.options...)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestGetRecordingAnalysis(t *testing.T) {
+	mock := new(mocks.Service)
+
+	analysis := &models.RecordingAnalysis{
+		TotalDuration:  10,
+		ActiveDuration: 4,
+		IdleGaps:       []models.IdleGap{{StartMS: 2000, EndMS: 8000, DurationMS: 6000}},
+		BytesOutput:    42,
+	}
+
+	cases := []struct {
+		description    string
+		role           string
+		requiredMocks  func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			description:    "fails when role is observer",
+			role:           guard.RoleObserver,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when the session is not found",
+			role:        guard.RoleOwner,
+			requiredMocks: func() {
+				mock.
+					On("AnalyzeRecording", gomock.Anything, "1234").
+					Return(nil, svc.NewErrSessionNotFound(models.UID("1234"), nil)).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to analyze the recording",
+			role:        guard.RoleOwner,
+			requiredMocks: func() {
+				mock.
+					On("AnalyzeRecording", gomock.Anything, "1234").
+					Return(analysis, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"total_duration":10,"active_duration":4,` +
+				`"idle_gaps":[{"start_ms":2000,"end_ms":8000,"duration_ms":6000}],"characters_typed":0,"bytes_output":42}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/sessions/1234/recording/analysis", nil)
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			if tc.expectedBody != "" {
+				assert.JSONEq(t, tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestTransferSession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		role           string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when the member is missing",
+			role:           guard.RoleOwner,
+			body:           `{}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "fails when role is operator",
+			role:           guard.RoleOperator,
+			body:           `{"member_id":"to"}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when the client rejects the transfer",
+			role:        guard.RoleAdministrator,
+			body:        `{"member_id":"to"}`,
+			requiredMocks: func() {
+				mock.
+					On("TransferSession", gomock.Anything, "1234", "507f1f77bcf86cd799439011", "to").
+					Return(svc.NewErrSessionTransferRejected(nil)).
+					Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds to transfer the session",
+			role:        guard.RoleAdministrator,
+			body:        `{"member_id":"to"}`,
+			requiredMocks: func() {
+				mock.
+					On("TransferSession", gomock.Anything, "1234", "507f1f77bcf86cd799439011", "to").
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/sessions/1234/transfer", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", "507f1f77bcf86cd799439011")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestWatchSession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	framesOf := func(frames ...models.SessionFrame) <-chan models.SessionFrame {
+		ch := make(chan models.SessionFrame, len(frames))
+		for _, frame := range frames {
+			ch <- frame
+		}
+
+		close(ch)
+
+		return ch
+	}
+
+	cases := []struct {
+		description    string
+		role           string
+		requiredMocks  func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			description: "fails when live monitoring is disabled",
+			role:        guard.RoleObserver,
+			requiredMocks: func() {
+				mock.
+					On("WatchSession", gomock.Anything, "1234").
+					Return(nil, svc.NewErrSessionLiveDisabled(nil)).
+					Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when the session has the maximum number of watchers",
+			role:        guard.RoleObserver,
+			requiredMocks: func() {
+				mock.
+					On("WatchSession", gomock.Anything, "1234").
+					Return(nil, svc.NewErrSessionLiveMonitorsLimit(5, nil)).
+					Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds to stream the frames",
+			role:        guard.RoleObserver,
+			requiredMocks: func() {
+				mock.
+					On("WatchSession", gomock.Anything, "1234").
+					Return(framesOf(models.SessionFrame{Time: time.Unix(0, 0).UTC(), Message: "ls", Width: 80, Height: 24}), nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "event: frame\ndata: {\"time\":\"1970-01-01T00:00:00Z\",\"message\":\"ls\",\"width\":80,\"height\":24}\n\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/sessions/1234/live", nil)
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant-id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+				assert.Equal(t, tc.expectedBody, rec.Body.String())
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
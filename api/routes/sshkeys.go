@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	echomiddleware "github.com/shellhub-io/shellhub/api/pkg/echo/middleware"
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
 	"github.com/shellhub-io/shellhub/api/store"
@@ -24,6 +25,7 @@ const (
 	AddPublicKeyTagURL     = "/sshkeys/public-keys/:fingerprint/tags"      // Add a tag to a public key.
 	RemovePublicKeyTagURL  = "/sshkeys/public-keys/:fingerprint/tags/:tag" // Remove a tag to a public key.
 	UpdatePublicKeyTagsURL = "/sshkeys/public-keys/:fingerprint/tags"      // Update all tags from a public key.
+	ImportPublicKeysURL    = "/namespaces/:tenant/pubkeys/import"          // Import the public keys of a user account.
 )
 
 const (
@@ -89,6 +91,20 @@ func (h *Handler) CreatePublicKey(c gateway.Context) error {
 		req.TenantID = tenant
 	}
 
+	// NOTICE: the member who creates the public key has its access schedule enforced on the connections made with it.
+	// When the key is created through an API key, the member is the one who created the API key.
+	switch key := c.Request().Header.Get(echomiddleware.APIKeyHeader); {
+	case c.ID() != nil:
+		req.CreatedBy = c.ID().ID
+	case key != "":
+		apiKey, err := h.service.AuthAPIKey(c.Ctx(), key)
+		if err != nil {
+			return err
+		}
+
+		req.CreatedBy = apiKey.CreatedBy
+	}
+
 	var res *responses.PublicKeyCreate
 	err := guard.EvaluatePermission(c.Role(), guard.Actions.PublicKey.Create, func() error {
 		var err error
@@ -266,3 +282,50 @@ func (h *Handler) UpdatePublicKeyTags(c gateway.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+// ImportPublicKeys imports the public keys of a GitHub or GitLab user account to the namespace, with the username,
+// filter and allowed IPs of the body, like the public keys created directly. The source and the account's username
+// are query parameters, what the binder doesn't bind on POST requests.
+func (h *Handler) ImportPublicKeys(c gateway.Context) error {
+	var req requests.PublicKeyImport
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	req.Source = c.QueryParam("source")
+	req.Account = c.QueryParam("username")
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	req.Tenant = ns.TenantID
+	req.CreatedBy = uid
+
+	var imported int
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.PublicKey.Create, func() error {
+		var err error
+		switch req.Source {
+		case models.PublicKeySourceGitHub:
+			imported, err = h.service.ImportPublicKeysFromGitHub(c.Ctx(), req)
+		case models.PublicKeySourceGitLab:
+			imported, err = h.service.ImportPublicKeysFromGitLab(c.Ctx(), req)
+		}
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, &responses.PublicKeyImport{Imported: imported})
+}
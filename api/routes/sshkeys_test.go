@@ -444,3 +444,141 @@ func TestCreatePrivateKey(t *testing.T) {
 		})
 	}
 }
+
+func TestImportPublicKeys(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "administrator", Role: guard.RoleAdministrator},
+			{ID: "456", Username: "operator", Role: guard.RoleOperator},
+		},
+	}
+
+	importRequest := func(source string) requests.PublicKeyImport {
+		return requests.PublicKeyImport{
+			TenantParam: requests.TenantParam{Tenant: "00000000-0000-4000-0000-000000000000"},
+			Source:      source,
+			Account:     "user",
+			Username:    "deploy",
+			Filter:      requests.PublicKeyFilter{Hostname: "^web-"},
+			AllowedIPs:  []string{"203.0.113.0/24"},
+			CreatedBy:   "123",
+		}
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		query          string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when the source is invalid",
+			uid:            "123",
+			query:          "source=bitbucket&username=user",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when the username is missing",
+			uid:            "123",
+			query:          "source=github",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when the username the keys log in as is missing",
+			uid:            "123",
+			query:          "source=github&username=user",
+			body:           `{"filter":{"hostname":".*"}}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when the filter is missing",
+			uid:            "123",
+			query:          "source=github&username=user",
+			body:           `{"username":"root"}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title: "fails when the namespace is not found",
+			uid:   "123",
+			query: "source=github&username=user",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "fails when the user cannot create public keys on the namespace",
+			uid:   "456",
+			query: "source=github&username=user",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "fails when the namespace reached the import limit",
+			uid:   "123",
+			query: "source=github&username=user",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("ImportPublicKeysFromGitHub", gomock.Anything, importRequest("github")).
+					Return(0, svc.NewErrPublicKeyImportLimit(svc.PublicKeysImportLimit, nil)).
+					Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "succeeds importing from GitHub",
+			uid:   "123",
+			query: "source=github&username=user",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("ImportPublicKeysFromGitHub", gomock.Anything, importRequest("github")).Return(2, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			title: "succeeds importing from GitLab",
+			uid:   "123",
+			query: "source=gitlab&username=user",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("ImportPublicKeysFromGitLab", gomock.Anything, importRequest("gitlab")).Return(1, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			body := tc.body
+			if body == "" {
+				body = `{"username":"deploy","filter":{"hostname":"^web-"},"allowed_ips":["203.0.113.0/24"]}`
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/namespaces/00000000-0000-4000-0000-000000000000/pubkeys/import?"+tc.query, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", guard.RoleAdministrator)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -12,6 +12,11 @@ import (
 const (
 	UpdateUserDataURL     = "/users/:id/data"
 	UpdateUserPasswordURL = "/users/:id/password" //nolint:gosec
+	DeleteUserURL         = "/users/:id"
+	// CompleteUserOnboardingURL marks the onboarding of the user as completed.
+	CompleteUserOnboardingURL = "/users/:id/onboarding"
+	// AssignUserPlanURL assigns a plan to the user, whose limits are used in place of the user's own.
+	AssignUserPlanURL = "/users/:id/plan"
 )
 
 const (
@@ -73,3 +78,66 @@ func (h *Handler) UpdateUserPassword(c gateway.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+func (h *Handler) DeleteUser(c gateway.Context) error {
+	var req requests.UserDelete
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var requesterID string
+	if id := c.ID(); id != nil {
+		requesterID = id.ID
+	}
+
+	if err := h.service.DeleteUser(c.Ctx(), req.ID, requesterID, req.GDPR); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CompleteUserOnboarding marks the onboarding of the authenticated user as completed.
+func (h *Handler) CompleteUserOnboarding(c gateway.Context) error {
+	var req requests.UserParam
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if c.ID() == nil || req.ID != c.ID().ID {
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	if err := h.service.CompleteOnboarding(c.Ctx(), req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// AssignUserPlan assigns a plan to a user. It's only exposed on the internal API, as the plan raises the user's limits
+// and must not be chosen by the user itself.
+func (h *Handler) AssignUserPlan(c gateway.Context) error {
+	var req requests.UserPlanAssign
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.service.AssignPlan(c.Ctx(), req.ID, req.Plan); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+)
+
+const (
+	ListUserSessionsURL  = "/users/sessions"
+	RevokeUserSessionURL = "/users/sessions/:id"
+)
+
+func (h *Handler) ListUserSessions(c gateway.Context) error {
+	if c.ID() == nil {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	sessions, err := h.service.ListUserSessions(c.Ctx(), c.ID().ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, sessions)
+}
+
+func (h *Handler) RevokeUserSession(c gateway.Context) error {
+	var req requests.UserSessionRevoke
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if c.ID() == nil {
+		return c.NoContent(http.StatusUnauthorized)
+	}
+
+	if err := h.service.RevokeUserSession(c.Ctx(), c.ID().ID, req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestListUserSessions(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		headers        map[string]string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when the user is not logged in",
+			headers:        map[string]string{},
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			description:    "fails when authenticated by an API key",
+			headers:        map[string]string{"X-ID": "user", "X-API-KEY": "key"},
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "succeeds to list the sessions",
+			headers:     map[string]string{"X-ID": "user"},
+			requiredMocks: func() {
+				mock.
+					On("ListUserSessions", gomock.Anything, "user").
+					Return([]models.UserSession{{ID: "session", UserID: "user"}}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users/sessions", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestRevokeUserSession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description: "fails when the session is not found",
+			requiredMocks: func() {
+				mock.On("RevokeUserSession", gomock.Anything, "user", "session").Return(svc.ErrUserSessionNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds to revoke the session",
+			requiredMocks: func() {
+				mock.On("RevokeUserSession", gomock.Anything, "user", "session").Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/users/sessions/session", nil)
+			req.Header.Set("X-ID", "user")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
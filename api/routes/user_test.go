@@ -24,6 +24,10 @@ func TestUpdateUserData(t *testing.T) {
 
 	svcMock := new(mocks.Service)
 
+	toPointer := func(s string) *string {
+		return &s
+	}
+
 	cases := []struct {
 		description   string
 		headers       map[string]string
@@ -61,6 +65,18 @@ func TestUpdateUserData(t *testing.T) {
 			requiredMocks: func() {},
 			expected:      Expected{http.StatusBadRequest},
 		},
+		{
+			description: "fails when bind fails to validate timezone",
+			headers: map[string]string{
+				"X-ID":   "000000000000000000000000",
+				"X-Role": "owner",
+			},
+			body: requests.UserDataUpdate{
+				Timezone: toPointer("Mars/Olympus_Mons"),
+			},
+			requiredMocks: func() {},
+			expected:      Expected{http.StatusBadRequest},
+		},
 		{
 			description: "fails when try to updating a non-existing user",
 			headers: map[string]string{
@@ -121,6 +137,28 @@ func TestUpdateUserData(t *testing.T) {
 			},
 			expected: Expected{http.StatusOK},
 		},
+		{
+			description: "success when clearing the timezone",
+			body: requests.UserDataUpdate{
+				Timezone: toPointer(""),
+			},
+			headers: map[string]string{
+				"X-ID":   "000000000000000000000000",
+				"X-Role": "owner",
+			},
+			requiredMocks: func() {
+				svcMock.
+					On(
+						"UpdateDataUser",
+						gomock.Anything,
+						"000000000000000000000000",
+						&requests.UserDataUpdate{Timezone: toPointer("")},
+					).
+					Return(nil, nil).
+					Once()
+			},
+			expected: Expected{http.StatusOK},
+		},
 	}
 
 	for _, tc := range cases {
@@ -289,3 +327,214 @@ func TestUpdateUserPassword(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestDeleteUser(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		url            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description: "fails when deleting the account of another user",
+			url:         "/api/users/65fde3a72c4c7507c7f53c44",
+			requiredMocks: func() {
+				mock.
+					On("DeleteUser", gomock.Anything, "65fde3a72c4c7507c7f53c44", "65fde3a72c4c7507c7f53c43", false).
+					Return(svc.NewErrUserDeleteForbidden(nil)).
+					Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when the user owns namespaces",
+			url:         "/api/users/65fde3a72c4c7507c7f53c43?gdpr=true",
+			requiredMocks: func() {
+				mock.
+					On("DeleteUser", gomock.Anything, "65fde3a72c4c7507c7f53c43", "65fde3a72c4c7507c7f53c43", true).
+					Return(svc.NewErrUserOwnsNamespaces([]string{"00000000-0000-4000-0000-000000000000"}, nil)).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description: "succeeds soft-deleting the user",
+			url:         "/api/users/65fde3a72c4c7507c7f53c43",
+			requiredMocks: func() {
+				mock.
+					On("DeleteUser", gomock.Anything, "65fde3a72c4c7507c7f53c43", "65fde3a72c4c7507c7f53c43", false).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			description: "succeeds erasing the user's data",
+			url:         "/api/users/65fde3a72c4c7507c7f53c43?gdpr=true",
+			requiredMocks: func() {
+				mock.
+					On("DeleteUser", gomock.Anything, "65fde3a72c4c7507c7f53c43", "65fde3a72c4c7507c7f53c43", true).
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodDelete, tc.url, nil)
+			req.Header.Set("X-ID", "65fde3a72c4c7507c7f53c43")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestCompleteUserOnboarding(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		url            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when completing the onboarding of another user",
+			url:            "/api/users/65fde3a72c4c7507c7f53c44/onboarding",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			description: "fails when the user is not found",
+			url:         "/api/users/65fde3a72c4c7507c7f53c43/onboarding",
+			requiredMocks: func() {
+				mock.
+					On("CompleteOnboarding", gomock.Anything, "65fde3a72c4c7507c7f53c43").
+					Return(svc.NewErrUserNotFound("65fde3a72c4c7507c7f53c43", nil)).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds",
+			url:         "/api/users/65fde3a72c4c7507c7f53c43/onboarding",
+			requiredMocks: func() {
+				mock.
+					On("CompleteOnboarding", gomock.Anything, "65fde3a72c4c7507c7f53c43").
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPatch, tc.url, nil)
+			req.Header.Set("X-ID", "65fde3a72c4c7507c7f53c43")
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAssignUserPlan(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		description    string
+		url            string
+		req            string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			description:    "fails when the plan is missing",
+			url:            "/internal/users/65fde3a72c4c7507c7f53c43/plan",
+			req:            `{}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "fails when requested through the public API",
+			url:            "/api/users/65fde3a72c4c7507c7f53c43/plan",
+			req:            `{"plan": "starter"}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "fails when the plan does not exist",
+			url:         "/internal/users/65fde3a72c4c7507c7f53c43/plan",
+			req:         `{"plan": "nonexistent"}`,
+			requiredMocks: func() {
+				mock.
+					On("AssignPlan", gomock.Anything, "65fde3a72c4c7507c7f53c43", "nonexistent").
+					Return(svc.NewErrPlanNotFound("nonexistent", nil)).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "fails when the user is not found",
+			url:         "/internal/users/65fde3a72c4c7507c7f53c43/plan",
+			req:         `{"plan": "starter"}`,
+			requiredMocks: func() {
+				mock.
+					On("AssignPlan", gomock.Anything, "65fde3a72c4c7507c7f53c43", "starter").
+					Return(svc.NewErrUserNotFound("65fde3a72c4c7507c7f53c43", nil)).
+					Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description: "succeeds",
+			url:         "/internal/users/65fde3a72c4c7507c7f53c43/plan",
+			req:         `{"plan": "starter"}`,
+			requiredMocks: func() {
+				mock.
+					On("AssignPlan", gomock.Anything, "65fde3a72c4c7507c7f53c43", "starter").
+					Return(nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPut, tc.url, strings.NewReader(tc.req))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/pkg/version"
+)
+
+const (
+	// VersionURL reports the build information of the API, allowing to detect version skew between the services.
+	VersionURL = "/version"
+)
+
+func (h *Handler) GetVersion(c gateway.Context) error {
+	return c.JSON(http.StatusOK, version.Get())
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVersion(t *testing.T) {
+	mock := new(mocks.Service)
+
+	version.Version, version.GitCommit, version.BuildDate = "v0.16.0", "385e64d", "2024-06-01T12:00:00Z"
+	t.Cleanup(func() {
+		version.Version, version.GitCommit, version.BuildDate = "", "", ""
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/version", nil)
+	rec := httptest.NewRecorder()
+
+	e := NewRouter(mock)
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `{"version":"v0.16.0","git_commit":"385e64d","build_date":"2024-06-01T12:00:00Z"}`, rec.Body.String())
+
+	mock.AssertExpectations(t)
+}
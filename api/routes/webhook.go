@@ -0,0 +1,155 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+const (
+	GetNamespaceWebhookURL    = "/namespaces/:tenant/webhook"
+	SetNamespaceWebhookURL    = "/namespaces/:tenant/webhook"
+	DeleteNamespaceWebhookURL = "/namespaces/:tenant/webhook"
+	// ListWebhookDeadLettersURL lists the events that couldn't be delivered to the webhook of a namespace.
+	ListWebhookDeadLettersURL = "/namespaces/:tenant/webhook/dead-letters"
+)
+
+func (h *Handler) GetNamespaceWebhook(c gateway.Context) error {
+	var req requests.TenantParam
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var webhook *models.NamespaceWebhook
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.Update, func() error {
+		var err error
+		webhook, err = h.service.GetNamespaceWebhook(c.Ctx(), ns.TenantID)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// SetNamespaceWebhook sets the webhook the events of a namespace are posted to, responding with the secret they are
+// signed with, shown only here.
+func (h *Handler) SetNamespaceWebhook(c gateway.Context) error {
+	var req requests.NamespaceWebhookSet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var webhook *responses.NamespaceWebhook
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.Update, func() error {
+		var err error
+		webhook, err = h.service.SetNamespaceWebhook(c.Ctx(), &req)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+func (h *Handler) DeleteNamespaceWebhook(c gateway.Context) error {
+	var req requests.TenantParam
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.Update, func() error {
+		return h.service.DeleteNamespaceWebhook(c.Ctx(), ns.TenantID)
+	}); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) ListWebhookDeadLetters(c gateway.Context) error {
+	var req requests.NamespaceWebhookDeadLettersList
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	req.Paginator.Normalize()
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var letters []models.WebhookDeadLetter
+	var count int
+	if err := guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.Update, func() error {
+		var err error
+		letters, count, err = h.service.ListWebhookDeadLetters(c.Ctx(), ns.TenantID, req.Paginator)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(count))
+
+	return c.JSON(http.StatusOK, letters)
+}
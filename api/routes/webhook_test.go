@@ -0,0 +1,165 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestSetNamespaceWebhook(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "administrator", Role: guard.RoleAdministrator},
+			{ID: "456", Username: "operator", Role: guard.RoleOperator},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		uid            string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when the URL is invalid",
+			uid:            "123",
+			body:           `{"url": "not a url", "events": ["device.accepted"]}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when no event is subscribed",
+			uid:            "123",
+			body:           `{"url": "https://example.com/hook", "events": []}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title: "fails when the user is not an administrator of the namespace",
+			uid:   "456",
+			body:  `{"url": "https://example.com/hook", "events": ["device.accepted"]}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title: "fails when an event is unknown",
+			uid:   "123",
+			body:  `{"url": "https://example.com/hook", "events": ["device.unknown"]}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("SetNamespaceWebhook", gomock.Anything, gomock.AnythingOfType("*requests.NamespaceWebhookSet")).
+					Return(nil, svc.NewErrWebhookInvalid(nil, nil)).
+					Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title: "succeeds",
+			uid:   "123",
+			body:  `{"url": "https://example.com/hook", "events": ["device.accepted"]}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("SetNamespaceWebhook", gomock.Anything, &requests.NamespaceWebhookSet{
+					TenantParam: requests.TenantParam{Tenant: "00000000-0000-4000-0000-000000000000"},
+					URL:         "https://example.com/hook",
+					Events:      []models.WebhookEventType{models.WebhookEventDeviceAccepted},
+				}).
+					Return(&responses.NamespaceWebhook{URL: "https://example.com/hook", Secret: "secret"}, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/namespaces/00000000-0000-4000-0000-000000000000/webhook", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", guard.RoleAdministrator)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListWebhookDeadLetters(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "123", Username: "administrator", Role: guard.RoleAdministrator},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		requiredMocks  func()
+		expectedStatus int
+		expectedCount  string
+	}{
+		{
+			title: "fails when the namespace is not found",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(nil, svc.ErrNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title: "succeeds",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "00000000-0000-4000-0000-000000000000").Return(namespace, nil).Once()
+				mock.On("ListWebhookDeadLetters", gomock.Anything, "00000000-0000-4000-0000-000000000000", query.Paginator{Page: 1, PerPage: 10}).
+					Return([]models.WebhookDeadLetter{{TenantID: "00000000-0000-4000-0000-000000000000"}}, 1, nil).
+					Once()
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  "1",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/namespaces/00000000-0000-4000-0000-000000000000/webhook/dead-letters?page=1&per_page=10", nil)
+			req.Header.Set("X-Role", guard.RoleAdministrator)
+			req.Header.Set("X-ID", "123")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+			assert.Equal(t, tc.expectedCount, rec.Result().Header.Get("X-Total-Count"))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -5,13 +5,20 @@ import (
 	"errors"
 	"os"
 	"os/signal"
+	"runtime"
 	"syscall"
+	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/shellhub-io/shellhub/api/pkg/echo/handlers"
+	apimiddleware "github.com/shellhub-io/shellhub/api/pkg/echo/middleware"
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/mailer"
+	"github.com/shellhub-io/shellhub/api/pkg/recordcipher"
+	"github.com/shellhub-io/shellhub/api/pkg/recordstorage"
+	"github.com/shellhub-io/shellhub/api/pkg/webhook"
 	"github.com/shellhub-io/shellhub/api/routes"
 	"github.com/shellhub-io/shellhub/api/services"
 	"github.com/shellhub-io/shellhub/api/store"
@@ -22,6 +29,8 @@ import (
 	storecache "github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/geoip"
 	"github.com/shellhub-io/shellhub/pkg/middleware"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/version"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -57,21 +66,29 @@ var serverCmd = &cobra.Command{
 				Fatal("unable to connect to MongoDB")
 		}
 
-		store, err := mongo.NewStore(ctx, db, cache, options.RunMigatrions)
+		storeCfg := mongo.Config{}
+		if cfg.MongoShardingEnabled {
+			storeCfg.ShardingHints = mongo.ShardingHints{
+				"sessions":          cfg.MongoSessionsShardKey,
+				"recorded_sessions": cfg.MongoRecordedSessionsShardKey,
+			}
+		}
+
+		store, err := mongo.NewStoreWithConfig(ctx, db, cache, storeCfg, options.RunMigatrions)
 		if err != nil {
 			log.
 				WithError(err).
 				Fatal("failed to create the store")
 		}
 
-		log.Info("Connected to MongoDB")
-
-		worker, err := workers.New(store)
-		if err != nil {
-			log.WithError(err).Warn("Failed to create workers.")
+		// NOTICE: the shard keys are validated after the migrations, which create the indexes of the collections.
+		if err := mongo.ValidateShardingConfig(ctx, db, storeCfg.ShardingHints); err != nil {
+			log.
+				WithError(err).
+				Fatal("invalid sharding configuration")
 		}
 
-		worker.Start(ctx)
+		log.Info("Connected to MongoDB")
 
 		go func() {
 			sig := <-sigs
@@ -92,6 +109,14 @@ var serverCmd = &cobra.Command{
 type config struct {
 	// MongoDB connection string (URI format)
 	MongoURI string `env:"MONGO_URI,default=mongodb://mongo:27017/main"`
+	// MongoShardingEnabled hints the shard keys of the large collections to their queries, when MongoDB is sharded.
+	// The shard keys must be indexed, what is verified on start.
+	MongoShardingEnabled bool `env:"MONGO_SHARDING_ENABLED,default=false"`
+	// MongoSessionsShardKey is the comma-separated list of the fields of the shard key of the sessions.
+	MongoSessionsShardKey []string `env:"MONGO_SESSIONS_SHARD_KEY"`
+	// MongoRecordedSessionsShardKey is the comma-separated list of the fields of the shard key of the frames recorded
+	// on the sessions.
+	MongoRecordedSessionsShardKey []string `env:"MONGO_RECORDED_SESSIONS_SHARD_KEY"`
 	// Redis connection string (URI format)
 	RedisURI string `env:"REDIS_URI,default=redis://redis:6379"`
 	// RedisCachePoolSize is the pool size of connections available for Redis cache.
@@ -106,6 +131,59 @@ type config struct {
 	SessionRecordCleanupSchedule string `env:"SESSION_RECORD_CLEANUP_SCHEDULE,default=@daily"`
 	// Sentry DSN.
 	SentryDSN string `env:"SENTRY_DSN,default="`
+	// S3ExportEnabled enables exporting session recordings to the S3-compatible object storage configured below.
+	S3ExportEnabled bool `env:"S3_EXPORT_ENABLED,default=false"`
+	// S3Endpoint is the URL of the S3-compatible object storage. When empty, AWS S3 itself is used.
+	S3Endpoint string `env:"S3_ENDPOINT,default="`
+	// S3Bucket is the bucket where the session recordings are exported to.
+	S3Bucket string `env:"S3_BUCKET,default="`
+	// S3AccessKey is the access key used to authenticate on the object storage.
+	S3AccessKey string `env:"S3_ACCESS_KEY,default="`
+	// S3SecretKey is the secret key used to authenticate on the object storage.
+	S3SecretKey string `env:"S3_SECRET_KEY,default="`
+	// S3Region is the region of the bucket.
+	S3Region string `env:"S3_REGION,default=us-east-1"`
+	// RecordArchiveEnabled exports the session recordings to the S3-compatible object storage configured above before
+	// the cleanup worker deletes them, which only deletes them once every one was exported.
+	RecordArchiveEnabled bool `env:"RECORD_ARCHIVE_ENABLED,default=false"`
+	// RecordingBackend is where the session recordings are kept: "mongo", along with the rest of the data, or
+	// "filesystem", as files on RecordingDirectory.
+	RecordingBackend string `env:"RECORDING_BACKEND,default=mongo"`
+	// RecordingDirectory is the directory the session recordings are kept on when RecordingBackend is "filesystem".
+	RecordingDirectory string `env:"RECORDING_DIRECTORY,default=/var/lib/shellhub/recordings"`
+	// RecordEncryptionKeys are the keys the session recordings are encrypted at rest with, as a comma-separated list of
+	// id:key, where the key is a base64 encoded AES key. The first one encrypts the new frames and the others are kept
+	// to decrypt the frames stored before a rotation. When empty, the recordings aren't encrypted.
+	RecordEncryptionKeys string `env:"RECORD_ENCRYPTION_KEYS,default="`
+	// SessionPayloadLimit is the maximum size, in bytes, of the bodies sent by the SSH server to the internal session
+	// routes, refused with 413 when exceeded. Set it to 0 to disable. Default is 1 MiB.
+	SessionPayloadLimit int64 `env:"SESSION_PAYLOAD_LIMIT,default=1048576"`
+	// SMTPHost is the host of the SMTP server the emails, like the usage reports of the namespaces, are sent through.
+	// When empty, no email is sent.
+	SMTPHost string `env:"SMTP_HOST,default="`
+	// SMTPPort is the port of the SMTP server.
+	SMTPPort int `env:"SMTP_PORT,default=587"`
+	// SMTPUsername is the username used to authenticate on the SMTP server. When empty, no authentication is made.
+	SMTPUsername string `env:"SMTP_USERNAME,default="`
+	// SMTPPassword is the password used to authenticate on the SMTP server.
+	SMTPPassword string `env:"SMTP_PASSWORD,default="`
+	// SMTPFrom is the address the emails are sent from.
+	SMTPFrom string `env:"SMTP_FROM,default="`
+	// WebhookAttempts is the maximum number of times the delivery of an event to the webhook of a namespace is
+	// attempted, backing off between them, before it's dead-lettered.
+	WebhookAttempts int `env:"WEBHOOK_ATTEMPTS,default=5"`
+	// WebhookTimeout is the time limit, in seconds, of each attempt to deliver an event to a webhook.
+	WebhookTimeout int `env:"WEBHOOK_TIMEOUT,default=10"`
+	// WebhookQueueSize is the maximum number of events waiting to be delivered to the webhooks. The events emitted
+	// when it's full are dead-lettered right away.
+	WebhookQueueSize int `env:"WEBHOOK_QUEUE_SIZE,default=1000"`
+	// WebhookAllowPrivateNetworks allows the webhooks to post to loopback, private and link-local addresses, like on
+	// instances whose webhooks are on the same network. It's disabled by default so the namespaces can't reach the
+	// instance's internal services.
+	WebhookAllowPrivateNetworks bool `env:"WEBHOOK_ALLOW_PRIVATE_NETWORKS,default=false"`
+	// BillingStatusCacheTTL is how long whether a namespace has a subscription is cached to report its deletion to the
+	// billing.
+	BillingStatusCacheTTL time.Duration `env:"BILLING_STATUS_CACHE_TTL,default=5m"`
 }
 
 func init() {
@@ -144,6 +222,11 @@ func startSentry(dsn string) (*sentry.Client, error) {
 }
 
 func startServer(ctx context.Context, cfg *config, store store.Store, cache storecache.Cache) error {
+	// NOTICE: when the version isn't injected at build time, the one of the ShellHub instance is reported.
+	if version.Version == "" {
+		version.Version = os.Getenv("SHELLHUB_VERSION")
+	}
+
 	log.Info("Starting Sentry client")
 
 	reporter, err := startSentry(cfg.SentryDSN)
@@ -169,11 +252,133 @@ func startServer(ctx context.Context, cfg *config, store store.Store, cache stor
 		locator = geoip.NewNullGeoLite()
 	}
 
-	service := services.NewService(store, nil, nil, cache, requestClient, locator)
+	var serviceOpts []services.Option
+	var workerOpts []workers.Option
+	switch cfg.RecordingBackend {
+	case recordstorage.BackendMongo:
+		// NOTICE: the service keeps the recordings on the store by default.
+	case recordstorage.BackendFileSystem:
+		storage, err := recordstorage.NewFileSystemRecordingStorage(cfg.RecordingDirectory)
+		if err != nil {
+			log.WithError(err).WithField("directory", cfg.RecordingDirectory).Fatal("Failed to create the recording storage")
+		}
+
+		log.WithField("directory", cfg.RecordingDirectory).Info("Session recordings are kept on the file system")
+
+		serviceOpts = append(serviceOpts, services.WithRecordingStorage(storage))
+		workerOpts = append(workerOpts, workers.WithRecordingStorage(storage))
+	default:
+		log.WithError(recordstorage.ErrUnknownBackend).WithField("backend", cfg.RecordingBackend).Fatal("Failed to create the recording storage")
+	}
+
+	if cfg.RecordEncryptionKeys != "" {
+		keyring, err := recordcipher.Parse(cfg.RecordEncryptionKeys)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to parse the record encryption keys")
+		}
 
-	e := routes.NewRouter(service)
-	e.Use(middleware.Log)
+		log.WithField("key_id", keyring.Current()).Info("Session record encryption is enabled")
+
+		serviceOpts = append(serviceOpts, services.WithRecordEncryption(keyring))
+	}
+
+	// NOTICE: the events waiting to be delivered are kept in memory, so they're lost when the API is stopped.
+	dispatcher := webhook.NewDispatcher(webhook.Config{
+		Attempts:             cfg.WebhookAttempts,
+		Delay:                time.Second,
+		MaxDelay:             time.Minute,
+		Timeout:              time.Duration(cfg.WebhookTimeout) * time.Second,
+		QueueSize:            cfg.WebhookQueueSize,
+		Workers:              runtime.NumCPU(),
+		AllowPrivateNetworks: cfg.WebhookAllowPrivateNetworks,
+	}, store.WebhookDeadLetterCreate)
+	dispatcher.Start(ctx)
+
+	serviceOpts = append(serviceOpts, services.WithWebhookDispatcher(dispatcher))
+	serviceOpts = append(serviceOpts, services.WithBillingStatusCacheTTL(cfg.BillingStatusCacheTTL))
+
+	service := services.NewService(store, nil, nil, cache, requestClient, locator, serviceOpts...)
+
+	s3cfg := &models.S3Config{
+		Endpoint:  cfg.S3Endpoint,
+		Bucket:    cfg.S3Bucket,
+		AccessKey: cfg.S3AccessKey,
+		SecretKey: cfg.S3SecretKey,
+		Region:    cfg.S3Region,
+	}
+
+	if cfg.RecordArchiveEnabled {
+		if s3cfg.Bucket == "" {
+			log.Fatal("Failed to enable the session recording archival: the S3 bucket is not set")
+		}
+
+		log.WithFields(log.Fields{
+			"endpoint": cfg.S3Endpoint,
+			"bucket":   cfg.S3Bucket,
+		}).Info("Session recording archival to S3 is enabled")
+
+		workerOpts = append(workerOpts, workers.WithRecordArchiver(func(ctx context.Context, uid models.UID) error {
+			_, err := service.SessionExportS3(ctx, string(uid), s3cfg)
+
+			return err
+		}))
+	}
+
+	// NOTICE: the expired devices are rejected through the service, which records the expirations on their timelines.
+	workerOpts = append(workerOpts, workers.WithDeviceExpirer(service.ExpireDevice))
+
+	// NOTICE: the expired namespaces are deleted through the service, which deletes their recordings kept out of the
+	// store too.
+	workerOpts = append(workerOpts, workers.WithNamespaceExpirer(service.ExpireNamespace))
+
+	// NOTICE: the same mailer sends the emails of the API and of the workers.
+	var m mailer.Mailer
+	if smtp := (&mailer.Config{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	}); smtp.Configured() {
+		log.WithFields(log.Fields{
+			"host": cfg.SMTPHost,
+			"port": cfg.SMTPPort,
+		}).Info("Sending emails is enabled")
+
+		m = mailer.NewSMTP(smtp)
+		workerOpts = append(workerOpts, workers.WithMailer(m))
+	}
+
+	// NOTICE: the workers are created after the service, which archives the recordings for them.
+	worker, err := workers.New(store, workerOpts...)
+	if err != nil {
+		log.WithError(err).Warn("Failed to create workers.")
+	}
+
+	worker.Start(ctx)
+
+	opts := []routes.Option{routes.WithSessionPayloadLimit(cfg.SessionPayloadLimit)}
+	if cfg.S3ExportEnabled {
+		log.WithFields(log.Fields{
+			"endpoint": cfg.S3Endpoint,
+			"bucket":   cfg.S3Bucket,
+		}).Info("Session export to S3 is enabled")
+
+		opts = append(opts, routes.WithSessionExport(s3cfg))
+	}
+
+	if m != nil {
+		opts = append(opts, routes.WithMailer(m))
+	}
+
+	e := routes.NewRouter(service, opts...)
+	// NOTICE: the request ID must be set before the logger is, as the request-scoped logger carries it.
 	e.Use(echoMiddleware.RequestID())
+	e.Use(middleware.Log)
+	if reporter != nil {
+		e.Use(apimiddleware.Trace(reporter))
+	}
+	e.Use(apimiddleware.RateLimit(service))
 	e.HTTPErrorHandler = handlers.NewErrors(reporter)
 
 	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
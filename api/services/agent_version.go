@@ -0,0 +1,40 @@
+package services
+
+import (
+	"github.com/Masterminds/semver"
+	"github.com/shellhub-io/shellhub/pkg/envs"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+// agentStatus compares the version of a device's agent to the server's version, SHELLHUB_VERSION, and to the minimum
+// agent version it supports, SHELLHUB_AGENT_MIN_VERSION.
+func agentStatus(version string) models.DeviceAgentStatus {
+	return compareAgentVersion(version, envs.DefaultBackend.Get("SHELLHUB_VERSION"), envs.DefaultBackend.Get("SHELLHUB_AGENT_MIN_VERSION"))
+}
+
+// compareAgentVersion reports an agent older than minimum as unsupported and one older than latest as having an update
+// available. An empty minimum supports every version. When version or latest aren't semantic versions, like the ones of
+// development builds, the status is empty, as they can't be compared.
+func compareAgentVersion(version, latest, minimum string) models.DeviceAgentStatus {
+	current, err := semver.NewVersion(version)
+	if err != nil {
+		return ""
+	}
+
+	if minimum != "" {
+		if min, err := semver.NewVersion(minimum); err == nil && current.LessThan(min) {
+			return models.DeviceAgentStatusUnsupported
+		}
+	}
+
+	server, err := semver.NewVersion(latest)
+	if err != nil {
+		return ""
+	}
+
+	if current.LessThan(server) {
+		return models.DeviceAgentStatusUpdateAvailable
+	}
+
+	return models.DeviceAgentStatusUpToDate
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareAgentVersion(t *testing.T) {
+	cases := []struct {
+		description string
+		version     string
+		latest      string
+		minimum     string
+		expected    models.DeviceAgentStatus
+	}{
+		{
+			description: "reports no status when the agent version is not a semantic version",
+			version:     "latest",
+			latest:      "v0.15.2",
+			minimum:     "v0.10.0",
+			expected:    "",
+		},
+		{
+			description: "reports no status when the server version is not a semantic version",
+			version:     "v0.15.2",
+			latest:      "latest",
+			minimum:     "",
+			expected:    "",
+		},
+		{
+			description: "reports unsupported when the agent is older than the minimum",
+			version:     "v0.9.3",
+			latest:      "v0.15.2",
+			minimum:     "v0.10.0",
+			expected:    models.DeviceAgentStatusUnsupported,
+		},
+		{
+			description: "reports unsupported even when the server version is not a semantic version",
+			version:     "v0.9.3",
+			latest:      "latest",
+			minimum:     "v0.10.0",
+			expected:    models.DeviceAgentStatusUnsupported,
+		},
+		{
+			description: "reports update available when the agent is older than the server",
+			version:     "v0.14.0",
+			latest:      "v0.15.2",
+			minimum:     "v0.10.0",
+			expected:    models.DeviceAgentStatusUpdateAvailable,
+		},
+		{
+			description: "reports update available when there is no minimum",
+			version:     "0.1.0",
+			latest:      "v0.15.2",
+			minimum:     "",
+			expected:    models.DeviceAgentStatusUpdateAvailable,
+		},
+		{
+			description: "reports update available when the agent is a pre-release of the server version",
+			version:     "v0.15.2-rc.1",
+			latest:      "v0.15.2",
+			minimum:     "",
+			expected:    models.DeviceAgentStatusUpdateAvailable,
+		},
+		{
+			description: "reports up to date when the agent has the server version",
+			version:     "v0.15.2",
+			latest:      "v0.15.2",
+			minimum:     "v0.10.0",
+			expected:    models.DeviceAgentStatusUpToDate,
+		},
+		{
+			description: "reports up to date when the agent is newer than the server",
+			version:     "v0.16.0",
+			latest:      "v0.15.2",
+			minimum:     "",
+			expected:    models.DeviceAgentStatusUpToDate,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, compareAgentVersion(tc.version, tc.latest, tc.minimum))
+		})
+	}
+}
@@ -79,6 +79,7 @@ func (s *service) CreateAPIKey(ctx context.Context, req *requests.CreateAPIKey)
 		Role:      req.Role,
 		ExpiresIn: expiresIn,
 		CreatedBy: req.UserID,
+		Scopes:    req.Scopes,
 	}
 
 	if _, err := s.store.APIKeyCreate(ctx, data); err != nil {
@@ -18,6 +18,7 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/api/jwttoken"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/logger"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/pkg/uuid"
 	log "github.com/sirupsen/logrus"
@@ -42,9 +43,13 @@ type AuthService interface {
 	// The key is cached for 2 minutes after use, so requests made within this period will treat the key as valid.
 	AuthAPIKey(ctx context.Context, key string) (apiKey *models.APIKey, err error)
 
-	AuthGetToken(ctx context.Context, id string) (*models.UserAuthResponse, error)
+	// AuthGetToken issues a token of the user with the specified ID to its first namespace, creating a user session,
+	// from sourceIP and userAgent, that the token is bound to, so it can be listed and revoked like a login.
+	AuthGetToken(ctx context.Context, id, sourceIP, userAgent string) (*models.UserAuthResponse, error)
 	AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth) (*models.PublicKeyAuthResponse, error)
-	AuthSwapToken(ctx context.Context, ID, tenant string) (*models.UserAuthResponse, error)
+	// AuthSwapToken issues a token of the user with the specified ID to the namespace with the specified tenant. The
+	// token is bound to the same user session of the token being swapped, if any.
+	AuthSwapToken(ctx context.Context, ID, tenant, session string) (*models.UserAuthResponse, error)
 	AuthUserInfo(ctx context.Context, username, tenant, token string) (*models.UserAuthResponse, error)
 	PublicKey() *rsa.PublicKey
 }
@@ -63,6 +68,12 @@ func (s *service) AuthDevice(ctx context.Context, req requests.DeviceAuth, remot
 		TenantID:  req.TenantID,
 	}
 
+	// NOTICE: the trusted host key is verified before the cache is read, so a key trusted after the device authenticated
+	// is enforced on its next authentication.
+	if err := s.verifyDeviceHostKey(ctx, req.TenantID, strings.ToLower(req.Hostname), identity, req.PublicKey); err != nil {
+		return nil, err
+	}
+
 	uid := sha256.Sum256(structhash.Dump(auth, 1))
 
 	key := hex.EncodeToString(uid[:])
@@ -125,12 +136,32 @@ func (s *service) AuthDevice(ctx context.Context, req requests.DeviceAuth, remot
 		},
 	}
 
+	// NOTICE: the agent's version is only reported when it authenticates, what it does again on every reconnection, so
+	// its status follows the server's upgrades as the agents reconnect to it.
+	if info != nil {
+		device.AgentStatus = agentStatus(info.Version)
+		if device.AgentStatus == models.DeviceAgentStatusUnsupported {
+			logger.FromContext(ctx).
+				WithFields(log.Fields{"uid": key, "tenant_id": req.TenantID, "version": info.Version}).
+				Warn("device authenticated with an unsupported agent version")
+		}
+	}
+
 	// The order here is critical as we don't want to register devices if the tenant id is invalid
 	namespace, err := s.store.NamespaceGet(ctx, device.TenantID, false)
 	if err != nil {
 		return nil, NewErrNamespaceNotFound(device.TenantID, err)
 	}
 
+	// NOTICE: once the namespace has an enrollment key, only the new devices must present it, so the devices already
+	// enrolled keep working when it's rotated.
+	if namespace.EnrollmentKey != nil {
+		if enrolled, _ := s.store.DeviceGetByUID(ctx, models.UID(device.UID), device.TenantID); enrolled == nil &&
+			!namespace.EnrollmentKey.Accepts(hashEnrollmentKey(req.EnrollmentKey), clock.Now()) {
+			return nil, NewErrDeviceEnrollmentKeyInvalid(nil)
+		}
+	}
+
 	hostname := strings.ToLower(req.Hostname)
 
 	if err := s.store.DeviceCreate(ctx, device, hostname); err != nil {
@@ -159,6 +190,27 @@ func (s *service) AuthDevice(ctx context.Context, req requests.DeviceAuth, remot
 	}, nil
 }
 
+// verifyDeviceHostKey checks the key presented by a device against the trusted host key of the device it claims to be.
+// A device registering with a new key takes the place of the device with the same identity, or of the accepted one with
+// the same hostname when it has no identity, so a device with a trusted host key must present it.
+func (s *service) verifyDeviceHostKey(ctx context.Context, tenantID, hostname string, identity *models.DeviceIdentity, publicKey string) error {
+	var known *models.Device
+	if identity != nil {
+		known, _ = s.store.DeviceGetByMac(ctx, identity.MAC, tenantID, models.DeviceStatusEmpty)
+	} else if hostname != "" {
+		known, _ = s.store.DeviceGetByName(ctx, hostname, tenantID, models.DeviceStatusAccepted)
+	}
+
+	if known == nil {
+		return nil
+	}
+
+	if err := known.VerifyHostKeyPEM(publicKey); err != nil {
+		return NewErrDeviceHostKeyMismatch(err)
+	}
+
+	return nil
+}
 func (s *service) AuthUser(ctx context.Context, req *requests.UserAuth, sourceIP string) (*models.UserAuthResponse, int64, string, error) {
 	var err error
 	var user *models.User
@@ -169,7 +221,8 @@ func (s *service) AuthUser(ctx context.Context, req *requests.UserAuth, sourceIP
 		user, err = s.store.UserGetByUsername(ctx, strings.ToLower(string(req.Identifier)))
 	}
 
-	if err != nil {
+	// NOTICE: a deleted user is reported as not found, so the deletion of an account isn't disclosed.
+	if err != nil || user.DeletedAt != nil {
 		return nil, 0, "", NewErrAuthUnathorized(nil)
 	}
 
@@ -179,7 +232,7 @@ func (s *service) AuthUser(ctx context.Context, req *requests.UserAuth, sourceIP
 
 	// Checks whether the user is currently blocked from new login attempts
 	if lockout, attempt, _ := s.cache.HasAccountLockout(ctx, sourceIP, user.ID); lockout > 0 {
-		log.
+		logger.FromContext(ctx).
 			WithFields(log.Fields{
 				"lockout":   lockout,
 				"attempt":   attempt,
@@ -194,7 +247,7 @@ func (s *service) AuthUser(ctx context.Context, req *requests.UserAuth, sourceIP
 	if !user.Password.Compare(req.Password) {
 		lockout, _, err := s.cache.StoreLoginAttempt(ctx, sourceIP, user.ID)
 		if err != nil {
-			log.WithError(err).
+			logger.FromContext(ctx).WithError(err).
 				WithField("source_ip", sourceIP).
 				WithField("user_id", user.ID).
 				Warn("unable to store login attempt")
@@ -205,7 +258,7 @@ func (s *service) AuthUser(ctx context.Context, req *requests.UserAuth, sourceIP
 
 	// Reset the attempt and timeout values when succeeds
 	if err := s.cache.ResetLoginAttempts(ctx, sourceIP, user.ID); err != nil {
-		log.WithError(err).
+		logger.FromContext(ctx).WithError(err).
 			WithField("source_ip", sourceIP).
 			WithField("user_id", user.ID).
 			Warn("unable to reset authentication attempts")
@@ -215,7 +268,7 @@ func (s *service) AuthUser(ctx context.Context, req *requests.UserAuth, sourceIP
 	if user.MFA.Enabled {
 		mfaToken := uuid.Generate()
 		if err := s.cache.Set(ctx, "mfa-token={"+mfaToken+"}", user.ID, 30*time.Minute); err != nil {
-			log.WithError(err).
+			logger.FromContext(ctx).WithError(err).
 				WithField("source_ip", sourceIP).
 				WithField("user_id", user.ID).
 				Warn("unable to store mfa-token")
@@ -241,11 +294,6 @@ func (s *service) AuthUser(ctx context.Context, req *requests.UserAuth, sourceIP
 		claims.Role = info.Role
 	}
 
-	jwtToken, err := jwttoken.Encode(claims.WithDefaults(), s.privKey)
-	if err != nil {
-		return nil, 0, "", NewErrTokenSigned(err)
-	}
-
 	// Updates last_login and the hash algorithm to bcrypt if still using SHA256
 	changes := &models.UserChanges{LastLogin: clock.Now()}
 	if !strings.HasPrefix(user.Password.Hash, "$") {
@@ -258,22 +306,39 @@ func (s *service) AuthUser(ctx context.Context, req *requests.UserAuth, sourceIP
 		return nil, 0, "", NewErrUserUpdate(user, err)
 	}
 
+	// NOTICE: a login without a session is still valid, although it isn't listed among the user's sessions and cannot
+	// be revoked on its own.
+	if session, err := s.createUserSession(ctx, user, sourceIP, req.UserAgent); err != nil {
+		logger.FromContext(ctx).WithError(err).
+			WithFields(log.Fields{"id": user.ID}).
+			Warn("unable to create the user session")
+	} else {
+		claims.Session = session.ID
+	}
+
+	jwtToken, err := jwttoken.Encode(claims.WithDefaults(), s.privKey)
+	if err != nil {
+		return nil, 0, "", NewErrTokenSigned(err)
+	}
+
 	if err := s.AuthCacheToken(ctx, claims.Tenant, user.ID, jwtToken); err != nil {
-		log.WithError(err).
+		logger.FromContext(ctx).WithError(err).
 			WithFields(log.Fields{"id": user.ID}).
 			Warn("unable to cache the authentication token")
 	}
 
 	res := &models.UserAuthResponse{
-		ID:            user.ID,
-		User:          user.Username,
-		Name:          user.Name,
-		Email:         user.Email,
-		RecoveryEmail: user.RecoveryEmail,
-		MFA:           user.MFA.Enabled,
-		Tenant:        claims.Tenant,
-		Role:          claims.Role,
-		Token:         jwtToken,
+		ID:                  user.ID,
+		User:                user.Username,
+		Name:                user.Name,
+		Email:               user.Email,
+		RecoveryEmail:       user.RecoveryEmail,
+		MFA:                 user.MFA.Enabled,
+		Timezone:            user.Timezone,
+		OnboardingCompleted: user.OnboardingCompleted,
+		Tenant:              claims.Tenant,
+		Role:                claims.Role,
+		Token:               jwtToken,
 	}
 
 	return res, 0, "", nil
@@ -300,13 +365,13 @@ func (s *service) AuthAPIKey(ctx context.Context, key string) (*models.APIKey, e
 	}
 
 	if err := s.cache.Set(ctx, "api-key={"+key+"}", apiKey, 2*time.Minute); err != nil {
-		log.WithError(err).Info("Unable to set the api-key in cache")
+		logger.FromContext(ctx).WithError(err).Info("Unable to set the api-key in cache")
 	}
 
 	return apiKey, nil
 }
 
-func (s *service) AuthGetToken(ctx context.Context, id string) (*models.UserAuthResponse, error) {
+func (s *service) AuthGetToken(ctx context.Context, id, sourceIP, userAgent string) (*models.UserAuthResponse, error) {
 	user, _, err := s.store.UserGetByID(ctx, id, false)
 	if err != nil {
 		return nil, NewErrUserNotFound(id, err)
@@ -335,11 +400,16 @@ func (s *service) AuthGetToken(ctx context.Context, id string) (*models.UserAuth
 		},
 	}
 
-	jwtToken, err := jwttoken.Encode(claims.WithDefaults(), s.privKey)
-	if err != nil {
-		return nil, NewErrTokenSigned(err)
+	// NOTICE: like on the login, a token without a session is still valid, although it cannot be revoked on its own.
+	if session, err := s.createUserSession(ctx, user, sourceIP, userAgent); err != nil {
+		logger.FromContext(ctx).WithError(err).
+			WithFields(log.Fields{"id": user.ID}).
+			Warn("unable to create the user session")
+	} else {
+		claims.Session = session.ID
 	}
 
+	jwtToken, err := jwttoken.Encode(claims.WithDefaults(), s.privKey)
 	if err != nil {
 		return nil, NewErrTokenSigned(err)
 	}
@@ -347,15 +417,17 @@ func (s *service) AuthGetToken(ctx context.Context, id string) (*models.UserAuth
 	s.AuthCacheToken(ctx, tenant, user.ID, jwtToken) // nolint: errcheck
 
 	return &models.UserAuthResponse{
-		ID:            user.ID,
-		User:          user.Username,
-		Name:          user.Name,
-		Email:         user.Email,
-		RecoveryEmail: user.RecoveryEmail,
-		MFA:           user.MFA.Enabled,
-		Tenant:        tenant,
-		Role:          role,
-		Token:         jwtToken,
+		ID:                  user.ID,
+		User:                user.Username,
+		Name:                user.Name,
+		Email:               user.Email,
+		RecoveryEmail:       user.RecoveryEmail,
+		MFA:                 user.MFA.Enabled,
+		Timezone:            user.Timezone,
+		OnboardingCompleted: user.OnboardingCompleted,
+		Tenant:              tenant,
+		Role:                role,
+		Token:               jwtToken,
 	}, nil
 }
 
@@ -386,7 +458,7 @@ func (s *service) AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth)
 	}, nil
 }
 
-func (s *service) AuthSwapToken(ctx context.Context, id, tenant string) (*models.UserAuthResponse, error) {
+func (s *service) AuthSwapToken(ctx context.Context, id, tenant, session string) (*models.UserAuthResponse, error) {
 	namespace, err := s.store.NamespaceGet(ctx, tenant, false)
 	if err != nil {
 		return nil, NewErrNamespaceNotFound(tenant, err)
@@ -397,6 +469,11 @@ func (s *service) AuthSwapToken(ctx context.Context, id, tenant string) (*models
 		return nil, NewErrUserNotFound(id, err)
 	}
 
+	// NOTICE: a deleted user is reported as not found, like on the login.
+	if user.DeletedAt != nil {
+		return nil, NewErrUserNotFound(id, nil)
+	}
+
 	for _, member := range namespace.Members {
 		if user.ID == member.ID {
 			claims := &models.UserAuthClaims{
@@ -406,6 +483,7 @@ func (s *service) AuthSwapToken(ctx context.Context, id, tenant string) (*models
 				Admin:    true,
 				Username: user.Username,
 				MFA:      user.MFA.Enabled,
+				Session:  session,
 				AuthClaims: models.AuthClaims{
 					Claims: "user",
 				},
@@ -423,15 +501,17 @@ func (s *service) AuthSwapToken(ctx context.Context, id, tenant string) (*models
 			s.AuthCacheToken(ctx, tenant, user.ID, jwtToken) // nolint: errcheck
 
 			return &models.UserAuthResponse{
-				ID:            user.ID,
-				User:          user.Username,
-				Name:          user.Name,
-				Email:         user.Email,
-				RecoveryEmail: user.RecoveryEmail,
-				MFA:           user.MFA.Enabled,
-				Tenant:        tenant,
-				Role:          member.Role,
-				Token:         jwtToken,
+				ID:                  user.ID,
+				User:                user.Username,
+				Name:                user.Name,
+				Email:               user.Email,
+				RecoveryEmail:       user.RecoveryEmail,
+				MFA:                 user.MFA.Enabled,
+				Timezone:            user.Timezone,
+				OnboardingCompleted: user.OnboardingCompleted,
+				Tenant:              tenant,
+				Role:                member.Role,
+				Token:               jwtToken,
 			}, nil
 		}
 	}
@@ -457,15 +537,17 @@ func (s *service) AuthUserInfo(ctx context.Context, username, tenant, token stri
 	token = strings.Replace(token, "Bearer ", "", 1)
 
 	return &models.UserAuthResponse{
-		ID:            user.ID,
-		User:          user.Username,
-		Name:          user.Name,
-		Email:         user.Email,
-		RecoveryEmail: user.RecoveryEmail,
-		MFA:           user.MFA.Enabled,
-		Tenant:        tenant,
-		Role:          role,
-		Token:         token,
+		ID:                  user.ID,
+		User:                user.Username,
+		Name:                user.Name,
+		Email:               user.Email,
+		RecoveryEmail:       user.RecoveryEmail,
+		MFA:                 user.MFA.Enabled,
+		Timezone:            user.Timezone,
+		OnboardingCompleted: user.OnboardingCompleted,
+		Tenant:              tenant,
+		Role:                role,
+		Token:               token,
 	}, nil
 }
 
@@ -5,12 +5,15 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
 	"encoding/hex"
+	"encoding/pem"
 	"net"
 	"testing"
 	"time"
 
 	"github.com/cnf/structhash"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mocks"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
@@ -85,6 +88,8 @@ func TestAuthDevice(t *testing.T) {
 		Return(device, nil).Once()
 	mock.On("NamespaceGet", ctx, namespace.TenantID, false).
 		Return(namespace, nil).Once()
+	mock.On("DeviceGetByMac", ctx, authReq.Identity.MAC, authReq.TenantID, models.DeviceStatusEmpty).
+		Return(nil, store.ErrNoDocuments).Once()
 
 	// Mock time.Now using monkey patch
 	patch, err := mpatch.PatchMethod(time.Now, func() time.Time { return now })
@@ -115,6 +120,253 @@ func TestAuthDevice(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
+func TestAuthDevice_trusted_host_key(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	encode := func(key *rsa.PrivateKey) string {
+		return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)}))
+	}
+
+	trustedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	trusted := encode(trustedKey)
+	other := encode(otherKey)
+
+	namespace := &models.Namespace{Name: "namespace", TenantID: "00000000-0000-4000-0000-000000000000"}
+
+	cases := []struct {
+		description string
+		publicKey   string
+		identity    *requests.DeviceIdentity
+		known       *models.Device
+		cached      bool
+		created     bool
+		expected    error
+	}{
+		{
+			description: "succeeds when the known device has no trusted host key",
+			publicKey:   other,
+			identity:    &requests.DeviceIdentity{MAC: "mac"},
+			known:       &models.Device{UID: "uid", PublicKey: trusted},
+			created:     true,
+			expected:    nil,
+		},
+		{
+			description: "succeeds when the device presents its trusted host key",
+			publicKey:   trusted,
+			identity:    &requests.DeviceIdentity{MAC: "mac"},
+			known:       &models.Device{UID: "uid", PublicKey: trusted, TrustedHostKey: trusted},
+			created:     true,
+			expected:    nil,
+		},
+		{
+			description: "fails when the device does not present its trusted host key",
+			publicKey:   other,
+			identity:    &requests.DeviceIdentity{MAC: "mac"},
+			known:       &models.Device{UID: "uid", PublicKey: trusted, TrustedHostKey: trusted},
+			created:     false,
+			expected:    NewErrDeviceHostKeyMismatch(models.ErrDeviceHostKeyMismatch),
+		},
+		{
+			description: "succeeds when the device without identity presents its trusted host key",
+			publicKey:   trusted,
+			identity:    nil,
+			known:       &models.Device{UID: "uid", PublicKey: trusted, TrustedHostKey: trusted},
+			created:     true,
+			expected:    nil,
+		},
+		{
+			description: "fails when the device without identity does not present its trusted host key",
+			publicKey:   other,
+			identity:    nil,
+			known:       &models.Device{UID: "uid", PublicKey: trusted, TrustedHostKey: trusted},
+			created:     false,
+			expected:    NewErrDeviceHostKeyMismatch(models.ErrDeviceHostKeyMismatch),
+		},
+		{
+			description: "fails when the cached device does not present its trusted host key",
+			publicKey:   other,
+			identity:    &requests.DeviceIdentity{MAC: "mac"},
+			known:       &models.Device{UID: "uid", PublicKey: trusted, TrustedHostKey: trusted},
+			cached:      true,
+			created:     false,
+			expected:    NewErrDeviceHostKeyMismatch(models.ErrDeviceHostKeyMismatch),
+		},
+	}
+
+	uuidMock := &uuidmock.Uuid{}
+	uuid.DefaultBackend = uuidMock
+
+	locator := &mocksGeoIp.Locator{}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.TODO()
+
+			req := requests.DeviceAuth{
+				TenantID:  namespace.TenantID,
+				Hostname:  "hostname",
+				Identity:  tc.identity,
+				PublicKey: tc.publicKey,
+			}
+
+			if tc.identity != nil {
+				storeMock.On("DeviceGetByMac", ctx, "mac", namespace.TenantID, models.DeviceStatusEmpty).
+					Return(tc.known, nil).Once()
+			} else {
+				storeMock.On("DeviceGetByName", ctx, "hostname", namespace.TenantID, models.DeviceStatusAccepted).
+					Return(tc.known, nil).Once()
+			}
+
+			// NOTICE: a cached authentication must never be reached when the trusted host key doesn't match.
+			cache := storecache.NewNullCache()
+			if tc.cached {
+				cacheMock := new(mockcache.Cache)
+				t.Cleanup(func() {
+					cacheMock.AssertNotCalled(t, "Get", testifymock.Anything, testifymock.Anything, testifymock.Anything)
+				})
+
+				cache = cacheMock
+			}
+
+			if tc.created {
+				clockMock.On("Now").Return(now).Twice()
+				uuidMock.On("Generate").Return("cdfd3cb0-c44e-4e54-b931-6d57713ad159").Once()
+				locator.On("GetPosition", net.ParseIP("127.0.0.1")).Return(geoip.Position{}, nil).Once()
+
+				storeMock.On("NamespaceGet", ctx, namespace.TenantID, false).
+					Return(namespace, nil).Once()
+				storeMock.On("DeviceCreate", ctx, testifymock.AnythingOfType("models.Device"), "hostname").
+					Return(nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, testifymock.AnythingOfType("models.UID"), namespace.TenantID).
+					Return(&models.Device{Name: "hostname"}, nil).Once()
+			}
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cache, clientMock, locator)
+
+			_, err := service.AuthDevice(ctx, req, "127.0.0.1")
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestAuthDevice_enrollment_key(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	backend := clock.DefaultBackend
+	t.Cleanup(func() {
+		clock.DefaultBackend = backend
+	})
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	expiresAt := now.Add(time.Hour)
+
+	namespace := &models.Namespace{
+		Name:     "namespace",
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		EnrollmentKey: &models.NamespaceEnrollmentKey{
+			Hash:              hashEnrollmentKey("key"),
+			PreviousHash:      hashEnrollmentKey("old"),
+			PreviousExpiresAt: &expiresAt,
+		},
+	}
+
+	cases := []struct {
+		description   string
+		enrollmentKey string
+		enrolled      bool
+		expected      error
+	}{
+		{
+			description:   "succeeds when a new device presents the key",
+			enrollmentKey: "key",
+			enrolled:      false,
+			expected:      nil,
+		},
+		{
+			description:   "succeeds when a new device presents the replaced key before it expires",
+			enrollmentKey: "old",
+			enrolled:      false,
+			expected:      nil,
+		},
+		{
+			description:   "succeeds when an enrolled device doesn't present the key",
+			enrollmentKey: "",
+			enrolled:      true,
+			expected:      nil,
+		},
+		{
+			description:   "fails when a new device doesn't present the key",
+			enrollmentKey: "",
+			enrolled:      false,
+			expected:      NewErrDeviceEnrollmentKeyInvalid(nil),
+		},
+		{
+			description:   "fails when a new device presents a wrong key",
+			enrollmentKey: "wrong",
+			enrolled:      false,
+			expected:      NewErrDeviceEnrollmentKeyInvalid(nil),
+		},
+	}
+
+	uuidMock := &uuidmock.Uuid{}
+	uuid.DefaultBackend = uuidMock
+
+	locator := &mocksGeoIp.Locator{}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.TODO()
+
+			req := requests.DeviceAuth{
+				TenantID:      namespace.TenantID,
+				Hostname:      "hostname",
+				PublicKey:     "key",
+				EnrollmentKey: tc.enrollmentKey,
+			}
+
+			uuidMock.On("Generate").Return("cdfd3cb0-c44e-4e54-b931-6d57713ad159").Once()
+			locator.On("GetPosition", net.ParseIP("127.0.0.1")).Return(geoip.Position{}, nil).Once()
+
+			storeMock.On("DeviceGetByName", ctx, "hostname", namespace.TenantID, models.DeviceStatusAccepted).
+				Return(nil, store.ErrNoDocuments).Once()
+			storeMock.On("NamespaceGet", ctx, namespace.TenantID, false).
+				Return(namespace, nil).Once()
+
+			if tc.enrolled {
+				storeMock.On("DeviceGetByUID", ctx, testifymock.AnythingOfType("models.UID"), namespace.TenantID).
+					Return(&models.Device{Name: "hostname"}, nil).Once()
+			} else {
+				storeMock.On("DeviceGetByUID", ctx, testifymock.AnythingOfType("models.UID"), namespace.TenantID).
+					Return(nil, store.ErrNoDocuments).Once()
+			}
+
+			if tc.expected == nil {
+				storeMock.On("DeviceCreate", ctx, testifymock.AnythingOfType("models.Device"), "hostname").
+					Return(nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, testifymock.AnythingOfType("models.UID"), namespace.TenantID).
+					Return(&models.Device{Name: "hostname"}, nil).Once()
+			}
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, locator)
+
+			_, err := service.AuthDevice(ctx, req, "127.0.0.1")
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
 func TestAuthUser(t *testing.T) {
 	mock := new(mocks.Store)
 	cacheMock := new(mockcache.Cache)
@@ -175,6 +427,28 @@ func TestAuthUser(t *testing.T) {
 				err:      NewErrAuthUnathorized(nil),
 			},
 		},
+		{
+			description: "fails when the user deleted its account",
+			sourceIP:    "127.0.0.1",
+			req: &requests.UserAuth{
+				Identifier: "john_doe",
+				Password:   "secret",
+			},
+			requiredMocks: func() {
+				deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+				mock.
+					On("UserGetByUsername", ctx, "john_doe").
+					Return(&models.User{ID: "65fdd16b5f62f93184ec8a39", Confirmed: true, DeletedAt: &deletedAt}, nil).
+					Once()
+			},
+			expected: Expected{
+				res:      nil,
+				lockout:  0,
+				mfaToken: "",
+				err:      NewErrAuthUnathorized(nil),
+			},
+		},
 		{
 			description: "fails when user is not confimed",
 			sourceIP:    "127.0.0.1",
@@ -479,6 +753,27 @@ func TestAuthUser(t *testing.T) {
 					On("UserUpdate", ctx, user.ID, &models.UserChanges{LastLogin: now}).
 					Return(nil).
 					Once()
+				uuidMock := &uuidmock.Uuid{}
+				uuid.DefaultBackend = uuidMock
+				uuidMock.
+					On("Generate").
+					Return("00000000-0000-4000-0000-000000000000")
+				session := &models.UserSession{
+					ID:           "00000000-0000-4000-0000-000000000000",
+					UserID:       "65fdd16b5f62f93184ec8a39",
+					IPAddress:    "127.0.0.1",
+					CreatedAt:    now,
+					LastActivity: now,
+					ExpiresAt:    now.Add(UserSessionTTL),
+				}
+				mock.
+					On("UserSessionCreate", ctx, session).
+					Return(nil).
+					Once()
+				cacheMock.
+					On("Set", ctx, "user-session={00000000-0000-4000-0000-000000000000}", session, UserSessionTTL).
+					Return(nil).
+					Once()
 				cacheMock.
 					On("Set", ctx, "token_65fdd16b5f62f93184ec8a39", testifymock.Anything, time.Hour*72).
 					Return(nil).
@@ -564,6 +859,27 @@ func TestAuthUser(t *testing.T) {
 					On("UserUpdate", ctx, user.ID, &models.UserChanges{LastLogin: now}).
 					Return(nil).
 					Once()
+				uuidMock := &uuidmock.Uuid{}
+				uuid.DefaultBackend = uuidMock
+				uuidMock.
+					On("Generate").
+					Return("00000000-0000-4000-0000-000000000000")
+				session := &models.UserSession{
+					ID:           "00000000-0000-4000-0000-000000000000",
+					UserID:       "65fdd16b5f62f93184ec8a39",
+					IPAddress:    "127.0.0.1",
+					CreatedAt:    now,
+					LastActivity: now,
+					ExpiresAt:    now.Add(UserSessionTTL),
+				}
+				mock.
+					On("UserSessionCreate", ctx, session).
+					Return(nil).
+					Once()
+				cacheMock.
+					On("Set", ctx, "user-session={00000000-0000-4000-0000-000000000000}", session, UserSessionTTL).
+					Return(nil).
+					Once()
 				cacheMock.
 					On("Set", ctx, "token_00000000-0000-4000-0000-00000000000065fdd16b5f62f93184ec8a39", testifymock.Anything, time.Hour*72).
 					Return(nil).
@@ -646,6 +962,27 @@ func TestAuthUser(t *testing.T) {
 					On("UserUpdate", ctx, user.ID, &models.UserChanges{LastLogin: now, Password: "$2a$10$V/6N1wsjheBVvWosPfv02uf4WAOb9lmp8YWQCIa2UYuFV4OJby7Yi"}).
 					Return(nil).
 					Once()
+				uuidMock := &uuidmock.Uuid{}
+				uuid.DefaultBackend = uuidMock
+				uuidMock.
+					On("Generate").
+					Return("00000000-0000-4000-0000-000000000000")
+				session := &models.UserSession{
+					ID:           "00000000-0000-4000-0000-000000000000",
+					UserID:       "65fdd16b5f62f93184ec8a39",
+					IPAddress:    "127.0.0.1",
+					CreatedAt:    now,
+					LastActivity: now,
+					ExpiresAt:    now.Add(UserSessionTTL),
+				}
+				mock.
+					On("UserSessionCreate", ctx, session).
+					Return(nil).
+					Once()
+				cacheMock.
+					On("Set", ctx, "user-session={00000000-0000-4000-0000-000000000000}", session, UserSessionTTL).
+					Return(nil).
+					Once()
 				cacheMock.
 					On("Set", ctx, "token_65fdd16b5f62f93184ec8a39", testifymock.Anything, time.Hour*72).
 					Return(nil).
@@ -671,7 +1008,10 @@ func TestAuthUser(t *testing.T) {
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	require.NoError(t, err)
 
-	service := NewService(store.Store(mock), privateKey, &privateKey.PublicKey, cacheMock, clientMock, nil)
+	locator := &mocksGeoIp.Locator{}
+	locator.On("GetPosition", net.ParseIP("127.0.0.1")).Return(geoip.Position{}, nil)
+
+	service := NewService(store.Store(mock), privateKey, &privateKey.PublicKey, cacheMock, clientMock, locator)
 
 	for _, tc := range tests {
 		t.Run(tc.description, func(t *testing.T) {
@@ -896,65 +1236,109 @@ func TestAuthGetToken(t *testing.T) {
 
 	ctx := context.TODO()
 
-	type Expected struct {
-		userAuthResponse *models.UserAuthResponse
-		err              error
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	locator := &mocksGeoIp.Locator{}
+	locator.On("GetPosition", net.ParseIP("127.0.0.1")).Return(geoip.Position{}, nil)
+
+	uuidMock := &uuidmock.Uuid{}
+	uuid.DefaultBackend = uuidMock
+	uuidMock.On("Generate").Return("00000000-0000-4000-0000-000000000000")
+
+	user := &models.User{
+		UserData: models.UserData{
+			Username: "user",
+			Name:     "user",
+			Email:    "email@email.com",
+		},
+		ID: "id",
+	}
+
+	namespace := &models.Namespace{
+		Name:     "namespace",
+		Owner:    "id",
+		TenantID: "xxxxxx",
+		Members: []models.Member{
+			{
+				ID:   "id",
+				Role: "owner",
+			},
+		},
+	}
+
+	session := &models.UserSession{
+		ID:           "00000000-0000-4000-0000-000000000000",
+		UserID:       "id",
+		IPAddress:    "127.0.0.1",
+		UserAgent:    "Mozilla/5.0",
+		CreatedAt:    now,
+		LastActivity: now,
+		ExpiresAt:    now.Add(UserSessionTTL),
 	}
 
 	tests := []struct {
 		description   string
 		userID        string
 		requiredMocks func()
-		expected      Expected
+		expected      string
+		expectedErr   error
 	}{
 		{
-			description: "success when try to get a token",
-			userID:      "user",
+			description: "fails when the user is not found",
+			userID:      "id",
 			requiredMocks: func() {
-				namespace := &models.Namespace{
-					Name:     "namespace",
-					Owner:    "id",
-					TenantID: "xxxxxx",
-					Members: []models.Member{
-						{
-							ID:   "memberID",
-							Role: "owner",
-						},
-					},
-				}
-
-				mock.On("UserGetByID", ctx, "user", false).Return(&models.User{
-					UserData: models.UserData{
-						Username: "user",
-						Name:     "user",
-						Email:    "email@email.com",
-					},
-					ID: "id",
-				}, 1, nil).Once()
+				mock.On("UserGetByID", ctx, "id", false).Return(nil, 0, errors.New("error", "", 0)).Once()
+			},
+			expectedErr: NewErrUserNotFound("id", errors.New("error", "", 0)),
+		},
+		{
+			description: "succeeds without a session when it cannot be created",
+			userID:      "id",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(user, 1, nil).Once()
 				mock.On("NamespaceGetFirst", ctx, "id").Return(namespace, nil).Once()
+				mock.On("UserSessionCreate", ctx, session).Return(errors.New("error", "", 0)).Once()
 
-				clockMock.On("Now").Return(now).Twice()
+				clockMock.On("Now").Return(now)
 			},
-			expected: Expected{
-				userAuthResponse: &models.UserAuthResponse{},
-				err:              nil,
+			expected: "",
+		},
+		{
+			description: "succeeds issuing a token bound to a new session",
+			userID:      "id",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(user, 1, nil).Once()
+				mock.On("NamespaceGetFirst", ctx, "id").Return(namespace, nil).Once()
+				mock.On("UserSessionCreate", ctx, session).Return(nil).Once()
+
+				clockMock.On("Now").Return(now)
 			},
+			expected: "00000000-0000-4000-0000-000000000000",
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.description, func(t *testing.T) {
-			mock.ExpectedCalls = nil
 			tc.requiredMocks()
 
-			privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
-			assert.NoError(t, err)
+			service := NewService(mock, privateKey, &privateKey.PublicKey, storecache.NewNullCache(), clientMock, locator)
 
-			service := NewService(mock, privateKey, &privateKey.PublicKey, storecache.NewNullCache(), clientMock, nil)
+			authRes, err := service.AuthGetToken(ctx, tc.userID, "127.0.0.1", "Mozilla/5.0")
+			assert.Equal(t, tc.expectedErr, err)
 
-			authRes, err := service.AuthGetToken(ctx, tc.userID)
-			assert.NotNil(t, authRes)
-			assert.Equal(t, tc.expected.err, err)
+			if tc.expectedErr == nil {
+				require.NotNil(t, authRes)
+				assert.Equal(t, "xxxxxx", authRes.Tenant)
+				assert.Equal(t, "owner", authRes.Role)
+
+				claims := new(models.UserAuthClaims)
+				_, err := jwt.ParseWithClaims(authRes.Token, claims, func(*jwt.Token) (interface{}, error) {
+					return &privateKey.PublicKey, nil
+				})
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, claims.Session)
+			}
 
 			mock.AssertExpectations(t)
 		})
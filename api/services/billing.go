@@ -1,12 +1,35 @@
 package services
 
 import (
+	"context"
+	"time"
+
 	req "github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/models"
 )
 
+// BillingStatusCacheTTL is how long, by default, whether a namespace has a subscription is cached to report its
+// deletion to the billing.
+const BillingStatusCacheTTL = 5 * time.Minute
+
 type BillingInterface interface {
 	BillingEvaluate(req.Client, string) (bool, error)
 	BillingReport(req.Client, string, string) error
+	// RefreshBillingCache caches again whether the namespace with the specified tenant ID has a subscription, so a
+	// change on its billing is seen before the cached status expires.
+	RefreshBillingCache(ctx context.Context, tenantID string) error
+}
+
+// cachedBillingStatus is whether a namespace has a subscription as cached. TenantID is set even when it has none,
+// telling it apart from a cache miss.
+type cachedBillingStatus struct {
+	TenantID   string
+	Reportable bool
+}
+
+func billingStatusCacheKey(tenantID string) string {
+	return "billing:status:{" + tenantID + "}"
 }
 
 // BillingEvaluate evaluate in the billing service if the namespace can create accept more devices.
@@ -39,3 +62,61 @@ func (s *service) BillingReport(client req.Client, tenant string, action string)
 		return ErrReport
 	}
 }
+
+func (s *service) RefreshBillingCache(ctx context.Context, tenantID string) error {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	_, err = s.cacheBillingStatus(ctx, namespace)
+
+	return err
+}
+
+// ableToReportDeleteNamespace reports whether the deletion of the namespace is reported to the billing, what happens
+// when it has a customer with a subscription. The status is cached, avoiding to evaluate it on every deletion attempt.
+func (s *service) ableToReportDeleteNamespace(ctx context.Context, namespace *models.Namespace) bool {
+	cached := new(cachedBillingStatus)
+	if err := s.cache.Get(ctx, billingStatusCacheKey(namespace.TenantID), cached); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("tenant_id", namespace.TenantID).
+			Warn("unable to get the billing status from cache")
+	}
+
+	if cached.TenantID == namespace.TenantID {
+		return cached.Reportable
+	}
+
+	cached, err := s.cacheBillingStatus(ctx, namespace)
+	if err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("tenant_id", namespace.TenantID).
+			Warn("unable to set the billing status in cache")
+	}
+
+	return cached.Reportable
+}
+
+// cacheBillingStatus caches whether the namespace has a customer with a subscription for the configured TTL.
+func (s *service) cacheBillingStatus(ctx context.Context, namespace *models.Namespace) (*cachedBillingStatus, error) {
+	cached := &cachedBillingStatus{
+		TenantID:   namespace.TenantID,
+		Reportable: !namespace.Billing.IsNil() && namespace.Billing.HasCutomer() && namespace.Billing.HasSubscription(),
+	}
+
+	return cached, s.cache.Set(ctx, billingStatusCacheKey(namespace.TenantID), cached, s.billingStatusTTL)
+}
+
+// invalidateBillingStatus removes the cached billing status of the namespace, so it's evaluated again on the next
+// deletion attempt.
+func (s *service) invalidateBillingStatus(ctx context.Context, tenantID string) {
+	if err := s.cache.Delete(ctx, billingStatusCacheKey(tenantID)); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("tenant_id", tenantID).
+			Warn("unable to invalidate the cached billing status")
+	}
+}
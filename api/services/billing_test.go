@@ -1,14 +1,19 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	"github.com/shellhub-io/shellhub/pkg/cache"
+	mockcache "github.com/shellhub-io/shellhub/pkg/cache/mocks"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
 )
 
 func TestBillingEvaluate(t *testing.T) {
@@ -116,3 +121,163 @@ func TestBillingReport(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestAbleToReportDeleteNamespace(t *testing.T) {
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	cached := func(reportable bool) func(testifymock.Arguments) {
+		return func(args testifymock.Arguments) {
+			*args.Get(2).(*cachedBillingStatus) = cachedBillingStatus{TenantID: tenantID, Reportable: reportable}
+		}
+	}
+
+	cases := []struct {
+		description   string
+		namespace     *models.Namespace
+		requiredMocks func()
+		expected      bool
+	}{
+		{
+			description: "succeeds when the status is cached",
+			namespace:   &models.Namespace{TenantID: tenantID},
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "billing:status:{"+tenantID+"}", testifymock.Anything).Run(cached(true)).Return(nil).Once()
+			},
+			expected: true,
+		},
+		{
+			description: "succeeds caching the status when it isn't cached",
+			namespace:   &models.Namespace{TenantID: tenantID, Billing: &models.Billing{CustomerID: "customer", SubscriptionID: "subscription"}},
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "billing:status:{"+tenantID+"}", testifymock.Anything).Return(nil).Once()
+				cacheMock.
+					On("Set", ctx, "billing:status:{"+tenantID+"}", &cachedBillingStatus{TenantID: tenantID, Reportable: true}, time.Minute).
+					Return(nil).
+					Once()
+			},
+			expected: true,
+		},
+		{
+			description: "succeeds caching the status of a namespace without subscription",
+			namespace:   &models.Namespace{TenantID: tenantID, Billing: &models.Billing{CustomerID: "customer"}},
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "billing:status:{"+tenantID+"}", testifymock.Anything).Return(nil).Once()
+				cacheMock.
+					On("Set", ctx, "billing:status:{"+tenantID+"}", &cachedBillingStatus{TenantID: tenantID}, time.Minute).
+					Return(nil).
+					Once()
+			},
+			expected: false,
+		},
+		{
+			description: "succeeds even when the cache fails",
+			namespace:   &models.Namespace{TenantID: tenantID, Billing: &models.Billing{CustomerID: "customer", SubscriptionID: "subscription"}},
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "billing:status:{"+tenantID+"}", testifymock.Anything).Return(errors.New("error")).Once()
+				cacheMock.
+					On("Set", ctx, "billing:status:{"+tenantID+"}", &cachedBillingStatus{TenantID: tenantID, Reportable: true}, time.Minute).
+					Return(errors.New("error")).
+					Once()
+			},
+			expected: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(new(mocks.Store)), privateKey, publicKey, cacheMock, clientMock, nil, WithBillingStatusCacheTTL(time.Minute))
+			assert.Equal(t, tc.expected, service.ableToReportDeleteNamespace(ctx, tc.namespace))
+		})
+	}
+
+	cacheMock.AssertExpectations(t)
+}
+
+func TestRefreshBillingCache(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when the namespace is not found",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the status cannot be cached",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				cacheMock.
+					On("Set", ctx, "billing:status:{"+tenantID+"}", &cachedBillingStatus{TenantID: tenantID}, BillingStatusCacheTTL).
+					Return(errors.New("error")).
+					Once()
+			},
+			expected: errors.New("error"),
+		},
+		{
+			description: "succeeds",
+			requiredMocks: func() {
+				storeMock.
+					On("NamespaceGet", ctx, tenantID, false).
+					Return(&models.Namespace{TenantID: tenantID, Billing: &models.Billing{CustomerID: "customer", SubscriptionID: "subscription"}}, nil).
+					Once()
+				cacheMock.
+					On("Set", ctx, "billing:status:{"+tenantID+"}", &cachedBillingStatus{TenantID: tenantID, Reportable: true}, BillingStatusCacheTTL).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			assert.Equal(t, tc.expected, service.RefreshBillingCache(ctx, tenantID))
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestEditNamespaceInvalidatesBillingStatus(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	namespace := &models.Namespace{TenantID: tenantID, Name: "namespace"}
+
+	storeMock.On("NamespaceEdit", ctx, tenantID, &models.NamespaceChanges{}).Return(nil).Once()
+	cacheMock.On("Delete", ctx, "billing:status:{"+tenantID+"}").Return(nil).Once()
+	storeMock.On("NamespaceGet", ctx, tenantID, true).Return(namespace, nil).Once()
+
+	service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+	edited, err := service.EditNamespace(ctx, &requests.NamespaceEdit{TenantParam: requests.TenantParam{Tenant: tenantID}})
+	assert.NoError(t, err)
+	assert.Equal(t, namespace, edited)
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
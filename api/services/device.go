@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/shellhub-io/shellhub/api/store"
 	req "github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/envs"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/pkg/validator"
@@ -22,11 +24,36 @@ type DeviceService interface {
 	GetDevice(ctx context.Context, uid models.UID) (*models.Device, error)
 	GetDeviceByPublicURLAddress(ctx context.Context, address string) (*models.Device, error)
 	DeleteDevice(ctx context.Context, uid models.UID, tenant string) error
+	// CleanupConnectorDevices removes the offline devices created by connectors on the namespace, what are left behind
+	// when a connector is stopped or its containers are gone. The online ones are kept, as they are still served by a
+	// running connector. When dryRun is true, nothing is removed.
+	//
+	// It returns the devices that were, or would be, removed.
+	CleanupConnectorDevices(ctx context.Context, tenant string, dryRun bool) ([]models.Device, error)
 	RenameDevice(ctx context.Context, uid models.UID, name, tenant string) error
 	LookupDevice(ctx context.Context, namespace, name string) (*models.Device, error)
 	OfflineDevice(ctx context.Context, uid models.UID) error
 	UpdateDeviceStatus(ctx context.Context, tenant string, uid models.UID, status models.DeviceStatus) error
 	UpdateDevice(ctx context.Context, tenant string, uid models.UID, name *string, publicURL *bool) error
+	// PingDevice checks if the device is reachable through its tunnel, waiting at most the timeout for an answer.
+	PingDevice(ctx context.Context, deviceUID, tenantID string, timeout time.Duration) (*models.DevicePingResult, error)
+	// PreShareDeviceHostKey sets the PEM encoded public key the device must present, from now on, to register and to be
+	// connected through the SSH server.
+	PreShareDeviceHostKey(ctx context.Context, deviceUID, tenantID, publicKeyPEM string) error
+	// SetDeviceSessionPolicy sets the policy applied to the SSH sessions to the device, taking precedence over its
+	// namespace's settings. A nil policy removes it, leaving only the namespace's settings.
+	SetDeviceSessionPolicy(ctx context.Context, deviceUID, tenantID string, policy *models.DeviceSessionPolicy) error
+	// UpdateDeviceAllowedCommands sets the glob patterns of the only commands that can be executed on the device
+	// through the SSH server, what also refuses interactive shells on it. No patterns remove the restriction.
+	UpdateDeviceAllowedCommands(ctx context.Context, deviceUID, tenantID string, commands []string) error
+	// SetDeviceExpiry sets when the device's access to its namespace ends. A nil expiresAt keeps the access forever.
+	SetDeviceExpiry(ctx context.Context, deviceUID, tenantID string, expiresAt *time.Time) error
+	// CheckDeviceExpiry checks if the device's access to its namespace has not ended, returning NewErrDeviceExpired
+	// when it has.
+	CheckDeviceExpiry(ctx context.Context, uid models.UID) error
+	// ExpireDevice rejects the device whose access to its namespace has ended, recording the expiration on the
+	// device's timeline.
+	ExpireDevice(ctx context.Context, tenantID string, uid models.UID) error
 }
 
 func (s *service) ListDevices(ctx context.Context, tenant string, status models.DeviceStatus, paginator query.Paginator, filter query.Filters, sorter query.Sorter) ([]models.Device, int, error) {
@@ -116,7 +143,43 @@ func (s *service) DeleteDevice(ctx context.Context, uid models.UID, tenant strin
 		}
 	}
 
-	return s.store.DeviceDelete(ctx, uid)
+	if err := s.store.DeviceDelete(ctx, uid); err != nil {
+		return err
+	}
+
+	s.recordDeviceEvent(ctx, tenant, uid, models.DeviceEventRemoved, nil)
+
+	return nil
+}
+
+func (s *service) CleanupConnectorDevices(ctx context.Context, tenant string, dryRun bool) ([]models.Device, error) {
+	if _, err := s.store.NamespaceGet(ctx, tenant, false); err != nil {
+		return nil, NewErrNamespaceNotFound(tenant, err)
+	}
+
+	devices, err := s.store.DeviceListByPlatform(ctx, tenant, models.DevicePlatformConnector)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := make([]models.Device, 0, len(devices))
+	for _, device := range devices {
+		if !device.Online {
+			orphans = append(orphans, device)
+		}
+	}
+
+	if dryRun {
+		return orphans, nil
+	}
+
+	for _, device := range orphans {
+		if err := s.DeleteDevice(ctx, models.UID(device.UID), tenant); err != nil {
+			return nil, err
+		}
+	}
+
+	return orphans, nil
 }
 
 func (s *service) RenameDevice(ctx context.Context, uid models.UID, name, tenant string) error {
@@ -160,7 +223,13 @@ func (s *service) RenameDevice(ctx context.Context, uid models.UID, name, tenant
 		return NewErrDeviceDuplicated(otherDevice.Name, err)
 	}
 
-	return s.store.DeviceRename(ctx, uid, name)
+	if err := s.store.DeviceRename(ctx, uid, name); err != nil {
+		return err
+	}
+
+	s.recordDeviceEvent(ctx, tenant, uid, models.DeviceEventRenamed, map[string]string{"from": device.Name, "to": updatedDevice.Name})
+
+	return nil
 }
 
 // LookupDevice looks for a device in a namespace.
@@ -169,6 +238,16 @@ func (s *service) RenameDevice(ctx context.Context, uid models.UID, name, tenant
 // device name from models.Device.
 func (s *service) LookupDevice(ctx context.Context, namespace, name string) (*models.Device, error) {
 	device, err := s.store.DeviceLookup(ctx, namespace, name)
+	if errors.Is(err, store.ErrNoDocuments) {
+		// After a rename, the namespace's previous name still resolves during a grace period to avoid breaking
+		// connections started right before it.
+		if grace := namespaceRenameGracePeriod(); grace > 0 {
+			if ns, nsErr := s.store.NamespaceGetByPreviousName(ctx, namespace, clock.Now().Add(-grace)); nsErr == nil {
+				device, err = s.store.DeviceLookup(ctx, ns.Name, name)
+			}
+		}
+	}
+
 	if err != nil || device == nil {
 		return nil, NewErrDeviceLookupNotFound(namespace, name, err)
 	}
@@ -176,6 +255,17 @@ func (s *service) LookupDevice(ctx context.Context, namespace, name string) (*mo
 	return device, nil
 }
 
+// namespaceRenameGracePeriod returns how long a namespace's previous name still resolves on device lookups after a
+// rename. It is defined, in seconds, by SHELLHUB_NAMESPACE_RENAME_GRACE_PERIOD and is disabled by default.
+func namespaceRenameGracePeriod() time.Duration {
+	seconds, err := strconv.Atoi(envs.DefaultBackend.Get("SHELLHUB_NAMESPACE_RENAME_GRACE_PERIOD"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
 func (s *service) OfflineDevice(ctx context.Context, uid models.UID) error {
 	if err := s.store.DeviceSetOffline(ctx, string(uid)); err != nil {
 		if errors.Is(err, store.ErrNoDocuments) {
@@ -188,8 +278,46 @@ func (s *service) OfflineDevice(ctx context.Context, uid models.UID) error {
 	return nil
 }
 
-// UpdateDeviceStatus updates the device status.
+func (s *service) PingDevice(ctx context.Context, deviceUID, tenantID string, timeout time.Duration) (*models.DevicePingResult, error) {
+	device, err := s.store.DeviceGetByUID(ctx, models.UID(deviceUID), tenantID)
+	if err != nil {
+		if errors.Is(err, store.ErrNoDocuments) {
+			return nil, NewErrDeviceNotFound(models.UID(deviceUID), err)
+		}
+
+		return nil, err
+	}
+
+	if !device.Online {
+		return &models.DevicePingResult{Reachable: false, Error: "device is offline"}, nil
+	}
+
+	result, err := s.client.(req.Client).PingDevice(tenantID, deviceUID, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateDeviceStatus updates the device status, recording on the device's timeline when it is accepted or rejected.
 func (s *service) UpdateDeviceStatus(ctx context.Context, tenant string, uid models.UID, status models.DeviceStatus) error {
+	if err := s.updateDeviceStatus(ctx, tenant, uid, status); err != nil {
+		return err
+	}
+
+	switch status {
+	case models.DeviceStatusAccepted:
+		s.recordDeviceEvent(ctx, tenant, uid, models.DeviceEventAccepted, nil)
+		s.emitWebhookEvent(ctx, tenant, models.WebhookEventDeviceAccepted, map[string]string{"uid": string(uid)})
+	case models.DeviceStatusRejected:
+		s.recordDeviceEvent(ctx, tenant, uid, models.DeviceEventRejected, nil)
+	}
+
+	return nil
+}
+
+func (s *service) updateDeviceStatus(ctx context.Context, tenant string, uid models.UID, status models.DeviceStatus) error {
 	namespace, err := s.store.NamespaceGet(ctx, tenant, true)
 	if err != nil {
 		return NewErrNamespaceNotFound(tenant, err)
@@ -335,3 +463,66 @@ func (s *service) UpdateDevice(ctx context.Context, tenant string, uid models.UI
 
 	return s.store.DeviceUpdate(ctx, tenant, uid, name, publicURL)
 }
+
+func (s *service) PreShareDeviceHostKey(ctx context.Context, deviceUID, tenantID, publicKeyPEM string) error {
+	if _, err := models.ParseDeviceHostKey(publicKeyPEM); err != nil {
+		return NewErrDeviceHostKeyInvalid(err)
+	}
+
+	if _, err := s.store.DeviceGetByUID(ctx, models.UID(deviceUID), tenantID); err != nil {
+		return NewErrDeviceNotFound(models.UID(deviceUID), err)
+	}
+
+	return s.store.DeviceSetTrustedHostKey(ctx, models.UID(deviceUID), publicKeyPEM)
+}
+
+func (s *service) SetDeviceSessionPolicy(ctx context.Context, deviceUID, tenantID string, policy *models.DeviceSessionPolicy) error {
+	if _, err := s.store.DeviceGetByUID(ctx, models.UID(deviceUID), tenantID); err != nil {
+		return NewErrDeviceNotFound(models.UID(deviceUID), err)
+	}
+
+	return s.store.DeviceSetSessionPolicy(ctx, models.UID(deviceUID), policy)
+}
+
+func (s *service) UpdateDeviceAllowedCommands(ctx context.Context, deviceUID, tenantID string, commands []string) error {
+	if _, err := s.store.DeviceGetByUID(ctx, models.UID(deviceUID), tenantID); err != nil {
+		return NewErrDeviceNotFound(models.UID(deviceUID), err)
+	}
+
+	return s.store.DeviceSetAllowedCommands(ctx, models.UID(deviceUID), commands)
+}
+
+func (s *service) SetDeviceExpiry(ctx context.Context, deviceUID, tenantID string, expiresAt *time.Time) error {
+	if _, err := s.store.DeviceGetByUID(ctx, models.UID(deviceUID), tenantID); err != nil {
+		return NewErrDeviceNotFound(models.UID(deviceUID), err)
+	}
+
+	return s.store.DeviceSetExpiresAt(ctx, models.UID(deviceUID), expiresAt)
+}
+
+func (s *service) CheckDeviceExpiry(ctx context.Context, uid models.UID) error {
+	device, err := s.store.DeviceGet(ctx, uid)
+	if err != nil {
+		return NewErrDeviceNotFound(uid, err)
+	}
+
+	if device.Expired(clock.Now()) {
+		return NewErrDeviceExpired(nil)
+	}
+
+	return nil
+}
+
+func (s *service) ExpireDevice(ctx context.Context, tenantID string, uid models.UID) error {
+	if err := s.store.DeviceUpdateStatus(ctx, uid, models.DeviceStatusRejected); err != nil {
+		if errors.Is(err, store.ErrNoDocuments) {
+			return NewErrDeviceNotFound(uid, err)
+		}
+
+		return err
+	}
+
+	s.recordDeviceEvent(ctx, tenantID, uid, models.DeviceEventExpired, nil)
+
+	return nil
+}
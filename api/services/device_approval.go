@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	"github.com/shellhub-io/shellhub/pkg/envs"
+	"github.com/shellhub-io/shellhub/pkg/errors"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type DeviceApprovalService interface {
+	// ListPendingDevices lists the devices of the namespace with the specified tenant ID awaiting acceptance, the
+	// oldest ones first.
+	ListPendingDevices(ctx context.Context, tenantID string, paginator query.Paginator) ([]models.Device, int, error)
+	// BulkAcceptDevices accepts the devices with the specified UIDs on the namespace, returning the result of each one.
+	// When accepting every device not accepted yet would exceed the namespace's maximum number of devices, none is
+	// accepted and NewErrDeviceMaxDevicesReached is returned.
+	BulkAcceptDevices(ctx context.Context, tenantID string, uids []models.UID) ([]responses.DeviceBulkResult, error)
+	// BulkRejectDevices rejects the devices with the specified UIDs on the namespace, returning the result of each one.
+	BulkRejectDevices(ctx context.Context, tenantID string, uids []models.UID) ([]responses.DeviceBulkResult, error)
+}
+
+func (s *service) ListPendingDevices(ctx context.Context, tenantID string, paginator query.Paginator) ([]models.Device, int, error) {
+	// NOTICE: the devices are matched by the tenant ID, as the listing only restricts them to the tenant in context.
+	filters := query.Filters{
+		Data: []query.Filter{
+			{Type: query.FilterTypeProperty, Params: &query.FilterProperty{Name: "tenant_id", Operator: "eq", Value: tenantID}},
+		},
+	}
+
+	return s.ListDevices(ctx, tenantID, models.DeviceStatusPending, paginator, filters, query.Sorter{By: "created_at", Order: query.OrderAsc})
+}
+
+func (s *service) BulkAcceptDevices(ctx context.Context, tenantID string, uids []models.UID) ([]responses.DeviceBulkResult, error) {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, true)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	uids = uniqueUIDs(uids)
+
+	if (envs.IsCommunity() || envs.IsEnterprise()) && namespace.HasMaxDevices() {
+		devices, err := s.store.DeviceListByUIDs(ctx, tenantID, uids)
+		if err != nil {
+			return nil, err
+		}
+
+		accepting := 0
+		for _, device := range devices {
+			if device.Status != models.DeviceStatusAccepted {
+				accepting++
+			}
+		}
+
+		if namespace.DevicesCount+accepting > namespace.MaxDevices {
+			return nil, NewErrDeviceMaxDevicesReached(namespace.MaxDevices)
+		}
+	}
+
+	return s.bulkUpdateDeviceStatus(ctx, tenantID, uids, models.DeviceStatusAccepted), nil
+}
+
+func (s *service) BulkRejectDevices(ctx context.Context, tenantID string, uids []models.UID) ([]responses.DeviceBulkResult, error) {
+	if _, err := s.store.NamespaceGet(ctx, tenantID, false); err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	return s.bulkUpdateDeviceStatus(ctx, tenantID, uniqueUIDs(uids), models.DeviceStatusRejected), nil
+}
+
+// bulkUpdateDeviceStatus updates the status of each device, one at a time, so a device that fails doesn't prevent the
+// others from being updated.
+func (s *service) bulkUpdateDeviceStatus(ctx context.Context, tenantID string, uids []models.UID, status models.DeviceStatus) []responses.DeviceBulkResult {
+	results := make([]responses.DeviceBulkResult, 0, len(uids))
+	for _, uid := range uids {
+		result := responses.DeviceBulkResult{UID: string(uid)}
+		if err := s.UpdateDeviceStatus(ctx, tenantID, uid, status); err != nil {
+			result.Error = deviceBulkError(err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// deviceBulkError returns the code of the error an action on a device of a batch failed with, keeping the messages of
+// the inner errors away from the clients.
+func deviceBulkError(err error) string {
+	var e errors.Error
+	if !errors.As(err, &e) || e.Layer != ErrLayer {
+		return responses.DeviceBulkErrorInternal
+	}
+
+	switch e.Code {
+	case ErrCodeNotFound:
+		return responses.DeviceBulkErrorNotFound
+	case ErrCodeInvalid:
+		return responses.DeviceBulkErrorInvalid
+	case ErrCodeDuplicated:
+		return responses.DeviceBulkErrorDuplicated
+	case ErrCodeLimit:
+		return responses.DeviceBulkErrorLimit
+	case ErrCodePayment:
+		return responses.DeviceBulkErrorPayment
+	default:
+		return responses.DeviceBulkErrorInternal
+	}
+}
+
+// uniqueUIDs returns the UIDs without the repeated ones, keeping their order.
+func uniqueUIDs(uids []models.UID) []models.UID {
+	seen := make(map[models.UID]bool, len(uids))
+	unique := make([]models.UID, 0, len(uids))
+	for _, uid := range uids {
+		if !seen[uid] {
+			seen[uid] = true
+			unique = append(unique, uid)
+		}
+	}
+
+	return unique
+}
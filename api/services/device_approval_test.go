@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/envs"
+	env_mocks "github.com/shellhub-io/shellhub/pkg/envs/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+func TestListPendingDevices(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	paginator := query.Paginator{Page: 1, PerPage: 10}
+	filters := query.Filters{
+		Data: []query.Filter{
+			{Type: query.FilterTypeProperty, Params: &query.FilterProperty{Name: "tenant_id", Operator: "eq", Value: tenantID}},
+		},
+	}
+	sorter := query.Sorter{By: "created_at", Order: query.OrderAsc}
+
+	storeMock.On("NamespaceGet", ctx, tenantID, true).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+	storeMock.On("DeviceList", ctx, models.DeviceStatusPending, paginator, filters, sorter, store.DeviceAcceptableIfNotAccepted).
+		Return([]models.Device{{UID: "uid", TenantID: tenantID, Status: models.DeviceStatusPending}}, 1, nil).
+		Once()
+
+	service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	devices, count, err := service.ListPendingDevices(ctx, tenantID, paginator)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []models.Device{{UID: "uid", TenantID: tenantID, Status: models.DeviceStatusPending}}, devices)
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestBulkAcceptDevices(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	backend := envs.DefaultBackend
+	t.Cleanup(func() {
+		envs.DefaultBackend = backend
+	})
+
+	// NOTICE: the devices are accepted as on a community instance, where the namespace's maximum number of devices is
+	// enforced.
+	envsMock := new(env_mocks.Backend)
+	envs.DefaultBackend = envsMock
+	envsMock.On("Get", "SHELLHUB_CLOUD").Return("false")
+	envsMock.On("Get", "SHELLHUB_ENTERPRISE").Return("false")
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	pending := func(uid string) *models.Device {
+		return &models.Device{UID: uid, Name: uid, TenantID: tenantID, Status: models.DeviceStatusPending, Identity: &models.DeviceIdentity{MAC: uid}}
+	}
+
+	type Expected struct {
+		results []responses.DeviceBulkResult
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		uids          []models.UID
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the namespace is not found",
+			uids:        []models.UID{"a"},
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, true).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{nil, NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when accepting the devices would exceed the maximum number of devices",
+			uids:        []models.UID{"a", "b", "a"},
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, true).Return(&models.Namespace{TenantID: tenantID, MaxDevices: 3, DevicesCount: 2}, nil).Once()
+				storeMock.On("DeviceListByUIDs", ctx, tenantID, []models.UID{"a", "b"}).Return([]models.Device{*pending("a"), *pending("b")}, nil).Once()
+			},
+			expected: Expected{nil, NewErrDeviceMaxDevicesReached(3)},
+		},
+		{
+			description: "succeeds reporting the result of each device",
+			uids:        []models.UID{"a", "b"},
+			requiredMocks: func() {
+				namespace := &models.Namespace{TenantID: tenantID, MaxDevices: 3, DevicesCount: 1}
+
+				storeMock.On("NamespaceGet", ctx, tenantID, true).Return(namespace, nil).Once()
+				storeMock.On("DeviceListByUIDs", ctx, tenantID, []models.UID{"a", "b"}).Return([]models.Device{*pending("a")}, nil).Once()
+
+				storeMock.On("NamespaceGet", ctx, tenantID, true).Return(namespace, nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, models.UID("a"), tenantID).Return(pending("a"), nil).Once()
+				storeMock.On("DeviceGetByMac", ctx, "a", tenantID, models.DeviceStatusAccepted).Return(nil, store.ErrNoDocuments).Once()
+				storeMock.On("DeviceGetByName", ctx, "a", tenantID, models.DeviceStatusAccepted).Return(nil, store.ErrNoDocuments).Once()
+				storeMock.On("DeviceUpdateStatus", ctx, models.UID("a"), models.DeviceStatusAccepted).Return(nil).Once()
+				storeMock.On("DeviceEventCreate", ctx, testifymock.Anything).Return(nil).Once()
+
+				storeMock.On("NamespaceGet", ctx, tenantID, true).Return(namespace, nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, models.UID("b"), tenantID).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{
+				results: []responses.DeviceBulkResult{
+					{UID: "a"},
+					{UID: "b", Error: responses.DeviceBulkErrorNotFound},
+				},
+				err: nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+			results, err := service.BulkAcceptDevices(ctx, tenantID, tc.uids)
+			assert.Equal(t, tc.expected, Expected{results, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestBulkRejectDevices(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	type Expected struct {
+		results []responses.DeviceBulkResult
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		uids          []models.UID
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the namespace is not found",
+			uids:        []models.UID{"a"},
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{nil, NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments)},
+		},
+		{
+			description: "succeeds reporting the result of each device",
+			uids:        []models.UID{"a", "b", "c"},
+			requiredMocks: func() {
+				namespace := &models.Namespace{TenantID: tenantID}
+
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(namespace, nil).Once()
+
+				storeMock.On("NamespaceGet", ctx, tenantID, true).Return(namespace, nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, models.UID("a"), tenantID).
+					Return(&models.Device{UID: "a", TenantID: tenantID, Status: models.DeviceStatusPending}, nil).
+					Once()
+				storeMock.On("DeviceUpdateStatus", ctx, models.UID("a"), models.DeviceStatusRejected).Return(nil).Once()
+				storeMock.On("DeviceEventCreate", ctx, testifymock.Anything).Return(nil).Once()
+
+				storeMock.On("NamespaceGet", ctx, tenantID, true).Return(namespace, nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, models.UID("b"), tenantID).
+					Return(&models.Device{UID: "b", TenantID: tenantID, Status: models.DeviceStatusAccepted}, nil).
+					Once()
+
+				storeMock.On("NamespaceGet", ctx, tenantID, true).Return(namespace, nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, models.UID("c"), tenantID).
+					Return(&models.Device{UID: "c", TenantID: tenantID, Status: models.DeviceStatusPending}, nil).
+					Once()
+				storeMock.On("DeviceUpdateStatus", ctx, models.UID("c"), models.DeviceStatusRejected).Return(errors.New("error")).Once()
+			},
+			expected: Expected{
+				results: []responses.DeviceBulkResult{
+					{UID: "a"},
+					{UID: "b", Error: responses.DeviceBulkErrorInvalid},
+					{UID: "c", Error: responses.DeviceBulkErrorInternal},
+				},
+				err: nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+			results, err := service.BulkRejectDevices(ctx, tenantID, tc.uids)
+			assert.Equal(t, tc.expected, Expected{results, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type DeviceConnectionService interface {
+	// ListDeviceConnections lists the connections made to the device with the specified UID on the namespace with the
+	// specified tenant ID, from the most recent to the oldest, and the total number of connections made to it.
+	//
+	// It returns [NewErrDeviceNotFound] when the device isn't on the namespace.
+	ListDeviceConnections(ctx context.Context, tenantID string, uid models.UID, paginator query.Paginator) ([]models.DeviceConnection, int, error)
+}
+
+func (s *service) ListDeviceConnections(ctx context.Context, tenantID string, uid models.UID, paginator query.Paginator) ([]models.DeviceConnection, int, error) {
+	if _, err := s.store.DeviceGetByUID(ctx, uid, tenantID); err != nil {
+		return nil, 0, NewErrDeviceNotFound(uid, err)
+	}
+
+	return s.store.DeviceConnectionHistory(ctx, string(uid), paginator)
+}
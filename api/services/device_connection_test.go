@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	goerrors "errors"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListDeviceConnections(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	paginator := query.Paginator{Page: 1, PerPage: 10}
+
+	type Expected struct {
+		connections []models.DeviceConnection
+		count       int
+		err         error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the device isn't on the namespace",
+			requiredMocks: func() {
+				storeMock.On("DeviceGetByUID", ctx, models.UID("device"), tenantID).
+					Return(nil, store.ErrNoDocuments).
+					Once()
+			},
+			expected: Expected{err: NewErrDeviceNotFound(models.UID("device"), store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the connections cannot be listed",
+			requiredMocks: func() {
+				storeMock.On("DeviceGetByUID", ctx, models.UID("device"), tenantID).
+					Return(&models.Device{UID: "device", TenantID: tenantID}, nil).
+					Once()
+				storeMock.On("DeviceConnectionHistory", ctx, "device", paginator).
+					Return(nil, 0, goerrors.New("error")).
+					Once()
+			},
+			expected: Expected{err: goerrors.New("error")},
+		},
+		{
+			description: "succeeds listing the connections",
+			requiredMocks: func() {
+				storeMock.On("DeviceGetByUID", ctx, models.UID("device"), tenantID).
+					Return(&models.Device{UID: "device", TenantID: tenantID}, nil).
+					Once()
+				storeMock.On("DeviceConnectionHistory", ctx, "device", paginator).
+					Return([]models.DeviceConnection{{SessionUID: "session", Username: "root"}}, 1, nil).
+					Once()
+			},
+			expected: Expected{connections: []models.DeviceConnection{{SessionUID: "session", Username: "root"}}, count: 1},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			connections, count, err := service.ListDeviceConnections(ctx, tenantID, models.UID("device"), paginator)
+			assert.Equal(t, tc.expected, Expected{connections, count, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
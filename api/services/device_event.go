@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+type DeviceEventService interface {
+	// ListDeviceEvents lists the lifecycle events, like its acceptance or its renaming, of the device with the
+	// specified UID on the namespace with the specified tenant ID. As the events outlive the device, the events of a
+	// removed device are still listed.
+	ListDeviceEvents(ctx context.Context, tenantID string, uid models.UID, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.DeviceEvent, int, error)
+}
+
+func (s *service) ListDeviceEvents(ctx context.Context, tenantID string, uid models.UID, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.DeviceEvent, int, error) {
+	return s.store.DeviceEventList(ctx, tenantID, uid, paginator, filters, sorter)
+}
+
+// recordDeviceEvent records an event on the device's timeline, attributing it to the user performing the request, if
+// any. As the timeline is meant for troubleshooting, a failure doesn't interrupt the action that triggered it.
+func (s *service) recordDeviceEvent(ctx context.Context, tenantID string, uid models.UID, kind models.DeviceEventType, data map[string]string) {
+	event := &models.DeviceEvent{
+		TenantID:  tenantID,
+		DeviceUID: string(uid),
+		Type:      kind,
+		Data:      data,
+	}
+
+	if id := gateway.IDFromContext(ctx); id != nil {
+		event.Actor = id.ID
+	}
+
+	if err := s.store.DeviceEventCreate(ctx, event); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithFields(log.Fields{"uid": uid, "type": kind}).
+			Warn("unable to record the device event")
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	goerrors "errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+// matchDeviceEvent matches the event of the specified type recorded on the timeline of the device with the specified
+// UID.
+func matchDeviceEvent(uid models.UID, kind models.DeviceEventType) interface{} {
+	return testifymock.MatchedBy(func(event *models.DeviceEvent) bool {
+		return event.DeviceUID == string(uid) && event.Type == kind
+	})
+}
+
+func TestListDeviceEvents(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	paginator := query.Paginator{Page: 1, PerPage: 10}
+	filters := query.Filters{}
+	sorter := query.Sorter{Order: query.OrderDesc}
+
+	type Expected struct {
+		events []models.DeviceEvent
+		count  int
+		err    error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the events cannot be listed",
+			requiredMocks: func() {
+				storeMock.On("DeviceEventList", ctx, tenantID, models.UID("device"), paginator, filters, sorter).
+					Return(nil, 0, goerrors.New("error")).
+					Once()
+			},
+			expected: Expected{err: goerrors.New("error")},
+		},
+		{
+			description: "succeeds listing the events",
+			requiredMocks: func() {
+				storeMock.On("DeviceEventList", ctx, tenantID, models.UID("device"), paginator, filters, sorter).
+					Return([]models.DeviceEvent{{DeviceUID: "device", Type: models.DeviceEventAccepted}}, 1, nil).
+					Once()
+			},
+			expected: Expected{events: []models.DeviceEvent{{DeviceUID: "device", Type: models.DeviceEventAccepted}}, count: 1},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			events, count, err := service.ListDeviceEvents(ctx, tenantID, models.UID("device"), paginator, filters, sorter)
+			assert.Equal(t, tc.expected, Expected{events, count, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestDeleteDevice_records_event(t *testing.T) {
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	request := func(id string) context.Context {
+		req := httptest.NewRequest(http.MethodDelete, "/api/devices/device", nil)
+		if id != "" {
+			req.Header.Set("X-ID", id)
+		}
+
+		c := gateway.NewContext(nil, echo.New().NewContext(req, httptest.NewRecorder()))
+
+		return context.WithValue(context.TODO(), "ctx", c) //nolint:revive
+	}
+
+	cases := []struct {
+		description string
+		ctx         context.Context
+		err         error
+		expected    *models.DeviceEvent
+	}{
+		{
+			description: "records the event attributed to the user performing the request",
+			ctx:         request("user"),
+			expected: &models.DeviceEvent{
+				TenantID:  tenantID,
+				DeviceUID: "device",
+				Type:      models.DeviceEventRemoved,
+				Actor:     "user",
+			},
+		},
+		{
+			description: "records the event without an actor when there is no user",
+			ctx:         context.TODO(),
+			expected: &models.DeviceEvent{
+				TenantID:  tenantID,
+				DeviceUID: "device",
+				Type:      models.DeviceEventRemoved,
+			},
+		},
+		{
+			description: "doesn't fail when the event cannot be recorded",
+			ctx:         request("user"),
+			err:         goerrors.New("error"),
+			expected: &models.DeviceEvent{
+				TenantID:  tenantID,
+				DeviceUID: "device",
+				Type:      models.DeviceEventRemoved,
+				Actor:     "user",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			storeMock := new(mocks.Store)
+			storeMock.On("DeviceGetByUID", tc.ctx, models.UID("device"), tenantID).
+				Return(&models.Device{UID: "device", TenantID: tenantID}, nil).
+				Once()
+			storeMock.On("NamespaceGet", tc.ctx, tenantID, false).
+				Return(&models.Namespace{TenantID: tenantID}, nil).
+				Once()
+			envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
+			storeMock.On("DeviceDelete", tc.ctx, models.UID("device")).
+				Return(nil).
+				Once()
+			storeMock.On("DeviceEventCreate", tc.ctx, tc.expected).
+				Return(tc.err).
+				Once()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			assert.NoError(t, service.DeleteDevice(tc.ctx, models.UID("device"), tenantID))
+
+			storeMock.AssertExpectations(t)
+		})
+	}
+}
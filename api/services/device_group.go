@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// DeviceGroupCacheTTL is how long a device group is cached to evaluate the firewall rules restricted to it.
+const DeviceGroupCacheTTL = 30 * time.Second
+
+type DeviceGroupService interface {
+	// CreateDeviceGroup creates a device group in the namespace with the specified tenant ID. Every member must be a
+	// device of the same namespace.
+	CreateDeviceGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupCreate) (*models.DeviceGroup, error)
+
+	// GetDeviceGroup retrieves the device group with the specified UID from the namespace with the specified tenant ID.
+	GetDeviceGroup(ctx context.Context, tenantID, uid string) (*models.DeviceGroup, error)
+
+	// UpdateDeviceGroup updates the name or the members of a device group, returning the updated group. The group's
+	// cached membership is invalidated, so the firewall rules restricted to it are evaluated against the new members
+	// right away.
+	UpdateDeviceGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupUpdate) (*models.DeviceGroup, error)
+
+	// AddDeviceToGroup adds a device of the namespace with the specified tenant ID to a device group, returning the
+	// updated group. Adding a device that is already a member does nothing.
+	AddDeviceToGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupDevice) (*models.DeviceGroup, error)
+
+	// RemoveDeviceFromGroup removes a device from a device group, returning the updated group. Removing a device that
+	// isn't a member does nothing.
+	RemoveDeviceFromGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupDevice) (*models.DeviceGroup, error)
+
+	// ListDeviceGroupDevices lists the devices of a device group, returning the devices of the requested page and
+	// the total of devices in the group.
+	ListDeviceGroupDevices(ctx context.Context, tenantID string, req *requests.DeviceGroupDevicesList) ([]models.Device, int, error)
+}
+
+// deviceGroupCacheKey returns the cache key of the device group with the specified UID.
+func deviceGroupCacheKey(uid string) string {
+	return "device-group={" + uid + "}"
+}
+
+// checkDeviceGroupMembers checks if every device is a member of the namespace with the specified tenant ID.
+func (s *service) checkDeviceGroupMembers(ctx context.Context, tenantID string, devices []string) error {
+	for _, uid := range devices {
+		if _, err := s.store.DeviceGetByUID(ctx, models.UID(uid), tenantID); err != nil {
+			return NewErrDeviceNotFound(models.UID(uid), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) CreateDeviceGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupCreate) (*models.DeviceGroup, error) {
+	if _, err := s.store.NamespaceGet(ctx, tenantID, false); err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if err := s.checkDeviceGroupMembers(ctx, tenantID, req.Devices); err != nil {
+		return nil, err
+	}
+
+	group := &models.DeviceGroup{
+		UID:      uuid.Generate(),
+		TenantID: tenantID,
+		Name:     req.Name,
+		Devices:  req.Devices,
+	}
+
+	if err := s.store.DeviceGroupCreate(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (s *service) GetDeviceGroup(ctx context.Context, tenantID, uid string) (*models.DeviceGroup, error) {
+	group, err := s.store.DeviceGroupGet(ctx, tenantID, uid)
+	if err != nil {
+		return nil, NewErrDeviceGroupNotFound(uid, err)
+	}
+
+	return group, nil
+}
+
+func (s *service) UpdateDeviceGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupUpdate) (*models.DeviceGroup, error) {
+	if req.Name == nil && req.Devices == nil {
+		return nil, NewErrDeviceGroupInvalid(map[string]interface{}{"uid": req.UID}, nil)
+	}
+
+	if _, err := s.store.DeviceGroupGet(ctx, tenantID, req.UID); err != nil {
+		return nil, NewErrDeviceGroupNotFound(req.UID, err)
+	}
+
+	changes := &models.DeviceGroupChanges{Name: req.Name}
+	if req.Devices != nil {
+		if err := s.checkDeviceGroupMembers(ctx, tenantID, req.Devices); err != nil {
+			return nil, err
+		}
+
+		changes.Devices = &req.Devices
+	}
+
+	if err := s.store.DeviceGroupUpdate(ctx, tenantID, req.UID, changes); err != nil {
+		if err == store.ErrNoDocuments {
+			return nil, NewErrDeviceGroupNotFound(req.UID, err)
+		}
+
+		return nil, err
+	}
+
+	s.invalidateDeviceGroup(ctx, tenantID, req.UID, req.Devices != nil)
+
+	return s.store.DeviceGroupGet(ctx, tenantID, req.UID)
+}
+
+func (s *service) AddDeviceToGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupDevice) (*models.DeviceGroup, error) {
+	if err := s.checkDeviceGroupMembers(ctx, tenantID, []string{req.Device}); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.DeviceGroupAddDevice(ctx, tenantID, req.UID, req.Device); err != nil {
+		if err == store.ErrNoDocuments {
+			return nil, NewErrDeviceGroupNotFound(req.UID, err)
+		}
+
+		return nil, err
+	}
+
+	s.invalidateDeviceGroup(ctx, tenantID, req.UID, true)
+
+	return s.store.DeviceGroupGet(ctx, tenantID, req.UID)
+}
+
+func (s *service) RemoveDeviceFromGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupDevice) (*models.DeviceGroup, error) {
+	if err := s.store.DeviceGroupRemoveDevice(ctx, tenantID, req.UID, req.Device); err != nil {
+		if err == store.ErrNoDocuments {
+			return nil, NewErrDeviceGroupNotFound(req.UID, err)
+		}
+
+		return nil, err
+	}
+
+	s.invalidateDeviceGroup(ctx, tenantID, req.UID, true)
+
+	return s.store.DeviceGroupGet(ctx, tenantID, req.UID)
+}
+
+func (s *service) ListDeviceGroupDevices(ctx context.Context, tenantID string, req *requests.DeviceGroupDevicesList) ([]models.Device, int, error) {
+	devices, count, err := s.store.DeviceGroupListDevices(ctx, tenantID, req.UID, req.Paginator)
+	if err != nil {
+		if err == store.ErrNoDocuments {
+			return nil, 0, NewErrDeviceGroupNotFound(req.UID, err)
+		}
+
+		return nil, 0, err
+	}
+
+	return devices, count, nil
+}
+
+// invalidateDeviceGroup invalidates the cached device group, so the firewall rules restricted to it are evaluated
+// against its current members right away. When its members changed, the affected firewall rules are logged.
+func (s *service) invalidateDeviceGroup(ctx context.Context, tenantID, uid string, membersChanged bool) {
+	if err := s.cache.Delete(ctx, deviceGroupCacheKey(uid)); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("uid", uid).
+			Warn("unable to invalidate the cached device group")
+	}
+
+	if membersChanged {
+		rules, err := s.store.FirewallRuleListByDeviceGroup(ctx, tenantID, uid)
+		if err == nil && len(rules) > 0 {
+			logger.FromContext(ctx).
+				WithFields(log.Fields{"uid": uid, "tenant_id": tenantID, "firewall_rules": len(rules)}).
+				Info("device group members changed, affecting its firewall rules")
+		}
+	}
+}
+
+// deviceGroupHas reports whether the device is a member of the device group. The group is cached for
+// [DeviceGroupCacheTTL], avoiding querying the store on every connection when they arrive at high rates. A group that
+// doesn't exist has no members.
+func (s *service) deviceGroupHas(ctx context.Context, tenantID, groupUID, deviceUID string) (bool, error) {
+	group := new(models.DeviceGroup)
+	if err := s.cache.Get(ctx, deviceGroupCacheKey(groupUID), group); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("uid", groupUID).
+			Warn("unable to get the device group from cache")
+	}
+
+	if group.UID == "" {
+		var err error
+		if group, err = s.store.DeviceGroupGet(ctx, tenantID, groupUID); err != nil {
+			if err == store.ErrNoDocuments {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		if err := s.cache.Set(ctx, deviceGroupCacheKey(groupUID), group, DeviceGroupCacheTTL); err != nil {
+			logger.FromContext(ctx).
+				WithError(err).
+				WithField("uid", groupUID).
+				Warn("unable to set the device group in cache")
+		}
+	}
+
+	// NOTICE: the group UID is unique, but the cached group is checked against the tenant as well, as a rule can only
+	// target groups of its own namespace.
+	return group.TenantID == tenantID && group.Has(deviceUID), nil
+}
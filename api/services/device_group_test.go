@@ -0,0 +1,492 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	goerrors "errors"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	mockcache "github.com/shellhub-io/shellhub/pkg/cache/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+func TestCreateDeviceGroup(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	type Expected struct {
+		group *models.DeviceGroup
+		err   error
+	}
+
+	cases := []struct {
+		description   string
+		req           *requests.DeviceGroupCreate
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the namespace is not found",
+			req:         &requests.DeviceGroupCreate{Name: "servers"},
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when a device is not in the namespace",
+			req:         &requests.DeviceGroupCreate{Name: "servers", Devices: []string{"device"}},
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, models.UID("device"), tenantID).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrDeviceNotFound(models.UID("device"), store.ErrNoDocuments)},
+		},
+		{
+			description: "succeeds creating the group",
+			req:         &requests.DeviceGroupCreate{Name: "servers", Devices: []string{"device"}},
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, models.UID("device"), tenantID).Return(&models.Device{UID: "device"}, nil).Once()
+				storeMock.On("DeviceGroupCreate", ctx, testifymock.MatchedBy(func(group *models.DeviceGroup) bool {
+					return group.UID != "" && group.TenantID == tenantID && group.Name == "servers"
+				})).Return(nil).Once()
+			},
+			expected: Expected{group: &models.DeviceGroup{TenantID: tenantID, Name: "servers", Devices: []string{"device"}}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			group, err := service.CreateDeviceGroup(ctx, tenantID, tc.req)
+			if group != nil {
+				group.UID = ""
+			}
+
+			assert.Equal(t, tc.expected, Expected{group, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestUpdateDeviceGroup(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	name := "databases"
+	devices := []string{"device"}
+
+	type Expected struct {
+		group *models.DeviceGroup
+		err   error
+	}
+
+	cases := []struct {
+		description   string
+		req           *requests.DeviceGroupUpdate
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description:   "fails when there is nothing to update",
+			req:           &requests.DeviceGroupUpdate{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}},
+			requiredMocks: func() {},
+			expected:      Expected{err: NewErrDeviceGroupInvalid(map[string]interface{}{"uid": "group"}, nil)},
+		},
+		{
+			description: "fails when the group is not found",
+			req:         &requests.DeviceGroupUpdate{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}, Name: &name},
+			requiredMocks: func() {
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrDeviceGroupNotFound("group", store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when a device is not in the namespace",
+			req:         &requests.DeviceGroupUpdate{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}, Devices: devices},
+			requiredMocks: func() {
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").Return(&models.DeviceGroup{UID: "group"}, nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, models.UID("device"), tenantID).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrDeviceNotFound(models.UID("device"), store.ErrNoDocuments)},
+		},
+		{
+			description: "succeeds renaming the group and invalidating its cache",
+			req:         &requests.DeviceGroupUpdate{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}, Name: &name},
+			requiredMocks: func() {
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").Return(&models.DeviceGroup{UID: "group"}, nil).Once()
+				storeMock.On("DeviceGroupUpdate", ctx, tenantID, "group", &models.DeviceGroupChanges{Name: &name}).Return(nil).Once()
+				cacheMock.On("Delete", ctx, "device-group={group}").Return(nil).Once()
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").Return(&models.DeviceGroup{UID: "group", Name: name}, nil).Once()
+			},
+			expected: Expected{group: &models.DeviceGroup{UID: "group", Name: name}},
+		},
+		{
+			description: "succeeds changing the members and invalidating its cache",
+			req:         &requests.DeviceGroupUpdate{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}, Devices: devices},
+			requiredMocks: func() {
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").Return(&models.DeviceGroup{UID: "group"}, nil).Once()
+				storeMock.On("DeviceGetByUID", ctx, models.UID("device"), tenantID).Return(&models.Device{UID: "device"}, nil).Once()
+				storeMock.On("DeviceGroupUpdate", ctx, tenantID, "group", &models.DeviceGroupChanges{Devices: &devices}).Return(nil).Once()
+				// NOTICE: failing to invalidate the cache doesn't fail the update, as the cache expires shortly.
+				cacheMock.On("Delete", ctx, "device-group={group}").Return(goerrors.New("error")).Once()
+				storeMock.On("FirewallRuleListByDeviceGroup", ctx, tenantID, "group").Return([]models.FirewallRule{{}}, nil).Once()
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").Return(&models.DeviceGroup{UID: "group", Devices: devices}, nil).Once()
+			},
+			expected: Expected{group: &models.DeviceGroup{UID: "group", Devices: devices}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			group, err := service.UpdateDeviceGroup(ctx, tenantID, tc.req)
+			assert.Equal(t, tc.expected, Expected{group, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestAddDeviceToGroup(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	req := &requests.DeviceGroupDevice{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}, Device: "device"}
+
+	type Expected struct {
+		group *models.DeviceGroup
+		err   error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the device is not in the namespace",
+			requiredMocks: func() {
+				storeMock.On("DeviceGetByUID", ctx, models.UID("device"), tenantID).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrDeviceNotFound(models.UID("device"), store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the group is not found",
+			requiredMocks: func() {
+				storeMock.On("DeviceGetByUID", ctx, models.UID("device"), tenantID).Return(&models.Device{UID: "device"}, nil).Once()
+				storeMock.On("DeviceGroupAddDevice", ctx, tenantID, "group", "device").Return(store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrDeviceGroupNotFound("group", store.ErrNoDocuments)},
+		},
+		{
+			description: "succeeds adding the device and invalidating the group's cache",
+			requiredMocks: func() {
+				storeMock.On("DeviceGetByUID", ctx, models.UID("device"), tenantID).Return(&models.Device{UID: "device"}, nil).Once()
+				storeMock.On("DeviceGroupAddDevice", ctx, tenantID, "group", "device").Return(nil).Once()
+				cacheMock.On("Delete", ctx, "device-group={group}").Return(nil).Once()
+				storeMock.On("FirewallRuleListByDeviceGroup", ctx, tenantID, "group").Return([]models.FirewallRule{}, nil).Once()
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").Return(&models.DeviceGroup{UID: "group", Devices: []string{"device"}}, nil).Once()
+			},
+			expected: Expected{group: &models.DeviceGroup{UID: "group", Devices: []string{"device"}}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			group, err := service.AddDeviceToGroup(ctx, tenantID, req)
+			assert.Equal(t, tc.expected, Expected{group, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestRemoveDeviceFromGroup(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	req := &requests.DeviceGroupDevice{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}, Device: "device"}
+
+	type Expected struct {
+		group *models.DeviceGroup
+		err   error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the group is not found",
+			requiredMocks: func() {
+				storeMock.On("DeviceGroupRemoveDevice", ctx, tenantID, "group", "device").Return(store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrDeviceGroupNotFound("group", store.ErrNoDocuments)},
+		},
+		{
+			description: "succeeds removing the device and invalidating the group's cache",
+			requiredMocks: func() {
+				storeMock.On("DeviceGroupRemoveDevice", ctx, tenantID, "group", "device").Return(nil).Once()
+				cacheMock.On("Delete", ctx, "device-group={group}").Return(nil).Once()
+				storeMock.On("FirewallRuleListByDeviceGroup", ctx, tenantID, "group").Return([]models.FirewallRule{}, nil).Once()
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").Return(&models.DeviceGroup{UID: "group", Devices: []string{}}, nil).Once()
+			},
+			expected: Expected{group: &models.DeviceGroup{UID: "group", Devices: []string{}}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			group, err := service.RemoveDeviceFromGroup(ctx, tenantID, req)
+			assert.Equal(t, tc.expected, Expected{group, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestListDeviceGroupDevices(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	paginator := query.Paginator{Page: 1, PerPage: 10}
+	req := &requests.DeviceGroupDevicesList{DeviceGroupParam: requests.DeviceGroupParam{UID: "group"}, Paginator: paginator}
+
+	type Expected struct {
+		devices []models.Device
+		count   int
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the group is not found",
+			requiredMocks: func() {
+				storeMock.On("DeviceGroupListDevices", ctx, tenantID, "group", paginator).Return(nil, 0, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrDeviceGroupNotFound("group", store.ErrNoDocuments)},
+		},
+		{
+			description: "succeeds listing the group's devices",
+			requiredMocks: func() {
+				storeMock.On("DeviceGroupListDevices", ctx, tenantID, "group", paginator).
+					Return([]models.Device{{UID: "device"}}, 1, nil).
+					Once()
+			},
+			expected: Expected{devices: []models.Device{{UID: "device"}}, count: 1},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			devices, count, err := service.ListDeviceGroupDevices(ctx, tenantID, req)
+			assert.Equal(t, tc.expected, Expected{devices, count, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestFirewallEvaluate_device_group(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	namespace := &models.Namespace{
+		Name:     "namespace",
+		TenantID: tenantID,
+		Settings: &models.NamespaceSettings{DefaultFirewallPolicy: models.FirewallPolicyAllow},
+	}
+
+	// The rule denies the connections to any device of the group.
+	rules := []models.FirewallRule{
+		{
+			TenantID: tenantID,
+			FirewallRuleFields: models.FirewallRuleFields{
+				Priority:       1,
+				Action:         models.FirewallPolicyDeny,
+				Active:         true,
+				SourceIP:       ".*",
+				Username:       ".*",
+				Filter:         models.FirewallFilter{Hostname: ".*"},
+				DeviceGroupUID: "group",
+			},
+		},
+	}
+
+	req := requests.FirewallEvaluate{
+		Domain:    "namespace",
+		Name:      "device",
+		Username:  "root",
+		IPAddress: "192.168.0.1",
+	}
+
+	cached := func(group *models.DeviceGroup) func(args testifymock.Arguments) {
+		return func(args testifymock.Arguments) {
+			*args.Get(2).(*models.DeviceGroup) = *group
+		}
+	}
+
+	type Expected struct {
+		allowed bool
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when cannot get the group",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGetByName", ctx, "namespace").Return(namespace, nil).Once()
+				storeMock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+				storeMock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{UID: "device"}, nil).Once()
+				cacheMock.On("Get", ctx, "device-group={group}", testifymock.Anything).Return(nil).Once()
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").Return(nil, goerrors.New("error")).Once()
+			},
+			expected: Expected{allowed: false, err: goerrors.New("error")},
+		},
+		{
+			description: "succeeds skipping the rule when the device is not found",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGetByName", ctx, "namespace").Return(namespace, nil).Once()
+				storeMock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+				storeMock.On("DeviceLookup", ctx, "namespace", "device").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{allowed: true, err: nil},
+		},
+		{
+			description: "succeeds skipping the rule when the group does not exist",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGetByName", ctx, "namespace").Return(namespace, nil).Once()
+				storeMock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+				storeMock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{UID: "device"}, nil).Once()
+				cacheMock.On("Get", ctx, "device-group={group}", testifymock.Anything).Return(nil).Once()
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{allowed: true, err: nil},
+		},
+		{
+			description: "succeeds skipping the rule when the device is not a member of the group",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGetByName", ctx, "namespace").Return(namespace, nil).Once()
+				storeMock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+				storeMock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{UID: "device"}, nil).Once()
+				cacheMock.On("Get", ctx, "device-group={group}", testifymock.Anything).Return(nil).Once()
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").
+					Return(&models.DeviceGroup{UID: "group", TenantID: tenantID, Devices: []string{"other"}}, nil).
+					Once()
+				cacheMock.On("Set", ctx, "device-group={group}", testifymock.Anything, DeviceGroupCacheTTL).Return(nil).Once()
+			},
+			expected: Expected{allowed: true, err: nil},
+		},
+		{
+			description: "succeeds matching the rule when the device is a member of the group, caching it",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGetByName", ctx, "namespace").Return(namespace, nil).Once()
+				storeMock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+				storeMock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{UID: "device"}, nil).Once()
+				cacheMock.On("Get", ctx, "device-group={group}", testifymock.Anything).Return(nil).Once()
+				storeMock.On("DeviceGroupGet", ctx, tenantID, "group").
+					Return(&models.DeviceGroup{UID: "group", TenantID: tenantID, Devices: []string{"other", "device"}}, nil).
+					Once()
+				cacheMock.On("Set", ctx, "device-group={group}", testifymock.Anything, DeviceGroupCacheTTL).Return(nil).Once()
+			},
+			expected: Expected{allowed: false, err: nil},
+		},
+		{
+			description: "succeeds matching the rule from the cached group",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGetByName", ctx, "namespace").Return(namespace, nil).Once()
+				storeMock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+				storeMock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{UID: "device"}, nil).Once()
+				cacheMock.On("Get", ctx, "device-group={group}", testifymock.Anything).
+					Run(cached(&models.DeviceGroup{UID: "group", TenantID: tenantID, Devices: []string{"device"}})).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{allowed: false, err: nil},
+		},
+		{
+			description: "succeeds skipping the rule when the cached group belongs to another namespace",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGetByName", ctx, "namespace").Return(namespace, nil).Once()
+				storeMock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+				storeMock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{UID: "device"}, nil).Once()
+				cacheMock.On("Get", ctx, "device-group={group}", testifymock.Anything).
+					Run(cached(&models.DeviceGroup{UID: "group", TenantID: "other", Devices: []string{"device"}})).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{allowed: true, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			allowed, _, err := service.FirewallEvaluate(ctx, req)
+			assert.Equal(t, tc.expected, Expected{allowed, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
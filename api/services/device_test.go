@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"testing"
 	"time"
@@ -9,11 +13,13 @@ import (
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	storecache "github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/errors"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListDevices_cloud(t *testing.T) {
@@ -1344,6 +1350,8 @@ func TestDeleteDevice(t *testing.T) {
 				envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
 				mock.On("DeviceDelete", ctx, models.UID(device.UID)).
 					Return(nil).Once()
+				mock.On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID(device.UID), models.DeviceEventRemoved)).
+					Return(nil).Once()
 			},
 			expected: nil,
 		},
@@ -1413,6 +1421,8 @@ func TestDeleteDevice(t *testing.T) {
 				}).Return(200, nil).Once()
 				mock.On("DeviceDelete", ctx, models.UID(device.UID)).
 					Return(nil).Once()
+				mock.On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID(device.UID), models.DeviceEventRemoved)).
+					Return(nil).Once()
 			},
 			expected: nil,
 		},*/
@@ -1525,6 +1535,8 @@ func TestRenameDevice(t *testing.T) {
 				mock.On("DeviceGetByUID", ctx, models.UID("uid"), "tenant").Return(device, nil).Once()
 				mock.On("DeviceGetByName", ctx, "anewname", "tenant", models.DeviceStatusAccepted).Return(nil, store.ErrNoDocuments).Once()
 				mock.On("DeviceRename", ctx, models.UID("uid"), "anewname").Return(nil).Once()
+				mock.On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID("uid"), models.DeviceEventRenamed)).
+					Return(nil).Once()
 			},
 			expected: nil,
 		},
@@ -1579,12 +1591,49 @@ func TestLookupDevice(t *testing.T) {
 			requiredMocks: func(device *models.Device, namespace string) {
 				mock.On("DeviceLookup", ctx, namespace, device.Name).
 					Return(nil, store.ErrNoDocuments).Once()
+				envMock.On("Get", "SHELLHUB_NAMESPACE_RENAME_GRACE_PERIOD").Return("").Once()
 			},
 			expected: Expected{
 				nil,
 				NewErrDeviceLookupNotFound("namespace", "name", store.ErrNoDocuments),
 			},
 		},
+		{
+			description: "fails when the namespace was not renamed within the grace period",
+			namespace:   "namespace",
+			device:      &models.Device{UID: "uid", Name: "name", TenantID: "tenant", Identity: &models.DeviceIdentity{MAC: "00:00:00:00:00:00"}, Status: "accepted"},
+			requiredMocks: func(device *models.Device, namespace string) {
+				mock.On("DeviceLookup", ctx, namespace, device.Name).
+					Return(nil, store.ErrNoDocuments).Once()
+				envMock.On("Get", "SHELLHUB_NAMESPACE_RENAME_GRACE_PERIOD").Return("60").Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("NamespaceGetByPreviousName", ctx, namespace, now.Add(-60*time.Second)).
+					Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{
+				nil,
+				NewErrDeviceLookupNotFound("namespace", "name", store.ErrNoDocuments),
+			},
+		},
+		{
+			description: "succeeds when the namespace was renamed within the grace period",
+			namespace:   "namespace",
+			device:      &models.Device{UID: "uid", Name: "name", TenantID: "tenant", Identity: &models.DeviceIdentity{MAC: "00:00:00:00:00:00"}, Status: "accepted"},
+			requiredMocks: func(device *models.Device, namespace string) {
+				mock.On("DeviceLookup", ctx, namespace, device.Name).
+					Return(nil, store.ErrNoDocuments).Once()
+				envMock.On("Get", "SHELLHUB_NAMESPACE_RENAME_GRACE_PERIOD").Return("60").Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("NamespaceGetByPreviousName", ctx, namespace, now.Add(-60*time.Second)).
+					Return(&models.Namespace{Name: "renamed", TenantID: "tenant"}, nil).Once()
+				mock.On("DeviceLookup", ctx, "renamed", device.Name).
+					Return(device, nil).Once()
+			},
+			expected: Expected{
+				&models.Device{UID: "uid", Name: "name", TenantID: "tenant", Identity: &models.DeviceIdentity{MAC: "00:00:00:00:00:00"}, Status: "accepted"},
+				nil,
+			},
+		},
 		{
 			description: "succeeds",
 			namespace:   "namespace",
@@ -1669,6 +1718,516 @@ func TestOfflineDevice(t *testing.T) {
 	storeMock.AssertExpectations(t)
 }
 
+func TestPingDevice(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	type Expected struct {
+		result *models.DevicePingResult
+		err    error
+	}
+
+	cases := []struct {
+		description string
+		uid         string
+		tenant      string
+		timeout     time.Duration
+		mocks       func(context.Context)
+		expected    Expected
+	}{
+		{
+			description: "fails when the device is not found",
+			uid:         "uid",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			timeout:     5 * time.Second,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(nil, store.ErrNoDocuments).
+					Once()
+			},
+			expected: Expected{
+				result: nil,
+				err:    NewErrDeviceNotFound(models.UID("uid"), store.ErrNoDocuments),
+			},
+		},
+		{
+			description: "fails when the store fails to get the device",
+			uid:         "uid",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			timeout:     5 * time.Second,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(nil, errors.New("error", "", 0)).
+					Once()
+			},
+			expected: Expected{
+				result: nil,
+				err:    errors.New("error", "", 0),
+			},
+		},
+		{
+			description: "returns unreachable when the device is offline",
+			uid:         "uid",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			timeout:     5 * time.Second,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(&models.Device{UID: "uid", Online: false}, nil).
+					Once()
+			},
+			expected: Expected{
+				result: &models.DevicePingResult{Reachable: false, Error: "device is offline"},
+				err:    nil,
+			},
+		},
+		{
+			description: "fails when the SSH server cannot be reached",
+			uid:         "uid",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			timeout:     5 * time.Second,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(&models.Device{UID: "uid", Online: true}, nil).
+					Once()
+				clientMock.
+					On("PingDevice", "00000000-0000-4000-0000-000000000000", "uid", 5*time.Second).
+					Return(nil, internalclient.ErrConnectionFailed).
+					Once()
+			},
+			expected: Expected{
+				result: nil,
+				err:    internalclient.ErrConnectionFailed,
+			},
+		},
+		{
+			description: "returns unreachable when the agent does not answer before the timeout",
+			uid:         "uid",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			timeout:     100 * time.Millisecond,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(&models.Device{UID: "uid", Online: true}, nil).
+					Once()
+				clientMock.
+					On("PingDevice", "00000000-0000-4000-0000-000000000000", "uid", 100*time.Millisecond).
+					Return(&models.DevicePingResult{Reachable: false, Error: "context deadline exceeded"}, nil).
+					Once()
+			},
+			expected: Expected{
+				result: &models.DevicePingResult{Reachable: false, Error: "context deadline exceeded"},
+				err:    nil,
+			},
+		},
+		{
+			description: "succeeds when the agent answers",
+			uid:         "uid",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			timeout:     5 * time.Second,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(&models.Device{UID: "uid", Online: true}, nil).
+					Once()
+				clientMock.
+					On("PingDevice", "00000000-0000-4000-0000-000000000000", "uid", 5*time.Second).
+					Return(&models.DevicePingResult{Reachable: true, LatencyMS: 42}, nil).
+					Once()
+			},
+			expected: Expected{
+				result: &models.DevicePingResult{Reachable: true, LatencyMS: 42},
+				err:    nil,
+			},
+		},
+	}
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			tc.mocks(ctx)
+
+			result, err := s.PingDevice(ctx, tc.uid, tc.tenant, tc.timeout)
+			assert.Equal(t, tc.expected, Expected{result, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	clientMock.AssertExpectations(t)
+}
+
+func TestPreShareDeviceHostKey(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	trusted := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)}))
+
+	cases := []struct {
+		description string
+		uid         string
+		tenant      string
+		key         string
+		mocks       func(context.Context)
+		expected    error
+	}{
+		{
+			description: "fails when the key is not a PEM encoded public key",
+			uid:         "uid",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			key:         "invalid",
+			mocks:       func(_ context.Context) {},
+			expected:    NewErrDeviceHostKeyInvalid(models.ErrDeviceHostKeyInvalid),
+		},
+		{
+			description: "fails when the device is not found",
+			uid:         "uid",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			key:         trusted,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(nil, store.ErrNoDocuments).
+					Once()
+			},
+			expected: NewErrDeviceNotFound(models.UID("uid"), store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the store fails to set the key",
+			uid:         "uid",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			key:         trusted,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(&models.Device{UID: "uid"}, nil).
+					Once()
+				storeMock.
+					On("DeviceSetTrustedHostKey", ctx, models.UID("uid"), trusted).
+					Return(errors.New("error", "", 0)).
+					Once()
+			},
+			expected: errors.New("error", "", 0),
+		},
+		{
+			description: "succeeds to set the trusted host key",
+			uid:         "uid",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			key:         trusted,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(&models.Device{UID: "uid"}, nil).
+					Once()
+				storeMock.
+					On("DeviceSetTrustedHostKey", ctx, models.UID("uid"), trusted).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			tc.mocks(ctx)
+
+			err := s.PreShareDeviceHostKey(ctx, tc.uid, tc.tenant, tc.key)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestSetDeviceSessionPolicy(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	policy := &models.DeviceSessionPolicy{MaxIdle: 300, ForceRecording: true, AllowedSubsystems: []string{"sftp"}}
+
+	cases := []struct {
+		description string
+		policy      *models.DeviceSessionPolicy
+		mocks       func(context.Context)
+		expected    error
+	}{
+		{
+			description: "fails when the device is not found",
+			policy:      policy,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(nil, store.ErrNoDocuments).
+					Once()
+			},
+			expected: NewErrDeviceNotFound(models.UID("uid"), store.ErrNoDocuments),
+		},
+		{
+			description: "succeeds to set the session policy",
+			policy:      policy,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(&models.Device{UID: "uid"}, nil).
+					Once()
+				storeMock.
+					On("DeviceSetSessionPolicy", ctx, models.UID("uid"), policy).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds to remove the session policy",
+			policy:      nil,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(&models.Device{UID: "uid"}, nil).
+					Once()
+				storeMock.
+					On("DeviceSetSessionPolicy", ctx, models.UID("uid"), (*models.DeviceSessionPolicy)(nil)).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			tc.mocks(ctx)
+
+			err := s.SetDeviceSessionPolicy(ctx, "uid", "00000000-0000-4000-0000-000000000000", tc.policy)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestSetDeviceExpiry(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	expiresAt := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		description string
+		expiresAt   *time.Time
+		mocks       func(context.Context)
+		expected    error
+	}{
+		{
+			description: "fails when the device is not found",
+			expiresAt:   &expiresAt,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(nil, store.ErrNoDocuments).
+					Once()
+			},
+			expected: NewErrDeviceNotFound(models.UID("uid"), store.ErrNoDocuments),
+		},
+		{
+			description: "succeeds to set the expiry",
+			expiresAt:   &expiresAt,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(&models.Device{UID: "uid"}, nil).
+					Once()
+				storeMock.
+					On("DeviceSetExpiresAt", ctx, models.UID("uid"), &expiresAt).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds to remove the expiry",
+			expiresAt:   nil,
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGetByUID", ctx, models.UID("uid"), "00000000-0000-4000-0000-000000000000").
+					Return(&models.Device{UID: "uid"}, nil).
+					Once()
+				storeMock.
+					On("DeviceSetExpiresAt", ctx, models.UID("uid"), (*time.Time)(nil)).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			tc.mocks(ctx)
+
+			err := s.SetDeviceExpiry(ctx, "uid", "00000000-0000-4000-0000-000000000000", tc.expiresAt)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestCheckDeviceExpiry(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	at := func(t time.Time) *time.Time {
+		return &t
+	}
+
+	cases := []struct {
+		description string
+		mocks       func(context.Context)
+		expected    error
+	}{
+		{
+			description: "fails when the device is not found",
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGet", ctx, models.UID("uid")).
+					Return(nil, store.ErrNoDocuments).
+					Once()
+			},
+			expected: NewErrDeviceNotFound(models.UID("uid"), store.ErrNoDocuments),
+		},
+		{
+			description: "succeeds when the device has no expiry",
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGet", ctx, models.UID("uid")).
+					Return(&models.Device{UID: "uid"}, nil).
+					Once()
+				clockMock.On("Now").Return(now).Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds when the device expires right after now",
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGet", ctx, models.UID("uid")).
+					Return(&models.Device{UID: "uid", ExpiresAt: at(now.Add(time.Nanosecond))}, nil).
+					Once()
+				clockMock.On("Now").Return(now).Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "fails when the device expires exactly at now",
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGet", ctx, models.UID("uid")).
+					Return(&models.Device{UID: "uid", ExpiresAt: at(now)}, nil).
+					Once()
+				clockMock.On("Now").Return(now).Once()
+			},
+			expected: NewErrDeviceExpired(nil),
+		},
+		{
+			description: "fails when the device has expired before now",
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceGet", ctx, models.UID("uid")).
+					Return(&models.Device{UID: "uid", ExpiresAt: at(now.Add(-time.Nanosecond))}, nil).
+					Once()
+				clockMock.On("Now").Return(now).Once()
+			},
+			expected: NewErrDeviceExpired(nil),
+		},
+	}
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			tc.mocks(ctx)
+
+			err := s.CheckDeviceExpiry(ctx, models.UID("uid"))
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestExpireDevice(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	cases := []struct {
+		description string
+		mocks       func(context.Context)
+		expected    error
+	}{
+		{
+			description: "fails when the device is not found",
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatusRejected).
+					Return(store.ErrNoDocuments).
+					Once()
+			},
+			expected: NewErrDeviceNotFound(models.UID("uid"), store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the device could not be rejected",
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatusRejected).
+					Return(errors.New("error", "", 0)).
+					Once()
+			},
+			expected: errors.New("error", "", 0),
+		},
+		{
+			description: "rejects the device and records its expiration",
+			mocks: func(ctx context.Context) {
+				storeMock.
+					On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatusRejected).
+					Return(nil).
+					Once()
+				storeMock.
+					On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID("uid"), models.DeviceEventExpired)).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			tc.mocks(ctx)
+
+			err := s.ExpireDevice(ctx, "tenant", models.UID("uid"))
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
 func TestUpdateDeviceStatus_same_mac(t *testing.T) {
 	mock := new(mocks.Store)
 
@@ -2019,6 +2578,8 @@ func TestUpdateDeviceStatus_same_mac(t *testing.T) {
 
 				mock.On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatus("accepted")).
 					Return(nil).Once()
+				mock.On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID("uid"), models.DeviceEventAccepted)).
+					Return(nil).Once()
 			},
 			expected: nil,
 		},
@@ -2293,6 +2854,8 @@ func TestUpdateDeviceStatus_community_and_enterprise(t *testing.T) {
 
 				mock.On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatus("accepted")).
 					Return(nil).Once()
+				mock.On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID("uid"), models.DeviceEventAccepted)).
+					Return(nil).Once()
 			},
 			expected: nil,
 		},
@@ -2551,6 +3114,8 @@ func TestUpdateDeviceStatus_cloud_subscription_active(t *testing.T) {
 
 				mock.On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatus("accepted")).
 					Return(nil).Once()
+				mock.On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID("uid"), models.DeviceEventAccepted)).
+					Return(nil).Once()
 			},
 			expected: nil,
 		},
@@ -2969,6 +3534,8 @@ func TestUpdateDeviceStatus_cloud_subscription_inactive(t *testing.T) {
 
 				mock.On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatus("accepted")).
 					Return(nil).Once()
+				mock.On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID("uid"), models.DeviceEventAccepted)).
+					Return(nil).Once()
 			},
 			expected: nil,
 		},
@@ -3204,6 +3771,8 @@ func TestUpdateDeviceStatus_cloud_subscription_inactive(t *testing.T) {
 
 				mock.On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatus("accepted")).
 					Return(nil).Once()
+				mock.On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID("uid"), models.DeviceEventAccepted)).
+					Return(nil).Once()
 			},
 			expected: nil,
 		},
@@ -3520,6 +4089,8 @@ func TestUpdateDeviceStatus_other_than_accepted(t *testing.T) {
 
 				mock.On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatus("rejected")).
 					Return(nil).Once()
+				mock.On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID("uid"), models.DeviceEventRejected)).
+					Return(nil).Once()
 			},
 			expected: nil,
 		},
@@ -3629,3 +4200,78 @@ func TestUpdateDeviceStatus_other_than_accepted(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestCleanupConnectorDevices(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	devices := []models.Device{
+		{UID: "online", TenantID: "tenant", Online: true, Info: &models.DeviceInfo{Platform: models.DevicePlatformConnector}},
+		{UID: "offline", TenantID: "tenant", Online: false, Info: &models.DeviceInfo{Platform: models.DevicePlatformConnector}},
+	}
+
+	type Expected struct {
+		devices []models.Device
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		dryRun        bool
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when namespace is not found",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{nil, NewErrNamespaceNotFound("tenant", store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when cannot list the connector devices",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
+				mock.On("DeviceListByPlatform", ctx, "tenant", models.DevicePlatformConnector).Return(nil, errors.New("error", "", 0)).Once()
+			},
+			expected: Expected{nil, errors.New("error", "", 0)},
+		},
+		{
+			description: "succeeds listing the offline devices without removing them on dry run",
+			dryRun:      true,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
+				mock.On("DeviceListByPlatform", ctx, "tenant", models.DevicePlatformConnector).Return(devices, nil).Once()
+			},
+			expected: Expected{[]models.Device{devices[1]}, nil},
+		},
+		{
+			description: "succeeds removing only the offline devices",
+			dryRun:      false,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
+				mock.On("DeviceListByPlatform", ctx, "tenant", models.DevicePlatformConnector).Return(devices, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("offline"), "tenant").Return(&devices[1], nil).Once()
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
+				envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
+				mock.On("DeviceDelete", ctx, models.UID("offline")).Return(nil).Once()
+				mock.On("DeviceEventCreate", ctx, matchDeviceEvent(models.UID("offline"), models.DeviceEventRemoved)).
+					Return(nil).Once()
+			},
+			expected: Expected{[]models.Device{devices[1]}, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			removed, err := service.CleanupConnectorDevices(ctx, "tenant", tc.dryRun)
+			assert.Equal(t, tc.expected, Expected{removed, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -76,6 +76,8 @@ var (
 	ErrUserPasswordNotMatch         = errors.New("user password does not match to the current password", ErrLayer, ErrCodeInvalid)
 	ErrUserNotConfirmed             = errors.New("user not confirmed", ErrLayer, ErrCodeForbidden)
 	ErrUserUpdate                   = errors.New("user update", ErrLayer, ErrCodeStore)
+	ErrUserDeleteForbidden          = errors.New("user can only delete its own account", ErrLayer, ErrCodeForbidden)
+	ErrUserOwnsNamespaces           = errors.New("user owns namespaces", ErrLayer, ErrCodeInvalid)
 	ErrNamespaceNotFound            = errors.New("namespace not found", ErrLayer, ErrCodeNotFound)
 	ErrNamespaceInvalid             = errors.New("namespace invalid", ErrLayer, ErrCodeInvalid)
 	ErrNamespaceList                = errors.New("namespace member list", ErrLayer, ErrCodeNotFound)
@@ -85,6 +87,7 @@ var (
 	ErrNamespaceMemberFillData      = errors.New("member fill data", ErrLayer, ErrCodeInvalid)
 	ErrNamespaceMemberDuplicated    = errors.New("member duplicated", ErrLayer, ErrCodeDuplicated)
 	ErrNamespaceCreateStore         = errors.New("namespace create store", ErrLayer, ErrCodeStore)
+	ErrNamespaceSessionRecord       = errors.New("namespace session record update", ErrLayer, ErrCodeStore)
 	ErrMaxTagReached                = errors.New("tag limit reached", ErrLayer, ErrCodeLimit)
 	ErrDuplicateTagName             = errors.New("tag duplicated", ErrLayer, ErrCodeDuplicated)
 	ErrTagNameNotFound              = errors.New("tag not found", ErrLayer, ErrCodeNotFound)
@@ -100,7 +103,11 @@ var (
 	ErrDeviceStatusInvalid          = errors.New("device status invalid", ErrLayer, ErrCodeInvalid)
 	ErrDeviceStatusAccepted         = errors.New("device status accepted", ErrLayer, ErrCodeInvalid)
 	ErrDeviceCreate                 = errors.New("device create", ErrLayer, ErrCodeStore)
+	ErrDeviceHostKeyInvalid         = errors.New("device host key invalid", ErrLayer, ErrCodeInvalid)
+	ErrDeviceHostKeyMismatch        = errors.New("device host key mismatch", ErrLayer, ErrCodeForbidden)
 	ErrDeviceSetOnline              = errors.New("device set online", ErrLayer, ErrCodeStore)
+	ErrDeviceExpired                = errors.New("device access expired", ErrLayer, ErrCodeForbidden)
+	ErrDeviceEnrollmentKeyInvalid   = errors.New("device enrollment key invalid", ErrLayer, ErrCodeForbidden)
 	ErrMaxDeviceCountReached        = errors.New("maximum number of accepted devices reached", ErrLayer, ErrCodeLimit)
 	ErrDuplicatedDeviceName         = errors.New("device name duplicated", ErrLayer, ErrCodeDuplicated)
 	ErrPublicKeyDuplicated          = errors.New("public key duplicated", ErrLayer, ErrCodeDuplicated)
@@ -109,12 +116,26 @@ var (
 	ErrPublicKeyNoTags              = errors.New("public key has no tags", ErrLayer, ErrCodeInvalid)
 	ErrPublicKeyDataInvalid         = errors.New("public key data invalid", ErrLayer, ErrCodeInvalid)
 	ErrPublicKeyFilter              = errors.New("public key cannot have more than one filter at same time", ErrLayer, ErrCodeInvalid)
+	ErrPublicKeyWeakKey             = errors.New("public key is weaker than allowed by the namespace", ErrLayer, ErrCodeInvalid)
+	ErrPublicKeyImportInvalid       = errors.New("public keys cannot be imported from the account", ErrLayer, ErrCodeInvalid)
+	ErrPublicKeyImportLimit         = errors.New("public key import limit reached", ErrLayer, ErrCodeLimit)
+	ErrWebhookNotFound              = errors.New("webhook not found", ErrLayer, ErrCodeNotFound)
+	ErrWebhookInvalid               = errors.New("webhook invalid", ErrLayer, ErrCodeInvalid)
 	ErrTokenSigned                  = errors.New("token signed", ErrLayer, ErrCodeInvalid)
 	ErrTypeAssertion                = errors.New("type assertion failed", ErrLayer, ErrCodeInvalid)
 	ErrSessionNotFound              = errors.New("session not found", ErrLayer, ErrCodeNotFound)
+	ErrSessionExportInvalid         = errors.New("session export config invalid", ErrLayer, ErrCodeInvalid)
+	ErrNamespaceUsageReportInvalid  = errors.New("namespace usage report invalid", ErrLayer, ErrCodeInvalid)
+	ErrNamespaceUsageReportLimit    = errors.New("namespace usage report sent too recently", ErrLayer, ErrCodeLimit)
+	ErrNamespaceDeletionInvalid     = errors.New("namespace deletion confirmation token invalid", ErrLayer, ErrCodeForbidden)
+	ErrNamespaceDeletionExpired     = errors.New("namespace deletion confirmation token expired", ErrLayer, ErrCodeForbidden)
+	ErrNamespaceDeletionMismatch    = errors.New("namespace deletion confirmation text does not match the namespace name", ErrLayer, ErrCodeInvalid)
+	ErrSessionRecordOffset          = errors.New("session record offset mismatch", ErrLayer, ErrCodeInvalid)
+	ErrSessionRecordInvalid         = errors.New("session record invalid", ErrLayer, ErrCodeInvalid)
 	ErrAuthInvalid                  = errors.New("auth invalid", ErrLayer, ErrCodeInvalid)
 	ErrAuthUnathorized              = errors.New("auth unauthorized", ErrLayer, ErrCodeUnauthorized)
 	ErrNamespaceLimitReached        = errors.New("namespace limit reached", ErrLayer, ErrCodeLimit)
+	ErrNamespaceMembersLimit        = errors.New("namespace members limit reached", ErrLayer, ErrCodeLimit)
 	ErrDeviceRemovedCount           = errors.New("device removed count", ErrLayer, ErrCodeNotFound)
 	ErrDeviceRemovedInsert          = errors.New("device removed insert", ErrLayer, ErrCodeStore)
 	ErrDeviceRemovedFull            = errors.New("device removed full", ErrLayer, ErrCodePayment)
@@ -127,6 +148,23 @@ var (
 	ErrAPIKeyNotFound               = errors.New("APIKey not found", ErrLayer, ErrCodeNotFound)
 	ErrAPIKeyDuplicated             = errors.New("APIKey duplicated", ErrLayer, ErrCodeDuplicated)
 	ErrAuthForbidden                = errors.New("user is authenticated but cannot access this resource", ErrLayer, ErrCodeForbidden)
+	ErrPlanNotFound                 = errors.New("plan not found", ErrLayer, ErrCodeNotFound)
+	ErrFirewallRuleInvalid          = errors.New("firewall rule invalid", ErrLayer, ErrCodeInvalid)
+	ErrFirewallRuleDuplicated       = errors.New("firewall rule duplicated", ErrLayer, ErrCodeDuplicated)
+	ErrDeviceGroupNotFound          = errors.New("device group not found", ErrLayer, ErrCodeNotFound)
+	ErrDeviceGroupInvalid           = errors.New("device group invalid", ErrLayer, ErrCodeInvalid)
+	ErrInviteLinkNotFound           = errors.New("invite link not found", ErrLayer, ErrCodeNotFound)
+	ErrInviteLinkInvalid            = errors.New("invite link invalid", ErrLayer, ErrCodeInvalid)
+	ErrInviteLinkExpired            = errors.New("invite link expired", ErrLayer, ErrCodeForbidden)
+	ErrInviteLinkExhausted          = errors.New("invite link exhausted", ErrLayer, ErrCodeLimit)
+	ErrUserSessionNotFound          = errors.New("user session not found", ErrLayer, ErrCodeNotFound)
+	ErrSessionTransferDisabled      = errors.New("session transfer disabled", ErrLayer, ErrCodeForbidden)
+	ErrSessionTransferInvalid       = errors.New("session transfer invalid", ErrLayer, ErrCodeInvalid)
+	ErrSessionTransferRejected      = errors.New("session transfer rejected", ErrLayer, ErrCodeForbidden)
+	ErrSessionTransferTimeout       = errors.New("session transfer timed out", ErrLayer, ErrCodeForbidden)
+	ErrSessionLiveDisabled          = errors.New("session live monitoring disabled", ErrLayer, ErrCodeForbidden)
+	ErrSessionLiveInactive          = errors.New("session is not active", ErrLayer, ErrCodeInvalid)
+	ErrSessionLiveMonitorsLimit     = errors.New("session live monitors limit reached", ErrLayer, ErrCodeLimit)
 )
 
 // NewErrNotFound returns an error with the ErrDataNotFound and wrap an error.
@@ -292,16 +330,60 @@ func NewErrPublicKeyDataInvalid(value []byte, next error) error {
 	return NewErrInvalid(ErrPublicKeyDataInvalid, map[string]interface{}{"Data": value}, next)
 }
 
+// NewErrPublicKeyWeakKey returns an error when the public key is weaker than allowed by the namespace. The details
+// hold the key's type, its actual size and the required one, in bits, what is zero when the key's type isn't allowed.
+func NewErrPublicKeyWeakKey(keyType string, bits, required int, next error) error {
+	return NewErrInvalid(ErrPublicKeyWeakKey, map[string]interface{}{"type": keyType, "bits": bits, "required": required}, next)
+}
+
 // NewErrPublicKeyFilter returns an error when the public key has more than one filter.
 func NewErrPublicKeyFilter(next error) error {
 	return NewErrInvalid(ErrPublicKeyFilter, nil, next)
 }
 
+// NewErrPublicKeyImportInvalid returns an error when the public keys of the user account cannot be retrieved from the
+// source.
+func NewErrPublicKeyImportInvalid(source, username string, next error) error {
+	return NewErrInvalid(ErrPublicKeyImportInvalid, map[string]interface{}{"source": source, "username": username}, next)
+}
+
+// NewErrPublicKeyImportLimit returns an error when the namespace already imported the maximum number of times allowed
+// in the window.
+func NewErrPublicKeyImportLimit(limit int, next error) error {
+	return NewErrLimit(ErrPublicKeyImportLimit, limit, next)
+}
+
 // NewErrDeviceNotFound returns an error when the device is not found.
 func NewErrDeviceNotFound(id models.UID, next error) error {
 	return NewErrNotFound(ErrDeviceNotFound, string(id), next)
 }
 
+// NewErrDeviceGroupNotFound returns an error when the device group is not found.
+func NewErrDeviceGroupNotFound(uid string, next error) error {
+	return NewErrNotFound(ErrDeviceGroupNotFound, uid, next)
+}
+
+// NewErrDeviceGroupInvalid returns an error when the device group is invalid, like when it has no change to apply.
+func NewErrDeviceGroupInvalid(data map[string]interface{}, next error) error {
+	return NewErrInvalid(ErrDeviceGroupInvalid, data, next)
+}
+
+// NewErrSessionExportInvalid returns an error when the configuration to export a session is invalid.
+func NewErrSessionExportInvalid(next error) error {
+	return NewErrInvalid(ErrSessionExportInvalid, nil, next)
+}
+
+// NewErrSessionRecordOffset returns an error when a recording's upload is resumed from an offset other than the number
+// of bytes already received.
+func NewErrSessionRecordOffset(offset, received int64) error {
+	return NewErrInvalid(ErrSessionRecordOffset, map[string]interface{}{"offset": offset, "bytes_received": received}, nil)
+}
+
+// NewErrSessionRecordInvalid returns an error when a recording's stream has a malformed frame.
+func NewErrSessionRecordInvalid(next error) error {
+	return NewErrInvalid(ErrSessionRecordInvalid, nil, next)
+}
+
 // NewErrSessionNotFound returns an error when the session is not found.
 func NewErrSessionNotFound(id models.UID, next error) error {
 	return NewErrNotFound(ErrSessionNotFound, string(id), next)
@@ -312,6 +394,34 @@ func NewErrNamespaceList(next error) error {
 	return NewErrInvalid(ErrNamespaceList, nil, next)
 }
 
+// NewErrNamespaceUsageReportInvalid returns an error when the usage report of a namespace can't be sent, like when it
+// has no email to send it to.
+func NewErrNamespaceUsageReportInvalid(next error) error {
+	return NewErrInvalid(ErrNamespaceUsageReportInvalid, nil, next)
+}
+
+// NewErrNamespaceUsageReportLimit returns an error when the usage report of a namespace was already sent right away in
+// the last [UsageReportSendInterval].
+func NewErrNamespaceUsageReportLimit(next error) error {
+	return NewErrLimit(ErrNamespaceUsageReportLimit, 1, next)
+}
+
+// NewErrNamespaceDeletionInvalid returns an error when the token to confirm the deletion of a namespace wasn't issued
+// to it.
+func NewErrNamespaceDeletionInvalid(next error) error {
+	return NewErrForbidden(ErrNamespaceDeletionInvalid, next)
+}
+
+// NewErrNamespaceDeletionExpired returns an error when the token to confirm the deletion of a namespace is expired.
+func NewErrNamespaceDeletionExpired(next error) error {
+	return NewErrForbidden(ErrNamespaceDeletionExpired, next)
+}
+
+// NewErrNamespaceDeletionMismatch returns an error when the text to confirm the deletion of a namespace isn't its name.
+func NewErrNamespaceDeletionMismatch(next error) error {
+	return NewErrInvalid(ErrNamespaceDeletionMismatch, nil, next)
+}
+
 // NewErrNamespaceInvalid returns an error to be used when the namespace is invalid.
 func NewErrNamespaceInvalid(next error) error {
 	return NewErrInvalid(ErrNamespaceInvalid, nil, next)
@@ -368,6 +478,27 @@ func NewErrDeviceLimit(limit int, next error) error {
 	return NewErrLimit(ErrDeviceLimit, limit, next)
 }
 
+// NewErrDeviceHostKeyInvalid returns an error to be used when the pre-shared device's host key cannot be parsed.
+func NewErrDeviceHostKeyInvalid(next error) error {
+	return NewErrInvalid(ErrDeviceHostKeyInvalid, nil, next)
+}
+
+// NewErrDeviceHostKeyMismatch returns an error to be used when the device doesn't present its trusted host key.
+func NewErrDeviceHostKeyMismatch(next error) error {
+	return NewErrForbidden(ErrDeviceHostKeyMismatch, next)
+}
+
+// NewErrDeviceExpired returns an error to be used when the device's access to its namespace has ended.
+func NewErrDeviceExpired(next error) error {
+	return NewErrForbidden(ErrDeviceExpired, next)
+}
+
+// NewErrDeviceEnrollmentKeyInvalid returns an error to be used when a new device doesn't present the enrollment key of
+// its namespace.
+func NewErrDeviceEnrollmentKeyInvalid(next error) error {
+	return NewErrForbidden(ErrDeviceEnrollmentKeyInvalid, next)
+}
+
 // NewErrDeviceStatusInvalid returns an error to be used when the device's status is invalid.
 func NewErrDeviceStatusInvalid(status string, next error) error {
 	return NewErrInvalid(ErrDeviceStatusInvalid, map[string]interface{}{"status": status}, next)
@@ -400,6 +531,16 @@ func NewErrUserUpdate(user *models.User, err error) error {
 	return NewErrStore(ErrUserUpdate, user, err)
 }
 
+// NewErrUserDeleteForbidden returns an error when a user tries to delete the account of another user.
+func NewErrUserDeleteForbidden(next error) error {
+	return NewErrForbidden(ErrUserDeleteForbidden, next)
+}
+
+// NewErrUserOwnsNamespaces returns an error when a user that still owns namespaces tries to delete its account.
+func NewErrUserOwnsNamespaces(tenantIDs []string, next error) error {
+	return NewErrInvalid(ErrUserOwnsNamespaces, map[string]interface{}{"namespaces": tenantIDs}, next)
+}
+
 // NewErrDeviceCreate returns a error to be used when the device create fails.
 func NewErrDeviceCreate(device models.Device, err error) error {
 	return NewErrStore(ErrDeviceCreate, device, err)
@@ -425,6 +566,12 @@ func NewErrNamespaceLimitReached(limit int, err error) error {
 	return NewErrLimit(ErrNamespaceLimitReached, limit, err)
 }
 
+// NewErrNamespaceMembersLimitReached returns an error when the namespace has the maximum number of members of its
+// owner's plan.
+func NewErrNamespaceMembersLimitReached(limit int, err error) error {
+	return NewErrLimit(ErrNamespaceMembersLimit, limit, err)
+}
+
 func NewErrDeviceRemovedCount(next error) error {
 	return NewErrInvalid(ErrDeviceRemovedCount, nil, next)
 }
@@ -464,3 +611,88 @@ func NewErrDeviceMaxDevicesReached(count int) error {
 func NewErrAuthForbidden() error {
 	return NewErrForbidden(ErrAuthForbidden, nil)
 }
+
+// NewErrPlanNotFound returns an error when the plan is not found.
+func NewErrPlanNotFound(name string, next error) error {
+	return NewErrNotFound(ErrPlanNotFound, name, next)
+}
+
+// NewErrFirewallRuleInvalid returns an error when the firewall rule is invalid.
+func NewErrFirewallRuleInvalid(data map[string]interface{}, next error) error {
+	return NewErrInvalid(ErrFirewallRuleInvalid, data, next)
+}
+
+// NewErrFirewallRuleDuplicated returns an error when the firewall rule conflicts with an existing one.
+func NewErrFirewallRuleDuplicated(next error) error {
+	return NewErrDuplicated(ErrFirewallRuleDuplicated, nil, next)
+}
+
+// NewErrInviteLinkNotFound returns an error when the invite link is not found.
+func NewErrInviteLinkNotFound(token string, next error) error {
+	return NewErrNotFound(ErrInviteLinkNotFound, token, next)
+}
+
+// NewErrInviteLinkInvalid returns an error when the invite link is invalid, like when it can never be used.
+func NewErrInviteLinkInvalid(data map[string]interface{}, next error) error {
+	return NewErrInvalid(ErrInviteLinkInvalid, data, next)
+}
+
+// NewErrInviteLinkExpired returns an error when the invite link is expired.
+func NewErrInviteLinkExpired(next error) error {
+	return NewErrForbidden(ErrInviteLinkExpired, next)
+}
+
+// NewErrInviteLinkExhausted returns an error when the invite link was used as many times as allowed.
+func NewErrInviteLinkExhausted(maxUses int, next error) error {
+	return NewErrLimit(ErrInviteLinkExhausted, maxUses, next)
+}
+
+// NewErrUserSessionNotFound returns an error when the user session is not found.
+func NewErrUserSessionNotFound(id string, next error) error {
+	return NewErrNotFound(ErrUserSessionNotFound, id, next)
+}
+
+// NewErrSessionTransferDisabled returns an error when the namespace doesn't allow transferring sessions.
+func NewErrSessionTransferDisabled(next error) error {
+	return NewErrForbidden(ErrSessionTransferDisabled, next)
+}
+
+// NewErrSessionTransferInvalid returns an error when the session cannot be transferred to the member.
+func NewErrSessionTransferInvalid(data map[string]interface{}, next error) error {
+	return NewErrInvalid(ErrSessionTransferInvalid, data, next)
+}
+
+// NewErrSessionTransferRejected returns an error when the session's client rejects the transfer.
+func NewErrSessionTransferRejected(next error) error {
+	return NewErrForbidden(ErrSessionTransferRejected, next)
+}
+
+// NewErrSessionLiveDisabled returns an error when the namespace doesn't allow watching its active sessions.
+func NewErrSessionLiveDisabled(next error) error {
+	return NewErrForbidden(ErrSessionLiveDisabled, next)
+}
+
+// NewErrSessionLiveInactive returns an error when the watched session is not active.
+func NewErrSessionLiveInactive(id string, next error) error {
+	return NewErrInvalid(ErrSessionLiveInactive, map[string]interface{}{"uid": id}, next)
+}
+
+// NewErrSessionLiveMonitorsLimit returns an error when the session already has the maximum number of watchers.
+func NewErrSessionLiveMonitorsLimit(limit int, next error) error {
+	return NewErrLimit(ErrSessionLiveMonitorsLimit, limit, next)
+}
+
+// NewErrSessionTransferTimeout returns an error when the session's client doesn't answer the transfer in time.
+func NewErrSessionTransferTimeout(next error) error {
+	return NewErrForbidden(ErrSessionTransferTimeout, next)
+}
+
+// NewErrWebhookNotFound returns an error when the namespace has no webhook.
+func NewErrWebhookNotFound(tenantID string, next error) error {
+	return NewErrNotFound(ErrWebhookNotFound, tenantID, next)
+}
+
+// NewErrWebhookInvalid returns an error when the webhook's URL or events are invalid.
+func NewErrWebhookInvalid(data map[string]interface{}, next error) error {
+	return NewErrInvalid(ErrWebhookInvalid, data, next)
+}
@@ -0,0 +1,439 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// FirewallBulkModeSkip keeps the existing rules, ignoring the incoming rules that conflict with them.
+	FirewallBulkModeSkip = "skip"
+	// FirewallBulkModeOverwrite replaces the existing rules with the incoming rules that conflict with them.
+	FirewallBulkModeOverwrite = "overwrite"
+	// FirewallBulkModeFailOnConflict aborts the import, writing nothing, when any incoming rule conflicts.
+	FirewallBulkModeFailOnConflict = "fail_on_conflict"
+)
+
+// BulkConflict describes an incoming firewall rule that conflicts with an existing one or with an earlier rule of the
+// same import, what means that both have the same priority and hostname filter.
+type BulkConflict struct {
+	// Index is the position of the incoming rule in the imported list.
+	Index int `json:"index"`
+	// ExistingID is the ID of the existing rule, if any.
+	ExistingID string `json:"existing_id,omitempty"`
+	// DuplicateOf is the position of the earlier rule in the imported list when the incoming rule duplicates it.
+	DuplicateOf *int   `json:"duplicate_of,omitempty"`
+	Priority    int    `json:"priority"`
+	Hostname    string `json:"hostname"`
+}
+
+type FirewallService interface {
+	// BulkCreateFirewallRules imports a list of firewall rules into a namespace at once. The mode defines how the
+	// incoming rules that conflict with existing ones are handled and must be one of [FirewallBulkModeSkip],
+	// [FirewallBulkModeOverwrite] or [FirewallBulkModeFailOnConflict].
+	//
+	// It returns the number of rules created or overwritten and the list of conflicts found.
+	BulkCreateFirewallRules(ctx context.Context, tenantID, actorID string, rules []requests.FirewallRuleCreate, mode string) (created int, conflicts []BulkConflict, err error)
+
+	// FirewallEvaluate reports whether a connection described by req is allowed by the firewall rules of its namespace,
+	// returning the rule that decided it. The active rules are evaluated in the order defined by [sortFirewallRules]
+	// and the first one that matches decides; when none matches, the namespace's default firewall policy is applied
+	// and the returned rule is nil.
+	FirewallEvaluate(ctx context.Context, req requests.FirewallEvaluate) (allowed bool, rule *models.FirewallRule, err error)
+
+	// ValidateFirewallRules looks for active firewall rules of a namespace that conflict with each other, what means
+	// rules that are shadowed by a rule evaluated before them, rules duplicated or rules with the same priority and
+	// opposite actions matching common connections.
+	//
+	// As the rules' expressions are regular expressions, two different expressions are only considered to overlap
+	// when one of them matches anything, so the conflicts found are the ones that surely exist.
+	ValidateFirewallRules(ctx context.Context, tenantID string) (conflicts []models.FirewallConflict, err error)
+
+	// PreviewFirewallRule evaluates a firewall rule, without saving it, as the firewall would for a connection to each
+	// of the current accepted devices of a namespace, returning the ones it would allow or deny. It doesn't consider
+	// the namespace's other rules, what may decide the connections before it.
+	PreviewFirewallRule(ctx context.Context, tenantID string, rule models.FirewallRuleFields, connection models.FirewallPreviewConnection) (*models.FirewallRulePreview, error)
+}
+
+func (s *service) FirewallEvaluate(ctx context.Context, req requests.FirewallEvaluate) (bool, *models.FirewallRule, error) {
+	namespace, err := s.store.NamespaceGetByName(ctx, req.Domain)
+	if err != nil || namespace == nil {
+		return false, nil, NewErrNamespaceNotFound(req.Domain, err)
+	}
+
+	rules, err := s.store.FirewallRuleListActive(ctx, namespace.TenantID)
+	if err != nil {
+		return false, nil, err
+	}
+
+	sortFirewallRules(rules)
+
+	var uid string
+	var tags []string
+	if req.Name != "" {
+		// The device's UID and tags are only used to match rules restricted to device groups or filtered by tags, so a
+		// missing device just doesn't match them.
+		if device, err := s.store.DeviceLookup(ctx, namespace.Name, req.Name); err == nil && device != nil {
+			uid = device.UID
+			tags = device.Tags
+		}
+	}
+
+	for i, rule := range rules {
+		if rule.DeviceGroupUID != "" {
+			if uid == "" {
+				continue
+			}
+
+			member, err := s.deviceGroupHas(ctx, namespace.TenantID, rule.DeviceGroupUID, uid)
+			if err != nil {
+				return false, nil, err
+			}
+
+			if !member {
+				continue
+			}
+		}
+
+		if firewallRuleMatches(rule, req, tags) {
+			return rule.Action == models.FirewallPolicyAllow, &rules[i], nil
+		}
+	}
+
+	return namespace.Settings == nil || namespace.Settings.DefaultFirewallPolicy != models.FirewallPolicyDeny, nil, nil
+}
+
+// sortFirewallRules sorts the rules in the order they are evaluated: by ascending priority, with the deny rules before
+// the allow ones of the same priority and, at last, by their IDs. It keeps the decision of rules that conflict
+// independent of the order they were stored.
+func sortFirewallRules(rules []models.FirewallRule) {
+	slices.SortStableFunc(rules, func(a, b models.FirewallRule) int {
+		if a.Priority != b.Priority {
+			return a.Priority - b.Priority
+		}
+
+		if a.Action != b.Action {
+			if a.Action == models.FirewallPolicyDeny {
+				return -1
+			}
+
+			if b.Action == models.FirewallPolicyDeny {
+				return 1
+			}
+		}
+
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+// firewallRuleMatches checks if the rule applies to the connection described by req to a device with the given tags.
+// Rules with invalid expressions never match.
+func firewallRuleMatches(rule models.FirewallRule, req requests.FirewallEvaluate, tags []string) bool {
+	match := func(expr, value string) bool {
+		ok, err := regexp.MatchString(expr, value)
+
+		return err == nil && ok
+	}
+
+	if !match(rule.SourceIP, req.IPAddress) || !match(rule.Username, req.Username) {
+		return false
+	}
+
+	return firewallFilterMatches(rule.Filter, req.Name, tags)
+}
+
+// firewallFilterMatches checks if the filter applies to the device with the given name and tags. Filters with an
+// invalid hostname expression never match.
+func firewallFilterMatches(filter models.FirewallFilter, name string, tags []string) bool {
+	if filter.Hostname != "" {
+		ok, err := regexp.MatchString(filter.Hostname, name)
+
+		return err == nil && ok
+	}
+
+	for _, tag := range filter.Tags {
+		if slices.Contains(tags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *service) BulkCreateFirewallRules(ctx context.Context, tenantID, actorID string, rules []requests.FirewallRuleCreate, mode string) (int, []BulkConflict, error) {
+	switch mode {
+	case FirewallBulkModeSkip, FirewallBulkModeOverwrite, FirewallBulkModeFailOnConflict:
+	default:
+		return 0, nil, NewErrFirewallRuleInvalid(map[string]interface{}{"mode": mode}, nil)
+	}
+
+	if _, err := s.store.NamespaceGet(ctx, tenantID, false); err != nil {
+		return 0, nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	incoming := make([]models.FirewallRule, len(rules))
+	for i, rule := range rules {
+		if err := rule.FirewallRuleFields.Validate(); err != nil {
+			return 0, nil, NewErrFirewallRuleInvalid(map[string]interface{}{"index": i}, err)
+		}
+
+		if rule.DeviceGroupUID != "" {
+			if _, err := s.store.DeviceGroupGet(ctx, tenantID, rule.DeviceGroupUID); err != nil {
+				return 0, nil, NewErrFirewallRuleInvalid(map[string]interface{}{"index": i}, NewErrDeviceGroupNotFound(rule.DeviceGroupUID, err))
+			}
+		}
+
+		incoming[i] = models.FirewallRule{TenantID: tenantID, FirewallRuleFields: rule.FirewallRuleFields}
+	}
+
+	existing, err := s.store.FirewallRuleConflicts(ctx, tenantID, incoming)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	key := func(priority int, hostname string) string {
+		return strconv.Itoa(priority) + "/" + hostname
+	}
+
+	existingByKey := make(map[string]models.FirewallRule, len(existing))
+	for _, rule := range existing {
+		existingByKey[key(rule.Priority, rule.Filter.Hostname)] = rule
+	}
+
+	// imported keeps, for each key already seen in the import, the position of its first rule in the imported list and
+	// in the rules to write, so the duplicates within the import are handled as conflicts too.
+	type imported struct {
+		index    int
+		position int
+	}
+
+	seen := make(map[string]imported)
+	conflicts := make([]BulkConflict, 0)
+	create := make([]models.FirewallRule, 0, len(incoming))
+	replace := make([]models.FirewallRule, 0)
+	for i, rule := range incoming {
+		if rule.Filter.Hostname == "" {
+			create = append(create, rule)
+
+			continue
+		}
+
+		k := key(rule.Priority, rule.Filter.Hostname)
+		current, exists := existingByKey[k]
+
+		if first, ok := seen[k]; ok {
+			conflicts = append(conflicts, BulkConflict{
+				Index:       i,
+				ExistingID:  current.ID,
+				DuplicateOf: &first.index,
+				Priority:    rule.Priority,
+				Hostname:    rule.Filter.Hostname,
+			})
+
+			// When overwriting, the last duplicate of the import wins.
+			if mode == FirewallBulkModeOverwrite {
+				rule.ID = current.ID
+				if exists {
+					replace[first.position] = rule
+				} else {
+					create[first.position] = rule
+				}
+			}
+
+			continue
+		}
+
+		if !exists {
+			seen[k] = imported{index: i, position: len(create)}
+			create = append(create, rule)
+
+			continue
+		}
+
+		seen[k] = imported{index: i, position: len(replace)}
+		conflicts = append(conflicts, BulkConflict{
+			Index:      i,
+			ExistingID: current.ID,
+			Priority:   rule.Priority,
+			Hostname:   rule.Filter.Hostname,
+		})
+
+		if mode == FirewallBulkModeOverwrite {
+			rule.ID = current.ID
+			replace = append(replace, rule)
+		}
+	}
+
+	if mode == FirewallBulkModeFailOnConflict && len(conflicts) > 0 {
+		return 0, conflicts, NewErrFirewallRuleDuplicated(nil)
+	}
+
+	inserted, replaced, err := s.store.FirewallRuleBulkWrite(ctx, create, replace)
+	if err != nil {
+		return 0, conflicts, err
+	}
+
+	logger.FromContext(ctx).WithFields(log.Fields{
+		"tenant_id": tenantID,
+		"actor":     actorID,
+		"mode":      mode,
+		"inserted":  inserted,
+		"replaced":  replaced,
+		"conflicts": len(conflicts),
+	}).Info("firewall rules imported")
+
+	return int(inserted + replaced), conflicts, nil
+}
+
+func (s *service) ValidateFirewallRules(ctx context.Context, tenantID string) ([]models.FirewallConflict, error) {
+	if _, err := s.store.NamespaceGet(ctx, tenantID, false); err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	rules, err := s.store.FirewallRuleListActive(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return firewallConflicts(rules), nil
+}
+
+func (s *service) PreviewFirewallRule(ctx context.Context, tenantID string, rule models.FirewallRuleFields, connection models.FirewallPreviewConnection) (*models.FirewallRulePreview, error) {
+	if _, err := s.store.NamespaceGet(ctx, tenantID, false); err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, NewErrFirewallRuleInvalid(nil, err)
+	}
+
+	var group *models.DeviceGroup
+	if rule.DeviceGroupUID != "" {
+		var err error
+		if group, err = s.store.DeviceGroupGet(ctx, tenantID, rule.DeviceGroupUID); err != nil {
+			return nil, NewErrFirewallRuleInvalid(nil, NewErrDeviceGroupNotFound(rule.DeviceGroupUID, err))
+		}
+	}
+
+	devices, err := s.store.DeviceListByStatus(ctx, tenantID, models.DeviceStatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.FirewallRulePreview{
+		Action:  rule.Action,
+		Devices: make([]models.FirewallPreviewDevice, 0),
+	}
+
+	for _, device := range devices {
+		if group != nil && !group.Has(device.UID) {
+			continue
+		}
+
+		req := requests.FirewallEvaluate{Name: device.Name, Username: connection.Username, IPAddress: connection.IPAddress}
+		if firewallRuleMatches(models.FirewallRule{TenantID: tenantID, FirewallRuleFields: rule}, req, device.Tags) {
+			preview.Devices = append(preview.Devices, models.FirewallPreviewDevice{UID: device.UID, Name: device.Name})
+		}
+	}
+
+	return preview, nil
+}
+
+// firewallConflicts returns the conflicts between every pair of rules.
+func firewallConflicts(rules []models.FirewallRule) []models.FirewallConflict {
+	conflicts := make([]models.FirewallConflict, 0)
+	for i := range rules {
+		for j := i + 1; j < len(rules); j++ {
+			first, second := rules[i], rules[j]
+			if first.Priority > second.Priority {
+				first, second = second, first
+			}
+
+			switch {
+			case first.Priority == second.Priority:
+				if first.Action == second.Action && firewallRuleCovers(first, second) && firewallRuleCovers(second, first) {
+					conflicts = append(conflicts, models.FirewallConflict{Rule1UID: first.ID, Rule2UID: second.ID, ConflictType: models.FirewallConflictDuplicate})
+				} else if first.Action != second.Action && firewallRuleOverlaps(first, second) {
+					conflicts = append(conflicts, models.FirewallConflict{Rule1UID: first.ID, Rule2UID: second.ID, ConflictType: models.FirewallConflictContradictory})
+				}
+			case firewallRuleCovers(first, second):
+				conflicts = append(conflicts, models.FirewallConflict{Rule1UID: first.ID, Rule2UID: second.ID, ConflictType: models.FirewallConflictShadowed})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// firewallExprMatchesAll checks if the expression matches any value.
+func firewallExprMatchesAll(expr string) bool {
+	switch expr {
+	case ".*", "^.*", ".*$", "^.*$":
+		return true
+	default:
+		return false
+	}
+}
+
+// firewallExprCovers checks if the expression a matches every value matched by the expression b.
+func firewallExprCovers(a, b string) bool {
+	return a == b || firewallExprMatchesAll(a)
+}
+
+// firewallFilterCovers checks if the filter a matches every device matched by the filter b.
+func firewallFilterCovers(a, b models.FirewallFilter) bool {
+	if a.Hostname != "" {
+		if b.Hostname == "" {
+			return firewallExprMatchesAll(a.Hostname)
+		}
+
+		return firewallExprCovers(a.Hostname, b.Hostname)
+	}
+
+	if len(b.Tags) == 0 {
+		return false
+	}
+
+	for _, tag := range b.Tags {
+		if !slices.Contains(a.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// firewallRuleCovers checks if the rule a matches every connection matched by the rule b.
+func firewallRuleCovers(a, b models.FirewallRule) bool {
+	return firewallExprCovers(a.SourceIP, b.SourceIP) &&
+		firewallExprCovers(a.Username, b.Username) &&
+		firewallFilterCovers(a.Filter, b.Filter) &&
+		(a.DeviceGroupUID == "" || a.DeviceGroupUID == b.DeviceGroupUID)
+}
+
+// firewallRuleOverlaps checks if the rules a and b match, at least, a common connection.
+func firewallRuleOverlaps(a, b models.FirewallRule) bool {
+	overlaps := func(a, b string) bool {
+		return firewallExprCovers(a, b) || firewallExprCovers(b, a)
+	}
+
+	filters := firewallFilterCovers(a.Filter, b.Filter) || firewallFilterCovers(b.Filter, a.Filter)
+	for _, tag := range a.Filter.Tags {
+		if slices.Contains(b.Filter.Tags, tag) {
+			filters = true
+		}
+	}
+
+	return overlaps(a.SourceIP, b.SourceIP) &&
+		overlaps(a.Username, b.Username) &&
+		filters &&
+		(a.DeviceGroupUID == "" || b.DeviceGroupUID == "" || a.DeviceGroupUID == b.DeviceGroupUID)
+}
@@ -0,0 +1,889 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	goerrors "errors"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkCreateFirewallRules(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	rule := func(priority int, hostname string) requests.FirewallRuleCreate {
+		return requests.FirewallRuleCreate{
+			FirewallRuleFields: models.FirewallRuleFields{
+				Priority: priority,
+				Action:   "allow",
+				Active:   true,
+				SourceIP: ".*",
+				Username: ".*",
+				Filter:   models.FirewallFilter{Hostname: hostname},
+			},
+		}
+	}
+
+	model := func(id string, priority int, hostname string) models.FirewallRule {
+		return models.FirewallRule{
+			ID:                 id,
+			TenantID:           tenantID,
+			FirewallRuleFields: rule(priority, hostname).FirewallRuleFields,
+		}
+	}
+
+	// Half of the incoming rules conflict with existing ones.
+	incoming := []requests.FirewallRuleCreate{
+		rule(1, "device-1"),
+		rule(2, "device-2"),
+		rule(3, "device-3"),
+		rule(4, "device-4"),
+	}
+
+	incomingModels := []models.FirewallRule{
+		model("", 1, "device-1"),
+		model("", 2, "device-2"),
+		model("", 3, "device-3"),
+		model("", 4, "device-4"),
+	}
+
+	existing := []models.FirewallRule{
+		model("6504b7bd9b6c4a63a9ccc053", 1, "device-1"),
+		model("e92f4a5d3e1a4f7b8b2b6e9a", 3, "device-3"),
+	}
+
+	conflicts := []BulkConflict{
+		{Index: 0, ExistingID: "6504b7bd9b6c4a63a9ccc053", Priority: 1, Hostname: "device-1"},
+		{Index: 2, ExistingID: "e92f4a5d3e1a4f7b8b2b6e9a", Priority: 3, Hostname: "device-3"},
+	}
+
+	deny := func(r requests.FirewallRuleCreate) requests.FirewallRuleCreate {
+		r.Action = "deny"
+
+		return r
+	}
+
+	toPointer := func(i int) *int {
+		return &i
+	}
+
+	// The last two incoming rules duplicate the first two, and the first one also conflicts with an existing rule.
+	duplicated := []requests.FirewallRuleCreate{
+		rule(1, "device-1"),
+		rule(2, "device-2"),
+		deny(rule(2, "device-2")),
+		deny(rule(1, "device-1")),
+	}
+
+	duplicatedModels := []models.FirewallRule{
+		model("", 1, "device-1"),
+		model("", 2, "device-2"),
+		{TenantID: tenantID, FirewallRuleFields: deny(rule(2, "device-2")).FirewallRuleFields},
+		{TenantID: tenantID, FirewallRuleFields: deny(rule(1, "device-1")).FirewallRuleFields},
+	}
+
+	duplicatedConflicts := []BulkConflict{
+		{Index: 0, ExistingID: "6504b7bd9b6c4a63a9ccc053", Priority: 1, Hostname: "device-1"},
+		{Index: 2, DuplicateOf: toPointer(1), Priority: 2, Hostname: "device-2"},
+		{Index: 3, ExistingID: "6504b7bd9b6c4a63a9ccc053", DuplicateOf: toPointer(0), Priority: 1, Hostname: "device-1"},
+	}
+
+	type Expected struct {
+		created   int
+		conflicts []BulkConflict
+		err       error
+	}
+
+	cases := []struct {
+		description   string
+		rules         []requests.FirewallRuleCreate
+		mode          string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description:   "fails when mode is invalid",
+			rules:         incoming,
+			mode:          "invalid",
+			requiredMocks: func() {},
+			expected: Expected{
+				created:   0,
+				conflicts: nil,
+				err:       NewErrFirewallRuleInvalid(map[string]interface{}{"mode": "invalid"}, nil),
+			},
+		},
+		{
+			description: "fails when namespace is not found",
+			rules:       incoming,
+			mode:        FirewallBulkModeSkip,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{
+				created:   0,
+				conflicts: nil,
+				err:       NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments),
+			},
+		},
+		{
+			description: "fails when a rule is restricted to a device group that does not exist",
+			rules: []requests.FirewallRuleCreate{
+				rule(1, "device-1"),
+				func() requests.FirewallRuleCreate {
+					r := rule(2, ".*")
+					r.DeviceGroupUID = "group"
+
+					return r
+				}(),
+			},
+			mode: FirewallBulkModeSkip,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("DeviceGroupGet", ctx, tenantID, "group").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{
+				created:   0,
+				conflicts: nil,
+				err: NewErrFirewallRuleInvalid(
+					map[string]interface{}{"index": 1},
+					NewErrDeviceGroupNotFound("group", store.ErrNoDocuments),
+				),
+			},
+		},
+		{
+			description: "fails when cannot check the conflicts",
+			rules:       incoming,
+			mode:        FirewallBulkModeSkip,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleConflicts", ctx, tenantID, incomingModels).Return(nil, goerrors.New("error")).Once()
+			},
+			expected: Expected{
+				created:   0,
+				conflicts: nil,
+				err:       goerrors.New("error"),
+			},
+		},
+		{
+			description: "succeeds creating all rules when there are no conflicts",
+			rules:       incoming,
+			mode:        FirewallBulkModeFailOnConflict,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleConflicts", ctx, tenantID, incomingModels).Return([]models.FirewallRule{}, nil).Once()
+				mock.On("FirewallRuleBulkWrite", ctx, incomingModels, []models.FirewallRule{}).Return(int64(4), int64(0), nil).Once()
+			},
+			expected: Expected{
+				created:   4,
+				conflicts: []BulkConflict{},
+				err:       nil,
+			},
+		},
+		{
+			description: "succeeds skipping the half of rules that conflict",
+			rules:       incoming,
+			mode:        FirewallBulkModeSkip,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleConflicts", ctx, tenantID, incomingModels).Return(existing, nil).Once()
+				mock.On(
+					"FirewallRuleBulkWrite",
+					ctx,
+					[]models.FirewallRule{model("", 2, "device-2"), model("", 4, "device-4")},
+					[]models.FirewallRule{},
+				).Return(int64(2), int64(0), nil).Once()
+			},
+			expected: Expected{
+				created:   2,
+				conflicts: conflicts,
+				err:       nil,
+			},
+		},
+		{
+			description: "succeeds overwriting the half of rules that conflict",
+			rules:       incoming,
+			mode:        FirewallBulkModeOverwrite,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleConflicts", ctx, tenantID, incomingModels).Return(existing, nil).Once()
+				mock.On(
+					"FirewallRuleBulkWrite",
+					ctx,
+					[]models.FirewallRule{model("", 2, "device-2"), model("", 4, "device-4")},
+					existing,
+				).Return(int64(2), int64(2), nil).Once()
+			},
+			expected: Expected{
+				created:   4,
+				conflicts: conflicts,
+				err:       nil,
+			},
+		},
+		{
+			description: "fails without writing when the half of rules conflict",
+			rules:       incoming,
+			mode:        FirewallBulkModeFailOnConflict,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleConflicts", ctx, tenantID, incomingModels).Return(existing, nil).Once()
+			},
+			expected: Expected{
+				created:   0,
+				conflicts: conflicts,
+				err:       NewErrFirewallRuleDuplicated(nil),
+			},
+		},
+		{
+			description: "succeeds skipping the rules duplicated within the import",
+			rules:       duplicated,
+			mode:        FirewallBulkModeSkip,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleConflicts", ctx, tenantID, duplicatedModels).Return(existing[:1], nil).Once()
+				mock.On(
+					"FirewallRuleBulkWrite",
+					ctx,
+					[]models.FirewallRule{model("", 2, "device-2")},
+					[]models.FirewallRule{},
+				).Return(int64(1), int64(0), nil).Once()
+			},
+			expected: Expected{
+				created:   1,
+				conflicts: duplicatedConflicts,
+				err:       nil,
+			},
+		},
+		{
+			description: "succeeds overwriting with the last rule duplicated within the import",
+			rules:       duplicated,
+			mode:        FirewallBulkModeOverwrite,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleConflicts", ctx, tenantID, duplicatedModels).Return(existing[:1], nil).Once()
+				mock.On(
+					"FirewallRuleBulkWrite",
+					ctx,
+					[]models.FirewallRule{duplicatedModels[2]},
+					[]models.FirewallRule{{ID: "6504b7bd9b6c4a63a9ccc053", TenantID: tenantID, FirewallRuleFields: duplicatedModels[3].FirewallRuleFields}},
+				).Return(int64(1), int64(1), nil).Once()
+			},
+			expected: Expected{
+				created:   2,
+				conflicts: duplicatedConflicts,
+				err:       nil,
+			},
+		},
+		{
+			description: "fails without writing when rules are duplicated within the import",
+			rules:       duplicated,
+			mode:        FirewallBulkModeFailOnConflict,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleConflicts", ctx, tenantID, duplicatedModels).Return(existing[:1], nil).Once()
+			},
+			expected: Expected{
+				created:   0,
+				conflicts: duplicatedConflicts,
+				err:       NewErrFirewallRuleDuplicated(nil),
+			},
+		},
+		{
+			description: "fails when cannot write the rules",
+			rules:       incoming,
+			mode:        FirewallBulkModeSkip,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleConflicts", ctx, tenantID, incomingModels).Return([]models.FirewallRule{}, nil).Once()
+				mock.On("FirewallRuleBulkWrite", ctx, incomingModels, []models.FirewallRule{}).Return(int64(0), int64(0), goerrors.New("error")).Once()
+			},
+			expected: Expected{
+				created:   0,
+				conflicts: []BulkConflict{},
+				err:       goerrors.New("error"),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			created, conflicts, err := service.BulkCreateFirewallRules(ctx, tenantID, "507f1f77bcf86cd799439011", tc.rules, tc.mode)
+			assert.Equal(t, tc.expected, Expected{created, conflicts, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestFirewallEvaluate(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	namespace := func(policy string) *models.Namespace {
+		return &models.Namespace{
+			Name:     "namespace",
+			TenantID: tenantID,
+			Settings: &models.NamespaceSettings{DefaultFirewallPolicy: policy},
+		}
+	}
+
+	rule := func(id string, priority int, action, username string, filter models.FirewallFilter) models.FirewallRule {
+		return models.FirewallRule{
+			ID:       id,
+			TenantID: tenantID,
+			FirewallRuleFields: models.FirewallRuleFields{
+				Priority: priority,
+				Action:   action,
+				Active:   true,
+				SourceIP: ".*",
+				Username: username,
+				Filter:   filter,
+			},
+		}
+	}
+
+	req := requests.FirewallEvaluate{
+		Domain:    "namespace",
+		Name:      "device",
+		Username:  "root",
+		IPAddress: "192.168.0.1",
+	}
+
+	// None of these rules match the request.
+	unmatched := []models.FirewallRule{
+		rule("unmatched-1", 1, "allow", "^admin$", models.FirewallFilter{Hostname: ".*"}),
+		rule("unmatched-2", 1, "allow", ".*", models.FirewallFilter{Hostname: "^other$"}),
+		rule("unmatched-3", 1, "allow", ".*", models.FirewallFilter{Tags: []string{"production"}}),
+	}
+
+	type Expected struct {
+		allowed bool
+		rule    *models.FirewallRule
+		err     error
+	}
+
+	ruleOf := func(r models.FirewallRule) *models.FirewallRule {
+		return &r
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when namespace is not found",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{allowed: false, rule: nil, err: NewErrNamespaceNotFound("namespace", store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when cannot list the rules",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyAllow), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(nil, goerrors.New("error")).Once()
+			},
+			expected: Expected{allowed: false, rule: nil, err: goerrors.New("error")},
+		},
+		{
+			description: "succeeds allowing with allow policy and no rules",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyAllow), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return([]models.FirewallRule{}, nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{allowed: true, rule: nil, err: nil},
+		},
+		{
+			description: "succeeds allowing with allow policy and no matching rule",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyAllow), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(slices.Clone(unmatched), nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{allowed: true, rule: nil, err: nil},
+		},
+		{
+			description: "succeeds allowing when the policy is not defined",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(""), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(slices.Clone(unmatched), nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{allowed: true, rule: nil, err: nil},
+		},
+		{
+			description: "succeeds denying with deny policy and no rules",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyDeny), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return([]models.FirewallRule{}, nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{allowed: false, rule: nil, err: nil},
+		},
+		{
+			description: "succeeds denying with deny policy and no matching rule",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyDeny), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(slices.Clone(unmatched), nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{allowed: false, rule: nil, err: nil},
+		},
+		{
+			description: "succeeds allowing with deny policy when a rule matches",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyDeny), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(
+					append(slices.Clone(unmatched), rule("allow", 1, "allow", ".*", models.FirewallFilter{Tags: []string{"staging"}})), nil,
+				).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{
+				allowed: true,
+				rule:    ruleOf(rule("allow", 1, "allow", ".*", models.FirewallFilter{Tags: []string{"staging"}})),
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds denying with allow policy when a rule matches",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyAllow), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(
+					[]models.FirewallRule{rule("deny", 1, "deny", "^root$", models.FirewallFilter{Hostname: "^device$"})}, nil,
+				).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{
+				allowed: false,
+				rule:    ruleOf(rule("deny", 1, "deny", "^root$", models.FirewallFilter{Hostname: "^device$"})),
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds deciding by the rule with the lowest priority number",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyAllow), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return([]models.FirewallRule{
+					rule("deny", 2, "deny", ".*", models.FirewallFilter{Hostname: ".*"}),
+					rule("allow", 1, "allow", "^root$", models.FirewallFilter{Hostname: "^device$"}),
+				}, nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{
+				allowed: true,
+				rule:    ruleOf(rule("allow", 1, "allow", "^root$", models.FirewallFilter{Hostname: "^device$"})),
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds deciding by the deny rule when rules with the same priority match",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyAllow), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return([]models.FirewallRule{
+					rule("a", 1, "allow", ".*", models.FirewallFilter{Hostname: ".*"}),
+					rule("b", 1, "deny", ".*", models.FirewallFilter{Tags: []string{"staging"}}),
+				}, nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{
+				allowed: false,
+				rule:    ruleOf(rule("b", 1, "deny", ".*", models.FirewallFilter{Tags: []string{"staging"}})),
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds deciding by the lowest ID when rules with the same priority and action match",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyDeny), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return([]models.FirewallRule{
+					rule("b", 1, "allow", ".*", models.FirewallFilter{Tags: []string{"staging"}}),
+					rule("a", 1, "allow", "^root$", models.FirewallFilter{Hostname: ".*"}),
+				}, nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{
+				allowed: true,
+				rule:    ruleOf(rule("a", 1, "allow", "^root$", models.FirewallFilter{Hostname: ".*"})),
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds matching a rule by any of the device's tags",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyAllow), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return([]models.FirewallRule{
+					rule("deny", 1, "deny", ".*", models.FirewallFilter{Tags: []string{"production", "database"}}),
+				}, nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging", "database"}}, nil).Once()
+			},
+			expected: Expected{
+				allowed: false,
+				rule:    ruleOf(rule("deny", 1, "deny", ".*", models.FirewallFilter{Tags: []string{"production", "database"}})),
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds skipping the rules filtered by tags when the device is not found",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyAllow), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return([]models.FirewallRule{
+					rule("deny", 1, "deny", ".*", models.FirewallFilter{Tags: []string{"staging"}}),
+				}, nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{allowed: true, rule: nil, err: nil},
+		},
+		{
+			description: "succeeds skipping the rules with invalid expressions",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(namespace(models.FirewallPolicyAllow), nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return([]models.FirewallRule{
+					rule("deny", 1, "deny", "(", models.FirewallFilter{Hostname: ".*"}),
+				}, nil).Once()
+				mock.On("DeviceLookup", ctx, "namespace", "device").Return(&models.Device{Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{allowed: true, rule: nil, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			allowed, rule, err := service.FirewallEvaluate(ctx, req)
+			assert.Equal(t, tc.expected, Expected{allowed, rule, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSortFirewallRules(t *testing.T) {
+	rule := func(id string, priority int, action string) models.FirewallRule {
+		return models.FirewallRule{ID: id, FirewallRuleFields: models.FirewallRuleFields{Priority: priority, Action: action}}
+	}
+
+	rules := []models.FirewallRule{
+		rule("c", 2, "allow"),
+		rule("b", 1, "allow"),
+		rule("d", 1, "deny"),
+		rule("a", 1, "allow"),
+		rule("e", 0, "allow"),
+	}
+
+	sortFirewallRules(rules)
+
+	assert.Equal(t, []models.FirewallRule{
+		rule("e", 0, "allow"),
+		rule("d", 1, "deny"),
+		rule("a", 1, "allow"),
+		rule("b", 1, "allow"),
+		rule("c", 2, "allow"),
+	}, rules)
+}
+
+func TestValidateFirewallRules(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	rule := func(id string, priority int, action, username string, filter models.FirewallFilter) models.FirewallRule {
+		return models.FirewallRule{
+			ID:       id,
+			TenantID: tenantID,
+			FirewallRuleFields: models.FirewallRuleFields{
+				Priority: priority,
+				Action:   action,
+				Active:   true,
+				SourceIP: ".*",
+				Username: username,
+				Filter:   filter,
+			},
+		}
+	}
+
+	type Expected struct {
+		conflicts []models.FirewallConflict
+		err       error
+	}
+
+	cases := []struct {
+		description   string
+		rules         []models.FirewallRule
+		requiredMocks func(rules []models.FirewallRule)
+		expected      Expected
+	}{
+		{
+			description: "fails when namespace is not found",
+			requiredMocks: func(_ []models.FirewallRule) {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when cannot list the rules",
+			requiredMocks: func(_ []models.FirewallRule) {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(nil, goerrors.New("error")).Once()
+			},
+			expected: Expected{err: goerrors.New("error")},
+		},
+		{
+			description: "succeeds without conflicts when the rules match different connections",
+			rules: []models.FirewallRule{
+				rule("1", 1, "allow", "^root$", models.FirewallFilter{Hostname: "^device$"}),
+				rule("2", 1, "deny", "^admin$", models.FirewallFilter{Hostname: "^device$"}),
+				rule("3", 2, "deny", "^root$", models.FirewallFilter{Hostname: "^other$"}),
+				rule("4", 3, "deny", "^root$", models.FirewallFilter{Tags: []string{"production"}}),
+			},
+			requiredMocks: func(rules []models.FirewallRule) {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+			},
+			expected: Expected{conflicts: []models.FirewallConflict{}},
+		},
+		{
+			description: "succeeds detecting duplicate rules",
+			rules: []models.FirewallRule{
+				rule("1", 1, "allow", "^root$", models.FirewallFilter{Hostname: "^device$"}),
+				rule("2", 1, "allow", "^root$", models.FirewallFilter{Hostname: "^device$"}),
+			},
+			requiredMocks: func(rules []models.FirewallRule) {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+			},
+			expected: Expected{
+				conflicts: []models.FirewallConflict{
+					{Rule1UID: "1", Rule2UID: "2", ConflictType: models.FirewallConflictDuplicate},
+				},
+			},
+		},
+		{
+			description: "succeeds detecting contradictory rules",
+			rules: []models.FirewallRule{
+				rule("1", 1, "allow", ".*", models.FirewallFilter{Tags: []string{"production", "staging"}}),
+				rule("2", 1, "deny", "^root$", models.FirewallFilter{Tags: []string{"staging"}}),
+			},
+			requiredMocks: func(rules []models.FirewallRule) {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+			},
+			expected: Expected{
+				conflicts: []models.FirewallConflict{
+					{Rule1UID: "1", Rule2UID: "2", ConflictType: models.FirewallConflictContradictory},
+				},
+			},
+		},
+		{
+			description: "succeeds detecting shadowed rules",
+			rules: []models.FirewallRule{
+				rule("1", 1, "deny", ".*", models.FirewallFilter{Hostname: ".*"}),
+				rule("2", 2, "allow", "^root$", models.FirewallFilter{Hostname: "^device$"}),
+				rule("3", 3, "allow", "^root$", models.FirewallFilter{Tags: []string{"production"}}),
+			},
+			requiredMocks: func(rules []models.FirewallRule) {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+			},
+			expected: Expected{
+				conflicts: []models.FirewallConflict{
+					{Rule1UID: "1", Rule2UID: "2", ConflictType: models.FirewallConflictShadowed},
+					{Rule1UID: "1", Rule2UID: "3", ConflictType: models.FirewallConflictShadowed},
+				},
+			},
+		},
+		{
+			description: "succeeds not shadowing a rule restricted to a device group by one restricted to another",
+			rules: []models.FirewallRule{
+				func() models.FirewallRule {
+					r := rule("1", 1, "deny", ".*", models.FirewallFilter{Hostname: ".*"})
+					r.DeviceGroupUID = "group"
+
+					return r
+				}(),
+				func() models.FirewallRule {
+					r := rule("2", 2, "allow", ".*", models.FirewallFilter{Hostname: ".*"})
+					r.DeviceGroupUID = "other"
+
+					return r
+				}(),
+			},
+			requiredMocks: func(rules []models.FirewallRule) {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("FirewallRuleListActive", ctx, tenantID).Return(rules, nil).Once()
+			},
+			expected: Expected{conflicts: []models.FirewallConflict{}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks(tc.rules)
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			conflicts, err := service.ValidateFirewallRules(ctx, tenantID)
+			assert.Equal(t, tc.expected, Expected{conflicts, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestPreviewFirewallRule(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	namespace := &models.Namespace{TenantID: tenantID}
+
+	devices := []models.Device{
+		{UID: "uid-1", Name: "prod-1", Tags: []string{"production"}},
+		{UID: "uid-2", Name: "prod-2"},
+		{UID: "uid-3", Name: "staging-1", Tags: []string{"production"}},
+	}
+
+	rule := func(username string, filter models.FirewallFilter, group string) models.FirewallRuleFields {
+		return models.FirewallRuleFields{
+			Priority:       1,
+			Action:         "deny",
+			Active:         true,
+			SourceIP:       "^192\\.168\\.",
+			Username:       username,
+			Filter:         filter,
+			DeviceGroupUID: group,
+		}
+	}
+
+	type Expected struct {
+		preview *models.FirewallRulePreview
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		rule          models.FirewallRuleFields
+		connection    models.FirewallPreviewConnection
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when namespace is not found",
+			rule:        rule(".*", models.FirewallFilter{Hostname: ".*"}, ""),
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the device group is not found",
+			rule:        rule(".*", models.FirewallFilter{Hostname: ".*"}, "group"),
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace, nil).Once()
+				mock.On("DeviceGroupGet", ctx, tenantID, "group").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrFirewallRuleInvalid(nil, NewErrDeviceGroupNotFound("group", store.ErrNoDocuments))},
+		},
+		{
+			description: "fails when cannot list the devices",
+			rule:        rule(".*", models.FirewallFilter{Hostname: ".*"}, ""),
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace, nil).Once()
+				mock.On("DeviceListByStatus", ctx, tenantID, models.DeviceStatusAccepted).Return(nil, goerrors.New("error")).Once()
+			},
+			expected: Expected{err: goerrors.New("error")},
+		},
+		{
+			description: "succeeds matching the devices by hostname for the connection's login and address",
+			rule:        rule("^root$", models.FirewallFilter{Hostname: "^prod-"}, ""),
+			connection:  models.FirewallPreviewConnection{Username: "root", IPAddress: "192.168.1.1"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace, nil).Once()
+				mock.On("DeviceListByStatus", ctx, tenantID, models.DeviceStatusAccepted).Return(devices, nil).Once()
+			},
+			expected: Expected{
+				preview: &models.FirewallRulePreview{
+					Action: "deny",
+					Devices: []models.FirewallPreviewDevice{
+						{UID: "uid-1", Name: "prod-1"},
+						{UID: "uid-2", Name: "prod-2"},
+					},
+				},
+			},
+		},
+		{
+			description: "succeeds matching no device when the connection's login doesn't match",
+			rule:        rule("^root$", models.FirewallFilter{Hostname: "^prod-"}, ""),
+			connection:  models.FirewallPreviewConnection{Username: "john_doe", IPAddress: "192.168.1.1"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace, nil).Once()
+				mock.On("DeviceListByStatus", ctx, tenantID, models.DeviceStatusAccepted).Return(devices, nil).Once()
+			},
+			expected: Expected{
+				preview: &models.FirewallRulePreview{Action: "deny", Devices: []models.FirewallPreviewDevice{}},
+			},
+		},
+		{
+			description: "succeeds matching no device when the connection's address doesn't match",
+			rule:        rule("^root$", models.FirewallFilter{Hostname: "^prod-"}, ""),
+			connection:  models.FirewallPreviewConnection{Username: "root", IPAddress: "10.0.0.1"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace, nil).Once()
+				mock.On("DeviceListByStatus", ctx, tenantID, models.DeviceStatusAccepted).Return(devices, nil).Once()
+			},
+			expected: Expected{
+				preview: &models.FirewallRulePreview{Action: "deny", Devices: []models.FirewallPreviewDevice{}},
+			},
+		},
+		{
+			description: "succeeds matching the devices by tags restricted to the device group",
+			rule:        rule(".*", models.FirewallFilter{Tags: []string{"production"}}, "group"),
+			connection:  models.FirewallPreviewConnection{Username: "john_doe", IPAddress: "192.168.1.1"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace, nil).Once()
+				mock.On("DeviceGroupGet", ctx, tenantID, "group").Return(&models.DeviceGroup{UID: "group", Devices: []string{"uid-3"}}, nil).Once()
+				mock.On("DeviceListByStatus", ctx, tenantID, models.DeviceStatusAccepted).Return(devices, nil).Once()
+			},
+			expected: Expected{
+				preview: &models.FirewallRulePreview{
+					Action:  "deny",
+					Devices: []models.FirewallPreviewDevice{{UID: "uid-3", Name: "staging-1"}},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			preview, err := service.PreviewFirewallRule(ctx, tenantID, tc.rule, tc.connection)
+			assert.Equal(t, tc.expected, Expected{preview, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
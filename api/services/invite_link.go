@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+type InviteLinkService interface {
+	// CreateInviteLink creates a link that invites anyone holding it to the namespace with the specified tenant ID,
+	// with the specified role, at most maxUses times until expiresIn has elapsed. The actor must be a member with
+	// authority over the role.
+	CreateInviteLink(ctx context.Context, tenantID, actorID, role string, maxUses int, expiresIn time.Duration) (*models.InviteLink, error)
+
+	// ListInviteLinks lists the invite links of the namespace with the specified tenant ID.
+	ListInviteLinks(ctx context.Context, tenantID string) ([]models.InviteLink, error)
+
+	// DeleteInviteLink deletes the invite link with the specified token from the namespace with the specified tenant
+	// ID, so it can no longer be used.
+	DeleteInviteLink(ctx context.Context, tenantID, token string) error
+
+	// GetInviteLink retrieves the namespace an invite link invites to, along with the role given on joining it. It
+	// fails when the link can no longer be used.
+	GetInviteLink(ctx context.Context, token string) (*responses.InviteLink, error)
+
+	// JoinInviteLink adds the user with the specified ID to the namespace of an invite link, with the link's role.
+	// Each join consumes one of the link's uses.
+	JoinInviteLink(ctx context.Context, token, userID string) (*models.Namespace, error)
+}
+
+func (s *service) CreateInviteLink(ctx context.Context, tenantID, actorID, role string, maxUses int, expiresIn time.Duration) (*models.InviteLink, error) {
+	if maxUses < 1 || expiresIn <= 0 {
+		return nil, NewErrInviteLinkInvalid(map[string]interface{}{"max_uses": maxUses, "expires_in": expiresIn.String()}, nil)
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	actor, ok := namespace.FindMember(actorID)
+	if !ok {
+		return nil, NewErrNamespaceMemberNotFound(actorID, nil)
+	}
+
+	if !guard.HasAuthority(actor.Role, role) {
+		return nil, guard.ErrForbidden
+	}
+
+	link := &models.InviteLink{
+		Token:     uuid.Generate(),
+		TenantID:  tenantID,
+		Role:      role,
+		MaxUses:   maxUses,
+		CreatedBy: actorID,
+		ExpiresAt: clock.Now().Add(expiresIn),
+	}
+
+	if err := s.store.InviteLinkCreate(ctx, link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (s *service) ListInviteLinks(ctx context.Context, tenantID string) ([]models.InviteLink, error) {
+	return s.store.InviteLinkList(ctx, tenantID)
+}
+
+func (s *service) DeleteInviteLink(ctx context.Context, tenantID, token string) error {
+	if err := s.store.InviteLinkDelete(ctx, tenantID, token); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrInviteLinkNotFound(token, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// usableInviteLink retrieves the invite link with the specified token, failing when it can no longer be used.
+func (s *service) usableInviteLink(ctx context.Context, token string) (*models.InviteLink, error) {
+	link, err := s.store.InviteLinkGet(ctx, token)
+	if err != nil {
+		return nil, NewErrInviteLinkNotFound(token, err)
+	}
+
+	if link.IsExpired(clock.Now()) {
+		return nil, NewErrInviteLinkExpired(nil)
+	}
+
+	if link.IsExhausted() {
+		return nil, NewErrInviteLinkExhausted(link.MaxUses, nil)
+	}
+
+	return link, nil
+}
+
+func (s *service) GetInviteLink(ctx context.Context, token string) (*responses.InviteLink, error) {
+	link, err := s.usableInviteLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, link.TenantID, false)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(link.TenantID, err)
+	}
+
+	return responses.InviteLinkFromModel(link, namespace), nil
+}
+
+func (s *service) JoinInviteLink(ctx context.Context, token, userID string) (*models.Namespace, error) {
+	link, err := s.usableInviteLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, _, err := s.store.UserGetByID(ctx, userID, false)
+	if err != nil {
+		return nil, NewErrUserNotFound(userID, err)
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, link.TenantID, false)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(link.TenantID, err)
+	}
+
+	if _, ok := namespace.FindMember(user.ID); ok {
+		return nil, NewErrNamespaceMemberDuplicated(user.ID, nil)
+	}
+
+	if err := s.checkNamespaceMembersLimit(ctx, namespace); err != nil {
+		return nil, err
+	}
+
+	// NOTICE: the link was checked above, but other users may have used it since. Consuming the use is what actually
+	// enforces the maximum uses, as it is atomic.
+	if _, err := s.store.InviteLinkUse(ctx, token, clock.Now()); err != nil {
+		if err == store.ErrNoDocuments {
+			return nil, NewErrInviteLinkExhausted(link.MaxUses, err)
+		}
+
+		return nil, err
+	}
+
+	namespace, err = s.store.NamespaceAddMember(ctx, link.TenantID, user.ID, link.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).
+		WithFields(log.Fields{"tenant_id": link.TenantID, "user_id": user.ID, "role": link.Role}).
+		Info("user joined the namespace through an invite link")
+
+	s.emitWebhookEvent(ctx, link.TenantID, models.WebhookEventMemberAdded, map[string]string{"id": user.ID, "role": link.Role})
+
+	return namespace, nil
+}
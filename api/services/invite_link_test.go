@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmock "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/uuid"
+	uuidmock "github.com/shellhub-io/shellhub/pkg/uuid/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateInviteLink(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	namespace := &models.Namespace{
+		TenantID: tenantID,
+		Members: []models.Member{
+			{ID: "owner", Role: guard.RoleOwner},
+			{ID: "operator", Role: guard.RoleOperator},
+		},
+	}
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	uuidMock := new(uuidmock.Uuid)
+	uuid.DefaultBackend = uuidMock
+	uuidMock.On("Generate").Return("token")
+
+	type Expected struct {
+		link *models.InviteLink
+		err  error
+	}
+
+	cases := []struct {
+		description   string
+		actorID       string
+		role          string
+		maxUses       int
+		expiresIn     time.Duration
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description:   "fails when the link can never be used",
+			actorID:       "owner",
+			role:          guard.RoleObserver,
+			maxUses:       0,
+			expiresIn:     time.Hour,
+			requiredMocks: func() {},
+			expected: Expected{
+				err: NewErrInviteLinkInvalid(map[string]interface{}{"max_uses": 0, "expires_in": time.Hour.String()}, nil),
+			},
+		},
+		{
+			description: "fails when the namespace is not found",
+			actorID:     "owner",
+			role:        guard.RoleObserver,
+			maxUses:     5,
+			expiresIn:   time.Hour,
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the actor is not a member",
+			actorID:     "stranger",
+			role:        guard.RoleObserver,
+			maxUses:     5,
+			expiresIn:   time.Hour,
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(namespace, nil).Once()
+			},
+			expected: Expected{err: NewErrNamespaceMemberNotFound("stranger", nil)},
+		},
+		{
+			description: "fails when the actor has no authority over the role",
+			actorID:     "operator",
+			role:        guard.RoleAdministrator,
+			maxUses:     5,
+			expiresIn:   time.Hour,
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(namespace, nil).Once()
+			},
+			expected: Expected{err: guard.ErrForbidden},
+		},
+		{
+			description: "succeeds creating the link",
+			actorID:     "owner",
+			role:        guard.RoleObserver,
+			maxUses:     5,
+			expiresIn:   time.Hour,
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(namespace, nil).Once()
+				storeMock.
+					On("InviteLinkCreate", ctx, &models.InviteLink{
+						Token:     "token",
+						TenantID:  tenantID,
+						Role:      guard.RoleObserver,
+						MaxUses:   5,
+						CreatedBy: "owner",
+						ExpiresAt: now.Add(time.Hour),
+					}).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{
+				link: &models.InviteLink{
+					Token:     "token",
+					TenantID:  tenantID,
+					Role:      guard.RoleObserver,
+					MaxUses:   5,
+					CreatedBy: "owner",
+					ExpiresAt: now.Add(time.Hour),
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			link, err := service.CreateInviteLink(ctx, tenantID, tc.actorID, tc.role, tc.maxUses, tc.expiresIn)
+			assert.Equal(t, tc.expected, Expected{link, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestGetInviteLink(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	type Expected struct {
+		link *responses.InviteLink
+		err  error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the link is not found",
+			requiredMocks: func() {
+				storeMock.On("InviteLinkGet", ctx, "token").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrInviteLinkNotFound("token", store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the link is expired",
+			requiredMocks: func() {
+				storeMock.
+					On("InviteLinkGet", ctx, "token").
+					Return(&models.InviteLink{Token: "token", TenantID: tenantID, MaxUses: 5, ExpiresAt: now}, nil).
+					Once()
+			},
+			expected: Expected{err: NewErrInviteLinkExpired(nil)},
+		},
+		{
+			description: "fails when the link is exhausted",
+			requiredMocks: func() {
+				storeMock.
+					On("InviteLinkGet", ctx, "token").
+					Return(&models.InviteLink{Token: "token", TenantID: tenantID, MaxUses: 5, UseCount: 5, ExpiresAt: now.Add(time.Hour)}, nil).
+					Once()
+			},
+			expected: Expected{err: NewErrInviteLinkExhausted(5, nil)},
+		},
+		{
+			description: "succeeds getting the namespace of the link",
+			requiredMocks: func() {
+				storeMock.
+					On("InviteLinkGet", ctx, "token").
+					Return(&models.InviteLink{Token: "token", TenantID: tenantID, Role: guard.RoleObserver, MaxUses: 5, ExpiresAt: now.Add(time.Hour)}, nil).
+					Once()
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID, Name: "dev"}, nil).Once()
+			},
+			expected: Expected{
+				link: &responses.InviteLink{TenantID: tenantID, Namespace: "dev", Role: guard.RoleObserver, ExpiresAt: now.Add(time.Hour)},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			link, err := service.GetInviteLink(ctx, "token")
+			assert.Equal(t, tc.expected, Expected{link, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestJoinInviteLink(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	link := &models.InviteLink{Token: "token", TenantID: tenantID, Role: guard.RoleObserver, MaxUses: 1, ExpiresAt: now.Add(time.Hour)}
+	user := &models.User{ID: "user"}
+	owner := &models.User{ID: "owner", PlanID: models.PlanFree}
+
+	type Expected struct {
+		namespace *models.Namespace
+		err       error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the link is not found",
+			requiredMocks: func() {
+				storeMock.On("InviteLinkGet", ctx, "token").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrInviteLinkNotFound("token", store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the user is not found",
+			requiredMocks: func() {
+				storeMock.On("InviteLinkGet", ctx, "token").Return(link, nil).Once()
+				storeMock.On("UserGetByID", ctx, "user", false).Return(nil, 0, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrUserNotFound("user", store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the user is already a member",
+			requiredMocks: func() {
+				storeMock.On("InviteLinkGet", ctx, "token").Return(link, nil).Once()
+				storeMock.On("UserGetByID", ctx, "user", false).Return(user, 0, nil).Once()
+				storeMock.
+					On("NamespaceGet", ctx, tenantID, false).
+					Return(&models.Namespace{TenantID: tenantID, Members: []models.Member{{ID: "user", Role: guard.RoleOperator}}}, nil).
+					Once()
+			},
+			expected: Expected{err: NewErrNamespaceMemberDuplicated("user", nil)},
+		},
+		{
+			description: "fails when the namespace reached the members limit of its owner's plan",
+			requiredMocks: func() {
+				members := make([]models.Member, 3)
+				storeMock.On("InviteLinkGet", ctx, "token").Return(link, nil).Once()
+				storeMock.On("UserGetByID", ctx, "user", false).Return(user, 0, nil).Once()
+				storeMock.
+					On("NamespaceGet", ctx, tenantID, false).
+					Return(&models.Namespace{TenantID: tenantID, Owner: "owner", Members: members}, nil).
+					Once()
+				storeMock.On("UserGetByID", ctx, "owner", false).Return(owner, 0, nil).Once()
+			},
+			expected: Expected{err: NewErrNamespaceMembersLimitReached(3, nil)},
+		},
+		{
+			description: "fails when the link was exhausted by another user meanwhile",
+			requiredMocks: func() {
+				storeMock.On("InviteLinkGet", ctx, "token").Return(link, nil).Once()
+				storeMock.On("UserGetByID", ctx, "user", false).Return(user, 0, nil).Once()
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID, Owner: "owner"}, nil).Once()
+				storeMock.On("UserGetByID", ctx, "owner", false).Return(owner, 0, nil).Once()
+				storeMock.On("InviteLinkUse", ctx, "token", now).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrInviteLinkExhausted(1, store.ErrNoDocuments)},
+		},
+		{
+			description: "succeeds joining the namespace with the link's role",
+			requiredMocks: func() {
+				storeMock.On("InviteLinkGet", ctx, "token").Return(link, nil).Once()
+				storeMock.On("UserGetByID", ctx, "user", false).Return(user, 0, nil).Once()
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID, Owner: "owner"}, nil).Once()
+				storeMock.On("UserGetByID", ctx, "owner", false).Return(owner, 0, nil).Once()
+				storeMock.On("InviteLinkUse", ctx, "token", now).Return(&models.InviteLink{UseCount: 1}, nil).Once()
+				storeMock.
+					On("NamespaceAddMember", ctx, tenantID, "user", guard.RoleObserver).
+					Return(&models.Namespace{TenantID: tenantID, Members: []models.Member{{ID: "user", Role: guard.RoleObserver}}}, nil).
+					Once()
+			},
+			expected: Expected{
+				namespace: &models.Namespace{TenantID: tenantID, Members: []models.Member{{ID: "user", Role: guard.RoleObserver}}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			namespace, err := service.JoinInviteLink(ctx, "token", "user")
+			assert.Equal(t, tc.expected, Expected{namespace, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestDeleteInviteLink(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when the link is not found",
+			requiredMocks: func() {
+				storeMock.On("InviteLinkDelete", ctx, tenantID, "token").Return(store.ErrNoDocuments).Once()
+			},
+			expected: NewErrInviteLinkNotFound("token", store.ErrNoDocuments),
+		},
+		{
+			description: "succeeds deleting the link",
+			requiredMocks: func() {
+				storeMock.On("InviteLinkDelete", ctx, tenantID, "token").Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			assert.Equal(t, tc.expected, service.DeleteInviteLink(ctx, tenantID, "token"))
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
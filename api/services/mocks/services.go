@@ -4,8 +4,12 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 
 	internalclient "github.com/shellhub-io/shellhub/pkg/api/internalclient"
+
+	mailer "github.com/shellhub-io/shellhub/api/pkg/mailer"
+
 	mock "github.com/stretchr/testify/mock"
 
 	models "github.com/shellhub-io/shellhub/pkg/models"
@@ -18,7 +22,11 @@ import (
 
 	rsa "crypto/rsa"
 
+	services "github.com/shellhub-io/shellhub/api/services"
+
 	template "text/template"
+
+	time "time"
 )
 
 // Service is an autogenerated mock type for the Service type
@@ -26,6 +34,32 @@ type Service struct {
 	mock.Mock
 }
 
+// AddDeviceToGroup provides a mock function with given fields: ctx, tenantID, req
+func (_m *Service) AddDeviceToGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupDevice) (*models.DeviceGroup, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	var r0 *models.DeviceGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *requests.DeviceGroupDevice) (*models.DeviceGroup, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *requests.DeviceGroupDevice) *models.DeviceGroup); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeviceGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *requests.DeviceGroupDevice) error); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddNamespaceUser provides a mock function with given fields: ctx, memberUsername, memberRole, tenantID, userID
 func (_m *Service) AddNamespaceUser(ctx context.Context, memberUsername string, memberRole string, tenantID string, userID string) (*models.Namespace, error) {
 	ret := _m.Called(ctx, memberUsername, memberRole, tenantID, userID)
@@ -66,6 +100,46 @@ func (_m *Service) AddPublicKeyTag(ctx context.Context, tenant string, fingerpri
 	return r0
 }
 
+// AnalyzeRecording provides a mock function with given fields: ctx, uid
+func (_m *Service) AnalyzeRecording(ctx context.Context, uid string) (*models.RecordingAnalysis, error) {
+	ret := _m.Called(ctx, uid)
+
+	var r0 *models.RecordingAnalysis
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.RecordingAnalysis, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.RecordingAnalysis); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RecordingAnalysis)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AssignPlan provides a mock function with given fields: ctx, userID, planID
+func (_m *Service) AssignPlan(ctx context.Context, userID string, planID string) error {
+	ret := _m.Called(ctx, userID, planID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, planID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AuthAPIKey provides a mock function with given fields: ctx, key
 func (_m *Service) AuthAPIKey(ctx context.Context, key string) (*models.APIKey, error) {
 	ret := _m.Called(ctx, key)
@@ -132,25 +206,25 @@ func (_m *Service) AuthDevice(ctx context.Context, req requests.DeviceAuth, remo
 	return r0, r1
 }
 
-// AuthGetToken provides a mock function with given fields: ctx, id
-func (_m *Service) AuthGetToken(ctx context.Context, id string) (*models.UserAuthResponse, error) {
-	ret := _m.Called(ctx, id)
+// AuthGetToken provides a mock function with given fields: ctx, id, sourceIP, userAgent
+func (_m *Service) AuthGetToken(ctx context.Context, id string, sourceIP string, userAgent string) (*models.UserAuthResponse, error) {
+	ret := _m.Called(ctx, id, sourceIP, userAgent)
 
 	var r0 *models.UserAuthResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.UserAuthResponse, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*models.UserAuthResponse, error)); ok {
+		return rf(ctx, id, sourceIP, userAgent)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *models.UserAuthResponse); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *models.UserAuthResponse); ok {
+		r0 = rf(ctx, id, sourceIP, userAgent)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.UserAuthResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, id, sourceIP, userAgent)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -208,25 +282,25 @@ func (_m *Service) AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth
 	return r0, r1
 }
 
-// AuthSwapToken provides a mock function with given fields: ctx, ID, tenant
-func (_m *Service) AuthSwapToken(ctx context.Context, ID string, tenant string) (*models.UserAuthResponse, error) {
-	ret := _m.Called(ctx, ID, tenant)
+// AuthSwapToken provides a mock function with given fields: ctx, ID, tenant, session
+func (_m *Service) AuthSwapToken(ctx context.Context, ID string, tenant string, session string) (*models.UserAuthResponse, error) {
+	ret := _m.Called(ctx, ID, tenant, session)
 
 	var r0 *models.UserAuthResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.UserAuthResponse, error)); ok {
-		return rf(ctx, ID, tenant)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*models.UserAuthResponse, error)); ok {
+		return rf(ctx, ID, tenant, session)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.UserAuthResponse); ok {
-		r0 = rf(ctx, ID, tenant)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *models.UserAuthResponse); ok {
+		r0 = rf(ctx, ID, tenant, session)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.UserAuthResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, ID, tenant)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, ID, tenant, session)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -288,6 +362,30 @@ func (_m *Service) AuthUser(ctx context.Context, req *requests.UserAuth, sourceI
 	return r0, r1, r2, r3
 }
 
+// AuthUserActive provides a mock function with given fields: ctx, userID
+func (_m *Service) AuthUserActive(ctx context.Context, userID string) (bool, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AuthUserInfo provides a mock function with given fields: ctx, username, tenant, token
 func (_m *Service) AuthUserInfo(ctx context.Context, username string, tenant string, token string) (*models.UserAuthResponse, error) {
 	ret := _m.Called(ctx, username, tenant, token)
@@ -314,6 +412,30 @@ func (_m *Service) AuthUserInfo(ctx context.Context, username string, tenant str
 	return r0, r1
 }
 
+// AuthUserSession provides a mock function with given fields: ctx, userID, sessionID
+func (_m *Service) AuthUserSession(ctx context.Context, userID string, sessionID string) (bool, error) {
+	ret := _m.Called(ctx, userID, sessionID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, userID, sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, userID, sessionID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BillingEvaluate provides a mock function with given fields: _a0, _a1
 func (_m *Service) BillingEvaluate(_a0 internalclient.Client, _a1 string) (bool, error) {
 	ret := _m.Called(_a0, _a1)
@@ -352,6 +474,145 @@ func (_m *Service) BillingReport(_a0 internalclient.Client, _a1 string, _a2 stri
 	return r0
 }
 
+// BulkAcceptDevices provides a mock function with given fields: ctx, tenantID, uids
+func (_m *Service) BulkAcceptDevices(ctx context.Context, tenantID string, uids []models.UID) ([]responses.DeviceBulkResult, error) {
+	ret := _m.Called(ctx, tenantID, uids)
+
+	var r0 []responses.DeviceBulkResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []models.UID) ([]responses.DeviceBulkResult, error)); ok {
+		return rf(ctx, tenantID, uids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []models.UID) []responses.DeviceBulkResult); ok {
+		r0 = rf(ctx, tenantID, uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]responses.DeviceBulkResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []models.UID) error); ok {
+		r1 = rf(ctx, tenantID, uids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BulkCreateFirewallRules provides a mock function with given fields: ctx, tenantID, actorID, rules, mode
+func (_m *Service) BulkCreateFirewallRules(ctx context.Context, tenantID string, actorID string, rules []requests.FirewallRuleCreate, mode string) (int, []services.BulkConflict, error) {
+	ret := _m.Called(ctx, tenantID, actorID, rules, mode)
+
+	var r0 int
+	var r1 []services.BulkConflict
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []requests.FirewallRuleCreate, string) (int, []services.BulkConflict, error)); ok {
+		return rf(ctx, tenantID, actorID, rules, mode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []requests.FirewallRuleCreate, string) int); ok {
+		r0 = rf(ctx, tenantID, actorID, rules, mode)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []requests.FirewallRuleCreate, string) []services.BulkConflict); ok {
+		r1 = rf(ctx, tenantID, actorID, rules, mode)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]services.BulkConflict)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, []requests.FirewallRuleCreate, string) error); ok {
+		r2 = rf(ctx, tenantID, actorID, rules, mode)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// BulkRejectDevices provides a mock function with given fields: ctx, tenantID, uids
+func (_m *Service) BulkRejectDevices(ctx context.Context, tenantID string, uids []models.UID) ([]responses.DeviceBulkResult, error) {
+	ret := _m.Called(ctx, tenantID, uids)
+
+	var r0 []responses.DeviceBulkResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []models.UID) ([]responses.DeviceBulkResult, error)); ok {
+		return rf(ctx, tenantID, uids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []models.UID) []responses.DeviceBulkResult); ok {
+		r0 = rf(ctx, tenantID, uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]responses.DeviceBulkResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []models.UID) error); ok {
+		r1 = rf(ctx, tenantID, uids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CheckDeviceExpiry provides a mock function with given fields: ctx, uid
+func (_m *Service) CheckDeviceExpiry(ctx context.Context, uid models.UID) error {
+	ret := _m.Called(ctx, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) error); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CleanupConnectorDevices provides a mock function with given fields: ctx, tenant, dryRun
+func (_m *Service) CleanupConnectorDevices(ctx context.Context, tenant string, dryRun bool) ([]models.Device, error) {
+	ret := _m.Called(ctx, tenant, dryRun)
+
+	var r0 []models.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) ([]models.Device, error)); ok {
+		return rf(ctx, tenant, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) []models.Device); ok {
+		r0 = rf(ctx, tenant, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, tenant, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompleteOnboarding provides a mock function with given fields: ctx, userID
+func (_m *Service) CompleteOnboarding(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateAPIKey provides a mock function with given fields: ctx, req
 func (_m *Service) CreateAPIKey(ctx context.Context, req *requests.CreateAPIKey) (*responses.CreateAPIKey, error) {
 	ret := _m.Called(ctx, req)
@@ -378,6 +639,32 @@ func (_m *Service) CreateAPIKey(ctx context.Context, req *requests.CreateAPIKey)
 	return r0, r1
 }
 
+// CreateDeviceGroup provides a mock function with given fields: ctx, tenantID, req
+func (_m *Service) CreateDeviceGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupCreate) (*models.DeviceGroup, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	var r0 *models.DeviceGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *requests.DeviceGroupCreate) (*models.DeviceGroup, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *requests.DeviceGroupCreate) *models.DeviceGroup); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeviceGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *requests.DeviceGroupCreate) error); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateDeviceTag provides a mock function with given fields: ctx, uid, tag
 func (_m *Service) CreateDeviceTag(ctx context.Context, uid models.UID, tag string) error {
 	ret := _m.Called(ctx, uid, tag)
@@ -392,6 +679,32 @@ func (_m *Service) CreateDeviceTag(ctx context.Context, uid models.UID, tag stri
 	return r0
 }
 
+// CreateInviteLink provides a mock function with given fields: ctx, tenantID, actorID, role, maxUses, expiresIn
+func (_m *Service) CreateInviteLink(ctx context.Context, tenantID string, actorID string, role string, maxUses int, expiresIn time.Duration) (*models.InviteLink, error) {
+	ret := _m.Called(ctx, tenantID, actorID, role, maxUses, expiresIn)
+
+	var r0 *models.InviteLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int, time.Duration) (*models.InviteLink, error)); ok {
+		return rf(ctx, tenantID, actorID, role, maxUses, expiresIn)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, int, time.Duration) *models.InviteLink); ok {
+		r0 = rf(ctx, tenantID, actorID, role, maxUses, expiresIn)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.InviteLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, int, time.Duration) error); ok {
+		r1 = rf(ctx, tenantID, actorID, role, maxUses, expiresIn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateNamespace provides a mock function with given fields: ctx, namespace, userID
 func (_m *Service) CreateNamespace(ctx context.Context, namespace requests.NamespaceCreate, userID string) (*models.Namespace, error) {
 	ret := _m.Called(ctx, namespace, userID)
@@ -538,8 +851,36 @@ func (_m *Service) DeleteDevice(ctx context.Context, uid models.UID, tenant stri
 	return r0
 }
 
-// DeleteNamespace provides a mock function with given fields: ctx, tenantID
-func (_m *Service) DeleteNamespace(ctx context.Context, tenantID string) error {
+// DeleteInviteLink provides a mock function with given fields: ctx, tenantID, token
+func (_m *Service) DeleteInviteLink(ctx context.Context, tenantID string, token string) error {
+	ret := _m.Called(ctx, tenantID, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteNamespace provides a mock function with given fields: ctx, tenantID, confirmationToken, confirmationText
+func (_m *Service) DeleteNamespace(ctx context.Context, tenantID string, confirmationToken string, confirmationText string) error {
+	ret := _m.Called(ctx, tenantID, confirmationToken, confirmationText)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, tenantID, confirmationToken, confirmationText)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteNamespaceWebhook provides a mock function with given fields: ctx, tenantID
+func (_m *Service) DeleteNamespaceWebhook(ctx context.Context, tenantID string) error {
 	ret := _m.Called(ctx, tenantID)
 
 	var r0 error
@@ -580,6 +921,20 @@ func (_m *Service) DeleteTag(ctx context.Context, tenant string, tag string) err
 	return r0
 }
 
+// DeleteUser provides a mock function with given fields: ctx, userID, requesterID, gdpr
+func (_m *Service) DeleteUser(ctx context.Context, userID string, requesterID string, gdpr bool) error {
+	ret := _m.Called(ctx, userID, requesterID, gdpr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool) error); ok {
+		r0 = rf(ctx, userID, requesterID, gdpr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // EditNamespace provides a mock function with given fields: ctx, req
 func (_m *Service) EditNamespace(ctx context.Context, req *requests.NamespaceEdit) (*models.Namespace, error) {
 	ret := _m.Called(ctx, req)
@@ -634,24 +989,50 @@ func (_m *Service) EditSessionRecordStatus(ctx context.Context, sessionRecord bo
 	return r0
 }
 
-// EvaluateKeyFilter provides a mock function with given fields: ctx, key, dev
-func (_m *Service) EvaluateKeyFilter(ctx context.Context, key *models.PublicKey, dev models.Device) (bool, error) {
-	ret := _m.Called(ctx, key, dev)
+// EvaluateAPIRateLimit provides a mock function with given fields: ctx, tenantID
+func (_m *Service) EvaluateAPIRateLimit(ctx context.Context, tenantID string) (*models.APIRateLimitStatus, error) {
+	ret := _m.Called(ctx, tenantID)
 
-	var r0 bool
+	var r0 *models.APIRateLimitStatus
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicKey, models.Device) (bool, error)); ok {
-		return rf(ctx, key, dev)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.APIRateLimitStatus, error)); ok {
+		return rf(ctx, tenantID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicKey, models.Device) bool); ok {
-		r0 = rf(ctx, key, dev)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.APIRateLimitStatus); ok {
+		r0 = rf(ctx, tenantID)
 	} else {
-		r0 = ret.Get(0).(bool)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.APIRateLimitStatus)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *models.PublicKey, models.Device) error); ok {
-		r1 = rf(ctx, key, dev)
-	} else {
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EvaluateKeyFilter provides a mock function with given fields: ctx, key, dev
+func (_m *Service) EvaluateKeyFilter(ctx context.Context, key *models.PublicKey, dev models.Device) (bool, error) {
+	ret := _m.Called(ctx, key, dev)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicKey, models.Device) (bool, error)); ok {
+		return rf(ctx, key, dev)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicKey, models.Device) bool); ok {
+		r0 = rf(ctx, key, dev)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.PublicKey, models.Device) error); ok {
+		r1 = rf(ctx, key, dev)
+	} else {
 		r1 = ret.Error(1)
 	}
 
@@ -682,6 +1063,81 @@ func (_m *Service) EvaluateKeyUsername(ctx context.Context, key *models.PublicKe
 	return r0, r1
 }
 
+// ExpireDevice provides a mock function with given fields: ctx, tenantID, uid
+func (_m *Service) ExpireDevice(ctx context.Context, tenantID string, uid models.UID) error {
+	ret := _m.Called(ctx, tenantID, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID) error); ok {
+		r0 = rf(ctx, tenantID, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExpireNamespace provides a mock function with given fields: ctx, tenantID
+func (_m *Service) ExpireNamespace(ctx context.Context, tenantID string) error {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExportNamespaceMembers provides a mock function with given fields: ctx, tenantID, w
+func (_m *Service) ExportNamespaceMembers(ctx context.Context, tenantID string, w io.Writer) error {
+	ret := _m.Called(ctx, tenantID, w)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.Writer) error); ok {
+		r0 = rf(ctx, tenantID, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FirewallEvaluate provides a mock function with given fields: ctx, req
+func (_m *Service) FirewallEvaluate(ctx context.Context, req requests.FirewallEvaluate) (bool, *models.FirewallRule, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 bool
+	var r1 *models.FirewallRule
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, requests.FirewallEvaluate) (bool, *models.FirewallRule, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, requests.FirewallEvaluate) bool); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, requests.FirewallEvaluate) *models.FirewallRule); ok {
+		r1 = rf(ctx, req)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.FirewallRule)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, requests.FirewallEvaluate) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetDevice provides a mock function with given fields: ctx, uid
 func (_m *Service) GetDevice(ctx context.Context, uid models.UID) (*models.Device, error) {
 	ret := _m.Called(ctx, uid)
@@ -734,6 +1190,58 @@ func (_m *Service) GetDeviceByPublicURLAddress(ctx context.Context, address stri
 	return r0, r1
 }
 
+// GetDeviceGroup provides a mock function with given fields: ctx, tenantID, uid
+func (_m *Service) GetDeviceGroup(ctx context.Context, tenantID string, uid string) (*models.DeviceGroup, error) {
+	ret := _m.Called(ctx, tenantID, uid)
+
+	var r0 *models.DeviceGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.DeviceGroup, error)); ok {
+		return rf(ctx, tenantID, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.DeviceGroup); ok {
+		r0 = rf(ctx, tenantID, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeviceGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInviteLink provides a mock function with given fields: ctx, token
+func (_m *Service) GetInviteLink(ctx context.Context, token string) (*responses.InviteLink, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 *responses.InviteLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*responses.InviteLink, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *responses.InviteLink); ok {
+		r0 = rf(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*responses.InviteLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetNamespace provides a mock function with given fields: ctx, tenantID
 func (_m *Service) GetNamespace(ctx context.Context, tenantID string) (*models.Namespace, error) {
 	ret := _m.Called(ctx, tenantID)
@@ -760,6 +1268,84 @@ func (_m *Service) GetNamespace(ctx context.Context, tenantID string) (*models.N
 	return r0, r1
 }
 
+// GetNamespaceSettings provides a mock function with given fields: ctx, tenantID
+func (_m *Service) GetNamespaceSettings(ctx context.Context, tenantID string) (*models.NamespaceSettings, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 *models.NamespaceSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.NamespaceSettings, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.NamespaceSettings); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.NamespaceSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNamespaceStats provides a mock function with given fields: ctx, tenantID
+func (_m *Service) GetNamespaceStats(ctx context.Context, tenantID string) (*models.Stats, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 *models.Stats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Stats, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Stats); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Stats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNamespaceWebhook provides a mock function with given fields: ctx, tenantID
+func (_m *Service) GetNamespaceWebhook(ctx context.Context, tenantID string) (*models.NamespaceWebhook, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 *models.NamespaceWebhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.NamespaceWebhook, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.NamespaceWebhook); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.NamespaceWebhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPublicKey provides a mock function with given fields: ctx, fingerprint, tenant
 func (_m *Service) GetPublicKey(ctx context.Context, fingerprint string, tenant string) (*models.PublicKey, error) {
 	ret := _m.Called(ctx, fingerprint, tenant)
@@ -895,6 +1481,94 @@ func (_m *Service) GetTags(ctx context.Context, tenant string) ([]string, int, e
 	return r0, r1, r2
 }
 
+// ImportPublicKeysFromGitHub provides a mock function with given fields: ctx, req
+func (_m *Service) ImportPublicKeysFromGitHub(ctx context.Context, req requests.PublicKeyImport) (int, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, requests.PublicKeyImport) (int, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, requests.PublicKeyImport) int); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, requests.PublicKeyImport) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportPublicKeysFromGitLab provides a mock function with given fields: ctx, req
+func (_m *Service) ImportPublicKeysFromGitLab(ctx context.Context, req requests.PublicKeyImport) (int, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, requests.PublicKeyImport) (int, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, requests.PublicKeyImport) int); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, requests.PublicKeyImport) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrementSessionViewCount provides a mock function with given fields: ctx, uid
+func (_m *Service) IncrementSessionViewCount(ctx context.Context, uid models.UID) error {
+	ret := _m.Called(ctx, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) error); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// JoinInviteLink provides a mock function with given fields: ctx, token, userID
+func (_m *Service) JoinInviteLink(ctx context.Context, token string, userID string) (*models.Namespace, error) {
+	ret := _m.Called(ctx, token, userID)
+
+	var r0 *models.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.Namespace, error)); ok {
+		return rf(ctx, token, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.Namespace); ok {
+		r0 = rf(ctx, token, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, token, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // KeepAliveSession provides a mock function with given fields: ctx, uid
 func (_m *Service) KeepAliveSession(ctx context.Context, uid models.UID) error {
 	ret := _m.Called(ctx, uid)
@@ -942,32 +1616,32 @@ func (_m *Service) ListAPIKeys(ctx context.Context, req *requests.ListAPIKey) ([
 	return r0, r1, r2
 }
 
-// ListDevices provides a mock function with given fields: ctx, tenant, status, paginator, filter, sorter
-func (_m *Service) ListDevices(ctx context.Context, tenant string, status models.DeviceStatus, paginator query.Paginator, filter query.Filters, sorter query.Sorter) ([]models.Device, int, error) {
-	ret := _m.Called(ctx, tenant, status, paginator, filter, sorter)
+// ListDeviceConnections provides a mock function with given fields: ctx, tenantID, uid, paginator
+func (_m *Service) ListDeviceConnections(ctx context.Context, tenantID string, uid models.UID, paginator query.Paginator) ([]models.DeviceConnection, int, error) {
+	ret := _m.Called(ctx, tenantID, uid, paginator)
 
-	var r0 []models.Device
+	var r0 []models.DeviceConnection
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, models.DeviceStatus, query.Paginator, query.Filters, query.Sorter) ([]models.Device, int, error)); ok {
-		return rf(ctx, tenant, status, paginator, filter, sorter)
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID, query.Paginator) ([]models.DeviceConnection, int, error)); ok {
+		return rf(ctx, tenantID, uid, paginator)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, models.DeviceStatus, query.Paginator, query.Filters, query.Sorter) []models.Device); ok {
-		r0 = rf(ctx, tenant, status, paginator, filter, sorter)
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID, query.Paginator) []models.DeviceConnection); ok {
+		r0 = rf(ctx, tenantID, uid, paginator)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Device)
+			r0 = ret.Get(0).([]models.DeviceConnection)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, models.DeviceStatus, query.Paginator, query.Filters, query.Sorter) int); ok {
-		r1 = rf(ctx, tenant, status, paginator, filter, sorter)
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.UID, query.Paginator) int); ok {
+		r1 = rf(ctx, tenantID, uid, paginator)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, string, models.DeviceStatus, query.Paginator, query.Filters, query.Sorter) error); ok {
-		r2 = rf(ctx, tenant, status, paginator, filter, sorter)
+	if rf, ok := ret.Get(2).(func(context.Context, string, models.UID, query.Paginator) error); ok {
+		r2 = rf(ctx, tenantID, uid, paginator)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -975,32 +1649,32 @@ func (_m *Service) ListDevices(ctx context.Context, tenant string, status models
 	return r0, r1, r2
 }
 
-// ListNamespaces provides a mock function with given fields: ctx, paginator, filters, export
-func (_m *Service) ListNamespaces(ctx context.Context, paginator query.Paginator, filters query.Filters, export bool) ([]models.Namespace, int, error) {
-	ret := _m.Called(ctx, paginator, filters, export)
+// ListDeviceEvents provides a mock function with given fields: ctx, tenantID, uid, paginator, filters, sorter
+func (_m *Service) ListDeviceEvents(ctx context.Context, tenantID string, uid models.UID, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.DeviceEvent, int, error) {
+	ret := _m.Called(ctx, tenantID, uid, paginator, filters, sorter)
 
-	var r0 []models.Namespace
+	var r0 []models.DeviceEvent
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, bool) ([]models.Namespace, int, error)); ok {
-		return rf(ctx, paginator, filters, export)
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID, query.Paginator, query.Filters, query.Sorter) ([]models.DeviceEvent, int, error)); ok {
+		return rf(ctx, tenantID, uid, paginator, filters, sorter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, bool) []models.Namespace); ok {
-		r0 = rf(ctx, paginator, filters, export)
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID, query.Paginator, query.Filters, query.Sorter) []models.DeviceEvent); ok {
+		r0 = rf(ctx, tenantID, uid, paginator, filters, sorter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Namespace)
+			r0 = ret.Get(0).([]models.DeviceEvent)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, query.Paginator, query.Filters, bool) int); ok {
-		r1 = rf(ctx, paginator, filters, export)
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.UID, query.Paginator, query.Filters, query.Sorter) int); ok {
+		r1 = rf(ctx, tenantID, uid, paginator, filters, sorter)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, query.Paginator, query.Filters, bool) error); ok {
-		r2 = rf(ctx, paginator, filters, export)
+	if rf, ok := ret.Get(2).(func(context.Context, string, models.UID, query.Paginator, query.Filters, query.Sorter) error); ok {
+		r2 = rf(ctx, tenantID, uid, paginator, filters, sorter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -1008,7 +1682,269 @@ func (_m *Service) ListNamespaces(ctx context.Context, paginator query.Paginator
 	return r0, r1, r2
 }
 
-// ListPublicKeys provides a mock function with given fields: ctx, paginator
+// ListDeviceGroupDevices provides a mock function with given fields: ctx, tenantID, req
+func (_m *Service) ListDeviceGroupDevices(ctx context.Context, tenantID string, req *requests.DeviceGroupDevicesList) ([]models.Device, int, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	var r0 []models.Device
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *requests.DeviceGroupDevicesList) ([]models.Device, int, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *requests.DeviceGroupDevicesList) []models.Device); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *requests.DeviceGroupDevicesList) int); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, *requests.DeviceGroupDevicesList) error); ok {
+		r2 = rf(ctx, tenantID, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListDevices provides a mock function with given fields: ctx, tenant, status, paginator, filter, sorter
+func (_m *Service) ListDevices(ctx context.Context, tenant string, status models.DeviceStatus, paginator query.Paginator, filter query.Filters, sorter query.Sorter) ([]models.Device, int, error) {
+	ret := _m.Called(ctx, tenant, status, paginator, filter, sorter)
+
+	var r0 []models.Device
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.DeviceStatus, query.Paginator, query.Filters, query.Sorter) ([]models.Device, int, error)); ok {
+		return rf(ctx, tenant, status, paginator, filter, sorter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.DeviceStatus, query.Paginator, query.Filters, query.Sorter) []models.Device); ok {
+		r0 = rf(ctx, tenant, status, paginator, filter, sorter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.DeviceStatus, query.Paginator, query.Filters, query.Sorter) int); ok {
+		r1 = rf(ctx, tenant, status, paginator, filter, sorter)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, models.DeviceStatus, query.Paginator, query.Filters, query.Sorter) error); ok {
+		r2 = rf(ctx, tenant, status, paginator, filter, sorter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListInviteLinks provides a mock function with given fields: ctx, tenantID
+func (_m *Service) ListInviteLinks(ctx context.Context, tenantID string) ([]models.InviteLink, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 []models.InviteLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.InviteLink, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.InviteLink); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.InviteLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListLiveSessions provides a mock function with given fields: ctx, tenantID
+func (_m *Service) ListLiveSessions(ctx context.Context, tenantID string) ([]models.LiveSession, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 []models.LiveSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.LiveSession, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.LiveSession); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LiveSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMemberTags provides a mock function with given fields: ctx, tenantID
+func (_m *Service) ListMemberTags(ctx context.Context, tenantID string) ([]string, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListNamespaceMembers provides a mock function with given fields: ctx, tenantID, tag
+func (_m *Service) ListNamespaceMembers(ctx context.Context, tenantID string, tag string) ([]models.Member, error) {
+	ret := _m.Called(ctx, tenantID, tag)
+
+	var r0 []models.Member
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]models.Member, error)); ok {
+		return rf(ctx, tenantID, tag)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []models.Member); ok {
+		r0 = rf(ctx, tenantID, tag)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Member)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, tag)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListNamespaces provides a mock function with given fields: ctx, paginator, filters, role, export
+func (_m *Service) ListNamespaces(ctx context.Context, paginator query.Paginator, filters query.Filters, role string, export bool) ([]models.Namespace, int, error) {
+	ret := _m.Called(ctx, paginator, filters, role, export)
+
+	var r0 []models.Namespace
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, string, bool) ([]models.Namespace, int, error)); ok {
+		return rf(ctx, paginator, filters, role, export)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, string, bool) []models.Namespace); ok {
+		r0 = rf(ctx, paginator, filters, role, export)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, query.Paginator, query.Filters, string, bool) int); ok {
+		r1 = rf(ctx, paginator, filters, role, export)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, query.Paginator, query.Filters, string, bool) error); ok {
+		r2 = rf(ctx, paginator, filters, role, export)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListPlans provides a mock function with given fields: ctx
+func (_m *Service) ListPlans(ctx context.Context) ([]models.Plan, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []models.Plan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.Plan, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Plan); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Plan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPendingDevices provides a mock function with given fields: ctx, tenantID, paginator
+func (_m *Service) ListPendingDevices(ctx context.Context, tenantID string, paginator query.Paginator) ([]models.Device, int, error) {
+	ret := _m.Called(ctx, tenantID, paginator)
+
+	var r0 []models.Device
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, query.Paginator) ([]models.Device, int, error)); ok {
+		return rf(ctx, tenantID, paginator)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, query.Paginator) []models.Device); ok {
+		r0 = rf(ctx, tenantID, paginator)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, query.Paginator) int); ok {
+		r1 = rf(ctx, tenantID, paginator)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, query.Paginator) error); ok {
+		r2 = rf(ctx, tenantID, paginator)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListPublicKeys provides a mock function with given fields: ctx, paginator
 func (_m *Service) ListPublicKeys(ctx context.Context, paginator query.Paginator) ([]models.PublicKey, int, error) {
 	ret := _m.Called(ctx, paginator)
 
@@ -1041,58 +1977,497 @@ func (_m *Service) ListPublicKeys(ctx context.Context, paginator query.Paginator
 	return r0, r1, r2
 }
 
-// ListSessions provides a mock function with given fields: ctx, paginator
-func (_m *Service) ListSessions(ctx context.Context, paginator query.Paginator) ([]models.Session, int, error) {
-	ret := _m.Called(ctx, paginator)
+// ListSessionRecordFrames provides a mock function with given fields: ctx, uid
+func (_m *Service) ListSessionRecordFrames(ctx context.Context, uid models.UID) ([]models.RecordedSession, error) {
+	ret := _m.Called(ctx, uid)
+
+	var r0 []models.RecordedSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) ([]models.RecordedSession, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) []models.RecordedSession); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.RecordedSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.UID) error); ok {
+		r1 = rf(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSessions provides a mock function with given fields: ctx, paginator, filters, sorter
+func (_m *Service) ListSessions(ctx context.Context, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.Session, int, error) {
+	ret := _m.Called(ctx, paginator, filters, sorter)
 
 	var r0 []models.Session
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator) ([]models.Session, int, error)); ok {
-		return rf(ctx, paginator)
+	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, query.Sorter) ([]models.Session, int, error)); ok {
+		return rf(ctx, paginator, filters, sorter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, query.Sorter) []models.Session); ok {
+		r0 = rf(ctx, paginator, filters, sorter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, query.Paginator, query.Filters, query.Sorter) int); ok {
+		r1 = rf(ctx, paginator, filters, sorter)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, query.Paginator, query.Filters, query.Sorter) error); ok {
+		r2 = rf(ctx, paginator, filters, sorter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListTopViewedSessions provides a mock function with given fields: ctx, tenantID, limit
+func (_m *Service) ListTopViewedSessions(ctx context.Context, tenantID string, limit int) ([]models.Session, error) {
+	ret := _m.Called(ctx, tenantID, limit)
+
+	var r0 []models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]models.Session, error)); ok {
+		return rf(ctx, tenantID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []models.Session); ok {
+		r0 = rf(ctx, tenantID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, tenantID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListUserSessions provides a mock function with given fields: ctx, userID
+func (_m *Service) ListUserSessions(ctx context.Context, userID string) ([]models.UserSession, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []models.UserSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.UserSession, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.UserSession); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UserSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWebhookDeadLetters provides a mock function with given fields: ctx, tenantID, paginator
+func (_m *Service) ListWebhookDeadLetters(ctx context.Context, tenantID string, paginator query.Paginator) ([]models.WebhookDeadLetter, int, error) {
+	ret := _m.Called(ctx, tenantID, paginator)
+
+	var r0 []models.WebhookDeadLetter
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, query.Paginator) ([]models.WebhookDeadLetter, int, error)); ok {
+		return rf(ctx, tenantID, paginator)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, query.Paginator) []models.WebhookDeadLetter); ok {
+		r0 = rf(ctx, tenantID, paginator)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.WebhookDeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, query.Paginator) int); ok {
+		r1 = rf(ctx, tenantID, paginator)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, query.Paginator) error); ok {
+		r2 = rf(ctx, tenantID, paginator)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// LookupDevice provides a mock function with given fields: ctx, namespace, name
+func (_m *Service) LookupDevice(ctx context.Context, namespace string, name string) (*models.Device, error) {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 *models.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.Device, error)); ok {
+		return rf(ctx, namespace, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.Device); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LookupNamespace provides a mock function with given fields: ctx, name
+func (_m *Service) LookupNamespace(ctx context.Context, name string) (*models.Namespace, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 *models.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Namespace, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Namespace); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OfflineDevice provides a mock function with given fields: ctx, uid
+func (_m *Service) OfflineDevice(ctx context.Context, uid models.UID) error {
+	ret := _m.Called(ctx, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) error); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PingDevice provides a mock function with given fields: ctx, deviceUID, tenantID, timeout
+func (_m *Service) PingDevice(ctx context.Context, deviceUID string, tenantID string, timeout time.Duration) (*models.DevicePingResult, error) {
+	ret := _m.Called(ctx, deviceUID, tenantID, timeout)
+
+	var r0 *models.DevicePingResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) (*models.DevicePingResult, error)); ok {
+		return rf(ctx, deviceUID, tenantID, timeout)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) *models.DevicePingResult); ok {
+		r0 = rf(ctx, deviceUID, tenantID, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DevicePingResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Duration) error); ok {
+		r1 = rf(ctx, deviceUID, tenantID, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PreShareDeviceHostKey provides a mock function with given fields: ctx, deviceUID, tenantID, publicKeyPEM
+func (_m *Service) PreShareDeviceHostKey(ctx context.Context, deviceUID string, tenantID string, publicKeyPEM string) error {
+	ret := _m.Called(ctx, deviceUID, tenantID, publicKeyPEM)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, deviceUID, tenantID, publicKeyPEM)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PreviewFirewallRule provides a mock function with given fields: ctx, tenantID, rule, connection
+func (_m *Service) PreviewFirewallRule(ctx context.Context, tenantID string, rule models.FirewallRuleFields, connection models.FirewallPreviewConnection) (*models.FirewallRulePreview, error) {
+	ret := _m.Called(ctx, tenantID, rule, connection)
+
+	var r0 *models.FirewallRulePreview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.FirewallRuleFields, models.FirewallPreviewConnection) (*models.FirewallRulePreview, error)); ok {
+		return rf(ctx, tenantID, rule, connection)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.FirewallRuleFields, models.FirewallPreviewConnection) *models.FirewallRulePreview); ok {
+		r0 = rf(ctx, tenantID, rule, connection)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.FirewallRulePreview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.FirewallRuleFields, models.FirewallPreviewConnection) error); ok {
+		r1 = rf(ctx, tenantID, rule, connection)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *Service) PublicKey() *rsa.PublicKey {
+	ret := _m.Called()
+
+	var r0 *rsa.PublicKey
+	if rf, ok := ret.Get(0).(func() *rsa.PublicKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*rsa.PublicKey)
+		}
+	}
+
+	return r0
+}
+
+// RecordNamespaceActivity provides a mock function with given fields: ctx, tenantID
+func (_m *Service) RecordNamespaceActivity(ctx context.Context, tenantID string) {
+	_m.Called(ctx, tenantID)
+}
+
+// RefreshBillingCache provides a mock function with given fields: ctx, tenantID
+func (_m *Service) RefreshBillingCache(ctx context.Context, tenantID string) error {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveDeviceFromGroup provides a mock function with given fields: ctx, tenantID, req
+func (_m *Service) RemoveDeviceFromGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupDevice) (*models.DeviceGroup, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	var r0 *models.DeviceGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *requests.DeviceGroupDevice) (*models.DeviceGroup, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *requests.DeviceGroupDevice) *models.DeviceGroup); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeviceGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *requests.DeviceGroupDevice) error); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveDeviceTag provides a mock function with given fields: ctx, uid, tag
+func (_m *Service) RemoveDeviceTag(ctx context.Context, uid models.UID, tag string) error {
+	ret := _m.Called(ctx, uid, tag)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string) error); ok {
+		r0 = rf(ctx, uid, tag)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveNamespaceUser provides a mock function with given fields: ctx, tenantID, memberID, userID
+func (_m *Service) RemoveNamespaceUser(ctx context.Context, tenantID string, memberID string, userID string) (*models.Namespace, error) {
+	ret := _m.Called(ctx, tenantID, memberID, userID)
+
+	var r0 *models.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*models.Namespace, error)); ok {
+		return rf(ctx, tenantID, memberID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *models.Namespace); ok {
+		r0 = rf(ctx, tenantID, memberID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, tenantID, memberID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemovePublicKeyTag provides a mock function with given fields: ctx, tenant, fingerprint, tag
+func (_m *Service) RemovePublicKeyTag(ctx context.Context, tenant string, fingerprint string, tag string) error {
+	ret := _m.Called(ctx, tenant, fingerprint, tag)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, tenant, fingerprint, tag)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RenameDevice provides a mock function with given fields: ctx, uid, name, tenant
+func (_m *Service) RenameDevice(ctx context.Context, uid models.UID, name string, tenant string) error {
+	ret := _m.Called(ctx, uid, name, tenant)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string, string) error); ok {
+		r0 = rf(ctx, uid, name, tenant)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RenameTag provides a mock function with given fields: ctx, tenant, oldTag, newTag
+func (_m *Service) RenameTag(ctx context.Context, tenant string, oldTag string, newTag string) error {
+	ret := _m.Called(ctx, tenant, oldTag, newTag)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, tenant, oldTag, newTag)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RenewNamespace provides a mock function with given fields: ctx, tenantID
+func (_m *Service) RenewNamespace(ctx context.Context, tenantID string) error {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RequestNamespaceDeletion provides a mock function with given fields: ctx, tenantID
+func (_m *Service) RequestNamespaceDeletion(ctx context.Context, tenantID string) (*responses.NamespaceDeletionRequest, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 *responses.NamespaceDeletionRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*responses.NamespaceDeletionRequest, error)); ok {
+		return rf(ctx, tenantID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator) []models.Session); ok {
-		r0 = rf(ctx, paginator)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *responses.NamespaceDeletionRequest); ok {
+		r0 = rf(ctx, tenantID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Session)
+			r0 = ret.Get(0).(*responses.NamespaceDeletionRequest)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, query.Paginator) int); ok {
-		r1 = rf(ctx, paginator)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
 	} else {
-		r1 = ret.Get(1).(int)
+		r1 = ret.Error(1)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, query.Paginator) error); ok {
-		r2 = rf(ctx, paginator)
+	return r0, r1
+}
+
+// RevokeUserSession provides a mock function with given fields: ctx, userID, sessionID
+func (_m *Service) RevokeUserSession(ctx context.Context, userID string, sessionID string) error {
+	ret := _m.Called(ctx, userID, sessionID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, sessionID)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1, r2
+	return r0
 }
 
-// LookupDevice provides a mock function with given fields: ctx, namespace, name
-func (_m *Service) LookupDevice(ctx context.Context, namespace string, name string) (*models.Device, error) {
-	ret := _m.Called(ctx, namespace, name)
+// RotateNamespaceEnrollmentKey provides a mock function with given fields: ctx, tenantID, actorID
+func (_m *Service) RotateNamespaceEnrollmentKey(ctx context.Context, tenantID string, actorID string) (*responses.NamespaceEnrollmentKey, error) {
+	ret := _m.Called(ctx, tenantID, actorID)
 
-	var r0 *models.Device
+	var r0 *responses.NamespaceEnrollmentKey
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.Device, error)); ok {
-		return rf(ctx, namespace, name)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*responses.NamespaceEnrollmentKey, error)); ok {
+		return rf(ctx, tenantID, actorID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.Device); ok {
-		r0 = rf(ctx, namespace, name)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *responses.NamespaceEnrollmentKey); ok {
+		r0 = rf(ctx, tenantID, actorID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Device)
+			r0 = ret.Get(0).(*responses.NamespaceEnrollmentKey)
 		}
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, namespace, name)
+		r1 = rf(ctx, tenantID, actorID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1100,13 +2475,13 @@ func (_m *Service) LookupDevice(ctx context.Context, namespace string, name stri
 	return r0, r1
 }
 
-// OfflineDevice provides a mock function with given fields: ctx, uid
-func (_m *Service) OfflineDevice(ctx context.Context, uid models.UID) error {
-	ret := _m.Called(ctx, uid)
+// SendNamespaceUsageReport provides a mock function with given fields: ctx, tenantID, m
+func (_m *Service) SendNamespaceUsageReport(ctx context.Context, tenantID string, m mailer.Mailer) error {
+	ret := _m.Called(ctx, tenantID, m)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.UID) error); ok {
-		r0 = rf(ctx, uid)
+	if rf, ok := ret.Get(0).(func(context.Context, string, mailer.Mailer) error); ok {
+		r0 = rf(ctx, tenantID, m)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1114,29 +2489,37 @@ func (_m *Service) OfflineDevice(ctx context.Context, uid models.UID) error {
 	return r0
 }
 
-// PublicKey provides a mock function with given fields:
-func (_m *Service) PublicKey() *rsa.PublicKey {
-	ret := _m.Called()
+// SessionExportS3 provides a mock function with given fields: ctx, uid, s3cfg
+func (_m *Service) SessionExportS3(ctx context.Context, uid string, s3cfg *models.S3Config) (string, error) {
+	ret := _m.Called(ctx, uid, s3cfg)
 
-	var r0 *rsa.PublicKey
-	if rf, ok := ret.Get(0).(func() *rsa.PublicKey); ok {
-		r0 = rf()
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.S3Config) (string, error)); ok {
+		return rf(ctx, uid, s3cfg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.S3Config) string); ok {
+		r0 = rf(ctx, uid, s3cfg)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*rsa.PublicKey)
-		}
+		r0 = ret.Get(0).(string)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string, *models.S3Config) error); ok {
+		r1 = rf(ctx, uid, s3cfg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// RemoveDeviceTag provides a mock function with given fields: ctx, uid, tag
-func (_m *Service) RemoveDeviceTag(ctx context.Context, uid models.UID, tag string) error {
-	ret := _m.Called(ctx, uid, tag)
+// SetDefaultEnvVars provides a mock function with given fields: ctx, tenantID, vars
+func (_m *Service) SetDefaultEnvVars(ctx context.Context, tenantID string, vars map[string]string) error {
+	ret := _m.Called(ctx, tenantID, vars)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string) error); ok {
-		r0 = rf(ctx, uid, tag)
+	if rf, ok := ret.Get(0).(func(context.Context, string, map[string]string) error); ok {
+		r0 = rf(ctx, tenantID, vars)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1144,39 +2527,41 @@ func (_m *Service) RemoveDeviceTag(ctx context.Context, uid models.UID, tag stri
 	return r0
 }
 
-// RemoveNamespaceUser provides a mock function with given fields: ctx, tenantID, memberID, userID
-func (_m *Service) RemoveNamespaceUser(ctx context.Context, tenantID string, memberID string, userID string) (*models.Namespace, error) {
-	ret := _m.Called(ctx, tenantID, memberID, userID)
+// SetDeviceExpiry provides a mock function with given fields: ctx, deviceUID, tenantID, expiresAt
+func (_m *Service) SetDeviceExpiry(ctx context.Context, deviceUID string, tenantID string, expiresAt *time.Time) error {
+	ret := _m.Called(ctx, deviceUID, tenantID, expiresAt)
 
-	var r0 *models.Namespace
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*models.Namespace, error)); ok {
-		return rf(ctx, tenantID, memberID, userID)
-	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *models.Namespace); ok {
-		r0 = rf(ctx, tenantID, memberID, userID)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *time.Time) error); ok {
+		r0 = rf(ctx, deviceUID, tenantID, expiresAt)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Namespace)
-		}
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
-		r1 = rf(ctx, tenantID, memberID, userID)
+	return r0
+}
+
+// SetDeviceSessionPolicy provides a mock function with given fields: ctx, deviceUID, tenantID, policy
+func (_m *Service) SetDeviceSessionPolicy(ctx context.Context, deviceUID string, tenantID string, policy *models.DeviceSessionPolicy) error {
+	ret := _m.Called(ctx, deviceUID, tenantID, policy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *models.DeviceSessionPolicy) error); ok {
+		r0 = rf(ctx, deviceUID, tenantID, policy)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// RemovePublicKeyTag provides a mock function with given fields: ctx, tenant, fingerprint, tag
-func (_m *Service) RemovePublicKeyTag(ctx context.Context, tenant string, fingerprint string, tag string) error {
-	ret := _m.Called(ctx, tenant, fingerprint, tag)
+// SetMemberAccessSchedule provides a mock function with given fields: ctx, tenantID, memberID, schedule
+func (_m *Service) SetMemberAccessSchedule(ctx context.Context, tenantID string, memberID string, schedule *models.AccessSchedule) error {
+	ret := _m.Called(ctx, tenantID, memberID, schedule)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
-		r0 = rf(ctx, tenant, fingerprint, tag)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *models.AccessSchedule) error); ok {
+		r0 = rf(ctx, tenantID, memberID, schedule)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1184,13 +2569,13 @@ func (_m *Service) RemovePublicKeyTag(ctx context.Context, tenant string, finger
 	return r0
 }
 
-// RenameDevice provides a mock function with given fields: ctx, uid, name, tenant
-func (_m *Service) RenameDevice(ctx context.Context, uid models.UID, name string, tenant string) error {
-	ret := _m.Called(ctx, uid, name, tenant)
+// SetMemberTags provides a mock function with given fields: ctx, tenantID, memberID, tags
+func (_m *Service) SetMemberTags(ctx context.Context, tenantID string, memberID string, tags []string) error {
+	ret := _m.Called(ctx, tenantID, memberID, tags)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string, string) error); ok {
-		r0 = rf(ctx, uid, name, tenant)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) error); ok {
+		r0 = rf(ctx, tenantID, memberID, tags)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1198,13 +2583,13 @@ func (_m *Service) RenameDevice(ctx context.Context, uid models.UID, name string
 	return r0
 }
 
-// RenameTag provides a mock function with given fields: ctx, tenant, oldTag, newTag
-func (_m *Service) RenameTag(ctx context.Context, tenant string, oldTag string, newTag string) error {
-	ret := _m.Called(ctx, tenant, oldTag, newTag)
+// SetNamespaceAPIRateLimit provides a mock function with given fields: ctx, tenantID, limit
+func (_m *Service) SetNamespaceAPIRateLimit(ctx context.Context, tenantID string, limit *models.APIRateLimitConfig) error {
+	ret := _m.Called(ctx, tenantID, limit)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
-		r0 = rf(ctx, tenant, oldTag, newTag)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.APIRateLimitConfig) error); ok {
+		r0 = rf(ctx, tenantID, limit)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1212,6 +2597,58 @@ func (_m *Service) RenameTag(ctx context.Context, tenant string, oldTag string,
 	return r0
 }
 
+// SetNamespaceWebhook provides a mock function with given fields: ctx, req
+func (_m *Service) SetNamespaceWebhook(ctx context.Context, req *requests.NamespaceWebhookSet) (*responses.NamespaceWebhook, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *responses.NamespaceWebhook
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *requests.NamespaceWebhookSet) (*responses.NamespaceWebhook, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *requests.NamespaceWebhookSet) *responses.NamespaceWebhook); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*responses.NamespaceWebhook)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *requests.NamespaceWebhookSet) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetSessionRecordForNamespaces provides a mock function with given fields: ctx, ownerID, tenants, enabled
+func (_m *Service) SetSessionRecordForNamespaces(ctx context.Context, ownerID string, tenants []string, enabled bool) ([]services.SessionRecordResult, error) {
+	ret := _m.Called(ctx, ownerID, tenants, enabled)
+
+	var r0 []services.SessionRecordResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, bool) ([]services.SessionRecordResult, error)); ok {
+		return rf(ctx, ownerID, tenants, enabled)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, bool) []services.SessionRecordResult); ok {
+		r0 = rf(ctx, ownerID, tenants, enabled)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]services.SessionRecordResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, bool) error); ok {
+		r1 = rf(ctx, ownerID, tenants, enabled)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Setup provides a mock function with given fields: ctx, req
 func (_m *Service) Setup(ctx context.Context, req requests.Setup) error {
 	ret := _m.Called(ctx, req)
@@ -1226,6 +2663,20 @@ func (_m *Service) Setup(ctx context.Context, req requests.Setup) error {
 	return r0
 }
 
+// StreamSessionRecordFrames provides a mock function with given fields: ctx, uid, fn
+func (_m *Service) StreamSessionRecordFrames(ctx context.Context, uid models.UID, fn func(*models.RecordedSession) error) error {
+	ret := _m.Called(ctx, uid, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, func(*models.RecordedSession) error) error); ok {
+		r0 = rf(ctx, uid, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SystemDownloadInstallScript provides a mock function with given fields: ctx, req
 func (_m *Service) SystemDownloadInstallScript(ctx context.Context, req requests.SystemInstallScript) (*template.Template, map[string]interface{}, error) {
 	ret := _m.Called(ctx, req)
@@ -1287,6 +2738,20 @@ func (_m *Service) SystemGetInfo(ctx context.Context, req requests.SystemGetInfo
 	return r0, r1
 }
 
+// TransferSession provides a mock function with given fields: ctx, sessionUID, fromMemberID, toMemberID
+func (_m *Service) TransferSession(ctx context.Context, sessionUID string, fromMemberID string, toMemberID string) error {
+	ret := _m.Called(ctx, sessionUID, fromMemberID, toMemberID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, sessionUID, fromMemberID, toMemberID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateAPIKey provides a mock function with given fields: ctx, req
 func (_m *Service) UpdateAPIKey(ctx context.Context, req *requests.UpdateAPIKey) error {
 	ret := _m.Called(ctx, req)
@@ -1341,6 +2806,46 @@ func (_m *Service) UpdateDevice(ctx context.Context, tenant string, uid models.U
 	return r0
 }
 
+// UpdateDeviceAllowedCommands provides a mock function with given fields: ctx, deviceUID, tenantID, commands
+func (_m *Service) UpdateDeviceAllowedCommands(ctx context.Context, deviceUID string, tenantID string, commands []string) error {
+	ret := _m.Called(ctx, deviceUID, tenantID, commands)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) error); ok {
+		r0 = rf(ctx, deviceUID, tenantID, commands)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateDeviceGroup provides a mock function with given fields: ctx, tenantID, req
+func (_m *Service) UpdateDeviceGroup(ctx context.Context, tenantID string, req *requests.DeviceGroupUpdate) (*models.DeviceGroup, error) {
+	ret := _m.Called(ctx, tenantID, req)
+
+	var r0 *models.DeviceGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *requests.DeviceGroupUpdate) (*models.DeviceGroup, error)); ok {
+		return rf(ctx, tenantID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *requests.DeviceGroupUpdate) *models.DeviceGroup); ok {
+		r0 = rf(ctx, tenantID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeviceGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *requests.DeviceGroupUpdate) error); ok {
+		r1 = rf(ctx, tenantID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateDeviceStatus provides a mock function with given fields: ctx, tenant, uid, status
 func (_m *Service) UpdateDeviceStatus(ctx context.Context, tenant string, uid models.UID, status models.DeviceStatus) error {
 	ret := _m.Called(ctx, tenant, uid, status)
@@ -1369,6 +2874,32 @@ func (_m *Service) UpdateDeviceTag(ctx context.Context, uid models.UID, tags []s
 	return r0
 }
 
+// UpdateNamespaceSettings provides a mock function with given fields: ctx, req
+func (_m *Service) UpdateNamespaceSettings(ctx context.Context, req *requests.NamespaceSettingsUpdate) (*models.NamespaceSettings, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *models.NamespaceSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *requests.NamespaceSettingsUpdate) (*models.NamespaceSettings, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *requests.NamespaceSettingsUpdate) *models.NamespaceSettings); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.NamespaceSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *requests.NamespaceSettingsUpdate) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdatePasswordUser provides a mock function with given fields: ctx, id, currentPassword, newPassword
 func (_m *Service) UpdatePasswordUser(ctx context.Context, id string, currentPassword string, newPassword string) error {
 	ret := _m.Called(ctx, id, currentPassword, newPassword)
@@ -1437,6 +2968,84 @@ func (_m *Service) UpdateSession(ctx context.Context, uid models.UID, model mode
 	return r0
 }
 
+// UploadSessionRecord provides a mock function with given fields: ctx, uid, offset, body
+func (_m *Service) UploadSessionRecord(ctx context.Context, uid models.UID, offset int64, body io.Reader) (*models.RecordingUploadState, error) {
+	ret := _m.Called(ctx, uid, offset, body)
+
+	var r0 *models.RecordingUploadState
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, int64, io.Reader) (*models.RecordingUploadState, error)); ok {
+		return rf(ctx, uid, offset, body)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, int64, io.Reader) *models.RecordingUploadState); ok {
+		r0 = rf(ctx, uid, offset, body)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RecordingUploadState)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.UID, int64, io.Reader) error); ok {
+		r1 = rf(ctx, uid, offset, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateFirewallRules provides a mock function with given fields: ctx, tenantID
+func (_m *Service) ValidateFirewallRules(ctx context.Context, tenantID string) ([]models.FirewallConflict, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 []models.FirewallConflict
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.FirewallConflict, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.FirewallConflict); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FirewallConflict)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WatchSession provides a mock function with given fields: ctx, uid
+func (_m *Service) WatchSession(ctx context.Context, uid string) (<-chan models.SessionFrame, error) {
+	ret := _m.Called(ctx, uid)
+
+	var r0 <-chan models.SessionFrame
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (<-chan models.SessionFrame, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) <-chan models.SessionFrame); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan models.SessionFrame)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewService interface {
 	mock.TestingT
 	Cleanup(func())
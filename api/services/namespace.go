@@ -2,46 +2,128 @@ package services
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
+	"net/mail"
 	"strings"
+	"time"
 
+	"github.com/robfig/cron/v3"
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/mailer"
 	"github.com/shellhub-io/shellhub/api/store"
 	req "github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/envs"
+	"github.com/shellhub-io/shellhub/pkg/logger"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/pkg/uuid"
+	log "github.com/sirupsen/logrus"
 )
 
 type NamespaceService interface {
-	ListNamespaces(ctx context.Context, paginator query.Paginator, filters query.Filters, export bool) ([]models.Namespace, int, error)
+	ListNamespaces(ctx context.Context, paginator query.Paginator, filters query.Filters, role string, export bool) ([]models.Namespace, int, error)
 	CreateNamespace(ctx context.Context, namespace requests.NamespaceCreate, userID string) (*models.Namespace, error)
 	GetNamespace(ctx context.Context, tenantID string) (*models.Namespace, error)
-	DeleteNamespace(ctx context.Context, tenantID string) error
+	// LookupNamespace gets a namespace by its name.
+	LookupNamespace(ctx context.Context, name string) (*models.Namespace, error)
+	// RequestNamespaceDeletion issues a token, valid for [NamespaceDeletionTTL], that must be redeemed to delete the
+	// namespace.
+	RequestNamespaceDeletion(ctx context.Context, tenantID string) (*responses.NamespaceDeletionRequest, error)
+	// DeleteNamespace deletes the namespace, along with the recordings of its sessions, redeeming the token issued by
+	// RequestNamespaceDeletion. When confirmationText isn't empty, it must be the namespace's name. An empty
+	// confirmationToken is only accepted when SHELLHUB_NAMESPACE_DELETION_WITHOUT_TOKEN is enabled.
+	DeleteNamespace(ctx context.Context, tenantID, confirmationToken, confirmationText string) error
+	// ExpireNamespace deletes the namespace whose inactivity expired it, along with the recordings of its sessions,
+	// without the confirmation DeleteNamespace requires, as it is only called internally.
+	ExpireNamespace(ctx context.Context, tenantID string) error
 
 	// EditNamespace updates a namespace for the specified requests.NamespaceEdit#Tenant.
 	// It returns the namespace with the updated fields and an error, if any.
 	EditNamespace(ctx context.Context, req *requests.NamespaceEdit) (*models.Namespace, error)
 
+	// GetNamespaceSettings gets the effective settings of the namespace with the specified tenant ID, with the defaults
+	// applied to the ones it doesn't define.
+	GetNamespaceSettings(ctx context.Context, tenantID string) (*models.NamespaceSettings, error)
+
+	// UpdateNamespaceSettings updates only the settings set in req, returning the effective settings of the namespace
+	// after the update.
+	UpdateNamespaceSettings(ctx context.Context, req *requests.NamespaceSettingsUpdate) (*models.NamespaceSettings, error)
+
+	// SetDefaultEnvVars sets the environment variables injected into the sessions of the namespace, replacing the
+	// previous ones. An empty vars clears them. Each variable must pass [models.ValidateEnvVar].
+	SetDefaultEnvVars(ctx context.Context, tenantID string, vars map[string]string) error
+
 	AddNamespaceUser(ctx context.Context, memberUsername, memberRole, tenantID, userID string) (*models.Namespace, error)
 	RemoveNamespaceUser(ctx context.Context, tenantID, memberID, userID string) (*models.Namespace, error)
 	EditNamespaceUser(ctx context.Context, tenantID, userID, memberID, memberNewRole string) error
+
+	// SetMemberTags replaces the tags of a namespace's member. A member has at most [models.MemberTagsMax] tags, and
+	// setting no tags removes the member's ones.
+	SetMemberTags(ctx context.Context, tenantID, memberID string, tags []string) error
+
+	// SetMemberAccessSchedule sets the access schedule of a namespace's member, restricting the SSH connections made
+	// with the public keys the member created. A nil schedule removes the member's one.
+	SetMemberAccessSchedule(ctx context.Context, tenantID, memberID string, schedule *models.AccessSchedule) error
+
+	// ListNamespaceMembers lists the members of a namespace, with their data filled. When tag is not empty, only the
+	// members with it are listed.
+	ListNamespaceMembers(ctx context.Context, tenantID, tag string) ([]models.Member, error)
+
+	// ListMemberTags lists, sorted, the unique tags of a namespace's members.
+	ListMemberTags(ctx context.Context, tenantID string) ([]string, error)
+
+	// RenewNamespace marks the namespace as active now, clearing the expiration date set to the inactive free-tier
+	// namespaces.
+	RenewNamespace(ctx context.Context, tenantID string) error
+
+	// RecordNamespaceActivity renews the namespace, like RenewNamespace, at most once every
+	// [NamespaceActivityInterval], what suits the frequent activity, like the authenticated requests. A failure is
+	// only logged, as the activity itself must not fail because of it.
+	RecordNamespaceActivity(ctx context.Context, tenantID string)
+
+	// SendNamespaceUsageReport sends, through m, the usage report of the namespace to its usage report email right
+	// away, regardless of its schedule, at most once every [UsageReportSendInterval]. It fails when the namespace has
+	// no usage report email, when the email doesn't belong to a member of the namespace anymore or m is nil, what
+	// means the SMTP server isn't configured.
+	SendNamespaceUsageReport(ctx context.Context, tenantID string, m mailer.Mailer) error
+
 	EditSessionRecordStatus(ctx context.Context, sessionRecord bool, tenantID string) error
+	// SetSessionRecordForNamespaces defines if the sessions will be recorded on each namespace of tenants owned by
+	// ownerID. Each namespace is handled independently, so a failure on one doesn't prevent the others from being
+	// updated; the outcome of each one is reported in the returned results, in the same order of tenants.
+	SetSessionRecordForNamespaces(ctx context.Context, ownerID string, tenants []string, enabled bool) ([]SessionRecordResult, error)
 	GetSessionRecord(ctx context.Context, tenantID string) (bool, error)
+	// ExportNamespaceMembers writes the members of a namespace to w as CSV, with the columns in
+	// [NamespaceMembersExportHeader]. The rows are written while the members' users are fetched, so large namespaces
+	// are streamed instead of built in memory. Members whose user was deleted are written as [DeletedMemberUsername].
+	ExportNamespaceMembers(ctx context.Context, tenantID string, w io.Writer) error
 }
 
+// DeletedMemberUsername is the username exported for a member whose user no longer exists.
+const DeletedMemberUsername = "[deleted]"
+
+// NamespaceMembersExportHeader is the header of the CSV written by ExportNamespaceMembers.
+var NamespaceMembersExportHeader = []string{"username", "email", "role", "joined_at", "last_seen_at"}
+
+// namespaceMembersExportFlush is the number of rows buffered before being flushed to the writer.
+const namespaceMembersExportFlush = 100
+
 // ListNamespaces lists selected namespaces from a user.
 //
 // It receives a context, used to "control" the request flow, a pagination query, that indicate how many registers are
-// requested per page, a filter string, a base64 encoded value what is converted to a slice of models.Filter and an
-// export flag.
+// requested per page, a filter string, a base64 encoded value what is converted to a slice of models.Filter, a role,
+// what restricts the list to the namespaces where the user in context has it when not empty, and an export flag.
 //
 // ListNamespaces returns a slice of models.Namespace, the total of namespaces and an error. When error is not nil, the
 // slice of models.Namespace is nil, total is zero.
-func (s *service) ListNamespaces(ctx context.Context, paginator query.Paginator, filters query.Filters, export bool) ([]models.Namespace, int, error) {
-	namespaces, count, err := s.store.NamespaceList(ctx, paginator, filters, export)
+func (s *service) ListNamespaces(ctx context.Context, paginator query.Paginator, filters query.Filters, role string, export bool) ([]models.Namespace, int, error) {
+	namespaces, count, err := s.store.NamespaceList(ctx, paginator, filters, role, export)
 	if err != nil {
 		return nil, 0, NewErrNamespaceList(err)
 	}
@@ -65,9 +147,16 @@ func (s *service) CreateNamespace(ctx context.Context, namespace requests.Namesp
 		return nil, NewErrUserNotFound(userID, err)
 	}
 
+	// When the user has a plan, its limits take precedence over the user's ones.
+	maxNamespaces := user.MaxNamespaces
+	plan, hasPlan := getPlan(user.PlanID)
+	if hasPlan {
+		maxNamespaces = plan.MaxNamespaces
+	}
+
 	// When MaxNamespaces is less than zero, it means that the user has no limit of namespaces.
-	if user.MaxNamespaces > 0 && user.MaxNamespaces <= user.Namespaces {
-		return nil, NewErrNamespaceLimitReached(user.MaxNamespaces, nil)
+	if maxNamespaces > 0 && maxNamespaces <= user.Namespaces {
+		return nil, NewErrNamespaceLimitReached(maxNamespaces, nil)
 	}
 
 	ns := &models.Namespace{
@@ -103,6 +192,10 @@ func (s *service) CreateNamespace(ctx context.Context, namespace requests.Namesp
 		ns.MaxDevices = -1
 	}
 
+	if hasPlan {
+		ns.MaxDevices = plan.MaxDevices
+	}
+
 	otherNamespace, err := s.store.NamespaceGetByName(ctx, ns.Name)
 	if err != nil && err != store.ErrNoDocuments {
 		return nil, NewErrNamespaceNotFound(ns.Name, err)
@@ -116,6 +209,10 @@ func (s *service) CreateNamespace(ctx context.Context, namespace requests.Namesp
 		return nil, NewErrNamespaceCreateStore(err)
 	}
 
+	logger.FromContext(ctx).
+		WithFields(log.Fields{"tenant_id": ns.TenantID, "owner": ns.Owner}).
+		Info("namespace created")
+
 	return ns, nil
 }
 
@@ -140,28 +237,136 @@ func (s *service) GetNamespace(ctx context.Context, tenantID string) (*models.Na
 	return namespace, nil
 }
 
+// LookupNamespace gets a namespace by its name, without filling its members' data.
+func (s *service) LookupNamespace(ctx context.Context, name string) (*models.Namespace, error) {
+	namespace, err := s.store.NamespaceGetByName(ctx, name)
+	if err != nil || namespace == nil {
+		return nil, NewErrNamespaceNotFound(name, err)
+	}
+
+	return namespace, nil
+}
+
+// NamespaceDeletionTTL is how long the token to confirm the deletion of a namespace is valid.
+const NamespaceDeletionTTL = time.Hour
+
+// namespaceDeletion is the deletion of a namespace a confirmation token was issued to.
+type namespaceDeletion struct {
+	TenantID  string    `json:"tenant_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func namespaceDeletionCacheKey(token string) string {
+	return "namespace-deletion={" + token + "}"
+}
+
+func (s *service) RequestNamespaceDeletion(ctx context.Context, tenantID string) (*responses.NamespaceDeletionRequest, error) {
+	if _, err := s.store.NamespaceGet(ctx, tenantID, false); err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	token := uuid.Generate()
+	deletion := &namespaceDeletion{TenantID: tenantID, ExpiresAt: clock.Now().Add(NamespaceDeletionTTL)}
+
+	if err := s.cache.Set(ctx, namespaceDeletionCacheKey(token), deletion, NamespaceDeletionTTL); err != nil {
+		return nil, err
+	}
+
+	return &responses.NamespaceDeletionRequest{ConfirmationToken: token, ExpiresAt: deletion.ExpiresAt}, nil
+}
+
 // DeleteNamespace deletes a namespace.
 //
-// It receives a context, used to "control" the request flow and the tenant ID from models.Namespace.
+// It receives a context, used to "control" the request flow, the tenant ID from models.Namespace and the token issued
+// to confirm its deletion, which is redeemed once the namespace is deleted.
 //
 // When cloud and billing is enabled, it will try to delete the namespace's billing information from the billing
 // service if it exists.
-func (s *service) DeleteNamespace(ctx context.Context, tenantID string) error {
+func (s *service) DeleteNamespace(ctx context.Context, tenantID, confirmationToken, confirmationText string) error {
 	ns, err := s.store.NamespaceGet(ctx, tenantID, true)
 	if err != nil {
 		return NewErrNamespaceNotFound(tenantID, err)
 	}
 
-	ableToReportDeleteNamespace := func(ns *models.Namespace) bool {
-		return !ns.Billing.IsNil() && ns.Billing.HasCutomer() && ns.Billing.HasSubscription()
+	if confirmationToken == "" {
+		// NOTICE: the clients that don't request the deletion token, like older UIs, can only delete namespaces when
+		// SHELLHUB_NAMESPACE_DELETION_WITHOUT_TOKEN is enabled.
+		if envs.DefaultBackend.Get("SHELLHUB_NAMESPACE_DELETION_WITHOUT_TOKEN") != "true" {
+			return NewErrNamespaceDeletionInvalid(nil)
+		}
+
+		logger.FromContext(ctx).WithField("tenant_id", tenantID).Warn("namespace deleted without a confirmation token")
+	} else {
+		deletion := new(namespaceDeletion)
+		if err := s.cache.Get(ctx, namespaceDeletionCacheKey(confirmationToken), deletion); err != nil {
+			return err
+		}
+
+		// NOTICE: a token that was never issued, or was already redeemed, isn't on the cache, leaving the deletion
+		// empty.
+		if deletion.TenantID != tenantID {
+			return NewErrNamespaceDeletionInvalid(nil)
+		}
+
+		if !clock.Now().Before(deletion.ExpiresAt) {
+			return NewErrNamespaceDeletionExpired(nil)
+		}
+	}
+
+	if confirmationText != "" && confirmationText != ns.Name {
+		return NewErrNamespaceDeletionMismatch(nil)
 	}
 
-	if envs.IsCloud() && envs.HasBilling() && ableToReportDeleteNamespace(ns) {
+	if err := s.deleteNamespace(ctx, ns); err != nil {
+		return err
+	}
+
+	if confirmationToken != "" {
+		if err := s.cache.Delete(ctx, namespaceDeletionCacheKey(confirmationToken)); err != nil {
+			logger.FromContext(ctx).WithError(err).WithField("tenant_id", tenantID).Warn("failed to redeem the namespace deletion token")
+		}
+	}
+
+	logger.FromContext(ctx).
+		WithField("tenant_id", tenantID).
+		Info("namespace deleted")
+
+	return nil
+}
+
+func (s *service) ExpireNamespace(ctx context.Context, tenantID string) error {
+	ns, err := s.store.NamespaceGet(ctx, tenantID, true)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	return s.deleteNamespace(ctx, ns)
+}
+
+// deleteNamespace deletes the namespace and the recordings of its sessions, reporting the deletion to the billing
+// service when the namespace is subscribed.
+func (s *service) deleteNamespace(ctx context.Context, ns *models.Namespace) error {
+	tenantID := ns.TenantID
+
+	if envs.IsCloud() && envs.HasBilling() && s.ableToReportDeleteNamespace(ctx, ns) {
 		if err := s.BillingReport(s.client.(req.Client), tenantID, ReportNamespaceDelete); err != nil {
 			return NewErrBillingReportNamespaceDelete(err)
 		}
 	}
 
+	// NOTICE: the recordings are deleted before the namespace, as its sessions are listed from it, so a failed deletion
+	// can be retried.
+	uids, err := s.store.SessionListUIDs(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	for _, uid := range uids {
+		if err := s.recordings.Delete(ctx, uid); err != nil {
+			return err
+		}
+	}
+
 	return s.store.NamespaceDelete(ctx, tenantID)
 }
 
@@ -182,7 +387,7 @@ func (s *service) fillMembersData(ctx context.Context, members []models.Member)
 			return nil, NewErrUserNotFound(member.ID, err)
 		}
 
-		members[index] = models.Member{ID: user.ID, Username: user.Username, Role: member.Role}
+		members[index] = models.Member{ID: user.ID, Username: user.Username, Role: member.Role, AddedAt: member.AddedAt, Tags: member.Tags, AccessSchedule: member.AccessSchedule}
 	}
 
 	return members, nil
@@ -190,9 +395,53 @@ func (s *service) fillMembersData(ctx context.Context, members []models.Member)
 
 func (s *service) EditNamespace(ctx context.Context, req *requests.NamespaceEdit) (*models.Namespace, error) {
 	changes := &models.NamespaceChanges{
-		Name:                   strings.ToLower(req.Name),
-		SessionRecord:          req.Settings.SessionRecord,
-		ConnectionAnnouncement: req.Settings.ConnectionAnnouncement,
+		Name:                       strings.ToLower(req.Name),
+		SessionRecord:              req.Settings.SessionRecord,
+		ConnectionAnnouncement:     req.Settings.ConnectionAnnouncement,
+		ExecAnnouncement:           req.Settings.ExecAnnouncement,
+		DefaultFirewallPolicy:      req.Settings.DefaultFirewallPolicy,
+		TransferSessionEnabled:     req.Settings.TransferSessionEnabled,
+		AccessSchedule:             req.Settings.AccessSchedule,
+		MinRSABits:                 req.Settings.MinRSABits,
+		AllowDSA:                   req.Settings.AllowDSA,
+		AllowRSA1024:               req.Settings.AllowRSA1024,
+		LiveMonitoringEnabled:      req.Settings.LiveMonitoringEnabled,
+		MaxLiveMonitors:            req.Settings.MaxLiveMonitors,
+		RecordingIdlePauseMS:       req.Settings.RecordingIdlePauseMS,
+		UsageReportSchedule:        req.Settings.UsageReportSchedule,
+		UsageReportEmail:           req.Settings.UsageReportEmail,
+		MaxSessionDurationMinutes:  req.Settings.MaxSessionDurationMinutes,
+		MaxSessionKeepaliveEnabled: req.Settings.MaxSessionKeepaliveEnabled,
+	}
+
+	if err := validateNamespaceChanges(changes); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateUsageReportEmail(ctx, req.Tenant, changes.UsageReportEmail); err != nil {
+		return nil, err
+	}
+
+	// As the namespace's name is part of the SSHID, the previous one is kept to be referenced after the rename.
+	if changes.Name != "" {
+		namespace, err := s.store.NamespaceGet(ctx, req.Tenant, false)
+		if err != nil {
+			return nil, NewErrNamespaceNotFound(req.Tenant, err)
+		}
+
+		if namespace.Name != changes.Name {
+			// The names are stored lowercased, so a rename differing only in case from another namespace collides.
+			otherNamespace, err := s.store.NamespaceGetByName(ctx, changes.Name)
+			if err != nil && err != store.ErrNoDocuments {
+				return nil, NewErrNamespaceNotFound(changes.Name, err)
+			}
+
+			if otherNamespace != nil && otherNamespace.TenantID != req.Tenant {
+				return nil, NewErrNamespaceDuplicated(nil)
+			}
+
+			changes.PreviousName = &models.NamespacePreviousName{Name: namespace.Name, ChangedAt: clock.Now()}
+		}
 	}
 
 	if err := s.store.NamespaceEdit(ctx, req.Tenant, changes); err != nil {
@@ -204,9 +453,167 @@ func (s *service) EditNamespace(ctx context.Context, req *requests.NamespaceEdit
 		}
 	}
 
+	// NOTICE: the billing isn't among the changes, being set by the billing service, but the cached status is
+	// invalidated anyway so an edit always evaluates the namespace as it's stored.
+	s.invalidateBillingStatus(ctx, req.Tenant)
+
 	return s.store.NamespaceGet(ctx, req.Tenant, true)
 }
 
+// validateNamespaceChanges checks the settings changed are valid.
+func validateNamespaceChanges(changes *models.NamespaceChanges) error {
+	if policy := changes.DefaultFirewallPolicy; policy != nil && *policy != models.FirewallPolicyAllow && *policy != models.FirewallPolicyDeny {
+		return NewErrNamespaceInvalid(errors.New("invalid default firewall policy"))
+	}
+
+	if schedule := changes.AccessSchedule; schedule != nil {
+		if err := models.ValidateAccessSchedule(*schedule); err != nil {
+			return NewErrNamespaceInvalid(err)
+		}
+	}
+
+	if schedule := changes.UsageReportSchedule; schedule != nil && *schedule != "" {
+		if _, err := cron.ParseStandard(*schedule); err != nil {
+			return NewErrNamespaceInvalid(err)
+		}
+	}
+
+	if email := changes.UsageReportEmail; email != nil && *email != "" {
+		if _, err := mail.ParseAddress(*email); err != nil {
+			return NewErrNamespaceInvalid(err)
+		}
+	}
+
+	return nil
+}
+
+// validateUsageReportEmail checks the usage report email set, if any, belongs to a member of the namespace, what
+// keeps the reports from being sent to any address.
+func (s *service) validateUsageReportEmail(ctx context.Context, tenantID string, email *string) error {
+	if email == nil || *email == "" {
+		return nil
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	member, err := s.isMemberEmail(ctx, namespace, *email)
+	if err != nil {
+		return err
+	}
+
+	if !member {
+		return NewErrNamespaceInvalid(errors.New("usage report email must belong to a member of the namespace"))
+	}
+
+	return nil
+}
+
+// isMemberEmail reports whether the email is the one of a member of the namespace.
+func (s *service) isMemberEmail(ctx context.Context, namespace *models.Namespace, email string) (bool, error) {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return false, nil
+	}
+
+	user, err := s.store.UserGetByEmail(ctx, address.Address)
+	switch {
+	case errors.Is(err, store.ErrNoDocuments):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	_, ok := namespace.FindMember(user.ID)
+
+	return ok, nil
+}
+
+func (s *service) GetNamespaceSettings(ctx context.Context, tenantID string) (*models.NamespaceSettings, error) {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	settings := namespace.Settings.Effective()
+
+	return &settings, nil
+}
+
+func (s *service) UpdateNamespaceSettings(ctx context.Context, req *requests.NamespaceSettingsUpdate) (*models.NamespaceSettings, error) {
+	changes := &models.NamespaceChanges{
+		SessionRecord:              req.SessionRecord,
+		ConnectionAnnouncement:     req.ConnectionAnnouncement,
+		ExecAnnouncement:           req.ExecAnnouncement,
+		DefaultFirewallPolicy:      req.DefaultFirewallPolicy,
+		TransferSessionEnabled:     req.TransferSessionEnabled,
+		AccessSchedule:             req.AccessSchedule,
+		MinRSABits:                 req.MinRSABits,
+		AllowDSA:                   req.AllowDSA,
+		AllowRSA1024:               req.AllowRSA1024,
+		LiveMonitoringEnabled:      req.LiveMonitoringEnabled,
+		MaxLiveMonitors:            req.MaxLiveMonitors,
+		RecordingIdlePauseMS:       req.RecordingIdlePauseMS,
+		UsageReportSchedule:        req.UsageReportSchedule,
+		UsageReportEmail:           req.UsageReportEmail,
+		MaxSessionDurationMinutes:  req.MaxSessionDurationMinutes,
+		MaxSessionKeepaliveEnabled: req.MaxSessionKeepaliveEnabled,
+	}
+
+	// NOTICE: without any setting to change, the namespace is left as it is.
+	if *changes == (models.NamespaceChanges{}) {
+		return s.GetNamespaceSettings(ctx, req.Tenant)
+	}
+
+	if err := validateNamespaceChanges(changes); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateUsageReportEmail(ctx, req.Tenant, changes.UsageReportEmail); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.NamespaceEdit(ctx, req.Tenant, changes); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNoDocuments):
+			return nil, NewErrNamespaceNotFound(req.Tenant, err)
+		default:
+			return nil, err
+		}
+	}
+
+	return s.GetNamespaceSettings(ctx, req.Tenant)
+}
+
+func (s *service) SetDefaultEnvVars(ctx context.Context, tenantID string, vars map[string]string) error {
+	if len(vars) > models.DefaultEnvVarsMax {
+		return NewErrNamespaceInvalid(fmt.Errorf("at most %d default environment variables are allowed", models.DefaultEnvVarsMax))
+	}
+
+	for name, value := range vars {
+		if err := models.ValidateEnvVar(name, value); err != nil {
+			return NewErrNamespaceInvalid(fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	if vars == nil {
+		vars = map[string]string{}
+	}
+
+	if err := s.store.NamespaceEdit(ctx, tenantID, &models.NamespaceChanges{DefaultEnvVars: &vars}); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNoDocuments):
+			return NewErrNamespaceNotFound(tenantID, err)
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
 // AddNamespaceUser adds a member to a namespace.
 //
 // It receives a context, used to "control" the request flow, the member's name, the member's role, the tenant ID from
@@ -252,7 +659,18 @@ func (s *service) AddNamespaceUser(ctx context.Context, memberUsername, memberRo
 		return nil, guard.ErrForbidden
 	}
 
-	return s.store.NamespaceAddMember(ctx, tenantID, passive.ID, memberRole)
+	if err := s.checkNamespaceMembersLimit(ctx, namespace); err != nil {
+		return nil, err
+	}
+
+	namespace, err = s.store.NamespaceAddMember(ctx, tenantID, passive.ID, memberRole)
+	if err != nil {
+		return nil, err
+	}
+
+	s.emitWebhookEvent(ctx, tenantID, models.WebhookEventMemberAdded, map[string]string{"id": passive.ID, "role": memberRole})
+
+	return namespace, nil
 }
 
 // RemoveNamespaceUser removes member from a namespace.
@@ -301,12 +719,23 @@ func (s *service) RemoveNamespaceUser(ctx context.Context, tenantID, memberID, u
 		return nil, guard.ErrForbidden
 	}
 
-	removed, err := s.store.NamespaceRemoveMember(ctx, tenantID, member.ID)
+	return s.removeNamespaceMember(ctx, namespace.TenantID, member.ID)
+}
+
+// removeNamespaceMember removes the member with the specified ID from the namespace, uncaching the member's token so
+// it no longer grants access to the namespace.
+func (s *service) removeNamespaceMember(ctx context.Context, tenantID, memberID string) (*models.Namespace, error) {
+	removed, err := s.store.NamespaceRemoveMember(ctx, tenantID, memberID)
 	if err != nil {
 		return nil, err
 	}
 
-	s.AuthUncacheToken(ctx, namespace.TenantID, member.ID) // nolint: errcheck
+	if err := s.AuthUncacheToken(ctx, tenantID, memberID); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithFields(log.Fields{"tenant_id": tenantID, "member_id": memberID}).
+			Warn("unable to uncache the removed member's token")
+	}
 
 	return removed, nil
 }
@@ -367,6 +796,178 @@ func (s *service) EditNamespaceUser(ctx context.Context, tenantID, userID, membe
 	return nil
 }
 
+func (s *service) SetMemberTags(ctx context.Context, tenantID, memberID string, tags []string) error {
+	if len(tags) > models.MemberTagsMax {
+		return NewErrNamespaceMemberInvalid(fmt.Errorf("a member has at most %d tags", models.MemberTagsMax))
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if _, ok := namespace.FindMember(memberID); !ok {
+		return NewErrNamespaceMemberNotFound(memberID, nil)
+	}
+
+	if err := s.store.NamespaceSetMemberTags(ctx, tenantID, memberID, tags); err != nil {
+		if errors.Is(err, store.ErrNoDocuments) {
+			return NewErrNamespaceMemberNotFound(memberID, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) SetMemberAccessSchedule(ctx context.Context, tenantID, memberID string, schedule *models.AccessSchedule) error {
+	if schedule != nil {
+		if err := models.ValidateAccessSchedule(*schedule); err != nil {
+			return NewErrNamespaceMemberInvalid(err)
+		}
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if _, ok := namespace.FindMember(memberID); !ok {
+		return NewErrNamespaceMemberNotFound(memberID, nil)
+	}
+
+	if err := s.store.NamespaceSetMemberAccessSchedule(ctx, tenantID, memberID, schedule); err != nil {
+		if errors.Is(err, store.ErrNoDocuments) {
+			return NewErrNamespaceMemberNotFound(memberID, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) ListNamespaceMembers(ctx context.Context, tenantID, tag string) ([]models.Member, error) {
+	if _, err := s.store.NamespaceGet(ctx, tenantID, false); err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	members, err := s.store.NamespaceListMembers(ctx, tenantID, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err = s.fillMembersData(ctx, members)
+	if err != nil {
+		return nil, NewErrNamespaceMemberFillData(err)
+	}
+
+	return members, nil
+}
+
+func (s *service) ListMemberTags(ctx context.Context, tenantID string) ([]string, error) {
+	if _, err := s.store.NamespaceGet(ctx, tenantID, false); err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	return s.store.NamespaceListMemberTags(ctx, tenantID)
+}
+
+func (s *service) RenewNamespace(ctx context.Context, tenantID string) error {
+	if err := s.store.NamespaceRenew(ctx, tenantID, clock.Now()); err != nil {
+		if errors.Is(err, store.ErrNoDocuments) {
+			return NewErrNamespaceNotFound(tenantID, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// NamespaceActivityInterval is how often the activity on a namespace renews it.
+const NamespaceActivityInterval = time.Hour
+
+func (s *service) RecordNamespaceActivity(ctx context.Context, tenantID string) {
+	key := "namespace-active={" + tenantID + "}"
+
+	var active bool
+	if err := s.cache.Get(ctx, key, &active); err == nil && active {
+		return
+	}
+
+	if err := s.RenewNamespace(ctx, tenantID); err != nil {
+		logger.FromContext(ctx).WithError(err).WithField("tenant_id", tenantID).Warn("Unable to renew the namespace")
+
+		return
+	}
+
+	if err := s.cache.Set(ctx, key, true, NamespaceActivityInterval); err != nil {
+		logger.FromContext(ctx).WithError(err).Info("Unable to set the namespace activity in cache")
+	}
+}
+
+// UsageReportSendInterval is the minimum time between two usage reports of a namespace sent right away.
+const UsageReportSendInterval = 10 * time.Minute
+
+func (s *service) SendNamespaceUsageReport(ctx context.Context, tenantID string, m mailer.Mailer) error {
+	if m == nil {
+		return NewErrNamespaceUsageReportInvalid(errors.New("SMTP server not configured"))
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if namespace.Settings == nil || namespace.Settings.UsageReportEmail == "" {
+		return NewErrNamespaceUsageReportInvalid(errors.New("usage report email not set"))
+	}
+
+	member, err := s.isMemberEmail(ctx, namespace, namespace.Settings.UsageReportEmail)
+	if err != nil {
+		return err
+	}
+
+	if !member {
+		return NewErrNamespaceUsageReportInvalid(errors.New("usage report email does not belong to a member of the namespace"))
+	}
+
+	key := "usage-report-sent={" + tenantID + "}"
+
+	var sent bool
+	if err := s.cache.Get(ctx, key, &sent); err == nil && sent {
+		return NewErrNamespaceUsageReportLimit(nil)
+	}
+
+	stats, err := s.store.GetNamespaceStats(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	// NOTICE: the report is marked as sent before sending it, what keeps the requests made while it's being sent from
+	// sending it again.
+	if err := s.cache.Set(ctx, key, true, UsageReportSendInterval); err != nil {
+		logger.FromContext(ctx).WithError(err).Info("Unable to set the usage report as sent in cache")
+	}
+
+	if err := mailer.SendNamespaceUsageReport(ctx, m, namespace.Settings.UsageReportEmail, &mailer.NamespaceUsageReport{
+		Namespace:   namespace.Name,
+		TenantID:    namespace.TenantID,
+		GeneratedAt: clock.Now(),
+		Stats:       *stats,
+	}); err != nil {
+		if err := s.cache.Delete(ctx, key); err != nil {
+			logger.FromContext(ctx).WithError(err).Info("Unable to unset the usage report as sent in cache")
+		}
+
+		return err
+	}
+
+	return nil
+}
+
 // EditSessionRecordStatus defines if the sessions will be recorded.
 //
 // It receives a context, used to "control" the request flow, a boolean to define if the sessions will be recorded and
@@ -375,6 +976,42 @@ func (s *service) EditSessionRecordStatus(ctx context.Context, sessionRecord boo
 	return s.store.NamespaceSetSessionRecord(ctx, sessionRecord, tenantID)
 }
 
+// SessionRecordResult is the outcome of changing the session record status of a single namespace in a bulk operation.
+type SessionRecordResult struct {
+	TenantID string `json:"tenant_id"`
+	Updated  bool   `json:"updated"`
+	// Error is the reason the namespace wasn't updated, if any.
+	Error string `json:"error,omitempty"`
+}
+
+func (s *service) SetSessionRecordForNamespaces(ctx context.Context, ownerID string, tenants []string, enabled bool) ([]SessionRecordResult, error) {
+	results := make([]SessionRecordResult, 0, len(tenants))
+	for _, tenant := range tenants {
+		result := SessionRecordResult{TenantID: tenant}
+
+		namespace, err := s.store.NamespaceGet(ctx, tenant, false)
+		switch {
+		case err != nil || namespace == nil:
+			result.Error = ErrNamespaceNotFound.Error()
+		case namespace.Owner != ownerID:
+			result.Error = ErrForbidden.Error()
+		default:
+			if err := s.store.NamespaceSetSessionRecord(ctx, enabled, tenant); err != nil {
+				// NOTICE: the store's error is only logged, as its message isn't meant for the clients.
+				logger.FromContext(ctx).WithError(err).WithField("tenant_id", tenant).Error("failed to set the session record")
+
+				result.Error = ErrNamespaceSessionRecord.Error()
+			} else {
+				result.Updated = true
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
 // GetSessionRecord gets the session record data.
 //
 // It receives a context, used to "control" the request flow, the tenant ID from models.Namespace.
@@ -388,3 +1025,54 @@ func (s *service) GetSessionRecord(ctx context.Context, tenantID string) (bool,
 
 	return s.store.NamespaceGetSessionRecord(ctx, tenantID)
 }
+
+func (s *service) ExportNamespaceMembers(ctx context.Context, tenantID string, w io.Writer) error {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil || namespace == nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(NamespaceMembersExportHeader); err != nil {
+		return err
+	}
+
+	for i, member := range namespace.Members {
+		var joined time.Time
+		if member.AddedAt != nil {
+			joined = *member.AddedAt
+		}
+
+		row := []string{DeletedMemberUsername, "", member.Role, format(joined), ""}
+
+		user, _, err := s.store.UserGetByID(ctx, member.ID, false)
+		switch {
+		case err == nil && user != nil:
+			row[0], row[1], row[4] = user.Username, user.Email, format(user.LastLogin)
+		case err != nil && err != store.ErrNoDocuments:
+			return err
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+
+		if (i+1)%namespaceMembersExportFlush == 0 {
+			if writer.Flush(); writer.Error() != nil {
+				return writer.Error()
+			}
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
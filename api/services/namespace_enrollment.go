@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/uuid"
+)
+
+// NamespaceEnrollmentKeyGrace is how long the enrollment key replaced by a rotation is still accepted.
+const NamespaceEnrollmentKeyGrace = 24 * time.Hour
+
+type NamespaceEnrollmentService interface {
+	// RotateNamespaceEnrollmentKey generates a new key to enroll devices in the namespace with the specified tenant
+	// ID, rotated by the user with the specified ID. The previous key is still accepted for
+	// [NamespaceEnrollmentKeyGrace]. The new key is only returned here, as only its hash is kept.
+	//
+	// Only new devices must present the key, so the devices already enrolled keep working.
+	RotateNamespaceEnrollmentKey(ctx context.Context, tenantID, actorID string) (*responses.NamespaceEnrollmentKey, error)
+}
+
+// hashEnrollmentKey returns the hash of the enrollment key kept on the namespace.
+func hashEnrollmentKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *service) RotateNamespaceEnrollmentKey(ctx context.Context, tenantID, actorID string) (*responses.NamespaceEnrollmentKey, error) {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	now := clock.Now()
+	key := uuid.Generate()
+
+	enrollment := &models.NamespaceEnrollmentKey{
+		Hash:      hashEnrollmentKey(key),
+		RotatedAt: now,
+		RotatedBy: actorID,
+	}
+
+	if namespace.EnrollmentKey != nil {
+		expiresAt := now.Add(NamespaceEnrollmentKeyGrace)
+
+		enrollment.PreviousHash = namespace.EnrollmentKey.Hash
+		enrollment.PreviousExpiresAt = &expiresAt
+	}
+
+	if err := s.store.NamespaceEdit(ctx, tenantID, &models.NamespaceChanges{EnrollmentKey: enrollment}); err != nil {
+		return nil, err
+	}
+
+	return &responses.NamespaceEnrollmentKey{
+		EnrollmentKey:     key,
+		PreviousExpiresAt: enrollment.PreviousExpiresAt,
+	}, nil
+}
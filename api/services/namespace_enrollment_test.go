@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmock "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/uuid"
+	uuid_mocks "github.com/shellhub-io/shellhub/pkg/uuid/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateNamespaceEnrollmentKey(t *testing.T) {
+	mock := new(mocks.Store)
+
+	backend := clock.DefaultBackend
+	t.Cleanup(func() {
+		clock.DefaultBackend = backend
+	})
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	uuidMock := new(uuid_mocks.Uuid)
+	uuid.DefaultBackend = uuidMock
+	uuidMock.On("Generate").Return("key")
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	expiresAt := now.Add(NamespaceEnrollmentKeyGrace)
+
+	type Expected struct {
+		key *responses.NamespaceEnrollmentKey
+		err error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when namespace does not exist",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(nil, errors.New("error")).Once()
+			},
+			expected: Expected{nil, NewErrNamespaceNotFound(tenantID, errors.New("error"))},
+		},
+		{
+			description: "fails when the key could not be saved",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("NamespaceEdit", ctx, tenantID, &models.NamespaceChanges{
+					EnrollmentKey: &models.NamespaceEnrollmentKey{Hash: hashEnrollmentKey("key"), RotatedAt: now, RotatedBy: "user"},
+				}).Return(errors.New("error")).Once()
+			},
+			expected: Expected{nil, errors.New("error")},
+		},
+		{
+			description: "succeeds when the namespace has no key",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("NamespaceEdit", ctx, tenantID, &models.NamespaceChanges{
+					EnrollmentKey: &models.NamespaceEnrollmentKey{Hash: hashEnrollmentKey("key"), RotatedAt: now, RotatedBy: "user"},
+				}).Return(nil).Once()
+			},
+			expected: Expected{&responses.NamespaceEnrollmentKey{EnrollmentKey: "key"}, nil},
+		},
+		{
+			description: "succeeds keeping the replaced key for the grace period",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{
+					TenantID:      tenantID,
+					EnrollmentKey: &models.NamespaceEnrollmentKey{Hash: hashEnrollmentKey("old")},
+				}, nil).Once()
+				mock.On("NamespaceEdit", ctx, tenantID, &models.NamespaceChanges{
+					EnrollmentKey: &models.NamespaceEnrollmentKey{
+						Hash:              hashEnrollmentKey("key"),
+						PreviousHash:      hashEnrollmentKey("old"),
+						PreviousExpiresAt: &expiresAt,
+						RotatedAt:         now,
+						RotatedBy:         "user",
+					},
+				}).Return(nil).Once()
+			},
+			expected: Expected{&responses.NamespaceEnrollmentKey{EnrollmentKey: "key", PreviousExpiresAt: &expiresAt}, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			key, err := service.RotateNamespaceEnrollmentKey(ctx, tenantID, "user")
+			assert.Equal(t, tc.expected, Expected{key, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
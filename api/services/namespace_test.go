@@ -2,22 +2,37 @@ package services
 
 import (
 	"context"
+	"encoding/csv"
 	"errors"
+	"fmt"
+	"io"
+	"net/mail"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/robfig/cron/v3"
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/mailer"
+	mailermocks "github.com/shellhub-io/shellhub/api/pkg/mailer/mocks"
+	"github.com/shellhub-io/shellhub/api/pkg/recordstorage"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mocks"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
 	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	mockcache "github.com/shellhub-io/shellhub/pkg/cache/mocks"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmock "github.com/shellhub-io/shellhub/pkg/clock/mocks"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/pkg/uuid"
 	uuid_mocks "github.com/shellhub-io/shellhub/pkg/uuid/mocks"
 	"github.com/shellhub-io/shellhub/pkg/validator"
 	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListNamespaces(t *testing.T) {
@@ -35,6 +50,7 @@ func TestListNamespaces(t *testing.T) {
 		description   string
 		paginator     query.Paginator
 		filters       query.Filters
+		role          string
 		ctx           context.Context
 		requiredMocks func()
 		expected      Expected
@@ -45,7 +61,7 @@ func TestListNamespaces(t *testing.T) {
 			filters:     query.Filters{},
 			ctx:         ctx,
 			requiredMocks: func() {
-				mock.On("NamespaceList", ctx, query.Paginator{Page: 1, PerPage: 10}, query.Filters{}, false).Return(nil, 0, errors.New("error")).Once()
+				mock.On("NamespaceList", ctx, query.Paginator{Page: 1, PerPage: 10}, query.Filters{}, "", false).Return(nil, 0, errors.New("error")).Once()
 			},
 			expected: Expected{
 				namespaces: nil,
@@ -88,7 +104,7 @@ func TestListNamespaces(t *testing.T) {
 					},
 				}
 
-				mock.On("NamespaceList", ctx, query.Paginator{Page: 1, PerPage: 10}, query.Filters{}, false).Return(namespaces, len(namespaces), nil).Once()
+				mock.On("NamespaceList", ctx, query.Paginator{Page: 1, PerPage: 10}, query.Filters{}, "", false).Return(namespaces, len(namespaces), nil).Once()
 				mock.On("UserGetByID", ctx, "hash", false).Return(nil, 0, errors.New("error")).Once()
 			},
 			expected: Expected{
@@ -97,6 +113,38 @@ func TestListNamespaces(t *testing.T) {
 				err:        NewErrNamespaceMemberFillData(NewErrUserNotFound("hash", errors.New("error"))),
 			},
 		},
+		{
+			description: "success to get the namespace list restricted to a role",
+			paginator:   query.Paginator{Page: 1, PerPage: 10},
+			filters:     query.Filters{},
+			role:        guard.RoleOwner,
+			ctx:         ctx,
+			requiredMocks: func() {
+				namespaces := []models.Namespace{
+					{
+						Name:     "group1",
+						Owner:    "hash",
+						TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713",
+						Members:  []models.Member{{ID: "hash", Role: guard.RoleOwner}},
+					},
+				}
+
+				mock.On("NamespaceList", ctx, query.Paginator{Page: 1, PerPage: 10}, query.Filters{}, guard.RoleOwner, false).Return(namespaces, len(namespaces), nil).Once()
+				mock.On("UserGetByID", ctx, "hash", false).Return(&models.User{ID: "hash", UserData: models.UserData{Username: "hash"}}, 0, nil).Once()
+			},
+			expected: Expected{
+				namespaces: []models.Namespace{
+					{
+						Name:     "group1",
+						Owner:    "hash",
+						TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713",
+						Members:  []models.Member{{ID: "hash", Username: "hash", Role: guard.RoleOwner}},
+					},
+				},
+				count: 1,
+				err:   nil,
+			},
+		},
 		{
 			description: "success to get the namespace list",
 			paginator:   query.Paginator{Page: 1, PerPage: 10},
@@ -149,7 +197,7 @@ func TestListNamespaces(t *testing.T) {
 				}
 
 				// TODO: Add mock to fillMembersData what will replace the three call to UserGetByID.
-				mock.On("NamespaceList", ctx, query.Paginator{Page: 1, PerPage: 10}, query.Filters{}, false).Return(namespaces, len(namespaces), nil).Once()
+				mock.On("NamespaceList", ctx, query.Paginator{Page: 1, PerPage: 10}, query.Filters{}, "", false).Return(namespaces, len(namespaces), nil).Once()
 				mock.On("UserGetByID", ctx, "hash", false).Return(user, 0, nil).Once()
 				mock.On("UserGetByID", ctx, "hash2", false).Return(user1, 0, nil).Once()
 				mock.On("UserGetByID", ctx, "hash", false).Return(user, 0, nil).Once()
@@ -213,7 +261,7 @@ func TestListNamespaces(t *testing.T) {
 			tc.requiredMocks()
 
 			services := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
-			nss, count, err := services.ListNamespaces(tc.ctx, tc.paginator, tc.filters, false)
+			nss, count, err := services.ListNamespaces(tc.ctx, tc.paginator, tc.filters, tc.role, false)
 			assert.Equal(t, tc.expected, Expected{nss, count, err})
 		})
 	}
@@ -221,6 +269,62 @@ func TestListNamespaces(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
+func TestLookupNamespace(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	type Expected struct {
+		namespace *models.Namespace
+		err       error
+	}
+
+	cases := []struct {
+		description   string
+		name          string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the namespace is not found",
+			name:        "namespace",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{
+				namespace: nil,
+				err:       NewErrNamespaceNotFound("namespace", store.ErrNoDocuments),
+			},
+		},
+		{
+			description: "succeeds",
+			name:        "namespace",
+			requiredMocks: func() {
+				mock.On("NamespaceGetByName", ctx, "namespace").
+					Return(&models.Namespace{Name: "namespace", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+			},
+			expected: Expected{
+				namespace: &models.Namespace{Name: "namespace", TenantID: "00000000-0000-4000-0000-000000000000"},
+				err:       nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+			namespace, err := service.LookupNamespace(ctx, tc.name)
+			assert.Equal(t, tc.expected, Expected{namespace, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
 func TestGetNamespace(t *testing.T) {
 	mock := new(mocks.Store)
 
@@ -343,6 +447,279 @@ func TestGetNamespace(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
+func TestGetNamespaceSettings(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	type Expected struct {
+		settings *models.NamespaceSettings
+		err      error
+	}
+
+	cases := []struct {
+		description   string
+		tenant        string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when could not get the namespace",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{
+				settings: nil,
+				err:      NewErrNamespaceNotFound("00000000-0000-4000-0000-000000000000", store.ErrNoDocuments),
+			},
+		},
+		{
+			description: "succeeds applying the defaults when the namespace has no settings",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(&models.Namespace{TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+			},
+			expected: Expected{
+				settings: &models.NamespaceSettings{
+					DefaultFirewallPolicy: models.FirewallPolicyAllow,
+					MinRSABits:            models.DefaultMinRSABits,
+					MaxLiveMonitors:       models.DefaultMaxLiveMonitors,
+				},
+				err: nil,
+			},
+		},
+		{
+			description: "succeeds keeping the settings the namespace defines",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(&models.Namespace{
+						TenantID: "00000000-0000-4000-0000-000000000000",
+						Settings: &models.NamespaceSettings{
+							SessionRecord:          true,
+							ConnectionAnnouncement: "welcome",
+							DefaultFirewallPolicy:  models.FirewallPolicyDeny,
+							MinRSABits:             4096,
+							AllowDSA:               true,
+						},
+					}, nil).
+					Once()
+			},
+			expected: Expected{
+				settings: &models.NamespaceSettings{
+					SessionRecord:          true,
+					ConnectionAnnouncement: "welcome",
+					DefaultFirewallPolicy:  models.FirewallPolicyDeny,
+					MinRSABits:             4096,
+					MaxLiveMonitors:        models.DefaultMaxLiveMonitors,
+					AllowDSA:               true,
+				},
+				err: nil,
+			},
+		},
+		{
+			description: "succeeds resolving the minimum RSA size when RSA keys of 1024 bits are allowed",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(&models.Namespace{
+						TenantID: "00000000-0000-4000-0000-000000000000",
+						Settings: &models.NamespaceSettings{AllowRSA1024: true},
+					}, nil).
+					Once()
+			},
+			expected: Expected{
+				settings: &models.NamespaceSettings{
+					DefaultFirewallPolicy: models.FirewallPolicyAllow,
+					MinRSABits:            1024,
+					MaxLiveMonitors:       models.DefaultMaxLiveMonitors,
+					AllowRSA1024:          true,
+				},
+				err: nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			settings, err := service.GetNamespaceSettings(ctx, tc.tenant)
+			assert.Equal(t, tc.expected, Expected{settings, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestUpdateNamespaceSettings(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	type Expected struct {
+		settings *models.NamespaceSettings
+		err      error
+	}
+
+	enabled := true
+	invalid := "reject"
+	bits := 4096
+
+	invalidSchedule := "every monday"
+	_, scheduleErr := cron.ParseStandard(invalidSchedule)
+	invalidEmail := "owner"
+	_, emailErr := mail.ParseAddress(invalidEmail)
+	email := "owner@shellhub.io"
+
+	cases := []struct {
+		description   string
+		req           *requests.NamespaceSettingsUpdate
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the default firewall policy is invalid",
+			req: &requests.NamespaceSettingsUpdate{
+				TenantParam:           requests.TenantParam{Tenant: "00000000-0000-4000-0000-000000000000"},
+				DefaultFirewallPolicy: &invalid,
+			},
+			requiredMocks: func() {},
+			expected: Expected{
+				settings: nil,
+				err:      NewErrNamespaceInvalid(errors.New("invalid default firewall policy")),
+			},
+		},
+		{
+			description: "fails when the usage report schedule is invalid",
+			req: &requests.NamespaceSettingsUpdate{
+				TenantParam:         requests.TenantParam{Tenant: "00000000-0000-4000-0000-000000000000"},
+				UsageReportSchedule: &invalidSchedule,
+			},
+			requiredMocks: func() {},
+			expected: Expected{
+				settings: nil,
+				err:      NewErrNamespaceInvalid(scheduleErr),
+			},
+		},
+		{
+			description: "fails when the usage report email is invalid",
+			req: &requests.NamespaceSettingsUpdate{
+				TenantParam:      requests.TenantParam{Tenant: "00000000-0000-4000-0000-000000000000"},
+				UsageReportEmail: &invalidEmail,
+			},
+			requiredMocks: func() {},
+			expected: Expected{
+				settings: nil,
+				err:      NewErrNamespaceInvalid(emailErr),
+			},
+		},
+		{
+			description: "fails when the usage report email does not belong to a member of the namespace",
+			req: &requests.NamespaceSettingsUpdate{
+				TenantParam:      requests.TenantParam{Tenant: "00000000-0000-4000-0000-000000000000"},
+				UsageReportEmail: &email,
+			},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(&models.Namespace{TenantID: "00000000-0000-4000-0000-000000000000", Members: []models.Member{{ID: "owner"}}}, nil).
+					Once()
+				mock.On("UserGetByEmail", ctx, "owner@shellhub.io").
+					Return(&models.User{ID: "other"}, nil).
+					Once()
+			},
+			expected: Expected{
+				settings: nil,
+				err:      NewErrNamespaceInvalid(errors.New("usage report email must belong to a member of the namespace")),
+			},
+		},
+		{
+			description: "fails when the namespace does not exist",
+			req: &requests.NamespaceSettingsUpdate{
+				TenantParam:   requests.TenantParam{Tenant: "00000000-0000-4000-0000-000000000000"},
+				SessionRecord: &enabled,
+			},
+			requiredMocks: func() {
+				mock.On("NamespaceEdit", ctx, "00000000-0000-4000-0000-000000000000", &models.NamespaceChanges{SessionRecord: &enabled}).
+					Return(store.ErrNoDocuments).
+					Once()
+			},
+			expected: Expected{
+				settings: nil,
+				err:      NewErrNamespaceNotFound("00000000-0000-4000-0000-000000000000", store.ErrNoDocuments),
+			},
+		},
+		{
+			description: "succeeds without changing the namespace when no setting is set",
+			req: &requests.NamespaceSettingsUpdate{
+				TenantParam: requests.TenantParam{Tenant: "00000000-0000-4000-0000-000000000000"},
+			},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(&models.Namespace{TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+			},
+			expected: Expected{
+				settings: &models.NamespaceSettings{
+					DefaultFirewallPolicy: models.FirewallPolicyAllow,
+					MinRSABits:            models.DefaultMinRSABits,
+					MaxLiveMonitors:       models.DefaultMaxLiveMonitors,
+				},
+				err: nil,
+			},
+		},
+		{
+			description: "succeeds changing only the settings set",
+			req: &requests.NamespaceSettingsUpdate{
+				TenantParam:   requests.TenantParam{Tenant: "00000000-0000-4000-0000-000000000000"},
+				SessionRecord: &enabled,
+				MinRSABits:    &bits,
+			},
+			requiredMocks: func() {
+				mock.On("NamespaceEdit", ctx, "00000000-0000-4000-0000-000000000000", &models.NamespaceChanges{SessionRecord: &enabled, MinRSABits: &bits}).
+					Return(nil).
+					Once()
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(&models.Namespace{
+						TenantID: "00000000-0000-4000-0000-000000000000",
+						Settings: &models.NamespaceSettings{
+							SessionRecord:          true,
+							ConnectionAnnouncement: "welcome",
+							MinRSABits:             4096,
+						},
+					}, nil).
+					Once()
+			},
+			expected: Expected{
+				settings: &models.NamespaceSettings{
+					SessionRecord:          true,
+					ConnectionAnnouncement: "welcome",
+					DefaultFirewallPolicy:  models.FirewallPolicyAllow,
+					MinRSABits:             4096,
+					MaxLiveMonitors:        models.DefaultMaxLiveMonitors,
+				},
+				err: nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			settings, err := service.UpdateNamespaceSettings(ctx, tc.req)
+			assert.Equal(t, tc.expected, Expected{settings, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
 func TestSetMemberData(t *testing.T) {
 	mock := new(mocks.Store)
 
@@ -746,49 +1123,152 @@ func TestCreateNamespace(t *testing.T) {
 				}, nil,
 			},
 		},
-	}
-
-	for _, tc := range cases {
-		t.Run(tc.description, func(t *testing.T) {
-			tc.requiredMocks()
-
-			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
-			returnedNamespace, err := service.CreateNamespace(ctx, tc.namespace, tc.ownerID)
-			assert.Equal(t, tc.expected, Expected{returnedNamespace, err})
-		})
-	}
-	mock.AssertExpectations(t)
-}
-
-func TestEditNamespace(t *testing.T) {
-	mock := new(mocks.Store)
-
-	ctx := context.TODO()
-
-	type Expected struct {
-		namespace *models.Namespace
-		err       error
-	}
-
-	cases := []struct {
-		description   string
-		requiredMocks func()
-		tenantID      string
-		namespaceName string
-		expected      Expected
-	}{
 		{
-			description:   "fails when namespace does not exist",
-			tenantID:      "xxxxx",
-			namespaceName: "newname",
-			requiredMocks: func() {
-				mock.On("NamespaceEdit", ctx, "xxxxx", &models.NamespaceChanges{Name: "newname"}).
-					Return(store.ErrNoDocuments).
-					Once()
+			description: "fails when the user reached the namespace limit of its plan",
+			ownerID:     "hash1",
+			namespace: requests.NamespaceCreate{
+				Name:     "namespace",
+				TenantID: "xxxxx",
 			},
-			expected: Expected{
-				nil,
-				NewErrNamespaceNotFound("xxxxx", store.ErrNoDocuments),
+			requiredMocks: func() {
+				user := &models.User{
+					UserData: models.UserData{
+						Name:     "user1",
+						Username: "hash1",
+					},
+					ID:            "hash1",
+					PlanID:        models.PlanFree,
+					MaxNamespaces: -1,
+					Namespaces:    1,
+				}
+
+				mock.On("UserGetByID", ctx, user.ID, false).Return(user, 0, nil).Once()
+			},
+			expected: Expected{
+				nil,
+				NewErrNamespaceLimitReached(1, nil),
+			},
+		},
+		{
+			description: "succeeds using the limits of the user's plan over the user's limits",
+			ownerID:     "hash1",
+			namespace: requests.NamespaceCreate{
+				Name:     "namespace",
+				TenantID: "xxxxx",
+			},
+			requiredMocks: func() {
+				user := &models.User{
+					UserData: models.UserData{
+						Name:     "user1",
+						Username: "hash1",
+					},
+					ID:            "hash1",
+					PlanID:        models.PlanStarter,
+					MaxNamespaces: 1,
+					Namespaces:    1,
+				}
+
+				var isCloud bool
+				planNamespace := &models.Namespace{
+					Name:  strings.ToLower("namespace"),
+					Owner: "hash1",
+					Members: []models.Member{
+						{ID: "hash1", Role: guard.RoleOwner},
+					},
+					Settings:   &models.NamespaceSettings{SessionRecord: true},
+					TenantID:   "xxxxx",
+					MaxDevices: 50,
+				}
+				mock.On("UserGetByID", ctx, user.ID, false).Return(user, 0, nil).Once()
+				mock.On("NamespaceGetByName", ctx, "namespace").Return(nil, nil).Once()
+				mock.On("NamespaceCreate", ctx, planNamespace).Return(nil, nil).Once()
+				envMock.On("Get", "SHELLHUB_CLOUD").Return(strconv.FormatBool(isCloud)).Once()
+			},
+			expected: Expected{
+				&models.Namespace{
+					Name:  strings.ToLower("namespace"),
+					Owner: "hash1",
+					Members: []models.Member{
+						{ID: "hash1", Role: guard.RoleOwner},
+					},
+					Settings:   &models.NamespaceSettings{SessionRecord: true},
+					TenantID:   "xxxxx",
+					MaxDevices: 50,
+				}, nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			returnedNamespace, err := service.CreateNamespace(ctx, tc.namespace, tc.ownerID)
+			assert.Equal(t, tc.expected, Expected{returnedNamespace, err})
+		})
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestEditNamespace(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	invalidPolicy := "reject"
+	denyPolicy := models.FirewallPolicyDeny
+	maxDuration := 60
+	keepalive := true
+	invalidSchedule := &models.AccessSchedule{
+		AllowedWindows: []models.TimeWindow{{DaysOfWeek: []int{1}, StartTime: "18:00", EndTime: "09:00"}},
+	}
+
+	type Expected struct {
+		namespace *models.Namespace
+		err       error
+	}
+
+	cases := []struct {
+		description    string
+		requiredMocks  func()
+		tenantID       string
+		namespaceName  string
+		firewallPolicy *string
+		accessSchedule *models.AccessSchedule
+		maxDuration    *int
+		keepalive      *bool
+		expected       Expected
+	}{
+		{
+			description:   "fails when namespace does not exist",
+			tenantID:      "xxxxx",
+			namespaceName: "newname",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "xxxxx", false).
+					Return(nil, store.ErrNoDocuments).
+					Once()
+			},
+			expected: Expected{
+				nil,
+				NewErrNamespaceNotFound("xxxxx", store.ErrNoDocuments),
+			},
+		},
+		{
+			description:   "fails when the name collides with another namespace's one",
+			tenantID:      "xxxxx",
+			namespaceName: "Taken",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "xxxxx", false).
+					Return(&models.Namespace{TenantID: "xxxxx", Name: "oldname"}, nil).
+					Once()
+				mock.On("NamespaceGetByName", ctx, "taken").
+					Return(&models.Namespace{TenantID: "yyyyy", Name: "taken"}, nil).
+					Once()
+			},
+			expected: Expected{
+				nil,
+				NewErrNamespaceDuplicated(nil),
 			},
 		},
 		{
@@ -796,7 +1276,14 @@ func TestEditNamespace(t *testing.T) {
 			tenantID:      "xxxxx",
 			namespaceName: "newname",
 			requiredMocks: func() {
-				mock.On("NamespaceEdit", ctx, "xxxxx", &models.NamespaceChanges{Name: "newname"}).
+				mock.On("NamespaceGet", ctx, "xxxxx", false).
+					Return(&models.Namespace{TenantID: "xxxxx", Name: "oldname"}, nil).
+					Once()
+				mock.On("NamespaceGetByName", ctx, "newname").
+					Return(nil, store.ErrNoDocuments).
+					Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("NamespaceEdit", ctx, "xxxxx", &models.NamespaceChanges{Name: "newname", PreviousName: &models.NamespacePreviousName{Name: "oldname", ChangedAt: now}}).
 					Return(errors.New("error")).
 					Once()
 			},
@@ -810,6 +1297,9 @@ func TestEditNamespace(t *testing.T) {
 			namespaceName: "newName",
 			tenantID:      "xxxxx",
 			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "xxxxx", false).
+					Return(&models.Namespace{TenantID: "xxxxx", Name: "newname"}, nil).
+					Once()
 				mock.On("NamespaceEdit", ctx, "xxxxx", &models.NamespaceChanges{Name: "newname"}).
 					Return(nil).
 					Once()
@@ -836,7 +1326,14 @@ func TestEditNamespace(t *testing.T) {
 			namespaceName: "newname",
 			tenantID:      "xxxxx",
 			requiredMocks: func() {
-				mock.On("NamespaceEdit", ctx, "xxxxx", &models.NamespaceChanges{Name: "newname"}).
+				mock.On("NamespaceGet", ctx, "xxxxx", false).
+					Return(&models.Namespace{TenantID: "xxxxx", Name: "oldname"}, nil).
+					Once()
+				mock.On("NamespaceGetByName", ctx, "newname").
+					Return(nil, store.ErrNoDocuments).
+					Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("NamespaceEdit", ctx, "xxxxx", &models.NamespaceChanges{Name: "newname", PreviousName: &models.NamespacePreviousName{Name: "oldname", ChangedAt: now}}).
 					Return(nil).
 					Once()
 
@@ -857,6 +1354,83 @@ func TestEditNamespace(t *testing.T) {
 				nil,
 			},
 		},
+		{
+			description:    "fails when the default firewall policy is invalid",
+			tenantID:       "xxxxx",
+			firewallPolicy: &invalidPolicy,
+			requiredMocks:  func() {},
+			expected: Expected{
+				nil,
+				NewErrNamespaceInvalid(errors.New("invalid default firewall policy")),
+			},
+		},
+		{
+			description:    "fails when the access schedule is invalid",
+			tenantID:       "xxxxx",
+			accessSchedule: invalidSchedule,
+			requiredMocks:  func() {},
+			expected: Expected{
+				nil,
+				NewErrNamespaceInvalid(models.ErrAccessScheduleWindow),
+			},
+		},
+		{
+			description:    "succeeds changing the default firewall policy",
+			tenantID:       "xxxxx",
+			firewallPolicy: &denyPolicy,
+			requiredMocks: func() {
+				mock.On("NamespaceEdit", ctx, "xxxxx", &models.NamespaceChanges{DefaultFirewallPolicy: &denyPolicy}).
+					Return(nil).
+					Once()
+
+				namespace := &models.Namespace{
+					TenantID: "xxxxx",
+					Name:     "namespace",
+					Settings: &models.NamespaceSettings{DefaultFirewallPolicy: models.FirewallPolicyDeny},
+				}
+
+				mock.On("NamespaceGet", ctx, "xxxxx", true).
+					Return(namespace, nil).
+					Once()
+			},
+			expected: Expected{
+				&models.Namespace{
+					TenantID: "xxxxx",
+					Name:     "namespace",
+					Settings: &models.NamespaceSettings{DefaultFirewallPolicy: models.FirewallPolicyDeny},
+				},
+				nil,
+			},
+		},
+		{
+			description: "succeeds changing the maximum session duration",
+			tenantID:    "xxxxx",
+			maxDuration: &maxDuration,
+			keepalive:   &keepalive,
+			requiredMocks: func() {
+				mock.On("NamespaceEdit", ctx, "xxxxx", &models.NamespaceChanges{MaxSessionDurationMinutes: &maxDuration, MaxSessionKeepaliveEnabled: &keepalive}).
+					Return(nil).
+					Once()
+
+				namespace := &models.Namespace{
+					TenantID: "xxxxx",
+					Name:     "namespace",
+					Settings: &models.NamespaceSettings{MaxSessionDurationMinutes: 60, MaxSessionKeepaliveEnabled: true},
+				}
+
+				mock.On("NamespaceGet", ctx, "xxxxx", true).
+					Return(namespace, nil).
+					Once()
+			},
+			expected: Expected{
+				&models.Namespace{
+					TenantID: "xxxxx",
+					Name:     "namespace",
+					Settings: &models.NamespaceSettings{MaxSessionDurationMinutes: 60, MaxSessionKeepaliveEnabled: true},
+				},
+				nil,
+			},
+		},
 	}
 	for _, tc := range cases {
 		t.Run(tc.description, func(t *testing.T) {
@@ -868,6 +1442,10 @@ func TestEditNamespace(t *testing.T) {
 				TenantParam: requests.TenantParam{Tenant: tc.tenantID},
 				Name:        tc.namespaceName,
 			}
+			req.Settings.DefaultFirewallPolicy = tc.firewallPolicy
+			req.Settings.AccessSchedule = tc.accessSchedule
+			req.Settings.MaxSessionDurationMinutes = tc.maxDuration
+			req.Settings.MaxSessionKeepaliveEnabled = tc.keepalive
 			namespace, err := service.EditNamespace(ctx, req)
 
 			assert.Equal(t, tc.expected, Expected{namespace, err})
@@ -877,121 +1455,389 @@ func TestEditNamespace(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
-func TestDeleteNamespace(t *testing.T) {
+func TestRequestNamespaceDeletion(t *testing.T) {
 	mock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	uuidMock := new(uuid_mocks.Uuid)
+	uuid.DefaultBackend = uuidMock
+	uuidMock.On("Generate").Return("token")
 
 	ctx := context.TODO()
 
+	namespace := &models.Namespace{Name: "namespace", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713"}
+
+	type Expected struct {
+		deletion *responses.NamespaceDeletionRequest
+		err      error
+	}
+
 	cases := []struct {
 		description   string
-		namespace     *models.Namespace
-		requiredMocks func(namespace *models.Namespace)
-		expected      error
+		tenantID      string
+		requiredMocks func()
+		expected      Expected
 	}{
 		{
 			description: "fails when namespace does not exist",
-			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
-			requiredMocks: func(namespace *models.Namespace) {
-				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(nil, errors.New("error")).Once()
+			tenantID:    namespace.TenantID,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, false).Return(nil, errors.New("error")).Once()
 			},
-			expected: NewErrNamespaceNotFound("a736a52b-5777-4f92-b0b8-e359bf484713", errors.New("error")),
+			expected: Expected{nil, NewErrNamespaceNotFound(namespace.TenantID, errors.New("error"))},
 		},
 		{
-			description: "fails when store delete fails",
-			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
-			requiredMocks: func(namespace *models.Namespace) {
-				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
-				envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
-				envMock.On("Get", "SHELLHUB_BILLING").Return("false").Once()
-				mock.On("NamespaceDelete", ctx, namespace.TenantID).Return(errors.New("error")).Once()
+			description: "fails when the token could not be cached",
+			tenantID:    namespace.TenantID,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, false).Return(namespace, nil).Once()
+				cacheMock.
+					On("Set", ctx, "namespace-deletion={token}", &namespaceDeletion{TenantID: namespace.TenantID, ExpiresAt: now.Add(NamespaceDeletionTTL)}, NamespaceDeletionTTL).
+					Return(errors.New("error")).
+					Once()
 			},
-			expected: errors.New("error"),
+			expected: Expected{nil, errors.New("error")},
 		},
 		{
 			description: "succeeds",
-			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
-			requiredMocks: func(namespace *models.Namespace) {
-				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
-				envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
-				envMock.On("Get", "SHELLHUB_BILLING").Return("false").Once()
-				mock.On("NamespaceDelete", ctx, namespace.TenantID).Return(nil).Once()
-			},
-			expected: nil,
-		},
-		{
-			description: "reports delete",
-			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
-			requiredMocks: func(namespace *models.Namespace) {
-				user1 := &models.User{
-					UserData: models.UserData{
-						Name:     "user1",
-						Username: "user1",
-						Email:    "user1@email.com",
-					},
-					ID: "ID1",
-				}
-
-				ns := &models.Namespace{
-					TenantID: namespace.TenantID,
-					Owner:    user1.ID,
-					Members: []models.Member{
-						{ID: user1.ID, Role: guard.RoleOwner},
-					},
-					Billing: &models.Billing{
-						Active: true,
-					},
-					MaxDevices: -1,
-				}
-				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(ns, nil).Once()
-				envMock.On("Get", "SHELLHUB_CLOUD").Return(strconv.FormatBool(true)).Once()
-				envMock.On("Get", "SHELLHUB_BILLING").Return(strconv.FormatBool(true)).Once()
-				clientMock.On("ReportDelete", ns).Return(200, nil).Once()
-				mock.On("NamespaceDelete", ctx, namespace.TenantID).Return(nil).Once()
+			tenantID:    namespace.TenantID,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, false).Return(namespace, nil).Once()
+				cacheMock.
+					On("Set", ctx, "namespace-deletion={token}", &namespaceDeletion{TenantID: namespace.TenantID, ExpiresAt: now.Add(NamespaceDeletionTTL)}, NamespaceDeletionTTL).
+					Return(nil).
+					Once()
 			},
-			expected: nil,
+			expected: Expected{&responses.NamespaceDeletionRequest{ConfirmationToken: "token", ExpiresAt: now.Add(NamespaceDeletionTTL)}, nil},
 		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.description, func(t *testing.T) {
-			tc.requiredMocks(tc.namespace)
+			tc.requiredMocks()
 
-			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
-			err := service.DeleteNamespace(ctx, tc.namespace.TenantID)
-			assert.Equal(t, tc.expected, err)
+			service := NewService(store.Store(mock), privateKey, publicKey, cacheMock, clientMock, nil)
+			deletion, err := service.RequestNamespaceDeletion(ctx, tc.tenantID)
+			assert.Equal(t, tc.expected, Expected{deletion, err})
 		})
 	}
 
 	mock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
 }
 
-func TestAddNamespaceUser(t *testing.T) {
+func TestDeleteNamespace(t *testing.T) {
 	mock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
 
 	ctx := context.TODO()
 
-	type Expected struct {
-		namespace *models.Namespace
-		err       error
+	// issued mocks the cache returning the deletion issued for tenantID, expiring at expiresAt.
+	issued := func(tenantID string, expiresAt time.Time) func(testifymock.Arguments) {
+		return func(args testifymock.Arguments) {
+			*args.Get(2).(*namespaceDeletion) = namespaceDeletion{TenantID: tenantID, ExpiresAt: expiresAt}
+		}
 	}
 
 	cases := []struct {
 		description   string
-		TenantID      string
-		Username      string
-		Role          string
-		ID            string
-		RequiredMocks func()
-		Expected      Expected
+		namespace     *models.Namespace
+		token         string
+		text          string
+		requiredMocks func(namespace *models.Namespace)
+		expected      error
 	}{
 		{
-			description: "fails when MemberID is not valid",
-			Username:    "",
-			Role:        guard.RoleObserver,
-			ID:          "ID1",
-			TenantID:    "a736a52b-5777-4f92-b0b8-e359bf484713",
-			RequiredMocks: func() {
-			},
+			description: "fails when namespace does not exist",
+			token:       "token",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(nil, errors.New("error")).Once()
+			},
+			expected: NewErrNamespaceNotFound("a736a52b-5777-4f92-b0b8-e359bf484713", errors.New("error")),
+		},
+		{
+			description: "fails without a token when deleting without it isn't allowed",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+				envMock.On("Get", "SHELLHUB_NAMESPACE_DELETION_WITHOUT_TOKEN").Return("").Once()
+			},
+			expected: NewErrNamespaceDeletionInvalid(nil),
+		},
+		{
+			description: "succeeds without a token when deleting without it is allowed",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+				envMock.On("Get", "SHELLHUB_NAMESPACE_DELETION_WITHOUT_TOKEN").Return("true").Once()
+				envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
+				mock.On("SessionListUIDs", ctx, namespace.TenantID).Return([]models.UID{}, nil).Once()
+				mock.On("NamespaceDelete", ctx, namespace.TenantID).Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "fails when the token was not issued",
+			token:       "token",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+				cacheMock.On("Get", ctx, "namespace-deletion={token}", testifymock.Anything).Return(nil).Once()
+			},
+			expected: NewErrNamespaceDeletionInvalid(nil),
+		},
+		{
+			description: "fails when the token was issued to another namespace",
+			token:       "token",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+				cacheMock.
+					On("Get", ctx, "namespace-deletion={token}", testifymock.Anything).
+					Run(issued("00000000-0000-4000-0000-000000000000", now.Add(time.Minute))).
+					Return(nil).
+					Once()
+			},
+			expected: NewErrNamespaceDeletionInvalid(nil),
+		},
+		{
+			description: "fails when the token has expired",
+			token:       "token",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+				cacheMock.
+					On("Get", ctx, "namespace-deletion={token}", testifymock.Anything).
+					Run(issued(namespace.TenantID, now)).
+					Return(nil).
+					Once()
+			},
+			expected: NewErrNamespaceDeletionExpired(nil),
+		},
+		{
+			description: "fails when the confirmation text does not match the namespace's name",
+			token:       "token",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			text:        "othername",
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+				cacheMock.
+					On("Get", ctx, "namespace-deletion={token}", testifymock.Anything).
+					Run(issued(namespace.TenantID, now.Add(time.Minute))).
+					Return(nil).
+					Once()
+			},
+			expected: NewErrNamespaceDeletionMismatch(nil),
+		},
+		{
+			description: "fails when store delete fails",
+			token:       "token",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+				cacheMock.
+					On("Get", ctx, "namespace-deletion={token}", testifymock.Anything).
+					Run(issued(namespace.TenantID, now.Add(time.Minute))).
+					Return(nil).
+					Once()
+				envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
+				mock.On("SessionListUIDs", ctx, namespace.TenantID).Return([]models.UID{}, nil).Once()
+				mock.On("NamespaceDelete", ctx, namespace.TenantID).Return(errors.New("error")).Once()
+			},
+			expected: errors.New("error"),
+		},
+		{
+			description: "fails when a recording of the namespace can't be deleted",
+			token:       "token",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+				cacheMock.
+					On("Get", ctx, "namespace-deletion={token}", testifymock.Anything).
+					Run(issued(namespace.TenantID, now.Add(time.Minute))).
+					Return(nil).
+					Once()
+				envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
+				mock.On("SessionListUIDs", ctx, namespace.TenantID).Return([]models.UID{"session"}, nil).Once()
+				mock.On("SessionDeleteRecordFrames", ctx, models.UID("session")).Return(errors.New("error")).Once()
+			},
+			expected: errors.New("error"),
+		},
+		{
+			description: "succeeds",
+			token:       "token",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+				cacheMock.
+					On("Get", ctx, "namespace-deletion={token}", testifymock.Anything).
+					Run(issued(namespace.TenantID, now.Add(time.Minute))).
+					Return(nil).
+					Once()
+				envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
+				mock.On("SessionListUIDs", ctx, namespace.TenantID).Return([]models.UID{"session"}, nil).Once()
+				mock.On("SessionDeleteRecordFrames", ctx, models.UID("session")).Return(nil).Once()
+				mock.On("NamespaceDelete", ctx, namespace.TenantID).Return(nil).Once()
+				cacheMock.On("Delete", ctx, "namespace-deletion={token}").Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds when the confirmation text matches the namespace's name",
+			token:       "token",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			text:        "oldname",
+			requiredMocks: func(namespace *models.Namespace) {
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+				cacheMock.
+					On("Get", ctx, "namespace-deletion={token}", testifymock.Anything).
+					Run(issued(namespace.TenantID, now.Add(time.Minute))).
+					Return(nil).
+					Once()
+				envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
+				mock.On("SessionListUIDs", ctx, namespace.TenantID).Return([]models.UID{}, nil).Once()
+				mock.On("NamespaceDelete", ctx, namespace.TenantID).Return(nil).Once()
+				cacheMock.On("Delete", ctx, "namespace-deletion={token}").Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "reports delete",
+			token:       "token",
+			namespace:   &models.Namespace{Name: "oldname", Owner: "ID1", TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713", Members: []models.Member{{ID: "user1", Role: guard.RoleOwner}}},
+			requiredMocks: func(namespace *models.Namespace) {
+				user1 := &models.User{
+					UserData: models.UserData{
+						Name:     "user1",
+						Username: "user1",
+						Email:    "user1@email.com",
+					},
+					ID: "ID1",
+				}
+
+				ns := &models.Namespace{
+					TenantID: namespace.TenantID,
+					Owner:    user1.ID,
+					Members: []models.Member{
+						{ID: user1.ID, Role: guard.RoleOwner},
+					},
+					Billing: &models.Billing{
+						Active:         true,
+						CustomerID:     "cus_test",
+						SubscriptionID: "sub_test",
+					},
+					MaxDevices: -1,
+				}
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(ns, nil).Once()
+				cacheMock.
+					On("Get", ctx, "namespace-deletion={token}", testifymock.Anything).
+					Run(issued(namespace.TenantID, now.Add(time.Minute))).
+					Return(nil).
+					Once()
+				envMock.On("Get", "SHELLHUB_CLOUD").Return(strconv.FormatBool(true)).Once()
+				envMock.On("Get", "SHELLHUB_BILLING").Return(strconv.FormatBool(true)).Once()
+				cacheMock.On("Get", ctx, "billing:status:{"+namespace.TenantID+"}", testifymock.Anything).Return(nil).Once()
+				cacheMock.
+					On("Set", ctx, "billing:status:{"+namespace.TenantID+"}", &cachedBillingStatus{TenantID: namespace.TenantID, Reportable: true}, BillingStatusCacheTTL).
+					Return(nil).
+					Once()
+				clientMock.On("BillingReport", namespace.TenantID, ReportNamespaceDelete).Return(200, nil).Once()
+				mock.On("SessionListUIDs", ctx, namespace.TenantID).Return([]models.UID{}, nil).Once()
+				mock.On("NamespaceDelete", ctx, namespace.TenantID).Return(nil).Once()
+				cacheMock.On("Delete", ctx, "namespace-deletion={token}").Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks(tc.namespace)
+
+			service := NewService(store.Store(mock), privateKey, publicKey, cacheMock, clientMock, nil)
+			err := service.DeleteNamespace(ctx, tc.namespace.TenantID, tc.token, tc.text)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+	clientMock.AssertExpectations(t)
+}
+
+func TestExpireNamespace(t *testing.T) {
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{Name: "namespace", TenantID: "00000000-0000-4000-0000-000000000000"}
+
+	t.Run("fails when the namespace does not exist", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(nil, store.ErrNoDocuments).Once()
+
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+		assert.Equal(t, NewErrNamespaceNotFound(namespace.TenantID, store.ErrNoDocuments), service.ExpireNamespace(ctx, namespace.TenantID))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("succeeds deleting the namespace and its recordings", func(t *testing.T) {
+		storage, err := recordstorage.NewFileSystemRecordingStorage(t.TempDir())
+		require.NoError(t, err)
+		require.NoError(t, storage.Store(ctx, "session", []models.RecordedSession{{UID: "session", Message: "message"}}))
+
+		mock := new(mocks.Store)
+		mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+		envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
+		mock.On("SessionListUIDs", ctx, namespace.TenantID).Return([]models.UID{"session"}, nil).Once()
+		mock.On("NamespaceDelete", ctx, namespace.TenantID).Return(nil).Once()
+
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithRecordingStorage(storage))
+		assert.NoError(t, service.ExpireNamespace(ctx, namespace.TenantID))
+
+		frames, err := storage.Retrieve(ctx, "session")
+		assert.NoError(t, err)
+		assert.Empty(t, frames)
+
+		mock.AssertExpectations(t)
+	})
+}
+
+func TestAddNamespaceUser(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	type Expected struct {
+		namespace *models.Namespace
+		err       error
+	}
+
+	cases := []struct {
+		description   string
+		TenantID      string
+		Username      string
+		Role          string
+		ID            string
+		RequiredMocks func()
+		Expected      Expected
+	}{
+		{
+			description: "fails when MemberID is not valid",
+			Username:    "",
+			Role:        guard.RoleObserver,
+			ID:          "ID1",
+			TenantID:    "a736a52b-5777-4f92-b0b8-e359bf484713",
+			RequiredMocks: func() {
+			},
 			Expected: Expected{
 				namespace: nil,
 				err:       NewErrNamespaceMemberInvalid(validator.ErrStructureInvalid),
@@ -1163,6 +2009,54 @@ func TestAddNamespaceUser(t *testing.T) {
 				err:       NewErrNamespaceMemberDuplicated("ID2", nil),
 			},
 		},
+		{
+			description: "fails when the namespace reached the members limit of its owner's plan",
+			Username:    "user4",
+			Role:        guard.RoleObserver,
+			ID:          "ID1",
+			TenantID:    "a736a52b-5777-4f92-b0b8-e359bf484713",
+			RequiredMocks: func() {
+				namespace := &models.Namespace{
+					Name:     "group1",
+					Owner:    "ID1",
+					TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713",
+					Members: []models.Member{
+						{ID: "ID1", Role: guard.RoleOwner},
+						{ID: "ID2", Role: guard.RoleObserver},
+						{ID: "ID3", Role: guard.RoleObserver},
+					},
+				}
+
+				user1 := &models.User{
+					UserData: models.UserData{
+						Name:     "user1",
+						Username: "user1",
+						Email:    "user1@email.com",
+					},
+					ID:     "ID1",
+					PlanID: models.PlanFree,
+				}
+
+				user4 := &models.User{
+					UserData: models.UserData{
+						Name:     "user4",
+						Username: "user4",
+						Email:    "user4@email.com",
+					},
+					ID: "ID4",
+				}
+
+				mock.On("NamespaceGet", ctx, namespace.TenantID, true).Return(namespace, nil).Once()
+
+				mock.On("UserGetByID", ctx, user1.ID, false).Return(user1, 0, nil).Once()
+				mock.On("UserGetByUsername", ctx, user4.Username).Return(user4, nil).Once()
+				mock.On("UserGetByID", ctx, namespace.Owner, false).Return(user1, 0, nil).Once()
+			},
+			Expected: Expected{
+				namespace: nil,
+				err:       NewErrNamespaceMembersLimitReached(3, nil),
+			},
+		},
 		{
 			description: "succeeds",
 			Username:    "user2",
@@ -1211,6 +2105,7 @@ func TestAddNamespaceUser(t *testing.T) {
 
 				mock.On("UserGetByID", ctx, user1.ID, false).Return(user1, 0, nil).Once()
 				mock.On("UserGetByUsername", ctx, user2.Username).Return(user2, nil).Once()
+				mock.On("UserGetByID", ctx, namespace.Owner, false).Return(user1, 0, nil).Once()
 
 				mock.On("NamespaceAddMember", ctx, namespace.TenantID, user2.ID, guard.RoleObserver).Return(namespaceTwoMembers, nil).Once()
 			},
@@ -1807,104 +2702,717 @@ func TestEditNamespaceUser(t *testing.T) {
 			},
 			Expected: guard.ErrForbidden,
 		},
-		{
-			description:   "fails when user store function fails",
-			TenantID:      "a736a52b-5777-4f92-b0b8-e359bf484717",
-			UserID:        "activeMemberID",
-			MemberID:      "passiveMemberID",
-			MemberNewRole: guard.RoleOperator,
-			RequiredMocks: func() {
-				activeMember := &models.User{
-					UserData: models.UserData{
-						Name:     "activeMemberName",
-						Username: "activeMemberUsername",
-					},
-					ID: "activeMemberID",
-				}
+		{
+			description:   "fails when user store function fails",
+			TenantID:      "a736a52b-5777-4f92-b0b8-e359bf484717",
+			UserID:        "activeMemberID",
+			MemberID:      "passiveMemberID",
+			MemberNewRole: guard.RoleOperator,
+			RequiredMocks: func() {
+				activeMember := &models.User{
+					UserData: models.UserData{
+						Name:     "activeMemberName",
+						Username: "activeMemberUsername",
+					},
+					ID: "activeMemberID",
+				}
+
+				passiveMember := &models.User{
+					UserData: models.UserData{
+						Name:     "passiveMemberName",
+						Username: "passiveMemberUsername",
+					},
+					ID: "passiveMemberID",
+				}
+
+				namespaceActivePassive := &models.Namespace{
+					Name:     "group1",
+					Owner:    "activeMemberID",
+					TenantID: "a736a52b-5777-4f92-b0b8-e359bf484717",
+					Members: []models.Member{
+						{ID: "ownerID", Role: guard.RoleOwner},
+						{ID: "activeMemberID", Role: guard.RoleAdministrator},
+						{ID: "passiveMemberID", Role: guard.RoleObserver},
+					},
+				}
+
+				mock.On("NamespaceGet", ctx, namespaceActivePassive.TenantID, true).Return(namespaceActivePassive, nil).Once()
+
+				mock.On("UserGetByID", ctx, passiveMember.ID, false).Return(passiveMember, 0, nil).Once()
+				mock.On("UserGetByID", ctx, activeMember.ID, false).Return(activeMember, 0, nil).Once()
+
+				mock.On("NamespaceEditMember", ctx, namespaceActivePassive.TenantID, passiveMember.ID, guard.RoleOperator).Return(errors.New("error")).Once()
+			},
+			Expected: errors.New("error"),
+		},
+		{
+			description:   "Success",
+			TenantID:      "a736a52b-5777-4f92-b0b8-e359bf484717",
+			UserID:        "activeMemberID",
+			MemberID:      "passiveMemberID",
+			MemberNewRole: guard.RoleOperator,
+			RequiredMocks: func() {
+				namespaceActivePassive := &models.Namespace{
+					Name:     "group1",
+					Owner:    "activeMemberID",
+					TenantID: "a736a52b-5777-4f92-b0b8-e359bf484717",
+					Members: []models.Member{
+						{ID: "ownerID", Role: guard.RoleOwner},
+						{ID: "activeMemberID", Role: guard.RoleAdministrator},
+						{ID: "passiveMemberID", Role: guard.RoleObserver},
+					},
+				}
+
+				activeMember := &models.User{
+					UserData: models.UserData{
+						Name:     "activeMemberName",
+						Username: "activeMemberUsername",
+					},
+					ID: "activeMemberID",
+				}
+
+				passiveMember := &models.User{
+					UserData: models.UserData{
+						Name:     "passiveMemberName",
+						Username: "passiveMemberUsername",
+					},
+					ID: "passiveMemberID",
+				}
+
+				mock.On("NamespaceGet", ctx, namespaceActivePassive.TenantID, true).Return(namespaceActivePassive, nil).Once()
+
+				mock.On("UserGetByID", ctx, passiveMember.ID, false).Return(passiveMember, 0, nil).Once()
+				mock.On("UserGetByID", ctx, activeMember.ID, false).Return(activeMember, 0, nil).Once()
+
+				mock.On("NamespaceEditMember", ctx, namespaceActivePassive.TenantID, passiveMember.ID, guard.RoleOperator).Return(nil).Once()
+			},
+			Expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.RequiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := service.EditNamespaceUser(ctx, tc.TenantID, tc.UserID, tc.MemberID, tc.MemberNewRole)
+			assert.Equal(t, tc.Expected, err)
+		})
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestSetDefaultEnvVars(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	tooMany := make(map[string]string)
+	for i := 0; i <= models.DefaultEnvVarsMax; i++ {
+		tooMany["VAR_"+strconv.Itoa(i)] = "value"
+	}
+
+	cases := []struct {
+		description   string
+		vars          map[string]string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description:   "fails when there are too many variables",
+			vars:          tooMany,
+			requiredMocks: func() {},
+			expected:      NewErrNamespaceInvalid(errors.New("at most 32 default environment variables are allowed")),
+		},
+		{
+			description:   "fails when a variable is not allowed",
+			vars:          map[string]string{"LD_PRELOAD": "/tmp/lib.so"},
+			requiredMocks: func() {},
+			expected:      NewErrNamespaceInvalid(fmt.Errorf("%s: %w", "LD_PRELOAD", models.ErrEnvVarSensitive)),
+		},
+		{
+			description: "fails when the namespace does not exist",
+			vars:        map[string]string{"TERM": "xterm-256color"},
+			requiredMocks: func() {
+				mock.On("NamespaceEdit", ctx, "00000000-0000-4000-0000-000000000000", &models.NamespaceChanges{DefaultEnvVars: &map[string]string{"TERM": "xterm-256color"}}).
+					Return(store.ErrNoDocuments).
+					Once()
+			},
+			expected: NewErrNamespaceNotFound("00000000-0000-4000-0000-000000000000", store.ErrNoDocuments),
+		},
+		{
+			description: "succeeds clearing the variables",
+			vars:        nil,
+			requiredMocks: func() {
+				mock.On("NamespaceEdit", ctx, "00000000-0000-4000-0000-000000000000", &models.NamespaceChanges{DefaultEnvVars: &map[string]string{}}).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds",
+			vars:        map[string]string{"TERM": "xterm-256color"},
+			requiredMocks: func() {
+				mock.On("NamespaceEdit", ctx, "00000000-0000-4000-0000-000000000000", &models.NamespaceChanges{DefaultEnvVars: &map[string]string{"TERM": "xterm-256color"}}).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := service.SetDefaultEnvVars(ctx, "00000000-0000-4000-0000-000000000000", tc.vars)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSetMemberTags(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "507f1f77bcf86cd799439011", Role: guard.RoleOwner},
+			{ID: "6509e169ae6144b2f56bf288", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		description   string
+		tenant        string
+		member        string
+		tags          []string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description:   "fails when there are too many tags",
+			tenant:        "00000000-0000-4000-0000-000000000000",
+			member:        "6509e169ae6144b2f56bf288",
+			tags:          []string{"t01", "t02", "t03", "t04", "t05", "t06", "t07", "t08", "t09", "t10", "t11"},
+			requiredMocks: func() {},
+			expected:      NewErrNamespaceMemberInvalid(errors.New("a member has at most 10 tags")),
+		},
+		{
+			description: "fails when the namespace does not exist",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			member:      "6509e169ae6144b2f56bf288",
+			tags:        []string{"team:infra"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: NewErrNamespaceNotFound("00000000-0000-4000-0000-000000000000", store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the member is not in the namespace",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			member:      "000000000000000000000000",
+			tags:        []string{"team:infra"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).Return(namespace, nil).Once()
+			},
+			expected: NewErrNamespaceMemberNotFound("000000000000000000000000", nil),
+		},
+		{
+			description: "fails when the tags could not be set",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			member:      "6509e169ae6144b2f56bf288",
+			tags:        []string{"team:infra"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).Return(namespace, nil).Once()
+				mock.On("NamespaceSetMemberTags", ctx, "00000000-0000-4000-0000-000000000000", "6509e169ae6144b2f56bf288", []string{"team:infra"}).
+					Return(errors.New("error")).
+					Once()
+			},
+			expected: errors.New("error"),
+		},
+		{
+			description: "succeeds",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			member:      "6509e169ae6144b2f56bf288",
+			tags:        []string{"team:infra", "region:eu"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).Return(namespace, nil).Once()
+				mock.On("NamespaceSetMemberTags", ctx, "00000000-0000-4000-0000-000000000000", "6509e169ae6144b2f56bf288", []string{"team:infra", "region:eu"}).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := service.SetMemberTags(ctx, tc.tenant, tc.member, tc.tags)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSetMemberAccessSchedule(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "507f1f77bcf86cd799439011", Role: guard.RoleOwner},
+			{ID: "6509e169ae6144b2f56bf288", Role: guard.RoleObserver},
+		},
+	}
+
+	schedule := &models.AccessSchedule{
+		Timezone:       "Europe/Berlin",
+		AllowedWindows: []models.TimeWindow{{DaysOfWeek: []int{1, 2, 3, 4, 5}, StartTime: "08:00", EndTime: "17:00"}},
+	}
+
+	cases := []struct {
+		description   string
+		member        string
+		schedule      *models.AccessSchedule
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description:   "fails when the schedule is invalid",
+			member:        "6509e169ae6144b2f56bf288",
+			schedule:      &models.AccessSchedule{Timezone: "Mars/Olympus"},
+			requiredMocks: func() {},
+			expected:      NewErrNamespaceMemberInvalid(models.ValidateAccessSchedule(models.AccessSchedule{Timezone: "Mars/Olympus"})),
+		},
+		{
+			description: "fails when the member is not in the namespace",
+			member:      "000000000000000000000000",
+			schedule:    schedule,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).Return(namespace, nil).Once()
+			},
+			expected: NewErrNamespaceMemberNotFound("000000000000000000000000", nil),
+		},
+		{
+			description: "succeeds to set the schedule",
+			member:      "6509e169ae6144b2f56bf288",
+			schedule:    schedule,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).Return(namespace, nil).Once()
+				mock.On("NamespaceSetMemberAccessSchedule", ctx, "00000000-0000-4000-0000-000000000000", "6509e169ae6144b2f56bf288", schedule).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds to remove the schedule",
+			member:      "6509e169ae6144b2f56bf288",
+			schedule:    nil,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).Return(namespace, nil).Once()
+				mock.On("NamespaceSetMemberAccessSchedule", ctx, "00000000-0000-4000-0000-000000000000", "6509e169ae6144b2f56bf288", (*models.AccessSchedule)(nil)).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := service.SetMemberAccessSchedule(ctx, "00000000-0000-4000-0000-000000000000", tc.member, tc.schedule)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListNamespaceMembers(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	type Expected struct {
+		members []models.Member
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		tenant        string
+		tag           string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the namespace does not exist",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			tag:         "team:infra",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{
+				members: nil,
+				err:     NewErrNamespaceNotFound("00000000-0000-4000-0000-000000000000", store.ErrNoDocuments),
+			},
+		},
+		{
+			description: "succeeds filling the data of the members with the tag",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			tag:         "team:infra",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(&models.Namespace{TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+				mock.On("NamespaceListMembers", ctx, "00000000-0000-4000-0000-000000000000", "team:infra").
+					Return([]models.Member{{ID: "6509e169ae6144b2f56bf288", Role: guard.RoleObserver, Tags: []string{"team:infra"}}}, nil).
+					Once()
+				mock.On("UserGetByID", ctx, "6509e169ae6144b2f56bf288", false).
+					Return(&models.User{ID: "6509e169ae6144b2f56bf288", UserData: models.UserData{Username: "john_doe"}}, 0, nil).
+					Once()
+			},
+			expected: Expected{
+				members: []models.Member{{ID: "6509e169ae6144b2f56bf288", Username: "john_doe", Role: guard.RoleObserver, Tags: []string{"team:infra"}}},
+				err:     nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			members, err := service.ListNamespaceMembers(ctx, tc.tenant, tc.tag)
+			assert.Equal(t, tc.expected, Expected{members, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestRenewNamespace(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	cases := []struct {
+		description   string
+		tenant        string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when the namespace is not found",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				clockMock.On("Now").Return(now).Once()
+				mock.On("NamespaceRenew", ctx, "00000000-0000-4000-0000-000000000000", now).
+					Return(store.ErrNoDocuments).
+					Once()
+			},
+			expected: NewErrNamespaceNotFound("00000000-0000-4000-0000-000000000000", store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the namespace could not be renewed",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				clockMock.On("Now").Return(now).Once()
+				mock.On("NamespaceRenew", ctx, "00000000-0000-4000-0000-000000000000", now).
+					Return(errors.New("error")).
+					Once()
+			},
+			expected: errors.New("error"),
+		},
+		{
+			description: "succeeds",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				clockMock.On("Now").Return(now).Once()
+				mock.On("NamespaceRenew", ctx, "00000000-0000-4000-0000-000000000000", now).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
 
-				passiveMember := &models.User{
-					UserData: models.UserData{
-						Name:     "passiveMemberName",
-						Username: "passiveMemberUsername",
-					},
-					ID: "passiveMemberID",
-				}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
 
-				namespaceActivePassive := &models.Namespace{
-					Name:     "group1",
-					Owner:    "activeMemberID",
-					TenantID: "a736a52b-5777-4f92-b0b8-e359bf484717",
-					Members: []models.Member{
-						{ID: "ownerID", Role: guard.RoleOwner},
-						{ID: "activeMemberID", Role: guard.RoleAdministrator},
-						{ID: "passiveMemberID", Role: guard.RoleObserver},
-					},
-				}
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := service.RenewNamespace(ctx, tc.tenant)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
 
-				mock.On("NamespaceGet", ctx, namespaceActivePassive.TenantID, true).Return(namespaceActivePassive, nil).Once()
+	mock.AssertExpectations(t)
+}
 
-				mock.On("UserGetByID", ctx, passiveMember.ID, false).Return(passiveMember, 0, nil).Once()
-				mock.On("UserGetByID", ctx, activeMember.ID, false).Return(activeMember, 0, nil).Once()
+func TestRecordNamespaceActivity(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
 
-				mock.On("NamespaceEditMember", ctx, namespaceActivePassive.TenantID, passiveMember.ID, guard.RoleOperator).Return(errors.New("error")).Once()
+	ctx := context.TODO()
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+	}{
+		{
+			description: "does not renew the namespace renewed in the last interval",
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "namespace-active={00000000-0000-4000-0000-000000000000}", testifymock.Anything).
+					Run(func(args testifymock.Arguments) { *args.Get(2).(*bool) = true }).
+					Return(nil).
+					Once()
 			},
-			Expected: errors.New("error"),
 		},
 		{
-			description:   "Success",
-			TenantID:      "a736a52b-5777-4f92-b0b8-e359bf484717",
-			UserID:        "activeMemberID",
-			MemberID:      "passiveMemberID",
-			MemberNewRole: guard.RoleOperator,
-			RequiredMocks: func() {
-				namespaceActivePassive := &models.Namespace{
-					Name:     "group1",
-					Owner:    "activeMemberID",
-					TenantID: "a736a52b-5777-4f92-b0b8-e359bf484717",
-					Members: []models.Member{
-						{ID: "ownerID", Role: guard.RoleOwner},
-						{ID: "activeMemberID", Role: guard.RoleAdministrator},
-						{ID: "passiveMemberID", Role: guard.RoleObserver},
-					},
-				}
+			description: "does not cache the activity when the namespace could not be renewed",
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "namespace-active={00000000-0000-4000-0000-000000000000}", testifymock.Anything).
+					Return(nil).
+					Once()
+				clockMock.On("Now").Return(now).Once()
+				storeMock.On("NamespaceRenew", ctx, "00000000-0000-4000-0000-000000000000", now).
+					Return(errors.New("error")).
+					Once()
+			},
+		},
+		{
+			description: "renews the namespace and caches the activity",
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "namespace-active={00000000-0000-4000-0000-000000000000}", testifymock.Anything).
+					Return(nil).
+					Once()
+				clockMock.On("Now").Return(now).Once()
+				storeMock.On("NamespaceRenew", ctx, "00000000-0000-4000-0000-000000000000", now).
+					Return(nil).
+					Once()
+				cacheMock.On("Set", ctx, "namespace-active={00000000-0000-4000-0000-000000000000}", true, NamespaceActivityInterval).
+					Return(nil).
+					Once()
+			},
+		},
+	}
 
-				activeMember := &models.User{
-					UserData: models.UserData{
-						Name:     "activeMemberName",
-						Username: "activeMemberUsername",
-					},
-					ID: "activeMemberID",
-				}
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
 
-				passiveMember := &models.User{
-					UserData: models.UserData{
-						Name:     "passiveMemberName",
-						Username: "passiveMemberUsername",
-					},
-					ID: "passiveMemberID",
-				}
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			service.RecordNamespaceActivity(ctx, "00000000-0000-4000-0000-000000000000")
+		})
+	}
 
-				mock.On("NamespaceGet", ctx, namespaceActivePassive.TenantID, true).Return(namespaceActivePassive, nil).Once()
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
 
-				mock.On("UserGetByID", ctx, passiveMember.ID, false).Return(passiveMember, 0, nil).Once()
-				mock.On("UserGetByID", ctx, activeMember.ID, false).Return(activeMember, 0, nil).Once()
+func TestSendNamespaceUsageReport(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
 
-				mock.On("NamespaceEditMember", ctx, namespaceActivePassive.TenantID, passiveMember.ID, guard.RoleOperator).Return(nil).Once()
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		Name:     "namespace",
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members:  []models.Member{{ID: "owner"}},
+		Settings: &models.NamespaceSettings{UsageReportSchedule: "@weekly", UsageReportEmail: "owner@shellhub.io"},
+	}
+
+	const key = "usage-report-sent={00000000-0000-4000-0000-000000000000}"
+
+	cases := []struct {
+		description   string
+		tenant        string
+		configured    bool
+		requiredMocks func(mailerMock *mailermocks.Mailer)
+		expected      error
+	}{
+		{
+			description:   "fails when the SMTP server is not configured",
+			tenant:        "00000000-0000-4000-0000-000000000000",
+			configured:    false,
+			requiredMocks: func(_ *mailermocks.Mailer) {},
+			expected:      NewErrNamespaceUsageReportInvalid(errors.New("SMTP server not configured")),
+		},
+		{
+			description: "fails when the namespace is not found",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			configured:  true,
+			requiredMocks: func(_ *mailermocks.Mailer) {
+				storeMock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(nil, store.ErrNoDocuments).
+					Once()
 			},
-			Expected: nil,
+			expected: NewErrNamespaceNotFound("00000000-0000-4000-0000-000000000000", store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the namespace has no usage report email",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			configured:  true,
+			requiredMocks: func(_ *mailermocks.Mailer) {
+				storeMock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(&models.Namespace{TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+			},
+			expected: NewErrNamespaceUsageReportInvalid(errors.New("usage report email not set")),
+		},
+		{
+			description: "fails when the usage report email does not belong to a member of the namespace",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			configured:  true,
+			requiredMocks: func(_ *mailermocks.Mailer) {
+				storeMock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(namespace, nil).
+					Once()
+				storeMock.On("UserGetByEmail", ctx, "owner@shellhub.io").
+					Return(nil, store.ErrNoDocuments).
+					Once()
+			},
+			expected: NewErrNamespaceUsageReportInvalid(errors.New("usage report email does not belong to a member of the namespace")),
+		},
+		{
+			description: "fails when the usage report was sent in the last interval",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			configured:  true,
+			requiredMocks: func(_ *mailermocks.Mailer) {
+				storeMock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(namespace, nil).
+					Once()
+				storeMock.On("UserGetByEmail", ctx, "owner@shellhub.io").
+					Return(&models.User{ID: "owner"}, nil).
+					Once()
+				cacheMock.On("Get", ctx, key, testifymock.Anything).
+					Run(func(args testifymock.Arguments) { *args.Get(2).(*bool) = true }).
+					Return(nil).
+					Once()
+			},
+			expected: NewErrNamespaceUsageReportLimit(nil),
+		},
+		{
+			description: "fails when the namespace stats could not be counted",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			configured:  true,
+			requiredMocks: func(_ *mailermocks.Mailer) {
+				storeMock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(namespace, nil).
+					Once()
+				storeMock.On("UserGetByEmail", ctx, "owner@shellhub.io").
+					Return(&models.User{ID: "owner"}, nil).
+					Once()
+				cacheMock.On("Get", ctx, key, testifymock.Anything).
+					Return(nil).
+					Once()
+				storeMock.On("GetNamespaceStats", ctx, "00000000-0000-4000-0000-000000000000").
+					Return(nil, errors.New("error")).
+					Once()
+			},
+			expected: errors.New("error"),
+		},
+		{
+			description: "succeeds sending the usage report to the namespace's usage report email",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			configured:  true,
+			requiredMocks: func(mailerMock *mailermocks.Mailer) {
+				storeMock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(namespace, nil).
+					Once()
+				storeMock.On("UserGetByEmail", ctx, "owner@shellhub.io").
+					Return(&models.User{ID: "owner"}, nil).
+					Once()
+				cacheMock.On("Get", ctx, key, testifymock.Anything).
+					Return(nil).
+					Once()
+				storeMock.On("GetNamespaceStats", ctx, "00000000-0000-4000-0000-000000000000").
+					Return(&models.Stats{RegisteredDevices: 10, OnlineDevices: 7, ActiveSessions: 3}, nil).
+					Once()
+				cacheMock.On("Set", ctx, key, true, UsageReportSendInterval).
+					Return(nil).
+					Once()
+				clockMock.On("Now").Return(now).Once()
+				mailerMock.On("Send", ctx, "owner@shellhub.io", "Usage report of namespace", testifymock.MatchedBy(func(body string) bool {
+					return strings.Contains(body, "<tr><th>Registered devices</th><td>10</td></tr>") &&
+						strings.Contains(body, "<tr><th>Online devices</th><td>7</td></tr>") &&
+						strings.Contains(body, "<tr><th>Active sessions</th><td>3</td></tr>")
+				})).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "fails allowing to send it again when the usage report could not be sent",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			configured:  true,
+			requiredMocks: func(mailerMock *mailermocks.Mailer) {
+				storeMock.On("NamespaceGet", ctx, "00000000-0000-4000-0000-000000000000", false).
+					Return(namespace, nil).
+					Once()
+				storeMock.On("UserGetByEmail", ctx, "owner@shellhub.io").
+					Return(&models.User{ID: "owner"}, nil).
+					Once()
+				cacheMock.On("Get", ctx, key, testifymock.Anything).
+					Return(nil).
+					Once()
+				storeMock.On("GetNamespaceStats", ctx, "00000000-0000-4000-0000-000000000000").
+					Return(&models.Stats{}, nil).
+					Once()
+				cacheMock.On("Set", ctx, key, true, UsageReportSendInterval).
+					Return(nil).
+					Once()
+				clockMock.On("Now").Return(now).Once()
+				mailerMock.On("Send", ctx, "owner@shellhub.io", "Usage report of namespace", testifymock.AnythingOfType("string")).
+					Return(errors.New("error")).
+					Once()
+				cacheMock.On("Delete", ctx, key).
+					Return(nil).
+					Once()
+			},
+			expected: errors.New("error"),
 		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.description, func(t *testing.T) {
-			tc.RequiredMocks()
+			mailerMock := mailermocks.NewMailer(t)
+			tc.requiredMocks(mailerMock)
 
-			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
-			err := service.EditNamespaceUser(ctx, tc.TenantID, tc.UserID, tc.MemberID, tc.MemberNewRole)
-			assert.Equal(t, tc.Expected, err)
+			var m mailer.Mailer
+			if tc.configured {
+				m = mailerMock
+			}
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			err := service.SendNamespaceUsageReport(ctx, tc.tenant, m)
+			assert.Equal(t, tc.expected, err)
 		})
 	}
-	mock.AssertExpectations(t)
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
 }
 
 func TestGetSessionRecord(t *testing.T) {
@@ -2077,3 +3585,167 @@ func TestEditSessionRecord(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestSetSessionRecordForNamespaces(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const ownerID = "507f1f77bcf86cd799439011"
+
+	cases := []struct {
+		description   string
+		tenants       []string
+		enabled       bool
+		requiredMocks func()
+		expected      []SessionRecordResult
+	}{
+		{
+			description: "succeeds updating every owned namespace",
+			tenants:     []string{"tenant-1", "tenant-2"},
+			enabled:     true,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant-1", false).Return(&models.Namespace{TenantID: "tenant-1", Owner: ownerID}, nil).Once()
+				mock.On("NamespaceSetSessionRecord", ctx, true, "tenant-1").Return(nil).Once()
+				mock.On("NamespaceGet", ctx, "tenant-2", false).Return(&models.Namespace{TenantID: "tenant-2", Owner: ownerID}, nil).Once()
+				mock.On("NamespaceSetSessionRecord", ctx, true, "tenant-2").Return(nil).Once()
+			},
+			expected: []SessionRecordResult{
+				{TenantID: "tenant-1", Updated: true},
+				{TenantID: "tenant-2", Updated: true},
+			},
+		},
+		{
+			description: "succeeds reporting the namespaces that could not be updated",
+			tenants:     []string{"tenant-1", "tenant-2", "tenant-3", "tenant-4"},
+			enabled:     false,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant-1", false).Return(nil, store.ErrNoDocuments).Once()
+				mock.On("NamespaceGet", ctx, "tenant-2", false).Return(&models.Namespace{TenantID: "tenant-2", Owner: "other"}, nil).Once()
+				mock.On("NamespaceGet", ctx, "tenant-3", false).Return(&models.Namespace{TenantID: "tenant-3", Owner: ownerID}, nil).Once()
+				mock.On("NamespaceSetSessionRecord", ctx, false, "tenant-3").Return(errors.New("error")).Once()
+				mock.On("NamespaceGet", ctx, "tenant-4", false).Return(&models.Namespace{TenantID: "tenant-4", Owner: ownerID}, nil).Once()
+				mock.On("NamespaceSetSessionRecord", ctx, false, "tenant-4").Return(nil).Once()
+			},
+			expected: []SessionRecordResult{
+				{TenantID: "tenant-1", Updated: false, Error: ErrNamespaceNotFound.Error()},
+				{TenantID: "tenant-2", Updated: false, Error: ErrForbidden.Error()},
+				{TenantID: "tenant-3", Updated: false, Error: ErrNamespaceSessionRecord.Error()},
+				{TenantID: "tenant-4", Updated: true},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			results, err := service.SetSessionRecordForNamespaces(ctx, ownerID, tc.tenants, tc.enabled)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, results)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestExportNamespaceMembers(t *testing.T) {
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	ctx := context.TODO()
+
+	t.Run("fails when the namespace is not found", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+
+		s := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		builder := new(strings.Builder)
+		assert.Equal(t, NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments), s.ExportNamespaceMembers(ctx, tenantID, builder))
+		assert.Empty(t, builder.String())
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when a member's user cannot be fetched", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mock.On("NamespaceGet", ctx, tenantID, false).
+			Return(&models.Namespace{TenantID: tenantID, Members: []models.Member{{ID: "member", Role: guard.RoleObserver}}}, nil).
+			Once()
+		mock.On("UserGetByID", ctx, "member", false).Return(nil, 0, errors.New("error")).Once()
+
+		s := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		assert.Equal(t, errors.New("error"), s.ExportNamespaceMembers(ctx, tenantID, io.Discard))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("succeeds streaming a namespace with 1200 members", func(t *testing.T) {
+		const total = 1200
+
+		joined := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+		login := time.Date(2023, 6, 1, 8, 30, 0, 0, time.UTC)
+
+		members := make([]models.Member, total)
+		for i := range members {
+			members[i] = models.Member{ID: strconv.Itoa(i), Role: guard.RoleObserver, AddedAt: &joined}
+		}
+
+		mock := new(mocks.Store)
+		mock.On("UserGetByID", ctx, testifymock.Anything, false).
+			Return(func(_ context.Context, id string, _ bool) (*models.User, int, error) {
+				// NOTICE: every hundredth user was deleted, so it's exported without its data.
+				if i, _ := strconv.Atoi(id); i%100 == 99 {
+					return nil, 0, store.ErrNoDocuments
+				}
+
+				return &models.User{ID: id, LastLogin: login, UserData: models.UserData{Username: "user" + id, Email: "user" + id + "@shellhub.io"}}, 0, nil
+			}).
+			Times(total)
+		mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID, Members: members}, nil).Once()
+
+		s := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		// NOTICE: as the pipe is unbuffered, the rows are only read while the export is running when they are
+		// written incrementally.
+		reader, writer := io.Pipe()
+		done := make(chan error)
+		go func() {
+			err := s.ExportNamespaceMembers(ctx, tenantID, writer)
+			writer.CloseWithError(err)
+			done <- err
+		}()
+
+		rows := csv.NewReader(reader)
+
+		header, err := rows.Read()
+		require.NoError(t, err)
+		assert.Equal(t, NamespaceMembersExportHeader, header)
+
+		count := 0
+		for {
+			row, err := rows.Read()
+			if err == io.EOF {
+				break
+			}
+
+			require.NoError(t, err)
+
+			id := strconv.Itoa(count)
+			if count%100 == 99 {
+				assert.Equal(t, []string{DeletedMemberUsername, "", guard.RoleObserver, "2023-01-01T12:00:00Z", ""}, row)
+			} else {
+				assert.Equal(t, []string{"user" + id, "user" + id + "@shellhub.io", guard.RoleObserver, "2023-01-01T12:00:00Z", "2023-06-01T08:30:00Z"}, row)
+			}
+
+			count++
+		}
+
+		assert.NoError(t, <-done)
+		assert.Equal(t, total, count)
+
+		mock.AssertExpectations(t)
+	})
+}
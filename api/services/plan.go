@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+// plans are the plans available to be assigned to a user.
+var plans = []models.Plan{
+	{
+		Name:          models.PlanFree,
+		MaxNamespaces: 1,
+		MaxDevices:    3,
+		MaxMembers:    3,
+		Features:      []string{},
+	},
+	{
+		Name:          models.PlanStarter,
+		MaxNamespaces: 3,
+		MaxDevices:    50,
+		MaxMembers:    10,
+		Features:      []string{"session_record"},
+	},
+	{
+		Name:          models.PlanEnterprise,
+		MaxNamespaces: -1,
+		MaxDevices:    -1,
+		MaxMembers:    -1,
+		Features:      []string{"session_record", "firewall"},
+	},
+}
+
+type PlanService interface {
+	// ListPlans lists all plans available to be assigned to a user.
+	ListPlans(ctx context.Context) ([]models.Plan, error)
+}
+
+func (s *service) ListPlans(_ context.Context) ([]models.Plan, error) {
+	// NOTICE: the plans are copied, as the caller could otherwise change the ones assigned to the users.
+	list := make([]models.Plan, len(plans))
+	for i, plan := range plans {
+		plan.Features = append([]string{}, plan.Features...)
+		list[i] = plan
+	}
+
+	return list, nil
+}
+
+// getPlan returns the plan with the specified name. It returns false when the plan does not exist.
+func getPlan(name string) (*models.Plan, bool) {
+	for _, plan := range plans {
+		if plan.Name == name {
+			return &plan, true
+		}
+	}
+
+	return nil, false
+}
+
+// checkNamespaceMembersLimit checks if a member can be added to the namespace, returning
+// NewErrNamespaceMembersLimitReached when it already has the maximum number of members of its owner's plan. The
+// namespaces whose owner has no plan aren't limited.
+func (s *service) checkNamespaceMembersLimit(ctx context.Context, namespace *models.Namespace) error {
+	owner, _, err := s.store.UserGetByID(ctx, namespace.Owner, false)
+	if err != nil {
+		return NewErrUserNotFound(namespace.Owner, err)
+	}
+
+	plan, ok := getPlan(owner.PlanID)
+	if !ok {
+		return nil
+	}
+
+	// When MaxMembers is less than zero, it means that the namespace has no limit of members.
+	if plan.MaxMembers > 0 && len(namespace.Members) >= plan.MaxMembers {
+		return NewErrNamespaceMembersLimitReached(plan.MaxMembers, nil)
+	}
+
+	return nil
+}
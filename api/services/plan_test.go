@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	mockcache "github.com/shellhub-io/shellhub/pkg/cache/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPlans(t *testing.T) {
+	service := NewService(new(mocks.Store), privateKey, publicKey, new(mockcache.Cache), clientMock, nil)
+
+	list, err := service.ListPlans(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, plans, list)
+
+	list[0].Name = "changed"
+	list[1].Features[0] = "changed"
+
+	assert.Equal(t, "free", plans[0].Name)
+	assert.Equal(t, "session_record", plans[1].Features[0])
+}
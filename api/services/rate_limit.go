@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+// APIRateLimitCacheTTL is how long the API rate limit of a namespace is cached to evaluate its requests.
+const APIRateLimitCacheTTL = 30 * time.Second
+
+type APIRateLimitService interface {
+	// SetNamespaceAPIRateLimit sets the rate limit of the API requests made on behalf of the namespace with the
+	// specified tenant ID. A nil limit removes it. The cached limit is invalidated, so it's enforced right away.
+	SetNamespaceAPIRateLimit(ctx context.Context, tenantID string, limit *models.APIRateLimitConfig) error
+
+	// EvaluateAPIRateLimit takes a token from the token bucket of the namespace with the specified tenant ID for an
+	// API request made on its behalf. It returns nil when the namespace's requests aren't rate limited.
+	EvaluateAPIRateLimit(ctx context.Context, tenantID string) (*models.APIRateLimitStatus, error)
+}
+
+// cachedAPIRateLimit is the API rate limit of a namespace as cached. TenantID is set even when the namespace has no
+// rate limit, telling it apart from a cache miss.
+type cachedAPIRateLimit struct {
+	TenantID string
+	Limit    *models.APIRateLimitConfig
+}
+
+func apiRateLimitCacheKey(tenantID string) string {
+	return "api-rate-limit={" + tenantID + "}"
+}
+
+func apiRateLimitBucketKey(tenantID string) string {
+	return "api-rate-limit-bucket={" + tenantID + "}"
+}
+
+func (s *service) SetNamespaceAPIRateLimit(ctx context.Context, tenantID string, limit *models.APIRateLimitConfig) error {
+	if err := s.store.NamespaceSetAPIRateLimit(ctx, tenantID, limit); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrNamespaceNotFound(tenantID, err)
+		}
+
+		return err
+	}
+
+	if err := s.cache.Delete(ctx, apiRateLimitCacheKey(tenantID)); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("tenant_id", tenantID).
+			Warn("unable to invalidate the cached API rate limit")
+	}
+
+	return nil
+}
+
+func (s *service) EvaluateAPIRateLimit(ctx context.Context, tenantID string) (*models.APIRateLimitStatus, error) {
+	limit, err := s.namespaceAPIRateLimit(ctx, tenantID)
+	if err != nil || limit == nil {
+		return nil, err
+	}
+
+	taken, remaining, reset, err := s.cache.TakeToken(ctx, apiRateLimitBucketKey(tenantID), limit.RequestsPerMinute, limit.BurstSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.APIRateLimitStatus{
+		Allowed:   taken,
+		Limit:     limit.BurstSize,
+		Remaining: remaining,
+		Reset:     reset,
+	}, nil
+}
+
+// namespaceAPIRateLimit returns the API rate limit of the namespace, caching it for [APIRateLimitCacheTTL] to avoid
+// querying the store on every request.
+func (s *service) namespaceAPIRateLimit(ctx context.Context, tenantID string) (*models.APIRateLimitConfig, error) {
+	cached := new(cachedAPIRateLimit)
+	if err := s.cache.Get(ctx, apiRateLimitCacheKey(tenantID), cached); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("tenant_id", tenantID).
+			Warn("unable to get the API rate limit from cache")
+	}
+
+	if cached.TenantID == tenantID {
+		return cached.Limit, nil
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	cached = &cachedAPIRateLimit{TenantID: tenantID, Limit: namespace.APIRateLimit}
+	if err := s.cache.Set(ctx, apiRateLimitCacheKey(tenantID), cached, APIRateLimitCacheTTL); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("tenant_id", tenantID).
+			Warn("unable to set the API rate limit in cache")
+	}
+
+	return cached.Limit, nil
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goerrors "errors"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	mockcache "github.com/shellhub-io/shellhub/pkg/cache/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestSetNamespaceAPIRateLimit(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	limit := &models.APIRateLimitConfig{RequestsPerMinute: 60, BurstSize: 10}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when the namespace is not found",
+			requiredMocks: func() {
+				storeMock.On("NamespaceSetAPIRateLimit", ctx, tenantID, limit).Return(store.ErrNoDocuments).Once()
+			},
+			expected: NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments),
+		},
+		{
+			description: "succeeds invalidating the cached rate limit",
+			requiredMocks: func() {
+				storeMock.On("NamespaceSetAPIRateLimit", ctx, tenantID, limit).Return(nil).Once()
+				cacheMock.On("Delete", ctx, "api-rate-limit={"+tenantID+"}").Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds even when the cached rate limit cannot be invalidated",
+			requiredMocks: func() {
+				storeMock.On("NamespaceSetAPIRateLimit", ctx, tenantID, limit).Return(nil).Once()
+				cacheMock.On("Delete", ctx, "api-rate-limit={"+tenantID+"}").Return(goerrors.New("error")).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			assert.Equal(t, tc.expected, service.SetNamespaceAPIRateLimit(ctx, tenantID, limit))
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestEvaluateAPIRateLimit(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	limit := &models.APIRateLimitConfig{RequestsPerMinute: 60, BurstSize: 10}
+	reset := time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)
+
+	cached := func(limit *models.APIRateLimitConfig) func(testifymock.Arguments) {
+		return func(args testifymock.Arguments) {
+			*args.Get(2).(*cachedAPIRateLimit) = cachedAPIRateLimit{TenantID: tenantID, Limit: limit}
+		}
+	}
+
+	type Expected struct {
+		status *models.APIRateLimitStatus
+		err    error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the namespace is not found",
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "api-rate-limit={"+tenantID+"}", testifymock.Anything).Return(nil).Once()
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments)},
+		},
+		{
+			description: "succeeds caching that the namespace has no rate limit",
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "api-rate-limit={"+tenantID+"}", testifymock.Anything).Return(nil).Once()
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				cacheMock.
+					On("Set", ctx, "api-rate-limit={"+tenantID+"}", &cachedAPIRateLimit{TenantID: tenantID}, APIRateLimitCacheTTL).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{},
+		},
+		{
+			description: "succeeds when the cached namespace has no rate limit",
+			requiredMocks: func() {
+				cacheMock.
+					On("Get", ctx, "api-rate-limit={"+tenantID+"}", testifymock.Anything).
+					Run(cached(nil)).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{},
+		},
+		{
+			description: "succeeds caching the namespace's rate limit and taking a token",
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "api-rate-limit={"+tenantID+"}", testifymock.Anything).Return(nil).Once()
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID, APIRateLimit: limit}, nil).Once()
+				cacheMock.
+					On("Set", ctx, "api-rate-limit={"+tenantID+"}", &cachedAPIRateLimit{TenantID: tenantID, Limit: limit}, APIRateLimitCacheTTL).
+					Return(nil).
+					Once()
+				cacheMock.On("TakeToken", ctx, "api-rate-limit-bucket={"+tenantID+"}", 60, 10).Return(true, 9, reset, nil).Once()
+			},
+			expected: Expected{status: &models.APIRateLimitStatus{Allowed: true, Limit: 10, Remaining: 9, Reset: reset}},
+		},
+		{
+			description: "succeeds when the cached rate limit is exceeded",
+			requiredMocks: func() {
+				cacheMock.
+					On("Get", ctx, "api-rate-limit={"+tenantID+"}", testifymock.Anything).
+					Run(cached(limit)).
+					Return(nil).
+					Once()
+				cacheMock.On("TakeToken", ctx, "api-rate-limit-bucket={"+tenantID+"}", 60, 10).Return(false, 0, reset, nil).Once()
+			},
+			expected: Expected{status: &models.APIRateLimitStatus{Allowed: false, Limit: 10, Remaining: 0, Reset: reset}},
+		},
+		{
+			description: "fails when the token cannot be taken",
+			requiredMocks: func() {
+				cacheMock.
+					On("Get", ctx, "api-rate-limit={"+tenantID+"}", testifymock.Anything).
+					Run(cached(limit)).
+					Return(nil).
+					Once()
+				cacheMock.On("TakeToken", ctx, "api-rate-limit-bucket={"+tenantID+"}", 60, 10).Return(false, 0, time.Time{}, goerrors.New("error")).Once()
+			},
+			expected: Expected{err: goerrors.New("error")},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			status, err := service.EvaluateAPIRateLimit(ctx, tenantID)
+			assert.Equal(t, tc.expected, Expected{status, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestEvaluateAPIRateLimitRedis(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7.2-alpine",
+			ExposedPorts: []string{"6379/tcp"},
+			WaitingFor:   wait.ForLog("Ready to accept connections"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Skipf("Redis container is not available: %s", err)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, container.Terminate(ctx))
+	})
+
+	uri, err := container.PortEndpoint(ctx, "6379/tcp", "redis")
+	require.NoError(t, err)
+
+	cache, err := storecache.NewRedisCache(uri, 0)
+	require.NoError(t, err)
+
+	const burst = 10
+
+	tenants := []string{"00000000-0000-4000-0000-000000000000", "00000000-0000-4000-0000-000000000001"}
+
+	storeMock := new(mocks.Store)
+	for _, tenant := range tenants {
+		storeMock.
+			On("NamespaceGet", testifymock.Anything, tenant, false).
+			Return(&models.Namespace{TenantID: tenant, APIRateLimit: &models.APIRateLimitConfig{RequestsPerMinute: 1, BurstSize: burst}}, nil)
+	}
+
+	service := NewService(store.Store(storeMock), privateKey, publicKey, cache, clientMock, nil)
+
+	// NOTICE: the requests of every replica share the same bucket, what is simulated by evaluating the requests
+	// concurrently; only the bucket's tokens must be taken, regardless of how many requests race for them.
+	allowed := make(map[string]*int64)
+	for _, tenant := range tenants {
+		allowed[tenant] = new(int64)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		for _, tenant := range tenants {
+			wg.Add(1)
+			go func(tenant string) {
+				defer wg.Done()
+
+				status, err := service.EvaluateAPIRateLimit(ctx, tenant)
+				if !assert.NoError(t, err) || !assert.NotNil(t, status) {
+					return
+				}
+
+				assert.Equal(t, burst, status.Limit)
+				if status.Allowed {
+					atomic.AddInt64(allowed[tenant], 1)
+				}
+			}(tenant)
+		}
+	}
+
+	wg.Wait()
+
+	for _, tenant := range tenants {
+		assert.Equal(t, int64(burst), atomic.LoadInt64(allowed[tenant]), tenant)
+	}
+
+	status, err := service.EvaluateAPIRateLimit(ctx, tenants[0])
+	require.NoError(t, err)
+	assert.False(t, status.Allowed)
+	assert.Equal(t, 0, status.Remaining)
+	assert.True(t, status.Reset.After(time.Now()))
+}
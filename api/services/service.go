@@ -2,7 +2,11 @@ package services
 
 import (
 	"crypto/rsa"
+	"time"
 
+	"github.com/shellhub-io/shellhub/api/pkg/recordcipher"
+	"github.com/shellhub-io/shellhub/api/pkg/recordstorage"
+	"github.com/shellhub-io/shellhub/api/pkg/webhook"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/geoip"
@@ -23,6 +27,56 @@ type service struct {
 	client    interface{}
 	locator   geoip.Locator
 	validator *validator.Validator
+	// records encrypts the frames recorded on the sessions at rest. When nil, they're stored unencrypted.
+	records *recordcipher.Keyring
+	// recordings is where the frames recorded on the sessions are kept.
+	recordings recordstorage.RecordingStorage
+	// keySources are the URLs, with a placeholder for the username, where the public keys of a user account are
+	// imported from, by source.
+	keySources map[string]string
+	// webhooks posts the events of the namespaces to their webhooks. When nil, the events aren't posted.
+	webhooks webhook.Dispatcher
+	// billingStatusTTL is how long whether a namespace has a subscription is cached.
+	billingStatusTTL time.Duration
+}
+
+// Option configures optional features of the service.
+type Option func(*service)
+
+// WithRecordingStorage keeps the frames recorded on the sessions on storage, instead of the store.
+func WithRecordingStorage(storage recordstorage.RecordingStorage) Option {
+	return func(s *service) {
+		s.recordings = storage
+	}
+}
+
+// WithRecordEncryption encrypts the frames recorded on the sessions at rest with the keys of keyring.
+func WithRecordEncryption(keyring *recordcipher.Keyring) Option {
+	return func(s *service) {
+		s.records = keyring
+	}
+}
+
+// WithPublicKeySources imports the public keys of the user accounts from sources, replacing
+// [DefaultPublicKeySources].
+func WithPublicKeySources(sources map[string]string) Option {
+	return func(s *service) {
+		s.keySources = sources
+	}
+}
+
+// WithWebhookDispatcher posts the events of the namespaces to their webhooks through dispatcher.
+func WithWebhookDispatcher(dispatcher webhook.Dispatcher) Option {
+	return func(s *service) {
+		s.webhooks = dispatcher
+	}
+}
+
+// WithBillingStatusCacheTTL caches whether a namespace has a subscription for ttl, replacing [BillingStatusCacheTTL].
+func WithBillingStatusCacheTTL(ttl time.Duration) Option {
+	return func(s *service) {
+		s.billingStatusTTL = ttl
+	}
 }
 
 //go:generate mockery --name Service --filename services.go
@@ -31,19 +85,30 @@ type Service interface {
 	TagsService
 	DeviceService
 	DeviceTags
+	DeviceGroupService
+	DeviceEventService
+	DeviceConnectionService
+	DeviceApprovalService
 	UserService
+	UserSessionService
 	SSHKeysService
 	SSHKeysTagsService
 	SessionService
 	NamespaceService
+	NamespaceEnrollmentService
+	InviteLinkService
 	AuthService
 	StatsService
 	SetupService
 	SystemService
 	APIKeyService
+	PlanService
+	FirewallService
+	APIRateLimitService
+	WebhookService
 }
 
-func NewService(store store.Store, privKey *rsa.PrivateKey, pubKey *rsa.PublicKey, cache cache.Cache, c interface{}, l geoip.Locator) *APIService {
+func NewService(store store.Store, privKey *rsa.PrivateKey, pubKey *rsa.PublicKey, cache cache.Cache, c interface{}, l geoip.Locator, opts ...Option) *APIService {
 	if privKey == nil || pubKey == nil {
 		var err error
 		privKey, pubKey, err = LoadKeys()
@@ -52,5 +117,10 @@ func NewService(store store.Store, privKey *rsa.PrivateKey, pubKey *rsa.PublicKe
 		}
 	}
 
-	return &APIService{service: &service{store, privKey, pubKey, cache, c, l, validator.New()}}
+	s := &service{store, privKey, pubKey, cache, c, l, validator.New(), nil, recordstorage.NewMongoRecordingStorage(store), DefaultPublicKeySources, nil, BillingStatusCacheTTL}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return &APIService{service: s}
 }
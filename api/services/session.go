@@ -1,32 +1,99 @@
 package services
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/shellhub-io/shellhub/api/pkg/replay"
 	"github.com/shellhub-io/shellhub/api/store"
+	req "github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/logger"
 	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/recording"
+	log "github.com/sirupsen/logrus"
 )
 
+// RecordSessionBatchSize is the number of frames of a streamed recording inserted at once.
+const RecordSessionBatchSize = 100
+
+// RecordingIdleThreshold is the minimum pause between two frames of a recording considered an idle gap.
+const RecordingIdleThreshold = 5 * time.Second
+
 type SessionService interface {
-	ListSessions(ctx context.Context, paginator query.Paginator) ([]models.Session, int, error)
+	ListSessions(ctx context.Context, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.Session, int, error)
 	GetSession(ctx context.Context, uid models.UID) (*models.Session, error)
 	CreateSession(ctx context.Context, session requests.SessionCreate) (*models.Session, error)
+	// DeactivateSession finishes the session with the specified UID. Finishing an already finished session succeeds,
+	// as both the client and the SSH server may finish it, while a session that never existed is not found.
 	DeactivateSession(ctx context.Context, uid models.UID) error
 	KeepAliveSession(ctx context.Context, uid models.UID) error
 	UpdateSession(ctx context.Context, uid models.UID, model models.SessionUpdate) error
+	// IncrementSessionViewCount registers a new view of the session's recording, once it was streamed to the client.
+	IncrementSessionViewCount(ctx context.Context, uid models.UID) error
+	// ListTopViewedSessions lists up to limit sessions of a namespace with the most viewed recordings.
+	ListTopViewedSessions(ctx context.Context, tenantID string, limit int) ([]models.Session, error)
+	// ListLiveSessions lists the sessions of a namespace currently connected to the SSH server, reconciled with the
+	// active sessions on the store. Sessions only present in one of both sources are flagged by
+	// [models.LiveSession.InMemory] and [models.LiveSession.Stored].
+	ListLiveSessions(ctx context.Context, tenantID string) ([]models.LiveSession, error)
+	// ListSessionRecordFrames lists the frames recorded on a session ordered by their time.
+	ListSessionRecordFrames(ctx context.Context, uid models.UID) ([]models.RecordedSession, error)
+	// StreamSessionRecordFrames calls fn for each frame recorded on a session, ordered by their time, reading them from
+	// the store as they are consumed instead of loading all of them in memory. It stops when ctx is done or fn fails.
+	StreamSessionRecordFrames(ctx context.Context, uid models.UID, fn func(frame *models.RecordedSession) error) error
+	// UploadSessionRecord stores the frames streamed on body, encoded by [recording.WriteFrame], as the session's
+	// recording. They are inserted in batches of [RecordSessionBatchSize], so the memory used doesn't grow with the
+	// recording's size, and the upload's progress is saved after each batch.
+	//
+	// The offset is the position of body on the whole stream and must match the bytes already received, so an
+	// interrupted upload is resumed from the returned state. When the stream ends in the middle of a frame, the
+	// complete frames are stored and the upload can be resumed from them.
+	UploadSessionRecord(ctx context.Context, uid models.UID, offset int64, body io.Reader) (*models.RecordingUploadState, error)
+	// AnalyzeRecording analyzes the frames recorded on a session, streaming them instead of loading all of them in
+	// memory. Pauses between frames of, at least, [RecordingIdleThreshold] are reported as idle gaps. When the session
+	// is closed, the analysis' summary is set on it, so it's shown on the sessions' list.
+	AnalyzeRecording(ctx context.Context, uid string) (*models.RecordingAnalysis, error)
+	// SessionExportS3 streams the frames recorded on a session to the S3-compatible object storage configured by s3cfg,
+	// as a gzipped asciinema v2 file, and sets the session's storage to it.
+	//
+	// It returns the URL of the exported object.
+	SessionExportS3(ctx context.Context, uid string, s3cfg *models.S3Config) (string, error)
+	// TransferSession transfers an active session from a namespace member to another one, what must be allowed by the
+	// namespace's settings. The session's client is asked to accept the transfer and, when it does, the session is
+	// closed on its side to be taken by the target member.
+	TransferSession(ctx context.Context, sessionUID, fromMemberID, toMemberID string) error
+	// WatchSession watches the frames of an active session's recording as they're stored, what must be allowed by the
+	// namespace's settings. The frames are delivered on the returned channel until ctx is done, when it's closed.
+	//
+	// The number of simultaneous watchers of a session is limited by [models.NamespaceSettings.LiveMonitorsLimit].
+	WatchSession(ctx context.Context, uid string) (<-chan models.SessionFrame, error)
 }
 
-func (s *service) ListSessions(ctx context.Context, paginator query.Paginator) ([]models.Session, int, error) {
-	return s.store.SessionList(ctx, paginator)
+func (s *service) ListSessions(ctx context.Context, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.Session, int, error) {
+	return s.store.SessionList(ctx, paginator, filters, sorter)
 }
 
 func (s *service) GetSession(ctx context.Context, uid models.UID) (*models.Session, error) {
 	session, err := s.store.SessionGet(ctx, uid)
 	if err != nil {
-		return nil, NewErrSessionNotFound(uid, err)
+		if err == store.ErrNoDocuments {
+			return nil, NewErrSessionNotFound(uid, err)
+		}
+
+		return nil, err
 	}
 
 	return session, nil
@@ -35,7 +102,7 @@ func (s *service) GetSession(ctx context.Context, uid models.UID) (*models.Sessi
 func (s *service) CreateSession(ctx context.Context, session requests.SessionCreate) (*models.Session, error) {
 	position, _ := s.locator.GetPosition(net.ParseIP(session.IPAddress))
 
-	return s.store.SessionCreate(ctx, models.Session{
+	created, err := s.store.SessionCreate(ctx, models.Session{
 		UID:       session.UID,
 		DeviceUID: models.UID(session.DeviceUID),
 		Username:  session.Username,
@@ -47,6 +114,26 @@ func (s *service) CreateSession(ctx context.Context, session requests.SessionCre
 			Latitude:  position.Latitude,
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A session opened on a device keeps its namespace active, preventing it from expiring due to inactivity. Failing
+	// to renew the namespace must not prevent the session from being created.
+	if created.TenantID != "" {
+		if err := s.RenewNamespace(ctx, created.TenantID); err != nil {
+			logger.FromContext(ctx).WithError(err).WithField("tenant_id", created.TenantID).Warn("failed to renew the namespace of the session")
+		}
+
+		s.emitWebhookEvent(ctx, created.TenantID, models.WebhookEventSessionStarted, map[string]string{
+			"uid":        created.UID,
+			"device_uid": string(created.DeviceUID),
+			"username":   created.Username,
+			"ip_address": created.IPAddress,
+		})
+	}
+
+	return created, nil
 }
 
 func (s *service) DeactivateSession(ctx context.Context, uid models.UID) error {
@@ -59,13 +146,22 @@ func (s *service) DeactivateSession(ctx context.Context, uid models.UID) error {
 }
 
 func (s *service) KeepAliveSession(ctx context.Context, uid models.UID) error {
-	return s.store.SessionSetLastSeen(ctx, uid)
+	err := s.store.SessionSetLastSeen(ctx, uid)
+	if err == store.ErrNoDocuments {
+		return NewErrSessionNotFound(uid, err)
+	}
+
+	return err
 }
 
 func (s *service) UpdateSession(ctx context.Context, uid models.UID, model models.SessionUpdate) error {
 	sess, err := s.store.SessionGet(ctx, uid)
 	if err != nil {
-		return NewErrSessionNotFound(uid, err)
+		if err == store.ErrNoDocuments {
+			return NewErrSessionNotFound(uid, err)
+		}
+
+		return err
 	}
 
 	var insertActiveSession bool
@@ -82,7 +178,23 @@ func (s *service) UpdateSession(ctx context.Context, uid models.UID, model model
 		sess.Type = *model.Type
 	}
 
+	if model.RecordingPausedDuration != nil {
+		sess.RecordingPausedDuration = *model.RecordingPausedDuration
+	}
+
+	if model.ClientFingerprint != nil {
+		sess.ClientFingerprint = *model.ClientFingerprint
+	}
+
+	if model.MaxDurationExceeded != nil {
+		sess.MaxDurationExceeded = *model.MaxDurationExceeded
+	}
+
 	if err := s.store.SessionUpdate(ctx, uid, sess); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrSessionNotFound(uid, err)
+		}
+
 		return err
 	}
 
@@ -92,3 +204,504 @@ func (s *service) UpdateSession(ctx context.Context, uid models.UID, model model
 
 	return nil
 }
+
+func (s *service) IncrementSessionViewCount(ctx context.Context, uid models.UID) error {
+	err := s.store.SessionIncrementViewCount(ctx, uid)
+	if err == store.ErrNoDocuments {
+		return NewErrSessionNotFound(uid, err)
+	}
+
+	return err
+}
+
+func (s *service) ListTopViewedSessions(ctx context.Context, tenantID string, limit int) ([]models.Session, error) {
+	return s.store.TopViewedSessions(ctx, tenantID, limit)
+}
+
+func (s *service) ListLiveSessions(ctx context.Context, tenantID string) ([]models.LiveSession, error) {
+	live, err := s.client.(req.Client).ListLiveSessions(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.store.SessionListActives(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	persisted := make(map[string]bool, len(stored))
+	for _, session := range stored {
+		persisted[session.UID] = true
+	}
+
+	sessions := make([]models.LiveSession, 0, len(live)+len(stored))
+	inMemory := make(map[string]bool, len(live))
+	for _, session := range live {
+		inMemory[session.UID] = true
+
+		session.InMemory = true
+		session.Stored = persisted[session.UID]
+		sessions = append(sessions, session)
+	}
+
+	for _, session := range stored {
+		if inMemory[session.UID] {
+			continue
+		}
+
+		sessions = append(sessions, models.LiveSession{
+			UID:       session.UID,
+			DeviceUID: session.DeviceUID,
+			TenantID:  session.TenantID,
+			Username:  session.Username,
+			IPAddress: session.IPAddress,
+			StartedAt: session.StartedAt,
+			InMemory:  false,
+			Stored:    true,
+		})
+	}
+
+	return sessions, nil
+}
+
+func (s *service) ListSessionRecordFrames(ctx context.Context, uid models.UID) ([]models.RecordedSession, error) {
+	if _, err := s.store.SessionGet(ctx, uid); err != nil {
+		if err == store.ErrNoDocuments {
+			return nil, NewErrSessionNotFound(uid, err)
+		}
+
+		return nil, err
+	}
+
+	frames, err := s.recordings.Retrieve(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range frames {
+		if err := s.records.Open(&frames[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return frames, nil
+}
+
+func (s *service) StreamSessionRecordFrames(ctx context.Context, uid models.UID, fn func(frame *models.RecordedSession) error) error {
+	if _, err := s.store.SessionGet(ctx, uid); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrSessionNotFound(uid, err)
+		}
+
+		return err
+	}
+
+	return s.streamSessionRecordFrames(ctx, uid, fn)
+}
+
+// streamSessionRecordFrames calls fn for each frame recorded on a session, decrypted when the recordings are encrypted
+// at rest.
+func (s *service) streamSessionRecordFrames(ctx context.Context, uid models.UID, fn func(frame *models.RecordedSession) error) error {
+	return s.recordings.Stream(ctx, uid, func(frame *models.RecordedSession) error {
+		if err := s.records.Open(frame); err != nil {
+			return err
+		}
+
+		return fn(frame)
+	})
+}
+
+func (s *service) UploadSessionRecord(ctx context.Context, uid models.UID, offset int64, body io.Reader) (*models.RecordingUploadState, error) {
+	session, err := s.store.SessionGet(ctx, uid)
+	if err != nil {
+		return nil, NewErrSessionNotFound(uid, err)
+	}
+
+	state := new(models.RecordingUploadState)
+	if session.RecordUpload != nil {
+		*state = *session.RecordUpload
+	}
+
+	if offset != state.BytesReceived {
+		return state, NewErrSessionRecordOffset(offset, state.BytesReceived)
+	}
+
+	decoder := recording.NewDecoder(body, recording.MaxFrameSize)
+	batch := make([]models.RecordedSession, 0, RecordSessionBatchSize)
+	// live holds the frames of the batch, in plain text, to be published to the watchers once the batch is stored, so
+	// they never see frames that were not persisted.
+	live := make([]models.SessionFrame, 0, RecordSessionBatchSize)
+	// NOTICE: the bytes of a frame are only counted when its batch is stored, keeping the state consistent with the
+	// frames on the store when the upload is interrupted.
+	var pending int64
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := s.recordings.Store(ctx, uid, batch); err != nil {
+			return err
+		}
+
+		for _, frame := range live {
+			s.publishSessionFrame(ctx, uid, frame)
+		}
+
+		next := *state
+		next.BytesReceived += pending
+		next.FramesStored += int64(len(batch))
+		batch, live, pending = batch[:0], live[:0], 0
+
+		// NOTICE: the state is only set when it's still the one the batch was stored from, so when another upload of
+		// the same recording moved it meanwhile, this one stops as its offset doesn't match anymore.
+		if err := s.store.SessionSetRecordUploadState(ctx, uid, state, &next); err != nil {
+			if !errors.Is(err, store.ErrNoDocuments) {
+				return err
+			}
+
+			current, err := s.store.SessionGet(ctx, uid)
+			if err != nil {
+				return NewErrSessionNotFound(uid, err)
+			}
+
+			var received int64
+			if current.RecordUpload != nil {
+				received = current.RecordUpload.BytesReceived
+			}
+
+			return NewErrSessionRecordOffset(state.BytesReceived, received)
+		}
+
+		*state = next
+
+		return nil
+	}
+
+	for {
+		frame, n, err := decoder.Decode()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+
+		if err != nil {
+			// NOTICE: the frames decoded before the failure are still stored, so the upload can be resumed from them.
+			if ferr := flush(); ferr != nil {
+				return state, ferr
+			}
+
+			if errors.Is(err, recording.ErrFrameTooLarge) || errors.Is(err, recording.ErrFrameTooSmall) {
+				return state, NewErrSessionRecordInvalid(err)
+			}
+
+			return state, err
+		}
+
+		frame.UID = uid
+		frame.TenantID = session.TenantID
+
+		// NOTICE: the frame is kept for its watchers before being encrypted, as they receive it in plain text.
+		live = append(live, models.SessionFrame{
+			Time:    frame.Time,
+			Message: frame.Message,
+			Width:   frame.Width,
+			Height:  frame.Height,
+		})
+
+		if err := s.records.Seal(frame); err != nil {
+			return state, err
+		}
+
+		batch = append(batch, *frame)
+		pending += n
+
+		if len(batch) == RecordSessionBatchSize {
+			if err := flush(); err != nil {
+				return state, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return state, err
+	}
+
+	return state, nil
+}
+
+func (s *service) AnalyzeRecording(ctx context.Context, uid string) (*models.RecordingAnalysis, error) {
+	session, err := s.store.SessionGet(ctx, models.UID(uid))
+	if err != nil {
+		return nil, NewErrSessionNotFound(models.UID(uid), err)
+	}
+
+	analyzer := newRecordingAnalyzer(RecordingIdleThreshold)
+	if err := s.streamSessionRecordFrames(ctx, models.UID(uid), analyzer.add); err != nil {
+		return nil, err
+	}
+
+	analysis := analyzer.analysis()
+
+	// NOTICE: the recording of an active session can still grow, so only the summary of closed ones is kept.
+	if session.Closed {
+		if err := s.store.SessionSetRecordingSummary(ctx, models.UID(uid), analysis.Summary()); err != nil {
+			logger.FromContext(ctx).
+				WithError(err).
+				WithField("uid", uid).
+				Warn("unable to set the summary of the session's recording")
+		}
+	}
+
+	return analysis, nil
+}
+
+// recordingAnalyzer accumulates the analysis of a recording from its frames, ordered by their time.
+type recordingAnalyzer struct {
+	threshold time.Duration
+	start     time.Time
+	last      time.Time
+	result    models.RecordingAnalysis
+}
+
+func newRecordingAnalyzer(threshold time.Duration) *recordingAnalyzer {
+	return &recordingAnalyzer{threshold: threshold, result: models.RecordingAnalysis{IdleGaps: []models.IdleGap{}}}
+}
+
+// add accumulates a frame into the analysis.
+func (a *recordingAnalyzer) add(frame *models.RecordedSession) error {
+	if a.start.IsZero() {
+		a.start, a.last = frame.Time, frame.Time
+	}
+
+	if gap := frame.Time.Sub(a.last); gap >= a.threshold {
+		a.result.IdleGaps = append(a.result.IdleGaps, models.IdleGap{
+			StartMS:    a.last.Sub(a.start).Milliseconds(),
+			EndMS:      frame.Time.Sub(a.start).Milliseconds(),
+			DurationMS: gap.Milliseconds(),
+		})
+	}
+
+	if frame.Time.After(a.last) {
+		a.last = frame.Time
+	}
+
+	a.result.BytesOutput += len(frame.Message)
+	if r, size := utf8.DecodeRuneInString(frame.Message); size > 0 && size == len(frame.Message) && unicode.IsPrint(r) {
+		a.result.CharactersTyped++
+	}
+
+	return nil
+}
+
+// analysis returns the analysis of the frames added.
+func (a *recordingAnalyzer) analysis() *models.RecordingAnalysis {
+	analysis := a.result
+
+	total := a.last.Sub(a.start)
+
+	idle := time.Duration(0)
+	for _, gap := range analysis.IdleGaps {
+		idle += time.Duration(gap.DurationMS) * time.Millisecond
+	}
+
+	analysis.TotalDuration = total.Seconds()
+	analysis.ActiveDuration = (total - idle).Seconds()
+
+	return &analysis
+}
+
+func (s *service) SessionExportS3(ctx context.Context, uid string, s3cfg *models.S3Config) (string, error) {
+	if s3cfg == nil || s3cfg.Bucket == "" {
+		return "", NewErrSessionExportInvalid(nil)
+	}
+
+	session, err := s.store.SessionGet(ctx, models.UID(uid))
+	if err != nil {
+		if err == store.ErrNoDocuments {
+			return "", NewErrSessionNotFound(models.UID(uid), err)
+		}
+
+		return "", err
+	}
+
+	options := s3.Options{
+		Region: s3cfg.Region,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: s3cfg.AccessKey, SecretAccessKey: s3cfg.SecretKey}, nil
+		}),
+	}
+
+	// NOTICE: S3-compatible object storages, like MinIO, usually don't support virtual-hosted–style requests.
+	if s3cfg.Endpoint != "" {
+		options.BaseEndpoint = aws.String(s3cfg.Endpoint)
+		options.UsePathStyle = true
+	}
+
+	client := s3.New(options)
+
+	// NOTICE: the recording is written to the pipe while it is uploaded, so it never is fully loaded in memory.
+	reader, writer := io.Pipe()
+	go func() {
+		compressor := gzip.NewWriter(writer)
+		recording := replay.NewWriter(compressor)
+
+		err := s.streamSessionRecordFrames(ctx, models.UID(uid), recording.WriteFrame)
+		if err == nil {
+			err = recording.Close()
+		}
+
+		if err == nil {
+			err = compressor.Close()
+		}
+
+		writer.CloseWithError(err)
+	}()
+
+	output, err := manager.NewUploader(client).Upload(ctx, &s3.PutObjectInput{
+		Bucket:          aws.String(s3cfg.Bucket),
+		Key:             aws.String(fmt.Sprintf("sessions/%s/%s.cast.gz", session.TenantID, uid)),
+		Body:            reader,
+		ContentType:     aws.String(replay.ContentType),
+		ContentEncoding: aws.String("gzip"),
+	})
+	if err != nil {
+		reader.CloseWithError(err)
+
+		return "", err
+	}
+
+	if err := s.store.SessionSetStorage(ctx, models.UID(uid), models.SessionStorageBackendS3, output.Location); err != nil {
+		return "", err
+	}
+
+	return output.Location, nil
+}
+
+func (s *service) TransferSession(ctx context.Context, sessionUID, fromMemberID, toMemberID string) error {
+	session, err := s.store.SessionGet(ctx, models.UID(sessionUID))
+	if err != nil {
+		return NewErrSessionNotFound(models.UID(sessionUID), err)
+	}
+
+	if !session.Active {
+		return NewErrSessionTransferInvalid(map[string]interface{}{"uid": sessionUID}, nil)
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, session.TenantID, false)
+	if err != nil {
+		return NewErrNamespaceNotFound(session.TenantID, err)
+	}
+
+	if namespace.Settings == nil || !namespace.Settings.TransferSessionEnabled {
+		return NewErrSessionTransferDisabled(nil)
+	}
+
+	for _, id := range []string{fromMemberID, toMemberID} {
+		if _, ok := namespace.FindMember(id); !ok {
+			return NewErrNamespaceMemberNotFound(id, nil)
+		}
+	}
+
+	if fromMemberID == toMemberID {
+		return NewErrSessionTransferInvalid(map[string]interface{}{"member_id": toMemberID}, nil)
+	}
+
+	if err := s.client.(req.Client).TransferSession(session.TenantID, sessionUID, toMemberID); err != nil {
+		switch {
+		case errors.Is(err, req.ErrNotFound):
+			return NewErrSessionNotFound(models.UID(sessionUID), err)
+		case errors.Is(err, req.ErrSessionTransferRejected):
+			return NewErrSessionTransferRejected(err)
+		case errors.Is(err, req.ErrSessionTransferTimeout):
+			return NewErrSessionTransferTimeout(err)
+		default:
+			return err
+		}
+	}
+
+	logger.FromContext(ctx).WithFields(log.Fields{
+		"uid":       sessionUID,
+		"tenant_id": session.TenantID,
+		"from":      fromMemberID,
+		"to":        toMemberID,
+	}).Info("session transferred")
+
+	return nil
+}
+
+// sessionLiveChannel returns the channel where the frames of the session's recording are published as they're received.
+func sessionLiveChannel(uid string) string {
+	return "session:live:" + uid
+}
+
+// publishSessionFrame publishes a frame of the session's recording to its watchers. As watching is a best-effort
+// feature, a failure doesn't interrupt the recording.
+func (s *service) publishSessionFrame(ctx context.Context, uid models.UID, frame models.SessionFrame) {
+	message, err := json.Marshal(frame)
+	if err != nil {
+		return
+	}
+
+	if err := s.cache.Publish(ctx, sessionLiveChannel(string(uid)), message); err != nil {
+		logger.FromContext(ctx).WithError(err).WithField("uid", uid).Warn("failed to publish the session frame")
+	}
+}
+
+func (s *service) WatchSession(ctx context.Context, uid string) (<-chan models.SessionFrame, error) {
+	session, err := s.store.SessionGet(ctx, models.UID(uid))
+	if err != nil {
+		return nil, NewErrSessionNotFound(models.UID(uid), err)
+	}
+
+	if !session.Active {
+		return nil, NewErrSessionLiveInactive(uid, nil)
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, session.TenantID, false)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(session.TenantID, err)
+	}
+
+	if namespace.Settings == nil || !namespace.Settings.LiveMonitoringEnabled {
+		return nil, NewErrSessionLiveDisabled(nil)
+	}
+
+	channel := sessionLiveChannel(uid)
+
+	// NOTICE: the watchers are counted by the subscribers of the session's channel, so the limit is shared by every API
+	// replica. Watchers connecting at the same time may exceed it by a few.
+	watchers, err := s.cache.Subscribers(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit := namespace.Settings.LiveMonitorsLimit(); watchers >= limit {
+		return nil, NewErrSessionLiveMonitorsLimit(limit, nil)
+	}
+
+	messages, err := s.cache.Subscribe(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make(chan models.SessionFrame)
+
+	go func() {
+		defer close(frames)
+
+		for message := range messages {
+			var frame models.SessionFrame
+			if err := json.Unmarshal(message, &frame); err != nil {
+				continue
+			}
+
+			select {
+			case frames <- frame:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return frames, nil
+}
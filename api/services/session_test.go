@@ -1,21 +1,36 @@
 package services
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"io"
 	"net"
+	"runtime"
 	"testing"
+	"time"
 
 	goerrors "errors"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/shellhub-io/shellhub/api/pkg/recordcipher"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	mockcache "github.com/shellhub-io/shellhub/pkg/cache/mocks"
 	"github.com/shellhub-io/shellhub/pkg/geoip"
 	mocksGeoIp "github.com/shellhub-io/shellhub/pkg/geoip/mocks"
 	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/recording"
 	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
 )
 
 func TestListSessions(t *testing.T) {
@@ -39,7 +54,7 @@ func TestListSessions(t *testing.T) {
 			description: "fails",
 			paginator:   query.Paginator{Page: 1, PerPage: 10},
 			requiredMocks: func(paginator query.Paginator) {
-				mock.On("SessionList", ctx, paginator).
+				mock.On("SessionList", ctx, paginator, query.Filters{}, query.Sorter{}).
 					Return(nil, 0, goerrors.New("error")).Once()
 			},
 			expected: Expected{
@@ -57,7 +72,7 @@ func TestListSessions(t *testing.T) {
 					{UID: "uid2"},
 					{UID: "uid3"},
 				}
-				mock.On("SessionList", ctx, paginator).
+				mock.On("SessionList", ctx, paginator, query.Filters{}, query.Sorter{}).
 					Return(sessions, len(sessions), nil).Once()
 			},
 			expected: Expected{
@@ -81,7 +96,7 @@ func TestListSessions(t *testing.T) {
 			tc.requiredMocks(tc.paginator)
 
 			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
-			returnedSessions, count, err := service.ListSessions(ctx, tc.paginator)
+			returnedSessions, count, err := service.ListSessions(ctx, tc.paginator, query.Filters{}, query.Sorter{})
 			assert.Equal(t, tc.expected, Expected{returnedSessions, count, err})
 		})
 	}
@@ -109,13 +124,25 @@ func TestGetSession(t *testing.T) {
 		{
 			name: "fails when session is not found",
 			uid:  models.UID("_uid"),
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("_uid")).
+					Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{
+				session: nil,
+				err:     NewErrSessionNotFound(models.UID("_uid"), store.ErrNoDocuments),
+			},
+		},
+		{
+			name: "fails",
+			uid:  models.UID("_uid"),
 			requiredMocks: func() {
 				mock.On("SessionGet", ctx, models.UID("_uid")).
 					Return(nil, goerrors.New("error")).Once()
 			},
 			expected: Expected{
 				session: nil,
-				err:     NewErrSessionNotFound(models.UID("_uid"), goerrors.New("error")),
+				err:     goerrors.New("error"),
 			},
 		},
 		{
@@ -201,6 +228,40 @@ func TestCreateSession(t *testing.T) {
 				err:     nil,
 			},
 		},
+		{
+			name:    "succeeds renewing the namespace of the session",
+			session: req,
+			requiredMocks: func() {
+				locator.On("GetPosition", net.ParseIP(model.IPAddress)).
+					Return(geoip.Position{}, nil).Once()
+				mock.On("SessionCreate", ctx, model).
+					Return(&models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("NamespaceRenew", ctx, "00000000-0000-4000-0000-000000000000", now).
+					Return(nil).Once()
+			},
+			expected: Expected{
+				session: &models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"},
+				err:     nil,
+			},
+		},
+		{
+			name:    "succeeds even when the namespace fails to renew",
+			session: req,
+			requiredMocks: func() {
+				locator.On("GetPosition", net.ParseIP(model.IPAddress)).
+					Return(geoip.Position{}, nil).Once()
+				mock.On("SessionCreate", ctx, model).
+					Return(&models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("NamespaceRenew", ctx, "00000000-0000-4000-0000-000000000000", now).
+					Return(Err).Once()
+			},
+			expected: Expected{
+				session: &models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"},
+				err:     nil,
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -269,12 +330,67 @@ func TestDeactivateSession(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
+func TestKeepAliveSession(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	cases := []struct {
+		name          string
+		uid           models.UID
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			name: "fails when session is not found",
+			uid:  models.UID("_uid"),
+			requiredMocks: func() {
+				mock.On("SessionSetLastSeen", ctx, models.UID("_uid")).
+					Return(store.ErrNoDocuments).Once()
+			},
+			expected: NewErrSessionNotFound("_uid", store.ErrNoDocuments),
+		},
+		{
+			name: "fails",
+			uid:  models.UID("_uid"),
+			requiredMocks: func() {
+				mock.On("SessionSetLastSeen", ctx, models.UID("_uid")).
+					Return(goerrors.New("error")).Once()
+			},
+			expected: goerrors.New("error"),
+		},
+		{
+			name: "succeeds",
+			uid:  models.UID("uid"),
+			requiredMocks: func() {
+				mock.On("SessionSetLastSeen", ctx, models.UID("uid")).
+					Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := service.KeepAliveSession(ctx, tc.uid)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
 func TestUpdateSession(t *testing.T) {
 	mock := new(mocks.Store)
 
 	ctx := context.TODO()
 
 	theTrue := true
+	paused := int64(30000)
+	fingerprint := "fingerprint"
 
 	cases := []struct {
 		name          string
@@ -283,6 +399,15 @@ func TestUpdateSession(t *testing.T) {
 		requiredMocks func()
 		expected      error
 	}{
+		{
+			name:  "fails when session is not found",
+			uid:   models.UID("_uid"),
+			model: models.SessionUpdate{},
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("_uid")).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: NewErrSessionNotFound(models.UID("_uid"), store.ErrNoDocuments),
+		},
 		{
 			name:  "fails whne cannot get the sessioni",
 			uid:   models.UID("_uid"),
@@ -290,7 +415,7 @@ func TestUpdateSession(t *testing.T) {
 			requiredMocks: func() {
 				mock.On("SessionGet", ctx, models.UID("_uid")).Return(nil, goerrors.New("error")).Once()
 			},
-			expected: NewErrSessionNotFound(models.UID("_uid"), goerrors.New("error")),
+			expected: goerrors.New("error"),
 		},
 		{
 			name:  "fails to update the session",
@@ -348,6 +473,42 @@ func TestUpdateSession(t *testing.T) {
 			},
 			expected: nil,
 		},
+		{
+			name: "success to update the session when the recording paused duration is updated",
+			uid:  models.UID("_uid"),
+			model: models.SessionUpdate{
+				RecordingPausedDuration: &paused,
+			},
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("_uid")).Return(&models.Session{}, nil).Once()
+				mock.On("SessionUpdate", ctx, models.UID("_uid"), &models.Session{RecordingPausedDuration: paused}).Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			name: "success to update the session when the client fingerprint is updated",
+			uid:  models.UID("_uid"),
+			model: models.SessionUpdate{
+				ClientFingerprint: &fingerprint,
+			},
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("_uid")).Return(&models.Session{}, nil).Once()
+				mock.On("SessionUpdate", ctx, models.UID("_uid"), &models.Session{ClientFingerprint: "fingerprint"}).Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			name: "success to update the session when it exceeded the maximum duration",
+			uid:  models.UID("_uid"),
+			model: models.SessionUpdate{
+				MaxDurationExceeded: &theTrue,
+			},
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("_uid")).Return(&models.Session{}, nil).Once()
+				mock.On("SessionUpdate", ctx, models.UID("_uid"), &models.Session{MaxDurationExceeded: true}).Return(nil).Once()
+			},
+			expected: nil,
+		},
 	}
 
 	for _, tc := range cases {
@@ -362,3 +523,1277 @@ func TestUpdateSession(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestIncrementSessionViewCount(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	cases := []struct {
+		name          string
+		uid           models.UID
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			name: "fails when session is not found",
+			uid:  models.UID("_uid"),
+			requiredMocks: func() {
+				mock.On("SessionIncrementViewCount", ctx, models.UID("_uid")).
+					Return(store.ErrNoDocuments).Once()
+			},
+			expected: NewErrSessionNotFound("_uid", store.ErrNoDocuments),
+		},
+		{
+			name: "fails",
+			uid:  models.UID("_uid"),
+			requiredMocks: func() {
+				mock.On("SessionIncrementViewCount", ctx, models.UID("_uid")).
+					Return(goerrors.New("error")).Once()
+			},
+			expected: goerrors.New("error"),
+		},
+		{
+			name: "succeeds",
+			uid:  models.UID("uid"),
+			requiredMocks: func() {
+				mock.On("SessionIncrementViewCount", ctx, models.UID("uid")).
+					Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := service.IncrementSessionViewCount(ctx, tc.uid)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListTopViewedSessions(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	type Expected struct {
+		sessions []models.Session
+		err      error
+	}
+
+	cases := []struct {
+		name          string
+		tenantID      string
+		limit         int
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			name:     "fails",
+			tenantID: "00000000-0000-4000-0000-000000000000",
+			limit:    10,
+			requiredMocks: func() {
+				mock.On("TopViewedSessions", ctx, "00000000-0000-4000-0000-000000000000", 10).
+					Return(nil, goerrors.New("error")).Once()
+			},
+			expected: Expected{sessions: nil, err: goerrors.New("error")},
+		},
+		{
+			name:     "succeeds",
+			tenantID: "00000000-0000-4000-0000-000000000000",
+			limit:    10,
+			requiredMocks: func() {
+				mock.On("TopViewedSessions", ctx, "00000000-0000-4000-0000-000000000000", 10).
+					Return([]models.Session{{UID: "uid", ViewCount: 2}}, nil).Once()
+			},
+			expected: Expected{sessions: []models.Session{{UID: "uid", ViewCount: 2}}, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			sessions, err := service.ListTopViewedSessions(ctx, tc.tenantID, tc.limit)
+			assert.Equal(t, tc.expected, Expected{sessions: sessions, err: err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListLiveSessions(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	startedAt := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type Expected struct {
+		sessions []models.LiveSession
+		err      error
+	}
+
+	cases := []struct {
+		description   string
+		tenantID      string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the SSH server cannot be reached",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				clientMock.
+					On("ListLiveSessions", "00000000-0000-4000-0000-000000000000").
+					Return(nil, internalclient.ErrConnectionFailed).
+					Once()
+			},
+			expected: Expected{sessions: nil, err: internalclient.ErrConnectionFailed},
+		},
+		{
+			description: "fails when the store fails to list the active sessions",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				clientMock.
+					On("ListLiveSessions", "00000000-0000-4000-0000-000000000000").
+					Return([]models.LiveSession{}, nil).
+					Once()
+				mock.
+					On("SessionListActives", ctx, "00000000-0000-4000-0000-000000000000").
+					Return(nil, goerrors.New("error")).
+					Once()
+			},
+			expected: Expected{sessions: nil, err: goerrors.New("error")},
+		},
+		{
+			description: "succeeds reconciling in memory and stored sessions",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			requiredMocks: func() {
+				clientMock.
+					On("ListLiveSessions", "00000000-0000-4000-0000-000000000000").
+					Return([]models.LiveSession{
+						{UID: "both", DeviceUID: "device", TenantID: "00000000-0000-4000-0000-000000000000", Username: "root", StartedAt: startedAt, InMemory: true},
+						{UID: "memory", DeviceUID: "device", TenantID: "00000000-0000-4000-0000-000000000000", Username: "root", StartedAt: startedAt, InMemory: true},
+					}, nil).
+					Once()
+				mock.
+					On("SessionListActives", ctx, "00000000-0000-4000-0000-000000000000").
+					Return([]models.Session{
+						{UID: "both", DeviceUID: "device", TenantID: "00000000-0000-4000-0000-000000000000", Username: "root", StartedAt: startedAt, Active: true},
+						{UID: "stored", DeviceUID: "device", TenantID: "00000000-0000-4000-0000-000000000000", Username: "root", StartedAt: startedAt, Active: true},
+					}, nil).
+					Once()
+			},
+			expected: Expected{
+				sessions: []models.LiveSession{
+					{UID: "both", DeviceUID: "device", TenantID: "00000000-0000-4000-0000-000000000000", Username: "root", StartedAt: startedAt, InMemory: true, Stored: true},
+					{UID: "memory", DeviceUID: "device", TenantID: "00000000-0000-4000-0000-000000000000", Username: "root", StartedAt: startedAt, InMemory: true, Stored: false},
+					{UID: "stored", DeviceUID: "device", TenantID: "00000000-0000-4000-0000-000000000000", Username: "root", StartedAt: startedAt, InMemory: false, Stored: true},
+				},
+				err: nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			sessions, err := service.ListLiveSessions(ctx, tc.tenantID)
+			assert.Equal(t, tc.expected, Expected{sessions, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListSessionRecordFrames(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	type Expected struct {
+		frames []models.RecordedSession
+		err    error
+	}
+
+	cases := []struct {
+		description   string
+		uid           models.UID
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when session is not found",
+			uid:         models.UID("_uid"),
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("_uid")).
+					Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{frames: nil, err: NewErrSessionNotFound("_uid", store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the store fails to list the frames",
+			uid:         models.UID("uid"),
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid"}, nil).Once()
+				mock.On("SessionListRecordFrames", ctx, models.UID("uid")).
+					Return(nil, goerrors.New("error")).Once()
+			},
+			expected: Expected{frames: nil, err: goerrors.New("error")},
+		},
+		{
+			description: "succeeds",
+			uid:         models.UID("uid"),
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid"}, nil).Once()
+				mock.On("SessionListRecordFrames", ctx, models.UID("uid")).
+					Return([]models.RecordedSession{{UID: "uid", Message: "message"}}, nil).Once()
+			},
+			expected: Expected{frames: []models.RecordedSession{{UID: "uid", Message: "message"}}, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			frames, err := service.ListSessionRecordFrames(ctx, tc.uid)
+			assert.Equal(t, tc.expected, Expected{frames, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestStreamSessionRecordFrames(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	frames := []models.RecordedSession{{UID: "uid", Message: "a"}, {UID: "uid", Message: "b"}}
+
+	cases := []struct {
+		description   string
+		uid           models.UID
+		requiredMocks func()
+		expected      []string
+		err           error
+	}{
+		{
+			description: "fails when session is not found",
+			uid:         models.UID("_uid"),
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("_uid")).
+					Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: []string{},
+			err:      NewErrSessionNotFound("_uid", store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the store fails to stream the frames",
+			uid:         models.UID("uid"),
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid"}, nil).Once()
+				mock.On("SessionStreamRecordFrames", ctx, models.UID("uid"), testifymock.Anything).
+					Return(goerrors.New("error")).Once()
+			},
+			expected: []string{},
+			err:      goerrors.New("error"),
+		},
+		{
+			description: "succeeds",
+			uid:         models.UID("uid"),
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid"}, nil).Once()
+				mock.On("SessionStreamRecordFrames", ctx, models.UID("uid"), testifymock.Anything).
+					Return(func(_ context.Context, _ models.UID, fn func(*models.RecordedSession) error) error {
+						for i := range frames {
+							if err := fn(&frames[i]); err != nil {
+								return err
+							}
+						}
+
+						return nil
+					}).Once()
+			},
+			expected: []string{"a", "b"},
+			err:      nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+			messages := []string{}
+			err := service.StreamSessionRecordFrames(ctx, tc.uid, func(frame *models.RecordedSession) error {
+				messages = append(messages, frame.Message)
+
+				return nil
+			})
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expected, messages)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSessionExportS3(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	frames := []models.RecordedSession{
+		{UID: "uid", Message: "a", Time: start, Width: 80, Height: 24},
+		{UID: "uid", Message: "b", Time: start.Add(time.Second)},
+	}
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	t.Run("fails when the config is missing", func(t *testing.T) {
+		location, err := s.SessionExportS3(context.Background(), "uid", nil)
+		assert.Equal(t, NewErrSessionExportInvalid(nil), err)
+		assert.Empty(t, location)
+	})
+
+	t.Run("fails when the session is not found", func(t *testing.T) {
+		ctx := context.Background()
+
+		storeMock.
+			On("SessionGet", ctx, models.UID("uid")).
+			Return(nil, store.ErrNoDocuments).
+			Once()
+
+		location, err := s.SessionExportS3(ctx, "uid", &models.S3Config{Bucket: "sessions"})
+		assert.Equal(t, NewErrSessionNotFound(models.UID("uid"), store.ErrNoDocuments), err)
+		assert.Empty(t, location)
+	})
+
+	t.Run("succeeds exporting the recording to MinIO", func(t *testing.T) {
+		ctx := context.Background()
+
+		container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:        "minio/minio:RELEASE.2024-01-16T16-07-38Z",
+				Cmd:          []string{"server", "/data"},
+				ExposedPorts: []string{"9000/tcp"},
+				Env:          map[string]string{"MINIO_ROOT_USER": "minioadmin", "MINIO_ROOT_PASSWORD": "minioadmin"},
+				WaitingFor:   wait.ForHTTP("/minio/health/live").WithPort("9000/tcp"),
+			},
+			Started: true,
+		})
+		if err != nil {
+			t.Skipf("MinIO container is not available: %s", err)
+		}
+
+		t.Cleanup(func() {
+			assert.NoError(t, container.Terminate(ctx))
+		})
+
+		endpoint, err := container.PortEndpoint(ctx, "9000/tcp", "http")
+		require.NoError(t, err)
+
+		cfg := &models.S3Config{
+			Endpoint:  endpoint,
+			Bucket:    "sessions",
+			AccessKey: "minioadmin",
+			SecretKey: "minioadmin",
+			Region:    "us-east-1",
+		}
+
+		client := s3.New(s3.Options{
+			Region: cfg.Region,
+			Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: cfg.AccessKey, SecretAccessKey: cfg.SecretKey}, nil
+			}),
+			BaseEndpoint: aws.String(cfg.Endpoint),
+			UsePathStyle: true,
+		})
+
+		_, err = client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(cfg.Bucket)})
+		require.NoError(t, err)
+
+		storeMock.
+			On("SessionGet", ctx, models.UID("uid")).
+			Return(&models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+			Once()
+		storeMock.
+			On("SessionStreamRecordFrames", ctx, models.UID("uid"), testifymock.Anything).
+			Return(func(_ context.Context, _ models.UID, fn func(*models.RecordedSession) error) error {
+				for i := range frames {
+					if err := fn(&frames[i]); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			}).
+			Once()
+		storeMock.
+			On("SessionSetStorage", ctx, models.UID("uid"), models.SessionStorageBackendS3, testifymock.AnythingOfType("string")).
+			Return(nil).
+			Once()
+
+		location, err := s.SessionExportS3(ctx, "uid", cfg)
+		require.NoError(t, err)
+		assert.Contains(t, location, "sessions/00000000-0000-4000-0000-000000000000/uid.cast.gz")
+
+		object, err := client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(cfg.Bucket),
+			Key:    aws.String("sessions/00000000-0000-4000-0000-000000000000/uid.cast.gz"),
+		})
+		require.NoError(t, err)
+		defer object.Body.Close()
+
+		reader, err := gzip.NewReader(object.Body)
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		assert.Equal(t, `{"version":2,"width":80,"height":24,"timestamp":1672574400}`+"\n"+
+			`[0,"o","a"]`+"\n"+
+			`[1,"o","b"]`+"\n", string(data))
+	})
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestAnalyzeRecording(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+
+	// The frames have two synthetic idle gaps: from 1s to 7s and from 8s to 20s. The pause from 7s to 8s is below
+	// the threshold.
+	frames := []models.RecordedSession{
+		{Time: at(0), Message: "$ "},
+		{Time: at(500), Message: "l"},
+		{Time: at(1000), Message: "s"},
+		{Time: at(7000), Message: "\r\n"},
+		{Time: at(8000), Message: "file\r\n$ "},
+		{Time: at(20000), Message: "é"},
+	}
+
+	stream := func(frames []models.RecordedSession) func(context.Context, models.UID, func(*models.RecordedSession) error) error {
+		return func(_ context.Context, _ models.UID, fn func(*models.RecordedSession) error) error {
+			for i := range frames {
+				if err := fn(&frames[i]); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	analysis := &models.RecordingAnalysis{
+		TotalDuration:  20,
+		ActiveDuration: 2,
+		IdleGaps: []models.IdleGap{
+			{StartMS: 1000, EndMS: 7000, DurationMS: 6000},
+			{StartMS: 8000, EndMS: 20000, DurationMS: 12000},
+		},
+		CharactersTyped: 3,
+		BytesOutput:     16,
+	}
+
+	type Expected struct {
+		analysis *models.RecordingAnalysis
+		err      error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the session is not found",
+			requiredMocks: func() {
+				storeMock.On("SessionGet", ctx, models.UID("uid")).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{err: NewErrSessionNotFound(models.UID("uid"), store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the frames cannot be streamed",
+			requiredMocks: func() {
+				storeMock.On("SessionGet", ctx, models.UID("uid")).Return(&models.Session{UID: "uid"}, nil).Once()
+				storeMock.
+					On("SessionStreamRecordFrames", ctx, models.UID("uid"), testifymock.Anything).
+					Return(goerrors.New("error")).
+					Once()
+			},
+			expected: Expected{err: goerrors.New("error")},
+		},
+		{
+			description: "succeeds analyzing an empty recording",
+			requiredMocks: func() {
+				storeMock.On("SessionGet", ctx, models.UID("uid")).Return(&models.Session{UID: "uid"}, nil).Once()
+				storeMock.
+					On("SessionStreamRecordFrames", ctx, models.UID("uid"), testifymock.Anything).
+					Return(stream(nil)).
+					Once()
+			},
+			expected: Expected{analysis: &models.RecordingAnalysis{IdleGaps: []models.IdleGap{}}},
+		},
+		{
+			description: "succeeds analyzing the recording of an active session without setting its summary",
+			requiredMocks: func() {
+				storeMock.On("SessionGet", ctx, models.UID("uid")).Return(&models.Session{UID: "uid"}, nil).Once()
+				storeMock.
+					On("SessionStreamRecordFrames", ctx, models.UID("uid"), testifymock.Anything).
+					Return(stream(frames)).
+					Once()
+			},
+			expected: Expected{analysis: analysis},
+		},
+		{
+			description: "succeeds analyzing the recording of a closed session and setting its summary",
+			requiredMocks: func() {
+				storeMock.On("SessionGet", ctx, models.UID("uid")).Return(&models.Session{UID: "uid", Closed: true}, nil).Once()
+				storeMock.
+					On("SessionStreamRecordFrames", ctx, models.UID("uid"), testifymock.Anything).
+					Return(stream(frames)).
+					Once()
+				// NOTICE: failing to set the summary doesn't fail the analysis.
+				storeMock.
+					On("SessionSetRecordingSummary", ctx, models.UID("uid"), &models.RecordingSummary{TotalDuration: 20, ActiveDuration: 2, IdleGaps: 2}).
+					Return(goerrors.New("error")).
+					Once()
+			},
+			expected: Expected{analysis: analysis},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			analysis, err := s.AnalyzeRecording(ctx, "uid")
+			assert.Equal(t, tc.expected, Expected{analysis, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+// encodeFrames encodes count frames with the message "frame", each taking 21 bytes on the stream.
+func encodeFrames(t *testing.T, count int) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	for i := 0; i < count; i++ {
+		frame := &models.RecordedSession{Message: "frame", Time: time.Unix(int64(i), 0), Width: 80, Height: 24}
+		require.NoError(t, recording.WriteFrame(buf, frame))
+	}
+
+	return buf.Bytes()
+}
+
+func TestUploadSessionRecord(t *testing.T) {
+	type Expected struct {
+		state *models.RecordingUploadState
+		err   error
+	}
+
+	storeMock := new(mocks.Store)
+
+	batchOf := func(size int) interface{} {
+		return testifymock.MatchedBy(func(frames []models.RecordedSession) bool {
+			return len(frames) == size && frames[0].UID == "uid" && frames[0].TenantID == "00000000-0000-4000-0000-000000000000"
+		})
+	}
+
+	cases := []struct {
+		description   string
+		offset        int64
+		body          []byte
+		requiredMocks func(ctx context.Context)
+		expected      Expected
+	}{
+		{
+			description: "fails when the session is not found",
+			body:        encodeFrames(t, 1),
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(nil, store.ErrNoDocuments).
+					Once()
+			},
+			expected: Expected{nil, NewErrSessionNotFound(models.UID("uid"), store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the offset doesn't match the bytes received",
+			offset:      10,
+			body:        encodeFrames(t, 1),
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid", RecordUpload: &models.RecordingUploadState{BytesReceived: 42, FramesStored: 2}}, nil).
+					Once()
+			},
+			expected: Expected{&models.RecordingUploadState{BytesReceived: 42, FramesStored: 2}, NewErrSessionRecordOffset(10, 42)},
+		},
+		{
+			description: "fails when a frame is larger than allowed, storing the frames before it",
+			body:        append(encodeFrames(t, 1), 0xff, 0xff, 0xff, 0xff),
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+				storeMock.
+					On("SessionCreateRecordFrames", ctx, batchOf(1)).
+					Return(nil).
+					Once()
+				storeMock.
+					On(
+						"SessionSetRecordUploadState",
+						ctx,
+						models.UID("uid"),
+						&models.RecordingUploadState{},
+						&models.RecordingUploadState{BytesReceived: 21, FramesStored: 1},
+					).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{
+				&models.RecordingUploadState{BytesReceived: 21, FramesStored: 1},
+				NewErrSessionRecordInvalid(recording.ErrFrameTooLarge),
+			},
+		},
+		{
+			description: "fails when the frames cannot be stored",
+			body:        encodeFrames(t, 1),
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+				storeMock.
+					On("SessionCreateRecordFrames", ctx, batchOf(1)).
+					Return(goerrors.New("error")).
+					Once()
+			},
+			expected: Expected{&models.RecordingUploadState{}, goerrors.New("error")},
+		},
+		{
+			description: "fails when another upload moved the state meanwhile",
+			body:        encodeFrames(t, 1),
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+				storeMock.
+					On("SessionCreateRecordFrames", ctx, batchOf(1)).
+					Return(nil).
+					Once()
+				storeMock.
+					On(
+						"SessionSetRecordUploadState",
+						ctx,
+						models.UID("uid"),
+						&models.RecordingUploadState{},
+						&models.RecordingUploadState{BytesReceived: 21, FramesStored: 1},
+					).
+					Return(store.ErrNoDocuments).
+					Once()
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid", RecordUpload: &models.RecordingUploadState{BytesReceived: 21, FramesStored: 1}}, nil).
+					Once()
+			},
+			expected: Expected{&models.RecordingUploadState{}, NewErrSessionRecordOffset(0, 21)},
+		},
+		{
+			description: "succeeds storing the frames in batches",
+			body:        encodeFrames(t, 250),
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+				storeMock.
+					On("SessionCreateRecordFrames", ctx, batchOf(100)).
+					Return(nil).
+					Twice()
+				storeMock.
+					On(
+						"SessionSetRecordUploadState",
+						ctx,
+						models.UID("uid"),
+						&models.RecordingUploadState{},
+						&models.RecordingUploadState{BytesReceived: 2100, FramesStored: 100},
+					).
+					Return(nil).
+					Once()
+				storeMock.
+					On(
+						"SessionSetRecordUploadState",
+						ctx,
+						models.UID("uid"),
+						&models.RecordingUploadState{BytesReceived: 2100, FramesStored: 100},
+						&models.RecordingUploadState{BytesReceived: 4200, FramesStored: 200},
+					).
+					Return(nil).
+					Once()
+				storeMock.
+					On("SessionCreateRecordFrames", ctx, batchOf(50)).
+					Return(nil).
+					Once()
+				storeMock.
+					On(
+						"SessionSetRecordUploadState",
+						ctx,
+						models.UID("uid"),
+						&models.RecordingUploadState{BytesReceived: 4200, FramesStored: 200},
+						&models.RecordingUploadState{BytesReceived: 5250, FramesStored: 250},
+					).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{&models.RecordingUploadState{BytesReceived: 5250, FramesStored: 250}, nil},
+		},
+		{
+			description: "succeeds resuming the upload, ignoring a truncated frame at the end",
+			offset:      42,
+			body:        append(encodeFrames(t, 1), encodeFrames(t, 1)[:10]...),
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{
+						UID:          "uid",
+						TenantID:     "00000000-0000-4000-0000-000000000000",
+						RecordUpload: &models.RecordingUploadState{BytesReceived: 42, FramesStored: 2},
+					}, nil).
+					Once()
+				storeMock.
+					On("SessionCreateRecordFrames", ctx, batchOf(1)).
+					Return(nil).
+					Once()
+				storeMock.
+					On(
+						"SessionSetRecordUploadState",
+						ctx,
+						models.UID("uid"),
+						&models.RecordingUploadState{BytesReceived: 42, FramesStored: 2},
+						&models.RecordingUploadState{BytesReceived: 63, FramesStored: 3},
+					).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{&models.RecordingUploadState{BytesReceived: 63, FramesStored: 3}, nil},
+		},
+	}
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			tc.requiredMocks(ctx)
+
+			state, err := s.UploadSessionRecord(ctx, models.UID("uid"), tc.offset, bytes.NewReader(tc.body))
+			assert.Equal(t, tc.expected, Expected{state, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestUploadSessionRecordPublish(t *testing.T) {
+	ctx := context.Background()
+
+	session := &models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"}
+
+	t.Run("does not publish the frames that fail to be stored", func(t *testing.T) {
+		storeMock := new(mocks.Store)
+		cacheMock := new(mockcache.Cache)
+
+		storeMock.On("SessionGet", ctx, models.UID("uid")).Return(session, nil).Once()
+		storeMock.On("SessionCreateRecordFrames", ctx, testifymock.Anything).Return(goerrors.New("error")).Once()
+
+		s := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+
+		_, err := s.UploadSessionRecord(ctx, models.UID("uid"), 0, bytes.NewReader(encodeFrames(t, 2)))
+		assert.Equal(t, goerrors.New("error"), err)
+
+		cacheMock.AssertNotCalled(t, "Publish", testifymock.Anything, testifymock.Anything, testifymock.Anything)
+		storeMock.AssertExpectations(t)
+	})
+
+	t.Run("publishes the frames once they're stored", func(t *testing.T) {
+		storeMock := new(mocks.Store)
+		cacheMock := new(mockcache.Cache)
+
+		storeMock.On("SessionGet", ctx, models.UID("uid")).Return(session, nil).Once()
+		storeMock.On("SessionCreateRecordFrames", ctx, testifymock.Anything).Return(nil).Once()
+		storeMock.On("SessionSetRecordUploadState", ctx, models.UID("uid"), testifymock.Anything, testifymock.Anything).Return(nil).Once()
+		cacheMock.On("Publish", ctx, sessionLiveChannel("uid"), testifymock.Anything).Return(nil).Twice()
+
+		s := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+
+		_, err := s.UploadSessionRecord(ctx, models.UID("uid"), 0, bytes.NewReader(encodeFrames(t, 2)))
+		assert.NoError(t, err)
+
+		cacheMock.AssertExpectations(t)
+		storeMock.AssertExpectations(t)
+	})
+}
+
+// frameStream is an [io.Reader] producing frames until size bytes are read, without holding them in memory.
+type frameStream struct {
+	frame []byte
+	size  int64
+	read  int64
+}
+
+func (f *frameStream) Read(p []byte) (int, error) {
+	if f.read >= f.size {
+		return 0, io.EOF
+	}
+
+	n := 0
+	for n < len(p) && f.read < f.size {
+		c := copy(p[n:], f.frame[f.read%int64(len(f.frame)):])
+		n += c
+		f.read += int64(c)
+	}
+
+	return n, nil
+}
+
+func TestUploadSessionRecordMemory(t *testing.T) {
+	const size = 100 << 20
+
+	frame := new(bytes.Buffer)
+	require.NoError(t, recording.WriteFrame(frame, &models.RecordedSession{Message: string(bytes.Repeat([]byte("a"), 4080))}))
+	require.Equal(t, 4096, frame.Len())
+
+	storeMock := new(mocks.Store)
+	storeMock.
+		On("SessionGet", testifymock.Anything, models.UID("uid")).
+		Return(&models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"}, nil)
+	storeMock.
+		On("SessionCreateRecordFrames", testifymock.Anything, testifymock.Anything).
+		Return(nil)
+	storeMock.
+		On("SessionSetRecordUploadState", testifymock.Anything, models.UID("uid"), testifymock.Anything, testifymock.Anything).
+		Return(nil)
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	runtime.GC()
+
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	done := make(chan struct{})
+	peak := make(chan uint64)
+	go func() {
+		var highest uint64
+		var stats runtime.MemStats
+
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			runtime.ReadMemStats(&stats)
+			if stats.HeapInuse > highest {
+				highest = stats.HeapInuse
+			}
+
+			select {
+			case <-done:
+				peak <- highest
+
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	state, err := s.UploadSessionRecord(context.Background(), models.UID("uid"), 0, &frameStream{frame: frame.Bytes(), size: size})
+	close(done)
+	require.NoError(t, err)
+
+	assert.Equal(t, &models.RecordingUploadState{BytesReceived: size, FramesStored: size / 4096}, state)
+
+	// NOTICE: the frames are decoded while the stream is read, so the heap grows with the batch size, not with the size
+	// of the recording.
+	growth := int64(<-peak) - int64(before.HeapInuse)
+	t.Logf("peak heap growth uploading %d MiB: %d KiB", size>>20, growth>>10)
+	assert.Less(t, growth, int64(32<<20))
+}
+
+func TestSessionRecordEncryption(t *testing.T) {
+	ctx := context.Background()
+
+	storeMock := new(mocks.Store)
+
+	keyring, err := recordcipher.Parse("v1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	require.NoError(t, err)
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithRecordEncryption(keyring))
+
+	var stored []models.RecordedSession
+
+	storeMock.
+		On("SessionGet", ctx, models.UID("uid")).
+		Return(&models.Session{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+		Twice()
+	storeMock.
+		On("SessionCreateRecordFrames", ctx, testifymock.Anything).
+		Run(func(args testifymock.Arguments) {
+			stored = append(stored, args.Get(1).([]models.RecordedSession)...)
+		}).
+		Return(nil).
+		Once()
+	storeMock.
+		On(
+			"SessionSetRecordUploadState",
+			ctx,
+			models.UID("uid"),
+			&models.RecordingUploadState{},
+			&models.RecordingUploadState{BytesReceived: 42, FramesStored: 2},
+		).
+		Return(nil).
+		Once()
+
+	_, err = s.UploadSessionRecord(ctx, models.UID("uid"), 0, bytes.NewReader(encodeFrames(t, 2)))
+	require.NoError(t, err)
+
+	require.Len(t, stored, 2)
+	for _, frame := range stored {
+		assert.Equal(t, "v1", frame.KeyID)
+		assert.NotEqual(t, "frame", frame.Message)
+	}
+
+	storeMock.
+		On("SessionListRecordFrames", ctx, models.UID("uid")).
+		Return(stored, nil).
+		Once()
+
+	frames, err := s.ListSessionRecordFrames(ctx, models.UID("uid"))
+	require.NoError(t, err)
+
+	require.Len(t, frames, 2)
+	for _, frame := range frames {
+		assert.Equal(t, "", frame.KeyID)
+		assert.Equal(t, "frame", frame.Message)
+	}
+
+	storeMock.AssertExpectations(t)
+}
+
+func TestTransferSession(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	session := &models.Session{UID: "uid", TenantID: tenantID, Active: true}
+
+	namespace := func(enabled bool) *models.Namespace {
+		return &models.Namespace{
+			TenantID: tenantID,
+			Members:  []models.Member{{ID: "from"}, {ID: "to"}},
+			Settings: &models.NamespaceSettings{TransferSessionEnabled: enabled},
+		}
+	}
+
+	cases := []struct {
+		description   string
+		from          string
+		to            string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when the session is not found",
+			from:        "from",
+			to:          "to",
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: NewErrSessionNotFound("uid", store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the session is not active",
+			from:        "from",
+			to:          "to",
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).Return(&models.Session{UID: "uid", TenantID: tenantID}, nil).Once()
+			},
+			expected: NewErrSessionTransferInvalid(map[string]interface{}{"uid": "uid"}, nil),
+		},
+		{
+			description: "fails when the namespace does not allow transfers",
+			from:        "from",
+			to:          "to",
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).Return(session, nil).Once()
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace(false), nil).Once()
+			},
+			expected: NewErrSessionTransferDisabled(nil),
+		},
+		{
+			description: "fails when the target is not a member",
+			from:        "from",
+			to:          "other",
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).Return(session, nil).Once()
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace(true), nil).Once()
+			},
+			expected: NewErrNamespaceMemberNotFound("other", nil),
+		},
+		{
+			description: "fails when transferring to the same member",
+			from:        "from",
+			to:          "from",
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).Return(session, nil).Once()
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace(true), nil).Once()
+			},
+			expected: NewErrSessionTransferInvalid(map[string]interface{}{"member_id": "from"}, nil),
+		},
+		{
+			description: "fails when the client rejects the transfer",
+			from:        "from",
+			to:          "to",
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).Return(session, nil).Once()
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace(true), nil).Once()
+				clientMock.On("TransferSession", tenantID, "uid", "to").Return(internalclient.ErrSessionTransferRejected).Once()
+			},
+			expected: NewErrSessionTransferRejected(internalclient.ErrSessionTransferRejected),
+		},
+		{
+			description: "fails when the client does not answer in time",
+			from:        "from",
+			to:          "to",
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).Return(session, nil).Once()
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace(true), nil).Once()
+				clientMock.On("TransferSession", tenantID, "uid", "to").Return(internalclient.ErrSessionTransferTimeout).Once()
+			},
+			expected: NewErrSessionTransferTimeout(internalclient.ErrSessionTransferTimeout),
+		},
+		{
+			description: "succeeds when the client accepts the transfer",
+			from:        "from",
+			to:          "to",
+			requiredMocks: func() {
+				mock.On("SessionGet", ctx, models.UID("uid")).Return(session, nil).Once()
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(namespace(true), nil).Once()
+				clientMock.On("TransferSession", tenantID, "uid", "to").Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := service.TransferSession(ctx, "uid", tc.from, tc.to)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestWatchSession(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	namespace := &models.Namespace{TenantID: tenantID, Settings: &models.NamespaceSettings{LiveMonitoringEnabled: true}}
+
+	cases := []struct {
+		description   string
+		requiredMocks func(ctx context.Context)
+		expected      error
+	}{
+		{
+			description: "fails when the session is not found",
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(nil, store.ErrNoDocuments).
+					Once()
+			},
+			expected: NewErrSessionNotFound(models.UID("uid"), store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the session is not active",
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid", TenantID: tenantID}, nil).
+					Once()
+			},
+			expected: NewErrSessionLiveInactive("uid", nil),
+		},
+		{
+			description: "fails when the namespace doesn't allow live monitoring",
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid", TenantID: tenantID, Active: true}, nil).
+					Once()
+				storeMock.
+					On("NamespaceGet", ctx, tenantID, false).
+					Return(&models.Namespace{TenantID: tenantID, Settings: &models.NamespaceSettings{}}, nil).
+					Once()
+			},
+			expected: NewErrSessionLiveDisabled(nil),
+		},
+		{
+			description: "fails when the session has the maximum number of watchers",
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("SessionGet", ctx, models.UID("uid")).
+					Return(&models.Session{UID: "uid", TenantID: tenantID, Active: true}, nil).
+					Once()
+				storeMock.
+					On("NamespaceGet", ctx, tenantID, false).
+					Return(namespace, nil).
+					Once()
+				cacheMock.
+					On("Subscribers", ctx, "session:live:uid").
+					Return(models.DefaultMaxLiveMonitors, nil).
+					Once()
+			},
+			expected: NewErrSessionLiveMonitorsLimit(models.DefaultMaxLiveMonitors, nil),
+		},
+	}
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			tc.requiredMocks(ctx)
+
+			frames, err := s.WatchSession(ctx, "uid")
+			assert.Equal(t, tc.expected, err)
+			assert.Nil(t, frames)
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestWatchSessionRedis(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7.2-alpine",
+			ExposedPorts: []string{"6379/tcp"},
+			WaitingFor:   wait.ForLog("Ready to accept connections"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Skipf("Redis container is not available: %s", err)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, container.Terminate(ctx))
+	})
+
+	uri, err := container.PortEndpoint(ctx, "6379/tcp", "redis")
+	require.NoError(t, err)
+
+	cache, err := storecache.NewRedisCache(uri, 0)
+	require.NoError(t, err)
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	storeMock := new(mocks.Store)
+	storeMock.
+		On("SessionGet", testifymock.Anything, models.UID("uid")).
+		Return(&models.Session{UID: "uid", TenantID: tenantID, Active: true}, nil)
+	storeMock.
+		On("NamespaceGet", testifymock.Anything, tenantID, false).
+		Return(&models.Namespace{TenantID: tenantID, Settings: &models.NamespaceSettings{LiveMonitoringEnabled: true, MaxLiveMonitors: 1}}, nil)
+	storeMock.
+		On("SessionCreateRecordFrames", testifymock.Anything, testifymock.Anything).
+		Return(nil)
+	storeMock.
+		On("SessionSetRecordUploadState", testifymock.Anything, models.UID("uid"), testifymock.Anything, testifymock.Anything).
+		Return(nil)
+
+	s := NewService(store.Store(storeMock), privateKey, publicKey, cache, clientMock, nil)
+
+	t.Run("delivers the recorded frames to the watcher", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		frames, err := s.WatchSession(ctx, "uid")
+		require.NoError(t, err)
+
+		_, err = s.UploadSessionRecord(ctx, models.UID("uid"), 0, bytes.NewReader(encodeFrames(t, 3)))
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			select {
+			case frame := <-frames:
+				assert.WithinDuration(t, time.Unix(int64(i), 0), frame.Time, 0)
+				assert.Equal(t, "frame", frame.Message)
+				assert.Equal(t, 80, frame.Width)
+				assert.Equal(t, 24, frame.Height)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("frame %d was not delivered", i)
+			}
+		}
+
+		cancel()
+
+		_, ok := <-frames
+		assert.False(t, ok)
+	})
+
+	t.Run("fails when the session has the maximum number of watchers", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		_, err := s.WatchSession(ctx, "uid")
+		require.NoError(t, err)
+
+		_, err = s.WatchSession(ctx, "uid")
+		assert.Equal(t, NewErrSessionLiveMonitorsLimit(1, nil), err)
+	})
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"crypto/dsa" //nolint:staticcheck
+	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -9,11 +11,14 @@ import (
 	"regexp"
 
 	"github.com/shellhub-io/shellhub/api/store"
+	req "github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	"github.com/shellhub-io/shellhub/pkg/api/responses"
 	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/logger"
 	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -26,6 +31,12 @@ type SSHKeysService interface {
 	UpdatePublicKey(ctx context.Context, fingerprint, tenant string, key requests.PublicKeyUpdate) (*models.PublicKey, error)
 	DeletePublicKey(ctx context.Context, fingerprint, tenant string) error
 	CreatePrivateKey(ctx context.Context) (*models.PrivateKey, error)
+	// ImportPublicKeysFromGitHub creates a public key on the namespace for each key of the GitHub user account, with
+	// the username, filter and allowed IPs of the request. It returns the number of keys imported.
+	ImportPublicKeysFromGitHub(ctx context.Context, req requests.PublicKeyImport) (int, error)
+	// ImportPublicKeysFromGitLab creates a public key on the namespace for each key of the GitLab user account, with
+	// the username, filter and allowed IPs of the request. It returns the number of keys imported.
+	ImportPublicKeysFromGitLab(ctx context.Context, req requests.PublicKeyImport) (int, error)
 }
 
 type Request struct {
@@ -66,6 +77,31 @@ func (s *service) EvaluateKeyUsername(_ context.Context, key *models.PublicKey,
 	return ok, nil
 }
 
+// checkPublicKeyStrength checks if the public key is as strong as required by the namespace's settings. RSA keys must
+// have at least [models.NamespaceSettings.RSAMinBits] bits and DSA keys are only accepted when allowed. Other keys,
+// like ECDSA and Ed25519 ones, have no weak sizes and are always accepted.
+func checkPublicKeyStrength(settings *models.NamespaceSettings, key ssh.PublicKey) error {
+	crypto, ok := key.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil
+	}
+
+	switch k := crypto.CryptoPublicKey().(type) {
+	case *rsa.PublicKey:
+		if bits, required := k.N.BitLen(), settings.RSAMinBits(); bits < required {
+			return NewErrPublicKeyWeakKey(key.Type(), bits, required, nil)
+		}
+	case *dsa.PublicKey: //nolint:staticcheck
+		if settings == nil || !settings.AllowDSA {
+			return NewErrPublicKeyWeakKey(key.Type(), k.P.BitLen(), 0, nil)
+		}
+	case *ecdsa.PublicKey:
+		// NOTICE: SSH only supports the NIST P-256, P-384 and P-521 curves, all of them strong enough.
+	}
+
+	return nil
+}
+
 func (s *service) GetPublicKey(ctx context.Context, fingerprint, tenant string) (*models.PublicKey, error) {
 	if _, err := s.store.NamespaceGet(ctx, tenant, false); err != nil {
 		return nil, NewErrNamespaceNotFound(tenant, err)
@@ -90,11 +126,24 @@ func (s *service) CreatePublicKey(ctx context.Context, req requests.PublicKeyCre
 		}
 	}
 
+	if err := models.ValidateIPCIDR(req.AllowedIPs); err != nil {
+		return nil, NewErrPublicKeyInvalid(map[string]interface{}{"allowed_ips": req.AllowedIPs}, err)
+	}
+
 	pubKey, _, _, _, err := ssh.ParseAuthorizedKey(req.Data) //nolint:dogsled
 	if err != nil {
 		return nil, NewErrPublicKeyDataInvalid(req.Data, nil)
 	}
 
+	namespace, err := s.store.NamespaceGet(ctx, tenant, false)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(tenant, err)
+	}
+
+	if err := checkPublicKeyStrength(namespace.Settings, pubKey); err != nil {
+		return nil, err
+	}
+
 	req.Fingerprint = ssh.FingerprintLegacyMD5(pubKey)
 
 	returnedKey, err := s.store.PublicKeyGet(ctx, req.Fingerprint, tenant)
@@ -111,6 +160,8 @@ func (s *service) CreatePublicKey(ctx context.Context, req requests.PublicKeyCre
 		Fingerprint: req.Fingerprint,
 		CreatedAt:   clock.Now(),
 		TenantID:    req.TenantID,
+		CreatedBy:   req.CreatedBy,
+		Source:      req.Source,
 		PublicKeyFields: models.PublicKeyFields{
 			Name:     req.Name,
 			Username: req.Username,
@@ -118,6 +169,7 @@ func (s *service) CreatePublicKey(ctx context.Context, req requests.PublicKeyCre
 				Hostname: req.Filter.Hostname,
 				Tags:     req.Filter.Tags,
 			},
+			AllowedIPs: req.AllowedIPs,
 		},
 	}
 
@@ -133,6 +185,7 @@ func (s *service) CreatePublicKey(ctx context.Context, req requests.PublicKeyCre
 		Username:    model.Username,
 		TenantID:    model.TenantID,
 		Fingerprint: model.Fingerprint,
+		AllowedIPs:  model.AllowedIPs,
 	}, nil
 }
 
@@ -156,6 +209,10 @@ func (s *service) UpdatePublicKey(ctx context.Context, fingerprint, tenant strin
 		}
 	}
 
+	if err := models.ValidateIPCIDR(key.AllowedIPs); err != nil {
+		return nil, NewErrPublicKeyInvalid(map[string]interface{}{"allowed_ips": key.AllowedIPs}, err)
+	}
+
 	model := models.PublicKeyUpdate{
 		PublicKeyFields: models.PublicKeyFields{
 			Name:     key.Name,
@@ -164,6 +221,7 @@ func (s *service) UpdatePublicKey(ctx context.Context, fingerprint, tenant strin
 				Hostname: key.Filter.Hostname,
 				Tags:     key.Filter.Tags,
 			},
+			AllowedIPs: key.AllowedIPs,
 		},
 	}
 
@@ -179,7 +237,20 @@ func (s *service) DeletePublicKey(ctx context.Context, fingerprint, tenant strin
 		return NewErrPublicKeyNotFound(fingerprint, err)
 	}
 
-	return s.store.PublicKeyDelete(ctx, fingerprint, tenant)
+	if err := s.store.PublicKeyDelete(ctx, fingerprint, tenant); err != nil {
+		return err
+	}
+
+	// NOTICE: the sessions already authenticated with the removed public key are closed, as they would otherwise last
+	// until their clients disconnect. Failing to close them must not undo the removal.
+	if err := s.client.(req.Client).RevokePublicKeySessions(tenant, fingerprint); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithFields(log.Fields{"tenant_id": tenant, "fingerprint": fingerprint}).
+			Warn("failed to close the sessions authenticated with the removed public key")
+	}
+
+	return nil
 }
 
 func (s *service) CreatePrivateKey(ctx context.Context) (*models.PrivateKey, error) {
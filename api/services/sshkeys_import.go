@@ -0,0 +1,200 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/errors"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// PublicKeysImportLimit is the maximum number of times a namespace can import public keys in
+	// [PublicKeysImportWindow].
+	PublicKeysImportLimit = 3
+	// PublicKeysImportWindow is the window where the public key imports of a namespace are counted.
+	PublicKeysImportWindow = time.Hour
+	// PublicKeysImportTimeout is how long the public keys of a user account take to be retrieved from its source.
+	PublicKeysImportTimeout = 10 * time.Second
+	// PublicKeysImportMaxSize is the maximum size, in bytes, of the public keys retrieved from a source.
+	PublicKeysImportMaxSize = 1 << 20
+)
+
+// DefaultPublicKeySources are the URLs where the public keys of GitHub and GitLab user accounts are published, in the
+// authorized_keys format.
+var DefaultPublicKeySources = map[string]string{
+	models.PublicKeySourceGitHub: "https://github.com/%s.keys",
+	models.PublicKeySourceGitLab: "https://gitlab.com/%s.keys",
+}
+
+// publicKeySourceUsername matches the usernames accepted by both GitHub and GitLab.
+var publicKeySourceUsername = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,254}$`)
+
+// publicKeysImports counts the public key imports of a namespace in the current window, which ends at Reset.
+type publicKeysImports struct {
+	Count int       `json:"count"`
+	Reset time.Time `json:"reset"`
+}
+
+func publicKeysImportsCacheKey(tenantID string) string {
+	return "public-key-imports={" + tenantID + "}"
+}
+
+func (s *service) ImportPublicKeysFromGitHub(ctx context.Context, req requests.PublicKeyImport) (int, error) {
+	return s.importPublicKeys(ctx, models.PublicKeySourceGitHub, req)
+}
+
+func (s *service) ImportPublicKeysFromGitLab(ctx context.Context, req requests.PublicKeyImport) (int, error) {
+	return s.importPublicKeys(ctx, models.PublicKeySourceGitLab, req)
+}
+
+// importPublicKeys creates a public key for each key published by the user account on the source, through
+// [service.CreatePublicKey], so the imported keys are validated like the ones added directly. The keys weaker than
+// allowed by the namespace and the ones already added are skipped. It returns the number of keys created.
+//
+// An import only counts to [PublicKeysImportLimit] once the keys are retrieved from the source.
+func (s *service) importPublicKeys(ctx context.Context, source string, req requests.PublicKeyImport) (int, error) {
+	if !publicKeySourceUsername.MatchString(req.Account) {
+		return 0, NewErrPublicKeyImportInvalid(source, req.Account, nil)
+	}
+
+	if err := models.ValidateIPCIDR(req.AllowedIPs); err != nil {
+		return 0, NewErrPublicKeyInvalid(map[string]interface{}{"allowed_ips": req.AllowedIPs}, err)
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, req.Tenant, false)
+	if err != nil {
+		return 0, NewErrNamespaceNotFound(req.Tenant, err)
+	}
+
+	imports, err := s.getPublicKeysImports(ctx, req.Tenant)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := s.fetchPublicKeys(ctx, source, req.Account)
+	if err != nil {
+		return 0, NewErrPublicKeyImportInvalid(source, req.Account, err)
+	}
+
+	s.countPublicKeysImport(ctx, req.Tenant, imports)
+
+	imported := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		key, _, _, _, err := ssh.ParseAuthorizedKey(line) //nolint:dogsled
+		if err != nil {
+			continue
+		}
+
+		if err := checkPublicKeyStrength(namespace.Settings, key); err != nil {
+			continue
+		}
+
+		_, err = s.CreatePublicKey(ctx, requests.PublicKeyCreate{
+			Data:       ssh.MarshalAuthorizedKey(key),
+			Filter:     req.Filter,
+			Name:       req.Account + "@" + source,
+			Username:   req.Username,
+			TenantID:   req.Tenant,
+			CreatedBy:  req.CreatedBy,
+			AllowedIPs: req.AllowedIPs,
+			Source:     source,
+		}, req.Tenant)
+
+		var duplicated errors.Error
+		if errors.As(err, &duplicated) && duplicated.Code == ErrCodeDuplicated {
+			continue
+		}
+
+		if err != nil {
+			return imported, err
+		}
+
+		imported++
+	}
+
+	return imported, nil
+}
+
+// getPublicKeysImports gets the public key imports of the namespace in the current window, failing when it already
+// reached [PublicKeysImportLimit].
+func (s *service) getPublicKeysImports(ctx context.Context, tenantID string) (*publicKeysImports, error) {
+	now := clock.Now()
+
+	imports := new(publicKeysImports)
+	if err := s.cache.Get(ctx, publicKeysImportsCacheKey(tenantID), imports); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("tenant_id", tenantID).
+			Warn("unable to get the public key imports from cache")
+	}
+
+	if !imports.Reset.After(now) {
+		imports = &publicKeysImports{Count: 0, Reset: now.Add(PublicKeysImportWindow)}
+	}
+
+	if imports.Count >= PublicKeysImportLimit {
+		return nil, NewErrPublicKeyImportLimit(PublicKeysImportLimit, nil)
+	}
+
+	return imports, nil
+}
+
+// countPublicKeysImport counts an import of public keys by the namespace on its imports in the current window.
+//
+// NOTICE: the count isn't atomic, so concurrent imports can go slightly over the limit.
+func (s *service) countPublicKeysImport(ctx context.Context, tenantID string, imports *publicKeysImports) {
+	imports.Count++
+	if err := s.cache.Set(ctx, publicKeysImportsCacheKey(tenantID), imports, imports.Reset.Sub(clock.Now())); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("tenant_id", tenantID).
+			Warn("unable to set the public key imports in cache")
+	}
+}
+
+// fetchPublicKeys retrieves the public keys published by the user account on the source, in the authorized_keys
+// format.
+func (s *service) fetchPublicKeys(ctx context.Context, source, username string) ([]byte, error) {
+	address, ok := s.keySources[source]
+	if !ok {
+		return nil, fmt.Errorf("unknown source %q", source)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, PublicKeysImportTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(address, url.PathEscape(username)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, PublicKeysImportMaxSize))
+}
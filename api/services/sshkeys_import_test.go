@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	goerrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	mockcache "github.com/shellhub-io/shellhub/pkg/cache/mocks"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmock "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestImportPublicKeys(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	backend := clock.DefaultBackend
+	t.Cleanup(func() {
+		clock.DefaultBackend = backend
+	})
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	newKey := func(t *testing.T) ssh.PublicKey {
+		public, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		key, err := ssh.NewPublicKey(public)
+		require.NoError(t, err)
+
+		return key
+	}
+
+	imported, existing := newKey(t), newKey(t)
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	weakKey, err := ssh.NewPublicKey(&weak.PublicKey)
+	require.NoError(t, err)
+
+	keys := string(ssh.MarshalAuthorizedKey(imported)) +
+		string(ssh.MarshalAuthorizedKey(existing)) +
+		string(ssh.MarshalAuthorizedKey(weakKey)) +
+		"\ninvalid\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/github/user.keys", "/gitlab/user.keys":
+			_, _ = w.Write([]byte(keys))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	sources := map[string]string{
+		models.PublicKeySourceGitHub: server.URL + "/github/%s.keys",
+		models.PublicKeySourceGitLab: server.URL + "/gitlab/%s.keys",
+	}
+
+	type Expected struct {
+		imported int
+		err      error
+	}
+
+	cases := []struct {
+		description   string
+		source        string
+		username      string
+		allowedIPs    []string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description:   "fails when the username is invalid",
+			source:        models.PublicKeySourceGitHub,
+			username:      "../user",
+			requiredMocks: func() {},
+			expected:      Expected{0, NewErrPublicKeyImportInvalid(models.PublicKeySourceGitHub, "../user", nil)},
+		},
+		{
+			description: "fails when the namespace is not found",
+			source:      models.PublicKeySourceGitHub,
+			username:    "user",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{0, NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments)},
+		},
+		{
+			description:   "fails when an allowed IP is malformed",
+			source:        models.PublicKeySourceGitHub,
+			username:      "user",
+			allowedIPs:    []string{"10.0.0.0/33"},
+			requiredMocks: func() {},
+			expected:      Expected{0, NewErrPublicKeyInvalid(map[string]interface{}{"allowed_ips": []string{"10.0.0.0/33"}}, models.ErrIPCIDR)},
+		},
+		{
+			description: "fails when the namespace reached the import limit",
+			source:      models.PublicKeySourceGitHub,
+			username:    "user",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				cacheMock.On("Get", ctx, "public-key-imports={"+tenantID+"}", testifymock.Anything).
+					Run(func(args testifymock.Arguments) {
+						*args.Get(2).(*publicKeysImports) = publicKeysImports{Count: PublicKeysImportLimit, Reset: now.Add(time.Minute)}
+					}).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{0, NewErrPublicKeyImportLimit(PublicKeysImportLimit, nil)},
+		},
+		{
+			description: "fails when the user account is not found on the source",
+			source:      models.PublicKeySourceGitHub,
+			username:    "unknown",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				// NOTICE: the import isn't counted when the keys can't be retrieved.
+				cacheMock.On("Get", ctx, "public-key-imports={"+tenantID+"}", testifymock.Anything).Return(nil).Once()
+			},
+			expected: Expected{0, NewErrPublicKeyImportInvalid(models.PublicKeySourceGitHub, "unknown", goerrors.New("unexpected status 404"))},
+		},
+		{
+			description: "succeeds importing only the new keys as strong as required from GitHub",
+			source:      models.PublicKeySourceGitHub,
+			username:    "user",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				cacheMock.On("Get", ctx, "public-key-imports={"+tenantID+"}", testifymock.Anything).
+					Run(func(args testifymock.Arguments) {
+						*args.Get(2).(*publicKeysImports) = publicKeysImports{Count: 1, Reset: now.Add(time.Minute)}
+					}).
+					Return(nil).
+					Once()
+				cacheMock.On("Set", ctx, "public-key-imports={"+tenantID+"}", testifymock.MatchedBy(func(imports *publicKeysImports) bool {
+					return imports.Count == 2
+				}), time.Minute).Return(nil).Once()
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Twice()
+				storeMock.On("PublicKeyGet", ctx, ssh.FingerprintLegacyMD5(imported), tenantID).Return(nil, store.ErrNoDocuments).Once()
+				storeMock.On("PublicKeyGet", ctx, ssh.FingerprintLegacyMD5(existing), tenantID).Return(&models.PublicKey{}, nil).Once()
+				storeMock.On("PublicKeyCreate", ctx, testifymock.MatchedBy(func(key *models.PublicKey) bool {
+					return key.Fingerprint == ssh.FingerprintLegacyMD5(imported) &&
+						key.TenantID == tenantID &&
+						key.CreatedBy == "actor" &&
+						key.Source == models.PublicKeySourceGitHub &&
+						key.Name == "user@github" &&
+						key.Username == "deploy" &&
+						key.Filter.Hostname == "^web-" &&
+						len(key.AllowedIPs) == 1
+				})).Return(nil).Once()
+			},
+			expected: Expected{1, nil},
+		},
+		{
+			description: "succeeds importing the keys from GitLab",
+			source:      models.PublicKeySourceGitLab,
+			username:    "user",
+			requiredMocks: func() {
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				cacheMock.On("Get", ctx, "public-key-imports={"+tenantID+"}", testifymock.Anything).Return(nil).Once()
+				cacheMock.On("Set", ctx, "public-key-imports={"+tenantID+"}", testifymock.Anything, PublicKeysImportWindow).Return(nil).Once()
+				storeMock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Twice()
+				storeMock.On("PublicKeyGet", ctx, ssh.FingerprintLegacyMD5(imported), tenantID).Return(nil, store.ErrNoDocuments).Once()
+				storeMock.On("PublicKeyGet", ctx, ssh.FingerprintLegacyMD5(existing), tenantID).Return(nil, store.ErrNoDocuments).Once()
+				storeMock.On("PublicKeyCreate", ctx, testifymock.MatchedBy(func(key *models.PublicKey) bool {
+					return key.Source == models.PublicKeySourceGitLab
+				})).Return(nil).Twice()
+			},
+			expected: Expected{2, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil, WithPublicKeySources(sources))
+
+			allowedIPs := tc.allowedIPs
+			if allowedIPs == nil {
+				allowedIPs = []string{"203.0.113.0/24"}
+			}
+
+			req := requests.PublicKeyImport{
+				TenantParam: requests.TenantParam{Tenant: tenantID},
+				Account:     tc.username,
+				Username:    "deploy",
+				Filter:      requests.PublicKeyFilter{Hostname: "^web-"},
+				AllowedIPs:  allowedIPs,
+				CreatedBy:   "actor",
+			}
+
+			var imported int
+			var err error
+			switch tc.source {
+			case models.PublicKeySourceGitHub:
+				imported, err = service.ImportPublicKeysFromGitHub(ctx, req)
+			case models.PublicKeySourceGitLab:
+				imported, err = service.ImportPublicKeysFromGitLab(ctx, req)
+			}
+
+			assert.Equal(t, tc.expected, Expected{imported, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
@@ -2,6 +2,12 @@ package services
 
 import (
 	"context"
+	"crypto/dsa" //nolint:staticcheck
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"testing"
 
 	"github.com/shellhub-io/shellhub/api/store"
@@ -14,6 +20,7 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/errors"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -430,6 +437,58 @@ func TestUpdatePublicKeys(t *testing.T) {
 				},
 			}, nil},
 		},
+		{
+			description: "fails to update the key when an allowed IP is malformed",
+			fingerprint: "fingerprint",
+			tenantID:    "tenant",
+			keyUpdate: requests.PublicKeyUpdate{
+				Filter: requests.PublicKeyFilter{
+					Hostname: ".*",
+				},
+				AllowedIPs: []string{"10.0.0.0/8", "10.0.0.0/33"},
+			},
+			requiredMocks: func() {},
+			expected:      Expected{nil, NewErrPublicKeyInvalid(map[string]interface{}{"allowed_ips": []string{"10.0.0.0/8", "10.0.0.0/33"}}, models.ErrIPCIDR)},
+		},
+		{
+			description: "succeeds to update the key with allowed IPs",
+			fingerprint: "fingerprint",
+			tenantID:    "tenant",
+			keyUpdate: requests.PublicKeyUpdate{
+				Filter: requests.PublicKeyFilter{
+					Hostname: ".*",
+				},
+				AllowedIPs: []string{"203.0.113.7", "2001:db8::/32"},
+			},
+			requiredMocks: func() {
+				model := models.PublicKeyUpdate{
+					PublicKeyFields: models.PublicKeyFields{
+						Filter: models.PublicKeyFilter{
+							Hostname: ".*",
+						},
+						AllowedIPs: []string{"203.0.113.7", "2001:db8::/32"},
+					},
+				}
+
+				key := &models.PublicKey{
+					PublicKeyFields: models.PublicKeyFields{
+						Filter: models.PublicKeyFilter{
+							Hostname: ".*",
+						},
+						AllowedIPs: []string{"203.0.113.7", "2001:db8::/32"},
+					},
+				}
+				mock.On("PublicKeyUpdate", ctx, "fingerprint", "tenant", &model).Return(key, nil).Once()
+			},
+			expected: Expected{&models.PublicKey{
+				PublicKeyFields: models.PublicKeyFields{
+					Filter: models.PublicKeyFilter{
+						Hostname: ".*",
+					},
+					AllowedIPs: []string{"203.0.113.7", "2001:db8::/32"},
+				},
+			}, nil},
+		},
 	}
 
 	for _, tc := range cases {
@@ -449,7 +508,7 @@ func TestDeletePublicKeys(t *testing.T) {
 
 	ctx := context.TODO()
 
-	clockMock.On("Now").Return(now).Twice()
+	clockMock.On("Now").Return(now).Times(3)
 
 	s := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
 
@@ -529,6 +588,29 @@ func TestDeletePublicKeys(t *testing.T) {
 						PublicKeyFields: models.PublicKeyFields{Name: "teste"},
 					}, nil).Once()
 				mock.On("PublicKeyDelete", ctx, "fingerprint", "tenant1").Return(nil).Once()
+				clientMock.On("RevokePublicKeySessions", "tenant1", "fingerprint").Return(nil).Once()
+			},
+			expected: Expected{nil},
+		},
+		{
+			description: "Successful to delete the key when its sessions could not be closed",
+			ctx:         ctx,
+			fingerprint: "fingerprint",
+			tenantID:    "tenant1",
+			requiredMocks: func() {
+				namespace := &models.Namespace{TenantID: "tenant1"}
+
+				mock.On("NamespaceGet", ctx, namespace.TenantID, false).Return(namespace, nil).Once()
+				mock.On("PublicKeyGet", ctx, "fingerprint", namespace.TenantID).
+					Return(&models.PublicKey{
+						Data:            []byte("teste"),
+						Fingerprint:     "fingerprint",
+						CreatedAt:       clock.Now(),
+						TenantID:        "tenant1",
+						PublicKeyFields: models.PublicKeyFields{Name: "teste"},
+					}, nil).Once()
+				mock.On("PublicKeyDelete", ctx, "fingerprint", "tenant1").Return(nil).Once()
+				clientMock.On("RevokePublicKeySessions", "tenant1", "fingerprint").Return(errors.New("error", "", 0)).Once()
 			},
 			expected: Expected{nil},
 		},
@@ -623,6 +705,46 @@ func TestCreatePublicKeys(t *testing.T) {
 				},
 			}.Data, nil)},
 		},
+		{
+			description: "fail when an allowed IP is malformed",
+			tenantID:    "tenant",
+			req: requests.PublicKeyCreate{
+				Data:       ssh.MarshalAuthorizedKey(pubKey),
+				TenantID:   "tenant",
+				Filter:     requests.PublicKeyFilter{Hostname: ".*"},
+				AllowedIPs: []string{"not an ip"},
+			},
+			requiredMocks: func() {},
+			expected:      Expected{nil, NewErrPublicKeyInvalid(map[string]interface{}{"allowed_ips": []string{"not an ip"}}, models.ErrIPCIDR)},
+		},
+		{
+			description: "fail when the namespace does not exist",
+			tenantID:    "tenant",
+			req: requests.PublicKeyCreate{
+				Data:     ssh.MarshalAuthorizedKey(pubKey),
+				TenantID: "tenant",
+				Filter:   requests.PublicKeyFilter{Hostname: ".*"},
+			},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(nil, errors.New("error", "", 0)).Once()
+			},
+			expected: Expected{nil, NewErrNamespaceNotFound("tenant", errors.New("error", "", 0))},
+		},
+		{
+			description: "fail when the public key is weaker than allowed by the namespace",
+			tenantID:    "tenant",
+			req: requests.PublicKeyCreate{
+				Data:     ssh.MarshalAuthorizedKey(pubKey),
+				TenantID: "tenant",
+				Filter:   requests.PublicKeyFilter{Hostname: ".*"},
+			},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant", false).
+					Return(&models.Namespace{TenantID: "tenant", Settings: &models.NamespaceSettings{MinRSABits: 4096}}, nil).
+					Once()
+			},
+			expected: Expected{nil, NewErrPublicKeyWeakKey("ssh-rsa", 2048, 4096, nil)},
+		},
 		{
 			description: "fail when cannot get the public key",
 			tenantID:    "tenant",
@@ -644,6 +766,7 @@ func TestCreatePublicKeys(t *testing.T) {
 					},
 				}
 
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
 				mock.On("PublicKeyGet", ctx, keyWithHostname.Fingerprint, "tenant").Return(nil, errors.New("error", "", 0)).Once()
 			},
 			expected: Expected{nil, NewErrPublicKeyNotFound(requests.PublicKeyCreate{
@@ -688,6 +811,7 @@ func TestCreatePublicKeys(t *testing.T) {
 					},
 				}
 
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
 				mock.On("PublicKeyGet", ctx, keyWithHostname.Fingerprint, "tenant").Return(&keyWithHostnameModel, nil).Once()
 			},
 			expected: Expected{nil, NewErrPublicKeyDuplicated([]string{ssh.FingerprintLegacyMD5(pubKey)}, nil)},
@@ -725,6 +849,7 @@ func TestCreatePublicKeys(t *testing.T) {
 					},
 				}
 
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
 				mock.On("PublicKeyGet", ctx, keyWithHostname.Fingerprint, "tenant").Return(nil, nil).Once()
 				mock.On("PublicKeyCreate", ctx, &keyWithHostnameModel).Return(errors.New("error", "", 0)).Once()
 			},
@@ -763,6 +888,7 @@ func TestCreatePublicKeys(t *testing.T) {
 					},
 				}
 
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
 				mock.On("PublicKeyGet", ctx, keyWithHostname.Fingerprint, "tenant").Return(nil, nil).Once()
 				mock.On("PublicKeyCreate", ctx, &keyWithHostnameModel).Return(nil).Once()
 			},
@@ -869,6 +995,7 @@ func TestCreatePublicKeys(t *testing.T) {
 				}
 
 				mock.On("TagsGet", ctx, keyWithTags.TenantID).Return([]string{"tag1", "tag2"}, 2, nil).Once()
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
 				mock.On("PublicKeyGet", ctx, keyWithTags.Fingerprint, "tenant").Return(nil, nil).Once()
 				mock.On("PublicKeyCreate", ctx, &keyWithTagsModel).Return(errors.New("error", "", 0)).Once()
 			},
@@ -908,6 +1035,7 @@ func TestCreatePublicKeys(t *testing.T) {
 				}
 
 				mock.On("TagsGet", ctx, keyWithTags.TenantID).Return([]string{"tag1", "tag2"}, 2, nil).Once()
+				mock.On("NamespaceGet", ctx, "tenant", false).Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
 				mock.On("PublicKeyGet", ctx, keyWithTags.Fingerprint, "tenant").Return(nil, nil).Once()
 				mock.On("PublicKeyCreate", ctx, &keyWithTagsModel).Return(nil).Once()
 			},
@@ -993,3 +1121,132 @@ func TestCreatePublicKeys(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestCheckPublicKeyStrength(t *testing.T) {
+	rsaKey := func(bits int) ssh.PublicKey {
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		require.NoError(t, err)
+
+		pub, err := ssh.NewPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+
+		return pub
+	}
+
+	dsaKey := func() ssh.PublicKey {
+		key := new(dsa.PrivateKey)
+		require.NoError(t, dsa.GenerateParameters(&key.Parameters, rand.Reader, dsa.L1024N160))
+		require.NoError(t, dsa.GenerateKey(key, rand.Reader))
+
+		pub, err := ssh.NewPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+
+		return pub
+	}
+
+	ecdsaKey := func() ssh.PublicKey {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		pub, err := ssh.NewPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+
+		return pub
+	}
+
+	ed25519Key := func() ssh.PublicKey {
+		key, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		pub, err := ssh.NewPublicKey(key)
+		require.NoError(t, err)
+
+		return pub
+	}
+
+	cases := []struct {
+		description string
+		settings    *models.NamespaceSettings
+		key         ssh.PublicKey
+		expected    error
+	}{
+		{
+			description: "fails when a RSA key is smaller than the default minimum",
+			settings:    &models.NamespaceSettings{},
+			key:         rsaKey(2047),
+			expected:    NewErrPublicKeyWeakKey("ssh-rsa", 2047, 2048, nil),
+		},
+		{
+			description: "succeeds when a RSA key has the default minimum",
+			settings:    &models.NamespaceSettings{},
+			key:         rsaKey(2048),
+			expected:    nil,
+		},
+		{
+			description: "fails when a RSA key has 1024 bits by default",
+			settings:    nil,
+			key:         rsaKey(1024),
+			expected:    NewErrPublicKeyWeakKey("ssh-rsa", 1024, 2048, nil),
+		},
+		{
+			description: "succeeds when a RSA key has 1024 bits and they are allowed",
+			settings:    &models.NamespaceSettings{AllowRSA1024: true},
+			key:         rsaKey(1024),
+			expected:    nil,
+		},
+		{
+			description: "succeeds when a RSA key has 1024 bits and they are allowed over the namespace's minimum",
+			settings:    &models.NamespaceSettings{MinRSABits: 4096, AllowRSA1024: true},
+			key:         rsaKey(1024),
+			expected:    nil,
+		},
+		{
+			description: "fails when a RSA key has less than 1024 bits even when they are allowed",
+			settings:    &models.NamespaceSettings{AllowRSA1024: true},
+			key:         rsaKey(1023),
+			expected:    NewErrPublicKeyWeakKey("ssh-rsa", 1023, 1024, nil),
+		},
+		{
+			description: "fails when a RSA key is smaller than the namespace's minimum",
+			settings:    &models.NamespaceSettings{MinRSABits: 3072},
+			key:         rsaKey(3071),
+			expected:    NewErrPublicKeyWeakKey("ssh-rsa", 3071, 3072, nil),
+		},
+		{
+			description: "succeeds when a RSA key has the namespace's minimum",
+			settings:    &models.NamespaceSettings{MinRSABits: 3072},
+			key:         rsaKey(3072),
+			expected:    nil,
+		},
+		{
+			description: "fails when a DSA key is not allowed",
+			settings:    &models.NamespaceSettings{},
+			key:         dsaKey(),
+			expected:    NewErrPublicKeyWeakKey("ssh-dss", 1024, 0, nil),
+		},
+		{
+			description: "succeeds when a DSA key is allowed",
+			settings:    &models.NamespaceSettings{AllowDSA: true},
+			key:         dsaKey(),
+			expected:    nil,
+		},
+		{
+			description: "succeeds when the key is ECDSA",
+			settings:    &models.NamespaceSettings{},
+			key:         ecdsaKey(),
+			expected:    nil,
+		},
+		{
+			description: "succeeds when the key is Ed25519",
+			settings:    &models.NamespaceSettings{},
+			key:         ed25519Key(),
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, checkPublicKeyStrength(tc.settings, tc.key))
+		})
+	}
+}
@@ -8,8 +8,18 @@ import (
 
 type StatsService interface {
 	GetStats(ctx context.Context) (*models.Stats, error)
+	// GetNamespaceStats counts the devices and the active sessions of the namespace with the specified tenant ID.
+	GetNamespaceStats(ctx context.Context, tenantID string) (*models.Stats, error)
 }
 
 func (s *service) GetStats(ctx context.Context) (*models.Stats, error) {
 	return s.store.GetStats(ctx)
 }
+
+func (s *service) GetNamespaceStats(ctx context.Context, tenantID string) (*models.Stats, error) {
+	if _, err := s.store.NamespaceGet(ctx, tenantID, false); err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	return s.store.GetNamespaceStats(ctx, tenantID)
+}
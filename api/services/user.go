@@ -2,10 +2,15 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"strings"
 
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/logger"
 	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
 )
 
 type UserService interface {
@@ -22,6 +27,31 @@ type UserService interface {
 	UpdateDataUser(ctx context.Context, userID string, req *requests.UserDataUpdate) (conflicts []string, err error)
 
 	UpdatePasswordUser(ctx context.Context, id string, currentPassword, newPassword string) error
+
+	// AssignPlan assigns the plan with name planID to the user. The plan's limits are used in place of the user's
+	// own limits, like MaxNamespaces, from now on.
+	AssignPlan(ctx context.Context, userID, planID string) error
+
+	// CompleteOnboarding marks the onboarding of the user with the specified ID as completed, so it isn't shown again.
+	// Completing it again does nothing.
+	CompleteOnboarding(ctx context.Context, userID string) error
+
+	// DeleteUser deletes the account of the user with the specified ID, which only the user itself, the requester, can
+	// do. A user that still owns namespaces must delete or transfer them first. Every session and token of the user is
+	// revoked.
+	//
+	// When gdpr is false, the account is soft-deleted, keeping its data. Otherwise, the user's data is erased: the API
+	// keys it created are deleted, it's removed from the namespaces it's a member of, the invite links it created and
+	// the device events it performed are attributed to [AnonymizeUserID] and, finally, the user itself is deleted.
+	DeleteUser(ctx context.Context, userID, requesterID string, gdpr bool) error
+}
+
+// AnonymizeUserID returns the deterministic, irreversible, identifier that replaces the ID of a user whose data was
+// erased on the records kept after it.
+func AnonymizeUserID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+
+	return "anonymized-" + hex.EncodeToString(sum[:])[:24]
 }
 
 func (s *service) UpdateDataUser(ctx context.Context, userID string, req *requests.UserDataUpdate) ([]string, error) {
@@ -44,6 +74,7 @@ func (s *service) UpdateDataUser(ctx context.Context, userID string, req *reques
 		Username:      req.Username,
 		Email:         req.Email,
 		RecoveryEmail: strings.ToLower(req.RecoveryEmail),
+		Timezone:      req.Timezone,
 	}
 
 	return nil, s.store.UserUpdate(ctx, userID, changes)
@@ -56,6 +87,10 @@ func (s *service) UpdatePasswordUser(ctx context.Context, id, currentPassword, n
 	}
 
 	if !user.Password.Compare(currentPassword) {
+		logger.FromContext(ctx).
+			WithField("user_id", id).
+			Warn("password update rejected as the current password does not match")
+
 		return NewErrUserPasswordNotMatch(nil)
 	}
 
@@ -68,5 +103,125 @@ func (s *service) UpdatePasswordUser(ctx context.Context, id, currentPassword, n
 		return NewErrUserUpdate(user, err)
 	}
 
+	logger.FromContext(ctx).
+		WithField("user_id", id).
+		Info("user password updated")
+
+	return nil
+}
+
+func (s *service) AssignPlan(ctx context.Context, userID, planID string) error {
+	if _, ok := getPlan(planID); !ok {
+		return NewErrPlanNotFound(planID, nil)
+	}
+
+	user, _, err := s.store.UserGetByID(ctx, userID, false)
+	if err != nil {
+		return NewErrUserNotFound(userID, err)
+	}
+
+	if err := s.store.UserUpdate(ctx, userID, &models.UserChanges{PlanID: planID}); err != nil {
+		return NewErrUserUpdate(user, err)
+	}
+
+	logger.FromContext(ctx).
+		WithFields(log.Fields{"user_id": userID, "plan": planID}).
+		Info("plan assigned to user")
+
 	return nil
 }
+
+func (s *service) CompleteOnboarding(ctx context.Context, userID string) error {
+	user, _, err := s.store.UserGetByID(ctx, userID, false)
+	if err != nil {
+		return NewErrUserNotFound(userID, err)
+	}
+
+	if user.OnboardingCompleted {
+		return nil
+	}
+
+	completed := true
+	if err := s.store.UserUpdate(ctx, userID, &models.UserChanges{OnboardingCompleted: &completed}); err != nil {
+		return NewErrUserUpdate(user, err)
+	}
+
+	return nil
+}
+
+func (s *service) DeleteUser(ctx context.Context, userID, requesterID string, gdpr bool) error {
+	if userID != requesterID {
+		return NewErrUserDeleteForbidden(nil)
+	}
+
+	if _, _, err := s.store.UserGetByID(ctx, userID, false); err != nil {
+		return NewErrUserNotFound(userID, err)
+	}
+
+	detach, err := s.store.UserDetachInfo(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if owned := detach["owner"]; len(owned) > 0 {
+		tenantIDs := make([]string, len(owned))
+		for i, namespace := range owned {
+			tenantIDs[i] = namespace.TenantID
+		}
+
+		return NewErrUserOwnsNamespaces(tenantIDs, nil)
+	}
+
+	if err := s.revokeUserSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	if !gdpr {
+		now := clock.Now()
+		if err := s.store.UserUpdate(ctx, userID, &models.UserChanges{DeletedAt: &now}); err != nil {
+			return err
+		}
+
+		// NOTICE: the namespaces' tokens of a soft-deleted user are uncached, as its membership is kept, so they're
+		// rejected at once.
+		for _, namespace := range detach["member"] {
+			if err := s.AuthUncacheToken(ctx, namespace.TenantID, userID); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := s.eraseUser(ctx, userID, detach["member"]); err != nil {
+			return err
+		}
+	}
+
+	logger.FromContext(ctx).
+		WithFields(log.Fields{"user_id": userID, "requester_id": requesterID, "gdpr": gdpr}).
+		Info("user account deleted")
+
+	return nil
+}
+
+// eraseUser erases the data of the user with the specified ID, member of namespaces, deleting the user at last. As
+// the user is deleted only when everything else was erased, a failed erasure can be retried.
+func (s *service) eraseUser(ctx context.Context, userID string, namespaces []*models.Namespace) error {
+	if err := s.store.APIKeyDeleteByCreator(ctx, userID); err != nil {
+		return err
+	}
+
+	for _, namespace := range namespaces {
+		if _, err := s.removeNamespaceMember(ctx, namespace.TenantID, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.store.InviteLinkReplaceCreator(ctx, userID, AnonymizeUserID(userID)); err != nil {
+		return err
+	}
+
+	if err := s.store.DeviceEventReplaceActor(ctx, userID, AnonymizeUserID(userID)); err != nil {
+		return err
+	}
+
+	return s.store.UserDelete(ctx, userID)
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/uuid"
+)
+
+const (
+	// UserSessionTTL is how long a user session lasts after the login, as long as the tokens issued on it.
+	UserSessionTTL = 72 * time.Hour
+	// UserSessionActivityInterval is the minimum interval between two updates of a session's last activity, avoiding
+	// writing to the store on every request.
+	UserSessionActivityInterval = time.Minute
+)
+
+type UserSessionService interface {
+	// ListUserSessions lists the active sessions of the user with the specified ID, i.e., where the user is logged in.
+	ListUserSessions(ctx context.Context, userID string) ([]models.UserSession, error)
+
+	// RevokeUserSession logs the user with the specified ID out of one of its sessions. Every token issued on the
+	// session is rejected from then on.
+	RevokeUserSession(ctx context.Context, userID, sessionID string) error
+
+	// AuthUserSession reports whether the session with the specified ID is still active for the user with the
+	// specified ID, recording the activity on it.
+	AuthUserSession(ctx context.Context, userID, sessionID string) (bool, error)
+
+	// AuthUserActive reports whether the user with the specified ID can still be authenticated, what a deleted user
+	// can't, even with a token issued before its deletion.
+	AuthUserActive(ctx context.Context, userID string) (bool, error)
+}
+
+// userSessionCacheKey returns the cache key of the user session with the specified ID.
+func userSessionCacheKey(id string) string {
+	return "user-session={" + id + "}"
+}
+
+// createUserSession creates a session for the user logging in from the source IP with the user agent. The session is
+// cached until it expires, so checking it on each request doesn't query the store.
+func (s *service) createUserSession(ctx context.Context, user *models.User, sourceIP, userAgent string) (*models.UserSession, error) {
+	now := clock.Now()
+
+	session := &models.UserSession{
+		ID:           uuid.Generate(),
+		UserID:       user.ID,
+		IPAddress:    sourceIP,
+		UserAgent:    userAgent,
+		CreatedAt:    now,
+		LastActivity: now,
+		ExpiresAt:    now.Add(UserSessionTTL),
+	}
+
+	if position, err := s.locator.GetPosition(net.ParseIP(sourceIP)); err == nil {
+		session.Position = models.SessionPosition{Longitude: position.Longitude, Latitude: position.Latitude}
+	}
+
+	if err := s.store.UserSessionCreate(ctx, session); err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Set(ctx, userSessionCacheKey(session.ID), session, UserSessionTTL); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("id", session.ID).
+			Warn("unable to set the user session in cache")
+	}
+
+	return session, nil
+}
+
+func (s *service) ListUserSessions(ctx context.Context, userID string) ([]models.UserSession, error) {
+	return s.store.UserSessionList(ctx, userID)
+}
+
+func (s *service) RevokeUserSession(ctx context.Context, userID, sessionID string) error {
+	if err := s.store.UserSessionDelete(ctx, userID, sessionID); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrUserSessionNotFound(sessionID, err)
+		}
+
+		return err
+	}
+
+	// NOTICE: failing to uncache the session would keep it active until it expires, so the error is returned for the
+	// revocation to be retried.
+	return s.cache.Delete(ctx, userSessionCacheKey(sessionID))
+}
+
+// revokeUserSessions logs the user with the specified ID out of every session. Like [service.RevokeUserSession], the
+// sessions are uncached too, so they're rejected at once.
+func (s *service) revokeUserSessions(ctx context.Context, userID string) error {
+	sessions, err := s.store.UserSessionList(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.UserSessionDeleteAll(ctx, userID); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := s.cache.Delete(ctx, userSessionCacheKey(session.ID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *service) AuthUserSession(ctx context.Context, userID, sessionID string) (bool, error) {
+	session := new(models.UserSession)
+	if err := s.cache.Get(ctx, userSessionCacheKey(sessionID), session); err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithField("id", sessionID).
+			Warn("unable to get the user session from cache")
+	}
+
+	now := clock.Now()
+
+	if session.ID == "" {
+		var err error
+		if session, err = s.store.UserSessionGet(ctx, sessionID); err != nil {
+			if err == store.ErrNoDocuments {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		if err := s.cache.Set(ctx, userSessionCacheKey(sessionID), session, session.ExpiresAt.Sub(now)); err != nil {
+			logger.FromContext(ctx).
+				WithError(err).
+				WithField("id", sessionID).
+				Warn("unable to set the user session in cache")
+		}
+	}
+
+	if session.UserID != userID || !now.Before(session.ExpiresAt) {
+		return false, nil
+	}
+
+	if now.Sub(session.LastActivity) >= UserSessionActivityInterval {
+		session.LastActivity = now
+
+		if err := s.store.UserSessionUpdate(ctx, sessionID, &models.UserSessionChanges{LastActivity: now}); err != nil {
+			logger.FromContext(ctx).
+				WithError(err).
+				WithField("id", sessionID).
+				Warn("unable to update the user session's last activity")
+		}
+
+		if err := s.cache.Set(ctx, userSessionCacheKey(sessionID), session, session.ExpiresAt.Sub(now)); err != nil {
+			logger.FromContext(ctx).
+				WithError(err).
+				WithField("id", sessionID).
+				Warn("unable to set the user session in cache")
+		}
+	}
+
+	return true, nil
+}
+
+func (s *service) AuthUserActive(ctx context.Context, userID string) (bool, error) {
+	user, _, err := s.store.UserGetByID(ctx, userID, false)
+	if err != nil {
+		if err == store.ErrNoDocuments {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return user.DeletedAt == nil, nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	goerrors "errors"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	mockcache "github.com/shellhub-io/shellhub/pkg/cache/mocks"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmock "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+func TestRevokeUserSession(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when the session is not found",
+			requiredMocks: func() {
+				storeMock.On("UserSessionDelete", ctx, "user", "session").Return(store.ErrNoDocuments).Once()
+			},
+			expected: NewErrUserSessionNotFound("session", store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the session cannot be uncached",
+			requiredMocks: func() {
+				storeMock.On("UserSessionDelete", ctx, "user", "session").Return(nil).Once()
+				cacheMock.On("Delete", ctx, "user-session={session}").Return(goerrors.New("error")).Once()
+			},
+			expected: goerrors.New("error"),
+		},
+		{
+			description: "succeeds revoking the session",
+			requiredMocks: func() {
+				storeMock.On("UserSessionDelete", ctx, "user", "session").Return(nil).Once()
+				cacheMock.On("Delete", ctx, "user-session={session}").Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			assert.Equal(t, tc.expected, service.RevokeUserSession(ctx, "user", "session"))
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestAuthUserSession(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.TODO()
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	active := models.UserSession{
+		ID:           "session",
+		UserID:       "user",
+		LastActivity: now.Add(-10 * time.Second),
+		ExpiresAt:    now.Add(time.Hour),
+	}
+
+	// cached makes the cache return the session.
+	cached := func(session models.UserSession) func(args testifymock.Arguments) {
+		return func(args testifymock.Arguments) {
+			*args.Get(2).(*models.UserSession) = session
+		}
+	}
+
+	type Expected struct {
+		ok  bool
+		err error
+	}
+
+	cases := []struct {
+		description   string
+		userID        string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the session was revoked",
+			userID:      "user",
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "user-session={session}", testifymock.Anything).Return(nil).Once()
+				storeMock.On("UserSessionGet", ctx, "session").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{ok: false, err: nil},
+		},
+		{
+			description: "fails when the session cannot be retrieved",
+			userID:      "user",
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "user-session={session}", testifymock.Anything).Return(nil).Once()
+				storeMock.On("UserSessionGet", ctx, "session").Return(nil, goerrors.New("error")).Once()
+			},
+			expected: Expected{ok: false, err: goerrors.New("error")},
+		},
+		{
+			description: "fails when the session belongs to another user",
+			userID:      "other",
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "user-session={session}", testifymock.Anything).Run(cached(active)).Return(nil).Once()
+			},
+			expected: Expected{ok: false, err: nil},
+		},
+		{
+			description: "fails when the cached session is expired",
+			userID:      "user",
+			requiredMocks: func() {
+				expired := active
+				expired.ExpiresAt = now
+
+				cacheMock.On("Get", ctx, "user-session={session}", testifymock.Anything).Run(cached(expired)).Return(nil).Once()
+			},
+			expected: Expected{ok: false, err: nil},
+		},
+		{
+			description: "succeeds from the cache without recording a recent activity again",
+			userID:      "user",
+			requiredMocks: func() {
+				cacheMock.On("Get", ctx, "user-session={session}", testifymock.Anything).Run(cached(active)).Return(nil).Once()
+			},
+			expected: Expected{ok: true, err: nil},
+		},
+		{
+			description: "succeeds from the cache recording the activity",
+			userID:      "user",
+			requiredMocks: func() {
+				idle := active
+				idle.LastActivity = now.Add(-UserSessionActivityInterval)
+
+				touched := active
+				touched.LastActivity = now
+
+				cacheMock.On("Get", ctx, "user-session={session}", testifymock.Anything).Run(cached(idle)).Return(nil).Once()
+				storeMock.On("UserSessionUpdate", ctx, "session", &models.UserSessionChanges{LastActivity: now}).Return(nil).Once()
+				cacheMock.On("Set", ctx, "user-session={session}", &touched, time.Hour).Return(nil).Once()
+			},
+			expected: Expected{ok: true, err: nil},
+		},
+		{
+			description: "succeeds from the store when the session is not cached",
+			userID:      "user",
+			requiredMocks: func() {
+				session := active
+
+				cacheMock.On("Get", ctx, "user-session={session}", testifymock.Anything).Return(goerrors.New("error")).Once()
+				storeMock.On("UserSessionGet", ctx, "session").Return(&session, nil).Once()
+				cacheMock.On("Set", ctx, "user-session={session}", &session, time.Hour).Return(nil).Once()
+			},
+			expected: Expected{ok: true, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			ok, err := service.AuthUserSession(ctx, tc.userID, "session")
+			assert.Equal(t, tc.expected, Expected{ok, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestAuthUserActive(t *testing.T) {
+	storeMock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type Expected struct {
+		ok  bool
+		err error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the user is not found",
+			requiredMocks: func() {
+				storeMock.On("UserGetByID", ctx, "user", false).Return(nil, 0, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{ok: false, err: nil},
+		},
+		{
+			description: "fails when the store fails",
+			requiredMocks: func() {
+				storeMock.On("UserGetByID", ctx, "user", false).Return(nil, 0, goerrors.New("error")).Once()
+			},
+			expected: Expected{ok: false, err: goerrors.New("error")},
+		},
+		{
+			description: "fails when the user was deleted",
+			requiredMocks: func() {
+				storeMock.On("UserGetByID", ctx, "user", false).Return(&models.User{ID: "user", DeletedAt: &deletedAt}, 0, nil).Once()
+			},
+			expected: Expected{ok: false, err: nil},
+		},
+		{
+			description: "succeeds when the user is active",
+			requiredMocks: func() {
+				storeMock.On("UserGetByID", ctx, "user", false).Return(&models.User{ID: "user"}, 0, nil).Once()
+			},
+			expected: Expected{ok: true, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(storeMock), privateKey, publicKey, new(mockcache.Cache), clientMock, nil)
+			ok, err := service.AuthUserActive(ctx, "user")
+			assert.Equal(t, tc.expected, Expected{ok, err})
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+}
@@ -3,11 +3,15 @@ package services
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mocks"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	mockcache "github.com/shellhub-io/shellhub/pkg/cache/mocks"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmock "github.com/shellhub-io/shellhub/pkg/clock/mocks"
 	"github.com/shellhub-io/shellhub/pkg/errors"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +25,10 @@ func TestUpdateDataUser(t *testing.T) {
 
 	storeMock := new(mocks.Store)
 
+	toPointer := func(s string) *string {
+		return &s
+	}
+
 	cases := []struct {
 		description   string
 		userID        string
@@ -274,6 +282,101 @@ func TestUpdateDataUser(t *testing.T) {
 				err:       nil,
 			},
 		},
+		{
+			description: "Success to update user's timezone",
+			userID:      "000000000000000000000000",
+			req: &requests.UserDataUpdate{
+				Name:          "John Doe",
+				Username:      "john_doe",
+				Email:         "john.doe@test.com",
+				RecoveryEmail: "recovery@test.com",
+				Timezone:      toPointer("America/New_York"),
+			},
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("UserGetByID", ctx, "000000000000000000000000", false).
+					Return(
+						&models.User{
+							ID: "000000000000000000000000",
+							UserData: models.UserData{
+								Name:          "James Smith",
+								Username:      "james_smith",
+								Email:         "james.smith@shellhub.io",
+								RecoveryEmail: "recover@test.com",
+							},
+						},
+						0,
+						nil,
+					).
+					Once()
+				storeMock.
+					On("UserConflicts", ctx, &models.UserConflicts{Username: "john_doe", Email: "john.doe@test.com"}).
+					Return([]string{}, false, nil).
+					Once()
+				storeMock.
+					On("UserUpdate", ctx, "000000000000000000000000", &models.UserChanges{
+						Name:          "John Doe",
+						Username:      "john_doe",
+						Email:         "john.doe@test.com",
+						RecoveryEmail: "recovery@test.com",
+						Timezone:      toPointer("America/New_York"),
+					}).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{
+				conflicts: nil,
+				err:       nil,
+			},
+		},
+		{
+			description: "Success to clear user's timezone",
+			userID:      "000000000000000000000000",
+			req: &requests.UserDataUpdate{
+				Name:          "John Doe",
+				Username:      "john_doe",
+				Email:         "john.doe@test.com",
+				RecoveryEmail: "recovery@test.com",
+				Timezone:      toPointer(""),
+			},
+			requiredMocks: func(ctx context.Context) {
+				storeMock.
+					On("UserGetByID", ctx, "000000000000000000000000", false).
+					Return(
+						&models.User{
+							ID:       "000000000000000000000000",
+							Timezone: "America/New_York",
+							UserData: models.UserData{
+								Name:          "James Smith",
+								Username:      "james_smith",
+								Email:         "james.smith@shellhub.io",
+								RecoveryEmail: "recover@test.com",
+							},
+						},
+						0,
+						nil,
+					).
+					Once()
+				storeMock.
+					On("UserConflicts", ctx, &models.UserConflicts{Username: "john_doe", Email: "john.doe@test.com"}).
+					Return([]string{}, false, nil).
+					Once()
+				storeMock.
+					On("UserUpdate", ctx, "000000000000000000000000", &models.UserChanges{
+						Name:          "John Doe",
+						Username:      "john_doe",
+						Email:         "john.doe@test.com",
+						RecoveryEmail: "recovery@test.com",
+						Timezone:      toPointer(""),
+					}).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{
+				conflicts: nil,
+				err:       nil,
+			},
+		},
 	}
 
 	service := NewService(store.Store(storeMock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
@@ -452,3 +555,327 @@ func TestUpdatePasswordUser(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestAssignPlan(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.Background()
+
+	cases := []struct {
+		description   string
+		id            string
+		planID        string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description:   "fails when plan is not found",
+			id:            "65fde3a72c4c7507c7f53c43",
+			planID:        "nonexistent",
+			requiredMocks: func() {},
+			expected:      NewErrPlanNotFound("nonexistent", nil),
+		},
+		{
+			description: "fails when user is not found",
+			id:          "65fde3a72c4c7507c7f53c43",
+			planID:      models.PlanStarter,
+			requiredMocks: func() {
+				mock.
+					On("UserGetByID", ctx, "65fde3a72c4c7507c7f53c43", false).
+					Return(nil, 0, errors.New("error", "", 0)).
+					Once()
+			},
+			expected: NewErrUserNotFound("65fde3a72c4c7507c7f53c43", errors.New("error", "", 0)),
+		},
+		{
+			description: "fails when cannot update the user",
+			id:          "65fde3a72c4c7507c7f53c43",
+			planID:      models.PlanStarter,
+			requiredMocks: func() {
+				user := &models.User{ID: "65fde3a72c4c7507c7f53c43"}
+
+				mock.
+					On("UserGetByID", ctx, "65fde3a72c4c7507c7f53c43", false).
+					Return(user, 0, nil).
+					Once()
+				mock.
+					On("UserUpdate", ctx, "65fde3a72c4c7507c7f53c43", &models.UserChanges{PlanID: models.PlanStarter}).
+					Return(errors.New("error", "", 0)).
+					Once()
+			},
+			expected: NewErrUserUpdate(&models.User{ID: "65fde3a72c4c7507c7f53c43"}, errors.New("error", "", 0)),
+		},
+		{
+			description: "succeeds",
+			id:          "65fde3a72c4c7507c7f53c43",
+			planID:      models.PlanStarter,
+			requiredMocks: func() {
+				user := &models.User{ID: "65fde3a72c4c7507c7f53c43"}
+
+				mock.
+					On("UserGetByID", ctx, "65fde3a72c4c7507c7f53c43", false).
+					Return(user, 0, nil).
+					Once()
+				mock.
+					On("UserUpdate", ctx, "65fde3a72c4c7507c7f53c43", &models.UserChanges{PlanID: models.PlanStarter}).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			services := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := services.AssignPlan(ctx, tc.id, tc.planID)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestCompleteOnboarding(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.Background()
+
+	completed := true
+
+	cases := []struct {
+		description   string
+		id            string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when user is not found",
+			id:          "65fde3a72c4c7507c7f53c43",
+			requiredMocks: func() {
+				mock.
+					On("UserGetByID", ctx, "65fde3a72c4c7507c7f53c43", false).
+					Return(nil, 0, errors.New("error", "", 0)).
+					Once()
+			},
+			expected: NewErrUserNotFound("65fde3a72c4c7507c7f53c43", errors.New("error", "", 0)),
+		},
+		{
+			description: "fails when cannot update the user",
+			id:          "65fde3a72c4c7507c7f53c43",
+			requiredMocks: func() {
+				mock.
+					On("UserGetByID", ctx, "65fde3a72c4c7507c7f53c43", false).
+					Return(&models.User{ID: "65fde3a72c4c7507c7f53c43"}, 0, nil).
+					Once()
+				mock.
+					On("UserUpdate", ctx, "65fde3a72c4c7507c7f53c43", &models.UserChanges{OnboardingCompleted: &completed}).
+					Return(errors.New("error", "", 0)).
+					Once()
+			},
+			expected: NewErrUserUpdate(&models.User{ID: "65fde3a72c4c7507c7f53c43"}, errors.New("error", "", 0)),
+		},
+		{
+			description: "succeeds without updating when the onboarding was already completed",
+			id:          "65fde3a72c4c7507c7f53c43",
+			requiredMocks: func() {
+				mock.
+					On("UserGetByID", ctx, "65fde3a72c4c7507c7f53c43", false).
+					Return(&models.User{ID: "65fde3a72c4c7507c7f53c43", OnboardingCompleted: true}, 0, nil).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds",
+			id:          "65fde3a72c4c7507c7f53c43",
+			requiredMocks: func() {
+				mock.
+					On("UserGetByID", ctx, "65fde3a72c4c7507c7f53c43", false).
+					Return(&models.User{ID: "65fde3a72c4c7507c7f53c43"}, 0, nil).
+					Once()
+				mock.
+					On("UserUpdate", ctx, "65fde3a72c4c7507c7f53c43", &models.UserChanges{OnboardingCompleted: &completed}).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			services := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := services.CompleteOnboarding(ctx, tc.id)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestDeleteUser(t *testing.T) {
+	storeMock := new(mocks.Store)
+	cacheMock := new(mockcache.Cache)
+
+	ctx := context.Background()
+
+	const userID = "65fde3a72c4c7507c7f53c43"
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	member := map[string][]*models.Namespace{
+		"owner":  {},
+		"member": {{TenantID: "00000000-0000-4000-0000-000000000000"}, {TenantID: "00000000-0000-4000-0000-000000000001"}},
+	}
+
+	cases := []struct {
+		description   string
+		requesterID   string
+		gdpr          bool
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description:   "fails when the requester is another user",
+			requesterID:   "65fde3a72c4c7507c7f53c44",
+			requiredMocks: func() {},
+			expected:      NewErrUserDeleteForbidden(nil),
+		},
+		{
+			description: "fails when the user is not found",
+			requesterID: userID,
+			requiredMocks: func() {
+				storeMock.On("UserGetByID", ctx, userID, false).Return(nil, 0, store.ErrNoDocuments).Once()
+			},
+			expected: NewErrUserNotFound(userID, store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the user owns namespaces",
+			requesterID: userID,
+			gdpr:        true,
+			requiredMocks: func() {
+				storeMock.On("UserGetByID", ctx, userID, false).Return(&models.User{ID: userID}, 0, nil).Once()
+				storeMock.
+					On("UserDetachInfo", ctx, userID).
+					Return(map[string][]*models.Namespace{
+						"owner":  {{TenantID: "00000000-0000-4000-0000-000000000000"}},
+						"member": {},
+					}, nil).
+					Once()
+			},
+			expected: NewErrUserOwnsNamespaces([]string{"00000000-0000-4000-0000-000000000000"}, nil),
+		},
+		{
+			description: "succeeds soft-deleting the user and revoking its sessions",
+			requesterID: userID,
+			gdpr:        false,
+			requiredMocks: func() {
+				storeMock.On("UserGetByID", ctx, userID, false).Return(&models.User{ID: userID}, 0, nil).Once()
+				storeMock.On("UserDetachInfo", ctx, userID).Return(member, nil).Once()
+				storeMock.
+					On("UserSessionList", ctx, userID).
+					Return([]models.UserSession{{ID: "session"}}, nil).
+					Once()
+				storeMock.On("UserSessionDeleteAll", ctx, userID).Return(nil).Once()
+				cacheMock.On("Delete", ctx, userSessionCacheKey("session")).Return(nil).Once()
+				storeMock.On("UserUpdate", ctx, userID, &models.UserChanges{DeletedAt: &now}).Return(nil).Once()
+				for _, namespace := range member["member"] {
+					cacheMock.On("Delete", ctx, "token_"+namespace.TenantID+userID).Return(nil).Once()
+				}
+			},
+			expected: nil,
+		},
+		{
+			description: "fails when cannot remove the user from a namespace, keeping the user to retry the erasure",
+			requesterID: userID,
+			gdpr:        true,
+			requiredMocks: func() {
+				storeMock.On("UserGetByID", ctx, userID, false).Return(&models.User{ID: userID}, 0, nil).Once()
+				storeMock.On("UserDetachInfo", ctx, userID).Return(member, nil).Once()
+				storeMock.On("UserSessionList", ctx, userID).Return([]models.UserSession{}, nil).Once()
+				storeMock.On("UserSessionDeleteAll", ctx, userID).Return(nil).Once()
+				storeMock.On("APIKeyDeleteByCreator", ctx, userID).Return(nil).Once()
+				storeMock.
+					On("NamespaceRemoveMember", ctx, "00000000-0000-4000-0000-000000000000", userID).
+					Return(nil, errors.New("error", "", 0)).
+					Once()
+			},
+			expected: errors.New("error", "", 0),
+		},
+		{
+			description: "fails when cannot anonymize the device events, keeping the user to retry the erasure",
+			requesterID: userID,
+			gdpr:        true,
+			requiredMocks: func() {
+				storeMock.On("UserGetByID", ctx, userID, false).Return(&models.User{ID: userID}, 0, nil).Once()
+				storeMock.On("UserDetachInfo", ctx, userID).Return(member, nil).Once()
+				storeMock.On("UserSessionList", ctx, userID).Return([]models.UserSession{}, nil).Once()
+				storeMock.On("UserSessionDeleteAll", ctx, userID).Return(nil).Once()
+				storeMock.On("APIKeyDeleteByCreator", ctx, userID).Return(nil).Once()
+				for _, namespace := range member["member"] {
+					storeMock.
+						On("NamespaceRemoveMember", ctx, namespace.TenantID, userID).
+						Return(&models.Namespace{TenantID: namespace.TenantID}, nil).
+						Once()
+					cacheMock.On("Delete", ctx, "token_"+namespace.TenantID+userID).Return(nil).Once()
+				}
+				storeMock.On("InviteLinkReplaceCreator", ctx, userID, AnonymizeUserID(userID)).Return(nil).Once()
+				storeMock.On("DeviceEventReplaceActor", ctx, userID, AnonymizeUserID(userID)).Return(errors.New("error", "", 0)).Once()
+			},
+			expected: errors.New("error", "", 0),
+		},
+		{
+			description: "succeeds erasing the user's data",
+			requesterID: userID,
+			gdpr:        true,
+			requiredMocks: func() {
+				storeMock.On("UserGetByID", ctx, userID, false).Return(&models.User{ID: userID}, 0, nil).Once()
+				storeMock.On("UserDetachInfo", ctx, userID).Return(member, nil).Once()
+				storeMock.On("UserSessionList", ctx, userID).Return([]models.UserSession{}, nil).Once()
+				storeMock.On("UserSessionDeleteAll", ctx, userID).Return(nil).Once()
+				storeMock.On("APIKeyDeleteByCreator", ctx, userID).Return(nil).Once()
+				for _, namespace := range member["member"] {
+					storeMock.
+						On("NamespaceRemoveMember", ctx, namespace.TenantID, userID).
+						Return(&models.Namespace{TenantID: namespace.TenantID}, nil).
+						Once()
+					cacheMock.On("Delete", ctx, "token_"+namespace.TenantID+userID).Return(nil).Once()
+				}
+				storeMock.On("InviteLinkReplaceCreator", ctx, userID, AnonymizeUserID(userID)).Return(nil).Once()
+				storeMock.On("DeviceEventReplaceActor", ctx, userID, AnonymizeUserID(userID)).Return(nil).Once()
+				storeMock.On("UserDelete", ctx, userID).Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			services := NewService(store.Store(storeMock), privateKey, publicKey, cacheMock, clientMock, nil)
+			err := services.DeleteUser(ctx, userID, tc.requesterID, tc.gdpr)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	storeMock.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestAnonymizeUserID(t *testing.T) {
+	anonymized := AnonymizeUserID("65fde3a72c4c7507c7f53c43")
+
+	assert.Equal(t, anonymized, AnonymizeUserID("65fde3a72c4c7507c7f53c43"))
+	assert.NotEqual(t, anonymized, AnonymizeUserID("65fde3a72c4c7507c7f53c44"))
+	assert.NotContains(t, anonymized, "65fde3a72c4c7507c7f53c43")
+}
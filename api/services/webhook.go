@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"slices"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/logger"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+type WebhookService interface {
+	// GetNamespaceWebhook returns the webhook the events of the namespace with the specified tenant ID are posted to,
+	// without its secret. It returns NewErrWebhookNotFound when the namespace has no webhook.
+	GetNamespaceWebhook(ctx context.Context, tenantID string) (*models.NamespaceWebhook, error)
+	// SetNamespaceWebhook sets the webhook the events of the namespace are posted to, replacing the current one. When
+	// no secret is provided, a new one is generated. The secret is only returned here.
+	SetNamespaceWebhook(ctx context.Context, req *requests.NamespaceWebhookSet) (*responses.NamespaceWebhook, error)
+	// DeleteNamespaceWebhook removes the webhook of the namespace, so its events aren't posted anymore.
+	DeleteNamespaceWebhook(ctx context.Context, tenantID string) error
+	// ListWebhookDeadLetters lists the events that couldn't be delivered to the webhook of the namespace, from the
+	// newest to the oldest.
+	ListWebhookDeadLetters(ctx context.Context, tenantID string, paginator query.Paginator) ([]models.WebhookDeadLetter, int, error)
+}
+
+func (s *service) GetNamespaceWebhook(ctx context.Context, tenantID string) (*models.NamespaceWebhook, error) {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if namespace.Webhook == nil {
+		return nil, NewErrWebhookNotFound(tenantID, nil)
+	}
+
+	return namespace.Webhook, nil
+}
+
+func (s *service) SetNamespaceWebhook(ctx context.Context, req *requests.NamespaceWebhookSet) (*responses.NamespaceWebhook, error) {
+	if address, err := url.Parse(req.URL); err != nil || (address.Scheme != "http" && address.Scheme != "https") || address.Host == "" {
+		return nil, NewErrWebhookInvalid(map[string]interface{}{"url": req.URL}, err)
+	}
+
+	for _, event := range req.Events {
+		if !slices.Contains(models.WebhookEventTypes, event) {
+			return nil, NewErrWebhookInvalid(map[string]interface{}{"event": event}, nil)
+		}
+	}
+
+	if _, err := s.store.NamespaceGet(ctx, req.Tenant, false); err != nil {
+		return nil, NewErrNamespaceNotFound(req.Tenant, err)
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = uuid.Generate()
+	}
+
+	webhook := &models.NamespaceWebhook{
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    req.Events,
+		UpdatedAt: clock.Now(),
+	}
+
+	if err := s.store.NamespaceSetWebhook(ctx, req.Tenant, webhook); err != nil {
+		return nil, NewErrNamespaceNotFound(req.Tenant, err)
+	}
+
+	return &responses.NamespaceWebhook{
+		URL:       webhook.URL,
+		Secret:    webhook.Secret,
+		Events:    webhook.Events,
+		UpdatedAt: webhook.UpdatedAt,
+	}, nil
+}
+
+func (s *service) DeleteNamespaceWebhook(ctx context.Context, tenantID string) error {
+	if _, err := s.GetNamespaceWebhook(ctx, tenantID); err != nil {
+		return err
+	}
+
+	if err := s.store.NamespaceSetWebhook(ctx, tenantID, nil); err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	return nil
+}
+
+func (s *service) ListWebhookDeadLetters(ctx context.Context, tenantID string, paginator query.Paginator) ([]models.WebhookDeadLetter, int, error) {
+	if _, err := s.store.NamespaceGet(ctx, tenantID, false); err != nil {
+		return nil, 0, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	return s.store.WebhookDeadLetterList(ctx, tenantID, paginator)
+}
+
+// emitWebhookEvent posts an event of the specified kind to the webhook of the namespace, when it subscribes to it. As
+// the events are delivered in the background, a failure doesn't interrupt the action that triggered it.
+func (s *service) emitWebhookEvent(ctx context.Context, tenantID string, kind models.WebhookEventType, data map[string]string) {
+	if s.webhooks == nil {
+		return
+	}
+
+	namespace, err := s.store.NamespaceGet(ctx, tenantID, false)
+	if err != nil {
+		logger.FromContext(ctx).
+			WithError(err).
+			WithFields(log.Fields{"tenant_id": tenantID, "type": kind}).
+			Warn("unable to get the namespace to emit the webhook event")
+
+		return
+	}
+
+	if !namespace.Webhook.Subscribes(kind) {
+		return
+	}
+
+	s.webhooks.Dispatch(*namespace.Webhook, models.WebhookEvent{
+		ID:        uuid.Generate(),
+		Type:      kind,
+		TenantID:  tenantID,
+		Data:      data,
+		CreatedAt: clock.Now(),
+	})
+}
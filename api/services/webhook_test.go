@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	webhookmocks "github.com/shellhub-io/shellhub/api/pkg/webhook/mocks"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/responses"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmock "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/uuid"
+	uuid_mocks "github.com/shellhub-io/shellhub/pkg/uuid/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNamespaceWebhook(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	webhook := &models.NamespaceWebhook{URL: "https://example.com/hook", Secret: "secret", Events: []models.WebhookEventType{models.WebhookEventDeviceAccepted}}
+
+	type Expected struct {
+		webhook *models.NamespaceWebhook
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when the namespace is not found",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{nil, NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments)},
+		},
+		{
+			description: "fails when the namespace has no webhook",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+			},
+			expected: Expected{nil, NewErrWebhookNotFound(tenantID, nil)},
+		},
+		{
+			description: "succeeds",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID, Webhook: webhook}, nil).Once()
+			},
+			expected: Expected{webhook, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			webhook, err := service.GetNamespaceWebhook(ctx, tenantID)
+			assert.Equal(t, tc.expected, Expected{webhook, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSetNamespaceWebhook(t *testing.T) {
+	mock := new(mocks.Store)
+
+	clockBackend := clock.DefaultBackend
+	uuidBackend := uuid.DefaultBackend
+	t.Cleanup(func() {
+		clock.DefaultBackend = clockBackend
+		uuid.DefaultBackend = uuidBackend
+	})
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	uuidMock := new(uuid_mocks.Uuid)
+	uuid.DefaultBackend = uuidMock
+	uuidMock.On("Generate").Return("generated")
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	events := []models.WebhookEventType{models.WebhookEventDeviceAccepted, models.WebhookEventMemberAdded}
+
+	type Expected struct {
+		webhook *responses.NamespaceWebhook
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		req           *requests.NamespaceWebhookSet
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description:   "fails when the URL isn't HTTP",
+			req:           &requests.NamespaceWebhookSet{TenantParam: requests.TenantParam{Tenant: tenantID}, URL: "ftp://example.com/hook", Events: events},
+			requiredMocks: func() {},
+			expected:      Expected{nil, NewErrWebhookInvalid(map[string]interface{}{"url": "ftp://example.com/hook"}, nil)},
+		},
+		{
+			description:   "fails when an event is unknown",
+			req:           &requests.NamespaceWebhookSet{TenantParam: requests.TenantParam{Tenant: tenantID}, URL: "https://example.com/hook", Events: []models.WebhookEventType{"device.unknown"}},
+			requiredMocks: func() {},
+			expected:      Expected{nil, NewErrWebhookInvalid(map[string]interface{}{"event": models.WebhookEventType("device.unknown")}, nil)},
+		},
+		{
+			description: "fails when the namespace is not found",
+			req:         &requests.NamespaceWebhookSet{TenantParam: requests.TenantParam{Tenant: tenantID}, URL: "https://example.com/hook", Events: events},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{nil, NewErrNamespaceNotFound(tenantID, store.ErrNoDocuments)},
+		},
+		{
+			description: "succeeds keeping the provided secret",
+			req:         &requests.NamespaceWebhookSet{TenantParam: requests.TenantParam{Tenant: tenantID}, URL: "https://example.com/hook", Secret: "0123456789abcdef", Events: events},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("NamespaceSetWebhook", ctx, tenantID, &models.NamespaceWebhook{URL: "https://example.com/hook", Secret: "0123456789abcdef", Events: events, UpdatedAt: now}).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{&responses.NamespaceWebhook{URL: "https://example.com/hook", Secret: "0123456789abcdef", Events: events, UpdatedAt: now}, nil},
+		},
+		{
+			description: "succeeds generating the secret when it isn't provided",
+			req:         &requests.NamespaceWebhookSet{TenantParam: requests.TenantParam{Tenant: tenantID}, URL: "https://example.com/hook", Events: events},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+				mock.On("NamespaceSetWebhook", ctx, tenantID, &models.NamespaceWebhook{URL: "https://example.com/hook", Secret: "generated", Events: events, UpdatedAt: now}).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{&responses.NamespaceWebhook{URL: "https://example.com/hook", Secret: "generated", Events: events, UpdatedAt: now}, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			webhook, err := service.SetNamespaceWebhook(ctx, tc.req)
+			assert.Equal(t, tc.expected, Expected{webhook, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestDeleteNamespaceWebhook(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	webhook := &models.NamespaceWebhook{URL: "https://example.com/hook", Secret: "secret", Events: []models.WebhookEventType{models.WebhookEventDeviceAccepted}}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when the namespace has no webhook",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+			},
+			expected: NewErrWebhookNotFound(tenantID, nil),
+		},
+		{
+			description: "succeeds",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID, Webhook: webhook}, nil).Once()
+				mock.On("NamespaceSetWebhook", ctx, tenantID, (*models.NamespaceWebhook)(nil)).Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			assert.Equal(t, tc.expected, service.DeleteNamespaceWebhook(ctx, tenantID))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestEmitWebhookEvent(t *testing.T) {
+	mock := new(mocks.Store)
+	dispatcherMock := new(webhookmocks.Dispatcher)
+
+	clockBackend := clock.DefaultBackend
+	uuidBackend := uuid.DefaultBackend
+	t.Cleanup(func() {
+		clock.DefaultBackend = clockBackend
+		uuid.DefaultBackend = uuidBackend
+	})
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	uuidMock := new(uuid_mocks.Uuid)
+	uuid.DefaultBackend = uuidMock
+	uuidMock.On("Generate").Return("id")
+
+	ctx := context.TODO()
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	webhook := &models.NamespaceWebhook{URL: "https://example.com/hook", Secret: "secret", Events: []models.WebhookEventType{models.WebhookEventDeviceAccepted}}
+
+	cases := []struct {
+		description   string
+		kind          models.WebhookEventType
+		requiredMocks func()
+	}{
+		{
+			description: "doesn't dispatch when the namespace is not found",
+			kind:        models.WebhookEventDeviceAccepted,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(nil, errors.New("error")).Once()
+			},
+		},
+		{
+			description: "doesn't dispatch when the namespace has no webhook",
+			kind:        models.WebhookEventDeviceAccepted,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID}, nil).Once()
+			},
+		},
+		{
+			description: "doesn't dispatch when the webhook doesn't subscribe to the event",
+			kind:        models.WebhookEventMemberAdded,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID, Webhook: webhook}, nil).Once()
+			},
+		},
+		{
+			description: "dispatches when the webhook subscribes to the event",
+			kind:        models.WebhookEventDeviceAccepted,
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, tenantID, false).Return(&models.Namespace{TenantID: tenantID, Webhook: webhook}, nil).Once()
+				dispatcherMock.On("Dispatch", *webhook, models.WebhookEvent{
+					ID:        "id",
+					Type:      models.WebhookEventDeviceAccepted,
+					TenantID:  tenantID,
+					Data:      map[string]string{"uid": "uid"},
+					CreatedAt: now,
+				}).Return().Once()
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithWebhookDispatcher(dispatcherMock))
+			service.emitWebhookEvent(ctx, tenantID, tc.kind, map[string]string{"uid": "uid"})
+		})
+	}
+
+	mock.AssertExpectations(t)
+	dispatcherMock.AssertExpectations(t)
+}
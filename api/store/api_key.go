@@ -34,4 +34,8 @@ type APIKeyStore interface {
 
 	// APIKeyDelete deletes an API key with the specified name and tenant ID. Returns an error if any.
 	APIKeyDelete(ctx context.Context, tenantID, name string) (err error)
+
+	// APIKeyDeleteByCreator deletes every API key, of any namespace, created by the user with the specified ID.
+	// Returns an error if any.
+	APIKeyDeleteByCreator(ctx context.Context, userID string) (err error)
 }
@@ -47,8 +47,39 @@ type DeviceStore interface {
 
 	// DeviceSetOnline receives a list of devices to mark as online. For each device in the array, it will upsert
 	// a connected device entry; each UID must exists in the "devices" collection.
+	//
+	// A device that can't be set as online doesn't prevent the other ones from being set; when it happens, a
+	// [DeviceSetOnlineError] with the UIDs of the failed devices is returned.
 	DeviceSetOnline(ctx context.Context, connectedDevices []models.ConnectedDevice) error
 
+	// DeviceListByPlatform lists the devices of the specified tenant whose agent reports the specified platform, like
+	// "connector" for the devices created from containers by a connector.
+	DeviceListByPlatform(ctx context.Context, tenantID, platform string) ([]models.Device, error)
+
+	// DeviceListByStatus lists the devices of the specified tenant with the specified status, sorted by name.
+	DeviceListByStatus(ctx context.Context, tenantID string, status models.DeviceStatus) ([]models.Device, error)
+
+	// DeviceListByUIDs lists the devices of the specified tenant with the specified UIDs. The UIDs without a device
+	// are ignored.
+	DeviceListByUIDs(ctx context.Context, tenantID string, uids []models.UID) ([]models.Device, error)
+
+	// DeviceSetTrustedHostKey sets the PEM encoded public key the device must present to register and to be connected.
+	DeviceSetTrustedHostKey(ctx context.Context, uid models.UID, key string) error
+
+	// DeviceSetExpiresAt sets when the device's access to its namespace ends. A nil expiresAt removes it.
+	DeviceSetExpiresAt(ctx context.Context, uid models.UID, expiresAt *time.Time) error
+
+	// DeviceListExpired lists the devices, not rejected yet, whose access to their namespaces ended until the
+	// specified time.
+	DeviceListExpired(ctx context.Context, before time.Time) ([]models.Device, error)
+
+	// DeviceSetSessionPolicy sets the policy applied to the SSH sessions to the device. A nil policy removes it.
+	DeviceSetSessionPolicy(ctx context.Context, uid models.UID, policy *models.DeviceSessionPolicy) error
+
+	// DeviceSetAllowedCommands sets the patterns of the only commands that can be executed on the device. No patterns
+	// remove the restriction.
+	DeviceSetAllowedCommands(ctx context.Context, uid models.UID, commands []string) error
+
 	// DeviceSetOffline sets a device's status to offline using its UID.
 	DeviceSetOffline(ctx context.Context, uid string) error
 }
@@ -0,0 +1,14 @@
+package store
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type DeviceConnectionStore interface {
+	// DeviceConnectionHistory returns the connections made to the device with the specified UID, from the most recent
+	// to the oldest, and the total number of connections made to it.
+	DeviceConnectionHistory(ctx context.Context, deviceUID string, paginator query.Paginator) (connections []models.DeviceConnection, count int, err error)
+}
@@ -0,0 +1,21 @@
+package store
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type DeviceEventStore interface {
+	// DeviceEventCreate records an event on the timeline of a device, setting its creation date to now.
+	DeviceEventCreate(ctx context.Context, event *models.DeviceEvent) error
+
+	// DeviceEventList returns the events of the device with the specified UID on the specified tenant, from the newest
+	// to the oldest unless a sorter is specified, and the total number of events matching the filters.
+	DeviceEventList(ctx context.Context, tenantID string, uid models.UID, paginator query.Paginator, filters query.Filters, sorter query.Sorter) (events []models.DeviceEvent, count int, err error)
+
+	// DeviceEventReplaceActor replaces the actor of every event performed by the user with the specified ID, on any
+	// namespace, with actor. Returns an error if any.
+	DeviceEventReplaceActor(ctx context.Context, userID, actor string) error
+}
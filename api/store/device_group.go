@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type DeviceGroupStore interface {
+	// DeviceGroupCreate creates a new device group.
+	DeviceGroupCreate(ctx context.Context, group *models.DeviceGroup) error
+
+	// DeviceGroupGet retrieves the device group with the specified UID from the specified tenant.
+	// It returns ErrNoDocuments when the group is not found.
+	DeviceGroupGet(ctx context.Context, tenantID, uid string) (*models.DeviceGroup, error)
+
+	// DeviceGroupUpdate applies changes to the device group with the specified UID from the specified tenant.
+	// It returns ErrNoDocuments when the group is not found.
+	DeviceGroupUpdate(ctx context.Context, tenantID, uid string, changes *models.DeviceGroupChanges) error
+
+	// DeviceGroupAddDevice adds the device with the specified UID to the device group, doing nothing when it's
+	// already a member. It returns ErrNoDocuments when the group is not found.
+	DeviceGroupAddDevice(ctx context.Context, tenantID, uid, deviceUID string) error
+
+	// DeviceGroupRemoveDevice removes the device with the specified UID from the device group, doing nothing when
+	// it isn't a member. It returns ErrNoDocuments when the group is not found.
+	DeviceGroupRemoveDevice(ctx context.Context, tenantID, uid, deviceUID string) error
+
+	// DeviceGroupListDevices lists the devices of the device group with the specified UID, sorted by name. Members
+	// that don't exist anymore are not listed. It returns the devices of the requested page, the total of devices
+	// and an error, which is ErrNoDocuments when the group is not found.
+	DeviceGroupListDevices(ctx context.Context, tenantID, uid string, paginator query.Paginator) ([]models.Device, int, error)
+}
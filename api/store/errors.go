@@ -1,6 +1,10 @@
 package store
 
-import "github.com/shellhub-io/shellhub/pkg/errors"
+import (
+	"strings"
+
+	"github.com/shellhub-io/shellhub/pkg/errors"
+)
 
 // ErrLayer is an error level. Each error defined at this level, is container to it.
 // ErrLayer is the errors' level for store's error.
@@ -23,3 +27,20 @@ var (
 	ErrDuplicateUser  = errors.New("user already exists", ErrLayer, ErrCodeDuplicated)
 	ErrDuplicateEmail = errors.New("email address is already in use", ErrLayer, ErrCodeDuplicated)
 )
+
+// DeviceSetOnlineError is the error returned when some of the devices couldn't be set as online, what doesn't prevent
+// the other ones from being set.
+type DeviceSetOnlineError struct {
+	// UIDs are the UIDs of the devices that couldn't be set as online.
+	UIDs []string
+	// Err is the error of the first device that couldn't be set as online.
+	Err error
+}
+
+func (e *DeviceSetOnlineError) Error() string {
+	return "failed to set the devices " + strings.Join(e.UIDs, ", ") + " as online: " + e.Err.Error()
+}
+
+func (e *DeviceSetOnlineError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,26 @@
+package store
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type FirewallRuleStore interface {
+	// FirewallRuleListActive returns the active firewall rules of the specified tenant sorted by priority, then by ID,
+	// in ascending order.
+	FirewallRuleListActive(ctx context.Context, tenantID string) (rules []models.FirewallRule, err error)
+
+	// FirewallRuleListByDeviceGroup returns the firewall rules of the specified tenant restricted to the device group
+	// with the specified UID.
+	FirewallRuleListByDeviceGroup(ctx context.Context, tenantID, groupUID string) (rules []models.FirewallRule, err error)
+
+	// FirewallRuleConflicts returns the firewall rules of the specified tenant that have the same priority and
+	// hostname filter as any of the targets. Targets without a hostname filter are ignored.
+	FirewallRuleConflicts(ctx context.Context, tenantID string, targets []models.FirewallRule) (conflicts []models.FirewallRule, err error)
+
+	// FirewallRuleBulkWrite atomically inserts the rules in create and replaces the existing rules in replace, matching
+	// them by ID. It returns the number of inserted and replaced rules and an error if any. When an error is returned,
+	// no rule is written.
+	FirewallRuleBulkWrite(ctx context.Context, create []models.FirewallRule, replace []models.FirewallRule) (inserted int64, replaced int64, err error)
+}
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type InviteLinkStore interface {
+	// InviteLinkCreate creates an invite link with the provided data. Returns an error if any.
+	InviteLinkCreate(ctx context.Context, link *models.InviteLink) error
+
+	// InviteLinkGet retrieves the invite link with the specified token. Returns the link and an error if any.
+	InviteLinkGet(ctx context.Context, token string) (*models.InviteLink, error)
+
+	// InviteLinkList retrieves the invite links of the namespace with the specified tenant ID.
+	InviteLinkList(ctx context.Context, tenantID string) ([]models.InviteLink, error)
+
+	// InviteLinkUse atomically increments the use count of the invite link with the specified token, provided that it
+	// is neither exhausted nor expired at now. It returns the link with the incremented count, or ErrNoDocuments when
+	// the link cannot be used, so concurrent uses never exceed the link's maximum uses.
+	InviteLinkUse(ctx context.Context, token string, now time.Time) (*models.InviteLink, error)
+
+	// InviteLinkDelete deletes the invite link with the specified token from the namespace with the specified tenant
+	// ID. Returns an error if any, or ErrNoDocuments when the link does not exist.
+	InviteLinkDelete(ctx context.Context, tenantID, token string) error
+
+	// InviteLinkReplaceCreator replaces the creator of every invite link created by the user with the specified ID
+	// with creator. Returns an error if any.
+	InviteLinkReplaceCreator(ctx context.Context, userID, creator string) error
+}
@@ -91,6 +91,20 @@ func (_m *Store) APIKeyDelete(ctx context.Context, tenantID string, name string)
 	return r0
 }
 
+// APIKeyDeleteByCreator provides a mock function with given fields: ctx, userID
+func (_m *Store) APIKeyDeleteByCreator(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // APIKeyGet provides a mock function with given fields: ctx, id
 func (_m *Store) APIKeyGet(ctx context.Context, id string) (*models.APIKey, error) {
 	ret := _m.Called(ctx, id)
@@ -252,6 +266,39 @@ func (_m *Store) DeviceChooser(ctx context.Context, tenantID string, chosen []st
 	return r0
 }
 
+// DeviceConnectionHistory provides a mock function with given fields: ctx, deviceUID, paginator
+func (_m *Store) DeviceConnectionHistory(ctx context.Context, deviceUID string, paginator query.Paginator) ([]models.DeviceConnection, int, error) {
+	ret := _m.Called(ctx, deviceUID, paginator)
+
+	var r0 []models.DeviceConnection
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, query.Paginator) ([]models.DeviceConnection, int, error)); ok {
+		return rf(ctx, deviceUID, paginator)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, query.Paginator) []models.DeviceConnection); ok {
+		r0 = rf(ctx, deviceUID, paginator)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.DeviceConnection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, query.Paginator) int); ok {
+		r1 = rf(ctx, deviceUID, paginator)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, query.Paginator) error); ok {
+		r2 = rf(ctx, deviceUID, paginator)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // DeviceCreate provides a mock function with given fields: ctx, d, hostname
 func (_m *Store) DeviceCreate(ctx context.Context, d models.Device, hostname string) error {
 	ret := _m.Called(ctx, d, hostname)
@@ -294,6 +341,67 @@ func (_m *Store) DeviceDelete(ctx context.Context, uid models.UID) error {
 	return r0
 }
 
+// DeviceEventCreate provides a mock function with given fields: ctx, event
+func (_m *Store) DeviceEventCreate(ctx context.Context, event *models.DeviceEvent) error {
+	ret := _m.Called(ctx, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeviceEvent) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceEventReplaceActor provides a mock function with given fields: ctx, userID, actor
+func (_m *Store) DeviceEventReplaceActor(ctx context.Context, userID string, actor string) error {
+	ret := _m.Called(ctx, userID, actor)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, actor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceEventList provides a mock function with given fields: ctx, tenantID, uid, paginator, filters, sorter
+func (_m *Store) DeviceEventList(ctx context.Context, tenantID string, uid models.UID, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.DeviceEvent, int, error) {
+	ret := _m.Called(ctx, tenantID, uid, paginator, filters, sorter)
+
+	var r0 []models.DeviceEvent
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID, query.Paginator, query.Filters, query.Sorter) ([]models.DeviceEvent, int, error)); ok {
+		return rf(ctx, tenantID, uid, paginator, filters, sorter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID, query.Paginator, query.Filters, query.Sorter) []models.DeviceEvent); ok {
+		r0 = rf(ctx, tenantID, uid, paginator, filters, sorter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.DeviceEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.UID, query.Paginator, query.Filters, query.Sorter) int); ok {
+		r1 = rf(ctx, tenantID, uid, paginator, filters, sorter)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, models.UID, query.Paginator, query.Filters, query.Sorter) error); ok {
+		r2 = rf(ctx, tenantID, uid, paginator, filters, sorter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // DeviceGet provides a mock function with given fields: ctx, uid
 func (_m *Store) DeviceGet(ctx context.Context, uid models.UID) (*models.Device, error) {
 	ret := _m.Called(ctx, uid)
@@ -457,6 +565,121 @@ func (_m *Store) DeviceGetTags(ctx context.Context, tenant string) ([]string, in
 	return r0, r1, r2
 }
 
+// DeviceGroupAddDevice provides a mock function with given fields: ctx, tenantID, uid, deviceUID
+func (_m *Store) DeviceGroupAddDevice(ctx context.Context, tenantID string, uid string, deviceUID string) error {
+	ret := _m.Called(ctx, tenantID, uid, deviceUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, tenantID, uid, deviceUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceGroupCreate provides a mock function with given fields: ctx, group
+func (_m *Store) DeviceGroupCreate(ctx context.Context, group *models.DeviceGroup) error {
+	ret := _m.Called(ctx, group)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeviceGroup) error); ok {
+		r0 = rf(ctx, group)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceGroupGet provides a mock function with given fields: ctx, tenantID, uid
+func (_m *Store) DeviceGroupGet(ctx context.Context, tenantID string, uid string) (*models.DeviceGroup, error) {
+	ret := _m.Called(ctx, tenantID, uid)
+
+	var r0 *models.DeviceGroup
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.DeviceGroup, error)); ok {
+		return rf(ctx, tenantID, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.DeviceGroup); ok {
+		r0 = rf(ctx, tenantID, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeviceGroup)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeviceGroupListDevices provides a mock function with given fields: ctx, tenantID, uid, paginator
+func (_m *Store) DeviceGroupListDevices(ctx context.Context, tenantID string, uid string, paginator query.Paginator) ([]models.Device, int, error) {
+	ret := _m.Called(ctx, tenantID, uid, paginator)
+
+	var r0 []models.Device
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, query.Paginator) ([]models.Device, int, error)); ok {
+		return rf(ctx, tenantID, uid, paginator)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, query.Paginator) []models.Device); ok {
+		r0 = rf(ctx, tenantID, uid, paginator)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, query.Paginator) int); ok {
+		r1 = rf(ctx, tenantID, uid, paginator)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, query.Paginator) error); ok {
+		r2 = rf(ctx, tenantID, uid, paginator)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// DeviceGroupRemoveDevice provides a mock function with given fields: ctx, tenantID, uid, deviceUID
+func (_m *Store) DeviceGroupRemoveDevice(ctx context.Context, tenantID string, uid string, deviceUID string) error {
+	ret := _m.Called(ctx, tenantID, uid, deviceUID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, tenantID, uid, deviceUID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceGroupUpdate provides a mock function with given fields: ctx, tenantID, uid, changes
+func (_m *Store) DeviceGroupUpdate(ctx context.Context, tenantID string, uid string, changes *models.DeviceGroupChanges) error {
+	ret := _m.Called(ctx, tenantID, uid, changes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *models.DeviceGroupChanges) error); ok {
+		r0 = rf(ctx, tenantID, uid, changes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeviceList provides a mock function with given fields: ctx, status, pagination, filters, sorter, acceptable
 func (_m *Store) DeviceList(ctx context.Context, status models.DeviceStatus, pagination query.Paginator, filters query.Filters, sorter query.Sorter, acceptable store.DeviceAcceptable) ([]models.Device, int, error) {
 	ret := _m.Called(ctx, status, pagination, filters, sorter, acceptable)
@@ -490,6 +713,84 @@ func (_m *Store) DeviceList(ctx context.Context, status models.DeviceStatus, pag
 	return r0, r1, r2
 }
 
+// DeviceListByPlatform provides a mock function with given fields: ctx, tenantID, platform
+func (_m *Store) DeviceListByPlatform(ctx context.Context, tenantID string, platform string) ([]models.Device, error) {
+	ret := _m.Called(ctx, tenantID, platform)
+
+	var r0 []models.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]models.Device, error)); ok {
+		return rf(ctx, tenantID, platform)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []models.Device); ok {
+		r0 = rf(ctx, tenantID, platform)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, platform)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeviceListByStatus provides a mock function with given fields: ctx, tenantID, status
+func (_m *Store) DeviceListByStatus(ctx context.Context, tenantID string, status models.DeviceStatus) ([]models.Device, error) {
+	ret := _m.Called(ctx, tenantID, status)
+
+	var r0 []models.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.DeviceStatus) ([]models.Device, error)); ok {
+		return rf(ctx, tenantID, status)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.DeviceStatus) []models.Device); ok {
+		r0 = rf(ctx, tenantID, status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.DeviceStatus) error); ok {
+		r1 = rf(ctx, tenantID, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeviceListByUIDs provides a mock function with given fields: ctx, tenantID, uids
+func (_m *Store) DeviceListByUIDs(ctx context.Context, tenantID string, uids []models.UID) ([]models.Device, error) {
+	ret := _m.Called(ctx, tenantID, uids)
+
+	var r0 []models.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []models.UID) ([]models.Device, error)); ok {
+		return rf(ctx, tenantID, uids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []models.UID) []models.Device); ok {
+		r0 = rf(ctx, tenantID, uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []models.UID) error); ok {
+		r1 = rf(ctx, tenantID, uids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeviceListByUsage provides a mock function with given fields: ctx, tenantID
 func (_m *Store) DeviceListByUsage(ctx context.Context, tenantID string) ([]models.UID, error) {
 	ret := _m.Called(ctx, tenantID)
@@ -516,6 +817,32 @@ func (_m *Store) DeviceListByUsage(ctx context.Context, tenantID string) ([]mode
 	return r0, r1
 }
 
+// DeviceListExpired provides a mock function with given fields: ctx, before
+func (_m *Store) DeviceListExpired(ctx context.Context, before time.Time) ([]models.Device, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 []models.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]models.Device, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []models.Device); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeviceLookup provides a mock function with given fields: ctx, namespace, hostname
 func (_m *Store) DeviceLookup(ctx context.Context, namespace string, hostname string) (*models.Device, error) {
 	ret := _m.Called(ctx, namespace, hostname)
@@ -695,6 +1022,34 @@ func (_m *Store) DeviceRename(ctx context.Context, uid models.UID, hostname stri
 	return r0
 }
 
+// DeviceSetAllowedCommands provides a mock function with given fields: ctx, uid, commands
+func (_m *Store) DeviceSetAllowedCommands(ctx context.Context, uid models.UID, commands []string) error {
+	ret := _m.Called(ctx, uid, commands)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, []string) error); ok {
+		r0 = rf(ctx, uid, commands)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceSetExpiresAt provides a mock function with given fields: ctx, uid, expiresAt
+func (_m *Store) DeviceSetExpiresAt(ctx context.Context, uid models.UID, expiresAt *time.Time) error {
+	ret := _m.Called(ctx, uid, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, *time.Time) error); ok {
+		r0 = rf(ctx, uid, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeviceSetOffline provides a mock function with given fields: ctx, uid
 func (_m *Store) DeviceSetOffline(ctx context.Context, uid string) error {
 	ret := _m.Called(ctx, uid)
@@ -737,6 +1092,20 @@ func (_m *Store) DeviceSetPosition(ctx context.Context, uid models.UID, position
 	return r0
 }
 
+// DeviceSetSessionPolicy provides a mock function with given fields: ctx, uid, policy
+func (_m *Store) DeviceSetSessionPolicy(ctx context.Context, uid models.UID, policy *models.DeviceSessionPolicy) error {
+	ret := _m.Called(ctx, uid, policy)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, *models.DeviceSessionPolicy) error); ok {
+		r0 = rf(ctx, uid, policy)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeviceSetTags provides a mock function with given fields: ctx, uid, tags
 func (_m *Store) DeviceSetTags(ctx context.Context, uid models.UID, tags []string) (int64, int64, error) {
 	ret := _m.Called(ctx, uid, tags)
@@ -768,6 +1137,20 @@ func (_m *Store) DeviceSetTags(ctx context.Context, uid models.UID, tags []strin
 	return r0, r1, r2
 }
 
+// DeviceSetTrustedHostKey provides a mock function with given fields: ctx, uid, key
+func (_m *Store) DeviceSetTrustedHostKey(ctx context.Context, uid models.UID, key string) error {
+	ret := _m.Called(ctx, uid, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string) error); ok {
+		r0 = rf(ctx, uid, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeviceUpdate provides a mock function with given fields: ctx, tenant, uid, name, publicURL
 func (_m *Store) DeviceUpdate(ctx context.Context, tenant string, uid models.UID, name *string, publicURL *bool) error {
 	ret := _m.Called(ctx, tenant, uid, name, publicURL)
@@ -824,12 +1207,147 @@ func (_m *Store) DeviceUpdateStatus(ctx context.Context, uid models.UID, status
 	return r0
 }
 
-// GetStats provides a mock function with given fields: ctx
-func (_m *Store) GetStats(ctx context.Context) (*models.Stats, error) {
-	ret := _m.Called(ctx)
+// FirewallRuleBulkWrite provides a mock function with given fields: ctx, create, replace
+func (_m *Store) FirewallRuleBulkWrite(ctx context.Context, create []models.FirewallRule, replace []models.FirewallRule) (int64, int64, error) {
+	ret := _m.Called(ctx, create, replace)
 
-	var r0 *models.Stats
-	var r1 error
+	var r0 int64
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.FirewallRule, []models.FirewallRule) (int64, int64, error)); ok {
+		return rf(ctx, create, replace)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []models.FirewallRule, []models.FirewallRule) int64); ok {
+		r0 = rf(ctx, create, replace)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []models.FirewallRule, []models.FirewallRule) int64); ok {
+		r1 = rf(ctx, create, replace)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []models.FirewallRule, []models.FirewallRule) error); ok {
+		r2 = rf(ctx, create, replace)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FirewallRuleConflicts provides a mock function with given fields: ctx, tenantID, targets
+func (_m *Store) FirewallRuleConflicts(ctx context.Context, tenantID string, targets []models.FirewallRule) ([]models.FirewallRule, error) {
+	ret := _m.Called(ctx, tenantID, targets)
+
+	var r0 []models.FirewallRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []models.FirewallRule) ([]models.FirewallRule, error)); ok {
+		return rf(ctx, tenantID, targets)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []models.FirewallRule) []models.FirewallRule); ok {
+		r0 = rf(ctx, tenantID, targets)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FirewallRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []models.FirewallRule) error); ok {
+		r1 = rf(ctx, tenantID, targets)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FirewallRuleListActive provides a mock function with given fields: ctx, tenantID
+func (_m *Store) FirewallRuleListActive(ctx context.Context, tenantID string) ([]models.FirewallRule, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 []models.FirewallRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.FirewallRule, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.FirewallRule); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FirewallRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FirewallRuleListByDeviceGroup provides a mock function with given fields: ctx, tenantID, groupUID
+func (_m *Store) FirewallRuleListByDeviceGroup(ctx context.Context, tenantID string, groupUID string) ([]models.FirewallRule, error) {
+	ret := _m.Called(ctx, tenantID, groupUID)
+
+	var r0 []models.FirewallRule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]models.FirewallRule, error)); ok {
+		return rf(ctx, tenantID, groupUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []models.FirewallRule); ok {
+		r0 = rf(ctx, tenantID, groupUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.FirewallRule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, groupUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNamespaceStats provides a mock function with given fields: ctx, tenantID
+func (_m *Store) GetNamespaceStats(ctx context.Context, tenantID string) (*models.Stats, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 *models.Stats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Stats, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Stats); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Stats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStats provides a mock function with given fields: ctx
+func (_m *Store) GetStats(ctx context.Context) (*models.Stats, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *models.Stats
+	var r1 error
 	if rf, ok := ret.Get(0).(func(context.Context) (*models.Stats, error)); ok {
 		return rf(ctx)
 	}
@@ -850,6 +1368,126 @@ func (_m *Store) GetStats(ctx context.Context) (*models.Stats, error) {
 	return r0, r1
 }
 
+// InviteLinkCreate provides a mock function with given fields: ctx, link
+func (_m *Store) InviteLinkCreate(ctx context.Context, link *models.InviteLink) error {
+	ret := _m.Called(ctx, link)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.InviteLink) error); ok {
+		r0 = rf(ctx, link)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InviteLinkDelete provides a mock function with given fields: ctx, tenantID, token
+func (_m *Store) InviteLinkDelete(ctx context.Context, tenantID string, token string) error {
+	ret := _m.Called(ctx, tenantID, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InviteLinkGet provides a mock function with given fields: ctx, token
+func (_m *Store) InviteLinkGet(ctx context.Context, token string) (*models.InviteLink, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 *models.InviteLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.InviteLink, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.InviteLink); ok {
+		r0 = rf(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.InviteLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InviteLinkList provides a mock function with given fields: ctx, tenantID
+func (_m *Store) InviteLinkList(ctx context.Context, tenantID string) ([]models.InviteLink, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 []models.InviteLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.InviteLink, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.InviteLink); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.InviteLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InviteLinkReplaceCreator provides a mock function with given fields: ctx, userID, creator
+func (_m *Store) InviteLinkReplaceCreator(ctx context.Context, userID string, creator string) error {
+	ret := _m.Called(ctx, userID, creator)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, creator)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InviteLinkUse provides a mock function with given fields: ctx, token, now
+func (_m *Store) InviteLinkUse(ctx context.Context, token string, now time.Time) (*models.InviteLink, error) {
+	ret := _m.Called(ctx, token, now)
+
+	var r0 *models.InviteLink
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (*models.InviteLink, error)); ok {
+		return rf(ctx, token, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) *models.InviteLink); ok {
+		r0 = rf(ctx, token, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.InviteLink)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, token, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NamespaceAddMember provides a mock function with given fields: ctx, tenantID, memberID, memberRole
 func (_m *Store) NamespaceAddMember(ctx context.Context, tenantID string, memberID string, memberRole string) (*models.Namespace, error) {
 	ret := _m.Called(ctx, tenantID, memberID, memberRole)
@@ -944,25 +1582,252 @@ func (_m *Store) NamespaceEditMember(ctx context.Context, tenantID string, membe
 	return r0
 }
 
-// NamespaceGet provides a mock function with given fields: ctx, tenantID, countDevices
-func (_m *Store) NamespaceGet(ctx context.Context, tenantID string, countDevices bool) (*models.Namespace, error) {
-	ret := _m.Called(ctx, tenantID, countDevices)
+// NamespaceGet provides a mock function with given fields: ctx, tenantID, countDevices
+func (_m *Store) NamespaceGet(ctx context.Context, tenantID string, countDevices bool) (*models.Namespace, error) {
+	ret := _m.Called(ctx, tenantID, countDevices)
+
+	var r0 *models.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (*models.Namespace, error)); ok {
+		return rf(ctx, tenantID, countDevices)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *models.Namespace); ok {
+		r0 = rf(ctx, tenantID, countDevices)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, tenantID, countDevices)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NamespaceGetByName provides a mock function with given fields: ctx, name
+func (_m *Store) NamespaceGetByName(ctx context.Context, name string) (*models.Namespace, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 *models.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Namespace, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Namespace); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NamespaceGetByPreviousName provides a mock function with given fields: ctx, name, since
+func (_m *Store) NamespaceGetByPreviousName(ctx context.Context, name string, since time.Time) (*models.Namespace, error) {
+	ret := _m.Called(ctx, name, since)
+
+	var r0 *models.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (*models.Namespace, error)); ok {
+		return rf(ctx, name, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) *models.Namespace); ok {
+		r0 = rf(ctx, name, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, name, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NamespaceGetFirst provides a mock function with given fields: ctx, id
+func (_m *Store) NamespaceGetFirst(ctx context.Context, id string) (*models.Namespace, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Namespace, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Namespace); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NamespaceGetSessionRecord provides a mock function with given fields: ctx, tenantID
+func (_m *Store) NamespaceGetSessionRecord(ctx context.Context, tenantID string) (bool, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NamespaceList provides a mock function with given fields: ctx, paginator, filters, role, export
+func (_m *Store) NamespaceList(ctx context.Context, paginator query.Paginator, filters query.Filters, role string, export bool) ([]models.Namespace, int, error) {
+	ret := _m.Called(ctx, paginator, filters, role, export)
+
+	var r0 []models.Namespace
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, string, bool) ([]models.Namespace, int, error)); ok {
+		return rf(ctx, paginator, filters, role, export)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, string, bool) []models.Namespace); ok {
+		r0 = rf(ctx, paginator, filters, role, export)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, query.Paginator, query.Filters, string, bool) int); ok {
+		r1 = rf(ctx, paginator, filters, role, export)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, query.Paginator, query.Filters, string, bool) error); ok {
+		r2 = rf(ctx, paginator, filters, role, export)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NamespaceListByMember provides a mock function with given fields: ctx, userID, role, paginator, filters
+func (_m *Store) NamespaceListByMember(ctx context.Context, userID string, role string, paginator query.Paginator, filters query.Filters) ([]models.Namespace, int, error) {
+	ret := _m.Called(ctx, userID, role, paginator, filters)
+
+	var r0 []models.Namespace
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, query.Paginator, query.Filters) ([]models.Namespace, int, error)); ok {
+		return rf(ctx, userID, role, paginator, filters)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, query.Paginator, query.Filters) []models.Namespace); ok {
+		r0 = rf(ctx, userID, role, paginator, filters)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, query.Paginator, query.Filters) int); ok {
+		r1 = rf(ctx, userID, role, paginator, filters)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, query.Paginator, query.Filters) error); ok {
+		r2 = rf(ctx, userID, role, paginator, filters)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NamespaceListByOwner provides a mock function with given fields: ctx, ownerID, paginator
+func (_m *Store) NamespaceListByOwner(ctx context.Context, ownerID string, paginator query.Paginator) ([]models.Namespace, int, error) {
+	ret := _m.Called(ctx, ownerID, paginator)
+
+	var r0 []models.Namespace
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, query.Paginator) ([]models.Namespace, int, error)); ok {
+		return rf(ctx, ownerID, paginator)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, query.Paginator) []models.Namespace); ok {
+		r0 = rf(ctx, ownerID, paginator)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, query.Paginator) int); ok {
+		r1 = rf(ctx, ownerID, paginator)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, query.Paginator) error); ok {
+		r2 = rf(ctx, ownerID, paginator)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NamespaceListExpired provides a mock function with given fields: ctx, before
+func (_m *Store) NamespaceListExpired(ctx context.Context, before time.Time) ([]models.Namespace, error) {
+	ret := _m.Called(ctx, before)
 
-	var r0 *models.Namespace
+	var r0 []models.Namespace
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (*models.Namespace, error)); ok {
-		return rf(ctx, tenantID, countDevices)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]models.Namespace, error)); ok {
+		return rf(ctx, before)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *models.Namespace); ok {
-		r0 = rf(ctx, tenantID, countDevices)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []models.Namespace); ok {
+		r0 = rf(ctx, before)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Namespace)
+			r0 = ret.Get(0).([]models.Namespace)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
-		r1 = rf(ctx, tenantID, countDevices)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -970,25 +1835,25 @@ func (_m *Store) NamespaceGet(ctx context.Context, tenantID string, countDevices
 	return r0, r1
 }
 
-// NamespaceGetByName provides a mock function with given fields: ctx, name
-func (_m *Store) NamespaceGetByName(ctx context.Context, name string) (*models.Namespace, error) {
-	ret := _m.Called(ctx, name)
+// NamespaceListInactive provides a mock function with given fields: ctx, before
+func (_m *Store) NamespaceListInactive(ctx context.Context, before time.Time) ([]models.Namespace, error) {
+	ret := _m.Called(ctx, before)
 
-	var r0 *models.Namespace
+	var r0 []models.Namespace
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Namespace, error)); ok {
-		return rf(ctx, name)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]models.Namespace, error)); ok {
+		return rf(ctx, before)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Namespace); ok {
-		r0 = rf(ctx, name)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []models.Namespace); ok {
+		r0 = rf(ctx, before)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Namespace)
+			r0 = ret.Get(0).([]models.Namespace)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, name)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -996,25 +1861,25 @@ func (_m *Store) NamespaceGetByName(ctx context.Context, name string) (*models.N
 	return r0, r1
 }
 
-// NamespaceGetFirst provides a mock function with given fields: ctx, id
-func (_m *Store) NamespaceGetFirst(ctx context.Context, id string) (*models.Namespace, error) {
-	ret := _m.Called(ctx, id)
+// NamespaceListMemberTags provides a mock function with given fields: ctx, tenantID
+func (_m *Store) NamespaceListMemberTags(ctx context.Context, tenantID string) ([]string, error) {
+	ret := _m.Called(ctx, tenantID)
 
-	var r0 *models.Namespace
+	var r0 []string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Namespace, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]string, error)); ok {
+		return rf(ctx, tenantID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Namespace); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []string); ok {
+		r0 = rf(ctx, tenantID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Namespace)
+			r0 = ret.Get(0).([]string)
 		}
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, id)
+		r1 = rf(ctx, tenantID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1022,23 +1887,25 @@ func (_m *Store) NamespaceGetFirst(ctx context.Context, id string) (*models.Name
 	return r0, r1
 }
 
-// NamespaceGetSessionRecord provides a mock function with given fields: ctx, tenantID
-func (_m *Store) NamespaceGetSessionRecord(ctx context.Context, tenantID string) (bool, error) {
-	ret := _m.Called(ctx, tenantID)
+// NamespaceListMembers provides a mock function with given fields: ctx, tenantID, tag
+func (_m *Store) NamespaceListMembers(ctx context.Context, tenantID string, tag string) ([]models.Member, error) {
+	ret := _m.Called(ctx, tenantID, tag)
 
-	var r0 bool
+	var r0 []models.Member
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
-		return rf(ctx, tenantID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]models.Member, error)); ok {
+		return rf(ctx, tenantID, tag)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
-		r0 = rf(ctx, tenantID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []models.Member); ok {
+		r0 = rf(ctx, tenantID, tag)
 	} else {
-		r0 = ret.Get(0).(bool)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Member)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, tenantID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, tag)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1046,37 +1913,30 @@ func (_m *Store) NamespaceGetSessionRecord(ctx context.Context, tenantID string)
 	return r0, r1
 }
 
-// NamespaceList provides a mock function with given fields: ctx, paginator, filters, export
-func (_m *Store) NamespaceList(ctx context.Context, paginator query.Paginator, filters query.Filters, export bool) ([]models.Namespace, int, error) {
-	ret := _m.Called(ctx, paginator, filters, export)
+// NamespaceListUsageReports provides a mock function with given fields: ctx
+func (_m *Store) NamespaceListUsageReports(ctx context.Context) ([]models.Namespace, error) {
+	ret := _m.Called(ctx)
 
 	var r0 []models.Namespace
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, bool) ([]models.Namespace, int, error)); ok {
-		return rf(ctx, paginator, filters, export)
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.Namespace, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, bool) []models.Namespace); ok {
-		r0 = rf(ctx, paginator, filters, export)
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Namespace); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Namespace)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, query.Paginator, query.Filters, bool) int); ok {
-		r1 = rf(ctx, paginator, filters, export)
-	} else {
-		r1 = ret.Get(1).(int)
-	}
-
-	if rf, ok := ret.Get(2).(func(context.Context, query.Paginator, query.Filters, bool) error); ok {
-		r2 = rf(ctx, paginator, filters, export)
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
-		r2 = ret.Error(2)
+		r1 = ret.Error(1)
 	}
 
-	return r0, r1, r2
+	return r0, r1
 }
 
 // NamespaceRemoveMember provides a mock function with given fields: ctx, tenantID, memberID
@@ -1105,6 +1965,90 @@ func (_m *Store) NamespaceRemoveMember(ctx context.Context, tenantID string, mem
 	return r0, r1
 }
 
+// NamespaceRenew provides a mock function with given fields: ctx, tenantID, activeAt
+func (_m *Store) NamespaceRenew(ctx context.Context, tenantID string, activeAt time.Time) error {
+	ret := _m.Called(ctx, tenantID, activeAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, tenantID, activeAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceRenewMany provides a mock function with given fields: ctx, tenantIDs, since, activeAt
+func (_m *Store) NamespaceRenewMany(ctx context.Context, tenantIDs []string, since time.Time, activeAt time.Time) error {
+	ret := _m.Called(ctx, tenantIDs, since, activeAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, time.Time, time.Time) error); ok {
+		r0 = rf(ctx, tenantIDs, since, activeAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceSetAPIRateLimit provides a mock function with given fields: ctx, tenantID, limit
+func (_m *Store) NamespaceSetAPIRateLimit(ctx context.Context, tenantID string, limit *models.APIRateLimitConfig) error {
+	ret := _m.Called(ctx, tenantID, limit)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.APIRateLimitConfig) error); ok {
+		r0 = rf(ctx, tenantID, limit)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceSetExpiresAt provides a mock function with given fields: ctx, tenantID, expiresAt
+func (_m *Store) NamespaceSetExpiresAt(ctx context.Context, tenantID string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, tenantID, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, tenantID, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceSetMemberAccessSchedule provides a mock function with given fields: ctx, tenantID, memberID, schedule
+func (_m *Store) NamespaceSetMemberAccessSchedule(ctx context.Context, tenantID string, memberID string, schedule *models.AccessSchedule) error {
+	ret := _m.Called(ctx, tenantID, memberID, schedule)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *models.AccessSchedule) error); ok {
+		r0 = rf(ctx, tenantID, memberID, schedule)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceSetMemberTags provides a mock function with given fields: ctx, tenantID, memberID, tags
+func (_m *Store) NamespaceSetMemberTags(ctx context.Context, tenantID string, memberID string, tags []string) error {
+	ret := _m.Called(ctx, tenantID, memberID, tags)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) error); ok {
+		r0 = rf(ctx, tenantID, memberID, tags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NamespaceSetSessionRecord provides a mock function with given fields: ctx, sessionRecord, tenantID
 func (_m *Store) NamespaceSetSessionRecord(ctx context.Context, sessionRecord bool, tenantID string) error {
 	ret := _m.Called(ctx, sessionRecord, tenantID)
@@ -1119,6 +2063,34 @@ func (_m *Store) NamespaceSetSessionRecord(ctx context.Context, sessionRecord bo
 	return r0
 }
 
+// NamespaceSetUsageReportSentAt provides a mock function with given fields: ctx, tenantID, sentAt
+func (_m *Store) NamespaceSetUsageReportSentAt(ctx context.Context, tenantID string, sentAt time.Time) error {
+	ret := _m.Called(ctx, tenantID, sentAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, tenantID, sentAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceSetWebhook provides a mock function with given fields: ctx, tenantID, webhook
+func (_m *Store) NamespaceSetWebhook(ctx context.Context, tenantID string, webhook *models.NamespaceWebhook) error {
+	ret := _m.Called(ctx, tenantID, webhook)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.NamespaceWebhook) error); ok {
+		r0 = rf(ctx, tenantID, webhook)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NamespaceUpdate provides a mock function with given fields: ctx, tenantID, namespace
 func (_m *Store) NamespaceUpdate(ctx context.Context, tenantID string, namespace *models.Namespace) error {
 	ret := _m.Called(ctx, tenantID, namespace)
@@ -1308,6 +2280,20 @@ func (_m *Store) PublicKeyGetTags(ctx context.Context, tenant string) ([]string,
 	return r0, r1, r2
 }
 
+// PublicKeyInvalidateEvalCache provides a mock function with given fields: ctx, fingerprint, tenantID
+func (_m *Store) PublicKeyInvalidateEvalCache(ctx context.Context, fingerprint string, tenantID string) error {
+	ret := _m.Called(ctx, fingerprint, tenantID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, fingerprint, tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // PublicKeyList provides a mock function with given fields: ctx, paginator
 func (_m *Store) PublicKeyList(ctx context.Context, paginator query.Paginator) ([]models.PublicKey, int, error) {
 	ret := _m.Called(ctx, paginator)
@@ -1449,16 +2435,139 @@ func (_m *Store) SessionCreate(ctx context.Context, session models.Session) (*mo
 	if rf, ok := ret.Get(0).(func(context.Context, models.Session) (*models.Session, error)); ok {
 		return rf(ctx, session)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, models.Session) *models.Session); ok {
-		r0 = rf(ctx, session)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Session) *models.Session); ok {
+		r0 = rf(ctx, session)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Session) error); ok {
+		r1 = rf(ctx, session)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SessionCreateRecordFrames provides a mock function with given fields: ctx, frames
+func (_m *Store) SessionCreateRecordFrames(ctx context.Context, frames []models.RecordedSession) error {
+	ret := _m.Called(ctx, frames)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []models.RecordedSession) error); ok {
+		r0 = rf(ctx, frames)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SessionDeleteActives provides a mock function with given fields: ctx, uid
+func (_m *Store) SessionDeleteActives(ctx context.Context, uid models.UID) error {
+	ret := _m.Called(ctx, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) error); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SessionDeleteByDate provides a mock function with given fields: ctx, lte
+func (_m *Store) SessionDeleteByDate(ctx context.Context, lte time.Time) (int64, error) {
+	ret := _m.Called(ctx, lte)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, lte)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, lte)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, lte)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SessionDeleteRecordFrameByDate provides a mock function with given fields: ctx, lte, limit
+func (_m *Store) SessionDeleteRecordFrameByDate(ctx context.Context, lte time.Time, limit int64) (int64, int64, error) {
+	ret := _m.Called(ctx, lte, limit)
+
+	var r0 int64
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) (int64, int64, error)); ok {
+		return rf(ctx, lte, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) int64); ok {
+		r0 = rf(ctx, lte, limit)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int64) int64); ok {
+		r1 = rf(ctx, lte, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, time.Time, int64) error); ok {
+		r2 = rf(ctx, lte, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SessionDeleteRecordFrames provides a mock function with given fields: ctx, uid
+func (_m *Store) SessionDeleteRecordFrames(ctx context.Context, uid models.UID) error {
+	ret := _m.Called(ctx, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) error); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SessionGet provides a mock function with given fields: ctx, uid
+func (_m *Store) SessionGet(ctx context.Context, uid models.UID) (*models.Session, error) {
+	ret := _m.Called(ctx, uid)
+
+	var r0 *models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) (*models.Session, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) *models.Session); ok {
+		r0 = rf(ctx, uid)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Session)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, models.Session) error); ok {
-		r1 = rf(ctx, session)
+	if rf, ok := ret.Get(1).(func(context.Context, models.UID) error); ok {
+		r1 = rf(ctx, uid)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1466,8 +2575,8 @@ func (_m *Store) SessionCreate(ctx context.Context, session models.Session) (*mo
 	return r0, r1
 }
 
-// SessionDeleteActives provides a mock function with given fields: ctx, uid
-func (_m *Store) SessionDeleteActives(ctx context.Context, uid models.UID) error {
+// SessionIncrementViewCount provides a mock function with given fields: ctx, uid
+func (_m *Store) SessionIncrementViewCount(ctx context.Context, uid models.UID) error {
 	ret := _m.Called(ctx, uid)
 
 	var r0 error
@@ -1480,30 +2589,32 @@ func (_m *Store) SessionDeleteActives(ctx context.Context, uid models.UID) error
 	return r0
 }
 
-// SessionDeleteRecordFrameByDate provides a mock function with given fields: ctx, lte
-func (_m *Store) SessionDeleteRecordFrameByDate(ctx context.Context, lte time.Time) (int64, int64, error) {
-	ret := _m.Called(ctx, lte)
+// SessionList provides a mock function with given fields: ctx, paginator, filters, sorter
+func (_m *Store) SessionList(ctx context.Context, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.Session, int, error) {
+	ret := _m.Called(ctx, paginator, filters, sorter)
 
-	var r0 int64
-	var r1 int64
+	var r0 []models.Session
+	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, int64, error)); ok {
-		return rf(ctx, lte)
+	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, query.Sorter) ([]models.Session, int, error)); ok {
+		return rf(ctx, paginator, filters, sorter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
-		r0 = rf(ctx, lte)
+	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator, query.Filters, query.Sorter) []models.Session); ok {
+		r0 = rf(ctx, paginator, filters, sorter)
 	} else {
-		r0 = ret.Get(0).(int64)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Session)
+		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, time.Time) int64); ok {
-		r1 = rf(ctx, lte)
+	if rf, ok := ret.Get(1).(func(context.Context, query.Paginator, query.Filters, query.Sorter) int); ok {
+		r1 = rf(ctx, paginator, filters, sorter)
 	} else {
-		r1 = ret.Get(1).(int64)
+		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, time.Time) error); ok {
-		r2 = rf(ctx, lte)
+	if rf, ok := ret.Get(2).(func(context.Context, query.Paginator, query.Filters, query.Sorter) error); ok {
+		r2 = rf(ctx, paginator, filters, sorter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -1511,20 +2622,46 @@ func (_m *Store) SessionDeleteRecordFrameByDate(ctx context.Context, lte time.Ti
 	return r0, r1, r2
 }
 
-// SessionGet provides a mock function with given fields: ctx, uid
-func (_m *Store) SessionGet(ctx context.Context, uid models.UID) (*models.Session, error) {
+// SessionListActives provides a mock function with given fields: ctx, tenantID
+func (_m *Store) SessionListActives(ctx context.Context, tenantID string) ([]models.Session, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 []models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.Session, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.Session); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SessionListRecordFrames provides a mock function with given fields: ctx, uid
+func (_m *Store) SessionListRecordFrames(ctx context.Context, uid models.UID) ([]models.RecordedSession, error) {
 	ret := _m.Called(ctx, uid)
 
-	var r0 *models.Session
+	var r0 []models.RecordedSession
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.UID) (*models.Session, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) ([]models.RecordedSession, error)); ok {
 		return rf(ctx, uid)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, models.UID) *models.Session); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID) []models.RecordedSession); ok {
 		r0 = rf(ctx, uid)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Session)
+			r0 = ret.Get(0).([]models.RecordedSession)
 		}
 	}
 
@@ -1537,37 +2674,56 @@ func (_m *Store) SessionGet(ctx context.Context, uid models.UID) (*models.Sessio
 	return r0, r1
 }
 
-// SessionList provides a mock function with given fields: ctx, paginator
-func (_m *Store) SessionList(ctx context.Context, paginator query.Paginator) ([]models.Session, int, error) {
-	ret := _m.Called(ctx, paginator)
+// SessionListUnarchivedRecords provides a mock function with given fields: ctx, lte, limit
+func (_m *Store) SessionListUnarchivedRecords(ctx context.Context, lte time.Time, limit int64) ([]models.UID, error) {
+	ret := _m.Called(ctx, lte, limit)
 
-	var r0 []models.Session
-	var r1 int
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator) ([]models.Session, int, error)); ok {
-		return rf(ctx, paginator)
+	var r0 []models.UID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) ([]models.UID, error)); ok {
+		return rf(ctx, lte, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, query.Paginator) []models.Session); ok {
-		r0 = rf(ctx, paginator)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) []models.UID); ok {
+		r0 = rf(ctx, lte, limit)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Session)
+			r0 = ret.Get(0).([]models.UID)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, query.Paginator) int); ok {
-		r1 = rf(ctx, paginator)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int64) error); ok {
+		r1 = rf(ctx, lte, limit)
 	} else {
-		r1 = ret.Get(1).(int)
+		r1 = ret.Error(1)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, query.Paginator) error); ok {
-		r2 = rf(ctx, paginator)
+	return r0, r1
+}
+
+// SessionListUIDs provides a mock function with given fields: ctx, tenantID
+func (_m *Store) SessionListUIDs(ctx context.Context, tenantID string) ([]models.UID, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 []models.UID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.UID, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.UID); ok {
+		r0 = rf(ctx, tenantID)
 	} else {
-		r2 = ret.Error(2)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UID)
+		}
 	}
 
-	return r0, r1, r2
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // SessionSetLastSeen provides a mock function with given fields: ctx, uid
@@ -1584,6 +2740,20 @@ func (_m *Store) SessionSetLastSeen(ctx context.Context, uid models.UID) error {
 	return r0
 }
 
+// SessionSetRecordUploadState provides a mock function with given fields: ctx, uid, from, state
+func (_m *Store) SessionSetRecordUploadState(ctx context.Context, uid models.UID, from *models.RecordingUploadState, state *models.RecordingUploadState) error {
+	ret := _m.Called(ctx, uid, from, state)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, *models.RecordingUploadState, *models.RecordingUploadState) error); ok {
+		r0 = rf(ctx, uid, from, state)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SessionSetRecorded provides a mock function with given fields: ctx, uid, recorded
 func (_m *Store) SessionSetRecorded(ctx context.Context, uid models.UID, recorded bool) error {
 	ret := _m.Called(ctx, uid, recorded)
@@ -1598,6 +2768,48 @@ func (_m *Store) SessionSetRecorded(ctx context.Context, uid models.UID, recorde
 	return r0
 }
 
+// SessionSetRecordingSummary provides a mock function with given fields: ctx, uid, summary
+func (_m *Store) SessionSetRecordingSummary(ctx context.Context, uid models.UID, summary *models.RecordingSummary) error {
+	ret := _m.Called(ctx, uid, summary)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, *models.RecordingSummary) error); ok {
+		r0 = rf(ctx, uid, summary)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SessionSetStorage provides a mock function with given fields: ctx, uid, backend, location
+func (_m *Store) SessionSetStorage(ctx context.Context, uid models.UID, backend string, location string) error {
+	ret := _m.Called(ctx, uid, backend, location)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string, string) error); ok {
+		r0 = rf(ctx, uid, backend, location)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SessionStreamRecordFrames provides a mock function with given fields: ctx, uid, fn
+func (_m *Store) SessionStreamRecordFrames(ctx context.Context, uid models.UID, fn func(*models.RecordedSession) error) error {
+	ret := _m.Called(ctx, uid, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, func(*models.RecordedSession) error) error); ok {
+		r0 = rf(ctx, uid, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SessionUpdate provides a mock function with given fields: ctx, uid, model
 func (_m *Store) SessionUpdate(ctx context.Context, uid models.UID, model *models.Session) error {
 	ret := _m.Called(ctx, uid, model)
@@ -1707,6 +2919,32 @@ func (_m *Store) TagsRename(ctx context.Context, tenant string, oldTag string, n
 	return r0, r1
 }
 
+// TopViewedSessions provides a mock function with given fields: ctx, tenantID, limit
+func (_m *Store) TopViewedSessions(ctx context.Context, tenantID string, limit int) ([]models.Session, error) {
+	ret := _m.Called(ctx, tenantID, limit)
+
+	var r0 []models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]models.Session, error)); ok {
+		return rf(ctx, tenantID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []models.Session); ok {
+		r0 = rf(ctx, tenantID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, tenantID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UserConflicts provides a mock function with given fields: ctx, target
 func (_m *Store) UserConflicts(ctx context.Context, target *models.UserConflicts) ([]string, bool, error) {
 	ret := _m.Called(ctx, target)
@@ -1912,6 +3150,114 @@ func (_m *Store) UserList(ctx context.Context, paginator query.Paginator, filter
 	return r0, r1, r2
 }
 
+// UserSessionCreate provides a mock function with given fields: ctx, session
+func (_m *Store) UserSessionCreate(ctx context.Context, session *models.UserSession) error {
+	ret := _m.Called(ctx, session)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserSession) error); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSessionDelete provides a mock function with given fields: ctx, userID, id
+func (_m *Store) UserSessionDelete(ctx context.Context, userID string, id string) error {
+	ret := _m.Called(ctx, userID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSessionDeleteAll provides a mock function with given fields: ctx, userID
+func (_m *Store) UserSessionDeleteAll(ctx context.Context, userID string) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSessionGet provides a mock function with given fields: ctx, id
+func (_m *Store) UserSessionGet(ctx context.Context, id string) (*models.UserSession, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.UserSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.UserSession, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.UserSession); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserSessionList provides a mock function with given fields: ctx, userID
+func (_m *Store) UserSessionList(ctx context.Context, userID string) ([]models.UserSession, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []models.UserSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.UserSession, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.UserSession); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UserSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserSessionUpdate provides a mock function with given fields: ctx, id, changes
+func (_m *Store) UserSessionUpdate(ctx context.Context, id string, changes *models.UserSessionChanges) error {
+	ret := _m.Called(ctx, id, changes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.UserSessionChanges) error); ok {
+		r0 = rf(ctx, id, changes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UserUpdate provides a mock function with given fields: ctx, id, changes
 func (_m *Store) UserUpdate(ctx context.Context, id string, changes *models.UserChanges) error {
 	ret := _m.Called(ctx, id, changes)
@@ -1926,6 +3272,53 @@ func (_m *Store) UserUpdate(ctx context.Context, id string, changes *models.User
 	return r0
 }
 
+// WebhookDeadLetterCreate provides a mock function with given fields: ctx, letter
+func (_m *Store) WebhookDeadLetterCreate(ctx context.Context, letter *models.WebhookDeadLetter) error {
+	ret := _m.Called(ctx, letter)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.WebhookDeadLetter) error); ok {
+		r0 = rf(ctx, letter)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WebhookDeadLetterList provides a mock function with given fields: ctx, tenantID, paginator
+func (_m *Store) WebhookDeadLetterList(ctx context.Context, tenantID string, paginator query.Paginator) ([]models.WebhookDeadLetter, int, error) {
+	ret := _m.Called(ctx, tenantID, paginator)
+
+	var r0 []models.WebhookDeadLetter
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, query.Paginator) ([]models.WebhookDeadLetter, int, error)); ok {
+		return rf(ctx, tenantID, paginator)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, query.Paginator) []models.WebhookDeadLetter); ok {
+		r0 = rf(ctx, tenantID, paginator)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.WebhookDeadLetter)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, query.Paginator) int); ok {
+		r1 = rf(ctx, tenantID, paginator)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, query.Paginator) error); ok {
+		r2 = rf(ctx, tenantID, paginator)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 type mockConstructorTestingTNewStore interface {
 	mock.TestingT
 	Cleanup(func())
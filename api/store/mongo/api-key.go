@@ -138,6 +138,14 @@ func (s *Store) APIKeyUpdate(ctx context.Context, tenantID, name string, changes
 	return nil
 }
 
+func (s *Store) APIKeyDeleteByCreator(ctx context.Context, userID string) error {
+	if _, err := s.db.Collection("api_keys").DeleteMany(ctx, bson.M{"created_by": userID}); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
+
 func (s *Store) APIKeyDelete(ctx context.Context, tenantID, name string) error {
 	result, err := s.db.
 		Collection("api_keys").
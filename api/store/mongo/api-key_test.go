@@ -526,3 +526,40 @@ func TestDeleteAPIKey(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIKeyDeleteByCreator(t *testing.T) {
+	cases := []struct {
+		description string
+		userID      string
+		fixtures    []string
+		expected    int
+	}{
+		{
+			description: "succeeds keeping the keys when the user created none",
+			userID:      "nonexistent",
+			fixtures:    []string{fixtureAPIKeys},
+			expected:    2,
+		},
+		{
+			description: "succeeds deleting the keys created by the user",
+			userID:      "507f1f77bcf86cd799439011",
+			fixtures:    []string{fixtureAPIKeys},
+			expected:    0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			require.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() { require.NoError(t, srv.Reset()) })
+
+			require.NoError(t, s.APIKeyDeleteByCreator(ctx, tc.userID))
+
+			_, count, err := s.APIKeyList(ctx, "00000000-0000-4000-0000-000000000000", query.Paginator{Page: 1, PerPage: 10}, query.Sorter{By: "expires_in", Order: query.OrderAsc})
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, count)
+		})
+	}
+}
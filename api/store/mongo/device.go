@@ -3,6 +3,7 @@ package mongo
 import (
 	"context"
 	"crypto/md5"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -236,6 +237,74 @@ func (s *Store) DeviceGet(ctx context.Context, uid models.UID) (*models.Device,
 	return device, nil
 }
 
+func (s *Store) DeviceListByPlatform(ctx context.Context, tenantID, platform string) ([]models.Device, error) {
+	query := []bson.M{
+		{
+			"$match": bson.M{"tenant_id": tenantID, "info.platform": platform},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "connected_devices",
+				"localField":   "uid",
+				"foreignField": "uid",
+				"as":           "online",
+			},
+		},
+		{
+			"$addFields": bson.M{
+				"online": bson.M{"$anyElementTrue": []interface{}{"$online"}},
+			},
+		},
+	}
+
+	cursor, err := s.db.Collection("devices").Aggregate(ctx, query)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	devices := make([]models.Device, 0)
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return devices, nil
+}
+
+func (s *Store) DeviceListByStatus(ctx context.Context, tenantID string, status models.DeviceStatus) ([]models.Device, error) {
+	cursor, err := s.db.Collection("devices").Find(
+		ctx,
+		bson.M{"tenant_id": tenantID, "status": status},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}),
+	)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	devices := make([]models.Device, 0)
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return devices, nil
+}
+
+func (s *Store) DeviceListByUIDs(ctx context.Context, tenantID string, uids []models.UID) ([]models.Device, error) {
+	cursor, err := s.db.Collection("devices").Find(ctx, bson.M{"tenant_id": tenantID, "uid": bson.M{"$in": uids}})
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	devices := make([]models.Device, 0)
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return devices, nil
+}
+
 func (s *Store) DeviceDelete(ctx context.Context, uid models.UID) error {
 	mongoSession, err := s.db.Client().StartSession()
 	if err != nil {
@@ -336,15 +405,51 @@ func (s *Store) DeviceSetOnline(ctx context.Context, connectedDevices []models.C
 		replaceModels = append(replaceModels, mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(d).SetUpsert(true))
 	}
 
-	if _, err := s.db.Collection("devices").BulkWrite(ctx, updateModels); err != nil {
-		return FromMongoError(err)
+	// NOTICE: the writes are unordered, so a device that fails doesn't stop the other ones from being written. Only
+	// the failed devices are reported, through the indexes of their write errors.
+	opts := options.BulkWrite().SetOrdered(false)
+
+	failed := make(map[int]bool)
+	var first error
+
+	for _, write := range []struct {
+		collection string
+		models     []mongo.WriteModel
+	}{
+		{"devices", updateModels},
+		{"connected_devices", replaceModels},
+	} {
+		_, err := s.db.Collection(write.collection).BulkWrite(ctx, write.models, opts)
+		if err == nil {
+			continue
+		}
+
+		var exception mongo.BulkWriteException
+		if !errors.As(err, &exception) || exception.WriteConcernError != nil || len(exception.WriteErrors) == 0 {
+			return FromMongoError(err)
+		}
+
+		for _, writeErr := range exception.WriteErrors {
+			if first == nil {
+				first = writeErr
+			}
+
+			failed[writeErr.Index] = true
+		}
 	}
 
-	if _, err := s.db.Collection("connected_devices").BulkWrite(ctx, replaceModels); err != nil {
-		return FromMongoError(err)
+	if len(failed) == 0 {
+		return nil
 	}
 
-	return nil
+	uids := make([]string, 0, len(failed))
+	for i, d := range connectedDevices {
+		if failed[i] {
+			uids = append(uids, d.UID)
+		}
+	}
+
+	return &store.DeviceSetOnlineError{UIDs: uids, Err: first}
 }
 
 func (s *Store) DeviceSetOffline(ctx context.Context, uid string) error {
@@ -511,6 +616,91 @@ func (s *Store) DeviceSetPosition(ctx context.Context, uid models.UID, position
 	return nil
 }
 
+func (s *Store) DeviceSetTrustedHostKey(ctx context.Context, uid models.UID, key string) error {
+	res, err := s.db.Collection("devices").UpdateOne(ctx, bson.M{"uid": uid}, bson.M{"$set": bson.M{"trusted_host_key": key}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) DeviceSetExpiresAt(ctx context.Context, uid models.UID, expiresAt *time.Time) error {
+	update := bson.M{"$set": bson.M{"expires_at": expiresAt}}
+	if expiresAt == nil {
+		update = bson.M{"$unset": bson.M{"expires_at": ""}}
+	}
+
+	res, err := s.db.Collection("devices").UpdateOne(ctx, bson.M{"uid": uid}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) DeviceListExpired(ctx context.Context, before time.Time) ([]models.Device, error) {
+	cursor, err := s.db.Collection("devices").Find(ctx, bson.M{
+		"expires_at": bson.M{"$lte": before},
+		"status":     bson.M{"$ne": models.DeviceStatusRejected},
+	})
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	devices := make([]models.Device, 0)
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return devices, nil
+}
+
+func (s *Store) DeviceSetSessionPolicy(ctx context.Context, uid models.UID, policy *models.DeviceSessionPolicy) error {
+	update := bson.M{"$set": bson.M{"session_policy": policy}}
+	if policy == nil {
+		update = bson.M{"$unset": bson.M{"session_policy": ""}}
+	}
+
+	res, err := s.db.Collection("devices").UpdateOne(ctx, bson.M{"uid": uid}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) DeviceSetAllowedCommands(ctx context.Context, uid models.UID, commands []string) error {
+	update := bson.M{"$set": bson.M{"allowed_commands": commands}}
+	if len(commands) == 0 {
+		update = bson.M{"$unset": bson.M{"allowed_commands": ""}}
+	}
+
+	res, err := s.db.Collection("devices").UpdateOne(ctx, bson.M{"uid": uid}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
 func (s *Store) DeviceChooser(ctx context.Context, tenantID string, chosen []string) error {
 	filter := bson.M{
 		"status":    "accepted",
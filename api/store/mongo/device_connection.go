@@ -0,0 +1,127 @@
+package mongo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store/mongo/queries"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// DeviceConnectionHistoryCached is the number of most recent connections of a device kept on the cache, serving the
+// first page of its connection history when it isn't larger than that.
+const DeviceConnectionHistoryCached = 20
+
+// deviceConnectionHistory is the first page of the connection history of a device kept on the cache.
+type deviceConnectionHistory struct {
+	Connections []models.DeviceConnection
+	Count       int
+}
+
+// deviceConnectionHistoryKey returns the cache key of the first page of the connection history of the device.
+func deviceConnectionHistoryKey(deviceUID string) string {
+	return strings.Join([]string{"device_connections", deviceUID}, "/")
+}
+
+func (s *Store) DeviceConnectionHistory(ctx context.Context, deviceUID string, paginator query.Paginator) ([]models.DeviceConnection, int, error) {
+	if paginator.Page != 1 || paginator.PerPage < 1 || paginator.PerPage > DeviceConnectionHistoryCached {
+		return s.deviceConnectionHistory(ctx, deviceUID, paginator)
+	}
+
+	var history *deviceConnectionHistory
+	if err := s.cache.Get(ctx, deviceConnectionHistoryKey(deviceUID), &history); err != nil {
+		logrus.Error(err)
+	}
+
+	if history == nil {
+		connections, count, err := s.deviceConnectionHistory(ctx, deviceUID, query.Paginator{Page: 1, PerPage: DeviceConnectionHistoryCached})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		history = &deviceConnectionHistory{Connections: connections, Count: count}
+		if err := s.cache.Set(ctx, deviceConnectionHistoryKey(deviceUID), history, time.Minute); err != nil {
+			logrus.Error(err)
+		}
+	}
+
+	connections := history.Connections
+	if len(connections) > paginator.PerPage {
+		connections = connections[:paginator.PerPage]
+	}
+
+	return connections, history.Count, nil
+}
+
+// deviceConnectionHistory queries the page of the connection history of the device, bypassing the cache.
+func (s *Store) deviceConnectionHistory(ctx context.Context, deviceUID string, paginator query.Paginator) ([]models.DeviceConnection, int, error) {
+	query := []bson.M{
+		{
+			"$match": bson.M{
+				"device_uid": deviceUID,
+			},
+		},
+	}
+
+	queryCount := query
+	queryCount = append(queryCount, bson.M{"$count": "count"})
+	count, err := AggregateCount(ctx, s.db.Collection("sessions"), queryCount)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+
+	query = append(query, bson.M{"$sort": bson.D{{Key: "started_at", Value: -1}, {Key: "uid", Value: -1}}})
+	query = append(query, queries.FromPaginator(&paginator)...)
+	query = append(query, bson.M{
+		"$project": bson.M{
+			"_id":        0,
+			"uid":        1,
+			"username":   1,
+			"ip_address": 1,
+			"started_at": 1,
+			"disconnected_at": bson.M{
+				"$cond": bson.A{"$closed", "$last_seen", nil},
+			},
+			"duration": bson.M{
+				"$max": bson.A{bson.M{"$subtract": bson.A{"$last_seen", "$started_at"}}, 0},
+			},
+			"auth_method": bson.M{
+				"$switch": bson.M{
+					"branches": bson.A{
+						bson.M{
+							"case": bson.M{"$ne": bson.A{"$authenticated", true}},
+							"then": "",
+						},
+						bson.M{
+							"case": bson.M{"$eq": bson.A{"$client_fingerprint", models.SessionClientFingerprintPassword}},
+							"then": models.DeviceConnectionAuthPassword,
+						},
+						bson.M{
+							"case": bson.M{"$gt": bson.A{"$client_fingerprint", ""}},
+							"then": models.DeviceConnectionAuthPublicKey,
+						},
+					},
+					"default": "",
+				},
+			},
+		},
+	})
+
+	connections := make([]models.DeviceConnection, 0)
+
+	cursor, err := s.db.Collection("sessions").Aggregate(ctx, query)
+	if err != nil {
+		return connections, count, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &connections); err != nil {
+		return connections, count, FromMongoError(err)
+	}
+
+	return connections, count, nil
+}
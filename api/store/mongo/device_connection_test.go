@@ -0,0 +1,121 @@
+package mongo_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceConnectionHistory(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Every session of the device starts a minute after the previous one and lasts as many seconds as its index. The
+	// odd ones are still open and the ones multiple of three were authenticated with a password.
+	sessions := make([]interface{}, 0)
+	for i := 0; i < 25; i++ {
+		session := models.Session{
+			UID:           fmt.Sprintf("session-%02d", i),
+			DeviceUID:     models.UID("device"),
+			TenantID:      "00000000-0000-4000-0000-000000000000",
+			Username:      "root",
+			IPAddress:     fmt.Sprintf("192.168.0.%d", i),
+			StartedAt:     start.Add(time.Duration(i) * time.Minute),
+			LastSeen:      start.Add(time.Duration(i)*time.Minute + time.Duration(i)*time.Second),
+			Closed:        i%2 == 0,
+			Authenticated: true,
+		}
+
+		if i%3 == 0 {
+			session.ClientFingerprint = models.SessionClientFingerprintPassword
+		} else {
+			session.ClientFingerprint = "c1:e8:2d:8d:4d:1b:0a:2a:ba:42:ac:b4:a4:a9:1e:0c"
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	sessions = append(sessions, models.Session{UID: "session-other", DeviceUID: models.UID("other"), StartedAt: start.Add(time.Hour)})
+
+	_, err := db.Collection("sessions").InsertMany(ctx, sessions)
+	require.NoError(t, err)
+
+	// uids returns the session UIDs of the connections.
+	uids := func(connections []models.DeviceConnection) []string {
+		uids := make([]string, len(connections))
+		for i, connection := range connections {
+			uids[i] = connection.SessionUID
+		}
+
+		return uids
+	}
+
+	t.Run("lists the first page from the most recent connection", func(t *testing.T) {
+		connections, count, err := s.DeviceConnectionHistory(ctx, "device", query.Paginator{Page: 1, PerPage: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 25, count)
+		assert.Equal(t, []string{
+			"session-24", "session-23", "session-22", "session-21", "session-20",
+			"session-19", "session-18", "session-17", "session-16", "session-15",
+		}, uids(connections))
+	})
+
+	t.Run("lists the last, partial, page", func(t *testing.T) {
+		connections, count, err := s.DeviceConnectionHistory(ctx, "device", query.Paginator{Page: 3, PerPage: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 25, count)
+		assert.Equal(t, []string{"session-04", "session-03", "session-02", "session-01", "session-00"}, uids(connections))
+	})
+
+	t.Run("lists nothing past the last page", func(t *testing.T) {
+		connections, count, err := s.DeviceConnectionHistory(ctx, "device", query.Paginator{Page: 2, PerPage: 25})
+		require.NoError(t, err)
+		assert.Equal(t, 25, count)
+		assert.Empty(t, connections)
+	})
+
+	t.Run("lists nothing when the device has no connections", func(t *testing.T) {
+		connections, count, err := s.DeviceConnectionHistory(ctx, "nonexistent", query.Paginator{Page: 1, PerPage: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.Empty(t, connections)
+	})
+
+	t.Run("describes each connection from its session", func(t *testing.T) {
+		connections, _, err := s.DeviceConnectionHistory(ctx, "device", query.Paginator{Page: 1, PerPage: 2})
+		require.NoError(t, err)
+
+		disconnectedAt := start.Add(24*time.Minute + 24*time.Second)
+		assert.Equal(t, []models.DeviceConnection{
+			{
+				SessionUID:     "session-24",
+				Username:       "root",
+				IP:             "192.168.0.24",
+				ConnectedAt:    start.Add(24 * time.Minute),
+				DisconnectedAt: &disconnectedAt,
+				Duration:       24000,
+				AuthMethod:     models.DeviceConnectionAuthPassword,
+			},
+			{
+				SessionUID:     "session-23",
+				Username:       "root",
+				IP:             "192.168.0.23",
+				ConnectedAt:    start.Add(23 * time.Minute),
+				DisconnectedAt: nil,
+				Duration:       23000,
+				AuthMethod:     models.DeviceConnectionAuthPublicKey,
+			},
+		}, connections)
+	})
+}
@@ -0,0 +1,75 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/api/store/mongo/queries"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (s *Store) DeviceEventCreate(ctx context.Context, event *models.DeviceEvent) error {
+	event.CreatedAt = clock.Now()
+
+	if _, err := s.db.Collection("device_events").InsertOne(ctx, event); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
+
+func (s *Store) DeviceEventList(ctx context.Context, tenantID string, uid models.UID, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.DeviceEvent, int, error) {
+	query := []bson.M{
+		{
+			"$match": bson.M{
+				"tenant_id":  tenantID,
+				"device_uid": string(uid),
+			},
+		},
+	}
+
+	queryMatch, err := queries.FromFilters(&filters)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+	query = append(query, queryMatch...)
+
+	queryCount := query
+	queryCount = append(queryCount, bson.M{"$count": "count"})
+	count, err := AggregateCount(ctx, s.db.Collection("device_events"), queryCount)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+
+	if sorter.By == "" {
+		sorter.By = "created_at"
+	}
+
+	query = append(query, queries.FromSorter(&sorter)...)
+	query = append(query, queries.FromPaginator(&paginator)...)
+
+	events := make([]models.DeviceEvent, 0)
+
+	cursor, err := s.db.Collection("device_events").Aggregate(ctx, query)
+	if err != nil {
+		return events, count, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &events); err != nil {
+		return events, count, FromMongoError(err)
+	}
+
+	return events, count, nil
+}
+
+func (s *Store) DeviceEventReplaceActor(ctx context.Context, userID, actor string) error {
+	if _, err := s.db.Collection("device_events").
+		UpdateMany(ctx, bson.M{"actor": userID}, bson.M{"$set": bson.M{"actor": actor}}); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package mongo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmocks "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceEvent(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	backend := clock.DefaultBackend
+	t.Cleanup(func() {
+		clock.DefaultBackend = backend
+	})
+
+	clockMock := new(clockmocks.Clock)
+	clock.DefaultBackend = clockMock
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, event := range []*models.DeviceEvent{
+		{TenantID: tenantID, DeviceUID: "device", Type: models.DeviceEventAccepted, Actor: "user"},
+		{TenantID: tenantID, DeviceUID: "device", Type: models.DeviceEventRenamed, Actor: "user", Data: map[string]string{"from": "old", "to": "new"}},
+		{TenantID: tenantID, DeviceUID: "other", Type: models.DeviceEventAccepted},
+		{TenantID: "00000000-0000-4000-0000-000000000001", DeviceUID: "device", Type: models.DeviceEventRemoved},
+	} {
+		clockMock.On("Now").Return(start.Add(time.Duration(i) * time.Minute)).Once()
+		require.NoError(t, s.DeviceEventCreate(ctx, event))
+	}
+
+	events, count, err := s.DeviceEventList(ctx, tenantID, models.UID("device"), query.Paginator{Page: 1, PerPage: 10}, query.Filters{}, query.Sorter{Order: query.OrderDesc})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []models.DeviceEvent{
+		{TenantID: tenantID, DeviceUID: "device", Type: models.DeviceEventRenamed, Actor: "user", Data: map[string]string{"from": "old", "to": "new"}, CreatedAt: start.Add(time.Minute)},
+		{TenantID: tenantID, DeviceUID: "device", Type: models.DeviceEventAccepted, Actor: "user", CreatedAt: start},
+	}, events)
+
+	events, count, err = s.DeviceEventList(ctx, tenantID, models.UID("device"), query.Paginator{Page: 1, PerPage: 1}, query.Filters{}, query.Sorter{Order: query.OrderAsc})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.DeviceEventAccepted, events[0].Type)
+}
+
+func TestDeviceEventReplaceActor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	for _, event := range []*models.DeviceEvent{
+		{TenantID: "00000000-0000-4000-0000-000000000000", DeviceUID: "device", Type: models.DeviceEventAccepted, Actor: "507f1f77bcf86cd799439011"},
+		{TenantID: "00000000-0000-4000-0000-000000000001", DeviceUID: "device", Type: models.DeviceEventRemoved, Actor: "507f1f77bcf86cd799439011"},
+		{TenantID: "00000000-0000-4000-0000-000000000000", DeviceUID: "other", Type: models.DeviceEventAccepted, Actor: "507f1f77bcf86cd799439012"},
+	} {
+		require.NoError(t, s.DeviceEventCreate(ctx, event))
+	}
+
+	require.NoError(t, s.DeviceEventReplaceActor(ctx, "507f1f77bcf86cd799439011", "anonymized"))
+
+	for _, tc := range []struct {
+		tenantID string
+		uid      models.UID
+		actor    string
+	}{
+		{tenantID: "00000000-0000-4000-0000-000000000000", uid: "device", actor: "anonymized"},
+		{tenantID: "00000000-0000-4000-0000-000000000001", uid: "device", actor: "anonymized"},
+		{tenantID: "00000000-0000-4000-0000-000000000000", uid: "other", actor: "507f1f77bcf86cd799439012"},
+	} {
+		events, _, err := s.DeviceEventList(ctx, tc.tenantID, tc.uid, query.Paginator{Page: 1, PerPage: 10}, query.Filters{}, query.Sorter{})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, tc.actor, events[0].Actor)
+	}
+}
@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mongo/queries"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (s *Store) DeviceGroupCreate(ctx context.Context, group *models.DeviceGroup) error {
+	group.CreatedAt = clock.Now()
+	if group.Devices == nil {
+		group.Devices = []string{}
+	}
+
+	if _, err := s.db.Collection("device_groups").InsertOne(ctx, group); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
+
+func (s *Store) DeviceGroupGet(ctx context.Context, tenantID, uid string) (*models.DeviceGroup, error) {
+	group := new(models.DeviceGroup)
+	if err := s.db.Collection("device_groups").FindOne(ctx, bson.M{"tenant_id": tenantID, "uid": uid}).Decode(group); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return group, nil
+}
+
+func (s *Store) DeviceGroupUpdate(ctx context.Context, tenantID, uid string, changes *models.DeviceGroupChanges) error {
+	res, err := s.db.Collection("device_groups").UpdateOne(ctx, bson.M{"tenant_id": tenantID, "uid": uid}, bson.M{"$set": changes})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) DeviceGroupAddDevice(ctx context.Context, tenantID, uid, deviceUID string) error {
+	res, err := s.db.Collection("device_groups").UpdateOne(ctx, bson.M{"tenant_id": tenantID, "uid": uid}, bson.M{"$addToSet": bson.M{"devices": deviceUID}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) DeviceGroupRemoveDevice(ctx context.Context, tenantID, uid, deviceUID string) error {
+	res, err := s.db.Collection("device_groups").UpdateOne(ctx, bson.M{"tenant_id": tenantID, "uid": uid}, bson.M{"$pull": bson.M{"devices": deviceUID}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) DeviceGroupListDevices(ctx context.Context, tenantID, uid string, paginator query.Paginator) ([]models.Device, int, error) {
+	group, err := s.DeviceGroupGet(ctx, tenantID, uid)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := []bson.M{
+		{
+			"$match": bson.M{"tenant_id": tenantID, "uid": bson.M{"$in": group.Devices}},
+		},
+	}
+
+	queryCount := query
+	queryCount = append(queryCount, bson.M{"$count": "count"})
+	count, err := AggregateCount(ctx, s.db.Collection("devices"), queryCount)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+
+	query = append(query, bson.M{"$sort": bson.M{"name": 1}})
+	query = append(query, queries.FromPaginator(&paginator)...)
+	query = append(query, []bson.M{
+		{
+			"$lookup": bson.M{
+				"from":         "connected_devices",
+				"localField":   "uid",
+				"foreignField": "uid",
+				"as":           "online",
+			},
+		},
+		{
+			"$addFields": bson.M{
+				"online": bson.M{"$anyElementTrue": []interface{}{"$online"}},
+			},
+		},
+	}...)
+
+	cursor, err := s.db.Collection("devices").Aggregate(ctx, query)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	devices := make([]models.Device, 0)
+	for cursor.Next(ctx) {
+		device := new(models.Device)
+		if err := cursor.Decode(device); err != nil {
+			return nil, 0, FromMongoError(err)
+		}
+
+		devices = append(devices, *device)
+	}
+
+	return devices, count, nil
+}
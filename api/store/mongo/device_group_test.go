@@ -0,0 +1,91 @@
+package mongo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceGroup(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	group := &models.DeviceGroup{
+		UID:      "group",
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Name:     "servers",
+	}
+	require.NoError(t, s.DeviceGroupCreate(ctx, group))
+
+	got, err := s.DeviceGroupGet(ctx, "00000000-0000-4000-0000-000000000000", "group")
+	require.NoError(t, err)
+	assert.Equal(t, "servers", got.Name)
+	assert.Equal(t, []string{}, got.Devices)
+
+	_, err = s.DeviceGroupGet(ctx, "00000000-0000-4000-0000-000000000001", "group")
+	assert.ErrorIs(t, err, store.ErrNoDocuments)
+
+	devices := []string{"2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c"}
+	require.NoError(t, s.DeviceGroupUpdate(ctx, "00000000-0000-4000-0000-000000000000", "group", &models.DeviceGroupChanges{Devices: &devices}))
+
+	got, err = s.DeviceGroupGet(ctx, "00000000-0000-4000-0000-000000000000", "group")
+	require.NoError(t, err)
+	assert.Equal(t, "servers", got.Name)
+	assert.Equal(t, devices, got.Devices)
+
+	err = s.DeviceGroupUpdate(ctx, "00000000-0000-4000-0000-000000000001", "group", &models.DeviceGroupChanges{Devices: &devices})
+	assert.ErrorIs(t, err, store.ErrNoDocuments)
+}
+
+func TestDeviceGroupMembers(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, srv.Apply(fixtureDevices))
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	require.NoError(t, s.DeviceGroupCreate(ctx, &models.DeviceGroup{UID: "group", TenantID: tenantID, Name: "servers"}))
+
+	for _, uid := range []string{
+		"2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c",
+		"5300530e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809f",
+		// Adding a member again does nothing.
+		"2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c",
+		// A member that doesn't exist anymore isn't listed.
+		"0000000000000000000000000000000000000000000000000000000000000000",
+	} {
+		require.NoError(t, s.DeviceGroupAddDevice(ctx, tenantID, "group", uid))
+	}
+
+	devices, count, err := s.DeviceGroupListDevices(ctx, tenantID, "group", query.Paginator{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, devices, 2)
+	assert.Equal(t, "device-1", devices[0].Name)
+	assert.Equal(t, "device-3", devices[1].Name)
+
+	require.NoError(t, s.DeviceGroupRemoveDevice(ctx, tenantID, "group", "5300530e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809f"))
+
+	devices, count, err = s.DeviceGroupListDevices(ctx, tenantID, "group", query.Paginator{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "device-3", devices[0].Name)
+
+	assert.ErrorIs(t, s.DeviceGroupAddDevice(ctx, "00000000-0000-4000-0000-000000000001", "group", "device"), store.ErrNoDocuments)
+	assert.ErrorIs(t, s.DeviceGroupRemoveDevice(ctx, "00000000-0000-4000-0000-000000000001", "group", "device"), store.ErrNoDocuments)
+
+	_, _, err = s.DeviceGroupListDevices(ctx, "00000000-0000-4000-0000-000000000001", "group", query.Paginator{Page: 1, PerPage: 10})
+	assert.ErrorIs(t, err, store.ErrNoDocuments)
+}
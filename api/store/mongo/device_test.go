@@ -11,6 +11,7 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestDeviceList(t *testing.T) {
@@ -1336,6 +1337,206 @@ func TestDeviceSetPosition(t *testing.T) {
 	}
 }
 
+func TestDeviceSetTrustedHostKey(t *testing.T) {
+	cases := []struct {
+		description string
+		uid         models.UID
+		key         string
+		fixtures    []string
+		expected    error
+	}{
+		{
+			description: "fails when the device is not found",
+			uid:         models.UID("nonexistent"),
+			key:         "key",
+			fixtures:    []string{fixtureDevices},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds when the device is found",
+			uid:         models.UID("2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c"),
+			key:         "key",
+			fixtures:    []string{fixtureDevices},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			err := s.DeviceSetTrustedHostKey(ctx, tc.uid, tc.key)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+}
+
+func TestDeviceSetSessionPolicy(t *testing.T) {
+	cases := []struct {
+		description string
+		uid         models.UID
+		policy      *models.DeviceSessionPolicy
+		fixtures    []string
+		expected    error
+	}{
+		{
+			description: "fails when the device is not found",
+			uid:         models.UID("nonexistent"),
+			policy:      &models.DeviceSessionPolicy{MaxIdle: 300},
+			fixtures:    []string{fixtureDevices},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds setting the policy when the device is found",
+			uid:         models.UID("2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c"),
+			policy:      &models.DeviceSessionPolicy{MaxIdle: 300, ForceRecording: true, AllowedSubsystems: []string{"sftp"}},
+			fixtures:    []string{fixtureDevices},
+			expected:    nil,
+		},
+		{
+			description: "succeeds removing the policy when the device is found",
+			uid:         models.UID("2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c"),
+			policy:      nil,
+			fixtures:    []string{fixtureDevices},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			err := s.DeviceSetSessionPolicy(ctx, tc.uid, tc.policy)
+			assert.Equal(t, tc.expected, err)
+
+			if err == nil {
+				device, err := s.DeviceGet(ctx, tc.uid)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.policy, device.SessionPolicy)
+			}
+		})
+	}
+}
+
+func TestDeviceSetExpiresAt(t *testing.T) {
+	expiresAt := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		description string
+		uid         models.UID
+		expiresAt   *time.Time
+		fixtures    []string
+		expected    error
+	}{
+		{
+			description: "fails when the device is not found",
+			uid:         models.UID("nonexistent"),
+			expiresAt:   &expiresAt,
+			fixtures:    []string{fixtureDevices},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds setting the expiry when the device is found",
+			uid:         models.UID("2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c"),
+			expiresAt:   &expiresAt,
+			fixtures:    []string{fixtureDevices},
+			expected:    nil,
+		},
+		{
+			description: "succeeds removing the expiry when the device is found",
+			uid:         models.UID("2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c"),
+			expiresAt:   nil,
+			fixtures:    []string{fixtureDevices},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			err := s.DeviceSetExpiresAt(ctx, tc.uid, tc.expiresAt)
+			assert.Equal(t, tc.expected, err)
+
+			if err == nil {
+				device, err := s.DeviceGet(ctx, tc.uid)
+				assert.NoError(t, err)
+
+				if tc.expiresAt == nil {
+					assert.Nil(t, device.ExpiresAt)
+				} else {
+					assert.True(t, tc.expiresAt.Equal(*device.ExpiresAt))
+				}
+			}
+		})
+	}
+}
+
+func TestDeviceListExpired(t *testing.T) {
+	expiresAt := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	uid := models.UID("2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c")
+
+	cases := []struct {
+		description string
+		before      time.Time
+		expected    []models.UID
+	}{
+		{
+			description: "succeeds listing nothing before the expiry",
+			before:      expiresAt.Add(-time.Millisecond),
+			expected:    []models.UID{},
+		},
+		{
+			description: "succeeds listing the device at its expiry",
+			before:      expiresAt,
+			expected:    []models.UID{uid},
+		},
+		{
+			description: "succeeds listing the device after its expiry",
+			before:      expiresAt.Add(time.Hour),
+			expected:    []models.UID{uid},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(fixtureDevices))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			assert.NoError(t, s.DeviceSetExpiresAt(ctx, uid, &expiresAt))
+
+			devices, err := s.DeviceListExpired(ctx, tc.before)
+			assert.NoError(t, err)
+
+			uids := make([]models.UID, 0, len(devices))
+			for _, device := range devices {
+				uids = append(uids, models.UID(device.UID))
+			}
+
+			assert.Equal(t, tc.expected, uids)
+		})
+	}
+}
+
 func TestDeviceChooser(t *testing.T) {
 	cases := []struct {
 		description string
@@ -1403,3 +1604,84 @@ func TestDeviceDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestDeviceListByPlatform(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	_, err := db.Collection("devices").InsertMany(ctx, []interface{}{
+		bson.M{"uid": "connector-1", "tenant_id": "00000000-0000-4000-0000-000000000000", "info": bson.M{"platform": "connector"}},
+		bson.M{"uid": "connector-2", "tenant_id": "00000000-0000-4000-0000-000000000000", "info": bson.M{"platform": "connector"}},
+		bson.M{"uid": "docker", "tenant_id": "00000000-0000-4000-0000-000000000000", "info": bson.M{"platform": "docker"}},
+		bson.M{"uid": "connector-3", "tenant_id": "00000000-0000-4001-0000-000000000000", "info": bson.M{"platform": "connector"}},
+	})
+	require.NoError(t, err)
+
+	_, err = db.Collection("connected_devices").InsertOne(ctx, bson.M{"uid": "connector-1", "tenant_id": "00000000-0000-4000-0000-000000000000"})
+	require.NoError(t, err)
+
+	devices, err := s.DeviceListByPlatform(ctx, "00000000-0000-4000-0000-000000000000", models.DevicePlatformConnector)
+	assert.NoError(t, err)
+
+	online := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		online[device.UID] = device.Online
+	}
+
+	assert.Equal(t, map[string]bool{"connector-1": true, "connector-2": false}, online)
+}
+
+func TestDeviceListByStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	_, err := db.Collection("devices").InsertMany(ctx, []interface{}{
+		bson.M{"uid": "uid-2", "name": "beta", "tenant_id": "00000000-0000-4000-0000-000000000000", "status": "accepted"},
+		bson.M{"uid": "uid-1", "name": "alpha", "tenant_id": "00000000-0000-4000-0000-000000000000", "status": "accepted"},
+		bson.M{"uid": "uid-3", "name": "gamma", "tenant_id": "00000000-0000-4000-0000-000000000000", "status": "pending"},
+		bson.M{"uid": "uid-4", "name": "delta", "tenant_id": "00000000-0000-4001-0000-000000000000", "status": "accepted"},
+	})
+	require.NoError(t, err)
+
+	devices, err := s.DeviceListByStatus(ctx, "00000000-0000-4000-0000-000000000000", models.DeviceStatusAccepted)
+	assert.NoError(t, err)
+
+	uids := make([]string, len(devices))
+	for i, device := range devices {
+		uids[i] = device.UID
+	}
+
+	assert.Equal(t, []string{"uid-1", "uid-2"}, uids)
+}
+
+func TestDeviceListByUIDs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	_, err := db.Collection("devices").InsertMany(ctx, []interface{}{
+		bson.M{"uid": "uid-1", "name": "alpha", "tenant_id": "00000000-0000-4000-0000-000000000000", "status": "accepted"},
+		bson.M{"uid": "uid-2", "name": "beta", "tenant_id": "00000000-0000-4000-0000-000000000000", "status": "pending"},
+		bson.M{"uid": "uid-3", "name": "gamma", "tenant_id": "00000000-0000-4000-0000-000000000000", "status": "pending"},
+		bson.M{"uid": "uid-4", "name": "delta", "tenant_id": "00000000-0000-4001-0000-000000000000", "status": "pending"},
+	})
+	require.NoError(t, err)
+
+	devices, err := s.DeviceListByUIDs(ctx, "00000000-0000-4000-0000-000000000000", []models.UID{"uid-1", "uid-2", "uid-4", "nonexistent"})
+	assert.NoError(t, err)
+
+	uids := make([]string, len(devices))
+	for i, device := range devices {
+		uids[i] = device.UID
+	}
+
+	assert.ElementsMatch(t, []string{"uid-1", "uid-2"}, uids)
+}
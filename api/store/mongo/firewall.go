@@ -0,0 +1,122 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (s *Store) FirewallRuleListActive(ctx context.Context, tenantID string) ([]models.FirewallRule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := s.db.Collection("firewall_rules").Find(ctx, bson.M{"tenant_id": tenantID, "active": true}, opts)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	rules := make([]models.FirewallRule, 0)
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return rules, nil
+}
+
+func (s *Store) FirewallRuleListByDeviceGroup(ctx context.Context, tenantID, groupUID string) ([]models.FirewallRule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: 1}})
+
+	cursor, err := s.db.Collection("firewall_rules").Find(ctx, bson.M{"tenant_id": tenantID, "device_group_uid": groupUID}, opts)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	rules := make([]models.FirewallRule, 0)
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return rules, nil
+}
+
+func (s *Store) FirewallRuleConflicts(ctx context.Context, tenantID string, targets []models.FirewallRule) ([]models.FirewallRule, error) {
+	conditions := make([]bson.M, 0, len(targets))
+	for _, target := range targets {
+		if target.Filter.Hostname == "" {
+			continue
+		}
+
+		conditions = append(conditions, bson.M{"priority": target.Priority, "filter.hostname": target.Filter.Hostname})
+	}
+
+	conflicts := make([]models.FirewallRule, 0)
+	if len(conditions) == 0 {
+		return conflicts, nil
+	}
+
+	cursor, err := s.db.Collection("firewall_rules").Find(ctx, bson.M{"tenant_id": tenantID, "$or": conditions})
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &conflicts); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return conflicts, nil
+}
+
+func (s *Store) FirewallRuleBulkWrite(ctx context.Context, create []models.FirewallRule, replace []models.FirewallRule) (int64, int64, error) {
+	writes := make([]mongo.WriteModel, 0, len(create)+len(replace))
+	for i := range create {
+		rule := create[i]
+		rule.ID = ""
+
+		writes = append(writes, mongo.NewInsertOneModel().SetDocument(&rule))
+	}
+
+	for i := range replace {
+		objID, err := primitive.ObjectIDFromHex(replace[i].ID)
+		if err != nil {
+			return 0, 0, FromMongoError(err)
+		}
+
+		rule := replace[i]
+		rule.ID = ""
+
+		writes = append(writes, mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": objID}).SetReplacement(&rule))
+	}
+
+	if len(writes) == 0 {
+		return 0, 0, nil
+	}
+
+	session, err := s.db.Client().StartSession()
+	if err != nil {
+		return 0, 0, FromMongoError(err)
+	}
+	defer session.EndSession(ctx)
+
+	var inserted, replaced int64
+	_, err = session.WithTransaction(ctx, func(mongoctx mongo.SessionContext) (interface{}, error) {
+		res, err := s.db.Collection("firewall_rules").BulkWrite(mongoctx, writes)
+		if err != nil {
+			return nil, err
+		}
+
+		inserted, replaced = res.InsertedCount, res.MatchedCount
+
+		return nil, nil
+	})
+	if err != nil {
+		return 0, 0, FromMongoError(err)
+	}
+
+	return inserted, replaced, nil
+}
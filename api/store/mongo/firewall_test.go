@@ -0,0 +1,214 @@
+package mongo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFirewallRuleConflicts(t *testing.T) {
+	type Expected struct {
+		priorities []int
+		err        error
+	}
+
+	rule := func(priority int, hostname string) models.FirewallRule {
+		return models.FirewallRule{
+			TenantID: "00000000-0000-4000-0000-000000000000",
+			FirewallRuleFields: models.FirewallRuleFields{
+				Priority: priority,
+				Action:   "allow",
+				Active:   true,
+				SourceIP: ".*",
+				Username: ".*",
+				Filter:   models.FirewallFilter{Hostname: hostname},
+			},
+		}
+	}
+
+	cases := []struct {
+		description string
+		tenantID    string
+		existing    []models.FirewallRule
+		targets     []models.FirewallRule
+		expected    Expected
+	}{
+		{
+			description: "succeeds with no conflicts when targets have no hostname",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			existing:    []models.FirewallRule{rule(1, "")},
+			targets:     []models.FirewallRule{rule(1, "")},
+			expected:    Expected{priorities: []int{}, err: nil},
+		},
+		{
+			description: "succeeds with no conflicts when tenant differs",
+			tenantID:    "00000000-0000-4000-0000-000000000001",
+			existing:    []models.FirewallRule{rule(1, "device")},
+			targets:     []models.FirewallRule{rule(1, "device")},
+			expected:    Expected{priorities: []int{}, err: nil},
+		},
+		{
+			description: "succeeds finding the rules with same priority and hostname",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			existing:    []models.FirewallRule{rule(1, "device"), rule(2, "device"), rule(3, "other")},
+			targets:     []models.FirewallRule{rule(1, "device"), rule(3, "device")},
+			expected:    Expected{priorities: []int{1}, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			_, _, err := s.FirewallRuleBulkWrite(ctx, tc.existing, nil)
+			require.NoError(t, err)
+
+			conflicts, err := s.FirewallRuleConflicts(ctx, tc.tenantID, tc.targets)
+
+			priorities := make([]int, 0, len(conflicts))
+			for _, conflict := range conflicts {
+				priorities = append(priorities, conflict.Priority)
+			}
+
+			assert.Equal(t, tc.expected, Expected{priorities: priorities, err: err})
+		})
+	}
+}
+
+func TestFirewallRuleBulkWrite(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	assert.NoError(t, srv.Apply(fixtureFirewallRules))
+
+	existing := models.FirewallRule{}
+	require.NoError(t, db.Collection("firewall_rules").FindOne(ctx, bson.M{"priority": 1}).Decode(&existing))
+
+	existing.Action = "deny"
+
+	create := []models.FirewallRule{
+		{
+			TenantID: "00000000-0000-4000-0000-000000000000",
+			FirewallRuleFields: models.FirewallRuleFields{
+				Priority: 5,
+				Action:   "allow",
+				Active:   true,
+				SourceIP: ".*",
+				Username: ".*",
+				Filter:   models.FirewallFilter{Hostname: "device"},
+			},
+		},
+	}
+
+	inserted, replaced, err := s.FirewallRuleBulkWrite(ctx, create, []models.FirewallRule{existing})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), inserted)
+	assert.Equal(t, int64(1), replaced)
+
+	count, err := db.Collection("firewall_rules").CountDocuments(ctx, bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+
+	updated := models.FirewallRule{}
+	require.NoError(t, db.Collection("firewall_rules").FindOne(ctx, bson.M{"priority": 1}).Decode(&updated))
+	assert.Equal(t, "deny", updated.Action)
+}
+
+func TestFirewallRuleListActive(t *testing.T) {
+	type Expected struct {
+		priorities []int
+		err        error
+	}
+
+	cases := []struct {
+		description string
+		tenantID    string
+		fixtures    []string
+		setup       func() error
+		expected    Expected
+	}{
+		{
+			description: "succeeds with no rules when tenant has none",
+			tenantID:    "00000000-0000-4000-0000-000000000001",
+			fixtures:    []string{fixtureFirewallRules},
+			setup:       func() error { return nil },
+			expected:    Expected{priorities: []int{}, err: nil},
+		},
+		{
+			description: "succeeds listing the active rules sorted by priority",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			fixtures:    []string{fixtureFirewallRules},
+			setup: func() error {
+				_, err := db.Collection("firewall_rules").UpdateOne(context.Background(), bson.M{"priority": 2}, bson.M{"$set": bson.M{"active": false}})
+
+				return err
+			},
+			expected: Expected{priorities: []int{1, 3, 4}, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			require.NoError(t, tc.setup())
+
+			rules, err := s.FirewallRuleListActive(ctx, tc.tenantID)
+
+			priorities := make([]int, 0, len(rules))
+			for _, rule := range rules {
+				priorities = append(priorities, rule.Priority)
+			}
+
+			assert.Equal(t, tc.expected, Expected{priorities: priorities, err: err})
+		})
+	}
+}
+
+func TestFirewallRuleListByDeviceGroup(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	assert.NoError(t, srv.Apply(fixtureFirewallRules))
+
+	_, err := db.Collection("firewall_rules").UpdateMany(
+		ctx,
+		bson.M{"priority": bson.M{"$in": []int{2, 4}}},
+		bson.M{"$set": bson.M{"device_group_uid": "group"}},
+	)
+	require.NoError(t, err)
+
+	rules, err := s.FirewallRuleListByDeviceGroup(ctx, "00000000-0000-4000-0000-000000000000", "group")
+	assert.NoError(t, err)
+
+	priorities := make([]int, 0, len(rules))
+	for _, rule := range rules {
+		priorities = append(priorities, rule.Priority)
+		assert.Equal(t, "group", rule.DeviceGroupUID)
+	}
+
+	assert.Equal(t, []int{2, 4}, priorities)
+
+	rules, err = s.FirewallRuleListByDeviceGroup(ctx, "00000000-0000-4000-0000-000000000001", "group")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+}
@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (s *Store) InviteLinkCreate(ctx context.Context, link *models.InviteLink) error {
+	link.CreatedAt = clock.Now()
+
+	if _, err := s.db.Collection("invite_links").InsertOne(ctx, link); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
+
+func (s *Store) InviteLinkGet(ctx context.Context, token string) (*models.InviteLink, error) {
+	link := new(models.InviteLink)
+	if err := s.db.Collection("invite_links").FindOne(ctx, bson.M{"_id": token}).Decode(link); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return link, nil
+}
+
+func (s *Store) InviteLinkList(ctx context.Context, tenantID string) ([]models.InviteLink, error) {
+	cursor, err := s.db.Collection("invite_links").Find(
+		ctx,
+		bson.M{"tenant_id": tenantID},
+		options.Find().SetSort(bson.M{"created_at": -1}),
+	)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	links := make([]models.InviteLink, 0)
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return links, nil
+}
+
+func (s *Store) InviteLinkUse(ctx context.Context, token string, now time.Time) (*models.InviteLink, error) {
+	// NOTICE: the link is checked and incremented in a single operation, so concurrent uses cannot both see the last
+	// remaining use.
+	filter := bson.M{
+		"_id":        token,
+		"expires_at": bson.M{"$gt": now},
+		"$expr":      bson.M{"$lt": bson.A{"$use_count", "$max_uses"}},
+	}
+
+	link := new(models.InviteLink)
+	if err := s.db.Collection("invite_links").
+		FindOneAndUpdate(ctx, filter, bson.M{"$inc": bson.M{"use_count": 1}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).
+		Decode(link); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return link, nil
+}
+
+func (s *Store) InviteLinkDelete(ctx context.Context, tenantID, token string) error {
+	res, err := s.db.Collection("invite_links").DeleteOne(ctx, bson.M{"_id": token, "tenant_id": tenantID})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.DeletedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) InviteLinkReplaceCreator(ctx context.Context, userID, creator string) error {
+	if _, err := s.db.Collection("invite_links").
+		UpdateMany(ctx, bson.M{"created_by": userID}, bson.M{"$set": bson.M{"created_by": creator}}); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package mongo_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInviteLink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	now := time.Date(2023, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	link := &models.InviteLink{
+		Token:     "token",
+		TenantID:  "00000000-0000-4000-0000-000000000000",
+		Role:      "observer",
+		MaxUses:   1,
+		CreatedBy: "507f1f77bcf86cd799439011",
+		ExpiresAt: now.Add(time.Hour),
+	}
+	require.NoError(t, s.InviteLinkCreate(ctx, link))
+
+	got, err := s.InviteLinkGet(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "observer", got.Role)
+	assert.Equal(t, 0, got.UseCount)
+
+	links, err := s.InviteLinkList(ctx, "00000000-0000-4000-0000-000000000000")
+	require.NoError(t, err)
+	assert.Len(t, links, 1)
+
+	links, err = s.InviteLinkList(ctx, "00000000-0000-4000-0000-000000000001")
+	require.NoError(t, err)
+	assert.Len(t, links, 0)
+
+	// An expired link cannot be used.
+	_, err = s.InviteLinkUse(ctx, "token", now.Add(time.Hour))
+	assert.ErrorIs(t, err, store.ErrNoDocuments)
+
+	got, err = s.InviteLinkUse(ctx, "token", now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.UseCount)
+
+	// An exhausted link cannot be used.
+	_, err = s.InviteLinkUse(ctx, "token", now)
+	assert.ErrorIs(t, err, store.ErrNoDocuments)
+
+	assert.ErrorIs(t, s.InviteLinkDelete(ctx, "00000000-0000-4000-0000-000000000001", "token"), store.ErrNoDocuments)
+	require.NoError(t, s.InviteLinkDelete(ctx, "00000000-0000-4000-0000-000000000000", "token"))
+
+	_, err = s.InviteLinkGet(ctx, "token")
+	assert.ErrorIs(t, err, store.ErrNoDocuments)
+}
+
+func TestInviteLinkReplaceCreator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	for _, link := range []*models.InviteLink{
+		{Token: "first", TenantID: "00000000-0000-4000-0000-000000000000", CreatedBy: "507f1f77bcf86cd799439011"},
+		{Token: "second", TenantID: "00000000-0000-4000-0000-000000000001", CreatedBy: "507f1f77bcf86cd799439011"},
+		{Token: "other", TenantID: "00000000-0000-4000-0000-000000000000", CreatedBy: "507f1f77bcf86cd799439012"},
+	} {
+		require.NoError(t, s.InviteLinkCreate(ctx, link))
+	}
+
+	require.NoError(t, s.InviteLinkReplaceCreator(ctx, "507f1f77bcf86cd799439011", "anonymized"))
+
+	for token, creator := range map[string]string{"first": "anonymized", "second": "anonymized", "other": "507f1f77bcf86cd799439012"} {
+		link, err := s.InviteLinkGet(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, creator, link.CreatedBy)
+	}
+}
+
+func TestInviteLinkUse_concurrently(t *testing.T) {
+	cases := []struct {
+		description string
+		maxUses     int
+		uses        int
+	}{
+		{
+			description: "succeeds only once when the link has a single use",
+			maxUses:     1,
+			uses:        50,
+		},
+		{
+			description: "succeeds at most max uses times",
+			maxUses:     10,
+			uses:        50,
+		},
+		{
+			description: "succeeds every time when there are less uses than allowed",
+			maxUses:     50,
+			uses:        20,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			now := time.Date(2023, time.January, 1, 12, 0, 0, 0, time.UTC)
+			require.NoError(t, s.InviteLinkCreate(ctx, &models.InviteLink{
+				Token:     "token",
+				TenantID:  "00000000-0000-4000-0000-000000000000",
+				Role:      "observer",
+				MaxUses:   tc.maxUses,
+				ExpiresAt: now.Add(time.Hour),
+			}))
+
+			var succeeded atomic.Int64
+
+			wg := new(sync.WaitGroup)
+			for i := 0; i < tc.uses; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					if _, err := s.InviteLinkUse(ctx, "token", now); err == nil {
+						succeeded.Add(1)
+					} else {
+						assert.ErrorIs(t, err, store.ErrNoDocuments)
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			expected := min(tc.maxUses, tc.uses)
+			assert.Equal(t, int64(expected), succeeded.Load())
+
+			link, err := s.InviteLinkGet(ctx, "token")
+			require.NoError(t, err)
+			assert.Equal(t, expected, link.UseCount)
+		})
+	}
+}
@@ -79,6 +79,14 @@ func GenerateMigrations() []migrate.Migration {
 		migration67,
 		migration68,
 		migration69,
+		migration70,
+		migration71,
+		migration72,
+		migration73,
+		migration74,
+		migration75,
+		migration76,
+		migration77,
 	}
 }
 
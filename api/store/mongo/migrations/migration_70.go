@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var migration70 = migrate.Migration{
+	Version:     70,
+	Description: "Setting the 'settings.default_firewall_policy' attribute of the namespaces to 'allow' when it is not defined.",
+	Up: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		logrus.WithFields(logrus.Fields{
+			"component": "migration",
+			"version":   70,
+			"action":    "Up",
+		}).Info("Applying migration")
+
+		filter := bson.M{
+			"settings.default_firewall_policy": bson.M{"$in": []interface{}{nil, ""}},
+		}
+
+		update := bson.M{
+			"$set": bson.M{
+				"settings.default_firewall_policy": "allow",
+			},
+		}
+
+		_, err := db.
+			Collection("namespaces").
+			UpdateMany(ctx, filter, update)
+
+		return err
+	}),
+	Down: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		logrus.WithFields(logrus.Fields{
+			"component": "migration",
+			"version":   70,
+			"action":    "Down",
+		}).Info("Reverting migration")
+
+		filter := bson.M{
+			"settings.default_firewall_policy": "allow",
+		}
+
+		update := bson.M{
+			"$unset": bson.M{
+				"settings.default_firewall_policy": "",
+			},
+		}
+
+		_, err := db.
+			Collection("namespaces").
+			UpdateMany(ctx, filter, update)
+
+		return err
+	}),
+}
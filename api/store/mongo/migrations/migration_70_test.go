@@ -0,0 +1,132 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/pkg/envs"
+	envMocks "github.com/shellhub-io/shellhub/pkg/envs/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMigration70(t *testing.T) {
+	ctx := context.Background()
+
+	mock := &envMocks.Backend{}
+	envs.DefaultBackend = mock
+
+	find := func(tenantID string) (*models.Namespace, error) {
+		ns := new(models.Namespace)
+		if err := c.Database("test").Collection("namespaces").FindOne(ctx, bson.M{"tenant_id": tenantID}).Decode(ns); err != nil {
+			return nil, errors.New("unable to find the namespace")
+		}
+
+		return ns, nil
+	}
+
+	cases := []struct {
+		description string
+		setup       func() error
+		test        func() error
+	}{
+		{
+			description: "Success to apply up on migration 70",
+			setup: func() error {
+				_, err := c.
+					Database("test").
+					Collection("namespaces").
+					InsertMany(ctx, []interface{}{
+						models.Namespace{
+							TenantID: "00000000-0000-4000-0000-000000000000",
+							Settings: &models.NamespaceSettings{},
+						},
+						models.Namespace{
+							TenantID: "00000000-0000-4000-0000-000000000001",
+							Settings: &models.NamespaceSettings{DefaultFirewallPolicy: models.FirewallPolicyDeny},
+						},
+					})
+
+				return err
+			},
+			test: func() error {
+				migrations := GenerateMigrations()[69:70]
+				migrates := migrate.NewMigrate(c.Database("test"), migrations...)
+				if err := migrates.Up(ctx, migrate.AllAvailable); err != nil {
+					return err
+				}
+
+				ns, err := find("00000000-0000-4000-0000-000000000000")
+				if err != nil {
+					return err
+				}
+
+				if ns.Settings.DefaultFirewallPolicy != models.FirewallPolicyAllow {
+					return errors.New("unable to apply the migration")
+				}
+
+				ns, err = find("00000000-0000-4000-0000-000000000001")
+				if err != nil {
+					return err
+				}
+
+				if ns.Settings.DefaultFirewallPolicy != models.FirewallPolicyDeny {
+					return errors.New("the migration must keep the defined policies")
+				}
+
+				return nil
+			},
+		},
+		{
+			description: "Success to apply down on migration 70",
+			setup: func() error {
+				_, err := c.
+					Database("test").
+					Collection("namespaces").
+					InsertOne(ctx, models.Namespace{
+						TenantID: "00000000-0000-4000-0000-000000000000",
+						Settings: &models.NamespaceSettings{},
+					})
+
+				return err
+			},
+			test: func() error {
+				migrations := GenerateMigrations()[69:70]
+				migrates := migrate.NewMigrate(c.Database("test"), migrations...)
+				if err := migrates.Up(ctx, migrate.AllAvailable); err != nil {
+					return err
+				}
+
+				if err := migrates.Down(ctx, migrate.AllAvailable); err != nil {
+					return err
+				}
+
+				ns, err := find("00000000-0000-4000-0000-000000000000")
+				if err != nil {
+					return err
+				}
+
+				if ns.Settings.DefaultFirewallPolicy != "" {
+					return errors.New("unable to revert the migration")
+				}
+
+				return nil
+			},
+		},
+	}
+
+	for _, test := range cases {
+		tc := test
+		t.Run(tc.description, func(t *testing.T) {
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			assert.NoError(t, tc.setup())
+			assert.NoError(t, tc.test())
+		})
+	}
+}
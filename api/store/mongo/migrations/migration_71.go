@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var migration71 = migrate.Migration{
+	Version:     71,
+	Description: "create index for members.id on namespaces",
+	Up: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   71,
+			"action":    "Up",
+		}).Info("Applying migration up")
+
+		indexName := "members.id"
+		_, err := db.Collection("namespaces").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+			Keys: bson.M{
+				"members.id": 1,
+			},
+			Options: &options.IndexOptions{ //nolint:exhaustruct
+				Name: &indexName,
+			},
+		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"component": "migration",
+				"version":   71,
+				"action":    "Up",
+			}).WithError(err).Info("Error while trying to apply migration 71")
+
+			return err
+		}
+
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   71,
+			"action":    "Up",
+		}).Info("Succeeds to to apply migration 71")
+
+		return nil
+	}),
+	Down: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   71,
+			"action":    "Down",
+		}).Info("Applying migration down")
+		if _, err := db.Collection("namespaces").Indexes().DropOne(context.Background(), "members.id"); err != nil {
+			return err
+		}
+
+		return nil
+	}),
+}
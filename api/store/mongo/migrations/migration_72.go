@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var migration72 = migrate.Migration{
+	Version:     72,
+	Description: "create indexes for user_id and expires_at on user_sessions",
+	Up: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   72,
+			"action":    "Up",
+		}).Info("Applying migration up")
+
+		indexes := []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "user_id", Value: 1}},
+				Options: options.Index().SetName("user_id"),
+			},
+			{
+				// NOTICE: expired sessions are removed as soon as their expiration date is reached.
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetName("expires_at").SetExpireAfterSeconds(0),
+			},
+		}
+
+		if _, err := db.Collection("user_sessions").Indexes().CreateMany(ctx, indexes); err != nil {
+			log.WithFields(log.Fields{
+				"component": "migration",
+				"version":   72,
+				"action":    "Up",
+			}).WithError(err).Info("Error while trying to apply migration 72")
+
+			return err
+		}
+
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   72,
+			"action":    "Up",
+		}).Info("Succeeds to to apply migration 72")
+
+		return nil
+	}),
+	Down: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   72,
+			"action":    "Down",
+		}).Info("Applying migration down")
+
+		for _, name := range []string{"user_id", "expires_at"} {
+			if _, err := db.Collection("user_sessions").Indexes().DropOne(ctx, name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}),
+}
@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMigration72(t *testing.T) {
+	ctx := context.Background()
+
+	hasIndex := func() (bool, error) {
+		cursor, err := c.Database("test").Collection("user_sessions").Indexes().List(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		var found int
+		for cursor.Next(ctx) {
+			var index bson.M
+			if err := cursor.Decode(&index); err != nil {
+				return false, err
+			}
+
+			switch index["name"] {
+			case "user_id":
+				found++
+			case "expires_at":
+				if index["expireAfterSeconds"] == int32(0) {
+					found++
+				}
+			}
+		}
+
+		return found == 2, nil
+	}
+
+	cases := []struct {
+		description string
+		test        func() error
+	}{
+		{
+			description: "Success to apply up on migration 72",
+			test: func() error {
+				migrates := migrate.NewMigrate(c.Database("test"), GenerateMigrations()[71:72]...)
+				if err := migrates.Up(ctx, migrate.AllAvailable); err != nil {
+					return err
+				}
+
+				found, err := hasIndex()
+				if err != nil {
+					return err
+				}
+
+				if !found {
+					return errors.New("indexes not created")
+				}
+
+				return nil
+			},
+		},
+		{
+			description: "Success to apply down on migration 72",
+			test: func() error {
+				migrates := migrate.NewMigrate(c.Database("test"), GenerateMigrations()[71:72]...)
+				if err := migrates.Up(ctx, migrate.AllAvailable); err != nil {
+					return err
+				}
+
+				if err := migrates.Down(ctx, migrate.AllAvailable); err != nil {
+					return err
+				}
+
+				found, err := hasIndex()
+				if err != nil {
+					return err
+				}
+
+				if found {
+					return errors.New("indexes not dropped")
+				}
+
+				return nil
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			assert.NoError(t, tc.test())
+		})
+	}
+}
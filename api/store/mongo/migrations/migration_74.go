@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var migration74 = migrate.Migration{
+	Version:     74,
+	Description: "set the last_active_at of the namespaces to their created_at",
+	Up: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   74,
+			"action":    "Up",
+		}).Info("Applying migration up")
+
+		// NOTICE: the namespaces without a creation date are considered active when the migration is applied, so they
+		// aren't expired right away.
+		update := bson.A{
+			bson.M{"$set": bson.M{"last_active_at": bson.M{"$ifNull": bson.A{"$created_at", "$$NOW"}}}},
+		}
+
+		if _, err := db.Collection("namespaces").UpdateMany(ctx, bson.M{"last_active_at": bson.M{"$exists": false}}, update); err != nil {
+			log.WithFields(log.Fields{
+				"component": "migration",
+				"version":   74,
+				"action":    "Up",
+			}).WithError(err).Info("Error while trying to apply migration 74")
+
+			return err
+		}
+
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   74,
+			"action":    "Up",
+		}).Info("Succeeds to to apply migration 74")
+
+		return nil
+	}),
+	Down: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   74,
+			"action":    "Down",
+		}).Info("Applying migration down")
+
+		if _, err := db.Collection("namespaces").UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"last_active_at": "", "expires_at": ""}}); err != nil {
+			return err
+		}
+
+		return nil
+	}),
+}
@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMigration74(t *testing.T) {
+	ctx := context.Background()
+
+	createdAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	activeAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	lastActiveAt := func(tenant string) (interface{}, bool, error) {
+		namespace := make(bson.M)
+		if err := c.Database("test").Collection("namespaces").FindOne(ctx, bson.M{"tenant_id": tenant}).Decode(&namespace); err != nil {
+			return nil, false, err
+		}
+
+		value, ok := namespace["last_active_at"]
+
+		return value, ok, nil
+	}
+
+	t.Run("Success to apply up on migration 74", func(t *testing.T) {
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Reset())
+		})
+
+		_, err := c.Database("test").Collection("namespaces").InsertMany(ctx, []interface{}{
+			bson.M{"tenant_id": "00000000-0000-4000-0000-000000000000", "created_at": createdAt},
+			bson.M{"tenant_id": "00000000-0000-4001-0000-000000000000", "created_at": createdAt, "last_active_at": activeAt},
+			bson.M{"tenant_id": "00000000-0000-4002-0000-000000000000"},
+		})
+		require.NoError(t, err)
+
+		migrates := migrate.NewMigrate(c.Database("test"), GenerateMigrations()[73:74]...)
+		require.NoError(t, migrates.Up(ctx, migrate.AllAvailable))
+
+		value, ok, err := lastActiveAt("00000000-0000-4000-0000-000000000000")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, primitive.NewDateTimeFromTime(createdAt), value)
+	})
+
+	t.Run("Success to apply down on migration 74", func(t *testing.T) {
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Reset())
+		})
+
+		_, err := c.Database("test").Collection("namespaces").InsertOne(ctx, bson.M{"tenant_id": "00000000-0000-4000-0000-000000000000", "created_at": createdAt})
+		require.NoError(t, err)
+
+		migrates := migrate.NewMigrate(c.Database("test"), GenerateMigrations()[73:74]...)
+		require.NoError(t, migrates.Up(ctx, migrate.AllAvailable))
+		require.NoError(t, migrates.Down(ctx, migrate.AllAvailable))
+
+		_, ok, err := lastActiveAt("00000000-0000-4000-0000-000000000000")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
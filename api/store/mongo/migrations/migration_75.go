@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var migration75 = migrate.Migration{
+	Version:     75,
+	Description: "set the onboarding of the existing users as completed",
+	Up: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   75,
+			"action":    "Up",
+		}).Info("Applying migration up")
+
+		// NOTICE: the onboarding is only shown to the users created from now on, as the existing ones already use
+		// ShellHub.
+		if _, err := db.Collection("users").UpdateMany(ctx, bson.M{"onboarding_completed": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"onboarding_completed": true}}); err != nil {
+			log.WithFields(log.Fields{
+				"component": "migration",
+				"version":   75,
+				"action":    "Up",
+			}).WithError(err).Info("Error while trying to apply migration 75")
+
+			return err
+		}
+
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   75,
+			"action":    "Up",
+		}).Info("Succeeds to to apply migration 75")
+
+		return nil
+	}),
+	Down: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   75,
+			"action":    "Down",
+		}).Info("Applying migration down")
+
+		if _, err := db.Collection("users").UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"onboarding_completed": ""}}); err != nil {
+			return err
+		}
+
+		return nil
+	}),
+}
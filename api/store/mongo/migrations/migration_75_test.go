@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMigration75(t *testing.T) {
+	ctx := context.Background()
+
+	onboardingCompleted := func(username string) (interface{}, bool, error) {
+		user := make(bson.M)
+		if err := c.Database("test").Collection("users").FindOne(ctx, bson.M{"username": username}).Decode(&user); err != nil {
+			return nil, false, err
+		}
+
+		value, ok := user["onboarding_completed"]
+
+		return value, ok, nil
+	}
+
+	t.Run("Success to apply up on migration 75", func(t *testing.T) {
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Reset())
+		})
+
+		_, err := c.Database("test").Collection("users").InsertMany(ctx, []interface{}{
+			bson.M{"username": "existing"},
+			bson.M{"username": "onboarding", "onboarding_completed": false},
+		})
+		require.NoError(t, err)
+
+		migrates := migrate.NewMigrate(c.Database("test"), GenerateMigrations()[74:75]...)
+		require.NoError(t, migrates.Up(ctx, migrate.AllAvailable))
+
+		value, ok, err := onboardingCompleted("existing")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, true, value)
+
+		value, ok, err = onboardingCompleted("onboarding")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, false, value)
+	})
+
+	t.Run("Success to apply down on migration 75", func(t *testing.T) {
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Reset())
+		})
+
+		_, err := c.Database("test").Collection("users").InsertOne(ctx, bson.M{"username": "existing"})
+		require.NoError(t, err)
+
+		migrates := migrate.NewMigrate(c.Database("test"), GenerateMigrations()[74:75]...)
+		require.NoError(t, migrates.Up(ctx, migrate.AllAvailable))
+		require.NoError(t, migrates.Down(ctx, migrate.AllAvailable))
+
+		_, ok, err := onboardingCompleted("existing")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var migration76 = migrate.Migration{
+	Version:     76,
+	Description: "create index for tenant_id and created_at on webhook_dead_letters",
+	Up: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   76,
+			"action":    "Up",
+		}).Info("Applying migration up")
+
+		index := mongo.IndexModel{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("tenant_id_created_at"),
+		}
+
+		if _, err := db.Collection("webhook_dead_letters").Indexes().CreateOne(ctx, index); err != nil {
+			log.WithFields(log.Fields{
+				"component": "migration",
+				"version":   76,
+				"action":    "Up",
+			}).WithError(err).Info("Error while trying to apply migration 76")
+
+			return err
+		}
+
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   76,
+			"action":    "Up",
+		}).Info("Succeeds to to apply migration 76")
+
+		return nil
+	}),
+	Down: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   76,
+			"action":    "Down",
+		}).Info("Applying migration down")
+
+		if _, err := db.Collection("webhook_dead_letters").Indexes().DropOne(ctx, "tenant_id_created_at"); err != nil {
+			return err
+		}
+
+		return nil
+	}),
+}
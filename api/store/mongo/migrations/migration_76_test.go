@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMigration76(t *testing.T) {
+	ctx := context.Background()
+
+	hasIndex := func() (bool, error) {
+		cursor, err := c.Database("test").Collection("webhook_dead_letters").Indexes().List(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		for cursor.Next(ctx) {
+			var index bson.M
+			if err := cursor.Decode(&index); err != nil {
+				return false, err
+			}
+
+			if index["name"] == "tenant_id_created_at" {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	cases := []struct {
+		description string
+		test        func() error
+	}{
+		{
+			description: "Success to apply up on migration 76",
+			test: func() error {
+				migrates := migrate.NewMigrate(c.Database("test"), GenerateMigrations()[75:76]...)
+				if err := migrates.Up(ctx, migrate.AllAvailable); err != nil {
+					return err
+				}
+
+				found, err := hasIndex()
+				if err != nil {
+					return err
+				}
+
+				if !found {
+					return errors.New("index not created")
+				}
+
+				return nil
+			},
+		},
+		{
+			description: "Success to apply down on migration 76",
+			test: func() error {
+				migrates := migrate.NewMigrate(c.Database("test"), GenerateMigrations()[75:76]...)
+				if err := migrates.Up(ctx, migrate.AllAvailable); err != nil {
+					return err
+				}
+
+				if err := migrates.Down(ctx, migrate.AllAvailable); err != nil {
+					return err
+				}
+
+				found, err := hasIndex()
+				if err != nil {
+					return err
+				}
+
+				if found {
+					return errors.New("index not dropped")
+				}
+
+				return nil
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			assert.NoError(t, tc.test())
+		})
+	}
+}
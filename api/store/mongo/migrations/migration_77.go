@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var migration77 = migrate.Migration{
+	Version:     77,
+	Description: "create a TTL index for created_at on webhook_dead_letters",
+	Up: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   77,
+			"action":    "Up",
+		}).Info("Applying migration up")
+
+		// NOTICE: the dead letters are kept for 30 days, as they're only inspected and never redelivered.
+		index := mongo.IndexModel{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("ttl").SetExpireAfterSeconds(2592000),
+		}
+
+		if _, err := db.Collection("webhook_dead_letters").Indexes().CreateOne(ctx, index); err != nil {
+			log.WithFields(log.Fields{
+				"component": "migration",
+				"version":   77,
+				"action":    "Up",
+			}).WithError(err).Info("Error while trying to apply migration 77")
+
+			return err
+		}
+
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   77,
+			"action":    "Up",
+		}).Info("Succeeds to to apply migration 77")
+
+		return nil
+	}),
+	Down: migrate.MigrationFunc(func(ctx context.Context, db *mongo.Database) error {
+		log.WithFields(log.Fields{
+			"component": "migration",
+			"version":   77,
+			"action":    "Down",
+		}).Info("Applying migration down")
+
+		if _, err := db.Collection("webhook_dead_letters").Indexes().DropOne(ctx, "ttl"); err != nil {
+			return err
+		}
+
+		return nil
+	}),
+}
@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
@@ -9,6 +10,8 @@ import (
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mongo/queries"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
@@ -16,7 +19,12 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func (s *Store) NamespaceList(ctx context.Context, paginator query.Paginator, filters query.Filters, export bool) ([]models.Namespace, int, error) {
+func (s *Store) NamespaceList(ctx context.Context, paginator query.Paginator, filters query.Filters, role string, export bool) ([]models.Namespace, int, error) {
+	// Listing the namespaces of the user in context is done through the members index, as it is the most common view.
+	if id := gateway.IDFromContext(ctx); id != nil && !export {
+		return s.NamespaceListByMember(ctx, id.ID, role, paginator, filters)
+	}
+
 	query := []bson.M{}
 	queryMatch, err := queries.FromFilters(&filters)
 	if err != nil {
@@ -69,9 +77,7 @@ func (s *Store) NamespaceList(ctx context.Context, paginator query.Paginator, fi
 		query = append(query, bson.M{
 			"$match": bson.M{
 				"members": bson.M{
-					"$elemMatch": bson.M{
-						"id": user.ID,
-					},
+					"$elemMatch": memberMatch(user.ID, role),
 				},
 			},
 		})
@@ -113,6 +119,85 @@ func (s *Store) NamespaceList(ctx context.Context, paginator query.Paginator, fi
 	return namespaces, count, err
 }
 
+// memberMatch matches the member with the specified ID and, when it's not empty, the specified role.
+func memberMatch(userID string, role string) bson.M {
+	match := bson.M{"id": userID}
+	if role != "" {
+		match["role"] = role
+	}
+
+	return match
+}
+
+func (s *Store) NamespaceListByMember(ctx context.Context, userID string, role string, paginator query.Paginator, filters query.Filters) ([]models.Namespace, int, error) {
+	// NOTICE: the match on the members must be the first stage to use the "members.id" index. The role is matched on
+	// the same member, so a namespace where the user has another role isn't listed.
+	query := []bson.M{
+		{
+			"$match": bson.M{"members": bson.M{"$elemMatch": memberMatch(userID, role)}},
+		},
+	}
+
+	queryMatch, err := queries.FromFilters(&filters)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+	query = append(query, queryMatch...)
+
+	return s.namespaceListByPipeline(ctx, query, paginator)
+}
+
+func (s *Store) NamespaceListByOwner(ctx context.Context, ownerID string, paginator query.Paginator) ([]models.Namespace, int, error) {
+	filters := query.Filters{
+		Data: []query.Filter{{Type: query.FilterTypeOwnerID, Params: &query.FilterOwnerID{ID: ownerID}}},
+	}
+
+	query, err := queries.FromFilters(&filters)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+
+	return s.namespaceListByPipeline(ctx, query, paginator)
+}
+
+// namespaceListByPipeline lists a page of the namespaces matched by the aggregation pipeline, with their number of accepted
+// devices, and the number of namespaces matched.
+func (s *Store) namespaceListByPipeline(ctx context.Context, pipeline []bson.M, paginator query.Paginator) ([]models.Namespace, int, error) {
+	queryCount := pipeline
+	queryCount = append(queryCount, bson.M{"$count": "count"})
+	count, err := AggregateCount(ctx, s.db.Collection("namespaces"), queryCount)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+
+	pipeline = append(pipeline, queries.FromPaginator(&paginator)...)
+
+	cursor, err := s.db.Collection("namespaces").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	namespaces := make([]models.Namespace, 0)
+	for cursor.Next(ctx) {
+		namespace := new(models.Namespace)
+		if err := cursor.Decode(namespace); err != nil {
+			return nil, 0, FromMongoError(err)
+		}
+
+		devices, err := s.db.Collection("devices").CountDocuments(ctx, bson.M{"tenant_id": namespace.TenantID, "status": "accepted"})
+		if err != nil {
+			return nil, 0, FromMongoError(err)
+		}
+
+		namespace.DevicesCount = int(devices)
+
+		namespaces = append(namespaces, *namespace)
+	}
+
+	return namespaces, count, nil
+}
+
 func (s *Store) NamespaceGet(ctx context.Context, tenantID string, countDevices bool) (*models.Namespace, error) {
 	var ns *models.Namespace
 
@@ -146,6 +231,15 @@ func (s *Store) NamespaceGet(ctx context.Context, tenantID string, countDevices
 	return ns, nil
 }
 
+// namespaceInvalidateCache deletes the namespace with tenantID cached by the store and by the SSH server.
+func (s *Store) namespaceInvalidateCache(ctx context.Context, tenantID string) error {
+	if err := s.cache.Delete(ctx, cache.NamespaceLookupKey(tenantID)); err != nil {
+		return err
+	}
+
+	return s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/"))
+}
+
 func (s *Store) NamespaceGetByName(ctx context.Context, name string) (*models.Namespace, error) {
 	var ns *models.Namespace
 
@@ -165,6 +259,12 @@ func (s *Store) NamespaceGetByName(ctx context.Context, name string) (*models.Na
 }
 
 func (s *Store) NamespaceCreate(ctx context.Context, namespace *models.Namespace) (*models.Namespace, error) {
+	// A new namespace is active since its creation, so the inactivity that expires it is counted from there.
+	if namespace.LastActiveAt == nil {
+		now := clock.Now()
+		namespace.LastActiveAt = &now
+	}
+
 	session, err := s.db.Client().StartSession()
 	if err != nil {
 		return nil, err
@@ -211,11 +311,11 @@ func (s *Store) NamespaceDelete(ctx context.Context, tenantID string) error {
 			return nil, FromMongoError(err)
 		}
 
-		if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+		if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
 			logrus.Error(err)
 		}
 
-		collections := []string{"devices", "sessions", "connected_devices", "firewall_rules", "public_keys", "recorded_sessions", "api_keys"}
+		collections := []string{"devices", "sessions", "connected_devices", "firewall_rules", "public_keys", "recorded_sessions", "api_keys", "webhook_dead_letters"}
 		for _, collection := range collections {
 			if _, err := s.db.Collection(collection).DeleteMany(sessCtx, bson.M{"tenant_id": tenantID}); err != nil {
 				return nil, FromMongoError(err)
@@ -240,9 +340,21 @@ func (s *Store) NamespaceDelete(ctx context.Context, tenantID string) error {
 }
 
 func (s *Store) NamespaceEdit(ctx context.Context, tenant string, changes *models.NamespaceChanges) error {
+	update := bson.M{"$set": changes}
+	// NOTICE: the previous name is pushed on the same update that renames the namespace, so the rename can't be kept
+	// without it.
+	if changes.PreviousName != nil {
+		update["$push"] = bson.M{
+			"previous_names": bson.M{
+				"$each":  []models.NamespacePreviousName{*changes.PreviousName},
+				"$slice": -models.NamespacePreviousNamesLimit,
+			},
+		}
+	}
+
 	res, err := s.db.
 		Collection("namespaces").
-		UpdateOne(ctx, bson.M{"tenant_id": tenant}, bson.M{"$set": changes})
+		UpdateOne(ctx, bson.M{"tenant_id": tenant}, update)
 	if err != nil {
 		return FromMongoError(err)
 	}
@@ -251,13 +363,74 @@ func (s *Store) NamespaceEdit(ctx context.Context, tenant string, changes *model
 		return store.ErrNoDocuments
 	}
 
-	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenant}, "/")); err != nil {
+	if err := s.namespaceInvalidateCache(ctx, tenant); err != nil {
 		logrus.Error(err)
 	}
 
 	return nil
 }
 
+func (s *Store) NamespaceGetByPreviousName(ctx context.Context, name string, since time.Time) (*models.Namespace, error) {
+	renamed := bson.M{
+		"$filter": bson.M{
+			"input": "$previous_names",
+			"cond": bson.M{
+				"$and": []bson.M{
+					{"$eq": []interface{}{"$$this.name", name}},
+					{"$gte": []interface{}{"$$this.changed_at", since}},
+				},
+			},
+		},
+	}
+
+	// NOTICE: as a name is free once its namespace is renamed, many namespaces may have used it during the period, so
+	// the one that used it last is chosen.
+	query := []bson.M{
+		{
+			"$match": bson.M{
+				"previous_names": bson.M{
+					"$elemMatch": bson.M{
+						"name":       name,
+						"changed_at": bson.M{"$gte": since},
+					},
+				},
+			},
+		},
+		{
+			"$addFields": bson.M{
+				"renamed_at": bson.M{"$max": bson.M{"$map": bson.M{"input": renamed, "in": "$$this.changed_at"}}},
+			},
+		},
+		{
+			"$sort": bson.D{{Key: "renamed_at", Value: -1}, {Key: "tenant_id", Value: 1}},
+		},
+		{
+			"$limit": 1,
+		},
+	}
+
+	cursor, err := s.db.Collection("namespaces").Aggregate(ctx, query)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		if err := cursor.Err(); err != nil {
+			return nil, FromMongoError(err)
+		}
+
+		return nil, store.ErrNoDocuments
+	}
+
+	ns := new(models.Namespace)
+	if err := cursor.Decode(ns); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return ns, nil
+}
+
 func (s *Store) NamespaceUpdate(ctx context.Context, tenantID string, namespace *models.Namespace) error {
 	ns, err := s.db.Collection("namespaces").UpdateOne(
 		ctx,
@@ -280,7 +453,7 @@ func (s *Store) NamespaceUpdate(ctx context.Context, tenantID string, namespace
 		return store.ErrNoDocuments
 	}
 
-	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
 		logrus.Error(err)
 	}
 
@@ -293,12 +466,12 @@ func (s *Store) NamespaceAddMember(ctx context.Context, tenantID string, memberI
 		return nil, ErrNamespaceDuplicatedMember
 	}
 
-	_, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, bson.M{"$addToSet": bson.M{"members": bson.M{"id": memberID, "role": memberRole}}})
+	_, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, bson.M{"$addToSet": bson.M{"members": bson.M{"id": memberID, "role": memberRole, "added_at": clock.Now()}}})
 	if err != nil {
 		return nil, FromMongoError(err)
 	}
 
-	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
 		logrus.Error(err)
 	}
 
@@ -320,7 +493,7 @@ func (s *Store) NamespaceRemoveMember(ctx context.Context, tenantID string, memb
 		return nil, ErrUserNotFound
 	}
 
-	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
 		logrus.Error(err)
 	}
 
@@ -337,13 +510,278 @@ func (s *Store) NamespaceEditMember(ctx context.Context, tenantID string, member
 		return ErrUserNotFound
 	}
 
-	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceSetMemberTags(ctx context.Context, tenantID, memberID string, tags []string) error {
+	res, err := s.db.
+		Collection("namespaces").
+		UpdateOne(ctx, bson.M{"tenant_id": tenantID, "members.id": memberID}, bson.M{"$set": bson.M{"members.$.tags": tags}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceSetMemberAccessSchedule(ctx context.Context, tenantID, memberID string, schedule *models.AccessSchedule) error {
+	update := bson.M{"$set": bson.M{"members.$.access_schedule": schedule}}
+	if schedule == nil {
+		update = bson.M{"$unset": bson.M{"members.$.access_schedule": ""}}
+	}
+
+	res, err := s.db.
+		Collection("namespaces").
+		UpdateOne(ctx, bson.M{"tenant_id": tenantID, "members.id": memberID}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceSetAPIRateLimit(ctx context.Context, tenantID string, limit *models.APIRateLimitConfig) error {
+	update := bson.M{"$set": bson.M{"api_rate_limit": limit}}
+	if limit == nil {
+		update = bson.M{"$unset": bson.M{"api_rate_limit": ""}}
+	}
+
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceSetWebhook(ctx context.Context, tenantID string, webhook *models.NamespaceWebhook) error {
+	update := bson.M{"$set": bson.M{"webhook": webhook}}
+	if webhook == nil {
+		update = bson.M{"$unset": bson.M{"webhook": ""}}
+	}
+
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
 		logrus.Error(err)
 	}
 
 	return nil
 }
 
+func (s *Store) NamespaceRenew(ctx context.Context, tenantID string, activeAt time.Time) error {
+	res, err := s.db.Collection("namespaces").UpdateOne(
+		ctx,
+		bson.M{"tenant_id": tenantID},
+		bson.M{"$set": bson.M{"last_active_at": activeAt}, "$unset": bson.M{"expires_at": ""}},
+	)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceRenewMany(ctx context.Context, tenantIDs []string, since, activeAt time.Time) error {
+	if len(tenantIDs) == 0 {
+		return nil
+	}
+
+	res, err := s.db.Collection("namespaces").UpdateMany(
+		ctx,
+		bson.M{
+			"tenant_id": bson.M{"$in": tenantIDs},
+			"$or": []bson.M{
+				{"last_active_at": bson.M{"$lt": since}},
+				{"last_active_at": nil},
+				{"expires_at": bson.M{"$ne": nil}},
+			},
+		},
+		bson.M{"$set": bson.M{"last_active_at": activeAt}, "$unset": bson.M{"expires_at": ""}},
+	)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.ModifiedCount > 0 {
+		for _, tenantID := range tenantIDs {
+			if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
+				logrus.Error(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceSetExpiresAt(ctx context.Context, tenantID string, expiresAt time.Time) error {
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, bson.M{"$set": bson.M{"expires_at": expiresAt}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceListInactive(ctx context.Context, before time.Time) ([]models.Namespace, error) {
+	return s.namespaceListFreeTier(ctx, bson.M{"last_active_at": bson.M{"$lt": before}, "expires_at": bson.M{"$exists": false}})
+}
+
+func (s *Store) NamespaceListExpired(ctx context.Context, before time.Time) ([]models.Namespace, error) {
+	return s.namespaceListFreeTier(ctx, bson.M{"expires_at": bson.M{"$lt": before}})
+}
+
+func (s *Store) NamespaceListUsageReports(ctx context.Context) ([]models.Namespace, error) {
+	cursor, err := s.db.Collection("namespaces").Find(ctx, bson.M{
+		"settings.usage_report_schedule": bson.M{"$nin": []interface{}{nil, ""}},
+		"settings.usage_report_email":    bson.M{"$nin": []interface{}{nil, ""}},
+	})
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	namespaces := make([]models.Namespace, 0)
+	if err := cursor.All(ctx, &namespaces); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return namespaces, nil
+}
+
+func (s *Store) NamespaceSetUsageReportSentAt(ctx context.Context, tenantID string, sentAt time.Time) error {
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, bson.M{"$set": bson.M{"usage_report_sent_at": sentAt}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.namespaceInvalidateCache(ctx, tenantID); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+// namespaceListFreeTier lists the namespaces without an active subscription matching filter.
+func (s *Store) namespaceListFreeTier(ctx context.Context, filter bson.M) ([]models.Namespace, error) {
+	filter["billing.active"] = bson.M{"$ne": true}
+
+	cursor, err := s.db.Collection("namespaces").Find(ctx, filter)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	namespaces := make([]models.Namespace, 0)
+	if err := cursor.All(ctx, &namespaces); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return namespaces, nil
+}
+
+func (s *Store) NamespaceListMembers(ctx context.Context, tenantID, tag string) ([]models.Member, error) {
+	match := bson.M{"tenant_id": tenantID}
+	if tag != "" {
+		match["members"] = bson.M{"$elemMatch": bson.M{"tags": tag}}
+	}
+
+	query := []bson.M{
+		{"$match": match},
+		{"$unwind": "$members"},
+	}
+
+	if tag != "" {
+		query = append(query, bson.M{"$match": bson.M{"members.tags": tag}})
+	}
+
+	query = append(query, bson.M{"$replaceRoot": bson.M{"newRoot": "$members"}})
+
+	cursor, err := s.db.Collection("namespaces").Aggregate(ctx, query)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	members := make([]models.Member, 0)
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return members, nil
+}
+
+func (s *Store) NamespaceListMemberTags(ctx context.Context, tenantID string) ([]string, error) {
+	list, err := s.db.Collection("namespaces").Distinct(ctx, "members.tags", bson.M{"tenant_id": tenantID})
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	tags := make([]string, len(list))
+	for i, item := range list {
+		tags[i] = item.(string) //nolint:forcetypeassert
+	}
+
+	sort.Strings(tags)
+
+	return tags, nil
+}
+
 func (s *Store) NamespaceGetFirst(ctx context.Context, id string) (*models.Namespace, error) {
 	ns := new(models.Namespace)
 	if err := s.db.Collection("namespaces").FindOne(ctx, bson.M{"members": bson.M{"$elemMatch": bson.M{"id": id}}}).Decode(&ns); err != nil {
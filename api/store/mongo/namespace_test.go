@@ -2,6 +2,7 @@ package mongo_test
 
 import (
 	"context"
+	"fmt"
 	"sort"
 	"testing"
 	"time"
@@ -10,8 +11,12 @@ import (
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mongo"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmocks "github.com/shellhub-io/shellhub/pkg/clock/mocks"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestNamespaceList(t *testing.T) {
@@ -125,7 +130,7 @@ func TestNamespaceList(t *testing.T) {
 				assert.NoError(t, srv.Reset())
 			})
 
-			ns, count, err := s.NamespaceList(ctx, tc.page, tc.filters, tc.export)
+			ns, count, err := s.NamespaceList(ctx, tc.page, tc.filters, "", tc.export)
 			sort(tc.expected.ns)
 			sort(ns)
 			assert.Equal(t, tc.expected, Expected{ns: ns, count: count, err: err})
@@ -133,6 +138,153 @@ func TestNamespaceList(t *testing.T) {
 	}
 }
 
+func TestNamespaceListByMember(t *testing.T) {
+	type Expected struct {
+		tenants []string
+		count   int
+		err     error
+	}
+
+	cases := []struct {
+		description string
+		userID      string
+		role        string
+		page        query.Paginator
+		fixtures    []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds with an empty list when user is not a member of any namespace",
+			userID:      "000000000000000000000000",
+			page:        query.Paginator{Page: -1, PerPage: -1},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    Expected{tenants: []string{}, count: 0, err: nil},
+		},
+		{
+			description: "succeeds listing only the namespaces where user is a member",
+			userID:      "6509e169ae6144b2f56bf288",
+			page:        query.Paginator{Page: -1, PerPage: -1},
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				tenants: []string{"00000000-0000-4000-0000-000000000000", "00000000-0000-4001-0000-000000000000"},
+				count:   2,
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds listing only the namespaces where user has the role",
+			userID:      "6509e169ae6144b2f56bf288",
+			role:        guard.RoleOwner,
+			page:        query.Paginator{Page: -1, PerPage: -1},
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				tenants: []string{"00000000-0000-4001-0000-000000000000"},
+				count:   1,
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds with an empty list when user does not have the role in any namespace",
+			userID:      "6509e169ae6144b2f56bf288",
+			role:        guard.RoleAdministrator,
+			page:        query.Paginator{Page: -1, PerPage: -1},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    Expected{tenants: []string{}, count: 0, err: nil},
+		},
+		{
+			description: "succeeds counting all namespaces when paginated",
+			userID:      "6509e169ae6144b2f56bf288",
+			page:        query.Paginator{Page: 2, PerPage: 2},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    Expected{tenants: []string{}, count: 2, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			ns, count, err := s.NamespaceListByMember(ctx, tc.userID, tc.role, tc.page, query.Filters{})
+
+			tenants := make([]string, 0, len(ns))
+			for _, n := range ns {
+				tenants = append(tenants, n.TenantID)
+			}
+			sort.Strings(tenants)
+
+			assert.Equal(t, tc.expected, Expected{tenants: tenants, count: count, err: err})
+		})
+	}
+}
+
+func TestNamespaceListByOwner(t *testing.T) {
+	type Expected struct {
+		tenants []string
+		count   int
+		err     error
+	}
+
+	cases := []struct {
+		description string
+		ownerID     string
+		page        query.Paginator
+		fixtures    []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds with an empty list when user does not own any namespace",
+			ownerID:     "000000000000000000000000",
+			page:        query.Paginator{Page: -1, PerPage: -1},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    Expected{tenants: []string{}, count: 0, err: nil},
+		},
+		{
+			description: "succeeds listing only the namespaces owned by the user",
+			ownerID:     "6509e169ae6144b2f56bf288",
+			page:        query.Paginator{Page: -1, PerPage: -1},
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				tenants: []string{"00000000-0000-4001-0000-000000000000"},
+				count:   1,
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds counting all namespaces when paginated",
+			ownerID:     "6509e169ae6144b2f56bf288",
+			page:        query.Paginator{Page: 2, PerPage: 1},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    Expected{tenants: []string{}, count: 1, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			ns, count, err := s.NamespaceListByOwner(ctx, tc.ownerID, tc.page)
+
+			tenants := make([]string, 0, len(ns))
+			for _, n := range ns {
+				tenants = append(tenants, n.TenantID)
+			}
+			sort.Strings(tenants)
+
+			assert.Equal(t, tc.expected, Expected{tenants: tenants, count: count, err: err})
+		})
+	}
+}
+
 func TestNamespaceGet(t *testing.T) {
 	type Expected struct {
 		ns  *models.Namespace
@@ -363,6 +515,16 @@ func TestNamespaceCreate(t *testing.T) {
 		err error
 	}
 
+	backend := clock.DefaultBackend
+	t.Cleanup(func() {
+		clock.DefaultBackend = backend
+	})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clockMock := new(clockmocks.Clock)
+	clockMock.On("Now").Return(now)
+	clock.DefaultBackend = clockMock
+
 	cases := []struct {
 		description string
 		ns          *models.Namespace
@@ -396,8 +558,9 @@ func TestNamespaceCreate(t *testing.T) {
 							Role: guard.RoleOwner,
 						},
 					},
-					MaxDevices: -1,
-					Settings:   &models.NamespaceSettings{SessionRecord: true},
+					MaxDevices:   -1,
+					Settings:     &models.NamespaceSettings{SessionRecord: true},
+					LastActiveAt: &now,
 				},
 				err: nil,
 			},
@@ -462,6 +625,169 @@ func TestNamespaceEdit(t *testing.T) {
 	}
 }
 
+func TestNamespaceEditPreviousName(t *testing.T) {
+	cases := []struct {
+		description string
+		tenant      string
+		renames     int
+		fixtures    []string
+		expected    error
+	}{
+		{
+			description: "fails when tenant is not found",
+			tenant:      "nonexistent",
+			renames:     1,
+			fixtures:    []string{fixtureNamespaces},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds when tenant is found",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			renames:     1,
+			fixtures:    []string{fixtureNamespaces},
+			expected:    nil,
+		},
+		{
+			description: "succeeds keeping only the newest names",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			renames:     models.NamespacePreviousNamesLimit + 2,
+			fixtures:    []string{fixtureNamespaces},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			changedAt := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+			var err error
+			for i := 0; i < tc.renames; i++ {
+				changes := &models.NamespaceChanges{
+					Name:         fmt.Sprintf("name-%d", i+1),
+					PreviousName: &models.NamespacePreviousName{Name: fmt.Sprintf("name-%d", i), ChangedAt: changedAt.Add(time.Duration(i) * time.Hour)},
+				}
+
+				if err = s.NamespaceEdit(ctx, tc.tenant, changes); err != nil {
+					break
+				}
+			}
+
+			assert.Equal(t, tc.expected, err)
+			if err != nil {
+				return
+			}
+
+			ns, err := s.NamespaceGet(ctx, tc.tenant, false)
+			assert.NoError(t, err)
+
+			expected := tc.renames
+			if expected > models.NamespacePreviousNamesLimit {
+				expected = models.NamespacePreviousNamesLimit
+			}
+
+			assert.Equal(t, fmt.Sprintf("name-%d", tc.renames), ns.Name)
+			assert.Len(t, ns.PreviousNames, expected)
+			assert.Equal(t, fmt.Sprintf("name-%d", tc.renames-1), ns.PreviousNames[len(ns.PreviousNames)-1].Name)
+		})
+	}
+}
+
+func TestNamespaceGetByPreviousName(t *testing.T) {
+	changedAt := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type Expected struct {
+		tenant string
+		err    error
+	}
+
+	cases := []struct {
+		description string
+		name        string
+		since       time.Time
+		fixtures    []string
+		// renamed holds, by tenant, when other namespaces were renamed from the name too.
+		renamed  map[string]time.Time
+		expected Expected
+	}{
+		{
+			description: "fails when no namespace used the name",
+			name:        "nonexistent",
+			since:       changedAt.Add(-time.Hour),
+			fixtures:    []string{fixtureNamespaces},
+			expected:    Expected{tenant: "", err: store.ErrNoDocuments},
+		},
+		{
+			description: "fails when the name was changed before since",
+			name:        "old-namespace",
+			since:       changedAt.Add(time.Hour),
+			fixtures:    []string{fixtureNamespaces},
+			expected:    Expected{tenant: "", err: store.ErrNoDocuments},
+		},
+		{
+			description: "succeeds when the name was changed after since",
+			name:        "old-namespace",
+			since:       changedAt.Add(-time.Hour),
+			fixtures:    []string{fixtureNamespaces},
+			expected:    Expected{tenant: "00000000-0000-4000-0000-000000000000", err: nil},
+		},
+		{
+			description: "succeeds with the namespace renamed last when many used the name",
+			name:        "old-namespace",
+			since:       changedAt.Add(-time.Hour),
+			fixtures:    []string{fixtureNamespaces},
+			renamed:     map[string]time.Time{"00000000-0000-4001-0000-000000000000": changedAt.Add(30 * time.Minute)},
+			expected:    Expected{tenant: "00000000-0000-4001-0000-000000000000", err: nil},
+		},
+		{
+			description: "succeeds ignoring the namespaces renamed before since when many used the name",
+			name:        "old-namespace",
+			since:       changedAt.Add(-time.Hour),
+			fixtures:    []string{fixtureNamespaces},
+			renamed:     map[string]time.Time{"00000000-0000-4001-0000-000000000000": changedAt.Add(-2 * time.Hour)},
+			expected:    Expected{tenant: "00000000-0000-4000-0000-000000000000", err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			rename := func(tenant string, at time.Time) error {
+				return s.NamespaceEdit(ctx, tenant, &models.NamespaceChanges{
+					Name:         "renamed-" + tenant,
+					PreviousName: &models.NamespacePreviousName{Name: "old-namespace", ChangedAt: at},
+				})
+			}
+
+			assert.NoError(t, rename("00000000-0000-4000-0000-000000000000", changedAt))
+			for tenant, at := range tc.renamed {
+				assert.NoError(t, rename(tenant, at))
+			}
+
+			ns, err := s.NamespaceGetByPreviousName(ctx, tc.name, tc.since)
+
+			var tenant string
+			if ns != nil {
+				tenant = ns.TenantID
+			}
+
+			assert.Equal(t, tc.expected, Expected{tenant: tenant, err: err})
+		})
+	}
+}
+
 func TestNamespaceUpdate(t *testing.T) {
 	cases := []struct {
 		description string
@@ -626,6 +952,16 @@ func TestNamespaceAddMember(t *testing.T) {
 			})
 
 			ns, err := s.NamespaceAddMember(ctx, tc.tenant, tc.member, tc.role)
+			if ns != nil {
+				// NOTICE: the date the member was added is set by the store, so it's only checked to be set.
+				for i := range ns.Members {
+					if ns.Members[i].ID == tc.member {
+						assert.NotNil(t, ns.Members[i].AddedAt)
+						ns.Members[i].AddedAt = nil
+					}
+				}
+			}
+
 			assert.Equal(t, tc.expected, Expected{ns: ns, err: err})
 		})
 	}
@@ -673,6 +1009,469 @@ func TestNamespaceEditMember(t *testing.T) {
 	}
 }
 
+func TestNamespaceSetMemberTags(t *testing.T) {
+	cases := []struct {
+		description string
+		tenant      string
+		member      string
+		tags        []string
+		fixtures    []string
+		expected    error
+	}{
+		{
+			description: "fails when tenant is not found",
+			tenant:      "nonexistent",
+			member:      "6509e169ae6144b2f56bf288",
+			tags:        []string{"team:infra"},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "fails when member is not found",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			member:      "nonexistent",
+			tags:        []string{"team:infra"},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds when tenant and member are found",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			member:      "6509e169ae6144b2f56bf288",
+			tags:        []string{"team:infra", "region:eu"},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			err := s.NamespaceSetMemberTags(ctx, tc.tenant, tc.member, tc.tags)
+			assert.Equal(t, tc.expected, err)
+
+			if err == nil {
+				ns, err := s.NamespaceGet(ctx, tc.tenant, false)
+				assert.NoError(t, err)
+
+				member, ok := ns.FindMember(tc.member)
+				assert.True(t, ok)
+				assert.Equal(t, tc.tags, member.Tags)
+			}
+		})
+	}
+}
+
+func TestNamespaceSetAPIRateLimit(t *testing.T) {
+	cases := []struct {
+		description string
+		tenant      string
+		limit       *models.APIRateLimitConfig
+		fixtures    []string
+		expected    error
+	}{
+		{
+			description: "fails when tenant is not found",
+			tenant:      "nonexistent",
+			limit:       &models.APIRateLimitConfig{RequestsPerMinute: 60, BurstSize: 10},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds setting the rate limit",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			limit:       &models.APIRateLimitConfig{RequestsPerMinute: 60, BurstSize: 10},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    nil,
+		},
+		{
+			description: "succeeds removing the rate limit",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			limit:       nil,
+			fixtures:    []string{fixtureNamespaces},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			err := s.NamespaceSetAPIRateLimit(ctx, tc.tenant, tc.limit)
+			assert.Equal(t, tc.expected, err)
+
+			if err == nil {
+				ns, err := s.NamespaceGet(ctx, tc.tenant, false)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.limit, ns.APIRateLimit)
+			}
+		})
+	}
+}
+
+func TestNamespaceSetWebhook(t *testing.T) {
+	cases := []struct {
+		description string
+		tenant      string
+		webhook     *models.NamespaceWebhook
+		fixtures    []string
+		expected    error
+	}{
+		{
+			description: "fails when tenant is not found",
+			tenant:      "nonexistent",
+			webhook:     &models.NamespaceWebhook{URL: "https://example.com/hook", Secret: "secret", Events: []models.WebhookEventType{models.WebhookEventDeviceAccepted}},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds setting the webhook",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			webhook:     &models.NamespaceWebhook{URL: "https://example.com/hook", Secret: "secret", Events: []models.WebhookEventType{models.WebhookEventDeviceAccepted}},
+			fixtures:    []string{fixtureNamespaces},
+			expected:    nil,
+		},
+		{
+			description: "succeeds removing the webhook",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			webhook:     nil,
+			fixtures:    []string{fixtureNamespaces},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			err := s.NamespaceSetWebhook(ctx, tc.tenant, tc.webhook)
+			assert.Equal(t, tc.expected, err)
+
+			if err == nil {
+				ns, err := s.NamespaceGet(ctx, tc.tenant, false)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.webhook, ns.Webhook)
+			}
+		})
+	}
+}
+
+func TestNamespaceRenew(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	_, err := db.Collection("namespaces").InsertOne(ctx, bson.M{
+		"tenant_id":      "00000000-0000-4000-0000-000000000000",
+		"last_active_at": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"expires_at":     time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, store.ErrNoDocuments, s.NamespaceRenew(ctx, "nonexistent", time.Now()))
+
+	activeAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, s.NamespaceRenew(ctx, "00000000-0000-4000-0000-000000000000", activeAt))
+
+	ns, err := s.NamespaceGet(ctx, "00000000-0000-4000-0000-000000000000", false)
+	require.NoError(t, err)
+	assert.Equal(t, &activeAt, ns.LastActiveAt)
+	assert.Nil(t, ns.ExpiresAt)
+}
+
+func TestNamespaceRenewMany(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := db.Collection("namespaces").InsertMany(ctx, []interface{}{
+		bson.M{"tenant_id": "inactive", "last_active_at": old},
+		bson.M{"tenant_id": "active", "last_active_at": recent},
+		bson.M{"tenant_id": "expiring", "last_active_at": recent, "expires_at": recent.AddDate(0, 0, 90)},
+		bson.M{"tenant_id": "other", "last_active_at": old},
+	})
+	require.NoError(t, err)
+
+	activeAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, s.NamespaceRenewMany(ctx, []string{"inactive", "active", "expiring"}, activeAt.Add(-time.Hour), activeAt))
+
+	expected := map[string]*time.Time{"inactive": &activeAt, "active": &recent, "expiring": &activeAt, "other": &old}
+	for tenant, lastActiveAt := range expected {
+		ns, err := s.NamespaceGet(ctx, tenant, false)
+		require.NoError(t, err)
+		assert.Equal(t, lastActiveAt, ns.LastActiveAt, tenant)
+		assert.Nil(t, ns.ExpiresAt, tenant)
+	}
+}
+
+func TestNamespaceSetExpiresAt(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, srv.Apply(fixtureNamespaces))
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	expiresAt := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, store.ErrNoDocuments, s.NamespaceSetExpiresAt(ctx, "nonexistent", expiresAt))
+	require.NoError(t, s.NamespaceSetExpiresAt(ctx, "00000000-0000-4000-0000-000000000000", expiresAt))
+
+	ns, err := s.NamespaceGet(ctx, "00000000-0000-4000-0000-000000000000", false)
+	require.NoError(t, err)
+	assert.Equal(t, &expiresAt, ns.ExpiresAt)
+}
+
+func TestNamespaceListInactiveAndExpired(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := db.Collection("namespaces").InsertMany(ctx, []interface{}{
+		bson.M{"tenant_id": "inactive", "last_active_at": old},
+		bson.M{"tenant_id": "active", "last_active_at": recent},
+		bson.M{"tenant_id": "subscribed", "last_active_at": old, "billing": bson.M{"active": true}},
+		bson.M{"tenant_id": "warned", "last_active_at": old, "expires_at": old.AddDate(0, 0, 90)},
+		bson.M{"tenant_id": "expiring", "last_active_at": recent, "expires_at": recent.AddDate(0, 0, 90)},
+	})
+	require.NoError(t, err)
+
+	tenants := func(namespaces []models.Namespace) []string {
+		list := make([]string, 0, len(namespaces))
+		for _, ns := range namespaces {
+			list = append(list, ns.TenantID)
+		}
+
+		sort.Strings(list)
+
+		return list
+	}
+
+	inactive, err := s.NamespaceListInactive(ctx, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"inactive"}, tenants(inactive))
+
+	expired, err := s.NamespaceListExpired(ctx, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"warned"}, tenants(expired))
+}
+
+func TestNamespaceListUsageReports(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	_, err := db.Collection("namespaces").InsertMany(ctx, []interface{}{
+		bson.M{"tenant_id": "reported", "settings": bson.M{"usage_report_schedule": "@weekly", "usage_report_email": "owner@shellhub.io"}},
+		bson.M{"tenant_id": "unscheduled", "settings": bson.M{"usage_report_email": "owner@shellhub.io"}},
+		bson.M{"tenant_id": "unaddressed", "settings": bson.M{"usage_report_schedule": "@weekly", "usage_report_email": ""}},
+		bson.M{"tenant_id": "unset"},
+	})
+	require.NoError(t, err)
+
+	namespaces, err := s.NamespaceListUsageReports(ctx)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+	assert.Equal(t, "reported", namespaces[0].TenantID)
+}
+
+func TestNamespaceSetUsageReportSentAt(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, srv.Apply(fixtureNamespaces))
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	sentAt := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, store.ErrNoDocuments, s.NamespaceSetUsageReportSentAt(ctx, "nonexistent", sentAt))
+	require.NoError(t, s.NamespaceSetUsageReportSentAt(ctx, "00000000-0000-4000-0000-000000000000", sentAt))
+
+	ns, err := s.NamespaceGet(ctx, "00000000-0000-4000-0000-000000000000", false)
+	require.NoError(t, err)
+	assert.Equal(t, &sentAt, ns.UsageReportSentAt)
+}
+
+func TestNamespaceListMembers(t *testing.T) {
+	type Expected struct {
+		members []models.Member
+		err     error
+	}
+
+	cases := []struct {
+		description string
+		tenant      string
+		tag         string
+		fixtures    []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds listing no members when tenant is not found",
+			tenant:      "nonexistent",
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				members: []models.Member{},
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds listing every member without a tag",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				members: []models.Member{
+					{ID: "507f1f77bcf86cd799439011", Role: guard.RoleOwner, Tags: []string{"team:infra", "region:eu"}},
+					{ID: "6509e169ae6144b2f56bf288", Role: guard.RoleObserver, Tags: []string{"team:infra", "region:us"}},
+				},
+				err: nil,
+			},
+		},
+		{
+			description: "succeeds listing the members with a tag shared by them",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			tag:         "team:infra",
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				members: []models.Member{
+					{ID: "507f1f77bcf86cd799439011", Role: guard.RoleOwner, Tags: []string{"team:infra", "region:eu"}},
+					{ID: "6509e169ae6144b2f56bf288", Role: guard.RoleObserver, Tags: []string{"team:infra", "region:us"}},
+				},
+				err: nil,
+			},
+		},
+		{
+			description: "succeeds listing only the members with the tag",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			tag:         "region:eu",
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				members: []models.Member{
+					{ID: "507f1f77bcf86cd799439011", Role: guard.RoleOwner, Tags: []string{"team:infra", "region:eu"}},
+				},
+				err: nil,
+			},
+		},
+		{
+			description: "succeeds listing no members when none has the tag",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			tag:         "region:ap",
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				members: []models.Member{},
+				err:     nil,
+			},
+		},
+		{
+			description: "succeeds listing no members when the tag belongs to another namespace",
+			tenant:      "00000000-0000-4001-0000-000000000000",
+			tag:         "team:infra",
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				members: []models.Member{},
+				err:     nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			assert.NoError(t, s.NamespaceSetMemberTags(ctx, "00000000-0000-4000-0000-000000000000", "507f1f77bcf86cd799439011", []string{"team:infra", "region:eu"}))
+			assert.NoError(t, s.NamespaceSetMemberTags(ctx, "00000000-0000-4000-0000-000000000000", "6509e169ae6144b2f56bf288", []string{"team:infra", "region:us"}))
+
+			members, err := s.NamespaceListMembers(ctx, tc.tenant, tc.tag)
+			assert.Equal(t, tc.expected, Expected{members: members, err: err})
+		})
+	}
+}
+
+func TestNamespaceListMemberTags(t *testing.T) {
+	type Expected struct {
+		tags []string
+		err  error
+	}
+
+	cases := []struct {
+		description string
+		tenant      string
+		fixtures    []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds listing no tags when the members have none",
+			tenant:      "00000000-0000-4001-0000-000000000000",
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				tags: []string{},
+				err:  nil,
+			},
+		},
+		{
+			description: "succeeds listing the unique tags sorted",
+			tenant:      "00000000-0000-4000-0000-000000000000",
+			fixtures:    []string{fixtureNamespaces},
+			expected: Expected{
+				tags: []string{"region:eu", "region:us", "team:infra"},
+				err:  nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			assert.NoError(t, s.NamespaceSetMemberTags(ctx, "00000000-0000-4000-0000-000000000000", "507f1f77bcf86cd799439011", []string{"team:infra", "region:eu"}))
+			assert.NoError(t, s.NamespaceSetMemberTags(ctx, "00000000-0000-4000-0000-000000000000", "6509e169ae6144b2f56bf288", []string{"team:infra", "region:us"}))
+
+			tags, err := s.NamespaceListMemberTags(ctx, tc.tenant)
+			assert.Equal(t, tc.expected, Expected{tags: tags, err: err})
+		})
+	}
+}
+
 func TestNamespaceRemoveMember(t *testing.T) {
 	type Expected struct {
 		ns  *models.Namespace
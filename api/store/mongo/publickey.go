@@ -7,7 +7,10 @@ import (
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mongo/queries"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/uuid"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -83,6 +86,10 @@ func (s *Store) PublicKeyUpdate(ctx context.Context, fingerprint string, tenantI
 		return nil, FromMongoError(err)
 	}
 
+	if err := s.PublicKeyInvalidateEvalCache(ctx, fingerprint, tenantID); err != nil {
+		logrus.Error(err)
+	}
+
 	return pubKey, nil
 }
 
@@ -96,5 +103,19 @@ func (s *Store) PublicKeyDelete(ctx context.Context, fingerprint string, tenantI
 		return store.ErrNoDocuments
 	}
 
+	if err := s.PublicKeyInvalidateEvalCache(ctx, fingerprint, tenantID); err != nil {
+		logrus.Error(err)
+	}
+
 	return nil
 }
+
+func (s *Store) PublicKeyInvalidateEvalCache(ctx context.Context, fingerprint, tenantID string) error {
+	// NOTICE: the version is changed before the evaluations are deleted, so an evaluation made concurrently, with the
+	// public key as it was before, is discarded by the SSH server instead of cached.
+	if err := s.cache.Set(ctx, cache.PublicKeyEvalVersionKey(fingerprint, tenantID), uuid.Generate(), cache.PublicKeyEvalTTL); err != nil {
+		return err
+	}
+
+	return s.cache.DeletePrefix(ctx, cache.PublicKeyEvalPrefix(fingerprint, tenantID))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 )
 
@@ -17,6 +18,10 @@ func (s *Store) PublicKeyPushTag(ctx context.Context, tenant, fingerprint, tag s
 		return store.ErrNoDocuments
 	}
 
+	if err := s.PublicKeyInvalidateEvalCache(ctx, fingerprint, tenant); err != nil {
+		logrus.Error(err)
+	}
+
 	return nil
 }
 
@@ -30,25 +35,68 @@ func (s *Store) PublicKeyPullTag(ctx context.Context, tenant, fingerprint, tag s
 		return store.ErrNoDocuments
 	}
 
+	if err := s.PublicKeyInvalidateEvalCache(ctx, fingerprint, tenant); err != nil {
+		logrus.Error(err)
+	}
+
 	return nil
 }
 
 func (s *Store) PublicKeySetTags(ctx context.Context, tenant, fingerprint string, tags []string) (int64, int64, error) {
 	res, err := s.db.Collection("public_keys").UpdateOne(ctx, bson.M{"tenant_id": tenant, "fingerprint": fingerprint}, bson.M{"$set": bson.M{"filter.tags": tags}})
+	if err != nil {
+		return 0, 0, FromMongoError(err)
+	}
 
-	return res.MatchedCount, res.ModifiedCount, FromMongoError(err)
+	if res.ModifiedCount > 0 {
+		if err := s.PublicKeyInvalidateEvalCache(ctx, fingerprint, tenant); err != nil {
+			logrus.Error(err)
+		}
+	}
+
+	return res.MatchedCount, res.ModifiedCount, nil
 }
 
 func (s *Store) PublicKeyBulkRenameTag(ctx context.Context, tenant, currentTag, newTag string) (int64, error) {
+	fingerprints, err := s.db.Collection("public_keys").Distinct(ctx, "fingerprint", bson.M{"tenant_id": tenant, "filter.tags": currentTag})
+	if err != nil {
+		return 0, FromMongoError(err)
+	}
+
 	res, err := s.db.Collection("public_keys").UpdateMany(ctx, bson.M{"tenant_id": tenant, "filter.tags": currentTag}, bson.M{"$set": bson.M{"filter.tags.$": newTag}})
+	if err != nil {
+		return 0, FromMongoError(err)
+	}
+
+	s.invalidatePublicKeysEvalCache(ctx, tenant, fingerprints)
 
-	return res.ModifiedCount, FromMongoError(err)
+	return res.ModifiedCount, nil
 }
 
 func (s *Store) PublicKeyBulkDeleteTag(ctx context.Context, tenant, tag string) (int64, error) {
+	fingerprints, err := s.db.Collection("public_keys").Distinct(ctx, "fingerprint", bson.M{"tenant_id": tenant, "filter.tags": tag})
+	if err != nil {
+		return 0, FromMongoError(err)
+	}
+
 	res, err := s.db.Collection("public_keys").UpdateMany(ctx, bson.M{"tenant_id": tenant}, bson.M{"$pull": bson.M{"filter.tags": tag}})
+	if err != nil {
+		return 0, FromMongoError(err)
+	}
+
+	s.invalidatePublicKeysEvalCache(ctx, tenant, fingerprints)
 
-	return res.ModifiedCount, FromMongoError(err)
+	return res.ModifiedCount, nil
+}
+
+// invalidatePublicKeysEvalCache invalidates the evaluations cached for the public keys of tenant with fingerprints,
+// returned by a distinct query.
+func (s *Store) invalidatePublicKeysEvalCache(ctx context.Context, tenant string, fingerprints []interface{}) {
+	for _, fingerprint := range fingerprints {
+		if err := s.PublicKeyInvalidateEvalCache(ctx, fingerprint.(string), tenant); err != nil { //nolint:forcetypeassert
+			logrus.Error(err)
+		}
+	}
 }
 
 func (s *Store) PublicKeyGetTags(ctx context.Context, tenant string) ([]string, int, error) {
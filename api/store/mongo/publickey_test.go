@@ -6,9 +6,14 @@ import (
 	"time"
 
 	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mongo"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	mockcache "github.com/shellhub-io/shellhub/pkg/cache/mocks"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPublicKeyGet(t *testing.T) {
@@ -318,3 +323,30 @@ func TestPublicKeyDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestPublicKeyInvalidateEvalCache(t *testing.T) {
+	ctx := context.Background()
+
+	cacheMock := new(mockcache.Cache)
+
+	st, err := mongo.NewStore(ctx, db, cacheMock)
+	require.NoError(t, err)
+
+	assert.NoError(t, srv.Apply(fixturePublicKeys))
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	cacheMock.
+		On("Set", ctx, cache.PublicKeyEvalVersionKey("fingerprint", "00000000-0000-4000-0000-000000000000"), testifymock.AnythingOfType("string"), cache.PublicKeyEvalTTL).
+		Return(nil).
+		Once()
+	cacheMock.
+		On("DeletePrefix", ctx, cache.PublicKeyEvalPrefix("fingerprint", "00000000-0000-4000-0000-000000000000")).
+		Return(nil).
+		Once()
+
+	assert.NoError(t, st.PublicKeyDelete(ctx, "fingerprint", "00000000-0000-4000-0000-000000000000"))
+
+	cacheMock.AssertExpectations(t)
+}
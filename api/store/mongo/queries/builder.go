@@ -50,6 +50,7 @@ func FromFilters(fs *query.Filters) ([]bson.M, error) {
 
 	queryFilter := make([]bson.M, 0)
 	queryMatcher := make([]bson.M, 0)
+	queryOwner := make([]bson.M, 0)
 
 	for _, filter := range fs.Data {
 		switch filter.Type {
@@ -85,6 +86,15 @@ func FromFilters(fs *query.Filters) ([]bson.M, error) {
 			})
 
 			queryFilter = nil
+		case query.FilterTypeOwnerID:
+			param, ok := filter.Params.(*query.FilterOwnerID)
+			if !ok || param.ID == "" {
+				return nil, query.ErrFilterInvalid
+			}
+
+			queryOwner = append(queryOwner, bson.M{
+				"$match": bson.M{"owner": param.ID},
+			})
 		default:
 			return nil, query.ErrFilterInvalid
 		}
@@ -98,5 +108,5 @@ func FromFilters(fs *query.Filters) ([]bson.M, error) {
 		}
 	}
 
-	return queryMatcher, nil
+	return append(queryOwner, queryMatcher...), nil
 }
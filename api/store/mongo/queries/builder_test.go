@@ -313,6 +313,59 @@ func TestFromFilters(t *testing.T) {
 				err:  nil,
 			},
 		},
+		{
+			description: "Fail when owner ID is empty",
+			filters: &query.Filters{
+				Data: []query.Filter{
+					{
+						Type:   "owner_id",
+						Params: &query.FilterOwnerID{ID: ""},
+					},
+				},
+			},
+			expected: Expected{nil, query.ErrFilterInvalid},
+		},
+		{
+			description: "Success when owner ID is valid",
+			filters: &query.Filters{
+				Data: []query.Filter{
+					{
+						Type:   "owner_id",
+						Params: &query.FilterOwnerID{ID: "507f1f77bcf86cd799439011"},
+					},
+				},
+			},
+			expected: Expected{
+				data: []bson.M{{"$match": bson.M{"owner": "507f1f77bcf86cd799439011"}}},
+				err:  nil,
+			},
+		},
+		{
+			description: "Success when owner ID is combined with a property",
+			filters: &query.Filters{
+				Data: []query.Filter{
+					{
+						Type: "property",
+						Params: &query.FilterProperty{
+							Name:     "name",
+							Operator: "eq",
+							Value:    "test",
+						},
+					},
+					{
+						Type:   "owner_id",
+						Params: &query.FilterOwnerID{ID: "507f1f77bcf86cd799439011"},
+					},
+				},
+			},
+			expected: Expected{
+				data: []bson.M{
+					{"$match": bson.M{"owner": "507f1f77bcf86cd799439011"}},
+					{"$match": bson.M{"$or": []bson.M{{"name": bson.M{"$eq": "test"}}}}},
+				},
+				err: nil,
+			},
+		},
 	}
 
 	for _, tc := range cases {
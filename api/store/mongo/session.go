@@ -10,12 +10,13 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func (s *Store) SessionList(ctx context.Context, paginator query.Paginator) ([]models.Session, int, error) {
+func (s *Store) SessionList(ctx context.Context, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.Session, int, error) {
 	query := []bson.M{
 		{
 			"$match": bson.M{
@@ -35,19 +36,24 @@ func (s *Store) SessionList(ctx context.Context, paginator query.Paginator) ([]m
 		})
 	}
 
+	queryMatch, err := queries.FromFilters(&filters)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+	query = append(query, queryMatch...)
+
 	queryCount := query
 	queryCount = append(queryCount, bson.M{"$count": "count"})
-	count, err := AggregateCount(ctx, s.db.Collection("sessions"), queryCount)
+	count, err := AggregateCount(ctx, s.db.Collection("sessions"), queryCount, s.hints.AggregateOptions("sessions"))
 	if err != nil {
 		return nil, 0, FromMongoError(err)
 	}
 
-	query = append(query, bson.M{
-		"$sort": bson.M{
-			"started_at": -1,
-		},
-	})
+	if sorter.By == "" {
+		sorter.By = "started_at"
+	}
 
+	query = append(query, queries.FromSorter(&sorter)...)
 	query = append(query, queries.FromPaginator(&paginator)...)
 	query = append(query, []bson.M{
 		{
@@ -66,7 +72,7 @@ func (s *Store) SessionList(ctx context.Context, paginator query.Paginator) ([]m
 	}...)
 
 	sessions := make([]models.Session, 0)
-	cursor, err := s.db.Collection("sessions").Aggregate(ctx, query)
+	cursor, err := s.db.Collection("sessions").Aggregate(ctx, query, s.hints.AggregateOptions("sessions"))
 	if err != nil {
 		return sessions, count, FromMongoError(err)
 	}
@@ -145,13 +151,22 @@ func (s *Store) SessionGet(ctx context.Context, uid models.UID) (*models.Session
 }
 
 func (s *Store) SessionUpdate(ctx context.Context, uid models.UID, model *models.Session) error {
-	result, err := s.db.Collection("sessions").UpdateOne(ctx, bson.M{"uid": uid}, bson.M{"$set": model})
-	if err != nil {
+	// NOTICE: the session before the update is returned, as the device it was opened on has its connection history
+	// cached.
+	session := new(models.Session)
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"device_uid": 1})
+	if err := s.db.Collection("sessions").FindOneAndUpdate(ctx, bson.M{"uid": uid}, bson.M{"$set": model}, opts).Decode(session); err != nil {
 		return FromMongoError(err)
 	}
 
-	if result.MatchedCount < 1 {
-		return store.ErrNoDocuments
+	for _, device := range []models.UID{session.DeviceUID, model.DeviceUID} {
+		if device == "" {
+			continue
+		}
+
+		if err := s.cache.Delete(ctx, deviceConnectionHistoryKey(string(device))); err != nil {
+			logrus.Error(err)
+		}
 	}
 
 	return nil
@@ -186,6 +201,10 @@ func (s *Store) SessionCreate(ctx context.Context, session models.Session) (*mod
 		return nil, FromMongoError(err)
 	}
 
+	if err := s.cache.Delete(ctx, deviceConnectionHistoryKey(string(session.DeviceUID))); err != nil {
+		logrus.Error(err)
+	}
+
 	return &session, nil
 }
 
@@ -216,7 +235,8 @@ func (s *Store) SessionSetLastSeen(ctx context.Context, uid models.UID) error {
 	return nil
 }
 
-// SessionDeleteActives sets a session's "closed" status to true and deletes all related active_sessions.
+// SessionDeleteActives sets a session's "closed" status to true and deletes all related active_sessions. Closing an
+// already closed session succeeds without changing it, so its "last_seen" is kept as when it was first closed.
 func (s *Store) SessionDeleteActives(ctx context.Context, uid models.UID) error {
 	mongoSession, err := s.db.Client().StartSession()
 	if err != nil {
@@ -224,22 +244,46 @@ func (s *Store) SessionDeleteActives(ctx context.Context, uid models.UID) error
 	}
 	defer mongoSession.EndSession(ctx)
 
-	_, err = mongoSession.WithTransaction(ctx, func(mongoctx mongo.SessionContext) (interface{}, error) {
+	closed, err := mongoSession.WithTransaction(ctx, func(mongoctx mongo.SessionContext) (interface{}, error) {
 		session := new(models.Session)
 
-		query := bson.M{"uid": uid}
+		query := bson.M{"uid": uid, "closed": bson.M{"$ne": true}}
 		update := bson.M{"$set": bson.M{"last_seen": clock.Now(), "closed": true}}
 
 		if err := s.db.Collection("sessions").FindOneAndUpdate(ctx, query, update).Decode(&session); err != nil {
+			if err != mongo.ErrNoDocuments {
+				return nil, FromMongoError(err)
+			}
+
+			// NOTICE: the session may be missing or already closed, what are told apart to succeed on the latter. The
+			// active session left behind by a previous close that failed midway is still removed.
+			count, err := s.db.Collection("sessions").CountDocuments(ctx, bson.M{"uid": uid})
+			if err != nil {
+				return nil, FromMongoError(err)
+			}
+
+			if count < 1 {
+				return nil, store.ErrNoDocuments
+			}
+
+			_, err = s.db.Collection("active_sessions").DeleteMany(ctx, bson.M{"uid": uid})
+
 			return nil, FromMongoError(err)
 		}
 
 		_, err := s.db.Collection("active_sessions").DeleteMany(ctx, bson.M{"uid": session.UID})
 
-		return nil, FromMongoError(err)
+		return session, FromMongoError(err)
 	})
+	if err != nil || closed == nil {
+		return err
+	}
+
+	if err := s.cache.Delete(ctx, deviceConnectionHistoryKey(string(closed.(*models.Session).DeviceUID))); err != nil {
+		logrus.Error(err)
+	}
 
-	return err
+	return nil
 }
 
 func (s *Store) SessionUpdateDeviceUID(ctx context.Context, oldUID models.UID, newUID models.UID) error {
@@ -252,19 +296,27 @@ func (s *Store) SessionUpdateDeviceUID(ctx context.Context, oldUID models.UID, n
 		return store.ErrNoDocuments
 	}
 
+	for _, uid := range []models.UID{oldUID, newUID} {
+		if err := s.cache.Delete(ctx, deviceConnectionHistoryKey(string(uid))); err != nil {
+			logrus.Error(err)
+		}
+	}
+
 	return nil
 }
 
 // SessionDeleteRecordFrameByDate deletes recorded sessions and updates session records
 // before the specified date.
 //
-// It takes a time 'lte', representing the maximum date. The method deletes all recorded sessions
+// It takes a time 'lte', representing the maximum date. The method deletes the recorded sessions
 // with a 'time' field less than or equal to 'lte' It also updates 'sessions' records by setting
 // the 'recorded' field to false for sessions that started before 'lte' and are marked as recorded.
+// At most 'limit' documents are deleted and updated, so a large cleanup can be split in batches
+// that don't hold locks for long; when 'limit' is less than 1, all of them are.
 //
 // The method returns the count of deleted sessions, the count of updated session records,
 // and any encountered error during the operation.
-func (s *Store) SessionDeleteRecordFrameByDate(ctx context.Context, lte time.Time) (deletedCount int64, updatedCount int64, err error) {
+func (s *Store) SessionDeleteRecordFrameByDate(ctx context.Context, lte time.Time, limit int64) (deletedCount int64, updatedCount int64, err error) {
 	mongoSession, err := s.db.Client().StartSession()
 	if err != nil {
 		return deletedCount, updatedCount, FromMongoError(err)
@@ -272,28 +324,35 @@ func (s *Store) SessionDeleteRecordFrameByDate(ctx context.Context, lte time.Tim
 	defer mongoSession.EndSession(ctx)
 
 	_, err = mongoSession.WithTransaction(ctx, func(mongoctx mongo.SessionContext) (interface{}, error) {
-		d, err := s.db.Collection("recorded_sessions").DeleteMany(
-			ctx,
-			bson.M{
-				"time": bson.D{
-					{Key: "$lte", Value: lte},
-				},
+		frames, err := limitedFilter(ctx, s.db.Collection("recorded_sessions"), bson.M{
+			"time": bson.D{
+				{Key: "$lte", Value: lte},
 			},
-		)
+		}, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := s.db.Collection("recorded_sessions").DeleteMany(ctx, frames)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions, err := limitedFilter(ctx, s.db.Collection("sessions"), bson.M{
+			"started_at": bson.D{
+				{Key: "$lte", Value: lte},
+			},
+			"recorded": bson.M{
+				"$eq": true,
+			},
+		}, limit)
 		if err != nil {
 			return nil, err
 		}
 
 		u, err := s.db.Collection("sessions").UpdateMany(
 			ctx,
-			bson.M{
-				"started_at": bson.D{
-					{Key: "$lte", Value: lte},
-				},
-				"recorded": bson.M{
-					"$eq": true,
-				},
-			},
+			sessions,
 			bson.M{
 				"$set": bson.M{
 					"recorded": false,
@@ -313,6 +372,135 @@ func (s *Store) SessionDeleteRecordFrameByDate(ctx context.Context, lte time.Tim
 	return deletedCount, updatedCount, FromMongoError(err)
 }
 
+func (s *Store) SessionListUnarchivedRecords(ctx context.Context, lte time.Time, limit int64) ([]models.UID, error) {
+	filter := bson.M{
+		"started_at":      bson.M{"$lte": lte},
+		"recorded":        true,
+		"closed":          true,
+		"storage_backend": bson.M{"$ne": models.SessionStorageBackendS3},
+	}
+
+	opts := options.Find().SetProjection(bson.M{"uid": 1}).SetSort(bson.D{{Key: "started_at", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cursor, err := s.db.Collection("sessions").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []struct {
+		UID models.UID `bson:"uid"`
+	}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	uids := make([]models.UID, len(sessions))
+	for i, session := range sessions {
+		uids[i] = session.UID
+	}
+
+	return uids, nil
+}
+
+// limitedFilter returns a filter matching, at most, limit documents of the collection matched by filter. When limit is
+// less than 1, filter itself is returned.
+func limitedFilter(ctx context.Context, collection *mongo.Collection, filter bson.M, limit int64) (bson.M, error) {
+	if limit < 1 {
+		return filter, nil
+	}
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []struct {
+		ID interface{} `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+
+	return bson.M{"_id": bson.M{"$in": ids}}, nil
+}
+
+// sessionDeleteBatchSize is the number of sessions deleted at once by SessionDeleteByDate.
+const sessionDeleteBatchSize = 1000
+
+// SessionDeleteByDate deletes the sessions whose 'last_seen' field is less than or equal to 'lte', so sessions still
+// alive are never deleted, whatever is their start. The recorded frames of each session are deleted before the session
+// itself, in batches, so a failure halfway leaves no frame without its session.
+//
+// The method returns the count of deleted sessions and any encountered error during the operation.
+func (s *Store) SessionDeleteByDate(ctx context.Context, lte time.Time) (int64, error) {
+	cursor, err := s.db.Collection("sessions").Find(
+		ctx,
+		bson.M{"last_seen": bson.M{"$lte": lte}},
+		options.Find().SetProjection(bson.M{"uid": 1}).SetBatchSize(sessionDeleteBatchSize),
+	)
+	if err != nil {
+		return 0, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	var deletedCount int64
+
+	remove := func(uids []string) error {
+		if _, err := s.db.Collection("recorded_sessions").DeleteMany(ctx, bson.M{"uid": bson.M{"$in": uids}}); err != nil {
+			return err
+		}
+
+		res, err := s.db.Collection("sessions").DeleteMany(ctx, bson.M{"uid": bson.M{"$in": uids}})
+		if err != nil {
+			return err
+		}
+
+		deletedCount += res.DeletedCount
+
+		return nil
+	}
+
+	uids := make([]string, 0, sessionDeleteBatchSize)
+	for cursor.Next(ctx) {
+		var session struct {
+			UID string `bson:"uid"`
+		}
+
+		if err := cursor.Decode(&session); err != nil {
+			return deletedCount, FromMongoError(err)
+		}
+
+		if uids = append(uids, session.UID); len(uids) == sessionDeleteBatchSize {
+			if err := remove(uids); err != nil {
+				return deletedCount, FromMongoError(err)
+			}
+
+			uids = uids[:0]
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return deletedCount, FromMongoError(err)
+	}
+
+	if len(uids) > 0 {
+		if err := remove(uids); err != nil {
+			return deletedCount, FromMongoError(err)
+		}
+	}
+
+	return deletedCount, nil
+}
+
 func (s *Store) SessionActiveCreate(ctx context.Context, uid models.UID, session *models.Session) error {
 	_, err := s.db.Collection("active_sessions").InsertOne(ctx, &models.ActiveSession{
 		UID:      uid,
@@ -325,3 +513,223 @@ func (s *Store) SessionActiveCreate(ctx context.Context, uid models.UID, session
 
 	return nil
 }
+
+// SessionIncrementViewCount atomically increments the "view_count" of a session and sets its "last_viewed_at" to now.
+func (s *Store) SessionIncrementViewCount(ctx context.Context, uid models.UID) error {
+	filter := bson.M{"uid": uid}
+
+	// Only match for the respective tenant if requested
+	if tenant := gateway.TenantFromContext(ctx); tenant != nil {
+		filter["tenant_id"] = tenant.ID
+	}
+
+	res, err := s.db.Collection("sessions").UpdateOne(
+		ctx,
+		filter,
+		bson.M{
+			"$inc": bson.M{"view_count": 1},
+			"$set": bson.M{"last_viewed_at": clock.Now()},
+		},
+	)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) TopViewedSessions(ctx context.Context, tenantID string, limit int) ([]models.Session, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "view_count", Value: -1}}).SetLimit(int64(limit))
+
+	cursor, err := s.db.Collection("sessions").Find(ctx, bson.M{"tenant_id": tenantID, "view_count": bson.M{"$gt": 0}}, opts)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	sessions := make([]models.Session, 0)
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return sessions, nil
+}
+
+func (s *Store) SessionListActives(ctx context.Context, tenantID string) ([]models.Session, error) {
+	query := []bson.M{
+		{
+			"$match": bson.M{
+				"tenant_id": tenantID,
+			},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "active_sessions",
+				"localField":   "uid",
+				"foreignField": "uid",
+				"as":           "active",
+			},
+		},
+		{
+			"$match": bson.M{
+				"active": bson.M{"$ne": []interface{}{}},
+			},
+		},
+		{
+			"$addFields": bson.M{
+				"active": true,
+			},
+		},
+		{
+			"$sort": bson.M{
+				"started_at": 1,
+			},
+		},
+	}
+
+	cursor, err := s.db.Collection("sessions").Aggregate(ctx, query)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	sessions := make([]models.Session, 0)
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return sessions, nil
+}
+
+func (s *Store) SessionListRecordFrames(ctx context.Context, uid models.UID) ([]models.RecordedSession, error) {
+	opts := s.hints.FindOptions("recorded_sessions").SetSort(bson.D{{Key: "time", Value: 1}})
+
+	cursor, err := s.db.Collection("recorded_sessions").Find(ctx, bson.M{"uid": uid}, opts)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	frames := make([]models.RecordedSession, 0)
+	if err := cursor.All(ctx, &frames); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return frames, nil
+}
+
+func (s *Store) SessionStreamRecordFrames(ctx context.Context, uid models.UID, fn func(frame *models.RecordedSession) error) error {
+	opts := s.hints.FindOptions("recorded_sessions").SetSort(bson.D{{Key: "time", Value: 1}})
+
+	cursor, err := s.db.Collection("recorded_sessions").Find(ctx, bson.M{"uid": uid}, opts)
+	if err != nil {
+		return FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		frame := new(models.RecordedSession)
+		if err := cursor.Decode(frame); err != nil {
+			return FromMongoError(err)
+		}
+
+		if err := fn(frame); err != nil {
+			return err
+		}
+	}
+
+	return FromMongoError(cursor.Err())
+}
+
+func (s *Store) SessionCreateRecordFrames(ctx context.Context, frames []models.RecordedSession) error {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(frames))
+	for i := range frames {
+		docs[i] = frames[i]
+	}
+
+	_, err := s.db.Collection("recorded_sessions").InsertMany(ctx, docs, options.InsertMany().SetOrdered(true))
+
+	return FromMongoError(err)
+}
+
+func (s *Store) SessionDeleteRecordFrames(ctx context.Context, uid models.UID) error {
+	_, err := s.db.Collection("recorded_sessions").DeleteMany(ctx, bson.M{"uid": uid})
+
+	return FromMongoError(err)
+}
+
+func (s *Store) SessionListUIDs(ctx context.Context, tenantID string) ([]models.UID, error) {
+	cursor, err := s.db.Collection("sessions").Find(ctx, bson.M{"tenant_id": tenantID}, options.Find().SetProjection(bson.M{"uid": 1}))
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	uids := make([]models.UID, 0)
+	for cursor.Next(ctx) {
+		var session struct {
+			UID models.UID `bson:"uid"`
+		}
+
+		if err := cursor.Decode(&session); err != nil {
+			return nil, FromMongoError(err)
+		}
+
+		uids = append(uids, session.UID)
+	}
+
+	return uids, FromMongoError(cursor.Err())
+}
+
+func (s *Store) SessionSetRecordUploadState(ctx context.Context, uid models.UID, from, state *models.RecordingUploadState) error {
+	filter := bson.M{"uid": uid, "record_upload.bytes_received": from.BytesReceived}
+	if from.BytesReceived == 0 {
+		// NOTICE: a null matches the sessions whose recording was never uploaded, as they have no progress stored.
+		filter["record_upload.bytes_received"] = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	res, err := s.db.Collection("sessions").UpdateOne(ctx, filter, bson.M{"$set": bson.M{"record_upload": state}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) SessionSetRecordingSummary(ctx context.Context, uid models.UID, summary *models.RecordingSummary) error {
+	res, err := s.db.Collection("sessions").UpdateOne(ctx, bson.M{"uid": uid}, bson.M{"$set": bson.M{"recording_summary": summary}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) SessionSetStorage(ctx context.Context, uid models.UID, backend, location string) error {
+	res, err := s.db.Collection("sessions").UpdateOne(ctx, bson.M{"uid": uid}, bson.M{"$set": bson.M{"storage_backend": backend, "storage_location": location}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
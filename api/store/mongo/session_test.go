@@ -2,14 +2,19 @@ package mongo_test
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/shellhub-io/shellhub/api/pkg/recordstorage"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestSessionList(t *testing.T) {
@@ -204,7 +209,7 @@ func TestSessionList(t *testing.T) {
 				assert.NoError(t, srv.Reset())
 			})
 
-			s, count, err := s.SessionList(ctx, tc.paginator)
+			s, count, err := s.SessionList(ctx, tc.paginator, query.Filters{}, query.Sorter{})
 
 			sort(tc.expected.s)
 			sort(s)
@@ -509,7 +514,7 @@ func TestSessionDeleteActives(t *testing.T) {
 			expected:    store.ErrNoDocuments,
 		},
 		{
-			description: "succeeds when session is found",
+			description: "succeeds when session is already closed",
 			UID:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
 			fixtures:    []string{fixtureSessions},
 			expected:    nil,
@@ -529,6 +534,59 @@ func TestSessionDeleteActives(t *testing.T) {
 			assert.Equal(t, tc.expected, err)
 		})
 	}
+
+	t.Run("succeeds closing the session once, keeping when it was closed", func(t *testing.T) {
+		ctx := context.Background()
+
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Reset())
+		})
+
+		const uid = models.UID("open")
+
+		_, err := db.Collection("sessions").InsertOne(ctx, bson.M{
+			"uid":        uid,
+			"device_uid": "device",
+			"last_seen":  time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+			"closed":     false,
+		})
+		assert.NoError(t, err)
+
+		assert.NoError(t, s.SessionDeleteActives(ctx, uid))
+
+		closed := new(models.Session)
+		assert.NoError(t, db.Collection("sessions").FindOne(ctx, bson.M{"uid": uid}).Decode(closed))
+		assert.True(t, closed.Closed)
+		assert.NotEqual(t, time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC), closed.LastSeen)
+
+		assert.NoError(t, s.SessionDeleteActives(ctx, uid))
+
+		again := new(models.Session)
+		assert.NoError(t, db.Collection("sessions").FindOne(ctx, bson.M{"uid": uid}).Decode(again))
+		assert.Equal(t, closed.LastSeen, again.LastSeen)
+	})
+
+	t.Run("succeeds removing the stale active session of an already closed session", func(t *testing.T) {
+		ctx := context.Background()
+
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Reset())
+		})
+
+		const uid = models.UID("closed")
+
+		_, err := db.Collection("sessions").InsertOne(ctx, bson.M{"uid": uid, "device_uid": "device", "closed": true})
+		assert.NoError(t, err)
+
+		_, err = db.Collection("active_sessions").InsertOne(ctx, bson.M{"uid": uid})
+		assert.NoError(t, err)
+
+		assert.NoError(t, s.SessionDeleteActives(ctx, uid))
+
+		count, err := db.Collection("active_sessions").CountDocuments(ctx, bson.M{"uid": uid})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
 }
 
 func TestSessionDeleteRecordFrameByDate(t *testing.T) {
@@ -541,6 +599,7 @@ func TestSessionDeleteRecordFrameByDate(t *testing.T) {
 	cases := []struct {
 		description string
 		lte         time.Time
+		limit       int64
 		fixtures    []string
 		expected    Expected
 	}{
@@ -554,6 +613,28 @@ func TestSessionDeleteRecordFrameByDate(t *testing.T) {
 				err:          nil,
 			},
 		},
+		{
+			description: "succeeds deleting and updating every match when there is no limit",
+			lte:         time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC),
+			limit:       0,
+			fixtures:    []string{fixtureSessions, fixtureRecordedSessions},
+			expected: Expected{
+				deletedCount: 2,
+				updatedCount: 2,
+				err:          nil,
+			},
+		},
+		{
+			description: "succeeds deleting and updating up to the limit",
+			lte:         time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC),
+			limit:       1,
+			fixtures:    []string{fixtureSessions, fixtureRecordedSessions},
+			expected: Expected{
+				deletedCount: 1,
+				updatedCount: 1,
+				err:          nil,
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -565,8 +646,742 @@ func TestSessionDeleteRecordFrameByDate(t *testing.T) {
 				assert.NoError(t, srv.Reset())
 			})
 
-			deletedCount, updatedCount, err := s.SessionDeleteRecordFrameByDate(ctx, tc.lte)
+			deletedCount, updatedCount, err := s.SessionDeleteRecordFrameByDate(ctx, tc.lte, tc.limit)
 			assert.Equal(t, tc.expected, Expected{deletedCount, updatedCount, err})
 		})
 	}
 }
+
+func TestSessionListUnarchivedRecords(t *testing.T) {
+	type Expected struct {
+		uids []models.UID
+		err  error
+	}
+
+	cases := []struct {
+		description string
+		lte         time.Time
+		limit       int64
+		fixtures    []string
+		setup       func() error
+		expected    Expected
+	}{
+		{
+			description: "succeeds when there are no sessions",
+			lte:         time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC),
+			fixtures:    []string{},
+			setup:       func() error { return nil },
+			expected:    Expected{uids: []models.UID{}},
+		},
+		{
+			description: "succeeds listing the recorded sessions started before the date, oldest first",
+			lte:         time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC),
+			fixtures:    []string{fixtureSessions},
+			setup:       func() error { return nil },
+			expected: Expected{uids: []models.UID{
+				"e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824",
+				"bc3d75821a29cfe70bf7986f9ee5629e384b2d3a21e0c3d90f6e35b0c946178a",
+			}},
+		},
+		{
+			description: "succeeds listing up to the limit",
+			lte:         time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC),
+			limit:       1,
+			fixtures:    []string{fixtureSessions},
+			setup:       func() error { return nil },
+			expected: Expected{uids: []models.UID{
+				"e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824",
+			}},
+		},
+		{
+			description: "succeeds skipping the sessions started after the date",
+			lte:         time.Date(2023, time.January, 3, 12, 0, 0, 0, time.UTC),
+			fixtures:    []string{fixtureSessions},
+			setup:       func() error { return nil },
+			expected: Expected{uids: []models.UID{
+				"e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824",
+			}},
+		},
+		{
+			description: "succeeds skipping the sessions still open",
+			lte:         time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC),
+			fixtures:    []string{fixtureSessions},
+			setup: func() error {
+				_, err := db.Collection("sessions").UpdateOne(
+					context.Background(),
+					bson.M{"uid": "e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824"},
+					bson.M{"$set": bson.M{"closed": false}},
+				)
+
+				return err
+			},
+			expected: Expected{uids: []models.UID{
+				"bc3d75821a29cfe70bf7986f9ee5629e384b2d3a21e0c3d90f6e35b0c946178a",
+			}},
+		},
+		{
+			description: "succeeds skipping the sessions already exported",
+			lte:         time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC),
+			fixtures:    []string{fixtureSessions},
+			setup: func() error {
+				return s.SessionSetStorage(
+					context.Background(),
+					models.UID("e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824"),
+					models.SessionStorageBackendS3,
+					"https://bucket.s3.amazonaws.com/sessions/e7f3a56d8b9e.cast.gz",
+				)
+			},
+			expected: Expected{uids: []models.UID{
+				"bc3d75821a29cfe70bf7986f9ee5629e384b2d3a21e0c3d90f6e35b0c946178a",
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			assert.NoError(t, tc.setup())
+
+			uids, err := s.SessionListUnarchivedRecords(ctx, tc.lte, tc.limit)
+			assert.Equal(t, tc.expected, Expected{uids, err})
+		})
+	}
+}
+
+func TestSessionDeleteByDate(t *testing.T) {
+	type Expected struct {
+		deletedCount int64
+		remaining    int64
+		records      int64
+		err          error
+	}
+
+	cases := []struct {
+		description string
+		lte         time.Time
+		fixtures    []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds when there are no sessions to delete",
+			lte:         time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC),
+			fixtures:    []string{},
+			expected: Expected{
+				deletedCount: 0,
+				remaining:    0,
+				records:      0,
+				err:          nil,
+			},
+		},
+		{
+			description: "succeeds deleting the sessions last seen before the date and their records",
+			lte:         time.Date(2023, time.January, 2, 12, 0, 0, 0, time.UTC),
+			fixtures:    []string{fixtureSessions, fixtureRecordedSessions},
+			expected: Expected{
+				deletedCount: 2,
+				remaining:    2,
+				records:      1,
+				err:          nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			deletedCount, err := s.SessionDeleteByDate(ctx, tc.lte)
+
+			remaining, countErr := db.Collection("sessions").CountDocuments(ctx, bson.M{})
+			require.NoError(t, countErr)
+
+			records, countErr := db.Collection("recorded_sessions").CountDocuments(ctx, bson.M{})
+			require.NoError(t, countErr)
+
+			assert.Equal(t, tc.expected, Expected{deletedCount, remaining, records, err})
+		})
+	}
+}
+
+func TestSessionIncrementViewCount(t *testing.T) {
+	cases := []struct {
+		description string
+		UID         models.UID
+		views       int
+		fixtures    []string
+		expected    error
+	}{
+		{
+			description: "fails when session is not found",
+			UID:         models.UID("nonexistent"),
+			views:       1,
+			fixtures:    []string{fixtureSessions},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds when session is found",
+			UID:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
+			views:       1,
+			fixtures:    []string{fixtureSessions},
+			expected:    nil,
+		},
+		{
+			description: "succeeds when session is viewed concurrently",
+			UID:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
+			views:       50,
+			fixtures:    []string{fixtureSessions},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			errs := make(chan error, tc.views)
+
+			wg := new(sync.WaitGroup)
+			for i := 0; i < tc.views; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- s.SessionIncrementViewCount(ctx, tc.UID)
+				}()
+			}
+
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				assert.Equal(t, tc.expected, err)
+			}
+
+			if tc.expected != nil {
+				return
+			}
+
+			session := new(models.Session)
+			require.NoError(t, db.Collection("sessions").FindOne(ctx, bson.M{"uid": tc.UID}).Decode(session))
+			assert.Equal(t, int64(tc.views), session.ViewCount)
+			assert.NotNil(t, session.LastViewedAt)
+		})
+	}
+}
+
+func TestTopViewedSessions(t *testing.T) {
+	type Expected struct {
+		uids []string
+		err  error
+	}
+
+	cases := []struct {
+		description string
+		tenantID    string
+		limit       int
+		views       map[models.UID]int
+		fixtures    []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds with an empty list when no session was viewed",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			limit:       10,
+			views:       map[models.UID]int{},
+			fixtures:    []string{fixtureSessions},
+			expected:    Expected{uids: []string{}, err: nil},
+		},
+		{
+			description: "succeeds ordering sessions by view count",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			limit:       1,
+			views: map[models.UID]int{
+				"a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68": 1,
+				"e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824": 3,
+			},
+			fixtures: []string{fixtureSessions},
+			expected: Expected{
+				uids: []string{"e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824"},
+				err:  nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			for uid, views := range tc.views {
+				for i := 0; i < views; i++ {
+					require.NoError(t, s.SessionIncrementViewCount(ctx, uid))
+				}
+			}
+
+			sessions, err := s.TopViewedSessions(ctx, tc.tenantID, tc.limit)
+
+			uids := make([]string, 0, len(sessions))
+			for _, session := range sessions {
+				uids = append(uids, session.UID)
+			}
+
+			assert.Equal(t, tc.expected, Expected{uids: uids, err: err})
+		})
+	}
+}
+
+func TestSessionListActives(t *testing.T) {
+	type Expected struct {
+		uids []string
+		err  error
+	}
+
+	cases := []struct {
+		description string
+		tenantID    string
+		fixtures    []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds with an empty list when namespace has no active sessions",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			fixtures:    []string{fixtureSessions},
+			expected:    Expected{uids: []string{}, err: nil},
+		},
+		{
+			description: "succeeds with an empty list when namespace is not found",
+			tenantID:    "nonexistent",
+			fixtures:    []string{fixtureSessions, fixtureActiveSessions},
+			expected:    Expected{uids: []string{}, err: nil},
+		},
+		{
+			description: "succeeds listing only the active sessions",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			fixtures:    []string{fixtureSessions, fixtureActiveSessions},
+			expected: Expected{
+				uids: []string{"a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"},
+				err:  nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			sessions, err := s.SessionListActives(ctx, tc.tenantID)
+
+			uids := make([]string, 0, len(sessions))
+			for _, session := range sessions {
+				assert.True(t, session.Active)
+				uids = append(uids, session.UID)
+			}
+
+			assert.Equal(t, tc.expected, Expected{uids: uids, err: err})
+		})
+	}
+}
+
+func TestSessionListRecordFrames(t *testing.T) {
+	type Expected struct {
+		messages []string
+		err      error
+	}
+
+	cases := []struct {
+		description string
+		uid         models.UID
+		fixtures    []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds with an empty list when session has no frames",
+			uid:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
+			fixtures:    []string{fixtureRecordedSessions},
+			expected:    Expected{messages: []string{}, err: nil},
+		},
+		{
+			description: "succeeds listing the session's frames",
+			uid:         models.UID("e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824"),
+			fixtures:    []string{fixtureRecordedSessions},
+			expected:    Expected{messages: []string{"message"}, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			frames, err := s.SessionListRecordFrames(ctx, tc.uid)
+
+			messages := make([]string, 0, len(frames))
+			for _, frame := range frames {
+				messages = append(messages, frame.Message)
+			}
+
+			assert.Equal(t, tc.expected, Expected{messages: messages, err: err})
+		})
+	}
+}
+
+func TestSessionStreamRecordFrames(t *testing.T) {
+	type Expected struct {
+		messages []string
+		err      error
+	}
+
+	cases := []struct {
+		description string
+		uid         models.UID
+		fixtures    []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds without calling fn when session has no frames",
+			uid:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
+			fixtures:    []string{fixtureRecordedSessions},
+			expected:    Expected{messages: []string{}, err: nil},
+		},
+		{
+			description: "succeeds streaming the session's frames",
+			uid:         models.UID("e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824"),
+			fixtures:    []string{fixtureRecordedSessions},
+			expected:    Expected{messages: []string{"message"}, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			messages := make([]string, 0)
+			err := s.SessionStreamRecordFrames(ctx, tc.uid, func(frame *models.RecordedSession) error {
+				messages = append(messages, frame.Message)
+
+				return nil
+			})
+
+			assert.Equal(t, tc.expected, Expected{messages: messages, err: err})
+		})
+	}
+}
+
+func TestSessionSetStorage(t *testing.T) {
+	cases := []struct {
+		description string
+		uid         models.UID
+		fixtures    []string
+		expected    error
+	}{
+		{
+			description: "fails when the session is not found",
+			uid:         models.UID("nonexistent"),
+			fixtures:    []string{fixtureSessions},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds when the session is found",
+			uid:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
+			fixtures:    []string{fixtureSessions},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			err := s.SessionSetStorage(ctx, tc.uid, models.SessionStorageBackendS3, "http://minio:9000/sessions/uid.cast.gz")
+			assert.Equal(t, tc.expected, err)
+
+			if err == nil {
+				session := new(models.Session)
+				assert.NoError(t, db.Collection("sessions").FindOne(ctx, bson.M{"uid": tc.uid}).Decode(session))
+				assert.Equal(t, models.SessionStorageBackendS3, session.StorageBackend)
+				assert.Equal(t, "http://minio:9000/sessions/uid.cast.gz", session.StorageLocation)
+			}
+		})
+	}
+}
+
+func TestSessionCreateRecordFrames(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	frames := []models.RecordedSession{
+		{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000", Message: "a", Time: start, Width: 80, Height: 24},
+		{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000", Message: "b", Time: start.Add(time.Second)},
+	}
+
+	assert.NoError(t, s.SessionCreateRecordFrames(ctx, nil))
+	assert.NoError(t, s.SessionCreateRecordFrames(ctx, frames))
+
+	stored, err := s.SessionListRecordFrames(ctx, models.UID("uid"))
+	assert.NoError(t, err)
+	assert.Equal(t, frames, stored)
+}
+
+func TestSessionDeleteRecordFrames(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, srv.Apply(fixtureRecordedSessions))
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	assert.NoError(t, s.SessionDeleteRecordFrames(ctx, models.UID("nonexistent")))
+	assert.NoError(t, s.SessionDeleteRecordFrames(ctx, models.UID("e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824")))
+
+	frames, err := s.SessionListRecordFrames(ctx, models.UID("e7f3a56d8b9e1dc4c285c98c8ea9c33032a17bda5b6c6b05a6213c2a02f97824"))
+	assert.NoError(t, err)
+	assert.Empty(t, frames)
+}
+
+func TestSessionListUIDs(t *testing.T) {
+	type Expected struct {
+		count int
+		err   error
+	}
+
+	cases := []struct {
+		description string
+		tenantID    string
+		fixtures    []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds with an empty list when the namespace has no session",
+			tenantID:    "nonexistent",
+			fixtures:    []string{fixtureSessions},
+			expected:    Expected{count: 0, err: nil},
+		},
+		{
+			description: "succeeds listing the sessions of the namespace",
+			tenantID:    "00000000-0000-4000-0000-000000000000",
+			fixtures:    []string{fixtureSessions},
+			expected:    Expected{count: 4, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			uids, err := s.SessionListUIDs(ctx, tc.tenantID)
+			assert.Equal(t, tc.expected, Expected{count: len(uids), err: err})
+		})
+	}
+}
+
+func TestSessionSetRecordUploadState(t *testing.T) {
+	cases := []struct {
+		description string
+		uid         models.UID
+		fixtures    []string
+		stored      *models.RecordingUploadState
+		from        *models.RecordingUploadState
+		expected    error
+	}{
+		{
+			description: "fails when the session is not found",
+			uid:         models.UID("nonexistent"),
+			fixtures:    []string{fixtureSessions},
+			from:        &models.RecordingUploadState{},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "fails when the session has no progress stored and from has bytes received",
+			uid:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
+			fixtures:    []string{fixtureSessions},
+			from:        &models.RecordingUploadState{BytesReceived: 1050, FramesStored: 50},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "fails when the progress stored is not from anymore",
+			uid:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
+			fixtures:    []string{fixtureSessions},
+			stored:      &models.RecordingUploadState{BytesReceived: 1050, FramesStored: 50},
+			from:        &models.RecordingUploadState{},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds when the session has no progress stored",
+			uid:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
+			fixtures:    []string{fixtureSessions},
+			from:        &models.RecordingUploadState{},
+			expected:    nil,
+		},
+		{
+			description: "succeeds when the progress stored is from",
+			uid:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
+			fixtures:    []string{fixtureSessions},
+			stored:      &models.RecordingUploadState{BytesReceived: 1050, FramesStored: 50},
+			from:        &models.RecordingUploadState{BytesReceived: 1050, FramesStored: 50},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			if tc.stored != nil {
+				assert.NoError(t, s.SessionSetRecordUploadState(ctx, tc.uid, &models.RecordingUploadState{}, tc.stored))
+			}
+
+			state := &models.RecordingUploadState{BytesReceived: 2100, FramesStored: 100}
+
+			err := s.SessionSetRecordUploadState(ctx, tc.uid, tc.from, state)
+			assert.Equal(t, tc.expected, err)
+
+			if err == nil {
+				session, err := s.SessionGet(ctx, tc.uid)
+				assert.NoError(t, err)
+				assert.Equal(t, state, session.RecordUpload)
+			}
+		})
+	}
+}
+
+func TestSessionSetRecordingSummary(t *testing.T) {
+	cases := []struct {
+		description string
+		uid         models.UID
+		fixtures    []string
+		expected    error
+	}{
+		{
+			description: "fails when the session is not found",
+			uid:         models.UID("nonexistent"),
+			fixtures:    []string{fixtureSessions},
+			expected:    store.ErrNoDocuments,
+		},
+		{
+			description: "succeeds when the session is found",
+			uid:         models.UID("a3b0431f5df6a7827945d2e34872a5c781452bc36de42f8b1297fd9ecb012f68"),
+			fixtures:    []string{fixtureSessions},
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+
+			assert.NoError(t, srv.Apply(tc.fixtures...))
+			t.Cleanup(func() {
+				assert.NoError(t, srv.Reset())
+			})
+
+			summary := &models.RecordingSummary{TotalDuration: 20, ActiveDuration: 2, IdleGaps: 2}
+
+			err := s.SessionSetRecordingSummary(ctx, tc.uid, summary)
+			assert.Equal(t, tc.expected, err)
+
+			if err == nil {
+				session, err := s.SessionGet(ctx, tc.uid)
+				assert.NoError(t, err)
+				assert.Equal(t, summary, session.RecordingSummary)
+			}
+		})
+	}
+}
+
+// BenchmarkRecordingStorage compares the throughput of the recording backends storing and retrieving a recording in
+// batches, as uploaded by the SSH server.
+func BenchmarkRecordingStorage(b *testing.B) {
+	const frames = 1000
+
+	ctx := context.Background()
+
+	fs, err := recordstorage.NewFileSystemRecordingStorage(b.TempDir())
+	require.NoError(b, err)
+
+	backends := map[string]recordstorage.RecordingStorage{
+		recordstorage.BackendMongo:      recordstorage.NewMongoRecordingStorage(s),
+		recordstorage.BackendFileSystem: fs,
+	}
+
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	batch := make([]models.RecordedSession, 100)
+
+	for name, storage := range backends {
+		b.Run(name, func(b *testing.B) {
+			b.Cleanup(func() {
+				assert.NoError(b, srv.Reset())
+			})
+
+			b.SetBytes(int64(frames * len("frame")))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				uid := models.UID(fmt.Sprintf("%s-%d", name, i))
+
+				for n := 0; n < frames; n += len(batch) {
+					for j := range batch {
+						batch[j] = models.RecordedSession{UID: uid, Message: "frame", Time: start.Add(time.Duration(n+j) * time.Millisecond)}
+					}
+
+					require.NoError(b, storage.Store(ctx, uid, batch))
+				}
+
+				retrieved, err := storage.Retrieve(ctx, uid)
+				require.NoError(b, err)
+				require.Len(b, retrieved, frames)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrShardKeyNotIndexed is returned when the shard key of a collection hasn't an index to be hinted to its queries.
+var ErrShardKeyNotIndexed = errors.New("shard key is not indexed")
+
+// ShardingHints maps the name of a collection to the fields of its shard key, hinted, in ascending order, to the
+// queries made on it. This makes the queries use the index of the shard key, routing them to the shards holding the
+// documents instead of broadcasting them, as the collection grows.
+type ShardingHints map[string][]string
+
+// Hint returns the index of the shard key of the collection, as a keys document, or nil when there is none.
+func (h ShardingHints) Hint(collection string) interface{} {
+	fields := h[collection]
+	if len(fields) == 0 {
+		return nil
+	}
+
+	keys := make(bson.D, len(fields))
+	for i, field := range fields {
+		keys[i] = bson.E{Key: field, Value: 1}
+	}
+
+	return keys
+}
+
+// FindOptions returns the options of a find on the collection, hinting the index of its shard key, if any.
+func (h ShardingHints) FindOptions(collection string) *options.FindOptions {
+	opts := options.Find()
+	if hint := h.Hint(collection); hint != nil {
+		opts.SetHint(hint)
+	}
+
+	return opts
+}
+
+// AggregateOptions returns the options of an aggregation on the collection, hinting the index of its shard key, if
+// any.
+func (h ShardingHints) AggregateOptions(collection string) *options.AggregateOptions {
+	opts := options.Aggregate()
+	if hint := h.Hint(collection); hint != nil {
+		opts.SetHint(hint)
+	}
+
+	return opts
+}
+
+// ValidateShardingConfig verifies that the shard key of each collection in hints has an index with its fields, in the
+// same order and ascending, as MongoDB refuses the queries hinting an index that doesn't exist.
+func ValidateShardingConfig(ctx context.Context, db *mongo.Database, hints ShardingHints) error {
+	for collection, fields := range hints {
+		if len(fields) == 0 {
+			continue
+		}
+
+		specs, err := db.Collection(collection).Indexes().ListSpecifications(ctx)
+		if err != nil {
+			return FromMongoError(err)
+		}
+
+		found := false
+		for _, spec := range specs {
+			if indexes(spec.KeysDocument, fields) {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%w: %s on %v", ErrShardKeyNotIndexed, collection, fields)
+		}
+	}
+
+	return nil
+}
+
+// indexes reports whether the keys document of an index is made of the fields, in the same order and ascending.
+func indexes(keys bson.Raw, fields []string) bool {
+	elements, err := keys.Elements()
+	if err != nil || len(elements) != len(fields) {
+		return false
+	}
+
+	for i, element := range elements {
+		if element.Key() != fields[i] {
+			return false
+		}
+
+		if order, ok := element.Value().AsInt64OK(); !ok || order != 1 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,178 @@
+package mongo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/store/mongo"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	mongodb "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestShardingHints(t *testing.T) {
+	hints := mongo.ShardingHints{
+		"sessions":          {"tenant_id", "started_at"},
+		"recorded_sessions": {"uid"},
+		"devices":           {},
+	}
+
+	cases := []struct {
+		description string
+		collection  string
+		expected    interface{}
+	}{
+		{
+			description: "hints the fields of the shard key in ascending order",
+			collection:  "sessions",
+			expected:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "started_at", Value: 1}},
+		},
+		{
+			description: "hints a single field shard key",
+			collection:  "recorded_sessions",
+			expected:    bson.D{{Key: "uid", Value: 1}},
+		},
+		{
+			description: "doesn't hint when the shard key has no fields",
+			collection:  "devices",
+			expected:    nil,
+		},
+		{
+			description: "doesn't hint when the collection has no shard key",
+			collection:  "users",
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, hints.Hint(tc.collection))
+			assert.Equal(t, tc.expected, hints.FindOptions(tc.collection).Hint)
+			assert.Equal(t, tc.expected, hints.AggregateOptions(tc.collection).Hint)
+		})
+	}
+}
+
+func TestValidateShardingConfig(t *testing.T) {
+	ctx := context.TODO()
+
+	_, err := db.Collection("sharded").Indexes().CreateOne(ctx, mongodb.IndexModel{
+		Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "uid", Value: 1}},
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Collection("sharded").Drop(ctx))
+	})
+
+	cases := []struct {
+		description string
+		hints       mongo.ShardingHints
+		err         error
+	}{
+		{
+			description: "succeeds when there are no hints",
+			hints:       nil,
+			err:         nil,
+		},
+		{
+			description: "succeeds when the shard key is indexed",
+			hints:       mongo.ShardingHints{"sharded": {"tenant_id", "uid"}},
+			err:         nil,
+		},
+		{
+			description: "succeeds when the shard key has no fields",
+			hints:       mongo.ShardingHints{"unsharded": {}},
+			err:         nil,
+		},
+		{
+			description: "fails when the shard key is indexed in another order",
+			hints:       mongo.ShardingHints{"sharded": {"uid", "tenant_id"}},
+			err:         mongo.ErrShardKeyNotIndexed,
+		},
+		{
+			description: "fails when the shard key is a prefix of an index",
+			hints:       mongo.ShardingHints{"sharded": {"tenant_id"}},
+			err:         mongo.ErrShardKeyNotIndexed,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.ErrorIs(t, mongo.ValidateShardingConfig(ctx, db, tc.hints), tc.err)
+		})
+	}
+}
+
+func TestShardingHintsOnQueries(t *testing.T) {
+	ctx := context.TODO()
+
+	var mu sync.Mutex
+	commands := make(map[string]bson.Raw)
+
+	monitor := &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			commands[e.CommandName] = append(bson.Raw(nil), e.Command...)
+		},
+	}
+
+	client, err := mongodb.Connect(ctx, options.Client().ApplyURI(srv.Container.ConnectionString).SetMonitor(monitor))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, client.Disconnect(ctx))
+	})
+
+	monitored := client.Database(db.Name())
+
+	for collection, keys := range map[string]bson.D{
+		"sessions":          {{Key: "tenant_id", Value: 1}},
+		"recorded_sessions": {{Key: "uid", Value: 1}},
+	} {
+		collection := collection
+
+		name, err := monitored.Collection(collection).Indexes().CreateOne(ctx, mongodb.IndexModel{Keys: keys})
+		require.NoError(t, err)
+
+		t.Cleanup(func() {
+			_, err := monitored.Collection(collection).Indexes().DropOne(ctx, name)
+			assert.NoError(t, err)
+		})
+	}
+
+	st, err := mongo.NewStoreWithConfig(ctx, monitored, cache.NewNullCache(), mongo.Config{
+		ShardingHints: mongo.ShardingHints{
+			"sessions":          {"tenant_id"},
+			"recorded_sessions": {"uid"},
+		},
+	})
+	require.NoError(t, err)
+
+	hint := func(command string) bson.D {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var keys bson.D
+		require.NoError(t, commands[command].Lookup("hint").Unmarshal(&keys))
+
+		return keys
+	}
+
+	_, err = st.SessionListRecordFrames(ctx, models.UID("uid"))
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "uid", Value: int32(1)}}, hint("find"))
+
+	_, _, err = st.SessionList(ctx, query.Paginator{Page: -1, PerPage: -1}, query.Filters{}, query.Sorter{})
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "tenant_id", Value: int32(1)}}, hint("aggregate"))
+}
@@ -9,13 +9,23 @@ import (
 )
 
 func (s *Store) GetStats(ctx context.Context) (*models.Stats, error) {
+	return s.getStats(ctx, gateway.TenantFromContext(ctx))
+}
+
+func (s *Store) GetNamespaceStats(ctx context.Context, tenantID string) (*models.Stats, error) {
+	return s.getStats(ctx, &models.Tenant{ID: tenantID})
+}
+
+// getStats counts the devices and the active sessions of the namespace of tenant, or of every namespace when tenant
+// is nil.
+func (s *Store) getStats(ctx context.Context, tenant *models.Tenant) (*models.Stats, error) {
 	query := []bson.M{
 		{"$group": bson.M{"_id": bson.M{"uid": "$uid"}, "count": bson.M{"$sum": 1}}},
 		{"$group": bson.M{"_id": bson.M{"uid": "$uid"}, "count": bson.M{"$sum": 1}}},
 	}
 
 	// Only match for the respective tenant if requested
-	if tenant := gateway.TenantFromContext(ctx); tenant != nil {
+	if tenant != nil {
 		query = append([]bson.M{{
 			"$match": bson.M{
 				"tenant_id": tenant.ID,
@@ -54,7 +64,7 @@ func (s *Store) GetStats(ctx context.Context) (*models.Stats, error) {
 	}
 
 	// Only match for the respective tenant if requested
-	if tenant := gateway.TenantFromContext(ctx); tenant != nil {
+	if tenant != nil {
 		query = append([]bson.M{{
 			"$match": bson.M{
 				"tenant_id": tenant.ID,
@@ -77,7 +87,7 @@ func (s *Store) GetStats(ctx context.Context) (*models.Stats, error) {
 	}
 
 	// Only match for the respective tenant if requested
-	if tenant := gateway.TenantFromContext(ctx); tenant != nil {
+	if tenant != nil {
 		query = append([]bson.M{{
 			"$match": bson.M{
 				"tenant_id": tenant.ID,
@@ -101,7 +111,7 @@ func (s *Store) GetStats(ctx context.Context) (*models.Stats, error) {
 	}
 
 	// Only match for the respective tenant if requested
-	if tenant := gateway.TenantFromContext(ctx); tenant != nil {
+	if tenant != nil {
 		query = append([]bson.M{{
 			"$match": bson.M{
 				"tenant_id": tenant.ID,
@@ -123,7 +133,7 @@ func (s *Store) GetStats(ctx context.Context) (*models.Stats, error) {
 	query = []bson.M{}
 
 	// Only match for the respective tenant if requested
-	if tenant := gateway.TenantFromContext(ctx); tenant != nil {
+	if tenant != nil {
 		query = append(query, bson.M{
 			"$match": bson.M{
 				"tenant_id": tenant.ID,
@@ -26,6 +26,15 @@ var (
 type Store struct {
 	db    *mongo.Database
 	cache cache.Cache
+	// hints are the shard keys hinted to the queries on the large collections.
+	hints ShardingHints
+}
+
+// Config is the configuration of the [Store].
+type Config struct {
+	// ShardingHints are the shard keys hinted to the queries on the large collections, when sharded. The store doesn't
+	// check them; see [ValidateShardingConfig].
+	ShardingHints ShardingHints
 }
 
 func Connect(ctx context.Context, uri string) (*mongo.Client, *mongo.Database, error) {
@@ -47,7 +56,12 @@ func Connect(ctx context.Context, uri string) (*mongo.Client, *mongo.Database, e
 }
 
 func NewStore(ctx context.Context, db *mongo.Database, cache cache.Cache, opts ...options.DatabaseOpt) (store.Store, error) {
-	store := &Store{db: db, cache: cache}
+	return NewStoreWithConfig(ctx, db, cache, Config{}, opts...)
+}
+
+// NewStoreWithConfig creates a [Store] like [NewStore], configured by cfg.
+func NewStoreWithConfig(ctx context.Context, db *mongo.Database, cache cache.Cache, cfg Config, opts ...options.DatabaseOpt) (store.Store, error) {
+	store := &Store{db: db, cache: cache, hints: cfg.ShardingHints}
 
 	for _, opt := range opts {
 		if err := opt(ctx, store.db); err != nil {
@@ -32,6 +32,7 @@ const (
 	fixtureUsers            = "users"             // Check "store.mongo.fixtures.users" for fixture iefo
 	fixtureNamespaces       = "namespaces"        // Check "store.mongo.fixtures.namespaces" for fixture info
 	fixtureRecoveryTokens   = "recovery_tokens"   // Check "store.mongo.fixtures.recovery_tokens" for fixture info
+	fixtureRecordedSessions = "recorded_sessions" // Check "store.mongo.fixtures.recorded_sessions" for fixture info
 )
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,86 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (s *Store) UserSessionCreate(ctx context.Context, session *models.UserSession) error {
+	if _, err := s.db.Collection("user_sessions").InsertOne(ctx, session); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
+
+// NOTICE: expired sessions are removed by a TTL index, but the removal may be delayed, so they are filtered out on
+// reading as well.
+
+func (s *Store) UserSessionGet(ctx context.Context, id string) (*models.UserSession, error) {
+	session := new(models.UserSession)
+	if err := s.db.Collection("user_sessions").
+		FindOne(ctx, bson.M{"_id": id, "expires_at": bson.M{"$gt": clock.Now()}}).
+		Decode(session); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return session, nil
+}
+
+func (s *Store) UserSessionList(ctx context.Context, userID string) ([]models.UserSession, error) {
+	cursor, err := s.db.Collection("user_sessions").Find(
+		ctx,
+		bson.M{"user_id": userID, "expires_at": bson.M{"$gt": clock.Now()}},
+		options.Find().SetSort(bson.M{"last_activity": -1}),
+	)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	sessions := make([]models.UserSession, 0)
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return sessions, nil
+}
+
+func (s *Store) UserSessionUpdate(ctx context.Context, id string, changes *models.UserSessionChanges) error {
+	res, err := s.db.Collection("user_sessions").UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": changes})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) UserSessionDelete(ctx context.Context, userID, id string) error {
+	res, err := s.db.Collection("user_sessions").DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.DeletedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) UserSessionDeleteAll(ctx context.Context, userID string) error {
+	if _, err := s.db.Collection("user_sessions").DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
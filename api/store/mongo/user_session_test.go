@@ -0,0 +1,86 @@
+package mongo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	sessions := []*models.UserSession{
+		{
+			ID:           "older",
+			UserID:       "507f1f77bcf86cd799439011",
+			IPAddress:    "127.0.0.1",
+			UserAgent:    "curl/8.0.0",
+			CreatedAt:    now.Add(-2 * time.Hour),
+			LastActivity: now.Add(-time.Hour),
+			ExpiresAt:    now.Add(time.Hour),
+		},
+		{
+			ID:           "newer",
+			UserID:       "507f1f77bcf86cd799439011",
+			IPAddress:    "127.0.0.2",
+			UserAgent:    "Mozilla/5.0",
+			CreatedAt:    now.Add(-time.Hour),
+			LastActivity: now,
+			ExpiresAt:    now.Add(time.Hour),
+		},
+		{
+			ID:           "expired",
+			UserID:       "507f1f77bcf86cd799439011",
+			IPAddress:    "127.0.0.3",
+			CreatedAt:    now.Add(-73 * time.Hour),
+			LastActivity: now.Add(-73 * time.Hour),
+			ExpiresAt:    now.Add(-time.Hour),
+		},
+	}
+
+	for _, session := range sessions {
+		require.NoError(t, s.UserSessionCreate(ctx, session))
+	}
+
+	list, err := s.UserSessionList(ctx, "507f1f77bcf86cd799439011")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "newer", list[0].ID)
+	assert.Equal(t, "older", list[1].ID)
+
+	_, err = s.UserSessionGet(ctx, "expired")
+	assert.ErrorIs(t, err, store.ErrNoDocuments)
+
+	require.NoError(t, s.UserSessionUpdate(ctx, "older", &models.UserSessionChanges{LastActivity: now.Add(time.Minute)}))
+
+	got, err := s.UserSessionGet(ctx, "older")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), got.LastActivity)
+	assert.Equal(t, "curl/8.0.0", got.UserAgent)
+
+	assert.ErrorIs(t, s.UserSessionUpdate(ctx, "unknown", &models.UserSessionChanges{LastActivity: now}), store.ErrNoDocuments)
+
+	// A session can only be deleted by its own user.
+	assert.ErrorIs(t, s.UserSessionDelete(ctx, "000000000000000000000000", "older"), store.ErrNoDocuments)
+	require.NoError(t, s.UserSessionDelete(ctx, "507f1f77bcf86cd799439011", "older"))
+
+	_, err = s.UserSessionGet(ctx, "older")
+	assert.ErrorIs(t, err, store.ErrNoDocuments)
+
+	require.NoError(t, s.UserSessionDeleteAll(ctx, "507f1f77bcf86cd799439011"))
+
+	list, err = s.UserSessionList(ctx, "507f1f77bcf86cd799439011")
+	require.NoError(t, err)
+	assert.Len(t, list, 0)
+}
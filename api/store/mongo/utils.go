@@ -9,15 +9,16 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // AggregateCount takes a pipeline and count the results.
-func AggregateCount(ctx context.Context, coll *mongo.Collection, pipeline []bson.M) (int, error) {
+func AggregateCount(ctx context.Context, coll *mongo.Collection, pipeline []bson.M, opts ...*options.AggregateOptions) (int, error) {
 	resp := struct {
 		Count int `bson:"count"`
 	}{}
 
-	cursor, err := coll.Aggregate(ctx, pipeline)
+	cursor, err := coll.Aggregate(ctx, pipeline, opts...)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,57 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/api/store/mongo/queries"
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (s *Store) WebhookDeadLetterCreate(ctx context.Context, letter *models.WebhookDeadLetter) error {
+	letter.CreatedAt = clock.Now()
+
+	if _, err := s.db.Collection("webhook_dead_letters").InsertOne(ctx, letter); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
+
+func (s *Store) WebhookDeadLetterList(ctx context.Context, tenantID string, paginator query.Paginator) ([]models.WebhookDeadLetter, int, error) {
+	sorter := query.Sorter{By: "created_at", Order: query.OrderDesc}
+
+	query := []bson.M{
+		{
+			"$match": bson.M{
+				"tenant_id": tenantID,
+			},
+		},
+	}
+
+	queryCount := query
+	queryCount = append(queryCount, bson.M{"$count": "count"})
+	count, err := AggregateCount(ctx, s.db.Collection("webhook_dead_letters"), queryCount)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+
+	query = append(query, queries.FromSorter(&sorter)...)
+	query = append(query, queries.FromPaginator(&paginator)...)
+
+	letters := make([]models.WebhookDeadLetter, 0)
+
+	cursor, err := s.db.Collection("webhook_dead_letters").Aggregate(ctx, query)
+	if err != nil {
+		return letters, count, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &letters); err != nil {
+		return letters, count, FromMongoError(err)
+	}
+
+	return letters, count, nil
+}
@@ -0,0 +1,56 @@
+package mongo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmocks "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeadLetter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Reset())
+	})
+
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	backend := clock.DefaultBackend
+	t.Cleanup(func() {
+		clock.DefaultBackend = backend
+	})
+
+	clockMock := new(clockmocks.Clock)
+	clock.DefaultBackend = clockMock
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, letter := range []*models.WebhookDeadLetter{
+		{TenantID: tenantID, URL: "https://example.com/hook", Event: models.WebhookEvent{ID: "first", Type: models.WebhookEventDeviceAccepted, TenantID: tenantID}, Attempts: 5, Error: "timeout"},
+		{TenantID: tenantID, URL: "https://example.com/hook", Event: models.WebhookEvent{ID: "second", Type: models.WebhookEventMemberAdded, TenantID: tenantID}, Attempts: 5, Error: "timeout"},
+		{TenantID: "00000000-0000-4000-0000-000000000001", URL: "https://example.com/hook", Event: models.WebhookEvent{ID: "other"}, Attempts: 1, Error: "timeout"},
+	} {
+		clockMock.On("Now").Return(start.Add(time.Duration(i) * time.Minute)).Once()
+		require.NoError(t, s.WebhookDeadLetterCreate(ctx, letter))
+	}
+
+	letters, count, err := s.WebhookDeadLetterList(ctx, tenantID, query.Paginator{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, letters, 2)
+	assert.Equal(t, "second", letters[0].Event.ID)
+	assert.Equal(t, start.Add(time.Minute), letters[0].CreatedAt)
+	assert.Equal(t, "first", letters[1].Event.ID)
+
+	letters, count, err = s.WebhookDeadLetterList(ctx, tenantID, query.Paginator{Page: 2, PerPage: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, letters, 1)
+	assert.Equal(t, "first", letters[0].Event.ID)
+}
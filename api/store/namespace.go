@@ -2,13 +2,23 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"github.com/shellhub-io/shellhub/pkg/api/query"
 	"github.com/shellhub-io/shellhub/pkg/models"
 )
 
 type NamespaceStore interface {
-	NamespaceList(ctx context.Context, paginator query.Paginator, filters query.Filters, export bool) ([]models.Namespace, int, error)
+	// NamespaceList lists the namespaces. When there is a user in context, only the namespaces where it's a member are
+	// listed and, if role is not empty, only the ones where it has that role.
+	NamespaceList(ctx context.Context, paginator query.Paginator, filters query.Filters, role string, export bool) ([]models.Namespace, int, error)
+	// NamespaceListByMember lists the namespaces where the user with the specified ID is a member, restricted to the
+	// ones where it has the specified role when it's not empty. It returns the namespaces of the requested page, the
+	// total of namespaces matching the filters and an error if any.
+	NamespaceListByMember(ctx context.Context, userID string, role string, paginator query.Paginator, filters query.Filters) ([]models.Namespace, int, error)
+	// NamespaceListByOwner lists the namespaces owned by the user with the specified ID. It returns the namespaces of
+	// the requested page, the total of namespaces owned by the user and an error if any.
+	NamespaceListByOwner(ctx context.Context, ownerID string, paginator query.Paginator) ([]models.Namespace, int, error)
 
 	// NamespaceGet retrieves a namespace identified by the given tenantID.
 	// If countDevices is set to true, it populates the [github.com/shellhub-io/shellhub/pkg/models.Namespace.DevicesCount].
@@ -20,15 +30,76 @@ type NamespaceStore interface {
 	NamespaceGetByName(ctx context.Context, name string) (*models.Namespace, error)
 	NamespaceCreate(ctx context.Context, namespace *models.Namespace) (*models.Namespace, error)
 
-	// NamespaceEdit updates a namespace with the specified tenant.
+	// NamespaceEdit updates a namespace with the specified tenant, appending the changes' previous name, when set, to
+	// its name history.
 	// It returns an error, if any, or store.ErrNoDocuments if the namespace does not exist.
 	NamespaceEdit(ctx context.Context, tenant string, changes *models.NamespaceChanges) error
 
+	// NamespaceGetByPreviousName retrieves the namespace that used name before being renamed, provided that it was
+	// renamed after since. When many namespaces did, it retrieves the one renamed last. It returns
+	// store.ErrNoDocuments if there is no such namespace.
+	NamespaceGetByPreviousName(ctx context.Context, name string, since time.Time) (*models.Namespace, error)
+
 	NamespaceUpdate(ctx context.Context, tenantID string, namespace *models.Namespace) error
 	NamespaceDelete(ctx context.Context, tenantID string) error
 	NamespaceAddMember(ctx context.Context, tenantID string, memberID string, memberRole string) (*models.Namespace, error)
 	NamespaceRemoveMember(ctx context.Context, tenantID string, memberID string) (*models.Namespace, error)
 	NamespaceEditMember(ctx context.Context, tenantID string, memberID string, memberNewRole string) error
+
+	// NamespaceSetMemberTags replaces the tags of the member with the specified ID.
+	// It returns an error, if any, or store.ErrNoDocuments if the namespace or the member does not exist.
+	NamespaceSetMemberTags(ctx context.Context, tenantID, memberID string, tags []string) error
+
+	// NamespaceSetMemberAccessSchedule sets the access schedule of the member with the specified ID. A nil schedule
+	// removes it.
+	NamespaceSetMemberAccessSchedule(ctx context.Context, tenantID, memberID string, schedule *models.AccessSchedule) error
+
+	// NamespaceSetAPIRateLimit sets the rate limit of the API requests made on behalf of the namespace with the
+	// specified tenant. A nil limit removes it.
+	//
+	// It returns an error, if any, or store.ErrNoDocuments if the namespace does not exist.
+	NamespaceSetAPIRateLimit(ctx context.Context, tenantID string, limit *models.APIRateLimitConfig) error
+
+	// NamespaceSetWebhook sets the webhook the events of the namespace with the specified tenant are posted to. A nil
+	// webhook removes it.
+	//
+	// It returns an error, if any, or store.ErrNoDocuments if the namespace does not exist.
+	NamespaceSetWebhook(ctx context.Context, tenantID string, webhook *models.NamespaceWebhook) error
+
+	// NamespaceListMembers lists the members of the namespace with the specified tenant. When tag is not empty, only the
+	// members with it are listed.
+	NamespaceListMembers(ctx context.Context, tenantID, tag string) ([]models.Member, error)
+
+	// NamespaceListMemberTags lists, sorted, the unique tags of the members of the namespace with the specified tenant.
+	NamespaceListMemberTags(ctx context.Context, tenantID string) ([]string, error)
+
+	// NamespaceRenew sets the namespace with the specified tenant as active at activeAt, clearing its expiration.
+	// It returns an error, if any, or store.ErrNoDocuments if the namespace does not exist.
+	NamespaceRenew(ctx context.Context, tenantID string, activeAt time.Time) error
+
+	// NamespaceRenewMany sets the namespaces with the specified tenants as active at activeAt, clearing their
+	// expiration, when they were last active before since or are set to expire. The other ones are left untouched, so
+	// it can be called on frequent activity, like the device heartbeats, without writing the namespaces every time.
+	NamespaceRenewMany(ctx context.Context, tenantIDs []string, since, activeAt time.Time) error
+
+	// NamespaceSetExpiresAt sets when the namespace with the specified tenant will be deleted due inactivity.
+	// It returns an error, if any, or store.ErrNoDocuments if the namespace does not exist.
+	NamespaceSetExpiresAt(ctx context.Context, tenantID string, expiresAt time.Time) error
+
+	// NamespaceListInactive lists the free-tier namespaces, what means the ones without an active subscription, last
+	// active before the specified time that don't expire yet.
+	NamespaceListInactive(ctx context.Context, before time.Time) ([]models.Namespace, error)
+
+	// NamespaceListExpired lists the free-tier namespaces that expired before the specified time.
+	NamespaceListExpired(ctx context.Context, before time.Time) ([]models.Namespace, error)
+
+	// NamespaceListUsageReports lists the namespaces with both a usage report schedule and email set.
+	NamespaceListUsageReports(ctx context.Context) ([]models.Namespace, error)
+
+	// NamespaceSetUsageReportSentAt sets when the usage report of the namespace with the specified tenant was sent by
+	// its schedule. It returns an error, if any, or store.ErrNoDocuments if the namespace does not exist.
+	NamespaceSetUsageReportSentAt(ctx context.Context, tenantID string, sentAt time.Time) error
+
 	NamespaceGetFirst(ctx context.Context, id string) (*models.Namespace, error)
 	NamespaceSetSessionRecord(ctx context.Context, sessionRecord bool, tenantID string) error
 	NamespaceGetSessionRecord(ctx context.Context, tenantID string) (bool, error)
@@ -13,4 +13,7 @@ type PublicKeyStore interface {
 	PublicKeyCreate(ctx context.Context, key *models.PublicKey) error
 	PublicKeyUpdate(ctx context.Context, fingerprint string, tenantID string, key *models.PublicKeyUpdate) (*models.PublicKey, error)
 	PublicKeyDelete(ctx context.Context, fingerprint string, tenantID string) error
+	// PublicKeyInvalidateEvalCache invalidates the evaluations of the public key cached by the SSH server, so the next
+	// authentication with it is evaluated again. It's called whenever the public key is deleted or its tags change.
+	PublicKeyInvalidateEvalCache(ctx context.Context, fingerprint, tenantID string) error
 }
@@ -9,14 +9,53 @@ import (
 )
 
 type SessionStore interface {
-	SessionList(ctx context.Context, paginator query.Paginator) ([]models.Session, int, error)
+	SessionList(ctx context.Context, paginator query.Paginator, filters query.Filters, sorter query.Sorter) ([]models.Session, int, error)
 	SessionGet(ctx context.Context, uid models.UID) (*models.Session, error)
 	SessionCreate(ctx context.Context, session models.Session) (*models.Session, error)
 	SessionUpdate(ctx context.Context, uid models.UID, model *models.Session) error
 	SessionSetLastSeen(ctx context.Context, uid models.UID) error
 	SessionDeleteActives(ctx context.Context, uid models.UID) error
 	SessionUpdateDeviceUID(ctx context.Context, oldUID models.UID, newUID models.UID) error
-	SessionDeleteRecordFrameByDate(ctx context.Context, lte time.Time) (deletedCount int64, updatedCount int64, err error)
+	// SessionDeleteRecordFrameByDate deletes at most limit recorded frames before or at lte, and marks at most limit
+	// sessions started before or at lte as not recorded. When limit is less than 1, every one of them is. It returns
+	// the number of deleted frames and updated sessions, so callers can delete in batches until both are below limit.
+	SessionDeleteRecordFrameByDate(ctx context.Context, lte time.Time, limit int64) (deletedCount int64, updatedCount int64, err error)
+	// SessionListUnarchivedRecords lists the UIDs of at most limit closed sessions started before or at lte, oldest
+	// first, whose recordings are still on the store and weren't exported to an object storage. These are the
+	// recordings [SessionStore.SessionDeleteRecordFrameByDate] deletes for the same lte. The sessions still open are
+	// left out, as their recordings aren't complete yet. When limit is less than 1, every one of them is listed.
+	SessionListUnarchivedRecords(ctx context.Context, lte time.Time, limit int64) ([]models.UID, error)
+	// SessionDeleteByDate deletes the sessions last seen before or at lte, along with their recorded frames. It returns
+	// the number of deleted sessions.
+	SessionDeleteByDate(ctx context.Context, lte time.Time) (deletedCount int64, err error)
 	SessionSetRecorded(ctx context.Context, uid models.UID, recorded bool) error
 	SessionActiveCreate(ctx context.Context, uid models.UID, session *models.Session) error
+	// SessionIncrementViewCount atomically increments the view count of a session and sets its last viewed date to now.
+	// It returns [ErrNoDocuments] if the session does not exist.
+	SessionIncrementViewCount(ctx context.Context, uid models.UID) error
+	// TopViewedSessions returns up to limit sessions of a namespace ordered by their view count in descending order.
+	TopViewedSessions(ctx context.Context, tenantID string, limit int) ([]models.Session, error)
+	// SessionListActives lists the sessions of a namespace that have an active record, ordered by their start date.
+	SessionListActives(ctx context.Context, tenantID string) ([]models.Session, error)
+	// SessionListRecordFrames lists the frames recorded on a session ordered by their time.
+	SessionListRecordFrames(ctx context.Context, uid models.UID) ([]models.RecordedSession, error)
+	// SessionStreamRecordFrames calls fn for each frame recorded on a session, ordered by their time, without loading
+	// all of them in memory. It stops at the first error returned by fn.
+	SessionStreamRecordFrames(ctx context.Context, uid models.UID, fn func(frame *models.RecordedSession) error) error
+	// SessionCreateRecordFrames stores the frames recorded on a session at once.
+	SessionCreateRecordFrames(ctx context.Context, frames []models.RecordedSession) error
+	// SessionDeleteRecordFrames deletes every frame recorded on a session. A session without frames is left as is.
+	SessionDeleteRecordFrames(ctx context.Context, uid models.UID) error
+	// SessionListUIDs lists the UIDs of every session of a namespace.
+	SessionListUIDs(ctx context.Context, tenantID string) ([]models.UID, error)
+	// SessionSetRecordUploadState sets the progress of the session's recording streamed to the server to state, as long
+	// as the progress stored is still from. A session without progress stored matches a from without bytes received.
+	// It returns [ErrNoDocuments] if the session does not exist or its progress is not from anymore.
+	SessionSetRecordUploadState(ctx context.Context, uid models.UID, from, state *models.RecordingUploadState) error
+	// SessionSetRecordingSummary sets the summary of the session's recording.
+	// It returns [ErrNoDocuments] if the session does not exist.
+	SessionSetRecordingSummary(ctx context.Context, uid models.UID, summary *models.RecordingSummary) error
+	// SessionSetStorage sets where the session's recording was exported to.
+	// It returns [ErrNoDocuments] if the session does not exist.
+	SessionSetStorage(ctx context.Context, uid models.UID, backend, location string) error
 }
@@ -8,4 +8,6 @@ import (
 
 type StatsStore interface {
 	GetStats(ctx context.Context) (*models.Stats, error)
+	// GetNamespaceStats counts the devices and the active sessions of the namespace with the specified tenant.
+	GetNamespaceStats(ctx context.Context, tenantID string) (*models.Stats, error)
 }
@@ -5,12 +5,19 @@ type Store interface {
 	TagsStore
 	DeviceStore
 	DeviceTagsStore
+	DeviceGroupStore
+	DeviceEventStore
+	DeviceConnectionStore
 	SessionStore
 	UserStore
+	UserSessionStore
 	NamespaceStore
+	InviteLinkStore
 	PublicKeyStore
 	PublicKeyTagsStore
 	PrivateKeyStore
 	StatsStore
 	APIKeyStore
+	FirewallRuleStore
+	WebhookStore
 }
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type UserSessionStore interface {
+	// UserSessionCreate creates a user session with the provided data. Returns an error if any.
+	UserSessionCreate(ctx context.Context, session *models.UserSession) error
+
+	// UserSessionGet retrieves the unexpired user session with the specified ID. Returns the session and an error if
+	// any, or ErrNoDocuments when the session does not exist or is expired.
+	UserSessionGet(ctx context.Context, id string) (*models.UserSession, error)
+
+	// UserSessionList retrieves the unexpired sessions of the user with the specified ID, the most recently active
+	// first.
+	UserSessionList(ctx context.Context, userID string) ([]models.UserSession, error)
+
+	// UserSessionUpdate updates the user session with the specified ID using the given changes. Returns an error if
+	// any, or ErrNoDocuments when the session does not exist.
+	UserSessionUpdate(ctx context.Context, id string, changes *models.UserSessionChanges) error
+
+	// UserSessionDelete deletes the session with the specified ID of the user with the specified ID. Returns an error
+	// if any, or ErrNoDocuments when the session does not exist.
+	UserSessionDelete(ctx context.Context, userID, id string) error
+
+	// UserSessionDeleteAll deletes every session of the user with the specified ID, logging out every token issued
+	// to the user. Returns an error if any.
+	UserSessionDeleteAll(ctx context.Context, userID string) error
+}
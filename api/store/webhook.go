@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type WebhookStore interface {
+	// WebhookDeadLetterCreate keeps an event that couldn't be delivered to the webhook of its namespace, setting its
+	// creation date to now.
+	WebhookDeadLetterCreate(ctx context.Context, letter *models.WebhookDeadLetter) error
+
+	// WebhookDeadLetterList returns the events that couldn't be delivered to the webhook of the namespace with the
+	// specified tenant, from the newest to the oldest, and their total number.
+	WebhookDeadLetterList(ctx context.Context, tenantID string, paginator query.Paginator) (letters []models.WebhookDeadLetter, count int, err error)
+}
@@ -0,0 +1,107 @@
+package workers
+
+import (
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// DeviceExpiryEvent is the event sent to the webhook when a device's access to its namespace has expired.
+const DeviceExpiryEvent = "device.access_expired"
+
+// DeviceExpiryNotice is the body of the webhook request notifying that a device's access to its namespace has expired.
+type DeviceExpiryNotice struct {
+	Event     string    `json:"event"`
+	TenantID  string    `json:"tenant_id"`
+	UID       string    `json:"uid"`
+	Name      string    `json:"name"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// registerDeviceExpiry worker is designed to reject the devices whose access to their namespaces has expired, sending
+// a notice to the webhook at `SHELLHUB_DEVICE_EXPIRY_WEBHOOK_URL` for each one. It uses a cron expression from
+// `SHELLHUB_DEVICE_EXPIRY_SCHEDULE` to schedule its periodic execution. The connections to an expired device are
+// refused by the SSH server even before it's rejected.
+func (w *Workers) registerDeviceExpiry() {
+	w.mux.HandleFunc(TaskDeviceExpiry, func(ctx context.Context, _ *asynq.Task) error {
+		log.WithFields(
+			log.Fields{
+				"component":       "worker",
+				"cron_expression": w.env.DeviceExpirySchedule,
+				"task":            TaskDeviceExpiry,
+			}).
+			Trace("Executing device expiry worker.")
+
+		return w.expireDevices(ctx, clock.Now())
+	})
+
+	task := asynq.NewTask(TaskDeviceExpiry, nil, asynq.TaskID(TaskDeviceExpiry), asynq.Queue("api"))
+	if _, err := w.scheduler.Register(w.env.DeviceExpirySchedule, task); err != nil {
+		log.WithFields(
+			log.Fields{
+				"component": "worker",
+				"task":      TaskDeviceExpiry,
+			}).
+			WithError(err).
+			Error("Failed to register the scheduler.")
+	}
+}
+
+// expireDevices rejects the devices whose access to their namespaces expired until now, notifying each one through the
+// webhook. A failed notice doesn't keep the device from being rejected.
+func (w *Workers) expireDevices(ctx context.Context, now time.Time) error {
+	var expired []models.Device
+	if err := w.retry.do(ctx, TaskDeviceExpiry, func() error {
+		var err error
+		expired, err = w.store.DeviceListExpired(ctx, now)
+
+		return err
+	}); err != nil {
+		log.WithFields(log.Fields{"component": "worker", "task": TaskDeviceExpiry}).
+			WithError(err).
+			Error("Failed to list the expired devices")
+
+		return err
+	}
+
+	expire := w.expireDevice
+	if expire == nil {
+		expire = func(ctx context.Context, _ string, uid models.UID) error {
+			return w.store.DeviceUpdateStatus(ctx, uid, models.DeviceStatusRejected)
+		}
+	}
+
+	for _, device := range expired {
+		logger := log.WithFields(log.Fields{"component": "worker", "task": TaskDeviceExpiry, "tenant_id": device.TenantID, "uid": device.UID})
+
+		if err := w.retry.do(ctx, TaskDeviceExpiry, func() error {
+			return expire(ctx, device.TenantID, models.UID(device.UID))
+		}); err != nil {
+			logger.WithError(err).Error("Failed to reject the expired device")
+
+			continue
+		}
+
+		logger.Info("Expired device rejected.")
+
+		if w.env.DeviceExpiryWebhookURL == "" {
+			continue
+		}
+
+		if err := postWebhook(ctx, w.env.DeviceExpiryWebhookURL, &DeviceExpiryNotice{
+			Event:     DeviceExpiryEvent,
+			TenantID:  device.TenantID,
+			UID:       device.UID,
+			Name:      device.Name,
+			ExpiresAt: *device.ExpiresAt,
+		}); err != nil {
+			logger.WithError(err).Error("Failed to notify about the device expiration")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpireDevices(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(-time.Hour)
+
+	type Expected struct {
+		notices []DeviceExpiryNotice
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		webhookStatus int
+		requiredMocks func(mock *mocks.Store)
+		expected      Expected
+	}{
+		{
+			description:   "fails when the expired devices could not be listed",
+			webhookStatus: http.StatusOK,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceListExpired", ctx, now).
+					Return(nil, errors.New("error")).
+					Once()
+			},
+			expected: Expected{notices: []DeviceExpiryNotice{}, err: errors.New("error")},
+		},
+		{
+			description:   "does not notify about a device that could not be rejected",
+			webhookStatus: http.StatusOK,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceListExpired", ctx, now).
+					Return([]models.Device{{UID: "uid", Name: "device", TenantID: "tenant", ExpiresAt: &expiresAt}}, nil).
+					Once()
+				mock.On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatusRejected).
+					Return(errors.New("error")).
+					Once()
+			},
+			expected: Expected{notices: []DeviceExpiryNotice{}, err: nil},
+		},
+		{
+			description:   "rejects the device even when the notice could not be sent",
+			webhookStatus: http.StatusInternalServerError,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceListExpired", ctx, now).
+					Return([]models.Device{{UID: "uid", Name: "device", TenantID: "tenant", ExpiresAt: &expiresAt}}, nil).
+					Once()
+				mock.On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatusRejected).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{
+				notices: []DeviceExpiryNotice{{Event: DeviceExpiryEvent, TenantID: "tenant", UID: "uid", Name: "device", ExpiresAt: expiresAt}},
+				err:     nil,
+			},
+		},
+		{
+			description:   "rejects and notifies about the expired devices",
+			webhookStatus: http.StatusOK,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceListExpired", ctx, now).
+					Return([]models.Device{
+						{UID: "uid", Name: "device", TenantID: "tenant", ExpiresAt: &expiresAt},
+						{UID: "uid2", Name: "device2", TenantID: "tenant", ExpiresAt: &now},
+					}, nil).
+					Once()
+				mock.On("DeviceUpdateStatus", ctx, models.UID("uid"), models.DeviceStatusRejected).
+					Return(nil).
+					Once()
+				mock.On("DeviceUpdateStatus", ctx, models.UID("uid2"), models.DeviceStatusRejected).
+					Return(nil).
+					Once()
+			},
+			expected: Expected{
+				notices: []DeviceExpiryNotice{
+					{Event: DeviceExpiryEvent, TenantID: "tenant", UID: "uid", Name: "device", ExpiresAt: expiresAt},
+					{Event: DeviceExpiryEvent, TenantID: "tenant", UID: "uid2", Name: "device2", ExpiresAt: now},
+				},
+				err: nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			notices := []DeviceExpiryNotice{}
+			webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var notice DeviceExpiryNotice
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&notice))
+				notices = append(notices, notice)
+
+				w.WriteHeader(tc.webhookStatus)
+			}))
+			t.Cleanup(webhook.Close)
+
+			mock := new(mocks.Store)
+			tc.requiredMocks(mock)
+
+			w := &Workers{store: mock, env: &Envs{DeviceExpiryWebhookURL: webhook.URL}}
+			err := w.expireDevices(ctx, now)
+
+			assert.Equal(t, tc.expected, Expected{notices: notices, err: err})
+			mock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExpireDevicesWithExpirer(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(-time.Hour)
+
+	mock := new(mocks.Store)
+	mock.On("DeviceListExpired", ctx, now).
+		Return([]models.Device{{UID: "uid", Name: "device", TenantID: "tenant", ExpiresAt: &expiresAt}}, nil).
+		Once()
+
+	expired := []models.UID{}
+	w := &Workers{store: mock, env: &Envs{}, expireDevice: func(_ context.Context, tenantID string, uid models.UID) error {
+		assert.Equal(t, "tenant", tenantID)
+		expired = append(expired, uid)
+
+		return nil
+	}}
+
+	assert.NoError(t, w.expireDevices(ctx, now))
+	assert.Equal(t, []models.UID{"uid"}, expired)
+	mock.AssertExpectations(t)
+}
@@ -6,13 +6,15 @@
 //
 // The `sessionCleanup` worker is designed to delete recorded sessions older than a specified number
 // of days. The retention period is determined by the value of the `SHELLHUB_RECORD_RETENTION` environment
-// variable. To disable this worker, set `SHELLHUB_RECORD_RETENTION` to 0 (default behavior). It uses
-// a cron expression from `SHELLHUB_RECORD_RETENTION` to schedule its periodic execution.
+// variable. When `SHELLHUB_SESSION_RETENTION` is set, the sessions older than it are deleted as well, independently
+// of the records retention. To disable this worker, set both to 0 (default behavior). It uses
+// a cron expression from `SHELLHUB_SESSION_RECORD_CLEANUP_SCHEDULE` to schedule its periodic execution.
 //
 // The `heartbeat` worker manages heartbeat tasks, signaling the online status of devices.
 // It aggregates heartbeat data and updates the online status of devices accordingly.
 // The maximum number of devices to wait for before triggering is defined by the `SHELLHUB_ASYNQ_GROUP_MAX_SIZE` (default is 500).
 // Another triggering mechanism involves a timeout defined in the `SHELLHUB_ASYNQ_GROUP_MAX_DELAY` environment variable.
 //
-// The patterns of tasks used by the handlers are available as constants with the "Task" prefix.
+// The patterns of tasks used by the handlers are available as constants with the "Task" prefix, and the schema version
+// of their payloads as constants with the "PayloadVersion" suffix.
 package workers
@@ -4,91 +4,215 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	ErrHeartbeatVersion   = errors.New("unsupported heartbeat payload version")
+	ErrHeartbeatEqual     = errors.New("failed to parse queue payload due to lack of '='")
+	ErrHeartbeatColon     = errors.New("failed to parse queue payload due to lack of ':'")
+	ErrHeartbeatTimestamp = errors.New("failed to parse timestamp to integer")
+	ErrHeartbeatFields    = errors.New("failed to parse queue payload due to malformed or missing fields")
+)
+
 // heartbeat worker manages heartbeat tasks, signaling the online status of devices.
 // It aggregates heartbeat data and updates the online status of devices accordingly.
 // The maximum number of devices to wait for before triggering is defined by the `SHELLHUB_ASYNQ_GROUP_MAX_SIZE` (default is 500).
 // Another triggering mechanism involves a timeout defined in the `SHELLHUB_ASYNQ_GROUP_MAX_DELAY` environment variable.
+//
+// The devices are set as online retrying the store while the database is unreachable; when it keeps failing, the task
+// fails and is retried by asynq, so the heartbeats aren't lost. A device that can't be set as online is only logged,
+// without affecting the other ones. The namespaces of the devices are renewed too, keeping them from expiring.
 func (w *Workers) registerHeartbeat() {
-	w.mux.HandleFunc(TaskHeartbeat, func(ctx context.Context, task *asynq.Task) error {
+	w.mux.HandleFunc(TaskHeartbeat, w.heartbeat)
+}
+
+func (w *Workers) heartbeat(ctx context.Context, task *asynq.Task) error {
+	log.
+		WithFields(log.Fields{
+			"component": "worker",
+			"task":      TaskHeartbeat,
+		}).
+		Trace("Executing heartbeat worker.")
+
+	scanner := bufio.NewScanner(bytes.NewReader(task.Payload()))
+	scanner.Split(bufio.ScanLines)
+
+	devices := make([]models.ConnectedDevice, 0)
+	for scanner.Scan() {
+		device, err := parseHeartbeat(scanner.Text())
+		if err != nil {
+			log.WithFields(
+				log.Fields{
+					"component": "worker",
+					"task":      TaskHeartbeat,
+				}).
+				WithError(err).
+				Warn("failed to parse the heartbeat payload.")
+
+			continue
+		}
+
+		devices = append(devices, *device)
+	}
+
+	err := w.retry.do(ctx, TaskHeartbeat, func() error {
+		return w.store.DeviceSetOnline(ctx, devices)
+	})
+
+	// NOTICE: when only some of the devices fail, the other ones were set as online, so the task isn't retried, what
+	// would fail the same devices again.
+	var partial *store.DeviceSetOnlineError
+	if errors.As(err, &partial) {
 		log.
+			WithError(partial.Err).
 			WithFields(log.Fields{
 				"component": "worker",
 				"task":      TaskHeartbeat,
+				"uids":      partial.UIDs,
 			}).
-			Trace("Executing heartbeat worker.")
-
-		scanner := bufio.NewScanner(bytes.NewReader(task.Payload()))
-		scanner.Split(bufio.ScanLines)
-
-		devices := make([]models.ConnectedDevice, 0)
-		for scanner.Scan() {
-			parts := strings.Split(scanner.Text(), "=")
-			if len(parts) != 2 {
-				log.WithFields(
-					log.Fields{
-						"component": "worker",
-						"task":      TaskHeartbeat,
-					}).
-					Warn("failed to parse queue payload due to lack of '='.")
-
-				continue
-			}
-
-			lastSeen, err := strconv.ParseInt(parts[1], 10, 64)
-			if err != nil {
-				log.WithFields(
-					log.Fields{
-						"component": "worker",
-						"task":      TaskHeartbeat,
-					}).
-					WithError(err).
-					Warn("failed to parse timestamp to integer.")
-
-				continue
-			}
-
-			parts = strings.Split(parts[0], ":")
-			if len(parts) != 2 {
-				log.WithFields(
-					log.Fields{
-						"component": "worker",
-						"task":      TaskHeartbeat,
-					}).
-					Warn("failed to parse queue payload due to lack of ':'.")
-
-				continue
-			}
-
-			device := models.ConnectedDevice{
-				UID:      parts[1],
-				TenantID: parts[0],
-				LastSeen: time.Unix(lastSeen, 0),
-			}
-
-			devices = append(devices, device)
-		}
+			Warn("failed to set some devices as online")
 
-		if err := w.store.DeviceSetOnline(ctx, devices); err != nil {
-			log.
-				WithError(err).
-				WithFields(log.Fields{
-					"component": "worker",
-					"task":      TaskHeartbeat,
-				}).
-				Error("failed to set devices as online")
+		w.renewNamespaces(ctx, devices)
+
+		return nil
+	}
+
+	if err != nil {
+		log.
+			WithError(err).
+			WithFields(log.Fields{
+				"component": "worker",
+				"task":      TaskHeartbeat,
+			}).
+			Error("failed to set devices as online")
+
+		return err
+	}
 
-			return err
+	w.renewNamespaces(ctx, devices)
+
+	return nil
+}
+
+// namespaceRenewInterval is how often the heartbeats of the devices of a namespace renew it, keeping it from expiring.
+const namespaceRenewInterval = time.Hour
+
+// renewNamespaces renews the namespaces of the devices, as active at their most recent heartbeat, at most once every
+// [namespaceRenewInterval]. A failure is only logged, as the devices were already set as online.
+func (w *Workers) renewNamespaces(ctx context.Context, devices []models.ConnectedDevice) {
+	if len(devices) == 0 {
+		return
+	}
+
+	seen := make(map[string]bool)
+	tenants := make([]string, 0)
+	var activeAt time.Time
+	for _, device := range devices {
+		if !seen[device.TenantID] {
+			seen[device.TenantID] = true
+			tenants = append(tenants, device.TenantID)
 		}
 
-		return nil
-	})
+		if device.LastSeen.After(activeAt) {
+			activeAt = device.LastSeen
+		}
+	}
+
+	if err := w.retry.do(ctx, TaskHeartbeat, func() error {
+		return w.store.NamespaceRenewMany(ctx, tenants, activeAt.Add(-namespaceRenewInterval), activeAt)
+	}); err != nil {
+		log.
+			WithError(err).
+			WithFields(log.Fields{
+				"component": "worker",
+				"task":      TaskHeartbeat,
+			}).
+			Warn("failed to renew the namespaces of the devices")
+	}
+}
+
+// parseHeartbeat parses a single heartbeat payload, detecting its version from its prefix. The "v1" payloads, without
+// a prefix, are parsed as the legacy "tenant:uid=timestamp" format, while the current one is parsed as a query string
+// of its fields, so fields can be added without breaking the producers not yet upgraded. The unknown fields are
+// ignored.
+func parseHeartbeat(payload string) (*models.ConnectedDevice, error) {
+	version, content := payloadVersion(payload)
+
+	var device *models.ConnectedDevice
+	var err error
+	switch version {
+	case PayloadVersionV1:
+		device, err = parseLegacyHeartbeat(content)
+	case HeartbeatPayloadVersion:
+		device, err = parseHeartbeatFields(content)
+	default:
+		return nil, ErrHeartbeatVersion
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	device.Protocol = version
+
+	return device, nil
+}
+
+// parseLegacyHeartbeat parses the content of a heartbeat payload in the "tenant:uid=timestamp" format.
+func parseLegacyHeartbeat(content string) (*models.ConnectedDevice, error) {
+	parts := strings.Split(content, "=")
+	if len(parts) != 2 {
+		return nil, ErrHeartbeatEqual
+	}
+
+	lastSeen, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrHeartbeatTimestamp
+	}
+
+	parts = strings.Split(parts[0], ":")
+	if len(parts) != 2 {
+		return nil, ErrHeartbeatColon
+	}
+
+	return &models.ConnectedDevice{
+		UID:      parts[1],
+		TenantID: parts[0],
+		LastSeen: time.Unix(lastSeen, 0),
+	}, nil
+}
+
+// parseHeartbeatFields parses the content of a heartbeat payload encoded as a query string, requiring its "tenant_id",
+// "uid" and "last_seen" fields.
+func parseHeartbeatFields(content string) (*models.ConnectedDevice, error) {
+	fields, err := url.ParseQuery(content)
+	if err != nil {
+		return nil, ErrHeartbeatFields
+	}
+
+	tenant, uid := fields.Get("tenant_id"), fields.Get("uid")
+	if tenant == "" || uid == "" || !fields.Has("last_seen") {
+		return nil, ErrHeartbeatFields
+	}
+
+	lastSeen, err := strconv.ParseInt(fields.Get("last_seen"), 10, 64)
+	if err != nil {
+		return nil, ErrHeartbeatTimestamp
+	}
+
+	return &models.ConnectedDevice{
+		UID:      uid,
+		TenantID: tenant,
+		LastSeen: time.Unix(lastSeen, 0),
+	}, nil
 }
@@ -0,0 +1,218 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHeartbeat(t *testing.T) {
+	type Expected struct {
+		device *models.ConnectedDevice
+		err    error
+	}
+
+	cases := []struct {
+		description string
+		payload     string
+		expected    Expected
+	}{
+		{
+			description: "succeeds parsing a v1 payload",
+			payload:     "00000000-0000-4000-0000-000000000000:uid=1700000000",
+			expected: Expected{
+				device: &models.ConnectedDevice{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000", LastSeen: time.Unix(1700000000, 0), Protocol: "v1"},
+				err:    nil,
+			},
+		},
+		{
+			description: "succeeds parsing a v2 payload",
+			payload:     "v2:tenant_id=00000000-0000-4000-0000-000000000000&uid=uid&last_seen=1700000000",
+			expected: Expected{
+				device: &models.ConnectedDevice{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000", LastSeen: time.Unix(1700000000, 0), Protocol: "v2"},
+				err:    nil,
+			},
+		},
+		{
+			description: "succeeds parsing a v2 payload with unknown fields",
+			payload:     "v2:load=0.5&tenant_id=00000000-0000-4000-0000-000000000000&uid=uid&last_seen=1700000000&agent_version=v0.20.0",
+			expected: Expected{
+				device: &models.ConnectedDevice{UID: "uid", TenantID: "00000000-0000-4000-0000-000000000000", LastSeen: time.Unix(1700000000, 0), Protocol: "v2"},
+				err:    nil,
+			},
+		},
+		{
+			description: "fails when a v2 payload lacks a field",
+			payload:     "v2:tenant_id=00000000-0000-4000-0000-000000000000&last_seen=1700000000",
+			expected:    Expected{device: nil, err: ErrHeartbeatFields},
+		},
+		{
+			description: "fails when a v2 payload is malformed",
+			payload:     "v2:tenant_id=%zz&uid=uid&last_seen=1700000000",
+			expected:    Expected{device: nil, err: ErrHeartbeatFields},
+		},
+		{
+			description: "fails when the timestamp of a v2 payload is not an integer",
+			payload:     "v2:tenant_id=00000000-0000-4000-0000-000000000000&uid=uid&last_seen=now",
+			expected:    Expected{device: nil, err: ErrHeartbeatTimestamp},
+		},
+		{
+			description: "fails when the version is not supported",
+			payload:     "v3:tenant_id=00000000-0000-4000-0000-000000000000&uid=uid&last_seen=1700000000",
+			expected:    Expected{device: nil, err: ErrHeartbeatVersion},
+		},
+		{
+			description: "fails when there is no '='",
+			payload:     "00000000-0000-4000-0000-000000000000:uid",
+			expected:    Expected{device: nil, err: ErrHeartbeatEqual},
+		},
+		{
+			description: "fails when the timestamp is not an integer",
+			payload:     "00000000-0000-4000-0000-000000000000:uid=now",
+			expected:    Expected{device: nil, err: ErrHeartbeatTimestamp},
+		},
+		{
+			description: "fails when there is no ':'",
+			payload:     "uid=1700000000",
+			expected:    Expected{device: nil, err: ErrHeartbeatColon},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			device, err := parseHeartbeat(tc.payload)
+			assert.Equal(t, tc.expected, Expected{device: device, err: err})
+		})
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.Background()
+
+	// The same aggregated task carries payloads enqueued by producers using different versions.
+	task := aggregate("heartbeats", []*asynq.Task{
+		asynq.NewTask(TaskHeartbeat, []byte("00000000-0000-4000-0000-000000000000:uid-1=1700000000")),
+		asynq.NewTask(TaskHeartbeat, []byte("v2:tenant_id=00000000-0000-4000-0000-000000000000&uid=uid-2&last_seen=1700000001")),
+		asynq.NewTask(TaskHeartbeat, []byte("v9:00000000-0000-4000-0000-000000000000:uid-3=1700000002")),
+	})
+
+	devices := []models.ConnectedDevice{
+		{UID: "uid-1", TenantID: "00000000-0000-4000-0000-000000000000", LastSeen: time.Unix(1700000000, 0), Protocol: "v1"},
+		{UID: "uid-2", TenantID: "00000000-0000-4000-0000-000000000000", LastSeen: time.Unix(1700000001, 0), Protocol: "v2"},
+	}
+
+	tenants := []string{"00000000-0000-4000-0000-000000000000"}
+	activeAt := time.Unix(1700000001, 0)
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when cannot set the devices as online",
+			requiredMocks: func() {
+				mock.On("DeviceSetOnline", ctx, devices).Return(errors.New("error")).Twice()
+			},
+			expected: errors.New("error"),
+		},
+		{
+			description: "succeeds setting the v1 and v2 devices as online",
+			requiredMocks: func() {
+				mock.On("DeviceSetOnline", ctx, devices).Return(nil).Once()
+				mock.On("NamespaceRenewMany", ctx, tenants, activeAt.Add(-namespaceRenewInterval), activeAt).Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds when only some of the devices cannot be set as online",
+			requiredMocks: func() {
+				mock.On("DeviceSetOnline", ctx, devices).
+					Return(&store.DeviceSetOnlineError{UIDs: []string{"uid-2"}, Err: errors.New("error")}).
+					Once()
+				mock.On("NamespaceRenewMany", ctx, tenants, activeAt.Add(-namespaceRenewInterval), activeAt).Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds setting the devices as online after the store recovers",
+			requiredMocks: func() {
+				mock.On("DeviceSetOnline", ctx, devices).Return(errors.New("error")).Once()
+				mock.On("DeviceSetOnline", ctx, devices).Return(nil).Once()
+				mock.On("NamespaceRenewMany", ctx, tenants, activeAt.Add(-namespaceRenewInterval), activeAt).Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "succeeds when the namespaces of the devices cannot be renewed",
+			requiredMocks: func() {
+				mock.On("DeviceSetOnline", ctx, devices).Return(nil).Once()
+				mock.On("NamespaceRenewMany", ctx, tenants, activeAt.Add(-namespaceRenewInterval), activeAt).
+					Return(errors.New("error")).
+					Twice()
+			},
+			expected: nil,
+		},
+	}
+
+	w := &Workers{store: mock, retry: retry{attempts: 2, delay: time.Millisecond}}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			assert.Equal(t, tc.expected, w.heartbeat(ctx, task))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestHeartbeatIsolatesFailedDevices(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.Background()
+
+	tasks := make([]*asynq.Task, 0, 10)
+	devices := make([]models.ConnectedDevice, 0, 10)
+	for i := 0; i < 10; i++ {
+		uid := "uid-" + strconv.Itoa(i)
+
+		tasks = append(tasks, asynq.NewTask(TaskHeartbeat, []byte("v2:tenant_id=00000000-0000-4000-0000-000000000000&uid="+uid+"&last_seen=1700000000")))
+		devices = append(devices, models.ConnectedDevice{UID: uid, TenantID: "00000000-0000-4000-0000-000000000000", LastSeen: time.Unix(1700000000, 0), Protocol: "v2"})
+	}
+
+	// NOTICE: the store is called only once, as retrying would fail the same device again.
+	mock.On("DeviceSetOnline", ctx, devices).
+		Return(&store.DeviceSetOnlineError{UIDs: []string{"uid-4"}, Err: errors.New("error")}).
+		Once()
+	mock.On("NamespaceRenewMany", ctx, []string{"00000000-0000-4000-0000-000000000000"}, time.Unix(1700000000, 0).Add(-namespaceRenewInterval), time.Unix(1700000000, 0)).
+		Return(nil).
+		Once()
+
+	w := &Workers{store: mock, retry: retry{attempts: 3, delay: time.Millisecond}}
+
+	assert.NoError(t, w.heartbeat(ctx, aggregate("heartbeats", tasks)))
+
+	mock.AssertExpectations(t)
+}
+
+func TestAggregate(t *testing.T) {
+	task := aggregate("heartbeats", []*asynq.Task{
+		asynq.NewTask(TaskHeartbeat, []byte("tenant:uid-1=1")),
+		asynq.NewTask(TaskHeartbeat, []byte("v2:tenant_id=tenant&uid=uid-2&last_seen=2")),
+	})
+
+	assert.Equal(t, TaskHeartbeat, task.Type())
+	assert.Equal(t, "tenant:uid-1=1\nv2:tenant_id=tenant&uid=uid-2&last_seen=2\n", string(task.Payload()))
+}
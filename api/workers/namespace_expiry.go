@@ -0,0 +1,189 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/shellhub-io/shellhub/api/pkg/mailer"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/envs"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// NamespaceExpiryWarningAfter is how long a free-tier namespace stays inactive before its members are warned that
+	// it will expire.
+	NamespaceExpiryWarningAfter = 75 * 24 * time.Hour
+	// NamespaceExpiryAfter is how long a free-tier namespace stays inactive before it is deleted.
+	NamespaceExpiryAfter = 90 * 24 * time.Hour
+	// NamespaceExpiryMinNotice is the minimum time between the warning and the deletion of a namespace, what only
+	// matters to the namespaces already inactive for about NamespaceExpiryAfter when first found, like the ones that
+	// were inactive before the expiration existed.
+	NamespaceExpiryMinNotice = 7 * 24 * time.Hour
+)
+
+// NamespaceExpiryEvent is the event sent to the webhook when an inactive namespace is set to expire.
+const NamespaceExpiryEvent = "namespace.expiring"
+
+// NamespaceExpiryWarning is the body of the webhook request warning that a namespace will expire.
+type NamespaceExpiryWarning struct {
+	Event        string    `json:"event"`
+	TenantID     string    `json:"tenant_id"`
+	Name         string    `json:"name"`
+	Owner        string    `json:"owner"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// registerNamespaceExpiry worker is designed to delete the free-tier namespaces inactive for
+// [NamespaceExpiryAfter]. Namespaces inactive for [NamespaceExpiryWarningAfter] are set to expire, and a warning is
+// sent to the webhook at `SHELLHUB_NAMESPACE_EXPIRY_WEBHOOK_URL`, or emailed to the owner when there is no webhook;
+// any activity on the namespace, like a device heartbeat or an authenticated request, renews it. It only
+// runs on cloud instances and uses a cron expression from `SHELLHUB_NAMESPACE_EXPIRY_SCHEDULE` to schedule its
+// periodic execution.
+func (w *Workers) registerNamespaceExpiry() {
+	if !envs.IsCloud() {
+		return
+	}
+
+	w.mux.HandleFunc(TaskNamespaceExpiry, func(ctx context.Context, _ *asynq.Task) error {
+		log.WithFields(
+			log.Fields{
+				"component":       "worker",
+				"cron_expression": w.env.NamespaceExpirySchedule,
+				"task":            TaskNamespaceExpiry,
+			}).
+			Trace("Executing namespace expiry worker.")
+
+		return w.expireNamespaces(ctx, clock.Now())
+	})
+
+	task := asynq.NewTask(TaskNamespaceExpiry, nil, asynq.TaskID(TaskNamespaceExpiry), asynq.Queue("api"))
+	if _, err := w.scheduler.Register(w.env.NamespaceExpirySchedule, task); err != nil {
+		log.WithFields(
+			log.Fields{
+				"component": "worker",
+				"task":      TaskNamespaceExpiry,
+			}).
+			WithError(err).
+			Error("Failed to register the scheduler.")
+	}
+}
+
+// expireNamespaces sets the free-tier namespaces inactive for [NamespaceExpiryWarningAfter] at now to expire, warning
+// about it through the webhook or the email, and deletes the ones whose expiration date is before now. A namespace is only set to
+// expire after the warning is sent, so it is never deleted without notice.
+func (w *Workers) expireNamespaces(ctx context.Context, now time.Time) error {
+	var inactive []models.Namespace
+	if err := w.retry.do(ctx, TaskNamespaceExpiry, func() error {
+		var err error
+		inactive, err = w.store.NamespaceListInactive(ctx, now.Add(-NamespaceExpiryWarningAfter))
+
+		return err
+	}); err != nil {
+		log.WithFields(log.Fields{"component": "worker", "task": TaskNamespaceExpiry}).
+			WithError(err).
+			Error("Failed to list the inactive namespaces")
+
+		return err
+	}
+
+	for _, ns := range inactive {
+		expiresAt := ns.LastActiveAt.Add(NamespaceExpiryAfter)
+		if earliest := now.Add(NamespaceExpiryMinNotice); expiresAt.Before(earliest) {
+			expiresAt = earliest
+		}
+
+		logger := log.WithFields(log.Fields{"component": "worker", "task": TaskNamespaceExpiry, "tenant_id": ns.TenantID})
+
+		if err := w.warnNamespaceExpiry(ctx, &NamespaceExpiryWarning{
+			Event:        NamespaceExpiryEvent,
+			TenantID:     ns.TenantID,
+			Name:         ns.Name,
+			Owner:        ns.Owner,
+			LastActiveAt: *ns.LastActiveAt,
+			ExpiresAt:    expiresAt,
+		}); err != nil {
+			logger.WithError(err).Error("Failed to warn about the namespace expiration")
+
+			continue
+		}
+
+		if err := w.retry.do(ctx, TaskNamespaceExpiry, func() error {
+			return w.store.NamespaceSetExpiresAt(ctx, ns.TenantID, expiresAt)
+		}); err != nil {
+			logger.WithError(err).Error("Failed to set the namespace expiration")
+
+			continue
+		}
+
+		logger.WithField("expires_at", expiresAt.String()).Info("Namespace set to expire.")
+	}
+
+	var expired []models.Namespace
+	if err := w.retry.do(ctx, TaskNamespaceExpiry, func() error {
+		var err error
+		expired, err = w.store.NamespaceListExpired(ctx, now)
+
+		return err
+	}); err != nil {
+		log.WithFields(log.Fields{"component": "worker", "task": TaskNamespaceExpiry}).
+			WithError(err).
+			Error("Failed to list the expired namespaces")
+
+		return err
+	}
+
+	expire := w.expireNamespace
+	if expire == nil {
+		expire = w.store.NamespaceDelete
+	}
+
+	for _, ns := range expired {
+		logger := log.WithFields(log.Fields{"component": "worker", "task": TaskNamespaceExpiry, "tenant_id": ns.TenantID})
+
+		if err := w.retry.do(ctx, TaskNamespaceExpiry, func() error {
+			return expire(ctx, ns.TenantID)
+		}); err != nil {
+			logger.WithError(err).Error("Failed to delete the expired namespace")
+
+			continue
+		}
+
+		logger.Info("Expired namespace deleted.")
+	}
+
+	return nil
+}
+
+// ErrNamespaceExpiryNoWarning is returned when there is no way to warn about a namespace expiration, as neither the
+// webhook nor the SMTP server is configured.
+var ErrNamespaceExpiryNoWarning = errors.New("neither the webhook nor the SMTP server is configured to warn about the namespace expiration")
+
+// warnNamespaceExpiry posts the warning to the namespace expiry webhook. When no webhook is configured, the warning is
+// emailed to the namespace owner instead; when the SMTP server isn't configured either, it fails with
+// [ErrNamespaceExpiryNoWarning], so the namespace isn't set to expire.
+func (w *Workers) warnNamespaceExpiry(ctx context.Context, warning *NamespaceExpiryWarning) error {
+	if w.env.NamespaceExpiryWebhookURL != "" {
+		return postWebhook(ctx, w.env.NamespaceExpiryWebhookURL, warning)
+	}
+
+	if w.mailer == nil {
+		return ErrNamespaceExpiryNoWarning
+	}
+
+	owner, _, err := w.store.UserGetByID(ctx, warning.Owner, false)
+	if err != nil {
+		return err
+	}
+
+	return mailer.SendNamespaceExpiryWarning(ctx, w.mailer, owner.Email, &mailer.NamespaceExpiryWarning{
+		Namespace:    warning.Name,
+		TenantID:     warning.TenantID,
+		LastActiveAt: warning.LastActiveAt,
+		ExpiresAt:    warning.ExpiresAt,
+	})
+}
@@ -0,0 +1,250 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mailermocks "github.com/shellhub-io/shellhub/api/pkg/mailer/mocks"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExpireNamespaces(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		at := now.AddDate(0, 0, -days)
+
+		return &at
+	}
+
+	type Expected struct {
+		warnings []NamespaceExpiryWarning
+		err      error
+	}
+
+	cases := []struct {
+		description   string
+		webhookStatus int
+		// withoutWebhook leaves the webhook unconfigured, so the warnings are emailed through the mailer mocked by
+		// mailerMocks, when not nil.
+		withoutWebhook bool
+		mailerMocks    func(m *mailermocks.Mailer)
+		requiredMocks  func(mock *mocks.Store)
+		expected       Expected
+	}{
+		{
+			description:   "fails when the inactive namespaces could not be listed",
+			webhookStatus: http.StatusOK,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("NamespaceListInactive", ctx, now.Add(-NamespaceExpiryWarningAfter)).
+					Return(nil, errors.New("error")).
+					Once()
+			},
+			expected: Expected{warnings: []NamespaceExpiryWarning{}, err: errors.New("error")},
+		},
+		{
+			description:   "warns and sets to expire 90 days after the last activity a namespace inactive for 75 days",
+			webhookStatus: http.StatusOK,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("NamespaceListInactive", ctx, now.Add(-NamespaceExpiryWarningAfter)).
+					Return([]models.Namespace{{TenantID: "tenant", Name: "namespace", Owner: "owner", LastActiveAt: daysAgo(75)}}, nil).
+					Once()
+				mock.On("NamespaceSetExpiresAt", ctx, "tenant", now.AddDate(0, 0, 15)).
+					Return(nil).
+					Once()
+				mock.On("NamespaceListExpired", ctx, now).
+					Return([]models.Namespace{}, nil).
+					Once()
+			},
+			expected: Expected{
+				warnings: []NamespaceExpiryWarning{
+					{
+						Event:        NamespaceExpiryEvent,
+						TenantID:     "tenant",
+						Name:         "namespace",
+						Owner:        "owner",
+						LastActiveAt: *daysAgo(75),
+						ExpiresAt:    now.AddDate(0, 0, 15),
+					},
+				},
+				err: nil,
+			},
+		},
+		{
+			description:   "gives the minimum notice to a namespace already inactive for more than 75 days",
+			webhookStatus: http.StatusOK,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("NamespaceListInactive", ctx, now.Add(-NamespaceExpiryWarningAfter)).
+					Return([]models.Namespace{{TenantID: "tenant", Name: "namespace", Owner: "owner", LastActiveAt: daysAgo(120)}}, nil).
+					Once()
+				mock.On("NamespaceSetExpiresAt", ctx, "tenant", now.Add(NamespaceExpiryMinNotice)).
+					Return(nil).
+					Once()
+				mock.On("NamespaceListExpired", ctx, now).
+					Return([]models.Namespace{}, nil).
+					Once()
+			},
+			expected: Expected{
+				warnings: []NamespaceExpiryWarning{
+					{
+						Event:        NamespaceExpiryEvent,
+						TenantID:     "tenant",
+						Name:         "namespace",
+						Owner:        "owner",
+						LastActiveAt: *daysAgo(120),
+						ExpiresAt:    now.Add(NamespaceExpiryMinNotice),
+					},
+				},
+				err: nil,
+			},
+		},
+		{
+			description:   "does not set to expire a namespace whose warning could not be sent",
+			webhookStatus: http.StatusInternalServerError,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("NamespaceListInactive", ctx, now.Add(-NamespaceExpiryWarningAfter)).
+					Return([]models.Namespace{{TenantID: "tenant", Name: "namespace", Owner: "owner", LastActiveAt: daysAgo(80)}}, nil).
+					Once()
+				mock.On("NamespaceListExpired", ctx, now).
+					Return([]models.Namespace{}, nil).
+					Once()
+			},
+			expected: Expected{
+				warnings: []NamespaceExpiryWarning{
+					{
+						Event:        NamespaceExpiryEvent,
+						TenantID:     "tenant",
+						Name:         "namespace",
+						Owner:        "owner",
+						LastActiveAt: *daysAgo(80),
+						ExpiresAt:    now.AddDate(0, 0, 10),
+					},
+				},
+				err: nil,
+			},
+		},
+		{
+			description:    "emails the warning to the owner when there is no webhook",
+			withoutWebhook: true,
+			mailerMocks: func(m *mailermocks.Mailer) {
+				m.On("Send", ctx, "owner@shellhub.io", "namespace will expire", mock.AnythingOfType("string")).
+					Return(nil).
+					Once()
+			},
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("NamespaceListInactive", ctx, now.Add(-NamespaceExpiryWarningAfter)).
+					Return([]models.Namespace{{TenantID: "tenant", Name: "namespace", Owner: "owner", LastActiveAt: daysAgo(75)}}, nil).
+					Once()
+				mock.On("UserGetByID", ctx, "owner", false).
+					Return(&models.User{ID: "owner", UserData: models.UserData{Email: "owner@shellhub.io"}}, 0, nil).
+					Once()
+				mock.On("NamespaceSetExpiresAt", ctx, "tenant", now.AddDate(0, 0, 15)).
+					Return(nil).
+					Once()
+				mock.On("NamespaceListExpired", ctx, now).
+					Return([]models.Namespace{}, nil).
+					Once()
+			},
+			expected: Expected{warnings: []NamespaceExpiryWarning{}, err: nil},
+		},
+		{
+			description:    "does not set to expire a namespace when there is no way to warn about it",
+			withoutWebhook: true,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("NamespaceListInactive", ctx, now.Add(-NamespaceExpiryWarningAfter)).
+					Return([]models.Namespace{{TenantID: "tenant", Name: "namespace", Owner: "owner", LastActiveAt: daysAgo(75)}}, nil).
+					Once()
+				mock.On("NamespaceListExpired", ctx, now).
+					Return([]models.Namespace{}, nil).
+					Once()
+			},
+			expected: Expected{warnings: []NamespaceExpiryWarning{}, err: nil},
+		},
+		{
+			description:   "deletes the namespaces inactive for 90 days",
+			webhookStatus: http.StatusOK,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("NamespaceListInactive", ctx, now.Add(-NamespaceExpiryWarningAfter)).
+					Return([]models.Namespace{}, nil).
+					Once()
+				mock.On("NamespaceListExpired", ctx, now).
+					Return([]models.Namespace{{TenantID: "expired", LastActiveAt: daysAgo(90)}, {TenantID: "failing", LastActiveAt: daysAgo(91)}}, nil).
+					Once()
+				mock.On("NamespaceDelete", ctx, "failing").
+					Return(errors.New("error")).
+					Once()
+				mock.On("NamespaceDelete", ctx, "expired").
+					Return(nil).
+					Once()
+			},
+			expected: Expected{warnings: []NamespaceExpiryWarning{}, err: nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			warnings := []NamespaceExpiryWarning{}
+			webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var warning NamespaceExpiryWarning
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&warning))
+				warnings = append(warnings, warning)
+
+				w.WriteHeader(tc.webhookStatus)
+			}))
+			t.Cleanup(webhook.Close)
+
+			mock := new(mocks.Store)
+			tc.requiredMocks(mock)
+
+			w := &Workers{store: mock, env: &Envs{NamespaceExpiryWebhookURL: webhook.URL}}
+			if tc.withoutWebhook {
+				w.env.NamespaceExpiryWebhookURL = ""
+			}
+
+			if tc.mailerMocks != nil {
+				m := mailermocks.NewMailer(t)
+				tc.mailerMocks(m)
+				w.mailer = m
+			}
+			err := w.expireNamespaces(ctx, now)
+
+			assert.Equal(t, tc.expected, Expected{warnings: warnings, err: err})
+			mock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExpireNamespacesWithExpirer(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	lastActiveAt := now.AddDate(0, 0, -90)
+
+	mock := new(mocks.Store)
+	mock.On("NamespaceListInactive", ctx, now.Add(-NamespaceExpiryWarningAfter)).
+		Return([]models.Namespace{}, nil).
+		Once()
+	mock.On("NamespaceListExpired", ctx, now).
+		Return([]models.Namespace{{TenantID: "expired", LastActiveAt: &lastActiveAt}}, nil).
+		Once()
+
+	expired := []string{}
+	w := &Workers{store: mock, env: &Envs{}, expireNamespace: func(_ context.Context, tenantID string) error {
+		expired = append(expired, tenantID)
+
+		return nil
+	}}
+
+	assert.NoError(t, w.expireNamespaces(ctx, now))
+	assert.Equal(t, []string{"expired"}, expired)
+	mock.AssertExpectations(t)
+}
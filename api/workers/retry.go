@@ -0,0 +1,61 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// retry is how the store calls of the workers are retried when they fail, what mostly happens while the database is
+// briefly unreachable. Its zero value calls the store only once.
+type retry struct {
+	// attempts is the maximum number of calls, including the first one.
+	attempts int
+	// delay is the pause before the first retry, doubled on every next one up to maxDelay.
+	delay    time.Duration
+	maxDelay time.Duration
+}
+
+// permanent reports whether the store error cannot be solved by retrying the call, like when the document doesn't
+// exist or only some of the devices failed to be set as online.
+func permanent(err error) bool {
+	var partial *store.DeviceSetOnlineError
+
+	return errors.Is(err, store.ErrNoDocuments) || errors.Is(err, store.ErrDuplicate) || errors.Is(err, store.ErrInvalidHex) ||
+		errors.As(err, &partial)
+}
+
+// do calls fn until it succeeds, fails with a permanent error or the attempts are exhausted, backing off between the
+// calls. It returns the last error, or the context's one when it's done while waiting to retry.
+func (r retry) do(ctx context.Context, task string, fn func() error) error {
+	delay := r.delay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || permanent(err) || attempt >= r.attempts {
+			return err
+		}
+
+		log.WithFields(
+			log.Fields{
+				"component": "worker",
+				"task":      task,
+				"attempt":   attempt,
+				"delay":     delay.String(),
+			}).
+			WithError(err).
+			Warn("Store call failed; retrying.")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; r.maxDelay > 0 && delay > r.maxDelay {
+			delay = r.maxDelay
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	// failing returns a store call failing with the errors, in order, before succeeding.
+	failing := func(calls *int, errs ...error) func() error {
+		return func() error {
+			*calls++
+			if *calls <= len(errs) {
+				return errs[*calls-1]
+			}
+
+			return nil
+		}
+	}
+
+	type Expected struct {
+		calls int
+		err   error
+	}
+
+	cases := []struct {
+		description string
+		retry       retry
+		errs        []error
+		expected    Expected
+	}{
+		{
+			description: "succeeds at the first call",
+			retry:       retry{attempts: 3, delay: time.Millisecond},
+			errs:        nil,
+			expected:    Expected{calls: 1, err: nil},
+		},
+		{
+			description: "succeeds after the store recovers",
+			retry:       retry{attempts: 3, delay: time.Millisecond},
+			errs:        []error{errors.New("connection refused"), errors.New("connection refused")},
+			expected:    Expected{calls: 3, err: nil},
+		},
+		{
+			description: "fails when the attempts are exhausted",
+			retry:       retry{attempts: 3, delay: time.Millisecond, maxDelay: 2 * time.Millisecond},
+			errs:        []error{errors.New("error 1"), errors.New("error 2"), errors.New("error 3")},
+			expected:    Expected{calls: 3, err: errors.New("error 3")},
+		},
+		{
+			description: "fails without retrying a permanent error",
+			retry:       retry{attempts: 3, delay: time.Millisecond},
+			errs:        []error{store.ErrNoDocuments},
+			expected:    Expected{calls: 1, err: store.ErrNoDocuments},
+		},
+		{
+			description: "fails without retrying when there is a single attempt",
+			retry:       retry{},
+			errs:        []error{errors.New("connection refused")},
+			expected:    Expected{calls: 1, err: errors.New("connection refused")},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			calls := 0
+			err := tc.retry.do(context.Background(), TaskHeartbeat, failing(&calls, tc.errs...))
+			assert.Equal(t, tc.expected, Expected{calls: calls, err: err})
+		})
+	}
+
+	t.Run("fails when the context is done while waiting to retry", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		calls := 0
+		err := retry{attempts: 3, delay: time.Hour}.do(ctx, TaskHeartbeat, failing(&calls, errors.New("connection refused")))
+		assert.Equal(t, Expected{calls: 1, err: context.Canceled}, Expected{calls: calls, err: err})
+	})
+}
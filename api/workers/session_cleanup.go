@@ -2,24 +2,35 @@ package workers
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/shellhub-io/shellhub/pkg/models"
 	log "github.com/sirupsen/logrus"
 )
 
 // registerSessionCleanup worker is designed to delete recorded sessions older than a specified number
 // of days. The retention period is determined by the value of the `SHELLHUB_RECORD_RETENTION` environment
-// variable. To disable this worker, set `SHELLHUB_RECORD_RETENTION` to 0 (default behavior). It uses
-// a cron expression from `SHELLHUB_RECORD_RETENTION` to schedule its periodic execution.
+// variable. The sessions themselves are deleted when older than `SHELLHUB_SESSION_RETENTION`, which is
+// independent of the records retention. To disable this worker, set both to 0 (default behavior). It uses
+// a cron expression from `SHELLHUB_SESSION_RECORD_CLEANUP_SCHEDULE` to schedule its periodic execution.
+//
+// When a [RecordArchiver] is set, the recorded sessions are archived before being deleted, and none is deleted
+// while one fails to be archived. Only the closed sessions are archived, as the recordings of the ones still open
+// aren't complete yet.
 func (w *Workers) registerSessionCleanup() {
-	if w.env.SessionRecordCleanupRetention < 1 {
+	if w.env.SessionRecordCleanupRetention < 1 && w.env.SessionCleanupRetention < 1 {
 		log.WithFields(
 			log.Fields{
 				"component": "worker",
 				"task":      TaskSessionCleanup,
 			}).
-			Warnf("Aborting cleanup worker due to SHELLHUB_RECORD_RETENTION equal to %d.", w.env.SessionRecordCleanupRetention)
+			Warnf(
+				"Aborting cleanup worker due to SHELLHUB_RECORD_RETENTION equal to %d and SHELLHUB_SESSION_RETENTION equal to %d.",
+				w.env.SessionRecordCleanupRetention,
+				w.env.SessionCleanupRetention,
+			)
 
 		return
 	}
@@ -33,18 +44,101 @@ func (w *Workers) registerSessionCleanup() {
 			}).
 			Trace("Executing cleanup worker.")
 
-		lte := time.Now().UTC().AddDate(0, 0, w.env.SessionRecordCleanupRetention*(-1))
-		deletedCount, updatedCount, err := w.store.SessionDeleteRecordFrameByDate(ctx, lte)
-		if err != nil {
+		if w.env.SessionRecordCleanupRetention > 0 {
+			lte := time.Now().UTC().AddDate(0, 0, w.env.SessionRecordCleanupRetention*(-1))
+
+			if w.archive != nil {
+				archivedCount, err := w.archiveRecords(ctx, lte, int64(w.env.SessionRecordCleanupBatchSize))
+				if err != nil {
+					log.WithFields(
+						log.Fields{
+							"component": "worker",
+							"task":      TaskSessionCleanup,
+						}).
+						WithError(err).
+						WithField("archived_count", archivedCount).
+						Error("Failed to archive recorded sessions, keeping them")
+
+					return err
+				}
+
+				log.WithFields(
+					log.Fields{
+						"component":      "worker",
+						"task":           TaskSessionCleanup,
+						"lte":            lte.String(),
+						"archived_count": archivedCount,
+					}).
+					Trace("Recorded sessions archived.")
+			}
+
+			deletedCount, updatedCount, err := w.cleanupRecords(
+				ctx,
+				lte,
+				int64(w.env.SessionRecordCleanupBatchSize),
+				time.Duration(w.env.SessionRecordCleanupBatchDelay)*time.Millisecond,
+			)
+			if err != nil {
+				log.WithFields(
+					log.Fields{
+						"component": "worker",
+						"task":      TaskSessionCleanup,
+					}).
+					WithError(err).
+					WithFields(log.Fields{"deleted_count": deletedCount, "updated_count": updatedCount}).
+					Error("Failed to delete recorded sessions")
+
+				return err
+			}
+
 			log.WithFields(
 				log.Fields{
-					"component": "worker",
-					"task":      TaskSessionCleanup,
+					"component":     "worker",
+					"task":          TaskSessionCleanup,
+					"lte":           lte.String(),
+					"deleted_count": deletedCount,
+					"updated_count": updatedCount,
 				}).
-				WithError(err).
-				Error("Failed to delete recorded sessions")
+				Trace("Recorded sessions deleted.")
 
-			return err
+			if err := w.cleanupRecordings(ctx, lte); err != nil {
+				return err
+			}
+		}
+
+		if w.env.SessionCleanupRetention > 0 {
+			lte := time.Now().UTC().AddDate(0, 0, w.env.SessionCleanupRetention*(-1))
+			var deletedCount int64
+			if err := w.retry.do(ctx, TaskSessionCleanup, func() error {
+				var err error
+				deletedCount, err = w.store.SessionDeleteByDate(ctx, lte)
+
+				return err
+			}); err != nil {
+				log.WithFields(
+					log.Fields{
+						"component": "worker",
+						"task":      TaskSessionCleanup,
+					}).
+					WithError(err).
+					Error("Failed to delete sessions")
+
+				return err
+			}
+
+			log.WithFields(
+				log.Fields{
+					"component":     "worker",
+					"task":          TaskSessionCleanup,
+					"lte":           lte.String(),
+					"deleted_count": deletedCount,
+				}).
+				Trace("Sessions deleted.")
+
+			// NOTICE: the recordings of the deleted sessions were last written before they were last seen.
+			if err := w.cleanupRecordings(ctx, lte); err != nil {
+				return err
+			}
 		}
 
 		log.WithFields(
@@ -52,9 +146,6 @@ func (w *Workers) registerSessionCleanup() {
 				"component":       "worker",
 				"cron_expression": w.env.SessionRecordCleanupSchedule,
 				"task":            TaskSessionCleanup,
-				"lte":             lte.String(),
-				"deleted_count":   deletedCount,
-				"updated_count":   updatedCount,
 			}).
 			Trace("Finishing cleanup worker.")
 
@@ -72,3 +163,98 @@ func (w *Workers) registerSessionCleanup() {
 			Error("Failed to register the scheduler.")
 	}
 }
+
+// archiveRecords archives the recordings deleted by [Workers.cleanupRecords] for the same lte, listing batchSize
+// sessions at once, until there is none left to archive. It returns the number of archived recordings, even when one
+// fails to be archived, stopping at it.
+func (w *Workers) archiveRecords(ctx context.Context, lte time.Time, batchSize int64) (int64, error) {
+	var archivedCount int64
+	for {
+		var uids []models.UID
+		if err := w.retry.do(ctx, TaskSessionCleanup, func() error {
+			var err error
+			uids, err = w.store.SessionListUnarchivedRecords(ctx, lte, batchSize)
+
+			return err
+		}); err != nil {
+			return archivedCount, err
+		}
+
+		for _, uid := range uids {
+			if err := w.archive(ctx, uid); err != nil {
+				return archivedCount, fmt.Errorf("failed to archive the recording of the session %s: %w", uid, err)
+			}
+
+			archivedCount++
+		}
+
+		// NOTICE: an archived recording isn't listed again, so the sessions are listed until a batch isn't full.
+		if batchSize < 1 || int64(len(uids)) < batchSize {
+			return archivedCount, nil
+		}
+	}
+}
+
+// cleanupRecords deletes the recorded frames before or at lte, in batches of batchSize with a pause of delay between
+// them, until a batch deletes and updates less than batchSize. It returns the cumulative number of deleted frames and
+// updated sessions, even when a batch fails. Every batch is retried while the database is unreachable.
+func (w *Workers) cleanupRecords(ctx context.Context, lte time.Time, batchSize int64, delay time.Duration) (int64, int64, error) {
+	var deletedCount, updatedCount int64
+	for {
+		var deleted, updated int64
+		if err := w.retry.do(ctx, TaskSessionCleanup, func() error {
+			var err error
+			deleted, updated, err = w.store.SessionDeleteRecordFrameByDate(ctx, lte, batchSize)
+
+			return err
+		}); err != nil {
+			return deletedCount, updatedCount, err
+		}
+
+		deletedCount += deleted
+		updatedCount += updated
+
+		if batchSize < 1 || (deleted < batchSize && updated < batchSize) {
+			return deletedCount, updatedCount, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return deletedCount, updatedCount, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// cleanupRecordings deletes the recordings written before or at lte from the recording storage, when the recordings
+// aren't kept on the store, which deletes them along with the frames and the sessions.
+func (w *Workers) cleanupRecordings(ctx context.Context, lte time.Time) error {
+	if w.recordings == nil {
+		return nil
+	}
+
+	deletedCount, err := w.recordings.DeleteBefore(ctx, lte)
+	if err != nil {
+		log.WithFields(
+			log.Fields{
+				"component": "worker",
+				"task":      TaskSessionCleanup,
+			}).
+			WithError(err).
+			WithField("deleted_count", deletedCount).
+			Error("Failed to delete the recordings from the recording storage")
+
+		return err
+	}
+
+	log.WithFields(
+		log.Fields{
+			"component":     "worker",
+			"task":          TaskSessionCleanup,
+			"lte":           lte.String(),
+			"deleted_count": deletedCount,
+		}).
+		Trace("Recordings deleted from the recording storage.")
+
+	return nil
+}
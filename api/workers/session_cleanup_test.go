@@ -0,0 +1,236 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/recordstorage"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanupRecords(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.Background()
+
+	lte := time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC)
+
+	type Expected struct {
+		deletedCount int64
+		updatedCount int64
+		err          error
+	}
+
+	cases := []struct {
+		description   string
+		batchSize     int64
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "succeeds deleting everything at once when the batch size is 0",
+			batchSize:   0,
+			requiredMocks: func() {
+				mock.On("SessionDeleteRecordFrameByDate", ctx, lte, int64(0)).Return(int64(2500), int64(30), nil).Once()
+			},
+			expected: Expected{deletedCount: 2500, updatedCount: 30},
+		},
+		{
+			description: "succeeds deleting in batches until a batch is not full",
+			batchSize:   1000,
+			requiredMocks: func() {
+				mock.On("SessionDeleteRecordFrameByDate", ctx, lte, int64(1000)).Return(int64(1000), int64(30), nil).Twice()
+				mock.On("SessionDeleteRecordFrameByDate", ctx, lte, int64(1000)).Return(int64(500), int64(0), nil).Once()
+			},
+			expected: Expected{deletedCount: 2500, updatedCount: 60},
+		},
+		{
+			description: "succeeds deleting in batches while the sessions to update fill a batch",
+			batchSize:   10,
+			requiredMocks: func() {
+				mock.On("SessionDeleteRecordFrameByDate", ctx, lte, int64(10)).Return(int64(0), int64(10), nil).Once()
+				mock.On("SessionDeleteRecordFrameByDate", ctx, lte, int64(10)).Return(int64(0), int64(3), nil).Once()
+			},
+			expected: Expected{deletedCount: 0, updatedCount: 13},
+		},
+		{
+			description: "fails when a batch fails, reporting what was deleted so far",
+			batchSize:   1000,
+			requiredMocks: func() {
+				mock.On("SessionDeleteRecordFrameByDate", ctx, lte, int64(1000)).Return(int64(1000), int64(30), nil).Once()
+				mock.On("SessionDeleteRecordFrameByDate", ctx, lte, int64(1000)).Return(int64(0), int64(0), errors.New("error")).Once()
+			},
+			expected: Expected{deletedCount: 1000, updatedCount: 30, err: errors.New("error")},
+		},
+	}
+
+	w := &Workers{store: mock}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			deletedCount, updatedCount, err := w.cleanupRecords(ctx, lte, tc.batchSize, time.Millisecond)
+			assert.Equal(t, tc.expected, Expected{deletedCount, updatedCount, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestCleanupRecordsCanceled(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lte := time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC)
+
+	mock.On("SessionDeleteRecordFrameByDate", ctx, lte, int64(10)).
+		Run(func(_ testifymock.Arguments) { cancel() }).
+		Return(int64(10), int64(0), nil).
+		Once()
+
+	w := &Workers{store: mock}
+
+	deletedCount, updatedCount, err := w.cleanupRecords(ctx, lte, 10, time.Hour)
+	assert.Equal(t, int64(10), deletedCount)
+	assert.Equal(t, int64(0), updatedCount)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	mock.AssertExpectations(t)
+}
+
+func TestArchiveRecords(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.Background()
+
+	lte := time.Date(2023, time.January, 30, 12, 0, 0, 0, time.UTC)
+
+	type Expected struct {
+		archived      []models.UID
+		archivedCount int64
+		err           error
+	}
+
+	cases := []struct {
+		description   string
+		batchSize     int64
+		fail          models.UID
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "succeeds when there is nothing to archive",
+			batchSize:   2,
+			requiredMocks: func() {
+				mock.On("SessionListUnarchivedRecords", ctx, lte, int64(2)).Return([]models.UID{}, nil).Once()
+			},
+			expected: Expected{archived: []models.UID{}, archivedCount: 0},
+		},
+		{
+			description: "succeeds archiving everything at once when the batch size is 0",
+			batchSize:   0,
+			requiredMocks: func() {
+				mock.On("SessionListUnarchivedRecords", ctx, lte, int64(0)).Return([]models.UID{"a", "b", "c"}, nil).Once()
+			},
+			expected: Expected{archived: []models.UID{"a", "b", "c"}, archivedCount: 3},
+		},
+		{
+			description: "succeeds archiving in batches until a batch is not full",
+			batchSize:   2,
+			requiredMocks: func() {
+				mock.On("SessionListUnarchivedRecords", ctx, lte, int64(2)).Return([]models.UID{"a", "b"}, nil).Once()
+				mock.On("SessionListUnarchivedRecords", ctx, lte, int64(2)).Return([]models.UID{"c"}, nil).Once()
+			},
+			expected: Expected{archived: []models.UID{"a", "b", "c"}, archivedCount: 3},
+		},
+		{
+			description: "fails when the sessions cannot be listed",
+			batchSize:   2,
+			requiredMocks: func() {
+				mock.On("SessionListUnarchivedRecords", ctx, lte, int64(2)).Return(nil, errors.New("error")).Once()
+			},
+			expected: Expected{archived: []models.UID{}, archivedCount: 0, err: errors.New("error")},
+		},
+		{
+			description: "fails at the first recording that cannot be archived",
+			batchSize:   3,
+			fail:        "b",
+			requiredMocks: func() {
+				mock.On("SessionListUnarchivedRecords", ctx, lte, int64(3)).Return([]models.UID{"a", "b", "c"}, nil).Once()
+			},
+			expected: Expected{
+				archived:      []models.UID{"a"},
+				archivedCount: 1,
+				err:           fmt.Errorf("failed to archive the recording of the session b: %w", errors.New("error")),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			archived := make([]models.UID, 0)
+			w := &Workers{store: mock, archive: func(_ context.Context, uid models.UID) error {
+				if uid == tc.fail {
+					return errors.New("error")
+				}
+
+				archived = append(archived, uid)
+
+				return nil
+			}}
+
+			archivedCount, err := w.archiveRecords(ctx, lte, tc.batchSize)
+			assert.Equal(t, tc.expected, Expected{archived, archivedCount, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestCleanupRecordings(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+
+	storage, err := recordstorage.NewFileSystemRecordingStorage(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.Store(ctx, "old", []models.RecordedSession{{UID: "old", Message: "message"}}))
+	require.NoError(t, storage.Store(ctx, "new", []models.RecordedSession{{UID: "new", Message: "message"}}))
+
+	now := time.Now()
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "old.ndjson"), now.Add(-time.Hour), now.Add(-time.Hour)))
+
+	t.Run("succeeds doing nothing when the recordings are on the store", func(t *testing.T) {
+		w := &Workers{store: new(mocks.Store)}
+
+		assert.NoError(t, w.cleanupRecordings(ctx, now.Add(-time.Minute)))
+	})
+
+	t.Run("succeeds deleting the recordings written before the date", func(t *testing.T) {
+		w := &Workers{store: new(mocks.Store), recordings: storage}
+
+		assert.NoError(t, w.cleanupRecordings(ctx, now.Add(-time.Minute)))
+
+		frames, err := storage.Retrieve(ctx, "old")
+		assert.NoError(t, err)
+		assert.Empty(t, frames)
+
+		frames, err = storage.Retrieve(ctx, "new")
+		assert.NoError(t, err)
+		assert.Len(t, frames, 1)
+	})
+}
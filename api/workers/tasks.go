@@ -3,4 +3,10 @@ package workers
 const (
 	TaskSessionCleanup = "session_record:cleanup"
 	TaskHeartbeat      = "api:heartbeat"
+	// TaskNamespaceExpiry expires the inactive free-tier namespaces.
+	TaskNamespaceExpiry = "namespace:expiry"
+	// TaskUsageReport sends the usage reports of the namespaces whose schedule is due.
+	TaskUsageReport = "namespace:usage_report"
+	// TaskDeviceExpiry rejects the devices whose access to their namespaces has expired.
+	TaskDeviceExpiry = "device:expiry"
 )
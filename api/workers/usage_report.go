@@ -0,0 +1,168 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/robfig/cron/v3"
+	"github.com/shellhub-io/shellhub/api/pkg/mailer"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// registerUsageReport worker is designed to send, by email, the usage reports of the namespaces on the schedules they
+// define. It only runs when the SMTP server is configured, and uses a cron expression from
+// `SHELLHUB_USAGE_REPORT_CHECK_SCHEDULE` to check which schedules are due, so they're followed with its precision.
+func (w *Workers) registerUsageReport() {
+	if w.mailer == nil {
+		log.WithFields(log.Fields{"component": "worker", "task": TaskUsageReport}).
+			Info("SMTP server not configured, the usage reports won't be sent.")
+
+		return
+	}
+
+	w.mux.HandleFunc(TaskUsageReport, func(ctx context.Context, _ *asynq.Task) error {
+		log.WithFields(
+			log.Fields{
+				"component":       "worker",
+				"cron_expression": w.env.UsageReportCheckSchedule,
+				"task":            TaskUsageReport,
+			}).
+			Trace("Executing usage report worker.")
+
+		return w.sendUsageReports(ctx, clock.Now())
+	})
+
+	task := asynq.NewTask(TaskUsageReport, nil, asynq.TaskID(TaskUsageReport), asynq.Queue("api"))
+	if _, err := w.scheduler.Register(w.env.UsageReportCheckSchedule, task); err != nil {
+		log.WithFields(
+			log.Fields{
+				"component": "worker",
+				"task":      TaskUsageReport,
+			}).
+			WithError(err).
+			Error("Failed to register the scheduler.")
+	}
+}
+
+// sendUsageReports sends the usage reports of the namespaces whose schedule is due at now, what means the next time
+// of the schedule after the last report sent isn't after now. The schedule of a namespace without a report sent yet
+// starts at now, so no report is sent just because the schedule was set.
+func (w *Workers) sendUsageReports(ctx context.Context, now time.Time) error {
+	var namespaces []models.Namespace
+	if err := w.retry.do(ctx, TaskUsageReport, func() error {
+		var err error
+		namespaces, err = w.store.NamespaceListUsageReports(ctx)
+
+		return err
+	}); err != nil {
+		log.WithFields(log.Fields{"component": "worker", "task": TaskUsageReport}).
+			WithError(err).
+			Error("Failed to list the namespaces with usage reports")
+
+		return err
+	}
+
+	for _, ns := range namespaces {
+		logger := log.WithFields(log.Fields{"component": "worker", "task": TaskUsageReport, "tenant_id": ns.TenantID})
+
+		schedule, err := cron.ParseStandard(ns.Settings.UsageReportSchedule)
+		if err != nil {
+			logger.WithError(err).Warn("Invalid usage report schedule")
+
+			continue
+		}
+
+		if ns.UsageReportSentAt != nil && schedule.Next(*ns.UsageReportSentAt).After(now) {
+			continue
+		}
+
+		// NOTICE: when the report cannot be sent, it isn't set as sent, so it's tried again on the next check.
+		if ns.UsageReportSentAt != nil && !w.sendUsageReport(ctx, logger, &ns, now) {
+			continue
+		}
+
+		if err := w.retry.do(ctx, TaskUsageReport, func() error {
+			return w.store.NamespaceSetUsageReportSentAt(ctx, ns.TenantID, now)
+		}); err != nil {
+			logger.WithError(err).Error("Failed to set when the usage report was sent")
+		}
+	}
+
+	return nil
+}
+
+// sendUsageReport sends the usage report of the namespace, reporting whether the report was handled. A report whose
+// email doesn't belong to a member of the namespace anymore is skipped, but considered handled, as only the members
+// may receive it.
+func (w *Workers) sendUsageReport(ctx context.Context, logger *log.Entry, ns *models.Namespace, now time.Time) bool {
+	var member bool
+	if err := w.retry.do(ctx, TaskUsageReport, func() error {
+		var err error
+		member, err = w.isMemberEmail(ctx, ns, ns.Settings.UsageReportEmail)
+
+		return err
+	}); err != nil {
+		logger.WithError(err).Error("Failed to check the usage report email")
+
+		return false
+	}
+
+	if !member {
+		logger.Warn("Usage report skipped as its email does not belong to a member of the namespace")
+
+		return true
+	}
+
+	var stats *models.Stats
+	if err := w.retry.do(ctx, TaskUsageReport, func() error {
+		var err error
+		stats, err = w.store.GetNamespaceStats(ctx, ns.TenantID)
+
+		return err
+	}); err != nil {
+		logger.WithError(err).Error("Failed to get the namespace stats")
+
+		return false
+	}
+
+	if err := mailer.SendNamespaceUsageReport(ctx, w.mailer, ns.Settings.UsageReportEmail, &mailer.NamespaceUsageReport{
+		Namespace:   ns.Name,
+		TenantID:    ns.TenantID,
+		GeneratedAt: now,
+		Stats:       *stats,
+	}); err != nil {
+		logger.WithError(err).Error("Failed to send the usage report")
+
+		return false
+	}
+
+	logger.Info("Usage report sent.")
+
+	return true
+}
+
+// isMemberEmail reports whether the email is the one of a member of the namespace.
+func (w *Workers) isMemberEmail(ctx context.Context, ns *models.Namespace, email string) (bool, error) {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return false, nil
+	}
+
+	user, err := w.store.UserGetByEmail(ctx, address.Address)
+	switch {
+	case errors.Is(err, store.ErrNoDocuments):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	_, ok := ns.FindMember(user.ID)
+
+	return ok, nil
+}
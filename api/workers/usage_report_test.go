@@ -0,0 +1,150 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	mailermocks "github.com/shellhub-io/shellhub/api/pkg/mailer/mocks"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+func TestSendUsageReports(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2024, time.June, 3, 0, 30, 0, 0, time.UTC)
+	at := func(t time.Time) *time.Time {
+		return &t
+	}
+
+	settings := &models.NamespaceSettings{UsageReportSchedule: "0 0 * * 1", UsageReportEmail: "owner@shellhub.io"}
+	members := []models.Member{{ID: "owner"}}
+
+	cases := []struct {
+		description   string
+		requiredMocks func(storeMock *mocks.Store, mailerMock *mailermocks.Mailer)
+		expected      error
+	}{
+		{
+			description: "fails when the namespaces with usage reports could not be listed",
+			requiredMocks: func(storeMock *mocks.Store, _ *mailermocks.Mailer) {
+				storeMock.On("NamespaceListUsageReports", ctx).
+					Return(nil, errors.New("error")).
+					Once()
+			},
+			expected: errors.New("error"),
+		},
+		{
+			description: "skips the namespaces whose schedule is invalid",
+			requiredMocks: func(storeMock *mocks.Store, _ *mailermocks.Mailer) {
+				storeMock.On("NamespaceListUsageReports", ctx).
+					Return([]models.Namespace{
+						{
+							TenantID:          "tenant",
+							Settings:          &models.NamespaceSettings{UsageReportSchedule: "weekly", UsageReportEmail: "owner@shellhub.io"},
+							UsageReportSentAt: at(now.AddDate(0, 0, -14)),
+						},
+					}, nil).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "starts the schedule of the namespaces without a report sent yet",
+			requiredMocks: func(storeMock *mocks.Store, _ *mailermocks.Mailer) {
+				storeMock.On("NamespaceListUsageReports", ctx).
+					Return([]models.Namespace{{TenantID: "tenant", Settings: settings}}, nil).
+					Once()
+				storeMock.On("NamespaceSetUsageReportSentAt", ctx, "tenant", now).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "does not send the report when the schedule is not due",
+			requiredMocks: func(storeMock *mocks.Store, _ *mailermocks.Mailer) {
+				storeMock.On("NamespaceListUsageReports", ctx).
+					Return([]models.Namespace{{TenantID: "tenant", Settings: settings, UsageReportSentAt: at(now.Add(-20 * time.Minute))}}, nil).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "skips the report when its email does not belong to a member of the namespace",
+			requiredMocks: func(storeMock *mocks.Store, _ *mailermocks.Mailer) {
+				storeMock.On("NamespaceListUsageReports", ctx).
+					Return([]models.Namespace{{TenantID: "tenant", Name: "namespace", Members: members, Settings: settings, UsageReportSentAt: at(now.AddDate(0, 0, -7))}}, nil).
+					Once()
+				storeMock.On("UserGetByEmail", ctx, "owner@shellhub.io").
+					Return(&models.User{ID: "other"}, nil).
+					Once()
+				storeMock.On("NamespaceSetUsageReportSentAt", ctx, "tenant", now).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "does not set the report as sent when it could not be sent",
+			requiredMocks: func(storeMock *mocks.Store, mailerMock *mailermocks.Mailer) {
+				storeMock.On("NamespaceListUsageReports", ctx).
+					Return([]models.Namespace{{TenantID: "tenant", Name: "namespace", Members: members, Settings: settings, UsageReportSentAt: at(now.AddDate(0, 0, -7))}}, nil).
+					Once()
+				storeMock.On("UserGetByEmail", ctx, "owner@shellhub.io").
+					Return(&models.User{ID: "owner"}, nil).
+					Once()
+				storeMock.On("GetNamespaceStats", ctx, "tenant").
+					Return(&models.Stats{}, nil).
+					Once()
+				mailerMock.On("Send", ctx, "owner@shellhub.io", "Usage report of namespace", testifymock.AnythingOfType("string")).
+					Return(errors.New("error")).
+					Once()
+			},
+			expected: nil,
+		},
+		{
+			description: "sends the report when the schedule is due",
+			requiredMocks: func(storeMock *mocks.Store, mailerMock *mailermocks.Mailer) {
+				storeMock.On("NamespaceListUsageReports", ctx).
+					Return([]models.Namespace{{TenantID: "tenant", Name: "namespace", Members: members, Settings: settings, UsageReportSentAt: at(now.AddDate(0, 0, -7))}}, nil).
+					Once()
+				storeMock.On("UserGetByEmail", ctx, "owner@shellhub.io").
+					Return(&models.User{ID: "owner"}, nil).
+					Once()
+				storeMock.On("GetNamespaceStats", ctx, "tenant").
+					Return(&models.Stats{RegisteredDevices: 10, ActiveSessions: 3}, nil).
+					Once()
+				mailerMock.On("Send", ctx, "owner@shellhub.io", "Usage report of namespace", testifymock.MatchedBy(func(body string) bool {
+					return strings.Contains(body, "<tr><th>Registered devices</th><td>10</td></tr>") &&
+						strings.Contains(body, "<tr><th>Active sessions</th><td>3</td></tr>")
+				})).
+					Return(nil).
+					Once()
+				storeMock.On("NamespaceSetUsageReportSentAt", ctx, "tenant", now).
+					Return(nil).
+					Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			storeMock := new(mocks.Store)
+			mailerMock := mailermocks.NewMailer(t)
+			tc.requiredMocks(storeMock, mailerMock)
+
+			w := &Workers{store: storeMock, env: &Envs{}, mailer: mailerMock}
+			err := w.sendUsageReports(ctx, now)
+
+			assert.Equal(t, tc.expected, err)
+			storeMock.AssertExpectations(t)
+		})
+	}
+}
@@ -10,6 +10,39 @@ type Envs struct {
 	RedisURI                      string `env:"REDIS_URI,default=redis://redis:6379"`
 	SessionRecordCleanupSchedule  string `env:"SESSION_RECORD_CLEANUP_SCHEDULE,default=@daily"`
 	SessionRecordCleanupRetention int    `env:"RECORD_RETENTION,default=0"`
+	// SessionRecordCleanupBatchSize is the maximum number of recorded frames deleted, and of sessions updated, at once
+	// by the cleanup worker. Deleting in bounded batches keeps the cleanup from holding locks for long on large
+	// datasets.
+	//
+	// When equal to 0, everything is deleted at once.
+	SessionRecordCleanupBatchSize int `env:"SESSION_RECORD_CLEANUP_BATCH_SIZE,default=1000"`
+	// SessionRecordCleanupBatchDelay is the pause between two batches of the cleanup worker, leaving room for the live
+	// traffic.
+	//
+	// Its time unit is millisecond.
+	SessionRecordCleanupBatchDelay int `env:"SESSION_RECORD_CLEANUP_BATCH_DELAY,default=100"`
+	// SessionCleanupRetention is the number of days a session is kept after it was last seen. Unlike
+	// SessionRecordCleanupRetention, which only trims the recorded frames, the sessions themselves are deleted.
+	//
+	// When equal to 0, the sessions are kept forever.
+	SessionCleanupRetention int `env:"SESSION_RETENTION,default=0"`
+	// NamespaceExpirySchedule is the cron expression of the worker expiring the inactive free-tier namespaces.
+	NamespaceExpirySchedule string `env:"NAMESPACE_EXPIRY_SCHEDULE,default=@weekly"`
+	// NamespaceExpiryWebhookURL is the URL that receives a POST request warning about each namespace set to expire.
+	//
+	// When empty, the warnings are emailed to the namespace owners, and, when the SMTP server isn't configured either,
+	// no namespace is set to expire.
+	NamespaceExpiryWebhookURL string `env:"NAMESPACE_EXPIRY_WEBHOOK_URL"`
+	// DeviceExpirySchedule is the cron expression of the worker rejecting the devices whose access has expired.
+	DeviceExpirySchedule string `env:"DEVICE_EXPIRY_SCHEDULE,default=@daily"`
+	// DeviceExpiryWebhookURL is the URL that receives a POST request notifying about each device whose access has
+	// expired.
+	//
+	// When empty, no notice is sent.
+	DeviceExpiryWebhookURL string `env:"DEVICE_EXPIRY_WEBHOOK_URL"`
+	// UsageReportCheckSchedule is the cron expression of the worker sending the usage reports of the namespaces whose
+	// schedule is due, what limits how precisely the schedules are followed.
+	UsageReportCheckSchedule string `env:"USAGE_REPORT_CHECK_SCHEDULE,default=@hourly"`
 	// AsynqGroupMaxDelay is the maximum duration to wait before processing a group of tasks.
 	//
 	// Its time unit is second.
@@ -28,6 +61,18 @@ type Envs struct {
 	//
 	// Check [https://github.com/hibiken/asynq/wiki/Task-aggregation] for more information.
 	AsynqGroupMaxSize int `env:"ASYNQ_GROUP_MAX_SIZE,default=500"`
+	// StoreRetryAttempts is the maximum number of times a worker calls the store before failing its task, what keeps
+	// a task from failing when the database is briefly unreachable.
+	StoreRetryAttempts int `env:"WORKER_STORE_RETRY_ATTEMPTS,default=5"`
+	// StoreRetryDelay is the pause before retrying a failed store call, doubled on every next retry up to
+	// StoreRetryMaxDelay.
+	//
+	// Its time unit is millisecond.
+	StoreRetryDelay int `env:"WORKER_STORE_RETRY_DELAY,default=200"`
+	// StoreRetryMaxDelay is the maximum pause between two retries of a failed store call.
+	//
+	// Its time unit is millisecond.
+	StoreRetryMaxDelay int `env:"WORKER_STORE_RETRY_MAX_DELAY,default=5000"`
 }
 
 func getEnvs() (*Envs, error) {
@@ -4,14 +4,40 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hibiken/asynq"
+	"github.com/shellhub-io/shellhub/api/pkg/mailer"
+	"github.com/shellhub-io/shellhub/api/pkg/recordstorage"
 	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/models"
 	log "github.com/sirupsen/logrus"
 )
 
+// Task payloads are prefixed with their schema version, like "v2:<payload>", allowing a payload format to change
+// without breaking the tasks that were already enqueued, or that are still enqueued by services not yet upgraded,
+// with a previous one. Payloads without a prefix are considered as "v1".
+//
+// To change the payload of a task in a backward-incompatible way:
+//
+//  1. bump its PayloadVersion constant below;
+//  2. keep the parsing of every previous version in its handler, as old tasks may still be in the queue;
+//  3. update the producers, like the internal client, to send the new version.
+//
+// The workers must be deployed before the producers sending a new version, as the previous workers drop the payloads
+// of the versions they don't know. A version can only be removed from a handler after all producers have been
+// upgraded and the queues drained.
+const (
+	// HeartbeatPayloadVersion is the schema version of the [TaskHeartbeat] payload, a query string of its fields like
+	// "tenant_id=tenant&uid=uid&last_seen=timestamp".
+	HeartbeatPayloadVersion = "v2"
+)
+
+// PayloadVersionV1 is the version of the payloads enqueued before the payloads were versioned.
+const PayloadVersionV1 = "v1"
+
 type Workers struct {
 	store store.Store
 
@@ -20,11 +46,79 @@ type Workers struct {
 	mux       *asynq.ServeMux
 	env       *Envs
 	scheduler *asynq.Scheduler
+	retry     retry
+	// mailer sends the usage reports of the namespaces. When nil, the SMTP server isn't configured.
+	mailer mailer.Mailer
+	// archive archives the recordings before the cleanup deletes them. When nil, they are deleted without being
+	// archived.
+	archive RecordArchiver
+	// recordings is where the recordings are kept when they aren't on the store, which deletes them along with the
+	// sessions. When nil, the recordings are on the store.
+	recordings recordstorage.RecordingStorage
+	// expireDevice rejects the devices whose access to their namespaces has expired. When nil, they are rejected on
+	// the store, without being recorded on their timelines.
+	expireDevice DeviceExpirer
+	// expireNamespace deletes the namespaces whose inactivity expired them. When nil, they are deleted on the store,
+	// leaving behind the recordings kept out of it.
+	expireNamespace NamespaceExpirer
+}
+
+// RecordArchiver archives the recording of the session with the specified UID, like exporting it to an object storage,
+// so it outlives its deletion from the store. It must only return once the recording is safely archived.
+type RecordArchiver func(ctx context.Context, uid models.UID) error
+
+// DeviceExpirer rejects the device with the specified UID, whose access to the namespace with the specified tenant ID
+// has expired.
+type DeviceExpirer func(ctx context.Context, tenantID string, uid models.UID) error
+
+// NamespaceExpirer deletes the namespace with the specified tenant ID, whose inactivity expired it.
+type NamespaceExpirer func(ctx context.Context, tenantID string) error
+
+type Option func(w *Workers)
+
+// WithRecordArchiver archives the recordings with archive before the cleanup deletes them, only deleting them once
+// every one was archived.
+func WithRecordArchiver(archive RecordArchiver) Option {
+	return func(w *Workers) {
+		w.archive = archive
+	}
+}
+
+// WithRecordingStorage deletes the recordings kept on storage, instead of the store, when the cleanup deletes the
+// recordings or the sessions.
+func WithRecordingStorage(storage recordstorage.RecordingStorage) Option {
+	return func(w *Workers) {
+		w.recordings = storage
+	}
+}
+
+// WithDeviceExpirer rejects the expired devices through expire, like the service does to record the expirations on
+// the devices' timelines, instead of on the store.
+func WithDeviceExpirer(expire DeviceExpirer) Option {
+	return func(w *Workers) {
+		w.expireDevice = expire
+	}
+}
+
+// WithNamespaceExpirer deletes the expired namespaces through expire, like the service does to delete their
+// recordings too, instead of on the store.
+func WithNamespaceExpirer(expire NamespaceExpirer) Option {
+	return func(w *Workers) {
+		w.expireNamespace = expire
+	}
+}
+
+// WithMailer sends the usage reports of the namespaces, and the namespace expiry warnings when there is no webhook,
+// through m.
+func WithMailer(m mailer.Mailer) Option {
+	return func(w *Workers) {
+		w.mailer = m
+	}
 }
 
 // New creates a new Workers instance with the provided store. It initializes
 // the worker's components, such as server, scheduler, and environment settings.
-func New(store store.Store) (*Workers, error) {
+func New(store store.Store, opts ...Option) (*Workers, error) {
 	env, err := getEnvs()
 	if err != nil {
 		log.WithFields(log.Fields{"component": "worker"}).
@@ -54,17 +148,7 @@ func New(store store.Store) (*Workers, error) {
 				"api":            1,
 				"session_record": 1,
 			},
-			GroupAggregator: asynq.GroupAggregatorFunc(
-				func(group string, tasks []*asynq.Task) *asynq.Task {
-					var b strings.Builder
-
-					for _, task := range tasks {
-						b.WriteString(fmt.Sprintf("%s\n", task.Payload()))
-					}
-
-					return asynq.NewTask(TaskHeartbeat, []byte(b.String()))
-				},
-			),
+			GroupAggregator:  asynq.GroupAggregatorFunc(aggregate),
 			GroupMaxDelay:    time.Duration(env.AsynqGroupMaxDelay) * time.Second,
 			GroupGracePeriod: time.Duration(env.AsynqGroupGracePeriod) * time.Second,
 			GroupMaxSize:     env.AsynqGroupMaxSize,
@@ -80,11 +164,47 @@ func New(store store.Store) (*Workers, error) {
 		mux:       mux,
 		scheduler: scheduler,
 		store:     store,
+		retry: retry{
+			attempts: env.StoreRetryAttempts,
+			delay:    time.Duration(env.StoreRetryDelay) * time.Millisecond,
+			maxDelay: time.Duration(env.StoreRetryMaxDelay) * time.Millisecond,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(w)
 	}
 
 	return w, nil
 }
 
+// aggregate joins the payloads of the tasks in a group, one per line, into a single [TaskHeartbeat] task. Each payload
+// is kept as is, including its version prefix, so tasks enqueued with different versions can be aggregated together.
+func aggregate(_ string, tasks []*asynq.Task) *asynq.Task {
+	var b strings.Builder
+
+	for _, task := range tasks {
+		b.WriteString(fmt.Sprintf("%s\n", task.Payload()))
+	}
+
+	return asynq.NewTask(TaskHeartbeat, []byte(b.String()))
+}
+
+// payloadVersion splits a task payload into its schema version and content. Payloads without a version prefix are
+// reported as [PayloadVersionV1].
+func payloadVersion(payload string) (string, string) {
+	prefix, content, ok := strings.Cut(payload, ":")
+	if !ok || len(prefix) < 2 || prefix[0] != 'v' {
+		return PayloadVersionV1, payload
+	}
+
+	if _, err := strconv.Atoi(prefix[1:]); err != nil {
+		return PayloadVersionV1, payload
+	}
+
+	return prefix, content
+}
+
 // Start initiates the server. It creates two new goroutines: one for the server itself
 // and another for the scheduler. This method is also responsible for setting up all
 // the server handlers.
@@ -124,4 +244,7 @@ func (w *Workers) Start(ctx context.Context) {
 func (w *Workers) setupHandlers() {
 	w.registerSessionCleanup()
 	w.registerHeartbeat()
+	w.registerNamespaceExpiry()
+	w.registerUsageReport()
+	w.registerDeviceExpiry()
 }
@@ -4,6 +4,7 @@ docker_install() {
     KEEPALIVE_INTERVAL_ARG="-e SHELLHUB_KEEPALIVE_INTERVAL=$KEEPALIVE_INTERVAL"
     PREFERRED_HOSTNAME_ARG="-e SHELLHUB_PREFERRED_HOSTNAME=$PREFERRED_HOSTNAME"
     PREFERRED_IDENTITY_ARG="-e SHELLHUB_PREFERRED_IDENTITY=$PREFERRED_IDENTITY"
+    ENROLLMENT_KEY_ARG="-e SHELLHUB_ENROLLMENT_KEY=$ENROLLMENT_KEY"
 
     docker run -d \
        --name=$CONTAINER_NAME \
@@ -25,6 +26,7 @@ docker_install() {
        $KEEPALIVE_INTERVAL_ARG \
        $PREFERRED_HOSTNAME_ARG \
        $PREFERRED_IDENTITY_ARG \
+       $ENROLLMENT_KEY_ARG \
        shellhubio/agent:$AGENT_VERSION
 }
 
@@ -98,6 +98,10 @@ type Config struct {
 	// This is required.
 	TenantID string `env:"TENANT_ID,required" validate:"required"`
 
+	// Sets the namespace's enrollment key, required to register the device when
+	// the namespace has one. Devices already registered don't need it.
+	EnrollmentKey string `env:"ENROLLMENT_KEY"`
+
 	// Determine the interval to send the keep alive message to the server. This
 	// has a direct impact of the bandwidth used by the device when in idle
 	// state. Default is 30 seconds.
@@ -342,7 +346,8 @@ func (a *Agent) probeServerInfo() error {
 // authorize send auth request to the server with device information in order to register it in the namespace.
 func (a *Agent) authorize() error {
 	data, err := a.cli.AuthDevice(&models.DeviceAuthRequest{
-		Info: a.Info,
+		Info:          a.Info,
+		EnrollmentKey: a.config.EnrollmentKey,
 		DeviceAuth: &models.DeviceAuth{
 			Hostname:  a.config.PreferredHostname,
 			Identity:  a.Identity,
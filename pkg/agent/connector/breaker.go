@@ -0,0 +1,47 @@
+package connector
+
+import (
+	"time"
+)
+
+// breaker counts the consecutive failures of a container's agent, tripping when threshold of them happen inside
+// window. A tripped breaker keeps the agent disabled until it's reset.
+type breaker struct {
+	threshold int
+	window    time.Duration
+	// failures are the times of the consecutive failures inside the window.
+	failures []time.Time
+	tripped  bool
+}
+
+// newBreaker creates a breaker that trips after threshold consecutive failures inside window. When threshold is zero,
+// the breaker never trips.
+func newBreaker(threshold int, window time.Duration) *breaker {
+	return &breaker{threshold: threshold, window: window}
+}
+
+// fail registers a failure at now, reporting whether it tripped the breaker.
+func (b *breaker) fail(now time.Time) bool {
+	if b.threshold <= 0 || b.tripped {
+		return b.tripped
+	}
+
+	// NOTICE: failures older than the window don't count, so sporadic failures never trip the breaker.
+	kept := b.failures[:0]
+	for _, failure := range b.failures {
+		if b.window <= 0 || now.Sub(failure) < b.window {
+			kept = append(kept, failure)
+		}
+	}
+
+	b.failures = append(kept, now)
+	b.tripped = len(b.failures) >= b.threshold
+
+	return b.tripped
+}
+
+// reset clears the failures registered, closing the breaker.
+func (b *breaker) reset() {
+	b.failures = nil
+	b.tripped = false
+}
@@ -0,0 +1,51 @@
+package connector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("trips after the consecutive failures inside the window", func(t *testing.T) {
+		b := newBreaker(3, time.Minute)
+
+		assert.False(t, b.fail(start))
+		assert.False(t, b.fail(start.Add(10*time.Second)))
+		assert.True(t, b.fail(start.Add(20*time.Second)))
+		// A tripped breaker stays tripped until reset.
+		assert.True(t, b.fail(start.Add(time.Hour)))
+	})
+
+	t.Run("doesn't trip when the failures are spread beyond the window", func(t *testing.T) {
+		b := newBreaker(3, time.Minute)
+
+		assert.False(t, b.fail(start))
+		assert.False(t, b.fail(start.Add(40*time.Second)))
+		assert.False(t, b.fail(start.Add(70*time.Second)))
+		assert.True(t, b.fail(start.Add(90*time.Second)))
+	})
+
+	t.Run("doesn't trip after being reset", func(t *testing.T) {
+		b := newBreaker(2, time.Minute)
+
+		assert.False(t, b.fail(start))
+		b.reset()
+		assert.False(t, b.fail(start.Add(time.Second)))
+		assert.True(t, b.fail(start.Add(2*time.Second)))
+
+		b.reset()
+		assert.False(t, b.fail(start.Add(3*time.Second)))
+	})
+
+	t.Run("never trips when the threshold is zero", func(t *testing.T) {
+		b := newBreaker(0, time.Minute)
+
+		for i := 0; i < 100; i++ {
+			assert.False(t, b.fail(start))
+		}
+	})
+}
@@ -2,6 +2,8 @@ package connector
 
 import (
 	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
 )
 
 // ConnectorVersion stores the version of the ShellHub Instane that is running the connector.
@@ -18,6 +20,8 @@ type Container struct {
 	ServerAddress string
 	// Tenant is the tenant ID of the namespace that the agent belongs to.
 	Tenant string
+	// EnrollmentKey is the enrollment key of the namespace, required to register the device when it has one.
+	EnrollmentKey string
 	// PrivateKey is the private key of the device. Specify the path to store the container private key. If not
 	// provided, the agent will generate a new one. This is required.
 	PrivateKey string
@@ -35,4 +39,21 @@ type Connector interface {
 	Stop(ctx context.Context, id string)
 	// Listen listens for events and starts or stops the agent for the container that was created or removed.
 	Listen(ctx context.Context) error
+	// Health aggregates the status of the agents started by the connector.
+	Health() Health
+	// SyncLag returns the distribution of the time, in seconds, between the Docker events and the corresponding
+	// changes of the devices on ShellHub for each sync action.
+	SyncLag() map[string]Histogram
+	// ExecTimeoutCount returns the number of sessions, opened in the containers, whose exec timed out.
+	ExecTimeoutCount() int
+	// Enable enables again the agent for the container with the given ID, disabled after failing repeatedly. It
+	// reports whether the agent was disabled.
+	Enable(id string) bool
+	// Tenant returns the tenant ID of the namespace the agents started by the connector belong to.
+	Tenant() string
+	// SelfTest tests, end to end, what the connector depends on, without affecting the agents it started.
+	SelfTest(ctx context.Context) SelfTestReport
+	// Statuses lists the status of the agents started by every replica of the connector, when their statuses are
+	// kept on a [ConnectorStore], or only by this one otherwise.
+	Statuses(ctx context.Context) ([]models.ConnectorStatus, error)
 }
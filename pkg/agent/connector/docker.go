@@ -3,15 +3,21 @@ package connector
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
 	dockerclient "github.com/docker/docker/client"
 	"github.com/shellhub-io/shellhub/pkg/agent"
+	connectormode "github.com/shellhub-io/shellhub/pkg/agent/server/modes/connector"
+	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/envs"
+	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/pkg/validator"
 	log "github.com/sirupsen/logrus"
 )
@@ -25,6 +31,8 @@ type DockerConnector struct {
 	server string
 	// tenant is the tenant ID of the namespace that the agent belongs to.
 	tenant string
+	// enrollmentKey is the enrollment key of the namespace, required to register the devices when it has one.
+	enrollmentKey string
 	// cli is the Docker client.
 	cli *dockerclient.Client
 	// privateKeys is the path to the directory that contains the private keys for the containers.
@@ -32,6 +40,48 @@ type DockerConnector struct {
 	// cancels is a map that contains the cancel functions for each container.
 	// This is used to stop the agent for a container, marking as done its context and closing the agent.
 	cancels map[string]context.CancelFunc
+	// statuses is a map that contains the status of the agent for each container.
+	statuses map[string]string
+	// failures is a map that contains the error the agent failed with for each container.
+	failures map[string]Error
+	// reconcileInterval is the interval between the full reconciliations of the running containers, what catches the
+	// events missed by the listener. When zero, the reconciliation is disabled.
+	reconcileInterval time.Duration
+	// maxAgents is the maximum number of agents started at the same time. When zero, there is no limit.
+	maxAgents int
+	// breakers is a map that contains the breaker of the agent for each container, disabling it after repeated
+	// failures.
+	breakers map[string]*breaker
+	// failureThreshold is the number of consecutive failures, inside failureWindow, after which the agent of a
+	// container is disabled. When zero, the agents are never disabled.
+	failureThreshold int
+	// failureWindow is the window the consecutive failures of an agent are counted in.
+	failureWindow time.Duration
+	// names is a map that contains the name of each container whose agent was started, used to restart it.
+	names map[string]string
+	// restarts is a map that contains how many times, and when, the agent of each container was restarted after
+	// failing.
+	restarts map[string]Restart
+	// watchdogInterval is the interval between the restarts of the failed agents. When zero, the failed agents are
+	// only started again on the reconciliations.
+	watchdogInterval time.Duration
+	// syncLag is a map that contains the distribution of the time between the Docker events and the corresponding
+	// changes of the devices for each sync action.
+	syncLag map[string]*Histogram
+	// apiTimeout is the time limit of each request to the Docker Engine API, except the events stream and the ones
+	// made by the agents. When zero, the requests aren't limited.
+	apiTimeout time.Duration
+	// execTimeout is the time limit to create and start the exec of each session opened in the containers. When zero,
+	// it isn't limited.
+	execTimeout time.Duration
+	// execCommandTimeout is the time limit of the commands run by the exec sessions opened in the containers. When
+	// zero, the commands aren't limited.
+	execCommandTimeout time.Duration
+	// execTimeouts is the number of sessions whose exec timed out.
+	execTimeouts int
+	// statusWriter saves the status changes of the agents on the store shared by the replicas. When nil, the statuses
+	// are only kept in memory.
+	statusWriter *statusWriter
 }
 
 // Config provides the configuration for the agent connector service.
@@ -50,10 +100,93 @@ type Config struct {
 	// This is required.
 	TenantID string `env:"TENANT_ID,required"`
 
+	// Sets the namespace's enrollment key, required to register the devices of
+	// the containers when the namespace has one. Devices already registered
+	// don't need it.
+	EnrollmentKey string `env:"ENROLLMENT_KEY"`
+
 	// Determine the interval to send the keep alive message to the server. This
 	// has a direct impact of the bandwidth used by the device when in idle
 	// state. Default is 30 seconds.
 	KeepAliveInterval int `env:"KEEPALIVE_INTERVAL,default=30"`
+
+	// Set the minimum TLS version used to reach the Docker Engine when it is exposed through TLS. Versions lower than
+	// 1.2 are not allowed.
+	TLSMinVersion string `env:"TLS_MIN_VERSION,default=1.2"`
+
+	// Set the comma-separated list of cipher suites allowed to reach the Docker Engine when it is exposed through TLS.
+	// If not provided, only secure cipher suites with forward secrecy are allowed.
+	TLSCipherSuites []string `env:"TLS_CIPHER_SUITES"`
+
+	// Determine the interval, in seconds, to reconcile the running containers with the started agents. Containers
+	// are started and stopped as soon as Docker reports it, so the reconciliation only catches the events that were
+	// missed. Set it to 0 to disable. Default is 300 seconds.
+	ReconcileInterval int `env:"RECONCILE_INTERVAL,default=300" validate:"min=0"`
+
+	// Set the maximum number of agents started at the same time, each one holding its own connection to the server.
+	// Containers beyond the limit are skipped until a slot is freed and they are reconciled. Set it to 0 to disable.
+	// Default is 0.
+	MaxAgents int `env:"MAX_AGENTS,default=0" validate:"min=0"`
+
+	// Set the number of consecutive failures, inside the failure window, after which the agent of a container is
+	// disabled and no longer retried. It's enabled again when the container is started again or through the enable
+	// endpoint. Set it to 0 to disable. Default is 5.
+	FailureThreshold int `env:"FAILURE_THRESHOLD,default=5" validate:"min=0"`
+
+	// Set the window, in seconds, the consecutive failures of an agent are counted in. Default is 600 seconds.
+	FailureWindow int `env:"FAILURE_WINDOW,default=600" validate:"min=0"`
+
+	// Determine the interval, in seconds, to restart the agents that failed, what is cheaper than a full
+	// reconciliation as the containers aren't listed. The restarts are capped by the failure threshold. Set it to 0 to
+	// disable, leaving the failed agents to the reconciliation. Default is 30 seconds.
+	WatchdogInterval int `env:"WATCHDOG_INTERVAL,default=30" validate:"min=0"`
+
+	// Set the address where the connector serves its health, metrics and version over HTTP. If not provided, they are
+	// not served.
+	HTTPAddress string `env:"CONNECTOR_HTTP_ADDRESS"`
+
+	// Set the path where the connector serves its health. Default is /health. It can't be /version, where the
+	// connector serves its version.
+	HealthPath string `env:"CONNECTOR_HEALTH_PATH,default=/health" validate:"startswith=/,ne=/version"`
+
+	// Set the path where the connector serves its metrics in the Prometheus format. Default is /metrics. It can't be
+	// /version, where the connector serves its version.
+	MetricsPath string `env:"CONNECTOR_METRICS_PATH,default=/metrics" validate:"startswith=/,nefield=HealthPath,ne=/version"`
+
+	// Set the token required, as a bearer token on the Authorization header, by every route of the connector's HTTP
+	// API, like its health, metrics, self-test and version. If not provided, the routes are served without
+	// authentication and only the requests from the loopback interface can enable an auto-disabled agent.
+	EnableToken string `env:"CONNECTOR_ENABLE_TOKEN"`
+
+	// Set the time limit of each request to the Docker Engine API made to list and inspect the containers. The events
+	// stream and the sessions opened in the containers aren't limited. Set it to 0 to disable. Default is 30 seconds.
+	DockerAPITimeout time.Duration `env:"CONNECTOR_DOCKER_API_TIMEOUT,default=30s" validate:"min=0"`
+
+	// Set the time limit to dial the Docker Engine, applied to every connection made to it. Set it to 0 to use the
+	// Docker client's one. Default is 10 seconds.
+	DockerAPIDialTimeout time.Duration `env:"CONNECTOR_DOCKER_API_DIAL_TIMEOUT,default=10s" validate:"min=0"`
+
+	// Set the time limit to create and start the exec of each session opened in the containers. The sessions whose
+	// exec isn't started in time are closed with an error. Set it to 0 to disable. Default is 30 seconds.
+	ExecTimeout time.Duration `env:"CONNECTOR_EXEC_TIMEOUT,default=30s" validate:"min=0"`
+
+	// Set the time limit of the commands run by the exec sessions, like `ssh device command`, killing them once it's
+	// exceeded. The shell sessions aren't limited. Set it to 0 to disable. Default is 0.
+	ExecCommandTimeout time.Duration `env:"CONNECTOR_EXEC_COMMAND_TIMEOUT,default=0" validate:"min=0"`
+
+	// Set the URI of the Redis instance shared by the replicas of the connector of the same tenant, so only one of
+	// them starts the agents at a time while the others wait to take over. If not provided, the replicas aren't
+	// coordinated.
+	LockRedisURI string `env:"CONNECTOR_LOCK_REDIS_URI"`
+
+	// Set the time the lock of the replica driving the connector lasts when it isn't renewed, what frees it when the
+	// replica crashes. It's renewed three times inside it. Default is 30 seconds.
+	LockTTL time.Duration `env:"CONNECTOR_LOCK_TTL,default=30s" validate:"min=1s"`
+
+	// Set the URI, with the database, of the MongoDB instance shared by the replicas of the connector to keep the
+	// status of the agents each one started, served by all of them on the status route. If not provided, each replica
+	// only serves the status of its own agents.
+	StatusMongoURI string `env:"CONNECTOR_STATUS_MONGO_URI"`
 }
 
 func LoadConfigFromEnv() (*Config, map[string]interface{}, error) {
@@ -69,33 +202,104 @@ func LoadConfigFromEnv() (*Config, map[string]interface{}, error) {
 		return nil, fields, err
 	}
 
+	if _, err := NewTLSConfig(cfg.TLSMinVersion, cfg.TLSCipherSuites); err != nil {
+		log.WithError(err).Error("failed to validate the TLS configuration loaded from envs")
+
+		return nil, map[string]interface{}{"tls": err.Error()}, err
+	}
+
 	return cfg, nil, nil
 }
 
-// NewDockerConnector creates a new [Connector] that uses Docker as the container runtime.
-func NewDockerConnector(server string, tenant string, privateKey string) (Connector, error) {
-	cli, err := dockerclient.NewClientWithOpts(dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation())
+// NewDockerConnector creates a new [Connector] that uses Docker as the container runtime, configured by cfg.
+func NewDockerConnector(cfg *Config) (Connector, error) {
+	tlsConfig, err := NewTLSConfig(cfg.TLSMinVersion, cfg.TLSCipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := dockerclient.NewClientWithOpts(dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation(), withTLSConfig(tlsConfig), withDialTimeout(cfg.DockerAPIDialTimeout))
 	if err != nil {
 		return nil, err
 	}
 
+	if err := logNegotiatedTLS(cli); err != nil {
+		log.WithError(err).Error("failed to negotiate TLS with the Docker Engine")
+
+		return nil, err
+	}
+
+	var writer *statusWriter
+	if cfg.StatusMongoURI != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), statusWriteTimeout)
+		defer cancel()
+
+		replica, _ := os.Hostname()
+
+		store, err := NewMongoConnectorStore(ctx, cfg.StatusMongoURI, replica)
+		if err != nil {
+			log.WithError(err).Error("failed to connect to the store of the agents' statuses")
+
+			return nil, err
+		}
+
+		writer = newStatusWriter(store, cfg.TenantID)
+	}
+
 	return &DockerConnector{
-		server:      server,
-		tenant:      tenant,
-		cli:         cli,
-		privateKeys: privateKey,
-		cancels:     make(map[string]context.CancelFunc),
+		server:        cfg.ServerAddress,
+		tenant:        cfg.TenantID,
+		enrollmentKey: cfg.EnrollmentKey,
+		cli:           cli,
+		privateKeys:   cfg.PrivateKeys,
+		cancels:       make(map[string]context.CancelFunc),
+		statuses:      make(map[string]string),
+		failures:      make(map[string]Error),
+		breakers:      make(map[string]*breaker),
+		names:         make(map[string]string),
+		restarts:      make(map[string]Restart),
+		syncLag:       make(map[string]*Histogram),
+
+		reconcileInterval: time.Duration(cfg.ReconcileInterval) * time.Second,
+		maxAgents:         cfg.MaxAgents,
+		failureThreshold:  cfg.FailureThreshold,
+		failureWindow:     time.Duration(cfg.FailureWindow) * time.Second,
+		watchdogInterval:  time.Duration(cfg.WatchdogInterval) * time.Second,
+		apiTimeout:        cfg.DockerAPITimeout,
+
+		execTimeout:        cfg.ExecTimeout,
+		execCommandTimeout: cfg.ExecCommandTimeout,
+
+		statusWriter: writer,
 	}, nil
 }
 
-// events returns the docker events.
+func (d *DockerConnector) Tenant() string {
+	return d.tenant
+}
+
+// events returns the docker events related to containers.
 func (d *DockerConnector) events(ctx context.Context) (<-chan events.Message, <-chan error) {
-	return d.cli.Events(ctx, types.EventsOptions{})
+	return d.cli.Events(ctx, types.EventsOptions{Filters: filters.NewArgs(filters.Arg("type", string(events.ContainerEventType)))})
+}
+
+// ping checks that the Docker Engine API is reachable.
+func (d *DockerConnector) ping(ctx context.Context) error {
+	return withAPITimeout(ctx, d.cli.DaemonHost(), d.apiTimeout, func(ctx context.Context) error {
+		_, err := d.cli.Ping(ctx)
+
+		return err
+	})
 }
 
 func (d *DockerConnector) List(ctx context.Context) ([]Container, error) {
-	containers, err := d.cli.ContainerList(ctx, container.ListOptions{})
-	if err != nil {
+	var containers []types.Container
+	if err := withAPITimeout(ctx, d.cli.DaemonHost(), d.apiTimeout, func(ctx context.Context) error {
+		var err error
+		containers, err = d.cli.ContainerList(ctx, container.ListOptions{})
+
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
@@ -114,27 +318,221 @@ func (d *DockerConnector) List(ctx context.Context) ([]Container, error) {
 	return list, nil
 }
 
-// Start starts the agent for the container with the given ID.
+// Start starts the agent for the container with the given ID. It does nothing when the agent is already started or
+// the limit of agents started at the same time was reached.
 func (d *DockerConnector) Start(ctx context.Context, id string, name string) {
+	d.start(ctx, id, name, time.Time{})
+}
+
+// start starts the agent for the container with the given ID, like [DockerConnector.Start], measuring the sync lag
+// from eventAt, the time of the Docker event that started the container, if not zero. It reports whether the agent
+// was started.
+func (d *DockerConnector) start(ctx context.Context, id string, name string, eventAt time.Time) bool {
 	id = id[:12]
 
-	d.mu.Lock()
-	ctx, d.cancels[id] = context.WithCancel(ctx)
-	d.mu.Unlock()
+	ctx, cancel, ok := d.track(ctx, id, name)
+	if !ok {
+		return false
+	}
 
 	privateKey := fmt.Sprintf("%s/%s.key", d.privateKeys, id)
-	go initContainerAgent(ctx, d.cli, Container{
-		ID:            id,
-		Name:          name,
-		ServerAddress: d.server,
-		Tenant:        d.tenant,
-		PrivateKey:    privateKey,
-		Cancel:        d.cancels[id],
+	go func() {
+		started := func() {
+			d.setStatus(id, StatusStarted)
+			d.succeed(id)
+			d.observeSyncLag(SyncActionStart, eventAt)
+		}
+
+		timeouts := connectormode.Timeouts{
+			Exec:          d.execTimeout,
+			Command:       d.execCommandTimeout,
+			OnExecTimeout: d.observeExecTimeout,
+		}
+
+		if err := initContainerAgent(ctx, d.cli, timeouts, Container{
+			ID:            id,
+			Name:          name,
+			ServerAddress: d.server,
+			Tenant:        d.tenant,
+			EnrollmentKey: d.enrollmentKey,
+			PrivateKey:    privateKey,
+			Cancel:        cancel,
+		}, started); err != nil {
+			d.fail(id, err)
+		}
+	}()
+
+	return true
+}
+
+// track reserves a slot for the agent of the container with the given ID and name, returning the context the agent
+// must run on. It reports false when the agent is already started, was disabled or the limit of agents was reached.
+func (d *DockerConnector) track(ctx context.Context, id string, name string) (context.Context, context.CancelFunc, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.cancels[id]; ok {
+		return nil, nil, false
+	}
+
+	if d.statuses[id] == StatusDisabled {
+		return nil, nil, false
+	}
+
+	if d.maxAgents > 0 && len(d.cancels) >= d.maxAgents {
+		log.WithFields(log.Fields{"id": id, "max_agents": d.maxAgents}).
+			Warn("skipping the container because the limit of agents started by the connector was reached")
+
+		return nil, nil, false
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	d.cancels[id] = cancel
+	d.statuses[id] = StatusConnected
+	d.saveStatus(id, StatusConnected)
+	d.names[id] = name
+	delete(d.failures, id)
+
+	return ctx, cancel, true
+}
+
+// setStatus sets the status of the agent for the container with the given ID, if it's still started.
+func (d *DockerConnector) setStatus(id string, status string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.cancels[id]; ok {
+		d.statuses[id] = status
+		d.saveStatus(id, status)
+	}
+}
+
+// saveStatus saves the status of the agent for the container with the given ID on the store shared by the replicas,
+// if any. An empty status deletes it. It must be called holding the connector's lock, so the changes are saved in the
+// order they happened.
+func (d *DockerConnector) saveStatus(id string, status string) {
+	if d.statusWriter != nil {
+		d.statusWriter.save(id, status)
+	}
+}
+
+// Statuses lists the status of the agents started by every replica of the connector from the store they share, or
+// only the ones of the agents started by this one when there is no store.
+func (d *DockerConnector) Statuses(ctx context.Context) ([]models.ConnectorStatus, error) {
+	if d.statusWriter != nil {
+		return d.statusWriter.store.GetConnectionStatus(ctx, d.tenant)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	replica, _ := os.Hostname()
+
+	statuses := make([]models.ConnectorStatus, 0, len(d.statuses))
+	for id, status := range d.statuses {
+		statuses = append(statuses, models.ConnectorStatus{TenantID: d.tenant, Address: id, Status: status, Replica: replica})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Address < statuses[j].Address
 	})
+
+	return statuses, nil
+}
+
+// fail marks the agent for the container with the given ID as failed with err, releasing its slot, so it's started
+// again on the next reconciliation. When the agent failed repeatedly, it's disabled instead and no longer started
+// until enabled again. It does nothing when the agent was stopped in the meantime.
+func (d *DockerConnector) fail(id string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cancel, ok := d.cancels[id]
+	if !ok {
+		return
+	}
+
+	cancel()
+	delete(d.cancels, id)
+	d.statuses[id] = StatusFailed
+	d.failures[id] = newError(err)
+
+	b, ok := d.breakers[id]
+	if !ok {
+		b = newBreaker(d.failureThreshold, d.failureWindow)
+		d.breakers[id] = b
+	}
+
+	if b.fail(clock.Now()) {
+		log.WithError(err).WithFields(log.Fields{"id": id, "failures": d.failureThreshold, "restarts": d.restarts[id].Count}).
+			Error("agent auto-disabled due to repeated failures")
+
+		d.statuses[id] = StatusDisabled
+		d.failures[id] = Error{Code: ErrCodeAutoDisabled, Message: "auto-disabled due to repeated failures", Details: err.Error()}
+	}
+
+	d.saveStatus(id, d.statuses[id])
+}
+
+// succeed resets the breaker of the agent for the container with the given ID, as its failures are no longer
+// consecutive.
+func (d *DockerConnector) succeed(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if b, ok := d.breakers[id]; ok {
+		b.reset()
+	}
+}
+
+// Enable enables again the agent for the container with the given ID, disabled after failing repeatedly, resetting
+// its breaker. The agent is started on the next reconciliation or when the container is started again. It reports
+// whether the agent was disabled.
+func (d *DockerConnector) Enable(id string) bool {
+	if len(id) > 12 {
+		id = id[:12]
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.breakers, id)
+
+	if d.statuses[id] != StatusDisabled {
+		return false
+	}
+
+	delete(d.statuses, id)
+	delete(d.failures, id)
+	d.saveStatus(id, "")
+
+	return true
+}
+
+// Health aggregates the status of the agents started by the connector.
+func (d *DockerConnector) Health() Health {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	health := newHealth(d.statuses, d.failures)
+	for id, restart := range d.restarts {
+		if health.Restarts == nil {
+			health.Restarts = make(map[string]Restart)
+		}
+
+		health.Restarts[id] = restart
+	}
+
+	return health
 }
 
 // Stop stops the agent for the container with the given ID.
 func (d *DockerConnector) Stop(_ context.Context, id string) {
+	d.stop(id)
+}
+
+// stop stops the agent for the container with the given ID, reporting whether it was started.
+func (d *DockerConnector) stop(id string) bool {
 	id = id[:12]
 
 	d.mu.Lock()
@@ -145,11 +543,73 @@ func (d *DockerConnector) Stop(_ context.Context, id string) {
 		cancel()
 		delete(d.cancels, id)
 	}
+
+	if _, ok := d.statuses[id]; ok {
+		d.saveStatus(id, "")
+	}
+
+	delete(d.statuses, id)
+	delete(d.failures, id)
+	delete(d.breakers, id)
+	delete(d.names, id)
+	delete(d.restarts, id)
+
+	return ok
+}
+
+// restartFailed restarts, through start, the agents that failed, recording each restart. The restarts are capped by
+// the breaker of each agent, which disables it after repeated failures. A restart refused by start, as when the limit
+// of agents was reached, isn't recorded. It returns the number of restarted agents.
+func (d *DockerConnector) restartFailed(ctx context.Context, start func(ctx context.Context, id string, name string) bool) int {
+	d.mu.Lock()
+	failed := make([]Container, 0)
+	for id, status := range d.statuses {
+		if status != StatusFailed {
+			continue
+		}
+
+		failed = append(failed, Container{ID: id, Name: d.names[id]})
+	}
+	d.mu.Unlock()
+
+	restarted := 0
+	for _, container := range failed {
+		if !start(ctx, container.ID, container.Name) {
+			continue
+		}
+
+		d.mu.Lock()
+		restart := d.restarts[container.ID]
+		restart.Count++
+		restart.LastAt = clock.Now()
+		d.restarts[container.ID] = restart
+		d.mu.Unlock()
+
+		restarted++
+	}
+
+	return restarted
+}
+
+// restartFailedWhenReady restarts the failed agents, like [DockerConnector.restartFailed], only when the Docker Engine
+// API is reachable. Otherwise, the agents would fail again right after being restarted, wearing out their breakers for
+// nothing, so an error is returned instead and they're kept as failed.
+func (d *DockerConnector) restartFailedWhenReady(ctx context.Context, start func(ctx context.Context, id string, name string) bool) (int, error) {
+	if err := d.ping(ctx); err != nil {
+		return 0, fmt.Errorf("the Docker Engine API is not reachable: %w", err)
+	}
+
+	return d.restartFailed(ctx, start), nil
 }
 
 func (d *DockerConnector) getContainerNameFromID(ctx context.Context, id string) (string, error) {
-	container, err := d.cli.ContainerInspect(ctx, id)
-	if err != nil {
+	var container types.ContainerJSON
+	if err := withAPITimeout(ctx, d.cli.DaemonHost(), d.apiTimeout, func(ctx context.Context) error {
+		var err error
+		container, err = d.cli.ContainerInspect(ctx, id)
+
+		return err
+	}); err != nil {
 		return "", err
 	}
 
@@ -157,17 +617,114 @@ func (d *DockerConnector) getContainerNameFromID(ctx context.Context, id string)
 	return container.Name[1:], nil
 }
 
-// Listen listens for events and starts or stops the agent for the containers.
-func (d *DockerConnector) Listen(ctx context.Context) error {
+// reconcile starts the agents for the running containers that don't have one and stops the agents of containers that
+// are not running anymore.
+func (d *DockerConnector) reconcile(ctx context.Context) error {
 	containers, err := d.List(ctx)
 	if err != nil {
 		return err
 	}
 
-	for _, container := range containers {
+	d.mu.Lock()
+	started := make([]string, 0, len(d.cancels))
+	for id := range d.cancels {
+		started = append(started, id)
+	}
+
+	// NOTICE: the disabled agents are handled as started, so they aren't retried, but are cleaned up when their
+	// containers stop running.
+	for id, status := range d.statuses {
+		if status == StatusDisabled {
+			started = append(started, id)
+		}
+	}
+	d.mu.Unlock()
+
+	start, stop := diffContainers(containers, started)
+	for _, container := range start {
 		d.Start(ctx, container.ID, container.Name)
 	}
 
+	for _, id := range stop {
+		d.Stop(ctx, id)
+	}
+
+	if len(start) > 0 || len(stop) > 0 {
+		log.WithFields(log.Fields{
+			"started": len(start),
+			"stopped": len(stop),
+		}).Info("Connector reconciled the running containers")
+	}
+
+	return nil
+}
+
+// diffContainers compares the running containers with the IDs of the started agents, returning the containers whose
+// agent must be started and the IDs of the agents that must be stopped. The started IDs are the short, 12 characters,
+// form of the container's ID.
+func diffContainers(running []Container, started []string) ([]Container, []string) {
+	short := func(id string) string {
+		if len(id) > 12 {
+			return id[:12]
+		}
+
+		return id
+	}
+
+	alive := make(map[string]struct{}, len(running))
+	for _, container := range running {
+		alive[short(container.ID)] = struct{}{}
+	}
+
+	tracked := make(map[string]struct{}, len(started))
+	for _, id := range started {
+		tracked[id] = struct{}{}
+	}
+
+	start := make([]Container, 0)
+	for _, container := range running {
+		if _, ok := tracked[short(container.ID)]; !ok {
+			start = append(start, container)
+		}
+	}
+
+	stop := make([]string, 0)
+	for _, id := range started {
+		if _, ok := alive[id]; !ok {
+			stop = append(stop, id)
+		}
+	}
+
+	return start, stop
+}
+
+// Listen listens for events and starts or stops the agent for the containers.
+func (d *DockerConnector) Listen(ctx context.Context) error {
+	if err := d.ping(ctx); err != nil {
+		return err
+	}
+
+	if err := d.reconcile(ctx); err != nil {
+		return err
+	}
+
+	// NOTICE: a nil channel blocks forever, what disables the reconciliation when no interval is defined.
+	var reconciliations <-chan time.Time
+	if d.reconcileInterval > 0 {
+		ticker := time.NewTicker(d.reconcileInterval)
+		defer ticker.Stop()
+
+		reconciliations = ticker.C
+	}
+
+	var watchdogs <-chan time.Time
+	if d.watchdogInterval > 0 {
+		ticker := time.NewTicker(d.watchdogInterval)
+		defer ticker.Stop()
+
+		watchdogs = ticker.C
+	}
+
 	events, errs := d.events(ctx)
 	for {
 		select {
@@ -175,6 +732,23 @@ func (d *DockerConnector) Listen(ctx context.Context) error {
 			return nil
 		case err := <-errs:
 			return err
+		case <-reconciliations:
+			if err := d.reconcile(ctx); err != nil {
+				log.WithError(err).Warn("Failed to reconcile the running containers")
+			}
+		case <-watchdogs:
+			restarted, err := d.restartFailedWhenReady(ctx, func(ctx context.Context, id string, name string) bool {
+				return d.start(ctx, id, name, time.Time{})
+			})
+			if err != nil {
+				log.WithError(err).Warn("Connector postponed the restart of the failed agents")
+
+				continue
+			}
+
+			if restarted > 0 {
+				log.WithField("restarted", restarted).Info("Connector restarted the failed agents")
+			}
 		case container := <-events:
 			// NOTICE: "start" and "die" Docker's events are call every time a new container start or stop,
 			// independently how the command was run. For example, if a container was started with `docker run -d`, the
@@ -187,22 +761,32 @@ func (d *DockerConnector) Listen(ctx context.Context) error {
 					return err
 				}
 
-				d.Start(ctx, container.ID, name)
-			case "die":
-				d.Stop(ctx, container.ID)
+				// NOTICE: starting a container again is a manual action, so its agent is enabled again if it was
+				// disabled after failing repeatedly.
+				d.Enable(container.ID)
+				d.start(ctx, container.ID, name, eventTime(container))
+			case "die", "destroy":
+				// NOTICE: a container that dies is destroyed later, but its agent is only stopped, and its lag
+				// measured, once.
+				if d.stop(container.ID) {
+					d.observeSyncLag(SyncActionStop, eventTime(container))
+				}
 			}
 		}
 	}
 }
 
-// initContainerAgent initializes the agent for a container.
-func initContainerAgent(ctx context.Context, cli *dockerclient.Client, container Container) {
-	agent.AgentPlatform = "connector"
+// initContainerAgent initializes the agent for a container, calling started once it's listening for connections, with
+// its sessions limited by timeouts. It blocks until the agent is closed, returning an error when it fails to connect or
+// to listen for connections.
+func initContainerAgent(ctx context.Context, cli *dockerclient.Client, timeouts connectormode.Timeouts, container Container, started func()) error {
+	agent.AgentPlatform = models.DevicePlatformConnector
 	agent.AgentVersion = ConnectorVersion
 
 	cfg := &agent.Config{
 		ServerAddress:     container.ServerAddress,
 		TenantID:          container.Tenant,
+		EnrollmentKey:     container.EnrollmentKey,
 		PrivateKey:        container.PrivateKey,
 		PreferredIdentity: container.ID,
 		PreferredHostname: container.Name,
@@ -219,7 +803,7 @@ func initContainerAgent(ctx context.Context, cli *dockerclient.Client, container
 		"version":        agent.AgentVersion,
 	}).Info("Connector container started")
 
-	mode, err := agent.NewConnectorMode(cli, container.ID)
+	mode, err := agent.NewConnectorMode(cli, container.ID, timeouts)
 	if err != nil {
 		log.WithError(err).WithFields(log.Fields{
 			"id":             container.ID,
@@ -229,7 +813,9 @@ func initContainerAgent(ctx context.Context, cli *dockerclient.Client, container
 			"server_address": cfg.ServerAddress,
 			"timestamp":      time.Now(),
 			"version":        agent.AgentVersion,
-		}).Fatal("Failed to create connector mode")
+		}).Error("Failed to create connector mode")
+
+		return err
 	}
 
 	ag, err := agent.NewAgentWithConfig(cfg, mode)
@@ -238,7 +824,9 @@ func initContainerAgent(ctx context.Context, cli *dockerclient.Client, container
 			"id":            container.ID,
 			"configuration": cfg,
 			"version":       agent.AgentVersion,
-		}).Fatal("Failed to create agent")
+		}).Error("Failed to create agent")
+
+		return err
 	}
 
 	if err := ag.Initialize(); err != nil {
@@ -246,9 +834,13 @@ func initContainerAgent(ctx context.Context, cli *dockerclient.Client, container
 			"id":            container.ID,
 			"configuration": cfg,
 			"version":       agent.AgentVersion,
-		}).Fatal("Failed to initialize agent")
+		}).Error("Failed to initialize agent")
+
+		return err
 	}
 
+	// NOTICE: a failure to ping the server closes the agent, being reported as its failure.
+	pinged := make(chan error, 1)
 	go func() {
 		if err := ag.Ping(ctx, agent.AgentPingDefaultInterval); err != nil {
 			log.WithError(err).WithFields(log.Fields{
@@ -259,7 +851,12 @@ func initContainerAgent(ctx context.Context, cli *dockerclient.Client, container
 				"server_address": cfg.ServerAddress,
 				"timestamp":      time.Now(),
 				"version":        agent.AgentVersion,
-			}).Fatal("Failed to ping server")
+			}).Error("Failed to ping server")
+
+			pinged <- err
+			container.Cancel()
+
+			return
 		}
 
 		log.WithFields(log.Fields{
@@ -283,6 +880,8 @@ func initContainerAgent(ctx context.Context, cli *dockerclient.Client, container
 		"version":        agent.AgentVersion,
 	}).Info("Listening for connections")
 
+	started()
+
 	// NOTICE(r): listing for connection and wait for a channel message to close the agent. It will receives
 	// this mensagem when something out of this goroutine send a `done`, what will cause the agent closes
 	// and no more connection to be allowed until it be started again.
@@ -295,7 +894,15 @@ func initContainerAgent(ctx context.Context, cli *dockerclient.Client, container
 			"server_address": cfg.ServerAddress,
 			"timestamp":      time.Now(),
 			"version":        agent.AgentVersion,
-		}).Fatal("Failed to listen for connections")
+		}).Error("Failed to listen for connections")
+
+		return err
+	}
+
+	select {
+	case err := <-pinged:
+		return err
+	default:
 	}
 
 	log.WithFields(log.Fields{
@@ -306,4 +913,6 @@ func initContainerAgent(ctx context.Context, cli *dockerclient.Client, container
 		"server_address": cfg.ServerAddress,
 		"version":        agent.AgentVersion,
 	}).Info("Connector container done")
+
+	return nil
 }
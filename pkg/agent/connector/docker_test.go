@@ -0,0 +1,352 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	dockerclient "github.com/docker/docker/client"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmock "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffContainers(t *testing.T) {
+	type Expected struct {
+		start []Container
+		stop  []string
+	}
+
+	cases := []struct {
+		description string
+		running     []Container
+		started     []string
+		expected    Expected
+	}{
+		{
+			description: "succeeds with nothing to do when all running containers are started",
+			running:     []Container{{ID: "0123456789abcdef0123", Name: "one"}},
+			started:     []string{"0123456789ab"},
+			expected:    Expected{start: []Container{}, stop: []string{}},
+		},
+		{
+			description: "succeeds starting the running containers that were missed",
+			running: []Container{
+				{ID: "0123456789abcdef0123", Name: "one"},
+				{ID: "fedcba9876543210fedc", Name: "two"},
+			},
+			started: []string{"0123456789ab"},
+			expected: Expected{
+				start: []Container{{ID: "fedcba9876543210fedc", Name: "two"}},
+				stop:  []string{},
+			},
+		},
+		{
+			description: "succeeds stopping the containers that are not running anymore",
+			running:     []Container{{ID: "0123456789abcdef0123", Name: "one"}},
+			started:     []string{"0123456789ab", "fedcba987654"},
+			expected:    Expected{start: []Container{}, stop: []string{"fedcba987654"}},
+		},
+		{
+			description: "succeeds starting and stopping at once",
+			running:     []Container{{ID: "fedcba9876543210fedc", Name: "two"}},
+			started:     []string{"0123456789ab"},
+			expected: Expected{
+				start: []Container{{ID: "fedcba9876543210fedc", Name: "two"}},
+				stop:  []string{"0123456789ab"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			start, stop := diffContainers(tc.running, tc.started)
+			assert.Equal(t, tc.expected, Expected{start: start, stop: stop})
+		})
+	}
+}
+
+func TestTrack(t *testing.T) {
+	cases := []struct {
+		description string
+		maxAgents   int
+		started     []string
+		id          string
+		expected    bool
+	}{
+		{
+			description: "fails when the agent is already started",
+			maxAgents:   0,
+			started:     []string{"0123456789ab"},
+			id:          "0123456789ab",
+			expected:    false,
+		},
+		{
+			description: "fails when the limit of agents was reached",
+			maxAgents:   1,
+			started:     []string{"0123456789ab"},
+			id:          "ba9876543210",
+			expected:    false,
+		},
+		{
+			description: "succeeds when there is no limit",
+			maxAgents:   0,
+			started:     []string{"0123456789ab"},
+			id:          "ba9876543210",
+			expected:    true,
+		},
+		{
+			description: "succeeds when the limit was not reached",
+			maxAgents:   2,
+			started:     []string{"0123456789ab"},
+			id:          "ba9876543210",
+			expected:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			d := &DockerConnector{
+				cancels:   make(map[string]context.CancelFunc),
+				statuses:  make(map[string]string),
+				failures:  make(map[string]Error),
+				names:     make(map[string]string),
+				maxAgents: tc.maxAgents,
+			}
+			for _, id := range tc.started {
+				d.cancels[id] = func() {}
+			}
+
+			_, cancel, ok := d.track(context.Background(), tc.id, "container")
+			assert.Equal(t, tc.expected, ok)
+			assert.Equal(t, tc.expected, cancel != nil)
+
+			if ok {
+				assert.Contains(t, d.cancels, tc.id)
+				assert.Equal(t, StatusConnected, d.statuses[tc.id])
+				cancel()
+			}
+		})
+	}
+}
+
+func TestFail(t *testing.T) {
+	d := &DockerConnector{
+		cancels:  make(map[string]context.CancelFunc),
+		statuses: make(map[string]string),
+		failures: make(map[string]Error),
+		breakers: make(map[string]*breaker),
+		names:    make(map[string]string),
+		restarts: make(map[string]Restart),
+	}
+
+	ctx, _, ok := d.track(context.Background(), "0123456789ab", "container")
+	assert.True(t, ok)
+
+	d.fail("0123456789ab", syscall.ECONNREFUSED)
+	assert.Error(t, ctx.Err())
+	assert.NotContains(t, d.cancels, "0123456789ab")
+	assert.Equal(t, StatusFailed, d.statuses["0123456789ab"])
+	assert.Equal(t, ErrCodeConnectionRefused, d.failures["0123456789ab"].Code)
+
+	// A failed agent is started again on the next reconciliation.
+	_, cancel, ok := d.track(context.Background(), "0123456789ab", "container")
+	assert.True(t, ok)
+	assert.Equal(t, StatusConnected, d.statuses["0123456789ab"])
+	assert.NotContains(t, d.failures, "0123456789ab")
+	cancel()
+
+	// A stopped agent is not marked as failed.
+	d.Stop(context.Background(), "0123456789ab")
+	d.fail("0123456789ab", syscall.ECONNREFUSED)
+	assert.NotContains(t, d.statuses, "0123456789ab")
+}
+
+func TestFailDisables(t *testing.T) {
+	d := &DockerConnector{
+		cancels:          make(map[string]context.CancelFunc),
+		statuses:         make(map[string]string),
+		failures:         make(map[string]Error),
+		breakers:         make(map[string]*breaker),
+		names:            make(map[string]string),
+		restarts:         make(map[string]Restart),
+		failureThreshold: 3,
+		failureWindow:    time.Minute,
+	}
+
+	for i := 0; i < 2; i++ {
+		_, _, ok := d.track(context.Background(), "0123456789ab", "container")
+		require.True(t, ok)
+
+		d.fail("0123456789ab", syscall.ECONNREFUSED)
+		assert.Equal(t, StatusFailed, d.statuses["0123456789ab"])
+	}
+
+	// A success in the middle resets the consecutive failures.
+	d.succeed("0123456789ab")
+
+	for i := 0; i < 3; i++ {
+		_, _, ok := d.track(context.Background(), "0123456789ab", "container")
+		require.True(t, ok)
+
+		d.fail("0123456789ab", syscall.ECONNREFUSED)
+	}
+
+	assert.Equal(t, StatusDisabled, d.statuses["0123456789ab"])
+	assert.Equal(t, ErrCodeAutoDisabled, d.failures["0123456789ab"].Code)
+	assert.Equal(t, "auto-disabled due to repeated failures", d.failures["0123456789ab"].Message)
+
+	// A disabled agent isn't started again.
+	_, _, ok := d.track(context.Background(), "0123456789ab", "container")
+	assert.False(t, ok)
+
+	// Enabling it again resets the breaker.
+	assert.True(t, d.Enable("0123456789abcdef"))
+	assert.False(t, d.Enable("0123456789ab"))
+	assert.NotContains(t, d.statuses, "0123456789ab")
+	assert.NotContains(t, d.breakers, "0123456789ab")
+
+	_, cancel, ok := d.track(context.Background(), "0123456789ab", "container")
+	assert.True(t, ok)
+	cancel()
+}
+
+func TestRestartFailed(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	backend := clock.DefaultBackend
+	t.Cleanup(func() { clock.DefaultBackend = backend })
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	d := &DockerConnector{
+		cancels:          make(map[string]context.CancelFunc),
+		statuses:         make(map[string]string),
+		failures:         make(map[string]Error),
+		breakers:         make(map[string]*breaker),
+		names:            make(map[string]string),
+		restarts:         make(map[string]Restart),
+		failureThreshold: 3,
+		failureWindow:    5 * time.Minute,
+	}
+
+	// The agent of a container keeps failing every time it's started, while the other one stays started.
+	_, cancel, ok := d.track(context.Background(), "ba9876543210", "healthy")
+	require.True(t, ok)
+	defer cancel()
+	d.setStatus("ba9876543210", StatusStarted)
+
+	failing := func(ctx context.Context, id string, name string) bool {
+		assert.Equal(t, "failing", name)
+
+		_, _, ok := d.track(ctx, id, name)
+		if ok {
+			d.fail(id, syscall.ECONNREFUSED)
+		}
+
+		return ok
+	}
+
+	_, _, ok = d.track(context.Background(), "0123456789ab", "failing")
+	require.True(t, ok)
+	d.fail("0123456789ab", syscall.ECONNREFUSED)
+
+	// A restart refused because the limit of agents was reached isn't recorded.
+	d.maxAgents = 1
+	assert.Equal(t, 0, d.restartFailed(context.Background(), failing))
+	assert.Equal(t, StatusFailed, d.statuses["0123456789ab"])
+	assert.NotContains(t, d.restarts, "0123456789ab")
+	d.maxAgents = 0
+
+	// The first failure is restarted twice before reaching the threshold of 3 failures.
+	assert.Equal(t, 1, d.restartFailed(context.Background(), failing))
+	assert.Equal(t, StatusFailed, d.statuses["0123456789ab"])
+	assert.Equal(t, 1, d.restartFailed(context.Background(), failing))
+	assert.Equal(t, StatusDisabled, d.statuses["0123456789ab"])
+
+	// A disabled agent is no longer restarted.
+	assert.Equal(t, 0, d.restartFailed(context.Background(), failing))
+
+	health := d.Health()
+	assert.Equal(t, map[string]Restart{"0123456789ab": {Count: 2, LastAt: now}}, health.Restarts)
+	assert.Equal(t, StatusStarted, health.Containers["ba9876543210"])
+	assert.Equal(t, 1, health.Disabled)
+
+	// Stopping the container forgets its restarts.
+	d.Stop(context.Background(), "0123456789abcdef")
+	assert.Nil(t, d.Health().Restarts)
+}
+
+func TestRestartFailedWhenReady(t *testing.T) {
+	// newConnector creates a connector to the Docker Engine API at url, with the agent of a container failed.
+	newConnector := func(t *testing.T, url string) *DockerConnector {
+		cli, err := dockerclient.NewClientWithOpts(
+			dockerclient.WithHost("tcp://"+strings.TrimPrefix(url, "http://")),
+			dockerclient.WithVersion("1.45"),
+		)
+		require.NoError(t, err)
+
+		d := &DockerConnector{
+			cli:              cli,
+			cancels:          make(map[string]context.CancelFunc),
+			statuses:         make(map[string]string),
+			failures:         make(map[string]Error),
+			breakers:         make(map[string]*breaker),
+			names:            make(map[string]string),
+			restarts:         make(map[string]Restart),
+			failureThreshold: 3,
+			failureWindow:    time.Minute,
+			apiTimeout:       time.Second,
+		}
+
+		_, _, ok := d.track(context.Background(), "0123456789ab", "container")
+		require.True(t, ok)
+		d.fail("0123456789ab", syscall.ECONNREFUSED)
+
+		return d
+	}
+
+	t.Run("keeps the agents failed when the Docker Engine API is not reachable", func(t *testing.T) {
+		engine := httptest.NewServer(http.NotFoundHandler())
+		engine.Close()
+
+		d := newConnector(t, engine.URL)
+
+		restarted, err := d.restartFailedWhenReady(context.Background(), func(context.Context, string, string) bool {
+			assert.Fail(t, "the agent must not be restarted")
+
+			return false
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 0, restarted)
+		assert.Equal(t, StatusFailed, d.statuses["0123456789ab"])
+		assert.NotContains(t, d.restarts, "0123456789ab")
+	})
+
+	t.Run("restarts the failed agents when the Docker Engine API is reachable", func(t *testing.T) {
+		engine := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Api-Version", "1.45")
+			_, _ = w.Write([]byte("OK"))
+		}))
+		t.Cleanup(engine.Close)
+
+		d := newConnector(t, engine.URL)
+
+		var started []string
+		restarted, err := d.restartFailedWhenReady(context.Background(), func(_ context.Context, id string, _ string) bool {
+			started = append(started, id)
+
+			return true
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, restarted)
+		assert.Equal(t, []string{"0123456789ab"}, started)
+	})
+}
@@ -0,0 +1,66 @@
+package connector
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"syscall"
+
+	dockerclient "github.com/docker/docker/client"
+)
+
+// Codes of the errors reported by the connector.
+const (
+	ErrCodeTLSHandshake      = "tls_handshake_failed"
+	ErrCodeConnectionRefused = "connection_refused"
+	ErrCodeDockerUnavailable = "docker_unavailable"
+	ErrCodeAgentFailed       = "agent_failed"
+	ErrCodeAutoDisabled      = "auto_disabled"
+	ErrCodeMethodNotAllowed  = "method_not_allowed"
+	ErrCodeNotFound          = "not_found"
+	ErrCodeNotAcceptable     = "not_acceptable"
+	ErrCodeUnauthorized      = "unauthorized"
+	ErrCodeForbidden         = "forbidden"
+	ErrCodeStoreUnavailable  = "store_unavailable"
+)
+
+// Error is the envelope of the errors reported by the connector, letting the clients show what went wrong instead of
+// a generic failure.
+type Error struct {
+	// Code identifies the kind of the error.
+	Code string `json:"code"`
+	// Message is a human-readable description of the error.
+	Message string `json:"message"`
+	// Details is the underlying error, when there is one.
+	Details string `json:"details,omitempty"`
+}
+
+// newError classifies err into an [Error].
+func newError(err error) Error {
+	var (
+		recordErr  tls.RecordHeaderError
+		unknownErr x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		certErr    x509.CertificateInvalidError
+	)
+
+	switch {
+	case errors.As(err, &recordErr), errors.As(err, &unknownErr), errors.As(err, &hostErr), errors.As(err, &certErr):
+		return Error{Code: ErrCodeTLSHandshake, Message: "TLS handshake failed", Details: err.Error()}
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return Error{Code: ErrCodeConnectionRefused, Message: "connection refused", Details: err.Error()}
+	case dockerclient.IsErrConnectionFailed(err):
+		return Error{Code: ErrCodeDockerUnavailable, Message: "failed to connect to the Docker Engine", Details: err.Error()}
+	default:
+		return Error{Code: ErrCodeAgentFailed, Message: "the agent failed", Details: err.Error()}
+	}
+}
+
+// writeError writes the error envelope as JSON with the specified status.
+func writeError(w http.ResponseWriter, status int, e Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(e) //nolint:errcheck
+}
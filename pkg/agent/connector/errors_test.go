@@ -0,0 +1,65 @@
+package connector
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewError(t *testing.T) {
+	cases := []struct {
+		description string
+		err         error
+		expected    Error
+	}{
+		{
+			description: "classifies an unknown certificate authority as a TLS handshake failure",
+			err:         fmt.Errorf("failed to connect: %w", x509.UnknownAuthorityError{}),
+			expected: Error{
+				Code:    ErrCodeTLSHandshake,
+				Message: "TLS handshake failed",
+				Details: "failed to connect: x509: certificate signed by unknown authority",
+			},
+		},
+		{
+			description: "classifies a non-TLS server as a TLS handshake failure",
+			err:         tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"},
+			expected: Error{
+				Code:    ErrCodeTLSHandshake,
+				Message: "TLS handshake failed",
+				Details: "tls: first record does not look like a TLS handshake",
+			},
+		},
+		{
+			description: "classifies a refused connection",
+			err: &net.OpError{
+				Op:  "dial",
+				Net: "tcp",
+				Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED},
+			},
+			expected: Error{
+				Code:    ErrCodeConnectionRefused,
+				Message: "connection refused",
+				Details: "dial tcp: connect: connection refused",
+			},
+		},
+		{
+			description: "classifies any other error as an agent failure",
+			err:         errors.New("error"),
+			expected:    Error{Code: ErrCodeAgentFailed, Message: "the agent failed", Details: "error"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, newError(tc.err))
+		})
+	}
+}
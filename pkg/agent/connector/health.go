@@ -0,0 +1,388 @@
+package connector
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/version"
+)
+
+// Status of the agents started by the connector.
+const (
+	// StatusConnected is the status of an agent that was started, but is still connecting to the server.
+	StatusConnected = "connected"
+	// StatusStarted is the status of an agent that is listening for connections.
+	StatusStarted = "started"
+	// StatusFailed is the status of an agent that failed to connect or to listen for connections. It's started again
+	// on the next reconciliation.
+	StatusFailed = "failed"
+	// StatusDisabled is the status of an agent that was auto-disabled due to repeated failures. It isn't started again
+	// until enabled.
+	StatusDisabled = "disabled"
+)
+
+// VersionPath is the path the connector's build information is served on.
+const VersionPath = "/version"
+
+// SelfTestPath is the path, followed by the tenant ID of the connector, the connector's self-test is run on.
+const SelfTestPath = "/selftest/"
+
+// StatusPath is the path the status of the agents started by every replica of the connector is served on.
+const StatusPath = "/status/all"
+
+// Versioning of the connector's HTTP API.
+const (
+	// APIVersion is the current version of the connector's HTTP API, prefixing its routes.
+	APIVersion = "v1"
+	// APIMediaType is the media type accepted by the clients of the current version of the API, routing the
+	// unversioned routes to it without deprecating them.
+	APIMediaType = "application/vnd.shellhub." + APIVersion + "+json"
+	// DeprecationHeader is the header set on the responses of the unversioned routes, kept as aliases of the current
+	// version of the API.
+	DeprecationHeader = "X-Deprecation-Notice"
+)
+
+// apiMediaTypePrefix is the prefix of the media types of every version of the connector's HTTP API.
+const apiMediaTypePrefix = "application/vnd.shellhub."
+
+// VersionResponse is the connector's build information with the version of its HTTP API.
+type VersionResponse struct {
+	version.Info
+	APIVersion       string `json:"api_version"`
+	ConnectorVersion string `json:"connector_version"`
+}
+
+// HealthUnhealthyScore is the health score below which the connector is considered unhealthy.
+const HealthUnhealthyScore = 0.5
+
+// Health is the aggregated health of the agents started by the connector.
+type Health struct {
+	Total     int `json:"total"`
+	Connected int `json:"connected"`
+	Started   int `json:"started"`
+	Failed    int `json:"failed"`
+	Disabled  int `json:"disabled"`
+	// Containers maps the short ID of each container to the status of its agent.
+	Containers map[string]string `json:"containers"`
+	// Errors maps the short ID of each container whose agent failed to the error it failed with.
+	Errors map[string]Error `json:"errors,omitempty"`
+	// Restarts maps the short ID of each container whose agent was restarted after failing to its restarts.
+	Restarts map[string]Restart `json:"restarts,omitempty"`
+	// HealthScore is the fraction, from 0.0 to 1.0, of the agents that are listening for connections. When there are
+	// no agents, it's 1.0.
+	HealthScore float64 `json:"health_score"`
+}
+
+// Restart is how many times, and when last, the agent of a container was restarted after failing.
+type Restart struct {
+	Count  int       `json:"count"`
+	LastAt time.Time `json:"last_at"`
+}
+
+// Healthy reports whether the health score is not below [HealthUnhealthyScore].
+func (h *Health) Healthy() bool {
+	return h.HealthScore >= HealthUnhealthyScore
+}
+
+// newHealth aggregates the status of each container's agent into a [Health], with the errors of the failed and
+// disabled ones.
+func newHealth(statuses map[string]string, failures map[string]Error) Health {
+	health := Health{Total: len(statuses), Containers: make(map[string]string, len(statuses)), HealthScore: 1}
+	for id, status := range statuses {
+		health.Containers[id] = status
+
+		switch status {
+		case StatusConnected:
+			health.Connected++
+		case StatusStarted:
+			health.Started++
+		case StatusFailed:
+			health.Failed++
+		case StatusDisabled:
+			health.Disabled++
+		}
+
+		if failure, ok := failures[id]; ok && (status == StatusFailed || status == StatusDisabled) {
+			if health.Errors == nil {
+				health.Errors = make(map[string]Error)
+			}
+
+			health.Errors[id] = failure
+		}
+	}
+
+	if health.Total > 0 {
+		health.HealthScore = float64(health.Started) / float64(health.Total)
+	}
+
+	return health
+}
+
+// NewHealthHandler creates a [http.Handler] serving the connector's health, as JSON, on healthPath and its metrics, in
+// the Prometheus text format, on metricsPath, including the sync lag of the devices and the timed out execs. The health
+// responds with [http.StatusServiceUnavailable] when the connector is unhealthy. A POST to healthPath/enable, with the
+// container's ID in the id query parameter, enables again an agent auto-disabled due to repeated failures. The
+// connector's build information is served on [VersionPath]. A GET to [SelfTestPath], followed by the connector's tenant
+// ID, runs its self-test, responding with [http.StatusServiceUnavailable] when a step fails. The status of the agents
+// started by every replica of the connector is served on [StatusPath].
+//
+// The routes are served under the [APIVersion] prefix, like /v1/health. The unversioned ones are kept as aliases of the
+// current version, deprecated through [DeprecationHeader] unless the client accepts [APIMediaType].
+//
+// Every route requires token as a bearer token on the Authorization header, responding with
+// [http.StatusUnauthorized] otherwise. When token is empty, the routes are served without authentication, but agents
+// can only be enabled from the loopback interface.
+func NewHealthHandler(connector Connector, healthPath, metricsPath, token string) http.Handler {
+	mux := http.NewServeMux()
+
+	prefix := "/" + APIVersion
+	healthPath, metricsPath = prefix+healthPath, prefix+metricsPath
+
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, Error{Code: ErrCodeMethodNotAllowed, Message: "method not allowed"})
+
+			return
+		}
+
+		health := connector.Health()
+
+		status := http.StatusOK
+		if !health.Healthy() {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(health) //nolint:errcheck
+	})
+
+	mux.HandleFunc(strings.TrimSuffix(healthPath, "/")+"/enable", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, Error{Code: ErrCodeMethodNotAllowed, Message: "method not allowed"})
+
+			return
+		}
+
+		if token == "" && !fromLoopback(r) {
+			writeError(w, http.StatusForbidden, Error{Code: ErrCodeForbidden, Message: "agents can only be enabled from the loopback interface"})
+
+			return
+		}
+
+		if !connector.Enable(r.URL.Query().Get("id")) {
+			writeError(w, http.StatusNotFound, Error{Code: ErrCodeNotFound, Message: "no disabled agent for the container"})
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc(prefix+VersionPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, Error{Code: ErrCodeMethodNotAllowed, Message: "method not allowed"})
+
+			return
+		}
+
+		connectorVersion := ConnectorVersion
+		if connectorVersion == "" {
+			connectorVersion = version.Unknown
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(VersionResponse{ //nolint:errcheck
+			Info:             version.Get(),
+			APIVersion:       APIVersion,
+			ConnectorVersion: connectorVersion,
+		})
+	})
+
+	mux.HandleFunc(prefix+SelfTestPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, Error{Code: ErrCodeMethodNotAllowed, Message: "method not allowed"})
+
+			return
+		}
+
+		if tenant := strings.TrimPrefix(r.URL.Path, prefix+SelfTestPath); tenant != connector.Tenant() {
+			writeError(w, http.StatusNotFound, Error{Code: ErrCodeNotFound, Message: "no connector for the tenant"})
+
+			return
+		}
+
+		report := connector.SelfTest(r.Context())
+
+		status := http.StatusOK
+		if !report.Passed {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report) //nolint:errcheck
+	})
+
+	mux.HandleFunc(prefix+StatusPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, Error{Code: ErrCodeMethodNotAllowed, Message: "method not allowed"})
+
+			return
+		}
+
+		statuses, err := connector.Statuses(r.Context())
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, Error{Code: ErrCodeStoreUnavailable, Message: "failed to get the status of the agents", Details: err.Error()})
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(statuses) //nolint:errcheck
+	})
+
+	mux.HandleFunc(metricsPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, Error{Code: ErrCodeMethodNotAllowed, Message: "method not allowed"})
+
+			return
+		}
+
+		health := connector.Health()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprintln(w, "# HELP connector_connections_total Number of agents started by the connector by status.")
+		fmt.Fprintln(w, "# TYPE connector_connections_total gauge")
+		fmt.Fprintf(w, "connector_connections_total{status=%q} %d\n", StatusConnected, health.Connected)
+		fmt.Fprintf(w, "connector_connections_total{status=%q} %d\n", StatusStarted, health.Started)
+		fmt.Fprintf(w, "connector_connections_total{status=%q} %d\n", StatusFailed, health.Failed)
+		fmt.Fprintf(w, "connector_connections_total{status=%q} %d\n", StatusDisabled, health.Disabled)
+		fmt.Fprintln(w, "# HELP connector_health_score Fraction of the agents started by the connector that are listening for connections.")
+		fmt.Fprintln(w, "# TYPE connector_health_score gauge")
+		fmt.Fprintf(w, "connector_health_score %g\n", health.HealthScore)
+
+		lags := connector.SyncLag()
+		fmt.Fprintln(w, "# HELP connector_sync_lag_seconds Time between a Docker event and the corresponding change of the device on ShellHub.")
+		fmt.Fprintln(w, "# TYPE connector_sync_lag_seconds histogram")
+		for _, action := range []string{SyncActionStart, SyncActionStop} {
+			histogram := lags[action]
+			histogram.write(w, "connector_sync_lag_seconds", "action", action)
+		}
+
+		fmt.Fprintln(w, "# HELP connector_exec_timeouts_total Number of sessions whose exec wasn't started in the container in time.")
+		fmt.Fprintln(w, "# TYPE connector_exec_timeouts_total counter")
+		fmt.Fprintf(w, "connector_exec_timeouts_total %d\n", connector.ExecTimeoutCount())
+	})
+
+	return newAuthenticatedHandler(newVersionedHandler(mux, prefix), token)
+}
+
+// newAuthenticatedHandler creates a [http.Handler] serving handler only to the requests carrying token as a bearer
+// token, responding with [http.StatusUnauthorized] to the others. Only the token's SHA-256 hash is kept. When token
+// is empty, handler is returned as is.
+func newAuthenticatedHandler(handler http.Handler, token string) http.Handler {
+	if token == "" {
+		return handler
+	}
+
+	hash := sha256.Sum256([]byte(token))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hasBearerToken(r, hash) {
+			writeError(w, http.StatusUnauthorized, Error{Code: ErrCodeUnauthorized, Message: "invalid or missing token"})
+
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// fromLoopback reports whether the request came from the loopback interface.
+func fromLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+// hasBearerToken reports whether the request carries, as a bearer token on its Authorization header, the token whose
+// SHA-256 hash is hash.
+func hasBearerToken(r *http.Request, hash [sha256.Size]byte) bool {
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(bearer))
+
+	return subtle.ConstantTimeCompare(sum[:], hash[:]) == 1
+}
+
+// newVersionedHandler creates a [http.Handler] serving the routes of mux, all of them under prefix, also on their
+// unversioned paths. The responses of the unversioned routes, except the version's one, carry [DeprecationHeader]
+// unless the client accepts [APIMediaType]. Clients accepting only other versions of the API are refused with
+// [http.StatusNotAcceptable].
+func newVersionedHandler(mux *http.ServeMux, prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/") {
+			mux.ServeHTTP(w, r)
+
+			return
+		}
+
+		negotiated, ok := negotiateAPIVersion(r.Header.Values("Accept"))
+		if !ok {
+			writeError(w, http.StatusNotAcceptable, Error{
+				Code:    ErrCodeNotAcceptable,
+				Message: fmt.Sprintf("only the version %s of the API is supported", APIVersion),
+			})
+
+			return
+		}
+
+		if !negotiated && r.URL.Path != VersionPath {
+			w.Header().Set(DeprecationHeader, fmt.Sprintf("unversioned routes are deprecated, use %s%s instead", prefix, r.URL.Path))
+		}
+
+		aliased := r.Clone(r.Context())
+		aliased.URL.Path = prefix + r.URL.Path
+		aliased.URL.RawPath = ""
+
+		mux.ServeHTTP(w, aliased)
+	})
+}
+
+// negotiateAPIVersion reports whether the media types accepted by the client include [APIMediaType] and whether the
+// current version of the API is acceptable, what it isn't when the client only accepts other versions of it.
+func negotiateAPIVersion(accept []string) (negotiated bool, ok bool) {
+	versioned := false
+	for _, value := range accept {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, _, err := mime.ParseMediaType(part)
+			if err != nil || !strings.HasPrefix(mediaType, apiMediaTypePrefix) {
+				continue
+			}
+
+			if mediaType == APIMediaType {
+				return true, true
+			}
+
+			versioned = true
+		}
+	}
+
+	return false, !versioned
+}
@@ -0,0 +1,475 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHealth(t *testing.T) {
+	cases := []struct {
+		description string
+		statuses    map[string]string
+		failures    map[string]Error
+		expected    Health
+	}{
+		{
+			description: "succeeds with a full score when there are no agents",
+			statuses:    map[string]string{},
+			expected:    Health{Containers: map[string]string{}, HealthScore: 1},
+		},
+		{
+			description: "succeeds with a full score when every agent is started",
+			statuses:    map[string]string{"0123456789ab": StatusStarted, "ba9876543210": StatusStarted},
+			expected: Health{
+				Total:       2,
+				Started:     2,
+				Containers:  map[string]string{"0123456789ab": StatusStarted, "ba9876543210": StatusStarted},
+				HealthScore: 1,
+			},
+		},
+		{
+			description: "succeeds with the fraction of started agents",
+			statuses: map[string]string{
+				"0123456789ab": StatusStarted,
+				"ba9876543210": StatusConnected,
+				"aaaaaaaaaaaa": StatusFailed,
+				"bbbbbbbbbbbb": StatusFailed,
+			},
+			failures: map[string]Error{
+				"aaaaaaaaaaaa": {Code: ErrCodeConnectionRefused, Message: "connection refused"},
+			},
+			expected: Health{
+				Total:     4,
+				Connected: 1,
+				Started:   1,
+				Failed:    2,
+				Containers: map[string]string{
+					"0123456789ab": StatusStarted,
+					"ba9876543210": StatusConnected,
+					"aaaaaaaaaaaa": StatusFailed,
+					"bbbbbbbbbbbb": StatusFailed,
+				},
+				Errors: map[string]Error{
+					"aaaaaaaaaaaa": {Code: ErrCodeConnectionRefused, Message: "connection refused"},
+				},
+				HealthScore: 0.25,
+			},
+		},
+		{
+			description: "succeeds counting the disabled agents with their errors",
+			statuses:    map[string]string{"0123456789ab": StatusStarted, "ba9876543210": StatusDisabled},
+			failures: map[string]Error{
+				"ba9876543210": {Code: ErrCodeAutoDisabled, Message: "auto-disabled due to repeated failures"},
+			},
+			expected: Health{
+				Total:      2,
+				Started:    1,
+				Disabled:   1,
+				Containers: map[string]string{"0123456789ab": StatusStarted, "ba9876543210": StatusDisabled},
+				Errors: map[string]Error{
+					"ba9876543210": {Code: ErrCodeAutoDisabled, Message: "auto-disabled due to repeated failures"},
+				},
+				HealthScore: 0.5,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, newHealth(tc.statuses, tc.failures))
+		})
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	cases := []struct {
+		description   string
+		statuses      map[string]string
+		path          string
+		method        string
+		token         string
+		remoteAddr    string
+		authorization string
+		status        int
+	}{
+		{
+			description: "responds OK when there are no agents",
+			statuses:    map[string]string{},
+			path:        "/health",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+		},
+		{
+			description: "responds OK when half of the agents are started",
+			statuses:    map[string]string{"0123456789ab": StatusStarted, "ba9876543210": StatusFailed},
+			path:        "/health",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+		},
+		{
+			description: "responds service unavailable when less than half of the agents are started",
+			statuses: map[string]string{
+				"0123456789ab": StatusStarted,
+				"ba9876543210": StatusFailed,
+				"aaaaaaaaaaaa": StatusConnected,
+			},
+			path:   "/health",
+			method: http.MethodGet,
+			status: http.StatusServiceUnavailable,
+		},
+		{
+			description: "responds method not allowed when the method is not GET",
+			statuses:    map[string]string{},
+			path:        "/health",
+			method:      http.MethodPost,
+			status:      http.StatusMethodNotAllowed,
+		},
+		{
+			description: "responds no content when enabling a disabled agent from the loopback interface",
+			statuses:    map[string]string{"0123456789ab": StatusDisabled},
+			path:        "/health/enable?id=0123456789ab",
+			method:      http.MethodPost,
+			remoteAddr:  "127.0.0.1:41234",
+			status:      http.StatusNoContent,
+		},
+		{
+			description: "responds not found when enabling an agent that isn't disabled",
+			statuses:    map[string]string{"0123456789ab": StatusFailed},
+			path:        "/health/enable?id=0123456789ab",
+			method:      http.MethodPost,
+			remoteAddr:  "127.0.0.1:41234",
+			status:      http.StatusNotFound,
+		},
+		{
+			description: "responds forbidden when enabling an agent from another interface without a token",
+			statuses:    map[string]string{"0123456789ab": StatusDisabled},
+			path:        "/health/enable?id=0123456789ab",
+			method:      http.MethodPost,
+			remoteAddr:  "192.0.2.1:41234",
+			status:      http.StatusForbidden,
+		},
+		{
+			description:   "responds no content when enabling a disabled agent with the token",
+			statuses:      map[string]string{"0123456789ab": StatusDisabled},
+			path:          "/health/enable?id=0123456789ab",
+			method:        http.MethodPost,
+			token:         "token",
+			remoteAddr:    "192.0.2.1:41234",
+			authorization: "Bearer token",
+			status:        http.StatusNoContent,
+		},
+		{
+			description:   "responds unauthorized when enabling an agent with another token",
+			statuses:      map[string]string{"0123456789ab": StatusDisabled},
+			path:          "/health/enable?id=0123456789ab",
+			method:        http.MethodPost,
+			token:         "token",
+			remoteAddr:    "192.0.2.1:41234",
+			authorization: "Bearer other",
+			status:        http.StatusUnauthorized,
+		},
+		{
+			description: "responds unauthorized to the health without the token",
+			statuses:    map[string]string{},
+			path:        "/v1/health",
+			method:      http.MethodGet,
+			token:       "token",
+			status:      http.StatusUnauthorized,
+		},
+		{
+			description:   "responds unauthorized to the health with another token",
+			statuses:      map[string]string{},
+			path:          "/health",
+			method:        http.MethodGet,
+			token:         "token",
+			authorization: "Bearer other",
+			status:        http.StatusUnauthorized,
+		},
+		{
+			description:   "responds OK to the health with the token",
+			statuses:      map[string]string{},
+			path:          "/v1/health",
+			method:        http.MethodGet,
+			token:         "token",
+			authorization: "Bearer token",
+			status:        http.StatusOK,
+		},
+		{
+			description: "responds unauthorized to the metrics without the token",
+			statuses:    map[string]string{},
+			path:        "/v1/metrics",
+			method:      http.MethodGet,
+			token:       "token",
+			status:      http.StatusUnauthorized,
+		},
+		{
+			description: "responds unauthorized to the version without the token",
+			statuses:    map[string]string{},
+			path:        "/version",
+			method:      http.MethodGet,
+			token:       "token",
+			status:      http.StatusUnauthorized,
+		},
+		{
+			description: "responds unauthorized to the self-test without the token",
+			statuses:    map[string]string{},
+			path:        "/v1/selftest/00000000-0000-4000-0000-000000000000",
+			method:      http.MethodGet,
+			token:       "token",
+			status:      http.StatusUnauthorized,
+		},
+		{
+			description: "responds unauthorized when enabling an agent without the token, even from the loopback interface",
+			statuses:    map[string]string{"0123456789ab": StatusDisabled},
+			path:        "/health/enable?id=0123456789ab",
+			method:      http.MethodPost,
+			token:       "token",
+			remoteAddr:  "127.0.0.1:41234",
+			status:      http.StatusUnauthorized,
+		},
+		{
+			description: "responds method not allowed when enabling an agent without POST",
+			statuses:    map[string]string{"0123456789ab": StatusDisabled},
+			path:        "/health/enable?id=0123456789ab",
+			method:      http.MethodGet,
+			status:      http.StatusMethodNotAllowed,
+		},
+		{
+			description: "responds OK to the metrics even when unhealthy",
+			statuses:    map[string]string{"ba9876543210": StatusFailed},
+			path:        "/metrics",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			d := &DockerConnector{cancels: make(map[string]context.CancelFunc), statuses: tc.statuses, failures: map[string]Error{}}
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.remoteAddr != "" {
+				req.RemoteAddr = tc.remoteAddr
+			}
+
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			NewHealthHandler(d, "/health", "/metrics", tc.token).ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestHealthHandlerBody(t *testing.T) {
+	d := &DockerConnector{
+		cancels:  make(map[string]context.CancelFunc),
+		statuses: map[string]string{"0123456789ab": StatusStarted, "ba9876543210": StatusFailed},
+		failures: map[string]Error{"ba9876543210": {Code: ErrCodeTLSHandshake, Message: "TLS handshake failed"}},
+	}
+
+	handler := NewHealthHandler(d, "/custom/health", "/custom/metrics", "")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/custom/health", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	health := new(Health)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(health))
+	assert.Equal(t, 0.5, health.HealthScore)
+	assert.Equal(t, StatusFailed, health.Containers["ba9876543210"])
+	assert.Equal(t, ErrCodeTLSHandshake, health.Errors["ba9876543210"].Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/custom/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	metrics := rec.Body.String()
+	assert.True(t, strings.Contains(metrics, `connector_connections_total{status="started"} 1`))
+	assert.True(t, strings.Contains(metrics, `connector_connections_total{status="failed"} 1`))
+	assert.True(t, strings.Contains(metrics, `connector_connections_total{status="disabled"} 0`))
+	assert.True(t, strings.Contains(metrics, "# TYPE connector_sync_lag_seconds histogram"))
+	assert.True(t, strings.Contains(metrics, `connector_sync_lag_seconds_bucket{action="start",le="+Inf"} 0`))
+	assert.True(t, strings.Contains(metrics, `connector_sync_lag_seconds_count{action="stop"} 0`))
+	assert.True(t, strings.Contains(metrics, "# TYPE connector_exec_timeouts_total counter"))
+	assert.True(t, strings.Contains(metrics, "connector_exec_timeouts_total 0"))
+}
+
+func TestHealthHandlerStatus(t *testing.T) {
+	store := newMemoryStore()
+	require.NoError(t, store.SaveConnectionStatus(context.Background(), "00000000-0000-4000-0000-000000000000", "0123456789ab", StatusStarted))
+
+	d := &DockerConnector{tenant: "00000000-0000-4000-0000-000000000000", statusWriter: &statusWriter{store: store}}
+
+	rec := httptest.NewRecorder()
+	NewHealthHandler(d, "/health", "/metrics", "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1"+StatusPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var statuses []models.ConnectorStatus
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&statuses))
+	assert.Equal(t, []models.ConnectorStatus{
+		{TenantID: "00000000-0000-4000-0000-000000000000", Address: "0123456789ab", Status: StatusStarted},
+	}, statuses)
+
+	store.err = errors.New("error")
+
+	rec = httptest.NewRecorder()
+	NewHealthHandler(d, "/health", "/metrics", "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1"+StatusPath, nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	e := new(Error)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(e))
+	assert.Equal(t, Error{Code: ErrCodeStoreUnavailable, Message: "failed to get the status of the agents", Details: "error"}, *e)
+}
+
+func TestHealthHandlerVersion(t *testing.T) {
+	d := &DockerConnector{cancels: make(map[string]context.CancelFunc), statuses: map[string]string{}}
+
+	version.Version, version.GitCommit, version.BuildDate = "v0.16.0", "385e64d", "2024-06-01T12:00:00Z"
+	t.Cleanup(func() {
+		version.Version, version.GitCommit, version.BuildDate = "", "", ""
+	})
+
+	rec := httptest.NewRecorder()
+	NewHealthHandler(d, "/health", "/metrics", "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, VersionPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	info := new(version.Info)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(info))
+	assert.Equal(t, version.Info{Version: "v0.16.0", GitCommit: "385e64d", BuildDate: "2024-06-01T12:00:00Z"}, *info)
+}
+
+func TestHealthHandlerError(t *testing.T) {
+	d := &DockerConnector{cancels: make(map[string]context.CancelFunc), statuses: map[string]string{}}
+
+	rec := httptest.NewRecorder()
+	NewHealthHandler(d, "/health", "/metrics", "").ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/metrics", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	e := new(Error)
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(e))
+	assert.Equal(t, Error{Code: ErrCodeMethodNotAllowed, Message: "method not allowed"}, *e)
+}
+
+func TestHealthHandlerVersioning(t *testing.T) {
+	type Expected struct {
+		status     int
+		deprecated bool
+	}
+
+	cases := []struct {
+		description string
+		path        string
+		method      string
+		accept      string
+		expected    Expected
+	}{
+		{
+			description: "serves the versioned health",
+			path:        "/v1/health",
+			method:      http.MethodGet,
+			expected:    Expected{status: http.StatusOK, deprecated: false},
+		},
+		{
+			description: "serves the unversioned health as deprecated",
+			path:        "/health",
+			method:      http.MethodGet,
+			expected:    Expected{status: http.StatusOK, deprecated: true},
+		},
+		{
+			description: "serves the unversioned health when the client accepts the current version",
+			path:        "/health",
+			method:      http.MethodGet,
+			accept:      "application/json, application/vnd.shellhub.v1+json",
+			expected:    Expected{status: http.StatusOK, deprecated: false},
+		},
+		{
+			description: "fails when the client only accepts another version",
+			path:        "/health",
+			method:      http.MethodGet,
+			accept:      "application/vnd.shellhub.v2+json",
+			expected:    Expected{status: http.StatusNotAcceptable, deprecated: false},
+		},
+		{
+			description: "serves the versioned metrics",
+			path:        "/v1/metrics",
+			method:      http.MethodGet,
+			expected:    Expected{status: http.StatusOK, deprecated: false},
+		},
+		{
+			description: "serves the versioned enable",
+			path:        "/v1/health/enable?id=0123456789ab",
+			method:      http.MethodPost,
+			expected:    Expected{status: http.StatusNotFound, deprecated: false},
+		},
+		{
+			description: "serves the unversioned enable as deprecated",
+			path:        "/health/enable?id=0123456789ab",
+			method:      http.MethodPost,
+			expected:    Expected{status: http.StatusNotFound, deprecated: true},
+		},
+		{
+			description: "serves the unversioned version without deprecating it",
+			path:        "/version",
+			method:      http.MethodGet,
+			expected:    Expected{status: http.StatusOK, deprecated: false},
+		},
+		{
+			description: "doesn't serve the routes of other versions",
+			path:        "/v2/health",
+			method:      http.MethodGet,
+			expected:    Expected{status: http.StatusNotFound, deprecated: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			d := &DockerConnector{cancels: make(map[string]context.CancelFunc), statuses: map[string]string{}, failures: map[string]Error{}}
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.RemoteAddr = "127.0.0.1:41234"
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			NewHealthHandler(d, "/health", "/metrics", "").ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Code)
+			assert.Equal(t, tc.expected.deprecated, rec.Header().Get(DeprecationHeader) != "")
+		})
+	}
+}
+
+func TestHealthHandlerAPIVersion(t *testing.T) {
+	d := &DockerConnector{cancels: make(map[string]context.CancelFunc), statuses: map[string]string{}}
+
+	ConnectorVersion = "v0.16.0"
+	t.Cleanup(func() {
+		ConnectorVersion = ""
+	})
+
+	for _, path := range []string{VersionPath, "/v1" + VersionPath} {
+		rec := httptest.NewRecorder()
+		NewHealthHandler(d, "/health", "/metrics", "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		body := new(VersionResponse)
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(body))
+		assert.Equal(t, APIVersion, body.APIVersion)
+		assert.Equal(t, "v0.16.0", body.ConnectorVersion)
+	}
+}
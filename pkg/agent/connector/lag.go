@@ -0,0 +1,125 @@
+package connector
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+)
+
+// Actions the sync lag is measured for, from the Docker event to the corresponding change of the device on ShellHub.
+const (
+	// SyncActionStart is a container started becoming a device connected to ShellHub, measured until its agent is
+	// listening for connections.
+	SyncActionStart = "start"
+	// SyncActionStop is a container stopped becoming a device disconnected from ShellHub, measured until its agent is
+	// closed.
+	SyncActionStop = "stop"
+)
+
+// SyncLagBuckets are the upper bounds, in seconds, of the buckets of the sync lag histogram.
+var SyncLagBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Histogram is the distribution of the observed values, in the Prometheus histogram model.
+type Histogram struct {
+	// Buckets are the upper bounds of the buckets, sorted.
+	Buckets []float64 `json:"buckets"`
+	// Counts are the number of observations less than or equal to the upper bound of each bucket, so they're
+	// cumulative.
+	Counts []uint64 `json:"counts"`
+	Sum    float64  `json:"sum"`
+	Count  uint64   `json:"count"`
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{Buckets: buckets, Counts: make([]uint64, len(buckets))}
+}
+
+func (h *Histogram) observe(value float64) {
+	for i, bucket := range h.Buckets {
+		if value <= bucket {
+			h.Counts[i]++
+		}
+	}
+
+	h.Sum += value
+	h.Count++
+}
+
+func (h *Histogram) clone() Histogram {
+	return Histogram{Buckets: h.Buckets, Counts: append([]uint64(nil), h.Counts...), Sum: h.Sum, Count: h.Count}
+}
+
+// write writes the histogram named name, in the Prometheus text format, with the label set to value.
+func (h *Histogram) write(w io.Writer, name, label, value string) {
+	for i, bucket := range h.Buckets {
+		fmt.Fprintf(w, "%s_bucket{%s=%q,le=%q} %d\n", name, label, value, strconv.FormatFloat(bucket, 'g', -1, 64), h.Counts[i])
+	}
+
+	fmt.Fprintf(w, "%s_bucket{%s=%q,le=\"+Inf\"} %d\n", name, label, value, h.Count)
+	fmt.Fprintf(w, "%s_sum{%s=%q} %g\n", name, label, value, h.Sum)
+	fmt.Fprintf(w, "%s_count{%s=%q} %d\n", name, label, value, h.Count)
+}
+
+// eventTime returns the time of the Docker event, or the zero time when the event doesn't have one.
+func eventTime(event events.Message) time.Time {
+	switch {
+	case event.TimeNano > 0:
+		return time.Unix(0, event.TimeNano)
+	case event.Time > 0:
+		return time.Unix(event.Time, 0)
+	default:
+		return time.Time{}
+	}
+}
+
+// observeSyncLag records the time since the Docker event at eventAt that caused the action. It does nothing when the
+// action wasn't caused by an event, like the ones of the reconciliations.
+func (d *DockerConnector) observeSyncLag(action string, eventAt time.Time) {
+	if eventAt.IsZero() {
+		return
+	}
+
+	// NOTICE: the time of the event is set by the Docker Engine, which may be on another host, so a skewed clock is
+	// reported as no lag.
+	lag := clock.Now().Sub(eventAt)
+	if lag < 0 {
+		lag = 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.syncLag == nil {
+		d.syncLag = make(map[string]*Histogram)
+	}
+
+	histogram, ok := d.syncLag[action]
+	if !ok {
+		histogram = newHistogram(SyncLagBuckets)
+		d.syncLag[action] = histogram
+	}
+
+	histogram.observe(lag.Seconds())
+}
+
+// SyncLag returns the distribution of the time, in seconds, between the Docker events and the corresponding changes
+// of the devices on ShellHub, for each of [SyncActionStart] and [SyncActionStop].
+func (d *DockerConnector) SyncLag() map[string]Histogram {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lags := make(map[string]Histogram, 2)
+	for _, action := range []string{SyncActionStart, SyncActionStop} {
+		if histogram, ok := d.syncLag[action]; ok {
+			lags[action] = histogram.clone()
+		} else {
+			lags[action] = newHistogram(SyncLagBuckets).clone()
+		}
+	}
+
+	return lags
+}
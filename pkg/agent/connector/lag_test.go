@@ -0,0 +1,105 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmock "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]float64{0.5, 1, 5})
+	for _, value := range []float64{0.1, 0.5, 0.7, 3, 10} {
+		h.observe(value)
+	}
+
+	assert.Equal(t, Histogram{Buckets: []float64{0.5, 1, 5}, Counts: []uint64{2, 3, 4}, Sum: 14.3, Count: 5}, h.clone())
+
+	var buf bytes.Buffer
+	h.write(&buf, "lag_seconds", "action", "start")
+	assert.Equal(t, `lag_seconds_bucket{action="start",le="0.5"} 2
+lag_seconds_bucket{action="start",le="1"} 3
+lag_seconds_bucket{action="start",le="5"} 4
+lag_seconds_bucket{action="start",le="+Inf"} 5
+lag_seconds_sum{action="start"} 14.3
+lag_seconds_count{action="start"} 5
+`, buf.String())
+}
+
+func TestEventTime(t *testing.T) {
+	cases := []struct {
+		description string
+		event       events.Message
+		expected    time.Time
+	}{
+		{
+			description: "uses the time in nanoseconds",
+			event:       events.Message{Time: 1717243200, TimeNano: 1717243200500000000},
+			expected:    time.Unix(0, 1717243200500000000),
+		},
+		{
+			description: "falls back to the time in seconds",
+			event:       events.Message{Time: 1717243200},
+			expected:    time.Unix(1717243200, 0),
+		},
+		{
+			description: "is zero when the event has no time",
+			event:       events.Message{},
+			expected:    time.Time{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, eventTime(tc.event))
+		})
+	}
+}
+
+func TestSyncLag(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	backend := clock.DefaultBackend
+	t.Cleanup(func() { clock.DefaultBackend = backend })
+
+	clockMock := new(clockmock.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	d := &DockerConnector{
+		cancels:  map[string]context.CancelFunc{"0123456789ab": func() {}},
+		statuses: map[string]string{"0123456789ab": StatusStarted},
+		failures: map[string]Error{},
+		breakers: map[string]*breaker{},
+		names:    map[string]string{},
+		restarts: map[string]Restart{},
+	}
+
+	// NOTICE: the starts made by the reconciliations aren't caused by an event, so they aren't measured.
+	d.observeSyncLag(SyncActionStart, time.Time{})
+	d.observeSyncLag(SyncActionStart, now.Add(-300*time.Millisecond))
+	d.observeSyncLag(SyncActionStart, now.Add(-2*time.Second))
+	// NOTICE: an event in the future, due to a skewed clock, is measured as no lag.
+	d.observeSyncLag(SyncActionStart, now.Add(time.Second))
+
+	assert.True(t, d.stop("0123456789abcdef0123"))
+	assert.False(t, d.stop("0123456789abcdef0123"))
+	d.observeSyncLag(SyncActionStop, now.Add(-100*time.Millisecond))
+
+	lags := d.SyncLag()
+
+	start := lags[SyncActionStart]
+	assert.Equal(t, uint64(3), start.Count)
+	assert.InDelta(t, 2.3, start.Sum, 1e-9)
+	assert.Equal(t, []uint64{1, 1, 1, 2, 2, 3, 3, 3, 3, 3}, start.Counts)
+
+	stop := lags[SyncActionStop]
+	assert.Equal(t, uint64(1), stop.Count)
+	assert.InDelta(t, 0.1, stop.Sum, 1e-9)
+	assert.Equal(t, []uint64{0, 1, 1, 1, 1, 1, 1, 1, 1, 1}, stop.Counts)
+}
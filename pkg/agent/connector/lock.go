@@ -0,0 +1,208 @@
+package connector
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/shellhub-io/shellhub/pkg/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+// lockRenewals is how many times the lock is renewed, or its acquisition retried, inside its TTL.
+const lockRenewals = 3
+
+// Lock is a lock shared by the replicas of the connector, so only one of them drives the connector of a tenant at a
+// time. It expires after its TTL when not renewed, what frees it when the replica holding it crashes.
+type Lock interface {
+	// Acquire tries to acquire the lock for ttl, reporting whether it was acquired.
+	Acquire(ctx context.Context, ttl time.Duration) (bool, error)
+	// Renew extends the lock for ttl, reporting whether it's still held.
+	Renew(ctx context.Context, ttl time.Duration) (bool, error)
+	// Release releases the lock, if it's still held.
+	Release(ctx context.Context) error
+}
+
+// renewLockScript extends the lock's expiration only when it's still held by the token, as it may have expired and
+// been acquired by another replica in the meantime.
+var renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+
+return 0
+`)
+
+// releaseLockScript deletes the lock only when it's still held by the token.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+
+return 0
+`)
+
+type redisLock struct {
+	client *redis.Client
+	// key is the Redis key the lock is stored on.
+	key string
+	// token identifies the replica holding the lock.
+	token string
+}
+
+var _ Lock = new(redisLock)
+
+// NewRedisLock creates a [Lock] for the connector of the tenant stored on the Redis instance at uri.
+func NewRedisLock(uri string, tenant string) (Lock, error) {
+	opt, err := redis.ParseURL(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisLock{
+		client: redis.NewClient(opt),
+		key:    "connector:lock:" + tenant,
+		token:  uuid.Generate(),
+	}, nil
+}
+
+func (l *redisLock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	return l.client.SetNX(ctx, l.key, l.token, ttl).Result()
+}
+
+func (l *redisLock) Renew(ctx context.Context, ttl time.Duration) (bool, error) {
+	renewed, err := renewLockScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return renewed == 1, nil
+}
+
+func (l *redisLock) Release(ctx context.Context) error {
+	return releaseLockScript.Run(ctx, l.client, []string{l.key}, l.token).Err()
+}
+
+// RunLocked listens for the events of the connector only while holding the lock, acquired for ttl and renewed before
+// it expires. When the lock is held by another replica, it waits for it to be released or to expire. When the lock is
+// lost, the connector stops listening and its agents are stopped, until the lock is acquired again.
+//
+// It blocks until ctx is done or the connector fails to listen, releasing the lock.
+func RunLocked(ctx context.Context, conn Connector, lock Lock, ttl time.Duration) error {
+	interval := ttl / lockRenewals
+
+	for {
+		if !acquireLock(ctx, lock, ttl, interval) {
+			return nil
+		}
+
+		log.Info("Connector acquired the lock")
+
+		held, cancel := context.WithCancel(ctx)
+
+		lost := make(chan struct{})
+		renewed := make(chan struct{})
+		go func() {
+			defer close(renewed)
+
+			if !renewLock(held, lock, ttl, interval) {
+				close(lost)
+				cancel()
+			}
+		}()
+
+		err := conn.Listen(held)
+
+		cancel()
+		<-renewed
+
+		for id := range conn.Health().Containers {
+			conn.Stop(ctx, id)
+		}
+
+		// NOTICE: the lock is released even when ctx is done, as the replica is stopping.
+		release, cancelRelease := context.WithTimeout(context.Background(), interval)
+		if err := lock.Release(release); err != nil {
+			log.WithError(err).Warn("Connector failed to release the lock")
+		}
+
+		cancelRelease()
+
+		select {
+		case <-lost:
+			log.Warn("Connector lost the lock, stopping its agents until it's acquired again")
+
+			continue
+		default:
+		}
+
+		return err
+	}
+}
+
+// acquireLock tries to acquire the lock for ttl every interval, until it's acquired or ctx is done. It reports whether
+// the lock was acquired.
+func acquireLock(ctx context.Context, lock Lock, ttl time.Duration, interval time.Duration) bool {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	waiting := false
+	for {
+		acquired, err := lock.Acquire(ctx, ttl)
+		switch {
+		case err != nil:
+			log.WithError(err).Warn("Connector failed to acquire the lock")
+		case acquired:
+			return true
+		case !waiting:
+			log.Info("Connector lock is held by another replica, waiting for it")
+
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// renewLock renews the lock for ttl every interval, until ctx is done. It reports false when the lock was lost, either
+// taken by another replica or about to expire while it couldn't be renewed. In the latter case, it gives up as soon as
+// the lock would expire before the next renewal, leaving the holder time to stop before another replica acquires it.
+func renewLock(ctx context.Context, lock Lock, ttl time.Duration, interval time.Duration) bool {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case <-ticker.C:
+		}
+
+		// NOTICE: a renewal taking longer than the interval would delay the next one past the lock's expiration.
+		renew, cancel := context.WithTimeout(ctx, interval)
+		ok, err := lock.Renew(renew, ttl)
+		cancel()
+
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return true
+			}
+
+			log.WithError(err).Warn("Connector failed to renew the lock")
+
+			if time.Since(renewedAt)+interval >= ttl {
+				return false
+			}
+		case !ok:
+			return false
+		default:
+			renewedAt = time.Now()
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// lockConnector is a [Connector] whose Listen blocks until its context is done, recording the stopped agents.
+type lockConnector struct {
+	Connector
+
+	mu        sync.Mutex
+	listening chan struct{}
+	stopped   []string
+}
+
+func (c *lockConnector) Listen(ctx context.Context) error {
+	c.listening <- struct{}{}
+	<-ctx.Done()
+
+	return nil
+}
+
+func (c *lockConnector) Health() Health {
+	return Health{Containers: map[string]string{"0123456789ab": StatusStarted}}
+}
+
+func (c *lockConnector) Stop(_ context.Context, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopped = append(c.stopped, id)
+}
+
+// memoryLock is a [Lock] held by the one who acquired it until released or taken.
+type memoryLock struct {
+	mu       sync.Mutex
+	held     bool
+	released int
+}
+
+func (l *memoryLock) Acquire(context.Context, time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held {
+		return false, nil
+	}
+
+	l.held = true
+
+	return true, nil
+}
+
+func (l *memoryLock) Renew(context.Context, time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.held, nil
+}
+
+func (l *memoryLock) Release(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.held = false
+	l.released++
+
+	return nil
+}
+
+// take simulates the lock expiring and being acquired by another replica.
+func (l *memoryLock) take(held bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.held = held
+}
+
+// unreachableLock is a [Lock] whose store can't be reached, failing every renewal.
+type unreachableLock struct {
+	memoryLock
+}
+
+func (l *unreachableLock) Renew(context.Context, time.Duration) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestRenewLock(t *testing.T) {
+	const ttl = 300 * time.Millisecond
+
+	t.Run("gives up before the lock expires when it can't be renewed", func(t *testing.T) {
+		start := time.Now()
+
+		assert.False(t, renewLock(context.Background(), new(unreachableLock), ttl, ttl/lockRenewals))
+		assert.Less(t, time.Since(start), ttl)
+	})
+
+	t.Run("keeps the lock while it's renewed until the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*ttl)
+		defer cancel()
+
+		assert.True(t, renewLock(ctx, &memoryLock{held: true}, ttl, ttl/lockRenewals))
+	})
+}
+
+func TestRunLocked(t *testing.T) {
+	const ttl = 30 * time.Millisecond
+
+	t.Run("waits for the lock held by another replica", func(t *testing.T) {
+		conn := &lockConnector{listening: make(chan struct{}, 1)}
+		lock := &memoryLock{held: true}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- RunLocked(ctx, conn, lock, ttl) }()
+
+		select {
+		case <-conn.listening:
+			t.Fatal("listened without holding the lock")
+		case <-time.After(3 * ttl):
+		}
+
+		lock.take(false)
+
+		select {
+		case <-conn.listening:
+		case <-time.After(time.Second):
+			t.Fatal("didn't listen after the lock was released")
+		}
+
+		cancel()
+		assert.NoError(t, <-done)
+		assert.Equal(t, 1, lock.released)
+		assert.Equal(t, []string{"0123456789ab"}, conn.stopped)
+	})
+
+	t.Run("stops the agents when the lock is lost and listens again once acquired", func(t *testing.T) {
+		conn := &lockConnector{listening: make(chan struct{}, 1)}
+		lock := &memoryLock{}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- RunLocked(ctx, conn, lock, ttl) }()
+
+		<-conn.listening
+
+		// NOTICE: the lock is taken by "another replica" and released right away, so it's acquired again.
+		lock.take(false)
+
+		select {
+		case <-conn.listening:
+		case <-time.After(time.Second):
+			t.Fatal("didn't listen again after the lock was acquired again")
+		}
+
+		conn.mu.Lock()
+		assert.Equal(t, []string{"0123456789ab"}, conn.stopped)
+		conn.mu.Unlock()
+
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("returns without listening when the context is done", func(t *testing.T) {
+		conn := &lockConnector{listening: make(chan struct{}, 1)}
+		lock := &memoryLock{held: true}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.NoError(t, RunLocked(ctx, conn, lock, ttl))
+		assert.Empty(t, conn.stopped)
+	})
+}
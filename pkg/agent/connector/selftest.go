@@ -0,0 +1,182 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+// Steps of the connector's self-test, run in this order.
+const (
+	// SelfTestStepServer reaches the ShellHub server the agents connect to.
+	SelfTestStepServer = "server"
+	// SelfTestStepPing pings the Docker Engine.
+	SelfTestStepPing = "ping"
+	// SelfTestStepList lists the running containers.
+	SelfTestStepList = "list"
+	// SelfTestStepInspect inspects a sample of the running containers, the first one listed.
+	SelfTestStepInspect = "inspect"
+)
+
+// Status of the steps of the connector's self-test.
+const (
+	SelfTestStatusPassed = "passed"
+	SelfTestStatusFailed = "failed"
+	// SelfTestStatusSkipped is the status of a step not run, as a previous one failed or there was nothing to test.
+	SelfTestStatusSkipped = "skipped"
+)
+
+// SelfTestStepTimeout is the time limit of each step of the connector's self-test.
+const SelfTestStepTimeout = 10 * time.Second
+
+// errSelfTestSkipped is returned by a step of the self-test that had nothing to test.
+var errSelfTestSkipped = errors.New("skipped")
+
+// SelfTestStep is the result of a step of the connector's self-test.
+type SelfTestStep struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// DurationMS is the time, in milliseconds, the step took.
+	DurationMS int64 `json:"duration_ms"`
+	// Details describes what the step found, when it passed.
+	Details string `json:"details,omitempty"`
+	// Error is the error the step failed with.
+	Error *Error `json:"error,omitempty"`
+}
+
+// SelfTestReport is the result of the connector's self-test.
+type SelfTestReport struct {
+	Tenant string `json:"tenant"`
+	// Passed reports whether no step failed.
+	Passed bool           `json:"passed"`
+	Steps  []SelfTestStep `json:"steps"`
+}
+
+// selfTest runs the steps of a self-test, skipping the remaining ones once a step fails.
+type selfTest struct {
+	report SelfTestReport
+}
+
+func newSelfTest(tenant string) *selfTest {
+	return &selfTest{report: SelfTestReport{Tenant: tenant, Passed: true, Steps: make([]SelfTestStep, 0)}}
+}
+
+// run runs the step named name, limited to [SelfTestStepTimeout], recording its status, timing and details.
+func (t *selfTest) run(ctx context.Context, name string, fn func(ctx context.Context) (string, error)) {
+	step := SelfTestStep{Name: name, Status: SelfTestStatusSkipped}
+	if !t.report.Passed {
+		t.report.Steps = append(t.report.Steps, step)
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, SelfTestStepTimeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := fn(ctx)
+	step.DurationMS = time.Since(start).Milliseconds()
+
+	switch {
+	case errors.Is(err, errSelfTestSkipped):
+	case err != nil:
+		e := newError(err)
+		step.Status, step.Error = SelfTestStatusFailed, &e
+		t.report.Passed = false
+	default:
+		step.Status, step.Details = SelfTestStatusPassed, details
+	}
+
+	t.report.Steps = append(t.report.Steps, step)
+}
+
+// SelfTest tests, end to end, what the connector depends on: the ShellHub server, the Docker Engine and its running
+// containers. It only reads from them, so the agents started by the connector aren't affected.
+func (d *DockerConnector) SelfTest(ctx context.Context) SelfTestReport {
+	test := newSelfTest(d.tenant)
+
+	test.run(ctx, SelfTestStepServer, func(ctx context.Context) (string, error) {
+		info, err := getServerInfo(ctx, d.server)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("ShellHub %s at %s", info.Version, d.server), nil
+	})
+
+	test.run(ctx, SelfTestStepPing, func(ctx context.Context) (string, error) {
+		if err := d.ping(ctx); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("Docker Engine at %s", d.cli.DaemonHost()), nil
+	})
+
+	var sample string
+	test.run(ctx, SelfTestStepList, func(ctx context.Context) (string, error) {
+		var containers []types.Container
+		if err := withAPITimeout(ctx, d.cli.DaemonHost(), d.apiTimeout, func(ctx context.Context) error {
+			var err error
+			containers, err = d.cli.ContainerList(ctx, container.ListOptions{})
+
+			return err
+		}); err != nil {
+			return "", err
+		}
+
+		if len(containers) > 0 {
+			sample = containers[0].ID
+		}
+
+		return fmt.Sprintf("%d running containers", len(containers)), nil
+	})
+
+	test.run(ctx, SelfTestStepInspect, func(ctx context.Context) (string, error) {
+		if sample == "" {
+			return "", errSelfTestSkipped
+		}
+
+		name, err := d.getContainerNameFromID(ctx, sample)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("container %s named %s", sample[:12], name), nil
+	})
+
+	return test.report
+}
+
+// getServerInfo gets the information of the ShellHub server at address. Unlike the API client used by the agents, it
+// doesn't retry, failing as soon as the server can't be reached.
+func getServerInfo(ctx context.Context, address string) (*models.Info, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(address, "/")+"/info", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status from the ShellHub server: %s", res.Status)
+	}
+
+	info := new(models.Info)
+	if err := json.NewDecoder(res.Body).Decode(info); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
@@ -0,0 +1,176 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dockerclient "github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest(t *testing.T) {
+	const id = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	// newServer creates a ShellHub server answering its information.
+	newServer := func(t *testing.T) string {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/info" {
+				http.NotFound(w, r)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"version":"v0.16.0"}`))
+		}))
+		t.Cleanup(server.Close)
+
+		return server.URL
+	}
+
+	// newEngine creates a Docker Engine API running the containers.
+	newEngine := func(t *testing.T, containers string) string {
+		engine := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Api-Version", "1.45")
+
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/_ping"):
+				_, _ = w.Write([]byte("OK"))
+			case strings.HasSuffix(r.URL.Path, "/containers/json"):
+				_, _ = w.Write([]byte(containers))
+			case strings.HasSuffix(r.URL.Path, "/containers/"+id+"/json"):
+				_, _ = w.Write([]byte(`{"Id":"` + id + `","Name":"/web"}`))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(engine.Close)
+
+		return engine.URL
+	}
+
+	newConnector := func(t *testing.T, server, engine string) *DockerConnector {
+		cli, err := dockerclient.NewClientWithOpts(
+			dockerclient.WithHost("tcp://"+strings.TrimPrefix(engine, "http://")),
+			dockerclient.WithVersion("1.45"),
+		)
+		require.NoError(t, err)
+
+		return &DockerConnector{server: server, tenant: "00000000-0000-4000-0000-000000000000", cli: cli}
+	}
+
+	// statuses returns the status of each step of the report.
+	statuses := func(report SelfTestReport) map[string]string {
+		statuses := make(map[string]string)
+		for _, step := range report.Steps {
+			statuses[step.Name] = step.Status
+		}
+
+		return statuses
+	}
+
+	t.Run("passes every step when the dependencies are reachable", func(t *testing.T) {
+		d := newConnector(t, newServer(t), newEngine(t, `[{"Id":"`+id+`"}]`))
+
+		report := d.SelfTest(context.Background())
+		assert.True(t, report.Passed)
+		assert.Equal(t, "00000000-0000-4000-0000-000000000000", report.Tenant)
+		assert.Equal(t, map[string]string{
+			SelfTestStepServer:  SelfTestStatusPassed,
+			SelfTestStepPing:    SelfTestStatusPassed,
+			SelfTestStepList:    SelfTestStatusPassed,
+			SelfTestStepInspect: SelfTestStatusPassed,
+		}, statuses(report))
+		assert.Equal(t, "container 0123456789ab named web", report.Steps[3].Details)
+	})
+
+	t.Run("skips the inspection when there are no running containers", func(t *testing.T) {
+		d := newConnector(t, newServer(t), newEngine(t, `[]`))
+
+		report := d.SelfTest(context.Background())
+		assert.True(t, report.Passed)
+		assert.Equal(t, SelfTestStatusSkipped, statuses(report)[SelfTestStepInspect])
+	})
+
+	t.Run("skips the remaining steps when the server is unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		d := newConnector(t, server.URL, newEngine(t, `[]`))
+
+		report := d.SelfTest(context.Background())
+		assert.False(t, report.Passed)
+		assert.Equal(t, map[string]string{
+			SelfTestStepServer:  SelfTestStatusFailed,
+			SelfTestStepPing:    SelfTestStatusSkipped,
+			SelfTestStepList:    SelfTestStatusSkipped,
+			SelfTestStepInspect: SelfTestStatusSkipped,
+		}, statuses(report))
+		assert.Equal(t, ErrCodeConnectionRefused, report.Steps[0].Error.Code)
+	})
+
+	t.Run("doesn't affect the agents started", func(t *testing.T) {
+		d := newConnector(t, newServer(t), newEngine(t, `[{"Id":"`+id+`"}]`))
+		d.cancels = make(map[string]context.CancelFunc)
+		d.statuses = map[string]string{"0123456789ab": StatusStarted}
+
+		d.SelfTest(context.Background())
+		assert.Equal(t, map[string]string{"0123456789ab": StatusStarted}, d.statuses)
+		assert.Empty(t, d.cancels)
+	})
+}
+
+func TestHealthHandlerSelfTest(t *testing.T) {
+	cases := []struct {
+		description string
+		path        string
+		method      string
+		status      int
+	}{
+		{
+			description: "fails when the tenant isn't the connector's",
+			path:        "/v1/selftest/00000000-0000-4000-0000-000000000001",
+			method:      http.MethodGet,
+			status:      http.StatusNotFound,
+		},
+		{
+			description: "fails when the method isn't allowed",
+			path:        "/v1/selftest/00000000-0000-4000-0000-000000000000",
+			method:      http.MethodPost,
+			status:      http.StatusMethodNotAllowed,
+		},
+		{
+			description: "reports the failed steps as unavailable",
+			path:        "/v1/selftest/00000000-0000-4000-0000-000000000000",
+			method:      http.MethodGet,
+			status:      http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			server := httptest.NewServer(http.NotFoundHandler())
+			server.Close()
+
+			cli, err := dockerclient.NewClientWithOpts(dockerclient.WithHost("tcp://127.0.0.1:1"), dockerclient.WithVersion("1.45"))
+			require.NoError(t, err)
+
+			d := &DockerConnector{server: server.URL, tenant: "00000000-0000-4000-0000-000000000000", cli: cli}
+
+			rec := httptest.NewRecorder()
+			NewHealthHandler(d, "/health", "/metrics", "").ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+			require.Equal(t, tc.status, rec.Code)
+
+			if tc.status == http.StatusServiceUnavailable {
+				report := new(SelfTestReport)
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(report))
+				assert.False(t, report.Passed)
+				assert.Len(t, report.Steps, 4)
+			}
+		})
+	}
+}
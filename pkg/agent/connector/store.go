@@ -0,0 +1,160 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+// ConnectorStore keeps the status of the agents started by the replicas of the connector, so the status of every
+// agent of a tenant can be read from any of them.
+type ConnectorStore interface {
+	// SaveConnectionStatus saves the status of the agent with the specified address, replacing its previous one.
+	SaveConnectionStatus(ctx context.Context, tenantID, address, status string) error
+	// DeleteConnectionStatus deletes the status of the agent with the specified address, as it's no longer started.
+	DeleteConnectionStatus(ctx context.Context, tenantID, address string) error
+	// GetConnectionStatus lists the status of every agent of the tenant, saved by any replica.
+	GetConnectionStatus(ctx context.Context, tenantID string) ([]models.ConnectorStatus, error)
+}
+
+// ConnectorStatusCollection is the MongoDB collection the statuses of the agents are kept on.
+const ConnectorStatusCollection = "connector_status"
+
+var ErrConnectorStoreURI = errors.New("the MongoDB URI doesn't set the database")
+
+type mongoStore struct {
+	collection *mongo.Collection
+	// replica identifies the replica of the connector saving the statuses.
+	replica string
+}
+
+var _ ConnectorStore = new(mongoStore)
+
+// NewMongoConnectorStore creates a [ConnectorStore] on the [ConnectorStatusCollection] of the MongoDB database at uri,
+// identifying the statuses it saves by replica. The collection has a unique index on the tenant ID and the address of
+// each agent.
+func NewMongoConnectorStore(ctx context.Context, uri, replica string) (ConnectorStore, error) {
+	connStr, err := connstring.ParseAndValidate(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if connStr.Database == "" {
+		return nil, ErrConnectorStoreURI
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	collection := client.Database(connStr.Database).Collection(ConnectorStatusCollection)
+	if _, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "address", Value: 1}},
+		Options: options.Index().SetName("tenant_id_address").SetUnique(true),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &mongoStore{collection: collection, replica: replica}, nil
+}
+
+func (s *mongoStore) SaveConnectionStatus(ctx context.Context, tenantID, address, status string) error {
+	_, err := s.collection.UpdateOne(
+		ctx,
+		bson.M{"tenant_id": tenantID, "address": address},
+		bson.M{"$set": bson.M{"status": status, "replica": s.replica, "updated_at": clock.Now()}},
+		options.Update().SetUpsert(true),
+	)
+
+	return err
+}
+
+func (s *mongoStore) DeleteConnectionStatus(ctx context.Context, tenantID, address string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"tenant_id": tenantID, "address": address})
+
+	return err
+}
+
+func (s *mongoStore) GetConnectionStatus(ctx context.Context, tenantID string) ([]models.ConnectorStatus, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{"tenant_id": tenantID}, options.Find().SetSort(bson.D{{Key: "address", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]models.ConnectorStatus, 0)
+	if err := cursor.All(ctx, &statuses); err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// statusWriteTimeout is the time limit of each status change saved on the [ConnectorStore].
+const statusWriteTimeout = 5 * time.Second
+
+// statusUpdate is a change of the status of the agent with address. An empty status deletes it.
+type statusUpdate struct {
+	address string
+	status  string
+}
+
+// statusWriter saves the status changes of the agents on a [ConnectorStore], in the order they happened, without
+// holding the connector while the store is reached.
+type statusWriter struct {
+	store   ConnectorStore
+	tenant  string
+	updates chan statusUpdate
+}
+
+// newStatusWriter creates a [statusWriter] saving the status changes of the agents of the tenant on store.
+func newStatusWriter(store ConnectorStore, tenant string) *statusWriter {
+	w := &statusWriter{store: store, tenant: tenant, updates: make(chan statusUpdate, 1024)}
+
+	go w.run()
+
+	return w
+}
+
+// save queues the change of the agent with address to status. An empty status deletes it. When the queue is full, as
+// the store is unreachable, the change is dropped.
+func (w *statusWriter) save(address, status string) {
+	select {
+	case w.updates <- statusUpdate{address: address, status: status}:
+	default:
+		log.WithFields(log.Fields{"address": address, "status": status}).
+			Warn("dropping the status change of the agent because the store is not keeping up")
+	}
+}
+
+func (w *statusWriter) run() {
+	for update := range w.updates {
+		ctx, cancel := context.WithTimeout(context.Background(), statusWriteTimeout)
+
+		var err error
+		if update.status == "" {
+			err = w.store.DeleteConnectionStatus(ctx, w.tenant, update.address)
+		} else {
+			err = w.store.SaveConnectionStatus(ctx, w.tenant, update.address, update.status)
+		}
+
+		cancel()
+
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{"address": update.address, "status": update.status}).
+				Error("failed to save the status of the agent")
+		}
+	}
+}
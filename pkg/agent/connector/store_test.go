@@ -0,0 +1,175 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is a [ConnectorStore] keeping the statuses in memory, recording every change saved on it.
+type memoryStore struct {
+	mu       sync.Mutex
+	statuses map[string]models.ConnectorStatus
+	changes  []string
+	err      error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{statuses: make(map[string]models.ConnectorStatus)}
+}
+
+func (s *memoryStore) SaveConnectionStatus(_ context.Context, tenantID, address, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.statuses[tenantID+"/"+address] = models.ConnectorStatus{TenantID: tenantID, Address: address, Status: status}
+	s.changes = append(s.changes, address+"="+status)
+
+	return nil
+}
+
+func (s *memoryStore) DeleteConnectionStatus(_ context.Context, tenantID, address string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.statuses, tenantID+"/"+address)
+	s.changes = append(s.changes, address+"=")
+
+	return nil
+}
+
+func (s *memoryStore) GetConnectionStatus(_ context.Context, tenantID string) ([]models.ConnectorStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	statuses := make([]models.ConnectorStatus, 0)
+	for _, status := range s.statuses {
+		if status.TenantID == tenantID {
+			statuses = append(statuses, status)
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Address < statuses[j].Address
+	})
+
+	return statuses, nil
+}
+
+// history returns the changes saved on the store once there are n of them.
+func (s *memoryStore) history(t *testing.T, n int) []string {
+	t.Helper()
+
+	var changes []string
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		changes = append([]string{}, s.changes...)
+
+		return len(changes) >= n
+	}, time.Second, time.Millisecond)
+
+	return changes
+}
+
+func TestDockerConnectorSavesStatuses(t *testing.T) {
+	store := newMemoryStore()
+
+	d := &DockerConnector{
+		tenant:           "00000000-0000-4000-0000-000000000000",
+		cancels:          make(map[string]context.CancelFunc),
+		statuses:         make(map[string]string),
+		failures:         make(map[string]Error),
+		breakers:         make(map[string]*breaker),
+		names:            make(map[string]string),
+		restarts:         make(map[string]Restart),
+		failureThreshold: 2,
+		failureWindow:    time.Minute,
+		statusWriter:     newStatusWriter(store, "00000000-0000-4000-0000-000000000000"),
+	}
+
+	_, _, ok := d.track(context.Background(), "0123456789ab", "container")
+	require.True(t, ok)
+	d.setStatus("0123456789ab", StatusStarted)
+	d.fail("0123456789ab", syscall.ECONNREFUSED)
+
+	_, _, ok = d.track(context.Background(), "0123456789ab", "container")
+	require.True(t, ok)
+	d.fail("0123456789ab", syscall.ECONNREFUSED)
+	assert.True(t, d.Enable("0123456789ab"))
+
+	_, _, ok = d.track(context.Background(), "ba9876543210", "container")
+	require.True(t, ok)
+	d.Stop(context.Background(), "ba9876543210")
+
+	assert.Equal(t, []string{
+		"0123456789ab=" + StatusConnected,
+		"0123456789ab=" + StatusStarted,
+		"0123456789ab=" + StatusFailed,
+		"0123456789ab=" + StatusConnected,
+		"0123456789ab=" + StatusDisabled,
+		"0123456789ab=",
+		"ba9876543210=" + StatusConnected,
+		"ba9876543210=",
+	}, store.history(t, 8))
+}
+
+func TestDockerConnectorStatuses(t *testing.T) {
+	t.Run("succeeds listing the statuses of the agents of this replica without a store", func(t *testing.T) {
+		d := &DockerConnector{
+			tenant:   "00000000-0000-4000-0000-000000000000",
+			statuses: map[string]string{"ba9876543210": StatusFailed, "0123456789ab": StatusStarted},
+		}
+
+		statuses, err := d.Statuses(context.Background())
+		require.NoError(t, err)
+		require.Len(t, statuses, 2)
+		assert.Equal(t, "0123456789ab", statuses[0].Address)
+		assert.Equal(t, StatusStarted, statuses[0].Status)
+		assert.Equal(t, "ba9876543210", statuses[1].Address)
+		assert.Equal(t, StatusFailed, statuses[1].Status)
+	})
+
+	t.Run("succeeds listing the statuses saved by every replica on the store", func(t *testing.T) {
+		store := newMemoryStore()
+		require.NoError(t, store.SaveConnectionStatus(context.Background(), "00000000-0000-4000-0000-000000000000", "0123456789ab", StatusStarted))
+		require.NoError(t, store.SaveConnectionStatus(context.Background(), "00000000-0000-4000-0000-000000000000", "ba9876543210", StatusConnected))
+		require.NoError(t, store.SaveConnectionStatus(context.Background(), "11111111-1111-4111-1111-111111111111", "aaaaaaaaaaaa", StatusStarted))
+
+		d := &DockerConnector{
+			tenant:       "00000000-0000-4000-0000-000000000000",
+			statuses:     map[string]string{},
+			statusWriter: &statusWriter{store: store},
+		}
+
+		statuses, err := d.Statuses(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []models.ConnectorStatus{
+			{TenantID: "00000000-0000-4000-0000-000000000000", Address: "0123456789ab", Status: StatusStarted},
+			{TenantID: "00000000-0000-4000-0000-000000000000", Address: "ba9876543210", Status: StatusConnected},
+		}, statuses)
+	})
+
+	t.Run("fails when the store is unavailable", func(t *testing.T) {
+		store := newMemoryStore()
+		store.err = errors.New("error")
+
+		d := &DockerConnector{statusWriter: &statusWriter{store: store}}
+
+		_, err := d.Statuses(context.Background())
+		assert.EqualError(t, err, "error")
+	})
+}
@@ -0,0 +1,108 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	dockerclient "github.com/docker/docker/client"
+	log "github.com/sirupsen/logrus"
+)
+
+// withDialTimeout is a [dockerclient.Opt] that limits to timeout the dial of the connections to the Docker Engine,
+// replacing the HTTP client of the Docker client with one whose transport dials with it. It does nothing when the
+// timeout is not greater than zero or the Docker Engine is not reached through a Unix socket or TCP.
+//
+// It must be applied after the options that configure the host and the TLS of the Docker client, as it keeps the
+// transport configured by them.
+func withDialTimeout(timeout time.Duration) dockerclient.Opt {
+	return func(cli *dockerclient.Client) error {
+		if timeout <= 0 {
+			return nil
+		}
+
+		host, err := dockerclient.ParseHostURL(cli.DaemonHost())
+		if err != nil {
+			return err
+		}
+
+		if host.Scheme != "unix" && host.Scheme != "tcp" {
+			return nil
+		}
+
+		client := cli.HTTPClient()
+		transport, ok := client.Transport.(*http.Transport)
+		if !ok {
+			return nil
+		}
+
+		// NOTICE: the transport configured by the Docker client dials without a context, through
+		// [http.Transport.Dial], with a fixed timeout. It's replaced by a dialer with the timeout defined, reaching the
+		// same address.
+		dialer := &net.Dialer{Timeout: timeout}
+
+		transport = transport.Clone()
+		transport.Dial = nil //nolint:staticcheck
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, host.Scheme, host.Host)
+			if err != nil && isTimeout(err) {
+				log.WithError(err).WithFields(log.Fields{
+					"address": cli.DaemonHost(),
+					"timeout": timeout.String(),
+				}).Warn("Timed out dialing the Docker Engine")
+			}
+
+			return conn, err
+		}
+
+		client.Transport = transport
+
+		return dockerclient.WithHTTPClient(client)(cli)
+	}
+}
+
+// withAPITimeout calls fn with a context derived from ctx, limited to timeout when it is greater than zero. It logs
+// when the call fails because the timeout was exceeded, with the address of the Docker Engine.
+func withAPITimeout(ctx context.Context, address string, timeout time.Duration, fn func(ctx context.Context) error) error {
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := fn(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		log.WithError(err).WithFields(log.Fields{
+			"address": address,
+			"timeout": timeout.String(),
+		}).Warn("Timed out requesting the Docker Engine API")
+	}
+
+	return err
+}
+
+// observeExecTimeout counts a session whose exec timed out.
+func (d *DockerConnector) observeExecTimeout() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.execTimeouts++
+}
+
+// ExecTimeoutCount returns the number of sessions whose exec timed out since the connector was started.
+func (d *DockerConnector) ExecTimeoutCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.execTimeouts
+}
+
+// isTimeout reports whether err is a network timeout.
+func isTimeout(err error) bool {
+	var netErr net.Error
+
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
@@ -0,0 +1,160 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	dockerclient "github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPITimeout(t *testing.T) {
+	// newEngine serves a mock Docker Engine API that responds to every request after delay.
+	newEngine := func(t *testing.T, delay time.Duration) *httptest.Server {
+		done := make(chan struct{})
+		engine := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(delay):
+			case <-done:
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Api-Version", "1.45")
+
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/_ping"):
+				_, _ = w.Write([]byte("OK"))
+			case strings.HasSuffix(r.URL.Path, "/containers/json"):
+				_, _ = w.Write([]byte(`[{"Id": "0123456789abcdef0123"}]`))
+			case strings.HasSuffix(r.URL.Path, "/containers/0123456789abcdef0123/json"):
+				_, _ = w.Write([]byte(`{"Id": "0123456789abcdef0123", "Name": "/container"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		t.Cleanup(func() {
+			close(done)
+			engine.Close()
+		})
+
+		return engine
+	}
+
+	cases := []struct {
+		description string
+		delay       time.Duration
+		apiTimeout  time.Duration
+		expected    []Container
+		timedOut    bool
+	}{
+		{
+			description: "fails when the Docker Engine responds after the timeout",
+			delay:       time.Second,
+			apiTimeout:  50 * time.Millisecond,
+			expected:    nil,
+			timedOut:    true,
+		},
+		{
+			description: "succeeds when the Docker Engine responds before the timeout",
+			delay:       0,
+			apiTimeout:  time.Second,
+			expected:    []Container{{ID: "0123456789abcdef0123", Name: "container"}},
+			timedOut:    false,
+		},
+		{
+			description: "succeeds when the timeout is disabled",
+			delay:       100 * time.Millisecond,
+			apiTimeout:  0,
+			expected:    []Container{{ID: "0123456789abcdef0123", Name: "container"}},
+			timedOut:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			engine := newEngine(t, tc.delay)
+
+			cli, err := dockerclient.NewClientWithOpts(
+				dockerclient.WithHost("tcp://"+strings.TrimPrefix(engine.URL, "http://")),
+				dockerclient.WithVersion("1.45"),
+				withDialTimeout(time.Second),
+			)
+			require.NoError(t, err)
+
+			d := &DockerConnector{cli: cli, apiTimeout: tc.apiTimeout}
+
+			start := time.Now()
+			pingErr := d.ping(context.Background())
+			containers, listErr := d.List(context.Background())
+
+			assert.Equal(t, tc.expected, containers)
+			if tc.timedOut {
+				assert.ErrorIs(t, pingErr, context.DeadlineExceeded)
+				assert.ErrorIs(t, listErr, context.DeadlineExceeded)
+				assert.Less(t, time.Since(start), tc.delay)
+			} else {
+				assert.NoError(t, pingErr)
+				assert.NoError(t, listErr)
+			}
+		})
+	}
+}
+
+func TestWithDialTimeout(t *testing.T) {
+	// newClient creates a Docker client to the host whose transport isn't wrapped, like it is while the options are
+	// applied.
+	newClient := func(t *testing.T, host string) *dockerclient.Client {
+		cli, err := dockerclient.NewClientWithOpts(dockerclient.WithHost(host))
+		require.NoError(t, err)
+		require.NoError(t, dockerclient.WithHTTPClient(&http.Client{Transport: &http.Transport{}})(cli))
+
+		return cli
+	}
+
+	t.Run("keeps the HTTP client when the timeout is disabled", func(t *testing.T) {
+		cli := newClient(t, "tcp://127.0.0.1:2375")
+
+		transport := cli.HTTPClient().Transport
+		require.NoError(t, withDialTimeout(0)(cli))
+		assert.Same(t, transport, cli.HTTPClient().Transport)
+	})
+
+	t.Run("dials the Docker Engine with the timeout", func(t *testing.T) {
+		cli := newClient(t, "tcp://127.0.0.1:2375")
+
+		transport := cli.HTTPClient().Transport
+		require.NoError(t, withDialTimeout(time.Second)(cli))
+		assert.NotSame(t, transport, cli.HTTPClient().Transport)
+
+		dialed, ok := cli.HTTPClient().Transport.(*http.Transport)
+		require.True(t, ok)
+		assert.NotNil(t, dialed.DialContext)
+	})
+
+	t.Run("fails dialing when the timeout is exceeded", func(t *testing.T) {
+		cli := newClient(t, "tcp://127.0.0.1:2375")
+		require.NoError(t, withDialTimeout(time.Nanosecond)(cli))
+
+		dialed, ok := cli.HTTPClient().Transport.(*http.Transport)
+		require.True(t, ok)
+
+		_, err := dialed.DialContext(context.Background(), "tcp", "127.0.0.1:2375")
+		assert.True(t, isTimeout(err))
+	})
+}
+
+func TestExecTimeoutCount(t *testing.T) {
+	d := &DockerConnector{}
+	assert.Equal(t, 0, d.ExecTimeoutCount())
+
+	d.observeExecTimeout()
+	d.observeExecTimeout()
+	assert.Equal(t, 2, d.ExecTimeoutCount())
+}
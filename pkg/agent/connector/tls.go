@@ -0,0 +1,170 @@
+package connector
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	dockerclient "github.com/docker/docker/client"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrTLSVersionInvalid     = errors.New("invalid TLS version")
+	ErrTLSVersionInsecure    = errors.New("TLS versions lower than 1.2 are not allowed")
+	ErrTLSCipherSuiteInvalid = errors.New("invalid TLS cipher suite")
+	ErrTLSCipherSuiteWeak    = errors.New("insecure TLS cipher suite is not allowed")
+)
+
+// tlsVersions maps the accepted values for the minimum TLS version to its [tls] constants.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// defaultCipherSuites are the cipher suites used when none is configured. Only suites with forward secrecy and AEAD are
+// allowed.
+var defaultCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// TLSConfig defines how the connector negotiates TLS with the Docker Engine, when it is reached through TLS.
+type TLSConfig struct {
+	// MinVersion is the minimum TLS version accepted. It can be "1.2" or "1.3".
+	MinVersion string
+	// CipherSuites are the names of the cipher suites allowed, as named by [tls.CipherSuiteName]. When empty, a set of
+	// secure cipher suites is used. Cipher suites are not configurable on TLS 1.3, so they only apply to TLS 1.2.
+	CipherSuites []string
+
+	minVersion   uint16
+	cipherSuites []uint16
+}
+
+// NewTLSConfig creates a [TLSConfig] validating the minimum version and cipher suites informed.
+func NewTLSConfig(minVersion string, cipherSuites []string) (*TLSConfig, error) {
+	cfg := &TLSConfig{MinVersion: minVersion, CipherSuites: cipherSuites}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *TLSConfig) validate() error {
+	switch c.MinVersion {
+	case "1.0", "1.1":
+		return ErrTLSVersionInsecure
+	case "":
+		c.minVersion = tls.VersionTLS12
+	default:
+		version, ok := tlsVersions[c.MinVersion]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrTLSVersionInvalid, c.MinVersion)
+		}
+
+		c.minVersion = version
+	}
+
+	if len(c.CipherSuites) == 0 {
+		c.cipherSuites = defaultCipherSuites
+
+		return nil
+	}
+
+	secure := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		secure[suite.Name] = suite.ID
+	}
+
+	insecure := make(map[string]uint16)
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.Name] = suite.ID
+	}
+
+	c.cipherSuites = make([]uint16, 0, len(c.CipherSuites))
+	for _, name := range c.CipherSuites {
+		if _, ok := insecure[name]; ok {
+			return fmt.Errorf("%w: %s", ErrTLSCipherSuiteWeak, name)
+		}
+
+		id, ok := secure[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrTLSCipherSuiteInvalid, name)
+		}
+
+		c.cipherSuites = append(c.cipherSuites, id)
+	}
+
+	return nil
+}
+
+// apply restricts the TLS configuration to the minimum version and cipher suites defined.
+func (c *TLSConfig) apply(cfg *tls.Config) {
+	cfg.MinVersion = c.minVersion
+	cfg.CipherSuites = c.cipherSuites
+}
+
+// withTLSConfig is a [dockerclient.Opt] that enforces the [TLSConfig] on the Docker client. It does nothing when the
+// client doesn't use TLS to reach the Docker Engine.
+func withTLSConfig(cfg *TLSConfig) dockerclient.Opt {
+	return func(cli *dockerclient.Client) error {
+		if cfg == nil {
+			return nil
+		}
+
+		// NOTICE: [dockerclient.Client.HTTPClient] returns a copy of the HTTP client, but its transport is shared with
+		// the Docker client.
+		transport, ok := cli.HTTPClient().Transport.(*http.Transport)
+		if !ok || transport.TLSClientConfig == nil {
+			return nil
+		}
+
+		cfg.apply(transport.TLSClientConfig)
+
+		return nil
+	}
+}
+
+// logNegotiatedTLS performs a TLS handshake with the Docker Engine, using the same configuration as the Docker client,
+// and logs the negotiated version and cipher suite. It does nothing when the client doesn't use TLS.
+func logNegotiatedTLS(cli *dockerclient.Client) error {
+	transport, ok := cli.HTTPClient().Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig == nil {
+		return nil
+	}
+
+	host, err := url.Parse(cli.DaemonHost())
+	if err != nil {
+		return err
+	}
+
+	config := transport.TLSClientConfig.Clone()
+	if config.ServerName == "" {
+		config.ServerName = host.Hostname()
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", host.Host, config)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	log.WithFields(log.Fields{
+		"host":         host.Host,
+		"version":      tls.VersionName(state.Version),
+		"cipher_suite": tls.CipherSuiteName(state.CipherSuite),
+	}).Info("TLS connection negotiated with the Docker Engine")
+
+	return nil
+}
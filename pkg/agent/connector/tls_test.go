@@ -0,0 +1,98 @@
+package connector
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	type Expected struct {
+		minVersion   uint16
+		cipherSuites []uint16
+		err          error
+	}
+
+	cases := []struct {
+		description  string
+		minVersion   string
+		cipherSuites []string
+		expected     Expected
+	}{
+		{
+			description:  "succeeds with defaults when nothing is informed",
+			minVersion:   "",
+			cipherSuites: nil,
+			expected: Expected{
+				minVersion:   tls.VersionTLS12,
+				cipherSuites: defaultCipherSuites,
+				err:          nil,
+			},
+		},
+		{
+			description:  "succeeds with TLS 1.3",
+			minVersion:   "1.3",
+			cipherSuites: nil,
+			expected: Expected{
+				minVersion:   tls.VersionTLS13,
+				cipherSuites: defaultCipherSuites,
+				err:          nil,
+			},
+		},
+		{
+			description:  "succeeds with secure cipher suites",
+			minVersion:   "1.2",
+			cipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+			expected: Expected{
+				minVersion:   tls.VersionTLS12,
+				cipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+				err:          nil,
+			},
+		},
+		{
+			description:  "fails when version is lower than 1.2",
+			minVersion:   "1.1",
+			cipherSuites: nil,
+			expected:     Expected{err: ErrTLSVersionInsecure},
+		},
+		{
+			description:  "fails when version is unknown",
+			minVersion:   "2.0",
+			cipherSuites: nil,
+			expected:     Expected{err: ErrTLSVersionInvalid},
+		},
+		{
+			description:  "fails when cipher suite is insecure",
+			minVersion:   "1.2",
+			cipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"},
+			expected:     Expected{err: ErrTLSCipherSuiteWeak},
+		},
+		{
+			description:  "fails when cipher suite is unknown",
+			minVersion:   "1.2",
+			cipherSuites: []string{"TLS_UNKNOWN"},
+			expected:     Expected{err: ErrTLSCipherSuiteInvalid},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg, err := NewTLSConfig(tc.minVersion, tc.cipherSuites)
+			if tc.expected.err != nil {
+				assert.ErrorIs(t, err, tc.expected.err)
+				assert.Nil(t, cfg)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			config := new(tls.Config)
+			cfg.apply(config)
+
+			assert.Equal(t, tc.expected.minVersion, config.MinVersion)
+			assert.Equal(t, tc.expected.cipherSuites, config.CipherSuites)
+		})
+	}
+}
@@ -78,12 +78,16 @@ func (m *HostMode) GetInfo() (*Info, error) {
 type ConnectorMode struct {
 	cli      *dockerclient.Client
 	identity string
+	timeouts connector.Timeouts
 }
 
-func NewConnectorMode(cli *dockerclient.Client, identity string) (Mode, error) {
+// NewConnectorMode creates the `Connector` mode for the container with the given identity, limiting the sessions
+// opened in it by timeouts.
+func NewConnectorMode(cli *dockerclient.Client, identity string, timeouts connector.Timeouts) (Mode, error) {
 	return &ConnectorMode{
 		cli:      cli,
 		identity: identity,
+		timeouts: timeouts,
 	}, nil
 }
 
@@ -102,7 +106,7 @@ func (m *ConnectorMode) Serve(agent *Agent) {
 		agent.config.SingleUserPassword,
 		&connector.Mode{
 			Authenticator: *connector.NewAuthenticator(agent.cli, m.cli, agent.authData, &agent.Identity.MAC),
-			Sessioner:     *connector.NewSessioner(&agent.Identity.MAC, m.cli),
+			Sessioner:     *connector.NewSessioner(&agent.Identity.MAC, m.cli, m.timeouts),
 		},
 	)
 
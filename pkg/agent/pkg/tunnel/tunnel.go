@@ -79,6 +79,10 @@ func NewTunnel() *Tunnel {
 	e.GET("/ssh/close/:id", func(e echo.Context) error {
 		return t.CloseHandler(e)
 	})
+	// NOTICE: the ping is answered by the tunnel itself, so the SSH server can time a round trip to the agent.
+	e.GET("/ping", func(e echo.Context) error {
+		return e.NoContent(http.StatusOK)
+	})
 
 	return t
 }
@@ -6,18 +6,83 @@ package connector
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	dockerclient "github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/process"
 	"github.com/shellhub-io/shellhub/pkg/agent/pkg/osauth"
+	log "github.com/sirupsen/logrus"
 )
 
+// ErrExecTimeout is returned when the exec of a session isn't created and started in the container in time.
+var ErrExecTimeout = errors.New("timed out starting the session in the container")
+
 type Mode struct {
 	Authenticator
 	Sessioner
 }
 
+// Timeouts limits the sessions opened in the containers.
+type Timeouts struct {
+	// Exec is the time limit to create and start the exec of a session in the container. When zero, it isn't limited.
+	Exec time.Duration
+	// Command is the time limit of the commands run by the exec sessions, whose processes are killed once it's
+	// exceeded. The shell and heredoc sessions aren't limited. When zero, the commands aren't limited.
+	Command time.Duration
+	// OnExecTimeout, when not nil, is called each time the exec of a session times out.
+	OnExecTimeout func()
+}
+
+// withExecTimeout calls attach with a context derived from ctx, limited to timeout when it is greater than zero. When
+// the timeout is exceeded, onTimeout is called, if not nil, and [ErrExecTimeout] is returned.
+//
+// NOTICE: the context only limits the creation and start of the exec. The hijacked connection returned by attach
+// isn't closed when the context is canceled, so the session lasts beyond it.
+func withExecTimeout(ctx context.Context, timeout time.Duration, onTimeout func(), attach func(ctx context.Context) (*types.HijackedResponse, string, error)) (*types.HijackedResponse, string, error) {
+	if timeout <= 0 {
+		return attach(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, id, err := attach(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		log.WithError(err).WithField("timeout", timeout.String()).Warn("Timed out starting the session in the container")
+
+		if onTimeout != nil {
+			onTimeout()
+		}
+
+		return nil, "", ErrExecTimeout
+	}
+
+	return resp, id, err
+}
+
+// killExecAfter kills the process of the exec with the given ID once timeout elapses, calling onKill, if not nil,
+// before it. The returned function stops the timer, reporting whether it was stopped before the process was killed.
+// When timeout isn't greater than zero, the process is never killed.
+func killExecAfter(cli dockerclient.APIClient, id string, timeout time.Duration, onKill func()) func() bool {
+	if timeout <= 0 {
+		return func() bool { return true }
+	}
+
+	timer := time.AfterFunc(timeout, func() {
+		if onKill != nil {
+			onKill()
+		}
+
+		if _, err := exitCodeExecFromContainer(cli, id); err != nil {
+			log.WithError(err).WithField("id", id).Warn("Failed to kill the command that exceeded its time limit")
+		}
+	})
+
+	return timer.Stop
+}
+
 func attachShellToContainer(ctx context.Context, cli dockerclient.APIClient, container string, user *osauth.User, size [2]uint) (*types.HijackedResponse, string, error) {
 	return attachToContainer(ctx, cli, "shell", container, user, true, []string{}, size)
 }
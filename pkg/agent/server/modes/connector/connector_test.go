@@ -0,0 +1,128 @@
+package connector
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/shellhub-io/shellhub/pkg/agent/pkg/osauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowDockerClient is a mock Docker client that creates the execs only after delay, as a Docker Engine under load.
+type slowDockerClient struct {
+	dockerclient.APIClient
+	delay   time.Duration
+	inspect types.ContainerExecInspect
+}
+
+func (c *slowDockerClient) ContainerExecCreate(ctx context.Context, _ string, _ types.ExecConfig) (types.IDResponse, error) {
+	select {
+	case <-time.After(c.delay):
+		return types.IDResponse{ID: "exec"}, nil
+	case <-ctx.Done():
+		return types.IDResponse{}, ctx.Err()
+	}
+}
+
+func (c *slowDockerClient) ContainerExecAttach(ctx context.Context, _ string, _ types.ExecStartCheck) (types.HijackedResponse, error) {
+	return types.HijackedResponse{}, ctx.Err()
+}
+
+func (c *slowDockerClient) ContainerExecInspect(_ context.Context, _ string) (types.ContainerExecInspect, error) {
+	return c.inspect, nil
+}
+
+func TestWithExecTimeout(t *testing.T) {
+	cases := []struct {
+		description string
+		delay       time.Duration
+		timeout     time.Duration
+		timeouts    int
+		expected    error
+	}{
+		{
+			description: "succeeds when the exec is started in time",
+			delay:       0,
+			timeout:     time.Second,
+			timeouts:    0,
+			expected:    nil,
+		},
+		{
+			description: "succeeds when the timeout is disabled",
+			delay:       50 * time.Millisecond,
+			timeout:     0,
+			timeouts:    0,
+			expected:    nil,
+		},
+		{
+			description: "fails when the exec isn't started in time",
+			delay:       time.Second,
+			timeout:     50 * time.Millisecond,
+			timeouts:    1,
+			expected:    ErrExecTimeout,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			cli := &slowDockerClient{delay: tc.delay}
+
+			timeouts := 0
+			resp, id, err := withExecTimeout(context.Background(), tc.timeout, func() { timeouts++ }, func(ctx context.Context) (*types.HijackedResponse, string, error) {
+				return attachExecToContainer(ctx, cli, "container", &osauth.User{Username: "root"}, false, []string{"true"}, [2]uint{24, 80})
+			})
+
+			assert.ErrorIs(t, err, tc.expected)
+			assert.Equal(t, tc.timeouts, timeouts)
+			if tc.expected == nil {
+				assert.NotNil(t, resp)
+				assert.Equal(t, "exec", id)
+			}
+		})
+	}
+}
+
+func TestKillExecAfter(t *testing.T) {
+	t.Run("kills the command that exceeds the timeout", func(t *testing.T) {
+		cmd := exec.Command("sleep", "10")
+		require.NoError(t, cmd.Start())
+
+		cli := &slowDockerClient{inspect: types.ContainerExecInspect{Running: true, Pid: cmd.Process.Pid}}
+
+		killed := make(chan struct{})
+		stop := killExecAfter(cli, "exec", 50*time.Millisecond, func() { close(killed) })
+		defer stop()
+
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+
+		select {
+		case err := <-done:
+			assert.Error(t, err)
+		case <-time.After(5 * time.Second):
+			_ = cmd.Process.Kill()
+			t.Fatal("the command wasn't killed after the timeout")
+		}
+
+		<-killed
+	})
+
+	t.Run("doesn't kill the command that finishes in time", func(t *testing.T) {
+		cli := &slowDockerClient{}
+
+		stop := killExecAfter(cli, "exec", time.Minute, func() { t.Error("the command was killed") })
+		assert.True(t, stop())
+	})
+
+	t.Run("doesn't kill the command when the timeout is disabled", func(t *testing.T) {
+		cli := &slowDockerClient{}
+
+		stop := killExecAfter(cli, "exec", 0, func() { t.Error("the command was killed") })
+		assert.True(t, stop())
+	})
+}
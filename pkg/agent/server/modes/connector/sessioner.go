@@ -1,11 +1,13 @@
 package connector
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"sync"
 
+	"github.com/docker/docker/api/types"
 	dockerclient "github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 	gliderssh "github.com/gliderlabs/ssh"
@@ -25,18 +27,32 @@ type Sessioner struct {
 	// NOTICE: It's a pointer because when the server is created, we don't know the device name yet, that is set later.
 	container *string
 	docker    dockerclient.APIClient
+	timeouts  Timeouts
 }
 
-// NewSessioner creates a new instance of Sessioner for the connector mode.
+// NewSessioner creates a new instance of Sessioner for the connector mode, limiting its sessions by timeouts.
 // The container is a pointer to a string because when the server is created, we don't know the device name yet, that
 // is set later.
-func NewSessioner(container *string, docker dockerclient.APIClient) *Sessioner {
+func NewSessioner(container *string, docker dockerclient.APIClient, timeouts Timeouts) *Sessioner {
 	return &Sessioner{
 		container: container,
 		docker:    docker,
+		timeouts:  timeouts,
 	}
 }
 
+// attach calls attach to start the session in the container, limited to the exec timeout. When it's exceeded, the
+// session is closed with an error written to its stderr.
+func (s *Sessioner) attach(session gliderssh.Session, attach func(ctx context.Context) (*types.HijackedResponse, string, error)) (*types.HijackedResponse, string, error) {
+	resp, id, err := withExecTimeout(session.Context(), s.timeouts.Exec, s.timeouts.OnExecTimeout, attach)
+	if errors.Is(err, ErrExecTimeout) {
+		fmt.Fprintln(session.Stderr(), err.Error())
+		session.Exit(1) //nolint:errcheck
+	}
+
+	return resp, id, err
+}
+
 // Shell handles the server's SSH shell session when server is running in connector mode.
 func (s *Sessioner) Shell(session gliderssh.Session) error {
 	sspty, _, _ := session.Pty()
@@ -49,7 +65,9 @@ func (s *Sessioner) Shell(session gliderssh.Session) error {
 		return ErrUserNotFound
 	}
 
-	resp, id, err := attachShellToContainer(session.Context(), s.docker, container, user, [2]uint{uint(sspty.Window.Height), uint(sspty.Window.Width)})
+	resp, id, err := s.attach(session, func(ctx context.Context) (*types.HijackedResponse, string, error) {
+		return attachShellToContainer(ctx, s.docker, container, user, [2]uint{uint(sspty.Window.Height), uint(sspty.Window.Width)})
+	})
 	if err != nil {
 		return err
 	}
@@ -100,12 +118,19 @@ func (s *Sessioner) Exec(session gliderssh.Session) error {
 		return ErrUserNotFound
 	}
 
-	resp, id, err := attachExecToContainer(session.Context(), s.docker, container, user, isPty, session.Command(), [2]uint{uint(sspty.Window.Height), uint(sspty.Window.Width)})
+	resp, id, err := s.attach(session, func(ctx context.Context) (*types.HijackedResponse, string, error) {
+		return attachExecToContainer(ctx, s.docker, container, user, isPty, session.Command(), [2]uint{uint(sspty.Window.Height), uint(sspty.Window.Width)})
+	})
 	if err != nil {
 		return err
 	}
 	defer resp.Close()
 
+	stop := killExecAfter(s.docker, id, s.timeouts.Command, func() {
+		fmt.Fprintln(session.Stderr(), "the command exceeded its time limit")
+	})
+	defer stop()
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -165,7 +190,9 @@ func (s *Sessioner) Heredoc(session gliderssh.Session) error {
 		return ErrUserNotFound
 	}
 
-	resp, id, err := attachHereDocToContainer(session.Context(), s.docker, container, user, [2]uint{uint(sspty.Window.Height), uint(sspty.Window.Width)})
+	resp, id, err := s.attach(session, func(ctx context.Context) (*types.HijackedResponse, string, error) {
+		return attachHereDocToContainer(ctx, s.docker, container, user, [2]uint{uint(sspty.Window.Height), uint(sspty.Window.Width)})
+	})
 	if err != nil {
 		return err
 	}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"net"
 	"net/http"
+	"sync"
 
 	resty "github.com/go-resty/resty/v2"
 	"github.com/hibiken/asynq"
@@ -15,6 +16,10 @@ type client struct {
 	http   *resty.Client
 	logger *logrus.Logger
 	asynq  *asynq.Client
+	// router routes the requests made on behalf of a tenant to the API server serving it.
+	router TenantRouter
+	// servers holds, by URL, the clients of the API servers the tenants were routed to, shared with them.
+	servers *sync.Map
 }
 
 type Client interface {
@@ -24,6 +29,11 @@ type Client interface {
 	sessionAPI
 	sshkeyAPI
 	firewallAPI
+
+	// ForTenant returns a client sending the requests to the API server serving the tenant with the specified ID, as
+	// chosen by its [TenantRouter], what routes the requests that don't carry the tenant, like the session's ones,
+	// made on its behalf.
+	ForTenant(tenantID string) Client
 }
 
 // Ensures the client implements Client.
@@ -44,13 +54,26 @@ var (
 // and its properties are privated.
 type Options struct {
 	Asynq *asynq.Client
+	// Router routes the requests made on behalf of a tenant to the API server serving it. When nil, every request is
+	// sent to [DefaultAPIURL].
+	Router TenantRouter
 }
 
 type Opt func(*Options) error
 
-func NewClient(opts ...Opt) Client {
+// WithTenantRouter routes the requests made on behalf of a tenant, like looking its namespace up, through router.
+func WithTenantRouter(router TenantRouter) Opt {
+	return func(o *Options) error {
+		o.Router = router
+
+		return nil
+	}
+}
+
+// newHTTPClient creates the HTTP client sending the requests to the API server at url.
+func newHTTPClient(url string) *resty.Client {
 	httpClient := resty.New()
-	httpClient.SetBaseURL("http://api:8080")
+	httpClient.SetBaseURL(url)
 	httpClient.SetRetryCount(math.MaxInt32)
 	httpClient.AddRetryCondition(func(r *resty.Response, err error) bool {
 		if _, ok := err.(net.Error); ok { // if the error is a network error, retry.
@@ -60,7 +83,13 @@ func NewClient(opts ...Opt) Client {
 		return r.StatusCode() >= http.StatusInternalServerError && r.StatusCode() != http.StatusNotImplemented
 	})
 
-	c := &client{http: httpClient}
+	return httpClient
+}
+
+func NewClient(opts ...Opt) Client {
+	httpClient := newHTTPClient(DefaultAPIURL)
+
+	c := &client{http: httpClient, router: NewStaticRouter(DefaultAPIURL), servers: new(sync.Map)}
 
 	o := new(Options)
 	for _, opt := range opts {
@@ -73,6 +102,10 @@ func NewClient(opts ...Opt) Client {
 		c.asynq = o.Asynq
 	}
 
+	if o.Router != nil {
+		c.router = o.Router
+	}
+
 	if c.logger != nil {
 		httpClient.SetLogger(&LeveledLogger{c.logger})
 	}
@@ -84,9 +117,9 @@ func NewClient(opts ...Opt) Client {
 // able to enqueue ping tasks to the Asynq server and late process by API server.
 //
 // It uses the [NewClient] function to create a new API internal client and injects the Asynq client to it through the
-// [Options] structure.
-func NewClientWithAsynq(uri string) Client {
-	return NewClient(func(o *Options) error {
+// [Options] structure, besides the other options in opts.
+func NewClientWithAsynq(uri string, opts ...Opt) Client {
+	return NewClient(append([]Opt{func(o *Options) error {
 		// The internal client used by the SSH server needs to be able to enqueue tasks to the Asynq server, due that,
 		// we must set the Asynq client to the internal client as a configuration function.
 		options, err := asynq.ParseRedisURI(uri)
@@ -102,5 +135,31 @@ func NewClientWithAsynq(uri string) Client {
 		o.Asynq = client
 
 		return nil
+	}}, opts...)...)
+}
+
+func (c *client) ForTenant(tenantID string) Client {
+	url := c.router.RouteForTenant(tenantID)
+	if url == c.http.BaseURL {
+		return c
+	}
+
+	if server, ok := c.servers.Load(url); ok {
+		return server.(*client)
+	}
+
+	httpClient := newHTTPClient(url)
+	if c.logger != nil {
+		httpClient.SetLogger(&LeveledLogger{c.logger})
+	}
+
+	server, _ := c.servers.LoadOrStore(url, &client{
+		http:    httpClient,
+		logger:  c.logger,
+		asynq:   c.asynq,
+		router:  c.router,
+		servers: c.servers,
 	})
+
+	return server.(*client)
 }
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hibiken/asynq"
@@ -31,8 +34,18 @@ type deviceAPI interface {
 
 	// DeviceLookup performs a lookup operation based on the provided parameters.
 	DeviceLookup(lookup map[string]string) (*models.Device, []error)
+
+	// PingDevice asks the SSH server to check if the device is reachable through its tunnel, waiting at most the
+	// timeout for an answer.
+	PingDevice(tenant, uid string, timeout time.Duration) (*models.DevicePingResult, error)
+
+	// CheckDeviceExpiry checks if the device's access to its namespace has not ended. It returns [ErrDeviceExpired]
+	// when it has.
+	CheckDeviceExpiry(uid string) error
 }
 
+var ErrDeviceExpired = errors.New("the device's access to the namespace has expired")
+
 func (c *client) DevicesOffline(uid string) error {
 	_, err := c.http.
 		R().
@@ -45,7 +58,15 @@ func (c *client) DevicesOffline(uid string) error {
 }
 
 func (c *client) DevicesHeartbeat(tenant, uid string) error {
-	payload := []byte(fmt.Sprintf("%s:%s=%d", tenant, uid, clock.Now().Unix()))
+	// NOTICE: the payload is prefixed with its schema version, which must match the API's HeartbeatPayloadVersion. The
+	// API workers must be upgraded before the services sending it, like the SSH server, as the previous ones only parse
+	// the "v1" payloads, without a prefix, dropping the heartbeats of any other version.
+	fields := url.Values{}
+	fields.Set("tenant_id", tenant)
+	fields.Set("uid", uid)
+	fields.Set("last_seen", strconv.FormatInt(clock.Now().Unix(), 10))
+
+	payload := []byte("v2:" + fields.Encode())
 	_, err := c.asynq.Enqueue(asynq.NewTask("api:heartbeat", payload), asynq.Queue("api"), asynq.Group("heartbeats"))
 
 	return err
@@ -141,3 +162,47 @@ func (c *client) GetDeviceByPublicURLAddress(address string) (*models.Device, er
 		return nil, ErrUnknown
 	}
 }
+
+func (c *client) PingDevice(tenant, uid string, timeout time.Duration) (*models.DevicePingResult, error) {
+	// NOTICE: a dedicated client is used here because the default one retries indefinitely, what would hold the
+	// request far beyond the ping timeout when the device is unreachable.
+	httpClient := resty.New()
+	httpClient.SetTimeout(timeout + time.Second)
+
+	result := new(models.DevicePingResult)
+	resp, err := httpClient.
+		R().
+		SetHeader("X-Tenant-ID", tenant).
+		SetQueryParam("timeout", strconv.FormatInt(timeout.Milliseconds(), 10)).
+		SetResult(result).
+		Get(fmt.Sprintf("http://ssh:8080/devices/%s/ping", uid))
+	if err != nil {
+		return nil, ErrConnectionFailed
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, ErrUnknown
+	}
+
+	return result, nil
+}
+
+func (c *client) CheckDeviceExpiry(uid string) error {
+	resp, err := c.http.
+		R().
+		Get(fmt.Sprintf("/internal/devices/%s/expiry", uid))
+	if err != nil {
+		return ErrConnectionFailed
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		return nil
+	case http.StatusForbidden:
+		return ErrDeviceExpired
+	case http.StatusNotFound:
+		return ErrNotFound
+	default:
+		return ErrUnknown
+	}
+}
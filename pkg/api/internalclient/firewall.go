@@ -2,16 +2,14 @@ package internalclient
 
 import (
 	"errors"
-	"net"
 	"net/http"
-
-	"github.com/go-resty/resty/v2"
 )
 
 // firewallAPI defines methods for interacting with firewall-related functionality.
 type firewallAPI interface {
-	// FirewallEvaluate evaluates firewall rules based on the provided lookup parameters.
-	// It returns an error if the evaluation fails or if a firewall rule prohibits the connection.
+	// FirewallEvaluate evaluates the firewall rules of the namespace, and its default firewall policy, against the
+	// connection described by the lookup parameters. It returns an error if the evaluation fails or if the connection
+	// is prohibited.
 	FirewallEvaluate(lookup map[string]string) error
 }
 
@@ -21,20 +19,10 @@ var (
 )
 
 func (c *client) FirewallEvaluate(lookup map[string]string) error {
-	local := resty.New()
-	local.AddRetryCondition(func(r *resty.Response, err error) bool {
-		if _, ok := err.(net.Error); ok {
-			return true
-		}
-
-		return r.StatusCode() >= http.StatusInternalServerError && r.StatusCode() != http.StatusNotImplemented
-	})
-
-	resp, err := local.
-		SetRetryCount(10).
+	resp, err := c.http.
 		R().
 		SetQueryParams(lookup).
-		Get("http://cloud-api:8080/internal/firewall/rules/evaluate")
+		Get("/internal/firewall/rules/evaluate")
 	if err != nil {
 		return ErrFirewallConnection
 	}
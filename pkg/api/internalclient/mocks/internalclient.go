@@ -3,10 +3,13 @@
 package mocks
 
 import (
+	internalclient "github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	models "github.com/shellhub-io/shellhub/pkg/models"
 	mock "github.com/stretchr/testify/mock"
 
 	requests "github.com/shellhub-io/shellhub/pkg/api/requests"
+
+	time "time"
 )
 
 // Client is an autogenerated mock type for the Client type
@@ -71,6 +74,20 @@ func (_m *Client) BillingReport(tenant string, action string) (int, error) {
 	return r0, r1
 }
 
+// CheckDeviceExpiry provides a mock function with given fields: uid
+func (_m *Client) CheckDeviceExpiry(uid string) error {
+	ret := _m.Called(uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreatePrivateKey provides a mock function with given fields:
 func (_m *Client) CreatePrivateKey() (*models.PrivateKey, error) {
 	ret := _m.Called()
@@ -207,6 +224,22 @@ func (_m *Client) FirewallEvaluate(lookup map[string]string) error {
 	return r0
 }
 
+// ForTenant provides a mock function with given fields: tenantID
+func (_m *Client) ForTenant(tenantID string) internalclient.Client {
+	ret := _m.Called(tenantID)
+
+	var r0 internalclient.Client
+	if rf, ok := ret.Get(0).(func(string) internalclient.Client); ok {
+		r0 = rf(tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(internalclient.Client)
+		}
+	}
+
+	return r0
+}
+
 // GetDevice provides a mock function with given fields: uid
 func (_m *Client) GetDevice(uid string) (*models.Device, error) {
 	ret := _m.Called(uid)
@@ -327,6 +360,32 @@ func (_m *Client) ListDevices() ([]models.Device, error) {
 	return r0, r1
 }
 
+// ListLiveSessions provides a mock function with given fields: tenant
+func (_m *Client) ListLiveSessions(tenant string) ([]models.LiveSession, error) {
+	ret := _m.Called(tenant)
+
+	var r0 []models.LiveSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]models.LiveSession, error)); ok {
+		return rf(tenant)
+	}
+	if rf, ok := ret.Get(0).(func(string) []models.LiveSession); ok {
+		r0 = rf(tenant)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LiveSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(tenant)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Lookup provides a mock function with given fields: lookup
 func (_m *Client) Lookup(lookup map[string]string) (string, []error) {
 	ret := _m.Called(lookup)
@@ -381,6 +440,58 @@ func (_m *Client) NamespaceLookup(tenant string) (*models.Namespace, []error) {
 	return r0, r1
 }
 
+// NamespaceLookupByName provides a mock function with given fields: name
+func (_m *Client) NamespaceLookupByName(name string) (*models.Namespace, error) {
+	ret := _m.Called(name)
+
+	var r0 *models.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.Namespace, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.Namespace); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PingDevice provides a mock function with given fields: tenant, uid, timeout
+func (_m *Client) PingDevice(tenant string, uid string, timeout time.Duration) (*models.DevicePingResult, error) {
+	ret := _m.Called(tenant, uid, timeout)
+
+	var r0 *models.DevicePingResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) (*models.DevicePingResult, error)); ok {
+		return rf(tenant, uid, timeout)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) *models.DevicePingResult); ok {
+		r0 = rf(tenant, uid, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DevicePingResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, time.Duration) error); ok {
+		r1 = rf(tenant, uid, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RecordSession provides a mock function with given fields: session, recordURL
 func (_m *Client) RecordSession(session *models.SessionRecorded, recordURL string) error {
 	ret := _m.Called(session, recordURL)
@@ -395,6 +506,20 @@ func (_m *Client) RecordSession(session *models.SessionRecorded, recordURL strin
 	return r0
 }
 
+// RevokePublicKeySessions provides a mock function with given fields: tenant, fingerprint
+func (_m *Client) RevokePublicKeySessions(tenant string, fingerprint string) error {
+	ret := _m.Called(tenant, fingerprint)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(tenant, fingerprint)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SessionAsAuthenticated provides a mock function with given fields: uid
 func (_m *Client) SessionAsAuthenticated(uid string) []error {
 	ret := _m.Called(uid)
@@ -425,6 +550,20 @@ func (_m *Client) SessionCreate(session requests.SessionCreate) error {
 	return r0
 }
 
+// TransferSession provides a mock function with given fields: tenant, uid, member
+func (_m *Client) TransferSession(tenant string, uid string, member string) error {
+	ret := _m.Called(tenant, uid, member)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(tenant, uid, member)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateSession provides a mock function with given fields: uid, model
 func (_m *Client) UpdateSession(uid string, model *models.SessionUpdate) error {
 	ret := _m.Called(uid, model)
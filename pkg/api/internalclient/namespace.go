@@ -11,6 +11,9 @@ type namespaceAPI interface {
 	// NamespaceLookup retrieves namespace with the specified tenant.
 	// It returns the namespace and any encountered errors.
 	NamespaceLookup(tenant string) (*models.Namespace, []error)
+
+	// NamespaceLookupByName retrieves the namespace with the specified name.
+	NamespaceLookupByName(name string) (*models.Namespace, error)
 }
 
 func (c *client) NamespaceLookup(tenant string) (*models.Namespace, []error) {
@@ -19,7 +22,7 @@ func (c *client) NamespaceLookup(tenant string) (*models.Namespace, []error) {
 	res, err := c.http.
 		R().
 		SetResult(namespace).
-		Get("/api/namespaces/" + tenant)
+		Get(c.router.RouteForTenant(tenant) + "/api/namespaces/" + tenant)
 	if err != nil {
 		return nil, []error{err}
 	}
@@ -30,3 +33,25 @@ func (c *client) NamespaceLookup(tenant string) (*models.Namespace, []error) {
 
 	return namespace, nil
 }
+
+func (c *client) NamespaceLookupByName(name string) (*models.Namespace, error) {
+	namespace := new(models.Namespace)
+
+	res, err := c.http.
+		R().
+		SetQueryParam("name", name).
+		SetResult(namespace).
+		Get("/internal/namespaces/lookup")
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode() {
+	case http.StatusOK:
+		return namespace, nil
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, ErrUnknown
+	}
+}
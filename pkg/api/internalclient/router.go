@@ -0,0 +1,136 @@
+package internalclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultAPIURL is the URL of the API server when ShellHub isn't in multi-region mode.
+const DefaultAPIURL = "http://api:8080"
+
+// TenantRouter routes the requests of a tenant to the API server serving it.
+type TenantRouter interface {
+	// RouteForTenant returns the base URL of the API server serving the tenant with the specified ID.
+	RouteForTenant(tenantID string) string
+}
+
+// StaticRouter routes the requests of every tenant to the same API server.
+type StaticRouter struct {
+	url string
+}
+
+var _ TenantRouter = (*StaticRouter)(nil)
+
+// NewStaticRouter creates a [StaticRouter] routing to the API server at url.
+func NewStaticRouter(url string) *StaticRouter {
+	return &StaticRouter{url: strings.TrimSuffix(url, "/")}
+}
+
+func (r *StaticRouter) RouteForTenant(_ string) string {
+	return r.url
+}
+
+const (
+	// DynamicRouterTTL is how long a [DynamicRouter] keeps the API server of a tenant before looking it up again.
+	DynamicRouterTTL = 60 * time.Second
+	// DynamicRouterTimeout is the time limit of a lookup of a [DynamicRouter].
+	DynamicRouterTimeout = 2 * time.Second
+)
+
+// dynamicRoute is the API server of a tenant looked up by a [DynamicRouter].
+type dynamicRoute struct {
+	url       string
+	expiresAt time.Time
+}
+
+// DynamicRouter routes the requests of each tenant to the API server set on Redis at "tenant:{tenantID}:api_url",
+// falling back to a default one when it isn't set or can't be looked up. Lookups are kept for [DynamicRouterTTL].
+type DynamicRouter struct {
+	// lookup returns the value at key, or an empty string when it isn't set.
+	lookup   func(ctx context.Context, key string) (string, error)
+	fallback string
+
+	mu     sync.Mutex
+	routes map[string]dynamicRoute
+}
+
+var _ TenantRouter = (*DynamicRouter)(nil)
+
+// NewDynamicRouter creates a [DynamicRouter] looking the API servers up on the Redis at uri, and routing to fallback
+// the tenants without one.
+func NewDynamicRouter(uri, fallback string) (*DynamicRouter, error) {
+	opt, err := redis.ParseURL(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opt)
+
+	return newDynamicRouter(func(ctx context.Context, key string) (string, error) {
+		url, err := client.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			return "", nil
+		}
+
+		return url, err
+	}, fallback), nil
+}
+
+func newDynamicRouter(lookup func(ctx context.Context, key string) (string, error), fallback string) *DynamicRouter {
+	return &DynamicRouter{
+		lookup:   lookup,
+		fallback: strings.TrimSuffix(fallback, "/"),
+		routes:   make(map[string]dynamicRoute),
+	}
+}
+
+// dynamicRouterKey returns the key of the API server of the tenant on Redis.
+func dynamicRouterKey(tenantID string) string {
+	return "tenant:" + tenantID + ":api_url"
+}
+
+func (r *DynamicRouter) RouteForTenant(tenantID string) string {
+	now := clock.Now()
+
+	r.mu.Lock()
+	route, ok := r.routes[tenantID]
+	r.mu.Unlock()
+
+	if ok && now.Before(route.expiresAt) {
+		return route.url
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DynamicRouterTimeout)
+	defer cancel()
+
+	url, err := r.lookup(ctx, dynamicRouterKey(tenantID))
+	if err != nil {
+		log.WithError(err).WithField("tenant_id", tenantID).Warn("failed to look up the API server of the tenant")
+
+		// NOTICE: a failed lookup isn't kept, so the next request looks the API server up again.
+		if ok {
+			return route.url
+		}
+
+		return r.fallback
+	}
+
+	if url == "" {
+		url = r.fallback
+	}
+
+	url = strings.TrimSuffix(url, "/")
+
+	r.mu.Lock()
+	r.routes[tenantID] = dynamicRoute{url: url, expiresAt: now.Add(DynamicRouterTTL)}
+	r.mu.Unlock()
+
+	return url
+}
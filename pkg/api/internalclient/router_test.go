@@ -0,0 +1,176 @@
+package internalclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmocks "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticRouter(t *testing.T) {
+	router := NewStaticRouter("http://api:8080/")
+
+	assert.Equal(t, "http://api:8080", router.RouteForTenant("00000000-0000-4000-0000-000000000000"))
+	assert.Equal(t, "http://api:8080", router.RouteForTenant("00000000-0000-4000-0000-000000000001"))
+}
+
+func TestDynamicRouter(t *testing.T) {
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	backend := clock.DefaultBackend
+	t.Cleanup(func() {
+		clock.DefaultBackend = backend
+	})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// fakeRedis answers the lookups with values, counting them.
+	type fakeRedis struct {
+		values  map[string]string
+		err     error
+		lookups int
+	}
+
+	lookup := func(r *fakeRedis) func(context.Context, string) (string, error) {
+		return func(_ context.Context, key string) (string, error) {
+			r.lookups++
+
+			return r.values[key], r.err
+		}
+	}
+
+	t.Run("routes to the API server set for the tenant", func(t *testing.T) {
+		clockMock := new(clockmocks.Clock)
+		clock.DefaultBackend = clockMock
+		clockMock.On("Now").Return(now)
+
+		redis := &fakeRedis{values: map[string]string{"tenant:" + tenantID + ":api_url": "http://api.eu:8080/"}}
+		router := newDynamicRouter(lookup(redis), DefaultAPIURL)
+
+		assert.Equal(t, "http://api.eu:8080", router.RouteForTenant(tenantID))
+	})
+
+	t.Run("routes to the fallback when the tenant has no API server set", func(t *testing.T) {
+		clockMock := new(clockmocks.Clock)
+		clock.DefaultBackend = clockMock
+		clockMock.On("Now").Return(now)
+
+		redis := &fakeRedis{values: map[string]string{}}
+		router := newDynamicRouter(lookup(redis), DefaultAPIURL)
+
+		assert.Equal(t, DefaultAPIURL, router.RouteForTenant(tenantID))
+	})
+
+	t.Run("keeps the API server of the tenant until it expires", func(t *testing.T) {
+		clockMock := new(clockmocks.Clock)
+		clock.DefaultBackend = clockMock
+
+		redis := &fakeRedis{values: map[string]string{"tenant:" + tenantID + ":api_url": "http://api.eu:8080"}}
+		router := newDynamicRouter(lookup(redis), DefaultAPIURL)
+
+		clockMock.On("Now").Return(now).Once()
+		assert.Equal(t, "http://api.eu:8080", router.RouteForTenant(tenantID))
+
+		redis.values["tenant:"+tenantID+":api_url"] = "http://api.us:8080"
+
+		clockMock.On("Now").Return(now.Add(DynamicRouterTTL - time.Second)).Once()
+		assert.Equal(t, "http://api.eu:8080", router.RouteForTenant(tenantID))
+		assert.Equal(t, 1, redis.lookups)
+
+		clockMock.On("Now").Return(now.Add(DynamicRouterTTL)).Once()
+		assert.Equal(t, "http://api.us:8080", router.RouteForTenant(tenantID))
+		assert.Equal(t, 2, redis.lookups)
+	})
+
+	t.Run("routes to the fallback when the lookup fails", func(t *testing.T) {
+		clockMock := new(clockmocks.Clock)
+		clock.DefaultBackend = clockMock
+		clockMock.On("Now").Return(now)
+
+		redis := &fakeRedis{err: errors.New("error")}
+		router := newDynamicRouter(lookup(redis), DefaultAPIURL)
+
+		assert.Equal(t, DefaultAPIURL, router.RouteForTenant(tenantID))
+		assert.Equal(t, DefaultAPIURL, router.RouteForTenant(tenantID))
+		assert.Equal(t, 2, redis.lookups)
+	})
+
+	t.Run("keeps routing to the expired API server when the lookup fails", func(t *testing.T) {
+		clockMock := new(clockmocks.Clock)
+		clock.DefaultBackend = clockMock
+
+		redis := &fakeRedis{values: map[string]string{"tenant:" + tenantID + ":api_url": "http://api.eu:8080"}}
+		router := newDynamicRouter(lookup(redis), DefaultAPIURL)
+
+		clockMock.On("Now").Return(now).Once()
+		assert.Equal(t, "http://api.eu:8080", router.RouteForTenant(tenantID))
+
+		redis.err = errors.New("error")
+
+		clockMock.On("Now").Return(now.Add(DynamicRouterTTL)).Once()
+		assert.Equal(t, "http://api.eu:8080", router.RouteForTenant(tenantID))
+	})
+}
+
+func TestClientTenantRouter(t *testing.T) {
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/namespaces/" + tenantID:
+			_, _ = w.Write([]byte(`{"name":"routed","tenant_id":"` + tenantID + `"}`))
+		case "/internal/sshkeys/public-keys/fingerprint/" + tenantID:
+			_, _ = w.Write([]byte(`{"fingerprint":"fingerprint","tenant_id":"` + tenantID + `"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cli := NewClient(WithTenantRouter(NewStaticRouter(server.URL)))
+
+	namespace, errs := cli.NamespaceLookup(tenantID)
+	require.Empty(t, errs)
+	assert.Equal(t, &models.Namespace{Name: "routed", TenantID: tenantID}, namespace)
+
+	key, err := cli.GetPublicKey("fingerprint", tenantID)
+	require.NoError(t, err)
+	assert.Equal(t, "fingerprint", key.Fingerprint)
+}
+
+func TestClientForTenant(t *testing.T) {
+	const tenantID = "00000000-0000-4000-0000-000000000000"
+
+	offline := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/internal/devices/uid/offline" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		offline <- r.URL.Path
+	}))
+	t.Cleanup(server.Close)
+
+	cli := NewClient(WithTenantRouter(NewStaticRouter(server.URL)))
+
+	routed := cli.ForTenant(tenantID)
+	assert.Same(t, routed, cli.ForTenant(tenantID))
+
+	require.NoError(t, routed.DevicesOffline("uid"))
+	assert.Equal(t, "/internal/devices/uid/offline", <-offline)
+
+	def := NewClient()
+	assert.Same(t, def, def.ForTenant(tenantID))
+}
@@ -3,7 +3,10 @@ package internalclient
 import (
 	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
+	"github.com/go-resty/resty/v2"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	"github.com/shellhub-io/shellhub/pkg/models"
 )
@@ -31,8 +34,27 @@ type sessionAPI interface {
 
 	// UpdateSession updates some fields of [models.Session] using [models.SessionUpdate].
 	UpdateSession(uid string, model *models.SessionUpdate) error
+
+	// ListLiveSessions lists the sessions from a namespace currently held in the SSH server's memory.
+	ListLiveSessions(tenant string) ([]models.LiveSession, error)
+
+	// TransferSession asks the SSH server to transfer the session with the specified uid to another namespace member,
+	// waiting at most [models.SessionTransferTimeout] for the session's client to answer.
+	//
+	// It returns [ErrNotFound] when the session isn't handled by the SSH server, [ErrSessionTransferRejected] when the
+	// client rejects the transfer and [ErrSessionTransferTimeout] when it doesn't answer in time.
+	TransferSession(tenant, uid, member string) error
+
+	// RevokePublicKeySessions asks the SSH server to close the sessions of the namespace that were authenticated with
+	// the public key with the specified fingerprint.
+	RevokePublicKeySessions(tenant, fingerprint string) error
 }
 
+var (
+	ErrSessionTransferRejected = errors.New("the session's client rejected the transfer")
+	ErrSessionTransferTimeout  = errors.New("the session's client did not answer the transfer in time")
+)
+
 func (c *client) SessionCreate(session requests.SessionCreate) error {
 	_, err := c.http.
 		R().
@@ -111,3 +133,68 @@ func (c *client) UpdateSession(uid string, model *models.SessionUpdate) error {
 
 	return nil
 }
+
+func (c *client) ListLiveSessions(tenant string) ([]models.LiveSession, error) {
+	sessions := make([]models.LiveSession, 0)
+
+	resp, err := c.http.
+		R().
+		SetHeader("X-Tenant-ID", tenant).
+		SetResult(&sessions).
+		Get("http://ssh:8080/sessions/live")
+	if err != nil {
+		return nil, ErrConnectionFailed
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return nil, ErrUnknown
+	}
+
+	return sessions, nil
+}
+
+func (c *client) TransferSession(tenant, uid, member string) error {
+	// NOTICE: a dedicated client is used here because the default one retries indefinitely, what would send the
+	// transfer request to the session's client more than once.
+	httpClient := resty.New()
+	httpClient.SetTimeout(models.SessionTransferTimeout + 5*time.Second)
+
+	resp, err := httpClient.
+		R().
+		SetHeader("X-Tenant-ID", tenant).
+		SetBody(map[string]string{"member_id": member}).
+		Post(fmt.Sprintf("http://ssh:8080/sessions/%s/transfer", uid))
+	if err != nil {
+		return ErrConnectionFailed
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusForbidden:
+		return ErrSessionTransferRejected
+	case http.StatusRequestTimeout:
+		return ErrSessionTransferTimeout
+	default:
+		return ErrUnknown
+	}
+}
+
+func (c *client) RevokePublicKeySessions(tenant, fingerprint string) error {
+	resp, err := c.http.
+		R().
+		SetHeader("X-Tenant-ID", tenant).
+		SetBody(map[string]string{"fingerprint": fingerprint}).
+		Post("http://ssh:8080/sessions/revoke")
+	if err != nil {
+		return ErrConnectionFailed
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return ErrUnknown
+	}
+
+	return nil
+}
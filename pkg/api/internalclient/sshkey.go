@@ -24,7 +24,7 @@ func (c *client) GetPublicKey(fingerprint, tenant string) (*models.PublicKey, er
 	resp, err := c.http.
 		R().
 		SetResult(&pubKey).
-		Get(fmt.Sprintf("/internal/sshkeys/public-keys/%s/%s", fingerprint, tenant))
+		Get(fmt.Sprintf("%s/internal/sshkeys/public-keys/%s/%s", c.router.RouteForTenant(tenant), fingerprint, tenant))
 	if err != nil {
 		return nil, err
 	}
@@ -39,11 +39,16 @@ func (c *client) GetPublicKey(fingerprint, tenant string) (*models.PublicKey, er
 func (c *client) EvaluateKey(fingerprint string, dev *models.Device, username string) (bool, error) {
 	var evaluate *bool
 
+	var tenant string
+	if dev != nil {
+		tenant = dev.TenantID
+	}
+
 	resp, err := c.http.
 		R().
 		SetBody(dev).
 		SetResult(&evaluate).
-		Post(fmt.Sprintf("/internal/sshkeys/public-keys/evaluate/%s/%s", fingerprint, username))
+		Post(fmt.Sprintf("%s/internal/sshkeys/public-keys/evaluate/%s/%s", c.router.RouteForTenant(tenant), fingerprint, username))
 	if err != nil {
 		return false, err
 	}
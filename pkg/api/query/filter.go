@@ -75,6 +75,14 @@ func (f *Filter) UnmarshalJSON(data []byte) error {
 		}
 		f.Params = &operator
 
+		return nil
+	case FilterTypeOwnerID:
+		var owner FilterOwnerID
+		if err := json.Unmarshal(params, &owner); err != nil {
+			return err
+		}
+		f.Params = &owner
+
 		return nil
 	default:
 		return ErrFilterInvalid
@@ -52,6 +52,18 @@ func TestFilterUnmarshalJSON(t *testing.T) {
 			data:     "ewogICAgInR5cGUiOiAib3BlcmF0b3IiLAogICAgInBhcmFtcyI6IHsKICAgICAgICAibmFtZSI6ICJhbmQiCiAgICB9Cn0=",
 			expected: nil,
 		},
+		{
+			description: "",
+			filter: &Filter{
+				Type: "owner_id",
+				Params: FilterOwnerID{
+					ID: "507f1f77bcf86cd799439011",
+				},
+			},
+			// {"type":"owner_id","params":{"id":"507f1f77bcf86cd799439011"}}
+			data:     "eyJ0eXBlIjoib3duZXJfaWQiLCJwYXJhbXMiOnsiaWQiOiI1MDdmMWY3N2JjZjg2Y2Q3OTk0MzkwMTEifX0=",
+			expected: nil,
+		},
 	}
 
 	for _, tc := range cases {
@@ -3,6 +3,7 @@ package query
 const (
 	FilterTypeProperty = "property"
 	FilterTypeOperator = "operator"
+	FilterTypeOwnerID  = "owner_id"
 )
 
 // FilterProperty is a JSON representation of a property expression in a query.
@@ -33,3 +34,10 @@ type FilterOperator struct {
 	// Name is the filter operator (e.g., "and", "or").
 	Name string `json:"name"`
 }
+
+// FilterOwnerID represents a JSON representation of a filter by the owner of a document. Unlike the
+// properties, it isn't combined with the operators and is always applied to the query.
+type FilterOwnerID struct {
+	// ID is the ID of the user who owns the document.
+	ID string `json:"id"`
+}
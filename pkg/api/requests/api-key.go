@@ -12,6 +12,7 @@ type CreateAPIKey struct {
 	ExpiresAt int    `json:"expires_at" validate:"required,api-key_expires-at"`
 	Key       string `json:"key" validate:"omitempty,uuid"`
 	OptRole   string `json:"role" validate:"omitempty,namespace_role"`
+	Scopes    []int  `json:"scopes" validate:"omitempty,dive,min=1"`
 }
 
 type ListAPIKey struct {
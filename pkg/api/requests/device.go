@@ -1,5 +1,12 @@
 package requests
 
+import (
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
 // DeviceParam is a structure to represent and validate a device UID as path param.
 type DeviceParam struct {
 	UID string `param:"uid" validate:"required"`
@@ -15,6 +22,12 @@ type DeviceDelete struct {
 	DeviceParam
 }
 
+// DeviceCleanupConnector is the structure to represent the request data for the connector devices cleanup endpoint.
+type DeviceCleanupConnector struct {
+	// DryRun lists the devices that would be removed without removing them.
+	DryRun bool `query:"dry_run"`
+}
+
 // DeviceRename is the structure to represent the request data for rename device endpoint.
 type DeviceRename struct {
 	DeviceParam
@@ -40,6 +53,12 @@ type DeviceUpdateStatus struct {
 	Status string `param:"status" validate:"required,oneof=accept reject pending unused"`
 }
 
+// DeviceBulkUpdateStatus is the structure to represent the request data for accept and reject devices in bulk
+// endpoints.
+type DeviceBulkUpdateStatus struct {
+	UIDs []string `json:"uids" validate:"required,min=1,max=100,unique"`
+}
+
 // DeviceCreateTag is the structure to represent the request data for device create tag endpoint.
 type DeviceCreateTag struct {
 	DeviceParam
@@ -78,6 +97,8 @@ type DeviceAuth struct {
 	Identity  *DeviceIdentity `json:"identity,omitempty" validate:"required_without=Hostname,omitempty"`
 	PublicKey string          `json:"public_key" validate:"required"`
 	TenantID  string          `json:"tenant_id" validate:"required"`
+	// EnrollmentKey is the key of the namespace required to enroll a new device, when the namespace has one.
+	EnrollmentKey string `json:"enrollment_key,omitempty" hash:"-"`
 }
 
 type DeviceGetPublicURL struct {
@@ -94,3 +115,61 @@ type DeviceUpdate struct {
 type DevicePublicURLAddress struct {
 	PublicURLAddress string `param:"address" validate:"required"`
 }
+
+// DeviceTrustHostKey is the structure to represent the request data for device trust host key endpoint.
+type DeviceTrustHostKey struct {
+	DeviceParam
+	// PublicKey is the PEM encoded public key the device must present.
+	PublicKey string `json:"public_key" validate:"required"`
+}
+
+// DeviceSessionPolicySet is the structure to represent the request data for set device session policy endpoint.
+type DeviceSessionPolicySet struct {
+	DeviceParam
+	models.DeviceSessionPolicy
+}
+
+// DeviceSessionPolicyDelete is the structure to represent the request data for delete device session policy endpoint.
+type DeviceSessionPolicyDelete struct {
+	DeviceParam
+}
+
+// DeviceAllowedCommandsUpdate is the structure to represent the request data for update device allowed commands
+// endpoint.
+type DeviceAllowedCommandsUpdate struct {
+	DeviceParam
+	AllowedCommands []string `json:"allowed_commands" validate:"max=100,unique,dive,required,max=4096"`
+}
+
+// DeviceExpiryUpdate is the structure to represent the request data for update device expiry endpoint.
+type DeviceExpiryUpdate struct {
+	DeviceParam
+	// ExpiresAt is when the device's access to the namespace ends. When null, the access doesn't end.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// DeviceExpiryCheck is the structure to represent the request data for check device expiry endpoint.
+type DeviceExpiryCheck struct {
+	DeviceParam
+}
+
+// DeviceEventsList is the structure to represent the request data for list device events endpoint.
+type DeviceEventsList struct {
+	DeviceParam
+	query.Paginator
+	query.Sorter
+	query.Filters
+}
+
+// DeviceConnectionsList is the structure to represent the request data for list device connections endpoint.
+type DeviceConnectionsList struct {
+	DeviceParam
+	query.Paginator
+}
+
+// DevicePing is the structure to represent the request data for device ping endpoint.
+type DevicePing struct {
+	DeviceParam
+	// TimeoutMS is the maximum time, in milliseconds, to wait for the device to answer.
+	TimeoutMS int `json:"timeout_ms" validate:"omitempty,min=1,max=30000"`
+}
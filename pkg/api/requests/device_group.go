@@ -0,0 +1,41 @@
+package requests
+
+import "github.com/shellhub-io/shellhub/pkg/api/query"
+
+// DeviceGroupParam is a structure to represent and validate a device group UID as path param.
+type DeviceGroupParam struct {
+	UID string `param:"uid" validate:"required"`
+}
+
+// DeviceGroupCreate is the structure to represent the request data for create device group endpoint.
+type DeviceGroupCreate struct {
+	Name string `json:"name" validate:"required,min=3,max=64"`
+	// Devices are the UIDs of the group's members.
+	Devices []string `json:"devices" validate:"unique"`
+}
+
+// DeviceGroupGet is the structure to represent the request data for get device group endpoint.
+type DeviceGroupGet struct {
+	DeviceGroupParam
+}
+
+// DeviceGroupUpdate is the structure to represent the request data for update device group endpoint. Attributes not
+// informed are kept unchanged, while an empty list of devices removes all members.
+type DeviceGroupUpdate struct {
+	DeviceGroupParam
+	Name    *string  `json:"name" validate:"omitempty,min=3,max=64"`
+	Devices []string `json:"devices" validate:"unique"`
+}
+
+// DeviceGroupDevice is the structure to represent the request data for the endpoints that add or remove a device from
+// a device group.
+type DeviceGroupDevice struct {
+	DeviceGroupParam
+	Device string `param:"device" validate:"required"`
+}
+
+// DeviceGroupDevicesList is the structure to represent the request data for list device group devices endpoint.
+type DeviceGroupDevicesList struct {
+	DeviceGroupParam
+	query.Paginator
+}
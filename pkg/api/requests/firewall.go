@@ -0,0 +1,40 @@
+package requests
+
+import "github.com/shellhub-io/shellhub/pkg/models"
+
+// FirewallRuleCreate is the structure to represent the request data for create a firewall rule.
+type FirewallRuleCreate struct {
+	models.FirewallRuleFields
+}
+
+// FirewallRuleBulkCreate is the structure to represent the request data for the bulk import of firewall rules endpoint.
+type FirewallRuleBulkCreate struct {
+	TenantParam
+	// Mode defines what to do when an incoming rule conflicts with an existing one.
+	Mode  string               `json:"mode" validate:"required,oneof=skip overwrite fail_on_conflict"`
+	Rules []FirewallRuleCreate `json:"rules" validate:"required,min=1"`
+}
+
+// FirewallEvaluate is the structure to represent a connection evaluated against the firewall rules. It carries the same
+// lookup sent by the SSH server when a connection is started.
+type FirewallEvaluate struct {
+	// Domain is the namespace's name.
+	Domain string `query:"domain" validate:"required"`
+	// Name is the device's name.
+	Name      string `query:"name"`
+	Username  string `query:"username"`
+	IPAddress string `query:"ip_address"`
+}
+
+// FirewallConflictList is the structure to represent the request data for the firewall conflicts endpoint.
+type FirewallConflictList struct {
+	TenantParam
+}
+
+// FirewallRulePreview is the structure to represent the request data for the firewall rule preview endpoint.
+type FirewallRulePreview struct {
+	TenantParam
+	models.FirewallRuleFields
+	// Connection is the connection the rule is evaluated against.
+	Connection models.FirewallPreviewConnection `json:"connection"`
+}
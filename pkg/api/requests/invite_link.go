@@ -0,0 +1,36 @@
+package requests
+
+// InviteLinkParam is a structure to represent and validate an invite link token as path param.
+type InviteLinkParam struct {
+	Token string `param:"token" validate:"required"`
+}
+
+// InviteLinkCreate is the structure to represent the request data for create invite link endpoint.
+type InviteLinkCreate struct {
+	TenantParam
+	RoleBody
+	MaxUses int `json:"max_uses" validate:"required,min=1"`
+	// ExpiresIn is how many seconds the link is valid for after its creation.
+	ExpiresIn int `json:"expires_in" validate:"required,min=1"`
+}
+
+// InviteLinkList is the structure to represent the request data for list invite links endpoint.
+type InviteLinkList struct {
+	TenantParam
+}
+
+// InviteLinkDelete is the structure to represent the request data for delete invite link endpoint.
+type InviteLinkDelete struct {
+	TenantParam
+	InviteLinkParam
+}
+
+// InviteLinkGet is the structure to represent the request data for the public get invite link endpoint.
+type InviteLinkGet struct {
+	InviteLinkParam
+}
+
+// InviteLinkJoin is the structure to represent the request data for join namespace through an invite link endpoint.
+type InviteLinkJoin struct {
+	InviteLinkParam
+}
@@ -1,5 +1,10 @@
 package requests
 
+import (
+	"github.com/shellhub-io/shellhub/pkg/api/query"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
 // TenantParam is a structure to represent and validate a namespace tenant as path param.
 type TenantParam struct {
 	Tenant string `param:"tenant" validate:"required,uuid"`
@@ -26,9 +31,24 @@ type NamespaceGet struct {
 	TenantParam
 }
 
+// NamespaceLookup is the structure to represent the request data for lookup namespace endpoint.
+type NamespaceLookup struct {
+	Name string `query:"name" validate:"required"`
+}
+
 // NamespaceDelete is the structure to represent the request data for delete namespace endpoint.
 type NamespaceDelete struct {
 	TenantParam
+	// ConfirmationToken is the token issued by the namespace deletion request endpoint. It is only optional when
+	// SHELLHUB_NAMESPACE_DELETION_WITHOUT_TOKEN is enabled, so the clients not requesting it keep working.
+	ConfirmationToken string `query:"confirmation_token"`
+	// ConfirmationText, when set, must be the namespace's name.
+	ConfirmationText string `json:"confirmation_text"`
+}
+
+// NamespaceDeletionRequest is the structure to represent the request data for request namespace deletion endpoint.
+type NamespaceDeletionRequest struct {
+	TenantParam
 }
 
 // NamespaceEdit is the structure to represent the request data for edit namespace endpoint.
@@ -36,11 +56,52 @@ type NamespaceEdit struct {
 	TenantParam
 	Name     string `json:"name" validate:"omitempty,hostname_rfc1123,excludes=."`
 	Settings struct {
-		SessionRecord          *bool   `json:"session_record" validate:"omitempty"`
-		ConnectionAnnouncement *string `json:"connection_announcement" validate:"omitempty,min=0,max=4096"`
+		SessionRecord              *bool                  `json:"session_record" validate:"omitempty"`
+		ConnectionAnnouncement     *string                `json:"connection_announcement" validate:"omitempty,min=0,max=4096"`
+		ExecAnnouncement           *bool                  `json:"exec_announcement" validate:"omitempty"`
+		DefaultFirewallPolicy      *string                `json:"default_firewall_policy" validate:"omitempty,oneof=allow deny"`
+		TransferSessionEnabled     *bool                  `json:"transfer_session_enabled" validate:"omitempty"`
+		AccessSchedule             *models.AccessSchedule `json:"access_schedule" validate:"omitempty"`
+		MinRSABits                 *int                   `json:"min_rsa_bits" validate:"omitempty,min=1024,max=16384"`
+		AllowDSA                   *bool                  `json:"allow_dsa" validate:"omitempty"`
+		AllowRSA1024               *bool                  `json:"allow_rsa1024" validate:"omitempty"`
+		LiveMonitoringEnabled      *bool                  `json:"live_monitoring_enabled" validate:"omitempty"`
+		MaxLiveMonitors            *int                   `json:"max_live_monitors" validate:"omitempty,min=1,max=100"`
+		RecordingIdlePauseMS       *int                   `json:"recording_idle_pause_ms" validate:"omitempty,min=0,max=3600000"`
+		UsageReportSchedule        *string                `json:"usage_report_schedule" validate:"omitempty,max=255"`
+		UsageReportEmail           *string                `json:"usage_report_email" validate:"omitempty,max=255"`
+		MaxSessionDurationMinutes  *int                   `json:"max_session_duration_minutes" validate:"omitempty,min=0,max=43200"`
+		MaxSessionKeepaliveEnabled *bool                  `json:"max_session_keepalive_enabled" validate:"omitempty"`
 	} `json:"settings"`
 }
 
+// NamespaceSettingsGet is the structure to represent the request data for get namespace settings endpoint.
+type NamespaceSettingsGet struct {
+	TenantParam
+}
+
+// NamespaceSettingsUpdate is the structure to represent the request data for update namespace settings endpoint. Only
+// the settings set are updated.
+type NamespaceSettingsUpdate struct {
+	TenantParam
+	SessionRecord              *bool                  `json:"session_record" validate:"omitempty"`
+	ConnectionAnnouncement     *string                `json:"connection_announcement" validate:"omitempty,min=0,max=4096"`
+	ExecAnnouncement           *bool                  `json:"exec_announcement" validate:"omitempty"`
+	DefaultFirewallPolicy      *string                `json:"default_firewall_policy" validate:"omitempty,oneof=allow deny"`
+	TransferSessionEnabled     *bool                  `json:"transfer_session_enabled" validate:"omitempty"`
+	AccessSchedule             *models.AccessSchedule `json:"access_schedule" validate:"omitempty"`
+	MinRSABits                 *int                   `json:"min_rsa_bits" validate:"omitempty,min=1024,max=16384"`
+	AllowDSA                   *bool                  `json:"allow_dsa" validate:"omitempty"`
+	AllowRSA1024               *bool                  `json:"allow_rsa1024" validate:"omitempty"`
+	LiveMonitoringEnabled      *bool                  `json:"live_monitoring_enabled" validate:"omitempty"`
+	MaxLiveMonitors            *int                   `json:"max_live_monitors" validate:"omitempty,min=1,max=100"`
+	RecordingIdlePauseMS       *int                   `json:"recording_idle_pause_ms" validate:"omitempty,min=0,max=3600000"`
+	UsageReportSchedule        *string                `json:"usage_report_schedule" validate:"omitempty,max=255"`
+	UsageReportEmail           *string                `json:"usage_report_email" validate:"omitempty,max=255"`
+	MaxSessionDurationMinutes  *int                   `json:"max_session_duration_minutes" validate:"omitempty,min=0,max=43200"`
+	MaxSessionKeepaliveEnabled *bool                  `json:"max_session_keepalive_enabled" validate:"omitempty"`
+}
+
 // NamespaceAddUser is the structure to represent the request data for add member to namespace endpoint.
 type NamespaceAddUser struct {
 	TenantParam
@@ -48,6 +109,74 @@ type NamespaceAddUser struct {
 	RoleBody
 }
 
+// NamespaceMembersList is the structure to represent the request data for list namespace members endpoint.
+type NamespaceMembersList struct {
+	TenantParam
+	// Tag restricts the list to the members with it, when not empty.
+	Tag string `query:"tag" validate:"omitempty,max=255"`
+}
+
+// NamespaceMemberTagsList is the structure to represent the request data for list namespace member tags endpoint.
+type NamespaceMemberTagsList struct {
+	TenantParam
+}
+
+// NamespaceMemberTagsSet is the structure to represent the request data for set namespace member tags endpoint.
+type NamespaceMemberTagsSet struct {
+	TenantParam
+	MemberParam
+	Tags []string `json:"tags" validate:"max=10,unique,dive,min=3,max=255,printascii,excludesall=/@&"`
+}
+
+// NamespaceMemberAccessScheduleSet is the structure to represent the request data for set namespace member access
+// schedule endpoint.
+type NamespaceMemberAccessScheduleSet struct {
+	TenantParam
+	MemberParam
+	models.AccessSchedule
+}
+
+// NamespaceMemberAccessScheduleDelete is the structure to represent the request data for delete namespace member access
+// schedule endpoint.
+type NamespaceMemberAccessScheduleDelete struct {
+	TenantParam
+	MemberParam
+}
+
+// NamespaceAPIRateLimitSet is the structure to represent the request data for set namespace API rate limit endpoint.
+type NamespaceAPIRateLimitSet struct {
+	TenantParam
+	models.APIRateLimitConfig
+}
+
+// NamespaceDefaultEnvVarsSet is the structure to represent the request data for set namespace default environment
+// variables endpoint.
+type NamespaceDefaultEnvVarsSet struct {
+	TenantParam
+	DefaultEnvVars map[string]string `json:"default_env_vars" validate:"max=32"`
+}
+
+// NamespaceWebhookSet is the structure to represent the request data for set namespace webhook endpoint. When Secret
+// is empty, a new one is generated.
+type NamespaceWebhookSet struct {
+	TenantParam
+	URL    string                    `json:"url" validate:"required,url"`
+	Secret string                    `json:"secret" validate:"omitempty,min=16,max=256"`
+	Events []models.WebhookEventType `json:"events" validate:"required,min=1,unique"`
+}
+
+// NamespaceWebhookDeadLettersList is the structure to represent the request data for list namespace webhook dead
+// letters endpoint.
+type NamespaceWebhookDeadLettersList struct {
+	TenantParam
+	query.Paginator
+}
+
+// NamespaceMembersExport is the structure to represent the request data for export namespace members endpoint.
+type NamespaceMembersExport struct {
+	TenantParam
+}
+
 // NamespaceRemoveUser is the structure to represent the request data for remove member from namespace endpoint.
 type NamespaceRemoveUser struct {
 	TenantParam
@@ -61,6 +190,13 @@ type NamespaceEditUser struct {
 	RoleBody
 }
 
+// SessionBulkEditRecordStatus is the structure to represent the request data for the bulk edit of session record
+// status endpoint.
+type SessionBulkEditRecordStatus struct {
+	Tenants       []string `json:"tenants" validate:"required,min=1,max=100,unique,dive,required"`
+	SessionRecord bool     `json:"session_record"`
+}
+
 // SessionEditRecordStatus is the structure to represent the request data for edit session record status endpoint.
 type SessionEditRecordStatus struct {
 	TenantParam
@@ -28,6 +28,29 @@ type PublicKeyCreate struct {
 	Username    string          `json:"username" validate:"required,regexp"`
 	TenantID    string          `json:"-"`
 	Fingerprint string          `json:"-"`
+	// CreatedBy is the ID of the user creating the public key.
+	CreatedBy string `json:"-"`
+	// AllowedIPs are the IP addresses and networks, in CIDR notation, the public key can be used from.
+	AllowedIPs []string `json:"allowed_ips" validate:"omitempty,max=32"`
+	// Source is where the public key was imported from, like "github". It's empty for the keys added directly.
+	Source string `json:"-"`
+}
+
+// PublicKeyImport is the structure to represent the request data for import public keys endpoint.
+type PublicKeyImport struct {
+	TenantParam
+	// Source is where the public keys are imported from, like "github".
+	Source string `json:"-" query:"source" validate:"required,oneof=github gitlab"`
+	// Account is the username of the user account on the source.
+	Account string `json:"-" query:"username" validate:"required,max=255"`
+	// Username is the username the imported public keys can log in as.
+	Username string `json:"username" validate:"required,regexp"`
+	// Filter is the filter of the devices the imported public keys can access.
+	Filter PublicKeyFilter `json:"filter" validate:"required"`
+	// AllowedIPs are the IP addresses and networks, in CIDR notation, the imported public keys can be used from.
+	AllowedIPs []string `json:"allowed_ips" validate:"omitempty,max=32"`
+	// CreatedBy is the ID of the user importing the public keys.
+	CreatedBy string `json:"-"`
 }
 
 // PublicKeyUpdate is the structure to represent the request data for update public key endpoint.
@@ -39,6 +62,8 @@ type PublicKeyUpdate struct {
 	Username string `json:"username" validate:"required,regexp"`
 	// Filter is the public key's filter.
 	Filter PublicKeyFilter `json:"filter" validate:"required"`
+	// AllowedIPs are the IP addresses and networks, in CIDR notation, the public key can be used from.
+	AllowedIPs []string `json:"allowed_ips" validate:"omitempty,max=32"`
 }
 
 // PublicKeyDelete is the structure to represent the request data for delete public key endpoint.
@@ -11,6 +11,44 @@ type SessionGet struct {
 	SessionIDParam
 }
 
+// SessionExport is the structure to represent the request data for export session endpoint.
+type SessionExport struct {
+	SessionIDParam
+}
+
+// SessionTransfer is the structure to represent the request data for transfer session endpoint.
+type SessionTransfer struct {
+	SessionIDParam
+	// MemberID is the ID of the namespace member who receives the session.
+	MemberID string `json:"member_id" validate:"required"`
+}
+
+// SessionWatch is the structure to represent the request data for watch session endpoint.
+type SessionWatch struct {
+	SessionIDParam
+}
+
+// SessionPlay is the structure to represent the request data for play session endpoint.
+type SessionPlay struct {
+	SessionIDParam
+	// Speed is the playback speed. When zero, the recording is played at its original speed.
+	Speed float64 `query:"speed" validate:"omitempty,min=0.1,max=10"`
+	// MaxFrameGapMS caps, in milliseconds, the delay between two frames. When zero, delays are not capped.
+	MaxFrameGapMS int `query:"max_gap" validate:"omitempty,min=0"`
+	// Timezone is the IANA name of the timezone used to format the start of the recording. When empty, UTC is used.
+	Timezone string `query:"tz" validate:"omitempty,timezone"`
+}
+
+// SessionTopViewed is the structure to represent the request data for the most viewed sessions endpoint.
+type SessionTopViewed struct {
+	// Limit is the maximum number of sessions listed. When zero, [SessionTopViewedDefaultLimit] sessions are listed.
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// SessionTopViewedDefaultLimit is the number of sessions listed by the most viewed sessions endpoint when no limit is
+// requested.
+const SessionTopViewedDefaultLimit = 10
+
 // SessionAuthenticatedSet is the structure to represent the request data for set authenticated session endpoint.
 type SessionAuthenticatedSet struct {
 	SessionIDParam
@@ -39,6 +77,9 @@ type SessionKeepAlive struct {
 
 type SessionUpdate struct {
 	SessionIDParam
-	Authenticated *bool   `json:"authenticated"`
-	Type          *string `json:"type"`
+	Authenticated           *bool   `json:"authenticated"`
+	Type                    *string `json:"type"`
+	RecordingPausedDuration *int64  `json:"recording_paused_duration" validate:"omitempty,min=0"`
+	ClientFingerprint       *string `json:"client_fingerprint" validate:"omitempty,max=255"`
+	MaxDurationExceeded     *bool   `json:"max_duration_exceeded"`
 }
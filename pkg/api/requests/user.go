@@ -7,12 +7,21 @@ type UserParam struct {
 	ID string `param:"id" validate:"required"`
 }
 
+// UserDelete is the structure to represent the request data for the delete user endpoint.
+type UserDelete struct {
+	UserParam
+	// GDPR erases the user's data instead of soft-deleting its account.
+	GDPR bool `query:"gdpr"`
+}
+
 // UserDataUpdate is the structure to represent the request body of the update user data endpoint.
 type UserDataUpdate struct {
 	Name          string `json:"name" validate:"omitempty,name"`
 	Username      string `json:"username" validate:"omitempty,username"`
 	Email         string `json:"email" validate:"omitempty,email"`
 	RecoveryEmail string `json:"recovery_email" validate:"omitempty,email"`
+	// Timezone is the IANA name of the user's timezone. When nil, it's kept as is, and when empty, it's cleared.
+	Timezone *string `json:"timezone" validate:"omitempty,eq=|timezone"`
 }
 
 // UserPasswordUpdate is the structure to represent the request body for the update user password endpoint.
@@ -22,6 +31,13 @@ type UserPasswordUpdate struct {
 	NewPassword     string `json:"new_password" validate:"required,password,nefield=CurrentPassword"`
 }
 
+// UserPlanAssign is the structure to represent the request data for the assign user plan endpoint.
+type UserPlanAssign struct {
+	UserParam
+	// Plan is the name of the plan assigned to the user.
+	Plan string `json:"plan" validate:"required"`
+}
+
 // UserAuth is the structure to represent the request body for the user auth endpoint.
 type UserAuth struct {
 	// Identifier represents an username or email.
@@ -29,4 +45,11 @@ type UserAuth struct {
 	// TODO: change json tag from username to identifier and update the OpenAPI.
 	Identifier models.UserAuthIdentifier `json:"username" validate:"required"`
 	Password   string                    `json:"password" validate:"required"`
+	// UserAgent is the user agent of the client logging in, recorded on the user session.
+	UserAgent string `json:"-"`
+}
+
+// UserSessionRevoke is the structure to represent the request data for revoke user session endpoint.
+type UserSessionRevoke struct {
+	ID string `param:"id" validate:"required"`
 }
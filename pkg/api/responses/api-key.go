@@ -15,6 +15,7 @@ type CreateAPIKey struct {
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
 	ExpiresIn int64     `json:"expires_in" bson:"expires_in"`
+	Scopes    []int     `json:"scopes,omitempty" bson:"scopes,omitempty"`
 }
 
 func CreateAPIKeyFromModel(m *models.APIKey) *CreateAPIKey {
@@ -27,5 +28,6 @@ func CreateAPIKeyFromModel(m *models.APIKey) *CreateAPIKey {
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
 		ExpiresIn: m.ExpiresIn,
+		Scopes:    m.Scopes,
 	}
 }
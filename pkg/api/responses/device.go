@@ -0,0 +1,25 @@
+package responses
+
+// DeviceBulkResult is the result of an action on a device of a batch.
+type DeviceBulkResult struct {
+	// UID is the device's UID.
+	UID string `json:"uid"`
+	// Error is the code of why the action failed on the device, one of the DeviceBulkError constants. It's empty when
+	// the action succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+const (
+	// DeviceBulkErrorNotFound is set when the device doesn't exist on the namespace.
+	DeviceBulkErrorNotFound = "not_found"
+	// DeviceBulkErrorInvalid is set when the device can't be changed to the status, like when it's already accepted.
+	DeviceBulkErrorInvalid = "invalid"
+	// DeviceBulkErrorDuplicated is set when an accepted device already has the device's name.
+	DeviceBulkErrorDuplicated = "duplicated"
+	// DeviceBulkErrorLimit is set when the namespace reached its maximum number of devices.
+	DeviceBulkErrorLimit = "limit_reached"
+	// DeviceBulkErrorPayment is set when accepting the device requires a payment.
+	DeviceBulkErrorPayment = "payment_required"
+	// DeviceBulkErrorInternal is set when the action failed for any other reason.
+	DeviceBulkErrorInternal = "internal"
+)
@@ -0,0 +1,24 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+// InviteLink is the public view of an invite link, shown to whoever holds its token before joining the namespace.
+type InviteLink struct {
+	TenantID  string    `json:"tenant_id"`
+	Namespace string    `json:"namespace"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func InviteLinkFromModel(link *models.InviteLink, namespace *models.Namespace) *InviteLink {
+	return &InviteLink{
+		TenantID:  link.TenantID,
+		Namespace: namespace.Name,
+		Role:      link.Role,
+		ExpiresAt: link.ExpiresAt,
+	}
+}
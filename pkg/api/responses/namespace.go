@@ -0,0 +1,29 @@
+package responses
+
+import (
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+// NamespaceDeletionRequest is the token that confirms the deletion of a namespace, valid until ExpiresAt.
+type NamespaceDeletionRequest struct {
+	ConfirmationToken string    `json:"confirmation_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// NamespaceEnrollmentKey is the new key to enroll devices in a namespace. The replaced key, if any, is still accepted
+// until PreviousExpiresAt.
+type NamespaceEnrollmentKey struct {
+	EnrollmentKey     string     `json:"enrollment_key"`
+	PreviousExpiresAt *time.Time `json:"previous_expires_at,omitempty"`
+}
+
+// NamespaceWebhook is the webhook set on a namespace, along with the secret its events are signed with. The secret is
+// only shown when the webhook is set.
+type NamespaceWebhook struct {
+	URL       string                    `json:"url"`
+	Secret    string                    `json:"secret"`
+	Events    []models.WebhookEventType `json:"events"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+}
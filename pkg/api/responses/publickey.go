@@ -17,4 +17,11 @@ type PublicKeyCreate struct {
 	Username    string          `json:"username"`
 	TenantID    string          `json:"tenant_id"`
 	Fingerprint string          `json:"fingerprint"`
+	AllowedIPs  []string        `json:"allowed_ips,omitempty"`
+}
+
+// PublicKeyImport is the structure to represent the response data for import public keys endpoint.
+type PublicKeyImport struct {
+	// Imported is the number of public keys imported.
+	Imported int `json:"imported"`
 }
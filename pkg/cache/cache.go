@@ -9,6 +9,8 @@ type Cache interface {
 	Get(ctx context.Context, key string, value interface{}) error
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
+	// DeletePrefix deletes every cached value whose key starts with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
 
 	// HasAccountLockout reports whether the source is currently blocked from attempting to
 	// log in to a user with the specified userID. It returns the absolute Unix timestamp
@@ -54,4 +56,21 @@ type Cache interface {
 	// ResetLoginAttempts resets the login attempts and associated lockout from the source to
 	// the user with the specified userID.
 	ResetLoginAttempts(ctx context.Context, source, userID string) error
+
+	// Publish publishes the message on the channel. Only its current subscribers receive it.
+	Publish(ctx context.Context, channel string, message []byte) error
+
+	// Subscribe subscribes to the channel, delivering its messages on the returned one until ctx is done, when it's
+	// closed.
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+
+	// Subscribers returns the number of subscribers of the channel.
+	Subscribers(ctx context.Context, channel string) (int, error)
+
+	// TakeToken takes a token from the token bucket with the specified key, which holds up to burst tokens and is
+	// refilled with rate tokens per minute. The bucket starts full and is shared by every caller using the same key.
+	//
+	// It reports whether a token was taken, the number of tokens remaining, when the bucket will be full again and an
+	// error if any.
+	TakeToken(ctx context.Context, key string, rate, burst int) (taken bool, remaining int, reset time.Time, err error)
 }
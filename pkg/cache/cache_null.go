@@ -3,6 +3,8 @@ package cache
 import (
 	"context"
 	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/clock"
 )
 
 type nullCache struct{}
@@ -25,6 +27,10 @@ func (*nullCache) Delete(_ context.Context, _ string) error {
 	return nil
 }
 
+func (*nullCache) DeletePrefix(_ context.Context, _ string) error {
+	return nil
+}
+
 func (*nullCache) HasAccountLockout(_ context.Context, _, _ string) (int64, int, error) {
 	return 0, 0, nil
 }
@@ -36,3 +42,26 @@ func (*nullCache) StoreLoginAttempt(_ context.Context, _, _ string) (int64, int,
 func (*nullCache) ResetLoginAttempts(_ context.Context, _, _ string) error {
 	return nil
 }
+
+func (*nullCache) Publish(_ context.Context, _ string, _ []byte) error {
+	return nil
+}
+
+func (*nullCache) Subscribe(ctx context.Context, _ string) (<-chan []byte, error) {
+	messages := make(chan []byte)
+
+	go func() {
+		<-ctx.Done()
+		close(messages)
+	}()
+
+	return messages, nil
+}
+
+func (*nullCache) Subscribers(_ context.Context, _ string) (int, error) {
+	return 0, nil
+}
+
+func (*nullCache) TakeToken(_ context.Context, _ string, _, burst int) (bool, int, time.Time, error) {
+	return true, burst, clock.Now(), nil
+}
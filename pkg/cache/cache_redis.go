@@ -4,6 +4,7 @@ import (
 	"context"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	rediscache "github.com/go-redis/cache/v8"
@@ -13,9 +14,44 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// takeTokenScript refills the token bucket for the time elapsed since it was last used and takes a token from it, if
+// there is one, atomically. The bucket expires once it would be full again, as a full bucket is the same as none.
+var takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "timestamp")
+local tokens = tonumber(bucket[1])
+local timestamp = tonumber(bucket[2])
+if tokens == nil or timestamp == nil then
+	tokens = burst
+	timestamp = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - timestamp) * rate / 60000)
+
+local taken = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	taken = 1
+end
+
+local full = math.ceil((burst - tokens) * 60000 / rate)
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "timestamp", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.max(full, 1))
+
+return {taken, math.floor(tokens), now + full}
+`)
+
+// globEscaper escapes the special characters of the patterns matched by Redis' SCAN.
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
 type redisCache struct {
-	cache *rediscache.Cache
-	cfg   *config
+	client *redis.Client
+	cache  *rediscache.Cache
+	cfg    *config
 }
 
 var _ Cache = &redisCache{}
@@ -35,10 +71,13 @@ func NewRedisCache(uri string, pool int) (Cache, error) {
 		log.WithError(err).Fatal("Failed to load environment variables")
 	}
 
+	client := redis.NewClient(opt)
+
 	return &redisCache{
-		cfg: cfg,
+		client: client,
+		cfg:    cfg,
 		cache: rediscache.New(&rediscache.Options{
-			Redis: redis.NewClient(opt),
+			Redis: client,
 		}),
 	}, nil
 }
@@ -68,6 +107,33 @@ func (c *redisCache) Delete(ctx context.Context, key string) error {
 	return c.cache.Delete(ctx, key)
 }
 
+// DeletePrefix deletes the cached values whose key starts with prefix. The keys are scanned in batches, so Redis isn't
+// blocked while they're looked up.
+func (c *redisCache) DeletePrefix(ctx context.Context, prefix string) error {
+	// NOTICE: the prefix is escaped, as the glob's special characters are valid on keys.
+	pattern := globEscaper.Replace(prefix) + "*"
+
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := c.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+
+		cursor = next
+	}
+}
+
 func (c *redisCache) HasAccountLockout(ctx context.Context, source, id string) (int64, int, error) {
 	if c.cfg.MaximumAccountLockout <= 0 {
 		return 0, 0, nil
@@ -137,3 +203,65 @@ func (c *redisCache) ResetLoginAttempts(ctx context.Context, source, id string)
 
 	return c.Delete(ctx, "account-lockout="+source+":"+id)
 }
+
+func (c *redisCache) Publish(ctx context.Context, channel string, message []byte) error {
+	return c.client.Publish(ctx, channel, message).Err()
+}
+
+func (c *redisCache) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	pubsub := c.client.Subscribe(ctx, channel)
+	// NOTICE: the subscription is confirmed before returning, so the messages published after it are not lost.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+
+		return nil, err
+	}
+
+	messages := make(chan []byte)
+
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				select {
+				case messages <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages, nil
+}
+
+func (c *redisCache) Subscribers(ctx context.Context, channel string) (int, error) {
+	counts, err := c.client.PubSubNumSub(ctx, channel).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(counts[channel]), nil
+}
+
+func (c *redisCache) TakeToken(ctx context.Context, key string, rate, burst int) (bool, int, time.Time, error) {
+	// NOTICE: the time is informed by the caller, instead of read from Redis, to keep the script deterministic.
+	now := clock.Now().UnixMilli()
+
+	result, err := takeTokenScript.Run(ctx, c.client, []string{key}, rate, burst, now).Int64Slice()
+	if err != nil {
+		return false, 0, time.Time{}, err
+	}
+
+	return result[0] == 1, int(result[1]), time.UnixMilli(result[2]), nil
+}
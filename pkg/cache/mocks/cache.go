@@ -32,6 +32,24 @@ func (_m *Cache) Delete(ctx context.Context, key string) error {
 	return r0
 }
 
+// DeletePrefix provides a mock function with given fields: ctx, prefix
+func (_m *Cache) DeletePrefix(ctx context.Context, prefix string) error {
+	ret := _m.Called(ctx, prefix)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePrefix")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, prefix)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: ctx, key, value
 func (_m *Cache) Get(ctx context.Context, key string, value interface{}) error {
 	ret := _m.Called(ctx, key, value)
@@ -85,6 +103,24 @@ func (_m *Cache) HasAccountLockout(ctx context.Context, source string, userID st
 	return r0, r1, r2
 }
 
+// Publish provides a mock function with given fields: ctx, channel, message
+func (_m *Cache) Publish(ctx context.Context, channel string, message []byte) error {
+	ret := _m.Called(ctx, channel, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Publish")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) error); ok {
+		r0 = rf(ctx, channel, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResetLoginAttempts provides a mock function with given fields: ctx, source, userID
 func (_m *Cache) ResetLoginAttempts(ctx context.Context, source string, userID string) error {
 	ret := _m.Called(ctx, source, userID)
@@ -156,6 +192,106 @@ func (_m *Cache) StoreLoginAttempt(ctx context.Context, source string, userID st
 	return r0, r1, r2
 }
 
+// Subscribe provides a mock function with given fields: ctx, channel
+func (_m *Cache) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	ret := _m.Called(ctx, channel)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 <-chan []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (<-chan []byte, error)); ok {
+		return rf(ctx, channel)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) <-chan []byte); ok {
+		r0 = rf(ctx, channel)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan []byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, channel)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Subscribers provides a mock function with given fields: ctx, channel
+func (_m *Cache) Subscribers(ctx context.Context, channel string) (int, error) {
+	ret := _m.Called(ctx, channel)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribers")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int, error)); ok {
+		return rf(ctx, channel)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int); ok {
+		r0 = rf(ctx, channel)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, channel)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TakeToken provides a mock function with given fields: ctx, key, rate, burst
+func (_m *Cache) TakeToken(ctx context.Context, key string, rate int, burst int) (bool, int, time.Time, error) {
+	ret := _m.Called(ctx, key, rate, burst)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TakeToken")
+	}
+
+	var r0 bool
+	var r1 int
+	var r2 time.Time
+	var r3 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) (bool, int, time.Time, error)); ok {
+		return rf(ctx, key, rate, burst)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) bool); ok {
+		r0 = rf(ctx, key, rate, burst)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) int); ok {
+		r1 = rf(ctx, key, rate, burst)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, int, int) time.Time); ok {
+		r2 = rf(ctx, key, rate, burst)
+	} else {
+		r2 = ret.Get(2).(time.Time)
+	}
+
+	if rf, ok := ret.Get(3).(func(context.Context, string, int, int) error); ok {
+		r3 = rf(ctx, key, rate, burst)
+	} else {
+		r3 = ret.Error(3)
+	}
+
+	return r0, r1, r2, r3
+}
+
 // NewCache creates a new instance of Cache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewCache(t interface {
@@ -0,0 +1,11 @@
+package cache
+
+import "time"
+
+// NamespaceLookupTTL is how long the namespace looked up by the SSH server is cached.
+const NamespaceLookupTTL = time.Minute
+
+// NamespaceLookupKey returns the key the namespace with tenantID is cached on by the SSH server.
+func NamespaceLookupKey(tenantID string) string {
+	return "ssh:namespace:" + tenantID
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// PublicKeyEvalTTL is how long the evaluation of a public key by the SSH server is cached.
+const PublicKeyEvalTTL = 5 * time.Minute
+
+// PublicKeyEvalPrefix returns the prefix of the keys of every evaluation cached for the public key with fingerprint of
+// the namespace with tenantID.
+func PublicKeyEvalPrefix(fingerprint, tenantID string) string {
+	return "pubkey:eval:" + fingerprint + ":" + tenantID + ":"
+}
+
+// PublicKeyEvalKey returns the key the evaluation of the public key with fingerprint, of the namespace with tenantID,
+// is cached on for the username on the device with deviceUID. The device's name and tags are part of the key, as the
+// public key's filter matches them, so renaming the device or changing its tags doesn't reuse a stale evaluation.
+func PublicKeyEvalKey(fingerprint, tenantID, deviceUID, deviceName string, deviceTags []string, username string) string {
+	tags := append([]string(nil), deviceTags...)
+	sort.Strings(tags)
+
+	return PublicKeyEvalPrefix(fingerprint, tenantID) + deviceUID + ":" + deviceName + ":" + strings.Join(tags, ",") + ":" + username
+}
+
+// PublicKeyEvalVersionKey returns the key changed whenever the evaluations cached for the public key are invalidated.
+// An evaluation that started before an invalidation must not be kept, as it can be stale.
+func PublicKeyEvalVersionKey(fingerprint, tenantID string) string {
+	return "pubkey:eval:" + fingerprint + ":" + tenantID
+}
@@ -0,0 +1,31 @@
+// Package logger carries a request-scoped logger through the context, so every log written while handling a request
+// shares the fields correlating it, like the request ID, across handlers, services and stores.
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type key struct{}
+
+// WithContext returns a copy of ctx carrying entry.
+func WithContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, key{}, entry)
+}
+
+// FromContext returns the logger carried by ctx. When ctx carries none, an entry of the standard logger is returned,
+// so it is always safe to log through it.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(key{}).(*logrus.Entry); ok && entry != nil {
+		return entry.WithContext(ctx)
+	}
+
+	return logrus.NewEntry(logrus.StandardLogger()).WithContext(ctx)
+}
+
+// WithFields adds fields to the logger carried by ctx, returning a copy of ctx carrying the new logger.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return WithContext(ctx, FromContext(ctx).WithFields(fields))
+}
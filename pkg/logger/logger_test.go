@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Run("returns the standard logger when the context carries none", func(t *testing.T) {
+		entry := FromContext(context.Background())
+		assert.Equal(t, logrus.StandardLogger(), entry.Logger)
+		assert.Empty(t, entry.Data)
+	})
+
+	t.Run("returns the logger carried by the context", func(t *testing.T) {
+		ctx := WithContext(context.Background(), logrus.WithField("request_id", "id"))
+		ctx = WithFields(ctx, logrus.Fields{"trace_id": "trace"})
+
+		entry := FromContext(ctx)
+		assert.Equal(t, logrus.Fields{"request_id": "id", "trace_id": "trace"}, entry.Data)
+		assert.Equal(t, ctx, entry.Context)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	echo "github.com/labstack/echo/v4"
+	"github.com/shellhub-io/shellhub/pkg/logger"
 	"github.com/sirupsen/logrus"
 )
 
@@ -15,14 +16,25 @@ func Log(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		level := logrus.InfoLevel
 
+		// NOTICE: when the request doesn't carry an ID, the one generated by the RequestID middleware is used, as long
+		// as it runs before this one.
+		id := c.Request().Header.Get(echo.HeaderXRequestID)
+		if id == "" {
+			id = c.Response().Header().Get(echo.HeaderXRequestID)
+		}
+
 		// Assign request tracking ID to log entry
 		entry := logrus.NewEntry(log).WithFields(logrus.Fields{
-			"id": c.Request().Header.Get(echo.HeaderXRequestID),
+			"id": id,
 		})
 
 		// Set context log entry
 		c.Set("log", entry)
 
+		// Set the request-scoped logger, used by handlers and services through the request's context
+		ctx := logger.WithContext(c.Request().Context(), logrus.WithField("request_id", id))
+		c.SetRequest(c.Request().WithContext(ctx))
+
 		// Measure request execution time
 		start := time.Now()
 		err := next(c)
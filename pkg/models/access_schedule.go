@@ -0,0 +1,150 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TimeWindow is a period of the week when SSH connections are allowed. It starts at StartTime, inclusive, and ends at
+// EndTime, exclusive, on every day of DaysOfWeek.
+type TimeWindow struct {
+	// DaysOfWeek are the days the window applies, from 0 (Sunday) to 6 (Saturday).
+	DaysOfWeek []int `json:"days_of_week" bson:"days_of_week"`
+	// StartTime is the start of the window in the "HH:MM" format.
+	StartTime string `json:"start_time" bson:"start_time"`
+	// EndTime is the end of the window in the "HH:MM" format. It must be after StartTime; "24:00" ends the window at
+	// the end of the day.
+	EndTime string `json:"end_time" bson:"end_time"`
+}
+
+// AccessSchedule restricts when SSH connections to a namespace's devices are allowed.
+type AccessSchedule struct {
+	// AllowedWindows are the windows when connections are allowed. When empty, connections are always allowed.
+	AllowedWindows []TimeWindow `json:"allowed_windows" bson:"allowed_windows"`
+	// Timezone is the IANA name of the timezone the windows are evaluated in. When empty, UTC is used.
+	Timezone string `json:"timezone" bson:"timezone"`
+}
+
+var (
+	ErrAccessScheduleTimezone = errors.New("invalid access schedule timezone")
+	ErrAccessScheduleDay      = errors.New("invalid access schedule day of week")
+	ErrAccessScheduleTime     = errors.New("invalid access schedule time")
+	ErrAccessScheduleWindow   = errors.New("access schedule window must end after it starts")
+)
+
+// parseTimeOfDay parses a time in the "HH:MM" format, returning the minutes since the start of the day. "24:00" is
+// parsed as the end of the day.
+func parseTimeOfDay(value string) (int, error) {
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+
+	parsed, err := time.Parse("15:04", value)
+	if err != nil || len(value) != len("15:04") {
+		return 0, ErrAccessScheduleTime
+	}
+
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// location returns the schedule's timezone.
+func (s *AccessSchedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	return time.LoadLocation(s.Timezone)
+}
+
+// ValidateAccessSchedule checks if the schedule's timezone exists and its windows are well formed.
+func ValidateAccessSchedule(schedule AccessSchedule) error {
+	if _, err := schedule.location(); err != nil {
+		return errors.Join(ErrAccessScheduleTimezone, err)
+	}
+
+	for _, window := range schedule.AllowedWindows {
+		if len(window.DaysOfWeek) == 0 {
+			return ErrAccessScheduleDay
+		}
+
+		for _, day := range window.DaysOfWeek {
+			if day < int(time.Sunday) || day > int(time.Saturday) {
+				return ErrAccessScheduleDay
+			}
+		}
+
+		start, err := parseTimeOfDay(window.StartTime)
+		if err != nil || start == 24*60 {
+			return ErrAccessScheduleTime
+		}
+
+		end, err := parseTimeOfDay(window.EndTime)
+		if err != nil {
+			return err
+		}
+
+		if end <= start {
+			return ErrAccessScheduleWindow
+		}
+	}
+
+	return nil
+}
+
+// Allows checks if a connection at the moment t is inside any of the schedule's windows, evaluated in its timezone. A
+// schedule without windows allows any moment, while an invalid schedule allows none.
+func (s *AccessSchedule) Allows(t time.Time) bool {
+	if len(s.AllowedWindows) == 0 {
+		return true
+	}
+
+	loc, err := s.location()
+	if err != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	day := int(local.Weekday())
+	minute := local.Hour()*60 + local.Minute()
+
+	for _, window := range s.AllowedWindows {
+		start, err := parseTimeOfDay(window.StartTime)
+		if err != nil {
+			continue
+		}
+
+		end, err := parseTimeOfDay(window.EndTime)
+		if err != nil {
+			continue
+		}
+
+		if slices.Contains(window.DaysOfWeek, day) && minute >= start && minute < end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String describes the schedule's windows, like "Mon,Tue 09:00-18:00 (America/Sao_Paulo)".
+func (s *AccessSchedule) String() string {
+	timezone := s.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	windows := make([]string, 0, len(s.AllowedWindows))
+	for _, window := range s.AllowedWindows {
+		days := make([]string, 0, len(window.DaysOfWeek))
+		for _, day := range window.DaysOfWeek {
+			days = append(days, time.Weekday(day).String()[:3])
+		}
+
+		windows = append(windows, fmt.Sprintf("%s %s-%s", strings.Join(days, ","), window.StartTime, window.EndTime))
+	}
+
+	return fmt.Sprintf("%s (%s)", strings.Join(windows, "; "), timezone)
+}
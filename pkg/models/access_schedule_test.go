@@ -0,0 +1,186 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAccessSchedule(t *testing.T) {
+	window := func(days []int, start, end string) TimeWindow {
+		return TimeWindow{DaysOfWeek: days, StartTime: start, EndTime: end}
+	}
+
+	cases := []struct {
+		description string
+		schedule    AccessSchedule
+		expected    error
+	}{
+		{
+			description: "fails when the timezone does not exist",
+			schedule:    AccessSchedule{Timezone: "Mars/Olympus_Mons"},
+			expected:    ErrAccessScheduleTimezone,
+		},
+		{
+			description: "fails when a window has no days",
+			schedule:    AccessSchedule{AllowedWindows: []TimeWindow{window(nil, "09:00", "18:00")}},
+			expected:    ErrAccessScheduleDay,
+		},
+		{
+			description: "fails when a day is out of the week",
+			schedule:    AccessSchedule{AllowedWindows: []TimeWindow{window([]int{7}, "09:00", "18:00")}},
+			expected:    ErrAccessScheduleDay,
+		},
+		{
+			description: "fails when a time is not in the HH:MM format",
+			schedule:    AccessSchedule{AllowedWindows: []TimeWindow{window([]int{1}, "9:00", "18:00")}},
+			expected:    ErrAccessScheduleTime,
+		},
+		{
+			description: "fails when a time is out of the day",
+			schedule:    AccessSchedule{AllowedWindows: []TimeWindow{window([]int{1}, "09:00", "24:30")}},
+			expected:    ErrAccessScheduleTime,
+		},
+		{
+			description: "fails when a window starts at the end of the day",
+			schedule:    AccessSchedule{AllowedWindows: []TimeWindow{window([]int{1}, "24:00", "24:00")}},
+			expected:    ErrAccessScheduleTime,
+		},
+		{
+			description: "fails when a window ends before it starts",
+			schedule:    AccessSchedule{AllowedWindows: []TimeWindow{window([]int{1}, "18:00", "09:00")}},
+			expected:    ErrAccessScheduleWindow,
+		},
+		{
+			description: "succeeds when the schedule is empty",
+			schedule:    AccessSchedule{},
+			expected:    nil,
+		},
+		{
+			description: "succeeds when the windows are well formed",
+			schedule: AccessSchedule{
+				Timezone: "America/Sao_Paulo",
+				AllowedWindows: []TimeWindow{
+					window([]int{1, 2, 3, 4, 5}, "09:00", "18:00"),
+					window([]int{6}, "00:00", "24:00"),
+				},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateAccessSchedule(tc.schedule), tc.expected)
+		})
+	}
+}
+
+func TestAccessScheduleAllows(t *testing.T) {
+	// 2024-01-01 was a Monday.
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	weekdays := AccessSchedule{
+		AllowedWindows: []TimeWindow{{DaysOfWeek: []int{1, 2, 3, 4, 5}, StartTime: "09:00", EndTime: "18:00"}},
+	}
+
+	cases := []struct {
+		description string
+		schedule    AccessSchedule
+		at          time.Time
+		expected    bool
+	}{
+		{
+			description: "allows any moment when the schedule is empty",
+			schedule:    AccessSchedule{},
+			at:          monday(3, 0),
+			expected:    true,
+		},
+		{
+			description: "allows the start of the window",
+			schedule:    weekdays,
+			at:          monday(9, 0),
+			expected:    true,
+		},
+		{
+			description: "denies the minute before the window",
+			schedule:    weekdays,
+			at:          monday(8, 59),
+			expected:    false,
+		},
+		{
+			description: "allows the last minute of the window",
+			schedule:    weekdays,
+			at:          monday(17, 59),
+			expected:    true,
+		},
+		{
+			description: "denies the end of the window",
+			schedule:    weekdays,
+			at:          monday(18, 0),
+			expected:    false,
+		},
+		{
+			description: "denies a day out of the window",
+			schedule:    weekdays,
+			at:          monday(12, 0).AddDate(0, 0, -1),
+			expected:    false,
+		},
+		{
+			description: "allows the last minute of a window ending at the end of the day",
+			schedule: AccessSchedule{
+				AllowedWindows: []TimeWindow{{DaysOfWeek: []int{1}, StartTime: "00:00", EndTime: "24:00"}},
+			},
+			at:       monday(23, 59),
+			expected: true,
+		},
+		{
+			description: "allows a moment inside the window in the schedule's timezone",
+			schedule: AccessSchedule{
+				Timezone:       "America/Sao_Paulo",
+				AllowedWindows: weekdays.AllowedWindows,
+			},
+			// 20:00 UTC is 17:00 in São Paulo.
+			at:       monday(20, 0),
+			expected: true,
+		},
+		{
+			description: "denies a moment inside the window in UTC but outside in the schedule's timezone",
+			schedule: AccessSchedule{
+				Timezone:       "America/Sao_Paulo",
+				AllowedWindows: weekdays.AllowedWindows,
+			},
+			// 10:00 UTC is 07:00 in São Paulo.
+			at:       monday(10, 0),
+			expected: false,
+		},
+		{
+			description: "evaluates the day of week in the schedule's timezone",
+			schedule: AccessSchedule{
+				Timezone:       "Asia/Tokyo",
+				AllowedWindows: []TimeWindow{{DaysOfWeek: []int{2}, StartTime: "00:00", EndTime: "12:00"}},
+			},
+			// 18:00 UTC on Monday is 03:00 on Tuesday in Tokyo.
+			at:       monday(18, 0),
+			expected: true,
+		},
+		{
+			description: "denies any moment when the timezone does not exist",
+			schedule: AccessSchedule{
+				Timezone:       "Mars/Olympus_Mons",
+				AllowedWindows: weekdays.AllowedWindows,
+			},
+			at:       monday(12, 0),
+			expected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.schedule.Allows(tc.at))
+		})
+	}
+}
@@ -29,6 +29,9 @@ type APIKey struct {
 	// ExpiresIn is the expiration date of the API key. An expired key cannot be used for
 	// authentication. When equals or less than 0 it means that are no expiration date.
 	ExpiresIn int64 `json:"expires_in" bson:"expires_in"`
+	// Scopes are the permissions, as defined by the guard package, the API key is restricted to. A route that requires
+	// scopes only accepts the key when it has at least one of them. Keys without scopes are not restricted.
+	Scopes []int `json:"scopes,omitempty" bson:"scopes,omitempty"`
 }
 
 // IsValid reports whether an API key is valid or not.
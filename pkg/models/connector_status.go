@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ConnectorStatus is the status of an agent started by one of the replicas of a connector.
+type ConnectorStatus struct {
+	TenantID string `json:"tenant_id" bson:"tenant_id"`
+	// Address identifies the agent on the connector, as the short ID of its container.
+	Address string `json:"address" bson:"address"`
+	Status  string `json:"status" bson:"status"`
+	// Replica is the replica of the connector that started the agent.
+	Replica   string    `json:"replica" bson:"replica"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
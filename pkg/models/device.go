@@ -1,11 +1,15 @@
 package models
 
 import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v4"
 	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/uuid"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 type DeviceStatus string
@@ -19,6 +23,18 @@ const (
 	DeviceStatusEmpty    DeviceStatus = ""
 )
 
+// DeviceAgentStatus is how the version of a device's agent compares to the server's ones.
+type DeviceAgentStatus string
+
+const (
+	// DeviceAgentStatusUpToDate is the status of an agent with, at least, the server's version.
+	DeviceAgentStatusUpToDate DeviceAgentStatus = "up_to_date"
+	// DeviceAgentStatusUpdateAvailable is the status of a supported agent older than the server's version.
+	DeviceAgentStatusUpdateAvailable DeviceAgentStatus = "update_available"
+	// DeviceAgentStatusUnsupported is the status of an agent older than the minimum version supported by the server.
+	DeviceAgentStatusUnsupported DeviceAgentStatus = "unsupported"
+)
+
 type Device struct {
 	// UID is the unique identifier for a device.
 	UID              string          `json:"uid"`
@@ -39,6 +55,110 @@ type Device struct {
 	PublicURL        bool            `json:"public_url" bson:"public_url,omitempty"`
 	PublicURLAddress string          `json:"public_url_address" bson:"public_url_address,omitempty"`
 	Acceptable       bool            `json:"acceptable" bson:"acceptable,omitempty"`
+	// TrustedHostKey is a PEM encoded public key pre-shared for the device. When set, the device must present this
+	// key to register and to be connected through the SSH server.
+	TrustedHostKey string `json:"trusted_host_key,omitempty" bson:"trusted_host_key,omitempty"`
+	// SessionPolicy restricts the SSH sessions to the device beyond its namespace's settings.
+	SessionPolicy *DeviceSessionPolicy `json:"session_policy,omitempty" bson:"session_policy,omitempty"`
+	// AgentStatus is how the version of the device's agent compares to the server's ones when it last authenticated.
+	// It's empty when the versions couldn't be compared.
+	AgentStatus DeviceAgentStatus `json:"agent_status,omitempty" bson:"agent_status,omitempty"`
+	// AllowedCommands are glob patterns of the only commands that can be executed on the device through the SSH server,
+	// matched argument by argument: an argument "*" matches any arguments, while inside an argument "*" matches any
+	// sequence of characters and "?" any single one. When not empty, interactive shells, subsystems and commands with
+	// shell metacharacters are refused.
+	AllowedCommands []string `json:"allowed_commands,omitempty" bson:"allowed_commands,omitempty"`
+	// ExpiresAt is when the device's access to the namespace ends, like for a contractor's device. From then on, the
+	// connections to it are refused, and it's soon rejected. When nil, the access doesn't end.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+}
+
+// Expired reports whether the device's access to the namespace ended at now.
+func (d *Device) Expired(now time.Time) bool {
+	return d.ExpiresAt != nil && !now.Before(*d.ExpiresAt)
+}
+
+// DeviceSessionPolicy is the policy applied to the SSH sessions to a device. It takes precedence over the namespace's
+// settings, allowing a sensitive device to enforce stricter rules than the rest of its namespace.
+type DeviceSessionPolicy struct {
+	// MaxIdle is how long, in seconds, a session can stay without input from its client before being closed. When
+	// zero, sessions are never closed due inactivity.
+	MaxIdle int `json:"max_idle" bson:"max_idle" validate:"min=0"`
+	// ForceRecording records the sessions even when the namespace's session recording is disabled.
+	ForceRecording bool `json:"force_recording" bson:"force_recording"`
+	// AllowedSubsystems are the only subsystems, like "sftp", that can be requested. When empty, any subsystem is
+	// allowed.
+	AllowedSubsystems []string `json:"allowed_subsystems" bson:"allowed_subsystems" validate:"unique,dive,required"`
+}
+
+var (
+	ErrDeviceHostKeyInvalid  = errors.New("invalid device host key")
+	ErrDeviceHostKeyMismatch = errors.New("device host key does not match the trusted one")
+)
+
+// ParseDeviceHostKey parses a PEM encoded public key, accepting both PKCS#1 ("RSA PUBLIC KEY") and PKIX ("PUBLIC KEY")
+// blocks.
+func ParseDeviceHostKey(data string) (gossh.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, ErrDeviceHostKeyInvalid
+	}
+
+	var key interface{}
+	var err error
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, ErrDeviceHostKeyInvalid
+	}
+
+	if err != nil {
+		return nil, ErrDeviceHostKeyInvalid
+	}
+
+	pub, err := gossh.NewPublicKey(key)
+	if err != nil {
+		return nil, ErrDeviceHostKeyInvalid
+	}
+
+	return pub, nil
+}
+
+// VerifyHostKey checks the key presented by the device against its trusted host key. Devices without a trusted host
+// key accept any key.
+func (d *Device) VerifyHostKey(key gossh.PublicKey) error {
+	if d.TrustedHostKey == "" {
+		return nil
+	}
+
+	trusted, err := ParseDeviceHostKey(d.TrustedHostKey)
+	if err != nil {
+		return err
+	}
+
+	if key == nil || string(trusted.Marshal()) != string(key.Marshal()) {
+		return ErrDeviceHostKeyMismatch
+	}
+
+	return nil
+}
+
+// VerifyHostKeyPEM is like [Device.VerifyHostKey], but receives the presented key PEM encoded.
+func (d *Device) VerifyHostKeyPEM(data string) error {
+	if d.TrustedHostKey == "" {
+		return nil
+	}
+
+	key, err := ParseDeviceHostKey(data)
+	if err != nil {
+		return ErrDeviceHostKeyMismatch
+	}
+
+	return d.VerifyHostKey(key)
 }
 
 type DeviceAuthClaims struct {
@@ -63,6 +183,8 @@ func (d *DeviceAuthClaims) WithDefaults() *DeviceAuthClaims {
 type DeviceAuthRequest struct {
 	Info     *DeviceInfo `json:"info"`
 	Sessions []string    `json:"sessions,omitempty"`
+	// EnrollmentKey is the key of the namespace required to enroll a new device, when the namespace has one.
+	EnrollmentKey string `json:"enrollment_key,omitempty"`
 	*DeviceAuth
 }
 
@@ -84,6 +206,9 @@ type DeviceIdentity struct {
 	MAC string `json:"mac"`
 }
 
+// DevicePlatformConnector is the [DeviceInfo.Platform] reported by the devices created from containers by a connector.
+const DevicePlatformConnector = "connector"
+
 type DeviceInfo struct {
 	ID         string `json:"id"`
 	PrettyName string `json:"pretty_name"`
@@ -96,6 +221,9 @@ type ConnectedDevice struct {
 	UID      string    `json:"uid"`
 	TenantID string    `json:"tenant_id" bson:"tenant_id"`
 	LastSeen time.Time `json:"last_seen" bson:"last_seen"`
+	// Protocol is the version of the heartbeat payload the device was reported online with, like "v2". It's empty for
+	// the devices reported before it was recorded.
+	Protocol string `json:"protocol,omitempty" bson:"protocol,omitempty"`
 }
 
 type DevicePosition struct {
@@ -117,3 +245,11 @@ func NewDeviceTag(tag string) DeviceTag {
 		Tag: tag,
 	}
 }
+
+// DevicePingResult is the result of a reachability check performed against a device through its tunnel.
+type DevicePingResult struct {
+	Reachable bool `json:"reachable"`
+	// LatencyMS is the time, in milliseconds, the device's agent took to answer a request sent through its tunnel.
+	LatencyMS int    `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
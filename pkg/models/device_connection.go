@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+const (
+	DeviceConnectionAuthPassword  = "password"
+	DeviceConnectionAuthPublicKey = "publickey"
+)
+
+// DeviceConnection is an entry on the connection history of a device, made from one of its sessions.
+type DeviceConnection struct {
+	SessionUID  string    `json:"session_uid" bson:"uid"`
+	Username    string    `json:"username" bson:"username"`
+	IP          string    `json:"ip" bson:"ip_address"`
+	ConnectedAt time.Time `json:"connected_at" bson:"started_at"`
+	// DisconnectedAt is when the session was closed. It is nil while the session is still open.
+	DisconnectedAt *time.Time `json:"disconnected_at" bson:"disconnected_at"`
+	// Duration is how long, in milliseconds, the session lasted, or has lasted so far when it is still open.
+	Duration int64 `json:"duration" bson:"duration"`
+	// AuthMethod is how the session's client authenticated, [DeviceConnectionAuthPassword] or
+	// [DeviceConnectionAuthPublicKey]. It is empty when the client didn't authenticate.
+	AuthMethod string `json:"auth_method" bson:"auth_method"`
+}
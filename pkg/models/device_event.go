@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// DeviceEventType is the lifecycle action a device event records.
+type DeviceEventType string
+
+const (
+	DeviceEventAccepted DeviceEventType = "accepted"
+	DeviceEventRejected DeviceEventType = "rejected"
+	DeviceEventRenamed  DeviceEventType = "renamed"
+	DeviceEventRemoved  DeviceEventType = "removed"
+	DeviceEventExpired  DeviceEventType = "expired"
+)
+
+// DeviceEvent is an entry on the timeline of a device's lifecycle.
+type DeviceEvent struct {
+	TenantID  string          `json:"tenant_id" bson:"tenant_id"`
+	DeviceUID string          `json:"device_uid" bson:"device_uid"`
+	Type      DeviceEventType `json:"type" bson:"type"`
+	// Actor is the ID of the user who performed the action. It is empty when the action wasn't performed by a user.
+	Actor string `json:"actor,omitempty" bson:"actor,omitempty"`
+	// Data holds details specific to the event's type, like the former and the new names of a renamed device.
+	Data      map[string]string `json:"data,omitempty" bson:"data,omitempty"`
+	CreatedAt time.Time         `json:"created_at" bson:"created_at"`
+}
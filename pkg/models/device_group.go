@@ -0,0 +1,26 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// DeviceGroup is a named set of devices of a namespace, allowing them to be targeted at once, like by firewall rules.
+type DeviceGroup struct {
+	UID       string    `json:"uid" bson:"uid"`
+	TenantID  string    `json:"tenant_id" bson:"tenant_id"`
+	Name      string    `json:"name" bson:"name"`
+	Devices   []string  `json:"devices" bson:"devices"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// Has reports whether the device with the specified UID is a member of the group.
+func (g *DeviceGroup) Has(uid string) bool {
+	return slices.Contains(g.Devices, uid)
+}
+
+// DeviceGroupChanges holds the attributes of a device group to update. Nil attributes are kept unchanged.
+type DeviceGroupChanges struct {
+	Name    *string   `bson:"name,omitempty"`
+	Devices *[]string `bson:"devices,omitempty"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultEnvVarsMax is the maximum number of environment variables a namespace can inject into its sessions.
+	DefaultEnvVarsMax = 32
+	// EnvVarValueMaxLength is the maximum length of the value of an environment variable sent to a device.
+	EnvVarValueMaxLength = 4096
+)
+
+var (
+	ErrEnvVarName      = errors.New("invalid environment variable name")
+	ErrEnvVarSensitive = errors.New("environment variable not allowed")
+	ErrEnvVarValue     = errors.New("invalid environment variable value")
+)
+
+// envVarName matches the names of the environment variables a shell accepts.
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+// sensitiveEnvVars are the environment variables that change what a device executes, like the dynamic linker's or the
+// shell's startup, so they can't be set through a session.
+var sensitiveEnvVars = map[string]bool{
+	"BASH_ENV":         true,
+	"ENV":              true,
+	"IFS":              true,
+	"PATH":             true,
+	"PROMPT_COMMAND":   true,
+	"PS4":              true,
+	"SHELLOPTS":        true,
+	"BASHOPTS":         true,
+	"GCONV_PATH":       true,
+	"HOSTALIASES":      true,
+	"LOCALDOMAIN":      true,
+	"NLSPATH":          true,
+	"PERL5OPT":         true,
+	"PYTHONSTARTUP":    true,
+	"PYTHONPATH":       true,
+	"RUBYOPT":          true,
+	"NODE_OPTIONS":     true,
+	"MALLOC_CHECK_":    true,
+	"RESOLV_HOST_CONF": true,
+}
+
+// ValidateEnvVar checks if the environment variable can be set through a session, either by the client or by the
+// namespace's default environment variables. Besides well-formed names and values, the variables that change what the
+// device executes are refused, like [sensitiveEnvVars] and the ones prefixed with "LD_" or "DYLD_".
+func ValidateEnvVar(name, value string) error {
+	if !envVarName.MatchString(name) {
+		return ErrEnvVarName
+	}
+
+	upper := strings.ToUpper(name)
+	if sensitiveEnvVars[upper] || strings.HasPrefix(upper, "LD_") || strings.HasPrefix(upper, "DYLD_") {
+		return ErrEnvVarSensitive
+	}
+
+	if len(value) > EnvVarValueMaxLength || strings.ContainsRune(value, 0) {
+		return ErrEnvVarValue
+	}
+
+	return nil
+}
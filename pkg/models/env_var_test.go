@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEnvVar(t *testing.T) {
+	cases := []struct {
+		description string
+		name        string
+		value       string
+		expected    error
+	}{
+		{
+			description: "fails when the name is empty",
+			name:        "",
+			expected:    ErrEnvVarName,
+		},
+		{
+			description: "fails when the name starts with a digit",
+			name:        "1TERM",
+			expected:    ErrEnvVarName,
+		},
+		{
+			description: "fails when the name has an equal sign",
+			name:        "TERM=xterm",
+			expected:    ErrEnvVarName,
+		},
+		{
+			description: "fails when the variable is sensitive",
+			name:        "BASH_ENV",
+			expected:    ErrEnvVarSensitive,
+		},
+		{
+			description: "fails when the variable is sensitive regardless of its case",
+			name:        "path",
+			expected:    ErrEnvVarSensitive,
+		},
+		{
+			description: "fails when the variable configures the dynamic linker",
+			name:        "LD_PRELOAD",
+			expected:    ErrEnvVarSensitive,
+		},
+		{
+			description: "fails when the value is too long",
+			name:        "TERM",
+			value:       strings.Repeat("x", EnvVarValueMaxLength+1),
+			expected:    ErrEnvVarValue,
+		},
+		{
+			description: "fails when the value has a null byte",
+			name:        "TERM",
+			value:       "xterm\x00",
+			expected:    ErrEnvVarValue,
+		},
+		{
+			description: "succeeds",
+			name:        "TERM",
+			value:       "xterm-256color",
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ValidateEnvVar(tc.name, tc.value))
+		})
+	}
+}
@@ -21,6 +21,9 @@ type FirewallRuleFields struct {
 	SourceIP string         `json:"source_ip" bson:"source_ip" validate:"required,regexp"`
 	Username string         `json:"username" validate:"required,regexp"`
 	Filter   FirewallFilter `json:"filter" bson:"filter" validate:"required"`
+	// DeviceGroupUID restricts the rule to the members of a device group, besides matching its filter. A rule meant
+	// for the whole group should use a filter matching any hostname, like ".*".
+	DeviceGroupUID string `json:"device_group_uid,omitempty" bson:"device_group_uid,omitempty"`
 }
 
 func (f *FirewallRuleFields) Validate() error {
@@ -44,3 +47,45 @@ type FirewallRule struct {
 type FirewallRuleUpdate struct {
 	FirewallRuleFields `bson:",inline"`
 }
+
+const (
+	// FirewallConflictShadowed means that a rule never applies, as a rule evaluated before it matches every connection
+	// it would match.
+	FirewallConflictShadowed = "shadowed"
+	// FirewallConflictDuplicate means that two rules with the same priority match the same connections with the same
+	// action.
+	FirewallConflictDuplicate = "duplicate"
+	// FirewallConflictContradictory means that two rules with the same priority match common connections with opposite
+	// actions, what is always decided by the deny one.
+	FirewallConflictContradictory = "contradictory"
+)
+
+// FirewallConflict describes two firewall rules that conflict with each other. When the conflict is
+// [FirewallConflictShadowed], Rule1UID is the rule that shadows Rule2UID.
+type FirewallConflict struct {
+	Rule1UID     string `json:"rule1_uid"`
+	Rule2UID     string `json:"rule2_uid"`
+	ConflictType string `json:"conflict_type"`
+}
+
+// FirewallRulePreview is what a firewall rule would affect if it was saved: the namespace's accepted devices that a
+// connection, described by a [FirewallPreviewConnection], would match through the rule's source IP, username, filter
+// and device group, as the firewall does when the connection is started. They would be allowed or denied as defined by
+// Action.
+type FirewallRulePreview struct {
+	Action  string                  `json:"action"`
+	Devices []FirewallPreviewDevice `json:"devices"`
+}
+
+// FirewallPreviewConnection is the connection a firewall rule is previewed for. Username is the login used on the
+// device, not a namespace member's username, and IPAddress is the address the connection comes from.
+type FirewallPreviewConnection struct {
+	Username  string `json:"username"`
+	IPAddress string `json:"ip_address"`
+}
+
+// FirewallPreviewDevice is a device affected by a previewed firewall rule.
+type FirewallPreviewDevice struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// InviteLink is a public invitation to join a namespace. Unlike adding a member, who must be known by username, anyone
+// with an account holding the link's token can join the namespace with the link's role, until the link expires or is
+// used MaxUses times.
+type InviteLink struct {
+	// Token is the secret that identifies the link in the invitation URL.
+	Token string `json:"token" bson:"_id"`
+	// TenantID is the namespace the link invites to.
+	TenantID string `json:"tenant_id" bson:"tenant_id"`
+	// Role is the role given to the members who join the namespace through the link.
+	Role string `json:"role" bson:"role"`
+	// MaxUses is how many times the link can be used.
+	MaxUses int `json:"max_uses" bson:"max_uses"`
+	// UseCount is how many times the link was used.
+	UseCount int `json:"use_count" bson:"use_count"`
+	// CreatedBy is the ID of the user who created the link.
+	CreatedBy string `json:"created_by" bson:"created_by"`
+	// CreatedAt is the creation date of the link.
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	// ExpiresAt is the date from which the link can no longer be used.
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// IsExpired reports whether the link is expired at now.
+func (l *InviteLink) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// IsExhausted reports whether the link was used as many times as allowed.
+func (l *InviteLink) IsExhausted() bool {
+	return l.UseCount >= l.MaxUses
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+)
+
+// ErrIPCIDR is returned when an entry is neither an IP address nor a network in CIDR notation.
+var ErrIPCIDR = errors.New("invalid IP address or CIDR")
+
+// parseIPCIDR parses an IP address, as a network with only itself, or a network in CIDR notation.
+func parseIPCIDR(entry string) (netip.Prefix, error) {
+	if strings.Contains(entry, "/") {
+		return netip.ParsePrefix(entry)
+	}
+
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// ValidateIPCIDR checks if each entry is either an IP address, like "192.168.0.1", or a network in CIDR notation, like
+// "10.0.0.0/8" or "2001:db8::/32".
+func ValidateIPCIDR(cidrs []string) error {
+	for _, entry := range cidrs {
+		if _, err := parseIPCIDR(entry); err != nil {
+			return ErrIPCIDR
+		}
+	}
+
+	return nil
+}
+
+// MatchIPCIDR reports whether ip is one of the addresses, or is on one of the networks, of cidrs. An empty list
+// matches any IP, while the malformed entries never match.
+func MatchIPCIDR(cidrs []string, ip string) bool {
+	if len(cidrs) == 0 {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	// NOTICE: an IPv4 client connected to an IPv6 socket has its address mapped, as "::ffff:192.168.0.1".
+	addr = addr.Unmap()
+
+	for _, entry := range cidrs {
+		prefix, err := parseIPCIDR(entry)
+		if err != nil {
+			continue
+		}
+
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateIPCIDR(t *testing.T) {
+	cases := []struct {
+		description string
+		cidrs       []string
+		expected    error
+	}{
+		{
+			description: "succeeds when the list is empty",
+			cidrs:       nil,
+			expected:    nil,
+		},
+		{
+			description: "succeeds with IP addresses and networks",
+			cidrs:       []string{"192.168.0.1", "10.0.0.0/8", "2001:db8::1", "2001:db8::/32"},
+			expected:    nil,
+		},
+		{
+			description: "fails when an address is malformed",
+			cidrs:       []string{"192.168.0.256"},
+			expected:    ErrIPCIDR,
+		},
+		{
+			description: "fails when a network is malformed",
+			cidrs:       []string{"10.0.0.0/33"},
+			expected:    ErrIPCIDR,
+		},
+		{
+			description: "fails when an entry is a hostname",
+			cidrs:       []string{"10.0.0.0/8", "example.com"},
+			expected:    ErrIPCIDR,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ValidateIPCIDR(tc.cidrs))
+		})
+	}
+}
+
+func TestMatchIPCIDR(t *testing.T) {
+	cases := []struct {
+		description string
+		cidrs       []string
+		ip          string
+		expected    bool
+	}{
+		{
+			description: "matches any IP when the list is empty",
+			cidrs:       []string{},
+			ip:          "203.0.113.7",
+			expected:    true,
+		},
+		{
+			description: "matches a single IP",
+			cidrs:       []string{"203.0.113.7"},
+			ip:          "203.0.113.7",
+			expected:    true,
+		},
+		{
+			description: "doesn't match another single IP",
+			cidrs:       []string{"203.0.113.7"},
+			ip:          "203.0.113.8",
+			expected:    false,
+		},
+		{
+			description: "matches an IP on a CIDR range",
+			cidrs:       []string{"10.0.0.0/8", "192.168.0.0/24"},
+			ip:          "192.168.0.42",
+			expected:    true,
+		},
+		{
+			description: "doesn't match an IP out of the CIDR ranges",
+			cidrs:       []string{"10.0.0.0/8", "192.168.0.0/24"},
+			ip:          "192.168.1.42",
+			expected:    false,
+		},
+		{
+			description: "matches an IPv6 address on a CIDR range",
+			cidrs:       []string{"2001:db8::/32"},
+			ip:          "2001:db8:1::1",
+			expected:    true,
+		},
+		{
+			description: "matches a single IPv6 address",
+			cidrs:       []string{"2001:db8::1"},
+			ip:          "2001:db8::1",
+			expected:    true,
+		},
+		{
+			description: "doesn't match an IPv6 address out of the CIDR range",
+			cidrs:       []string{"2001:db8::/32"},
+			ip:          "2001:db9::1",
+			expected:    false,
+		},
+		{
+			description: "matches an IPv4-mapped IPv6 address",
+			cidrs:       []string{"192.168.0.0/24"},
+			ip:          "::ffff:192.168.0.42",
+			expected:    true,
+		},
+		{
+			description: "ignores the malformed entries",
+			cidrs:       []string{"10.0.0.0/33", "not an ip", "192.168.0.42"},
+			ip:          "192.168.0.42",
+			expected:    true,
+		},
+		{
+			description: "doesn't match when every entry is malformed",
+			cidrs:       []string{"10.0.0.0/33"},
+			ip:          "10.0.0.1",
+			expected:    false,
+		},
+		{
+			description: "doesn't match a malformed IP",
+			cidrs:       []string{"10.0.0.0/8"},
+			ip:          "10.0.0",
+			expected:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, MatchIPCIDR(tc.cidrs, tc.ip))
+		})
+	}
+}
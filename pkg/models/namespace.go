@@ -1,6 +1,7 @@
 package models
 
 import (
+	"crypto/subtle"
 	"time"
 )
 
@@ -16,6 +17,55 @@ type Namespace struct {
 	DevicesCount int                `json:"devices_count" bson:"devices_count,omitempty"`
 	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
 	Billing      *Billing           `json:"billing" bson:"billing,omitempty"`
+	// PreviousNames are the last names used by the namespace, from the oldest to the newest, limited to
+	// [NamespacePreviousNamesLimit] entries.
+	PreviousNames []NamespacePreviousName `json:"previous_names,omitempty" bson:"previous_names,omitempty"`
+	// APIRateLimit limits the rate of the API requests made on behalf of the namespace. When nil, it isn't limited.
+	APIRateLimit *APIRateLimitConfig `json:"api_rate_limit,omitempty" bson:"api_rate_limit,omitempty"`
+	// LastActiveAt is the last time the namespace was active, like when a session to one of its devices was started.
+	LastActiveAt *time.Time `json:"last_active_at,omitempty" bson:"last_active_at,omitempty"`
+	// ExpiresAt is when the namespace will be deleted due inactivity. It's set on free-tier namespaces inactive for
+	// a long time and cleared when they are active again. When nil, the namespace doesn't expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	// UsageReportSentAt is the last time the usage report was sent by its schedule, from when the next one is
+	// scheduled. When nil, the schedule hasn't started yet.
+	UsageReportSentAt *time.Time `json:"-" bson:"usage_report_sent_at,omitempty"`
+	// EnrollmentKey is the key the new devices must present to enroll in the namespace. When nil, the devices enroll
+	// with the tenant ID only.
+	EnrollmentKey *NamespaceEnrollmentKey `json:"-" bson:"enrollment_key,omitempty"`
+	// Webhook is where the namespace's events are posted to. When nil, they aren't posted.
+	Webhook *NamespaceWebhook `json:"-" bson:"webhook,omitempty"`
+}
+
+// NamespaceEnrollmentKey is the key required to enroll new devices in a namespace. Only the SHA256 hashes of the keys are
+// kept, so a key is known only when it's generated.
+type NamespaceEnrollmentKey struct {
+	Hash string `bson:"hash"`
+	// PreviousHash is the hash of the key replaced by the last rotation, still accepted until PreviousExpiresAt.
+	PreviousHash      string     `bson:"previous_hash,omitempty"`
+	PreviousExpiresAt *time.Time `bson:"previous_expires_at,omitempty"`
+	RotatedAt         time.Time  `bson:"rotated_at"`
+	// RotatedBy is the ID of the user who rotated the key.
+	RotatedBy string `bson:"rotated_by"`
+}
+
+// Accepts checks if the key with the specified SHA256 hash enrolls devices at now.
+func (k *NamespaceEnrollmentKey) Accepts(hash string, now time.Time) bool {
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(k.Hash)) == 1 {
+		return true
+	}
+
+	return k.PreviousHash != "" && k.PreviousExpiresAt != nil && now.Before(*k.PreviousExpiresAt) &&
+		subtle.ConstantTimeCompare([]byte(hash), []byte(k.PreviousHash)) == 1
+}
+
+// NamespacePreviousNamesLimit is the maximum number of previous names kept on a namespace.
+const NamespacePreviousNamesLimit = 10
+
+// NamespacePreviousName is a name used by a namespace before being renamed.
+type NamespacePreviousName struct {
+	Name      string    `json:"name" bson:"name"`
+	ChangedAt time.Time `json:"changed_at" bson:"changed_at"`
 }
 
 // HasMaxDevices checks if the namespace has a maximum number of devices.
@@ -52,16 +102,144 @@ func (n *Namespace) FindMember(id string) (*Member, bool) {
 type NamespaceSettings struct {
 	SessionRecord          bool   `json:"session_record" bson:"session_record,omitempty"`
 	ConnectionAnnouncement string `json:"connection_announcement" bson:"connection_announcement"`
+	// ExecAnnouncement also shows the connection announcement, on stderr, to the sessions executing a command. It's
+	// disabled by default as some automation can't tolerate the extra output.
+	ExecAnnouncement bool `json:"exec_announcement" bson:"exec_announcement,omitempty"`
+	// DefaultFirewallPolicy is the action applied to connections that don't match any firewall rule, as evaluated by
+	// the SSH server when the connection is authenticated. It must be either [FirewallPolicyAllow] or
+	// [FirewallPolicyDeny]; an empty value behaves as [FirewallPolicyAllow].
+	DefaultFirewallPolicy string `json:"default_firewall_policy" bson:"default_firewall_policy,omitempty"`
+	// TransferSessionEnabled allows the namespace's members to transfer their active sessions to other members.
+	TransferSessionEnabled bool `json:"transfer_session_enabled" bson:"transfer_session_enabled,omitempty"`
+	// AccessSchedule restricts the SSH connections to the namespace's devices to its time windows. A nil schedule
+	// allows connections at any time.
+	AccessSchedule *AccessSchedule `json:"access_schedule,omitempty" bson:"access_schedule,omitempty"`
+	// MinRSABits is the minimum size, in bits, of the RSA public keys added to the namespace. When zero,
+	// [DefaultMinRSABits] is used. It's ignored when [AllowRSA1024] is set.
+	MinRSABits int `json:"min_rsa_bits" bson:"min_rsa_bits,omitempty"`
+	// AllowDSA allows DSA public keys to be added to the namespace.
+	AllowDSA bool `json:"allow_dsa" bson:"allow_dsa,omitempty"`
+	// AllowRSA1024 allows RSA public keys of 1024 bits to be added to the namespace. It takes precedence over
+	// [MinRSABits], lowering the minimum size to 1024 bits whatever its value.
+	AllowRSA1024 bool `json:"allow_rsa1024" bson:"allow_rsa1024,omitempty"`
+	// LiveMonitoringEnabled allows the namespace's members to watch the recording of its active sessions as it happens.
+	LiveMonitoringEnabled bool `json:"live_monitoring_enabled" bson:"live_monitoring_enabled,omitempty"`
+	// MaxLiveMonitors is the maximum number of simultaneous watchers of an active session. When zero,
+	// [DefaultMaxLiveMonitors] is used.
+	MaxLiveMonitors int `json:"max_live_monitors" bson:"max_live_monitors,omitempty"`
+	// RecordingIdlePauseMS is how long, in milliseconds, a recorded session can stay without data in either direction
+	// before its recording's timeline is paused, until data flows again. When zero, the recording is never paused.
+	RecordingIdlePauseMS int `json:"recording_idle_pause_ms" bson:"recording_idle_pause_ms,omitempty"`
+	// UsageReportSchedule is the cron expression of when the namespace's usage report is sent to UsageReportEmail.
+	UsageReportSchedule string `json:"usage_report_schedule" bson:"usage_report_schedule,omitempty"`
+	// UsageReportEmail is the address the namespace's usage report is sent to. When empty, the report isn't sent.
+	UsageReportEmail string `json:"usage_report_email" bson:"usage_report_email,omitempty"`
+	// DefaultEnvVars are the environment variables set on the namespace's sessions, unless the client sets them itself.
+	DefaultEnvVars map[string]string `json:"default_env_vars,omitempty" bson:"default_env_vars,omitempty"`
+	// MaxSessionDurationMinutes is how long, in minutes, the namespace's sessions can last before being disconnected.
+	// When zero, there is no limit.
+	MaxSessionDurationMinutes int `json:"max_session_duration_minutes" bson:"max_session_duration_minutes,omitempty"`
+	// MaxSessionKeepaliveEnabled restarts the maximum session duration on every keepalive from the agent, making it a
+	// limit on how long the agent can stay silent instead of on the whole session. The agent sends its keepalives
+	// periodically, regardless of the user's activity, so it doesn't disconnect the idle sessions.
+	MaxSessionKeepaliveEnabled bool `json:"max_session_keepalive_enabled" bson:"max_session_keepalive_enabled,omitempty"`
+}
+
+// DefaultMinRSABits is the minimum size, in bits, of the RSA public keys when the namespace doesn't define one.
+const DefaultMinRSABits = 2048
+
+// DefaultMaxLiveMonitors is the maximum number of simultaneous watchers of an active session when the namespace doesn't
+// define one.
+const DefaultMaxLiveMonitors = 5
+
+// LiveMonitorsLimit returns the maximum number of simultaneous watchers of the namespace's active sessions.
+func (s *NamespaceSettings) LiveMonitorsLimit() int {
+	if s != nil && s.MaxLiveMonitors > 0 {
+		return s.MaxLiveMonitors
+	}
+
+	return DefaultMaxLiveMonitors
 }
 
+// RSAMinBits returns the minimum size, in bits, of the RSA public keys added to the namespace. When [AllowRSA1024]
+// is set, it's 1024 bits, even if [MinRSABits] is greater.
+func (s *NamespaceSettings) RSAMinBits() int {
+	bits := DefaultMinRSABits
+	if s != nil && s.MinRSABits > 0 {
+		bits = s.MinRSABits
+	}
+
+	if s != nil && s.AllowRSA1024 && bits > 1024 {
+		bits = 1024
+	}
+
+	return bits
+}
+
+// Effective returns the settings as they are enforced, with the defaults applied to the unset ones: an empty
+// [DefaultFirewallPolicy] is [FirewallPolicyAllow], [MinRSABits] is the one returned by [RSAMinBits] and
+// [MaxLiveMonitors] the one returned by [LiveMonitorsLimit].
+func (s *NamespaceSettings) Effective() NamespaceSettings {
+	var effective NamespaceSettings
+	if s != nil {
+		effective = *s
+	}
+
+	if effective.DefaultFirewallPolicy == "" {
+		effective.DefaultFirewallPolicy = FirewallPolicyAllow
+	}
+
+	effective.MinRSABits = s.RSAMinBits()
+	effective.MaxLiveMonitors = s.LiveMonitorsLimit()
+
+	return effective
+}
+
+const (
+	FirewallPolicyAllow = "allow"
+	FirewallPolicyDeny  = "deny"
+)
+
 type Member struct {
 	ID       string `json:"id,omitempty" bson:"id,omitempty"`
 	Username string `json:"username,omitempty" bson:"username,omitempty" validate:"username"`
 	Role     string `json:"role" bson:"role" validate:"required,oneof=administrator operator observer"`
+	// AddedAt is when the member was added to the namespace. It's nil for the namespace's owner and for the members
+	// added before it was recorded.
+	AddedAt *time.Time `json:"added_at,omitempty" bson:"added_at,omitempty"`
+	// Tags organize the namespace's members, like "team:infra" or "region:eu". A member has at most
+	// [MemberTagsMax] tags.
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	// AccessSchedule restricts the SSH connections made with the public keys the member created to its time windows,
+	// in addition to the namespace's schedule. A nil schedule doesn't restrict them further.
+	AccessSchedule *AccessSchedule `json:"access_schedule,omitempty" bson:"access_schedule,omitempty"`
 }
 
+// MemberTagsMax is the maximum number of tags a namespace's member can have.
+const MemberTagsMax = 10
+
 type NamespaceChanges struct {
-	Name                   string  `bson:"name,omitempty"`
-	SessionRecord          *bool   `bson:"settings.session_record,omitempty"`
-	ConnectionAnnouncement *string `bson:"settings.connection_announcement,omitempty"`
+	Name                       string                  `bson:"name,omitempty"`
+	SessionRecord              *bool                   `bson:"settings.session_record,omitempty"`
+	ConnectionAnnouncement     *string                 `bson:"settings.connection_announcement,omitempty"`
+	ExecAnnouncement           *bool                   `bson:"settings.exec_announcement,omitempty"`
+	DefaultFirewallPolicy      *string                 `bson:"settings.default_firewall_policy,omitempty"`
+	TransferSessionEnabled     *bool                   `bson:"settings.transfer_session_enabled,omitempty"`
+	AccessSchedule             *AccessSchedule         `bson:"settings.access_schedule,omitempty"`
+	MinRSABits                 *int                    `bson:"settings.min_rsa_bits,omitempty"`
+	AllowDSA                   *bool                   `bson:"settings.allow_dsa,omitempty"`
+	AllowRSA1024               *bool                   `bson:"settings.allow_rsa1024,omitempty"`
+	LiveMonitoringEnabled      *bool                   `bson:"settings.live_monitoring_enabled,omitempty"`
+	MaxLiveMonitors            *int                    `bson:"settings.max_live_monitors,omitempty"`
+	RecordingIdlePauseMS       *int                    `bson:"settings.recording_idle_pause_ms,omitempty"`
+	UsageReportSchedule        *string                 `bson:"settings.usage_report_schedule,omitempty"`
+	UsageReportEmail           *string                 `bson:"settings.usage_report_email,omitempty"`
+	MaxSessionDurationMinutes  *int                    `bson:"settings.max_session_duration_minutes,omitempty"`
+	MaxSessionKeepaliveEnabled *bool                   `bson:"settings.max_session_keepalive_enabled,omitempty"`
+	EnrollmentKey              *NamespaceEnrollmentKey `bson:"enrollment_key,omitempty"`
+	// NOTICE: a pointer to the map keeps the changes comparable and sets an empty map, clearing the variables.
+	DefaultEnvVars *map[string]string `bson:"settings.default_env_vars,omitempty"`
+	// PreviousName, when set, is appended to the namespace's name history on the same update, keeping only the newest
+	// [NamespacePreviousNamesLimit] entries.
+	PreviousName *NamespacePreviousName `bson:"-"`
 }
@@ -0,0 +1,22 @@
+package models
+
+const (
+	PlanFree       = "free"
+	PlanStarter    = "starter"
+	PlanEnterprise = "enterprise"
+)
+
+// Plan represents a tier that defines the limits applied to a user and to the namespaces it owns. For any limit, a
+// value less than zero means that there is no limit.
+type Plan struct {
+	// Name is the plan's unique identifier. It is the value stored in [User.PlanID].
+	Name string `json:"name" bson:"name"`
+	// MaxNamespaces is the maximum number of namespaces a user in this plan can own.
+	MaxNamespaces int `json:"max_namespaces" bson:"max_namespaces"`
+	// MaxDevices is the maximum number of devices of a namespace created by a user in this plan.
+	MaxDevices int `json:"max_devices" bson:"max_devices"`
+	// MaxMembers is the maximum number of members of a namespace created by a user in this plan.
+	MaxMembers int `json:"max_members" bson:"max_members"`
+	// Features is a list of features enabled for the plan.
+	Features []string `json:"features" bson:"features"`
+}
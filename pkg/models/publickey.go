@@ -19,6 +19,9 @@ type PublicKeyFields struct {
 	Name     string          `json:"name"`
 	Username string          `json:"username" bson:"username" validate:"regexp"`
 	Filter   PublicKeyFilter `json:"filter" bson:"filter" validate:"required"`
+	// AllowedIPs are the IP addresses and networks, in CIDR notation, the public key can be used from. When empty, the
+	// public key can be used from any IP.
+	AllowedIPs []string `json:"allowed_ips,omitempty" bson:"allowed_ips"`
 }
 
 func (p *PublicKeyFields) Validate() error {
@@ -34,13 +37,26 @@ func (p *PublicKeyFields) Validate() error {
 }
 
 type PublicKey struct {
-	Data            []byte    `json:"data"`
-	Fingerprint     string    `json:"fingerprint"`
-	CreatedAt       time.Time `json:"created_at" bson:"created_at"`
-	TenantID        string    `json:"tenant_id" bson:"tenant_id"`
+	Data        []byte    `json:"data"`
+	Fingerprint string    `json:"fingerprint"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	TenantID    string    `json:"tenant_id" bson:"tenant_id"`
+	// CreatedBy is the ID of the namespace member who created the public key. It's empty for the keys created before
+	// it was recorded.
+	CreatedBy string `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	// Source is where the public key was imported from, like [PublicKeySourceGitHub]. It's empty for the keys added
+	// directly.
+	Source          string `json:"source,omitempty" bson:"source,omitempty"`
 	PublicKeyFields `bson:",inline"`
 }
 
+const (
+	// PublicKeySourceGitHub is the source of the public keys imported from a GitHub user account.
+	PublicKeySourceGitHub = "github"
+	// PublicKeySourceGitLab is the source of the public keys imported from a GitLab user account.
+	PublicKeySourceGitLab = "gitlab"
+)
+
 type PublicKeyUpdate struct {
 	PublicKeyFields `bson:",inline"`
 }
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// APIRateLimitConfig is a token bucket limiting the rate of the API requests: every request takes a token from a bucket
+// holding up to BurstSize tokens, refilled with RequestsPerMinute tokens per minute.
+type APIRateLimitConfig struct {
+	RequestsPerMinute int `json:"requests_per_minute" bson:"requests_per_minute" validate:"required,min=1,max=100000"`
+	BurstSize         int `json:"burst_size" bson:"burst_size" validate:"required,min=1,max=100000"`
+}
+
+// APIRateLimitStatus is the outcome of evaluating a request against an API rate limit.
+type APIRateLimitStatus struct {
+	// Allowed reports whether the request is within the rate limit.
+	Allowed bool `json:"allowed"`
+	// Limit is the maximum number of requests that can be made at once.
+	Limit int `json:"limit"`
+	// Remaining is the number of requests that can still be made at once.
+	Remaining int `json:"remaining"`
+	// Reset is when the limit will be fully available again.
+	Reset time.Time `json:"reset"`
+}
@@ -25,6 +25,71 @@ type Session struct {
 	Type          string          `json:"type" bson:"type"`
 	Term          string          `json:"term" bson:"term"`
 	Position      SessionPosition `json:"position" bson:"position"`
+	// ViewCount is the number of times the session's recording was played.
+	ViewCount int64 `json:"view_count" bson:"view_count"`
+	// LastViewedAt is the last time the session's recording was played.
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty" bson:"last_viewed_at,omitempty"`
+	// StorageBackend is where the session's recording was exported to, like [SessionStorageBackendS3]. When empty, the
+	// recording is only on the database.
+	StorageBackend string `json:"storage_backend,omitempty" bson:"storage_backend,omitempty"`
+	// StorageLocation is the URL of the exported recording on its storage backend.
+	StorageLocation string `json:"storage_location,omitempty" bson:"storage_location,omitempty"`
+	// RecordingSummary summarizes the session's recording. It's set when the recording of a closed session is
+	// analyzed.
+	RecordingSummary *RecordingSummary `json:"recording_summary,omitempty" bson:"recording_summary,omitempty"`
+	// RecordUpload is the progress of the session's recording streamed to the server, used to resume the upload.
+	RecordUpload *RecordingUploadState `json:"-" bson:"record_upload,omitempty"`
+	// RecordingPausedDuration is how long, in milliseconds, the session's recording was paused while its client was
+	// idle.
+	RecordingPausedDuration int64 `json:"recording_paused_duration" bson:"recording_paused_duration,omitempty"`
+	// ClientFingerprint identifies the credential the session's client authenticated with: the fingerprint of its
+	// public key or [SessionClientFingerprintPassword].
+	ClientFingerprint string `json:"client_fingerprint,omitempty" bson:"client_fingerprint,omitempty"`
+	// MaxDurationExceeded reports whether the session was disconnected for lasting longer than the maximum session
+	// duration of its namespace.
+	MaxDurationExceeded bool `json:"max_duration_exceeded,omitempty" bson:"max_duration_exceeded,omitempty"`
+}
+
+// SessionClientFingerprintPassword is the client fingerprint of the sessions authenticated with a password.
+const SessionClientFingerprintPassword = "password"
+
+// SessionTransferTimeout is how long the client of a session has to answer a request to transfer it to another
+// namespace member.
+const SessionTransferTimeout = 30 * time.Second
+
+// SessionStorageBackendS3 is the storage backend of recordings exported to an S3-compatible object storage.
+const SessionStorageBackendS3 = "s3"
+
+// S3Config is the configuration to access a bucket on an S3-compatible object storage.
+type S3Config struct {
+	// Endpoint is the URL of the object storage. When empty, AWS S3 itself is used.
+	Endpoint  string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Region    string
+}
+
+// LiveSession is a session currently connected to the SSH server, reconciled with its state on the store.
+type LiveSession struct {
+	UID       string    `json:"uid"`
+	DeviceUID UID       `json:"device_uid"`
+	TenantID  string    `json:"tenant_id"`
+	Username  string    `json:"username"`
+	IPAddress string    `json:"ip_address"`
+	StartedAt time.Time `json:"started_at"`
+	// InMemory indicates the session is present in the SSH server's session registry.
+	InMemory bool `json:"in_memory"`
+	// Stored indicates the session is persisted as active on the store.
+	Stored bool `json:"stored"`
+}
+
+// SessionFrame is a frame of an active session's recording, delivered to its watchers as it's received.
+type SessionFrame struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+	Width   int       `json:"width"`
+	Height  int       `json:"height"`
 }
 
 type ActiveSession struct {
@@ -44,21 +109,88 @@ type RecordedSession struct {
 	Time     time.Time `json:"time" bson:"time,omitempty"`
 	Width    int       `json:"width" bson:"width,omitempty"`
 	Height   int       `json:"height" bson:"height,omitempty"`
+	// KeyID is the ID of the key the message was encrypted with, when the recordings are encrypted at rest.
+	KeyID string `json:"-" bson:"key_id,omitempty"`
+}
+
+// IdleGap is a pause between two frames of a recording long enough to consider the session idle. Its start and end
+// are offsets, in milliseconds, from the start of the recording.
+type IdleGap struct {
+	StartMS    int64 `json:"start_ms"`
+	EndMS      int64 `json:"end_ms"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// RecordingAnalysis describes how the time of a session's recording was spent.
+type RecordingAnalysis struct {
+	// TotalDuration is the time, in seconds, from the first to the last frame.
+	TotalDuration float64 `json:"total_duration"`
+	// ActiveDuration is the time, in seconds, of the recording that isn't in an idle gap.
+	ActiveDuration float64   `json:"active_duration"`
+	IdleGaps       []IdleGap `json:"idle_gaps"`
+	// CharactersTyped is an estimate of the characters typed by the user. As only the terminal's output is recorded,
+	// it counts the frames with a single printable character, which are the echo of a key press.
+	CharactersTyped int `json:"characters_typed"`
+	// BytesOutput is the number of bytes written to the terminal.
+	BytesOutput int `json:"bytes_output"`
+}
+
+// Summary returns the summary of the analysis shown with the session.
+func (a *RecordingAnalysis) Summary() *RecordingSummary {
+	return &RecordingSummary{
+		TotalDuration:  a.TotalDuration,
+		ActiveDuration: a.ActiveDuration,
+		IdleGaps:       len(a.IdleGaps),
+	}
+}
+
+// RecordingSummary is the summary of a [RecordingAnalysis].
+type RecordingSummary struct {
+	TotalDuration  float64 `json:"total_duration" bson:"total_duration"`
+	ActiveDuration float64 `json:"active_duration" bson:"active_duration"`
+	// IdleGaps is the number of idle gaps in the recording.
+	IdleGaps int `json:"idle_gaps" bson:"idle_gaps"`
+}
+
+// RecordingUploadState is the progress of a recording streamed to the server. As frames are only counted after being
+// stored, an interrupted upload is resumed from BytesReceived.
+type RecordingUploadState struct {
+	// BytesReceived is the number of bytes of the stream whose frames were stored.
+	BytesReceived int64 `json:"bytes_received" bson:"bytes_received"`
+	// FramesStored is the number of frames stored from the stream.
+	FramesStored int64 `json:"frames_stored" bson:"frames_stored"`
 }
 
 type Status struct {
 	Authenticated bool `json:"authenticated"`
 }
 
+// SessionRecordedTypeGap is the type of the frame written when the recording of a session resumes after being paused
+// while its client was idle.
+const SessionRecordedTypeGap = "gap"
+
 type SessionRecorded struct {
 	UID       string `json:"uid"`
 	Namespace string `json:"namespace" bson:"namespace"`
 	Message   string `json:"message" bson:"message"`
 	Width     int    `json:"width" bson:"width,omitempty"`
 	Height    int    `json:"height" bson:"height,omitempty"`
+	// Time is when the frame was recorded.
+	Time time.Time `json:"time" bson:"time,omitempty"`
+	// Type is empty for the frames with the session's output, or [SessionRecordedTypeGap] for the ones marking a
+	// pause of the recording.
+	Type string `json:"type,omitempty" bson:"type,omitempty"`
+	// DurationMS is how long, in milliseconds, the recording was paused before a [SessionRecordedTypeGap] frame.
+	DurationMS int64 `json:"duration_ms,omitempty" bson:"duration_ms,omitempty"`
+	// Channel identifies, within the session, the channel whose shell the frame was recorded from, so the shells of
+	// different channels of the same connection can be told apart. The first channel is 0.
+	Channel int `json:"channel,omitempty" bson:"channel,omitempty"`
 }
 
 type SessionUpdate struct {
-	Authenticated *bool   `json:"authenticated"`
-	Type          *string `json:"type"`
+	Authenticated           *bool   `json:"authenticated"`
+	Type                    *string `json:"type"`
+	RecordingPausedDuration *int64  `json:"recording_paused_duration"`
+	ClientFingerprint       *string `json:"client_fingerprint"`
+	MaxDurationExceeded     *bool   `json:"max_duration_exceeded"`
 }
@@ -18,13 +18,24 @@ type User struct {
 	CreatedAt      time.Time `json:"created_at" bson:"created_at"`
 	LastLogin      time.Time `json:"last_login" bson:"last_login"`
 	EmailMarketing bool      `json:"email_marketing" bson:"email_marketing"`
-	UserData       `bson:",inline"`
+	// PlanID is the name of the [Plan] assigned to the user. When empty, the user's limits are defined by its own
+	// attributes, like [User.MaxNamespaces].
+	PlanID string `json:"plan_id" bson:"plan_id,omitempty"`
+	// OnboardingCompleted reports whether the user went through the onboarding, shown once after the user is created.
+	// It's independent from [User.LastLogin], as the user may log in without completing it.
+	OnboardingCompleted bool `json:"onboarding_completed" bson:"onboarding_completed"`
+	// Timezone is the IANA name of the timezone, like "America/New_York", used to display dates to the user. When
+	// empty, dates are displayed in UTC.
+	Timezone string `json:"timezone" bson:"timezone,omitempty"`
+	UserData `bson:",inline"`
 	// MFA contains attributes related to a user's MFA settings. Use [UserMFA.Enabled] to
 	// check if MFA is active for the user.
 	//
 	// NOTE: MFA is available as a cloud-only feature and must be ignored in community.
 	MFA      UserMFA      `json:"mfa" bson:"mfa"`
 	Password UserPassword `bson:",inline"`
+	// DeletedAt is when the user deleted its account without erasing its data. A deleted user can't log in.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
 }
 
 type UserData struct {
@@ -99,6 +110,9 @@ type UserAuthResponse struct {
 	Email         string `json:"email"`
 	RecoveryEmail string `json:"recovery_email"`
 	MFA           bool   `json:"mfa"`
+	Timezone      string `json:"timezone"`
+	// OnboardingCompleted reports whether the user went through the onboarding.
+	OnboardingCompleted bool `json:"onboarding_completed"`
 }
 
 type UserAuthClaims struct {
@@ -108,6 +122,7 @@ type UserAuthClaims struct {
 	Role                 string `json:"role"`
 	Username             string `json:"name"`
 	MFA                  bool   `json:"mfa"`
+	Session              string `json:"session,omitempty"` // ID of the [UserSession] the token was issued for, if any.
 	AuthClaims           `mapstruct:",squash"`
 	jwt.RegisteredClaims `mapstruct:",squash"`
 }
@@ -138,13 +153,18 @@ type UserTokenRecover struct {
 // UserChanges specifies the attributes that can be updated for a user. Any zero values in this
 // struct must be ignored. If an attribute is a pointer type, its zero value is represented as `nil`.
 type UserChanges struct {
-	LastLogin     time.Time `bson:"last_login,omitempty"`
-	Name          string    `bson:"name,omitempty"`
-	Username      string    `bson:"username,omitempty"`
-	Email         string    `bson:"email,omitempty"`
-	RecoveryEmail string    `bson:"recovery_email,omitempty"`
-	Password      string    `bson:"password,omitempty"`
-	Confirmed     *bool     `bson:"confirmed,omitempty"`
+	LastLogin     time.Time  `bson:"last_login,omitempty"`
+	Name          string     `bson:"name,omitempty"`
+	Username      string     `bson:"username,omitempty"`
+	Email         string     `bson:"email,omitempty"`
+	RecoveryEmail string     `bson:"recovery_email,omitempty"`
+	Password      string     `bson:"password,omitempty"`
+	Confirmed     *bool      `bson:"confirmed,omitempty"`
+	PlanID        string     `bson:"plan_id,omitempty"`
+	Timezone      *string    `bson:"timezone,omitempty"`
+	DeletedAt     *time.Time `bson:"deleted_at,omitempty"`
+	// OnboardingCompleted is a pointer, like Confirmed, so false can be set.
+	OnboardingCompleted *bool `bson:"onboarding_completed,omitempty"`
 }
 
 // UserConflicts holds user attributes that must be unique for each itam and can be utilized in queries
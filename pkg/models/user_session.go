@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// UserSession is a login of a user, from which the user's tokens are issued. Revoking it logs out every token issued
+// from it.
+type UserSession struct {
+	ID        string `json:"id" bson:"_id"`
+	UserID    string `json:"user_id" bson:"user_id"`
+	IPAddress string `json:"ip_address" bson:"ip_address"`
+	UserAgent string `json:"user_agent" bson:"user_agent"`
+	// Position is the approximate location of the IP address the user logged in from.
+	Position     SessionPosition `json:"position" bson:"position"`
+	CreatedAt    time.Time       `json:"created_at" bson:"created_at"`
+	LastActivity time.Time       `json:"last_activity" bson:"last_activity"`
+	// ExpiresAt is the date from which the session's tokens are no longer accepted.
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// UserSessionChanges specifies the attributes that can be updated for a user session. Any zero values in this struct
+// must be ignored.
+type UserSessionChanges struct {
+	LastActivity time.Time `bson:"last_activity,omitempty"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// WebhookEventType is the kind of action a webhook event notifies about.
+type WebhookEventType string
+
+const (
+	// WebhookEventDeviceAccepted is sent when a device is accepted on the namespace.
+	WebhookEventDeviceAccepted WebhookEventType = "device.accepted"
+	// WebhookEventSessionStarted is sent when a session is started on one of the namespace's devices.
+	WebhookEventSessionStarted WebhookEventType = "session.started"
+	// WebhookEventMemberAdded is sent when a member is added to the namespace.
+	WebhookEventMemberAdded WebhookEventType = "member.added"
+)
+
+// WebhookEventTypes are the kinds of events a webhook can subscribe to.
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventDeviceAccepted,
+	WebhookEventSessionStarted,
+	WebhookEventMemberAdded,
+}
+
+// NamespaceWebhook is the URL the namespace's events are posted to, signed with its secret.
+type NamespaceWebhook struct {
+	URL string `json:"url" bson:"url"`
+	// Secret is the key of the HMAC-SHA256 signature sent along with each event, so the receiver can verify it was
+	// sent by ShellHub.
+	Secret string `json:"-" bson:"secret"`
+	// Events are the kinds of events posted to the webhook.
+	Events    []WebhookEventType `json:"events" bson:"events"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Subscribes reports whether the events of the specified kind are posted to the webhook.
+func (w *NamespaceWebhook) Subscribes(kind WebhookEventType) bool {
+	return w != nil && slices.Contains(w.Events, kind)
+}
+
+// WebhookEvent is the body, encoded as JSON, posted to a webhook.
+type WebhookEvent struct {
+	// ID identifies the event, being the same on every attempt to deliver it, so the receiver can ignore the
+	// duplicated ones.
+	ID       string           `json:"id" bson:"id"`
+	Type     WebhookEventType `json:"type" bson:"type"`
+	TenantID string           `json:"tenant_id" bson:"tenant_id"`
+	// Data holds details specific to the event's type, like the UID of the accepted device.
+	Data      map[string]string `json:"data,omitempty" bson:"data,omitempty"`
+	CreatedAt time.Time         `json:"created_at" bson:"created_at"`
+}
+
+// WebhookDeadLetter is an event that couldn't be delivered to the namespace's webhook, kept so it can be inspected.
+type WebhookDeadLetter struct {
+	TenantID string       `json:"tenant_id" bson:"tenant_id"`
+	URL      string       `json:"url" bson:"url"`
+	Event    WebhookEvent `json:"event" bson:"event"`
+	// Attempts is the number of times the delivery was attempted.
+	Attempts int `json:"attempts" bson:"attempts"`
+	// Error is the reason the last attempt failed.
+	Error     string    `json:"error" bson:"error"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
@@ -0,0 +1,96 @@
+// Package recording encodes and decodes the frames recorded on a session in a length-prefixed binary format, allowing
+// long recordings to be streamed without loading them in memory.
+//
+// Each frame is a big-endian uint32 with the length of its body, followed by the body: the frame's time as big-endian
+// int64 Unix nanoseconds, the terminal's width and height as big-endian uint16 and, in the remaining bytes, the data
+// written to the terminal.
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+const (
+	// PrefixSize is the size, in bytes, of the length prefixed to each frame.
+	PrefixSize = 4
+	// HeaderSize is the size, in bytes, of the fixed fields of a frame's body.
+	HeaderSize = 12
+	// MaxFrameSize is the default maximum size, in bytes, of a frame's body.
+	MaxFrameSize = 1 << 20
+)
+
+var (
+	ErrFrameTooLarge = errors.New("frame is larger than the maximum frame size")
+	ErrFrameTooSmall = errors.New("frame is smaller than its header")
+)
+
+// WriteFrame encodes a frame to w.
+func WriteFrame(w io.Writer, frame *models.RecordedSession) error {
+	buf := make([]byte, PrefixSize+HeaderSize+len(frame.Message))
+	binary.BigEndian.PutUint32(buf[0:], uint32(HeaderSize+len(frame.Message)))
+	binary.BigEndian.PutUint64(buf[4:], uint64(frame.Time.UnixNano()))
+	binary.BigEndian.PutUint16(buf[12:], uint16(frame.Width))
+	binary.BigEndian.PutUint16(buf[14:], uint16(frame.Height))
+	copy(buf[16:], frame.Message)
+
+	_, err := w.Write(buf)
+
+	return err
+}
+
+// Decoder reads frames from a stream, reusing a single buffer as large as the biggest frame read so far.
+type Decoder struct {
+	reader  *bufio.Reader
+	maxSize uint32
+	buf     []byte
+}
+
+// NewDecoder creates a [Decoder] reading from r, refusing frames whose body is larger than maxSize bytes.
+func NewDecoder(r io.Reader, maxSize uint32) *Decoder {
+	return &Decoder{reader: bufio.NewReader(r), maxSize: maxSize}
+}
+
+// Decode reads the next frame, returning it and the number of bytes it took on the stream. It returns [io.EOF] when
+// the stream ends between frames and [io.ErrUnexpectedEOF] when it ends in the middle of one.
+func (d *Decoder) Decode() (*models.RecordedSession, int64, error) {
+	var prefix [PrefixSize]byte
+	if _, err := io.ReadFull(d.reader, prefix[:]); err != nil {
+		return nil, 0, err
+	}
+
+	size := binary.BigEndian.Uint32(prefix[:])
+	switch {
+	case size > d.maxSize:
+		return nil, 0, ErrFrameTooLarge
+	case size < HeaderSize:
+		return nil, 0, ErrFrameTooSmall
+	}
+
+	if uint32(cap(d.buf)) < size {
+		d.buf = make([]byte, size)
+	}
+
+	body := d.buf[:size]
+	if _, err := io.ReadFull(d.reader, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, 0, err
+	}
+
+	frame := &models.RecordedSession{
+		Time:    time.Unix(0, int64(binary.BigEndian.Uint64(body[0:]))).UTC(),
+		Width:   int(binary.BigEndian.Uint16(body[8:])),
+		Height:  int(binary.BigEndian.Uint16(body[10:])),
+		Message: string(body[HeaderSize:]),
+	}
+
+	return frame, int64(PrefixSize + size), nil
+}
@@ -0,0 +1,64 @@
+package recording
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder(t *testing.T) {
+	start := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("decodes the frames written", func(t *testing.T) {
+		frames := []*models.RecordedSession{
+			{Message: "ls -la\r\n", Time: start, Width: 80, Height: 24},
+			{Message: "", Time: start.Add(time.Millisecond)},
+			{Message: string(bytes.Repeat([]byte("a"), 4096)), Time: start.Add(time.Second), Width: 120, Height: 40},
+		}
+
+		buf := new(bytes.Buffer)
+		for _, frame := range frames {
+			require.NoError(t, WriteFrame(buf, frame))
+		}
+
+		decoder := NewDecoder(buf, MaxFrameSize)
+		for _, expected := range frames {
+			frame, n, err := decoder.Decode()
+			require.NoError(t, err)
+			assert.Equal(t, expected, frame)
+			assert.Equal(t, int64(PrefixSize+HeaderSize+len(expected.Message)), n)
+		}
+
+		_, _, err := decoder.Decode()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("fails when the frame is larger than the maximum", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteFrame(buf, &models.RecordedSession{Message: "abcd", Time: start}))
+
+		_, _, err := NewDecoder(buf, HeaderSize+3).Decode()
+		assert.Equal(t, ErrFrameTooLarge, err)
+	})
+
+	t.Run("fails when the frame is smaller than its header", func(t *testing.T) {
+		_, _, err := NewDecoder(bytes.NewReader([]byte{0, 0, 0, 4, 1, 2, 3, 4}), MaxFrameSize).Decode()
+		assert.Equal(t, ErrFrameTooSmall, err)
+	})
+
+	t.Run("fails when the stream ends in the middle of a frame", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteFrame(buf, &models.RecordedSession{Message: "abcd", Time: start}))
+
+		_, _, err := NewDecoder(bytes.NewReader(buf.Bytes()[:PrefixSize]), MaxFrameSize).Decode()
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+		_, _, err = NewDecoder(bytes.NewReader(buf.Bytes()[:2]), MaxFrameSize).Decode()
+		assert.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"strings"
+	"time"
 	"unicode"
 
 	"github.com/go-playground/validator/v10"
@@ -36,8 +38,27 @@ const (
 	UserPasswordTag = "password"
 	// DeviceNameTag contains the rule to validate the device's name.
 	DeviceNameTag = "device_name"
+	// TimezoneTag contains the rule to validate an IANA timezone name.
+	TimezoneTag = "timezone"
 )
 
+// ErrTimezoneInvalid is returned when a timezone isn't a valid IANA timezone name.
+var ErrTimezoneInvalid = errors.New("the timezone must be a valid IANA timezone name, like America/New_York")
+
+// ValidateTimezone checks if tz is an IANA timezone name known by [time.LoadLocation]. An empty value is valid and
+// means UTC. The "Local" timezone is rejected as it depends on the server where it is loaded.
+func ValidateTimezone(tz string) error {
+	if strings.EqualFold(tz, "Local") {
+		return ErrTimezoneInvalid
+	}
+
+	if _, err := time.LoadLocation(tz); err != nil {
+		return ErrTimezoneInvalid
+	}
+
+	return nil
+}
+
 // Rules is a slice that contains all validation rules.
 var Rules = []Rule{
 	{
@@ -77,6 +98,13 @@ var Rules = []Rule{
 		},
 		Error: fmt.Errorf("the device name can only contain `_`, `-` and alpha numeric characters"),
 	},
+	{
+		Tag: TimezoneTag,
+		Handler: func(field validator.FieldLevel) bool {
+			return ValidateTimezone(field.Field().String()) == nil
+		},
+		Error: ErrTimezoneInvalid,
+	},
 	// api-key_name reports whether a given string is a valid name for an api key or not. A valid
 	// value must be more than 3 characters, less than 20 and does not contains any whitespace.
 	{
@@ -260,3 +260,58 @@ func TestDeviceName(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTimezone(t *testing.T) {
+	tests := []struct {
+		description string
+		value       string
+		expected    error
+	}{
+		{
+			description: "success when the timezone is empty",
+			value:       "",
+			expected:    nil,
+		},
+		{
+			description: "success when the timezone is UTC",
+			value:       "UTC",
+			expected:    nil,
+		},
+		{
+			description: "success when the timezone is an IANA timezone name",
+			value:       "America/New_York",
+			expected:    nil,
+		},
+		{
+			description: "failed when the timezone is Local",
+			value:       "Local",
+			expected:    ErrTimezoneInvalid,
+		},
+		{
+			description: "failed when the timezone is local",
+			value:       "local",
+			expected:    ErrTimezoneInvalid,
+		},
+		{
+			description: "failed when the timezone is unknown",
+			value:       "Mars/Olympus_Mons",
+			expected:    ErrTimezoneInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ValidateTimezone(tt.value))
+
+			data := struct {
+				Timezone string `validate:"timezone"`
+			}{
+				Timezone: tt.value,
+			}
+
+			ok, _ := New().Struct(data)
+
+			assert.Equal(t, tt.expected == nil, ok)
+		})
+	}
+}
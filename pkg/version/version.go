@@ -0,0 +1,39 @@
+// Package version holds the build information of ShellHub's services, injected at build time using `-ldflags` (e.g:
+// `go build -ldflags "-X github.com/shellhub-io/shellhub/pkg/version.Version=1.2.3"`).
+package version
+
+// Unknown is reported for the build information not injected at build time.
+const Unknown = "unknown"
+
+var (
+	// Version is the ShellHub version the service was built from.
+	Version string
+	// GitCommit is the hash of the Git commit the service was built from.
+	GitCommit string
+	// BuildDate is the date, in RFC 3339, the service was built.
+	BuildDate string
+)
+
+// Info is the build information of a service.
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildDate string `json:"build_date"`
+}
+
+// Get returns the build information of the running service, reporting [Unknown] for what wasn't injected at build time.
+func Get() Info {
+	or := func(value string) string {
+		if value == "" {
+			return Unknown
+		}
+
+		return value
+	}
+
+	return Info{
+		Version:   or(Version),
+		GitCommit: or(GitCommit),
+		BuildDate: or(BuildDate),
+	}
+}
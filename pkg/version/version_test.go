@@ -0,0 +1,40 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	cases := []struct {
+		description string
+		version     string
+		gitCommit   string
+		buildDate   string
+		expected    Info
+	}{
+		{
+			description: "reports unknown when nothing was injected",
+			expected:    Info{Version: Unknown, GitCommit: Unknown, BuildDate: Unknown},
+		},
+		{
+			description: "reports the injected build information",
+			version:     "v0.16.0",
+			gitCommit:   "385e64d",
+			buildDate:   "2024-06-01T12:00:00Z",
+			expected:    Info{Version: "v0.16.0", GitCommit: "385e64d", BuildDate: "2024-06-01T12:00:00Z"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			Version, GitCommit, BuildDate = tc.version, tc.gitCommit, tc.buildDate
+			t.Cleanup(func() {
+				Version, GitCommit, BuildDate = "", "", ""
+			})
+
+			assert.Equal(t, tc.expected, Get())
+		})
+	}
+}
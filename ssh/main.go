@@ -1,14 +1,19 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 	"runtime"
 	"time"
 
 	"github.com/labstack/echo-contrib/pprof"
+	"github.com/labstack/echo/v4"
 	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	"github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/envs"
 	"github.com/shellhub-io/shellhub/pkg/loglevel"
+	"github.com/shellhub-io/shellhub/ssh/pkg/magickey"
+	"github.com/shellhub-io/shellhub/ssh/pkg/sni"
 	"github.com/shellhub-io/shellhub/ssh/pkg/tunnel"
 	"github.com/shellhub-io/shellhub/ssh/server"
 	"github.com/shellhub-io/shellhub/ssh/web"
@@ -28,6 +33,27 @@ type Envs struct {
 	// Agents 0.5.x or earlier do not validate the public key request and may panic.
 	// Please refer to: https://github.com/shellhub-io/shellhub/issues/3453
 	AllowPublickeyAccessBelow060 bool `env:"ALLOW_PUBLIC_KEY_ACCESS_BELLOW_0_6_0,default=false"`
+	// MaxAgentChannels is the maximum number of channels a single connection can open on the agent at the same time,
+	// protecting the agent from clients that try to exhaust its resources. When less than one, there is no limit.
+	MaxAgentChannels int `env:"MAX_AGENT_CHANNELS,default=10"`
+	// SessionResumeGrace is how long a shell is kept running on the agent after its client drops, waiting for the same
+	// user to resume it. When zero, sessions cannot be resumed.
+	SessionResumeGrace time.Duration `env:"SESSION_RESUME_GRACE,default=0s"`
+	// SNIRoutingEnabled wraps the SSH connections in TLS, routing each one to the namespace addressed by the server
+	// name presented on its handshake, like "{namespace}.ssh.shellhub.io".
+	SNIRoutingEnabled bool   `env:"SNI_ROUTING_ENABLED,default=false"`
+	SNIDomain         string `env:"SNI_DOMAIN,default=ssh.shellhub.io"`
+	SNICertificate    string `env:"SNI_CERTIFICATE,default=/var/run/secrets/sni.crt"`
+	SNIKey            string `env:"SNI_KEY,default=/var/run/secrets/sni.key"`
+	// PubKeyEvalCacheEnabled caches, on Redis, whether a public key is allowed to access a device with a username,
+	// avoiding asking the API on every authentication with it.
+	PubKeyEvalCacheEnabled bool `env:"PUBKEY_EVAL_CACHE_ENABLED,default=false"`
+	// TenantRoutingEnabled sends the requests made on behalf of a tenant to the API server set for it on Redis at
+	// "tenant:{tenantID}:api_url", falling back to the default one when it isn't set.
+	TenantRoutingEnabled bool `env:"TENANT_ROUTING_ENABLED,default=false"`
+	// WebRTCEnabled allows the web terminals to be connected through a WebRTC data channel, instead of the WebSocket,
+	// lowering the latency of their input and output.
+	WebRTCEnabled bool `env:"WEBRTC_ENABLED,default=false"`
 }
 
 func main() {
@@ -37,15 +63,44 @@ func main() {
 		log.WithError(err).Fatal("Failed to load environment variables")
 	}
 
+	opts := []internalclient.Opt{}
+	if env.TenantRoutingEnabled {
+		tenantRouter, err := internalclient.NewDynamicRouter(env.RedisURI, internalclient.DefaultAPIURL)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create the tenant router")
+		}
+
+		opts = append(opts, internalclient.WithTenantRouter(tenantRouter))
+	}
+
 	tun := tunnel.NewTunnel("/ssh/connection", "/ssh/revdial")
-	tun.API = internalclient.NewClientWithAsynq(env.RedisURI)
+	tun.API = internalclient.NewClientWithAsynq(env.RedisURI, opts...)
 	if tun.API == nil {
 		log.Fatal("failed to create internal client")
 	}
 
 	router := tun.GetRouter()
 
-	web.NewSSHServerBridge(router)
+	web.NewSSHServerBridge(router, tun.API, env.WebRTCEnabled)
+
+	// NOTICE: the gateway does not expose the internal routes, so only the services inside the network can rotate the
+	// magic key.
+	router.POST("/internal/magickey/rotate", func(c echo.Context) error {
+		version, err := magickey.Rotate()
+		if err != nil {
+			log.WithError(err).Error("failed to rotate the magic key")
+
+			if errors.Is(err, magickey.ErrRotationStale) {
+				return c.NoContent(http.StatusConflict)
+			}
+
+			return c.NoContent(http.StatusInternalServerError)
+		}
+
+		log.WithField("version", version).Info("magic key rotated")
+
+		return c.JSON(http.StatusOK, map[string]int{"version": version})
+	})
 
 	if envs.IsDevelopment() {
 		runtime.SetBlockProfileRate(1)
@@ -56,9 +111,32 @@ func main() {
 
 	go http.ListenAndServe(":8080", router) // nolint:errcheck
 
-	log.Fatal(server.NewServer(&server.Options{
+	srv := server.NewServer(&server.Options{
 		ConnectTimeout:               env.ConnectTimeout,
 		RecordURL:                    env.RecordURL,
 		AllowPublickeyAccessBelow060: env.AllowPublickeyAccessBelow060,
-	}, tun.Tunnel).ListenAndServe())
+		MaxAgentChannels:             env.MaxAgentChannels,
+		SessionResumeGrace:           env.SessionResumeGrace,
+		SNIRoutingEnabled:            env.SNIRoutingEnabled,
+		SNIDomain:                    env.SNIDomain,
+		SNICertificate:               env.SNICertificate,
+		SNIKey:                       env.SNIKey,
+	}, tun.Tunnel).WithInternalClient(tun.API)
+
+	if env.SNIRoutingEnabled || env.PubKeyEvalCacheEnabled {
+		cache, err := cache.NewRedisCache(env.RedisURI, 0)
+		if err != nil {
+			log.WithError(err).Fatal("failed to connect to redis cache")
+		}
+
+		if env.SNIRoutingEnabled {
+			srv.WithSNIResolver(sni.NewResolver(tun.API, cache, env.SNIDomain))
+		}
+
+		if env.PubKeyEvalCacheEnabled {
+			srv.WithPublicKeyEvalCache(cache)
+		}
+	}
+
+	log.Fatal(srv.ListenAndServe())
 }
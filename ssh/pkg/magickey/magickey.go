@@ -1,29 +1,289 @@
 package magickey
 
 import (
+	"bytes"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/envs"
 	log "github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	// TypeRSA is the RSA 2048 magic key type.
+	TypeRSA = "rsa"
+	// TypeED25519 is the ED25519 magic key type.
+	TypeED25519 = "ed25519"
+)
+
+type config struct {
+	// Type is the type of the magic key used by the web terminal to authenticate on the SSH server.
+	Type string `env:"MAGIC_KEY_TYPE,default=rsa"`
+	// AcceptLegacy allows the RSA magic key to keep being accepted when the type is not [TypeRSA], what eases the
+	// migration between key types.
+	AcceptLegacy bool `env:"MAGIC_KEY_ACCEPT_LEGACY,default=true"`
+	// Store is the directory holding the versioned RSA magic keys, usually a mounted secret. Each key is a PEM file
+	// named after its version, like "3.pem", and the highest version is the one in use. When empty, the key is
+	// generated when first used and a rotation generates a new one.
+	Store string `env:"MAGIC_KEY_STORE"`
+	// RotationWindow is how long the RSA magic key replaced by a rotation keeps being accepted.
+	RotationWindow time.Duration `env:"MAGIC_KEY_ROTATION_WINDOW,default=5m"`
+}
+
+var (
+	ErrStoreEmpty    = errors.New("the magic key store has no versioned key")
+	ErrKeyInvalid    = errors.New("the magic key is not a PEM encoded RSA private key")
+	ErrRotationStale = errors.New("the magic key store has no version newer than the one in use")
 )
 
 var lock = &sync.Mutex{}
 
 var magicKey *rsa.PrivateKey
 
+// version is the version of the RSA magic key in use. Keys generated by the process are versioned sequentially.
+var version int
+
+// previous is the RSA magic key replaced by the last rotation, accepted until previousUntil.
+var (
+	previous      *rsa.PrivateKey
+	previousUntil time.Time
+)
+
+var magicKeyED25519 ed25519.PrivateKey
+
+var conf *config
+
+func init() {
+	var err error
+
+	conf, err = envs.Parse[config]()
+	if err != nil {
+		log.WithError(err).Error("failed to parse the environment variables")
+
+		conf = &config{Type: TypeRSA, AcceptLegacy: true, RotationWindow: 5 * time.Minute}
+	}
+
+	if conf.Type != TypeRSA && conf.Type != TypeED25519 {
+		log.WithField("type", conf.Type).Warn("invalid magic key type, using rsa instead")
+
+		conf.Type = TypeRSA
+	}
+}
+
+// GetRerefence returns the RSA magic key. Besides the authentication, it is also used to sign tokens and to encrypt
+// data, so it is always available whatever is the configured type.
 func GetRerefence() *rsa.PrivateKey {
+	lock.Lock()
+	defer lock.Unlock()
+
 	if magicKey == nil {
-		lock.Lock()
-		defer lock.Unlock()
+		key, v, err := next()
+		if err != nil {
+			log.WithError(err).Fatal()
+		}
+
+		magicKey, version = key, v
+	}
+
+	return magicKey
+}
 
+// next returns the key that should be in use and its version: the latest one from the store or, when there is no
+// store, a newly generated one.
+func next() (*rsa.PrivateKey, int, error) {
+	if conf.Store == "" {
 		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return key, version + 1, nil
+	}
+
+	return latest(conf.Store)
+}
+
+// latest reads the key with the highest version from the store at dir.
+func latest(dir string) (*rsa.PrivateKey, int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	highest := 0
+	for _, entry := range entries {
+		name, found := strings.CutSuffix(entry.Name(), ".pem")
+		if !found || entry.IsDir() {
+			continue
+		}
+
+		if v, err := strconv.Atoi(name); err == nil && v > highest {
+			highest = v
+		}
+	}
+
+	if highest == 0 {
+		return nil, 0, ErrStoreEmpty
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, strconv.Itoa(highest)+".pem"))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, 0, ErrKeyInvalid
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, highest, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, 0, ErrKeyInvalid
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, 0, ErrKeyInvalid
+	}
+
+	return key, highest, nil
+}
+
+// Rotate replaces the RSA magic key by the latest version from the store or, when there is no store, by a newly
+// generated key, returning the new version. The replaced key keeps being accepted for the rotation window, so clients
+// still holding it are not refused while the new one is rolled out.
+func Rotate() (int, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	key, v, err := next()
+	if err != nil {
+		return 0, err
+	}
+
+	if magicKey != nil && v <= version {
+		return 0, ErrRotationStale
+	}
+
+	previous, previousUntil = magicKey, clock.Now().Add(conf.RotationWindow)
+	magicKey, version = key, v
+
+	return version, nil
+}
+
+// Version returns the version of the RSA magic key in use.
+func Version() int {
+	GetRerefence()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	return version
+}
+
+// getPrevious returns the RSA magic key replaced by the last rotation while its rotation window is open.
+func getPrevious() *rsa.PrivateKey {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if previous == nil || !clock.Now().Before(previousUntil) {
+		return nil
+	}
+
+	return previous
+}
+
+func getED25519() ed25519.PrivateKey {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if magicKeyED25519 == nil {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
 		if err != nil {
 			log.WithError(err).Fatal()
 		}
 
-		magicKey = key
+		magicKeyED25519 = key
 	}
 
-	return magicKey
+	return magicKeyED25519
+}
+
+// Signer returns a signer for the magic key of the configured type.
+func Signer() (gossh.Signer, error) {
+	if conf.Type == TypeED25519 {
+		return gossh.NewSignerFromKey(getED25519())
+	}
+
+	return gossh.NewSignerFromKey(GetRerefence())
+}
+
+// PublicKeys returns the public keys of all magic keys currently accepted, including the RSA magic key replaced by the
+// last rotation while its rotation window is open.
+func PublicKeys() ([]gossh.PublicKey, error) {
+	keys := make([]gossh.PublicKey, 0, 2)
+
+	if conf.Type == TypeED25519 {
+		key, err := gossh.NewPublicKey(getED25519().Public())
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+
+		if !conf.AcceptLegacy {
+			return keys, nil
+		}
+	}
+
+	key, err := gossh.NewPublicKey(&GetRerefence().PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keys = append(keys, key)
+
+	if old := getPrevious(); old != nil {
+		key, err := gossh.NewPublicKey(&old.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// IsMagic reports whether the public key belongs to any of the accepted magic keys. The comparison is made over the
+// key's wire format, so it is independent of the key type.
+func IsMagic(key gossh.PublicKey) (bool, error) {
+	keys, err := PublicKeys()
+	if err != nil {
+		return false, err
+	}
+
+	for _, magic := range keys {
+		if magic.Type() == key.Type() && bytes.Equal(magic.Marshal(), key.Marshal()) {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
@@ -0,0 +1,199 @@
+package magickey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmocks "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestIsMagic(t *testing.T) {
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := gossh.NewPublicKey(other.Public())
+	require.NoError(t, err)
+
+	rsaKey, err := gossh.NewPublicKey(&GetRerefence().PublicKey)
+	require.NoError(t, err)
+
+	ed25519Key, err := gossh.NewPublicKey(getED25519().Public())
+	require.NoError(t, err)
+
+	cases := []struct {
+		description string
+		conf        config
+		key         gossh.PublicKey
+		expected    bool
+	}{
+		{
+			description: "fails when key is not a magic key",
+			conf:        config{Type: TypeED25519, AcceptLegacy: true},
+			key:         otherKey,
+			expected:    false,
+		},
+		{
+			description: "fails when key is the ed25519 magic key but type is rsa",
+			conf:        config{Type: TypeRSA, AcceptLegacy: true},
+			key:         ed25519Key,
+			expected:    false,
+		},
+		{
+			description: "fails when key is the rsa magic key but legacy is not accepted",
+			conf:        config{Type: TypeED25519, AcceptLegacy: false},
+			key:         rsaKey,
+			expected:    false,
+		},
+		{
+			description: "succeeds when key is the rsa magic key and type is rsa",
+			conf:        config{Type: TypeRSA, AcceptLegacy: false},
+			key:         rsaKey,
+			expected:    true,
+		},
+		{
+			description: "succeeds when key is the rsa magic key and legacy is accepted",
+			conf:        config{Type: TypeED25519, AcceptLegacy: true},
+			key:         rsaKey,
+			expected:    true,
+		},
+		{
+			description: "succeeds when key is the ed25519 magic key and type is ed25519",
+			conf:        config{Type: TypeED25519, AcceptLegacy: false},
+			key:         ed25519Key,
+			expected:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			conf = &tc.conf
+
+			ok, err := IsMagic(tc.key)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func writeKey(t *testing.T, dir string, version int) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, strconv.Itoa(version)+".pem"), data, 0o600))
+
+	return key
+}
+
+func TestRotate(t *testing.T) {
+	now := time.Now()
+
+	clockMock := new(clockmocks.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	dir := t.TempDir()
+	first := writeKey(t, dir, 1)
+
+	conf = &config{Type: TypeRSA, AcceptLegacy: true, Store: dir, RotationWindow: time.Minute}
+	magicKey, version, previous = nil, 0, nil
+
+	isMagic := func(key *rsa.PrivateKey) bool {
+		pub, err := gossh.NewPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+
+		ok, err := IsMagic(pub)
+		require.NoError(t, err)
+
+		return ok
+	}
+
+	// The latest key of the store is loaded when first used.
+	assert.Equal(t, first, GetRerefence())
+	assert.Equal(t, 1, Version())
+
+	_, err := Rotate()
+	assert.ErrorIs(t, err, ErrRotationStale)
+
+	second := writeKey(t, dir, 2)
+
+	v, err := Rotate()
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, second, GetRerefence())
+
+	// Both keys are accepted during the rotation window.
+	assert.True(t, isMagic(first))
+	assert.True(t, isMagic(second))
+
+	clockMock.ExpectedCalls = nil
+	clockMock.On("Now").Return(now.Add(time.Minute))
+
+	// Only the new key is accepted once the window closes.
+	assert.False(t, isMagic(first))
+	assert.True(t, isMagic(second))
+}
+
+func TestRotate_without_store(t *testing.T) {
+	conf = &config{Type: TypeRSA, AcceptLegacy: true, RotationWindow: time.Minute}
+	magicKey, version, previous = nil, 0, nil
+
+	first := GetRerefence()
+	assert.Equal(t, 1, Version())
+
+	v, err := Rotate()
+	require.NoError(t, err)
+	assert.Equal(t, 2, v)
+	assert.NotEqual(t, first, GetRerefence())
+
+	pub, err := gossh.NewPublicKey(&first.PublicKey)
+	require.NoError(t, err)
+
+	ok, err := IsMagic(pub)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestLatest(t *testing.T) {
+	t.Run("fails when the store is empty", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("keys"), 0o600))
+
+		_, _, err := latest(dir)
+		assert.ErrorIs(t, err, ErrStoreEmpty)
+	})
+
+	t.Run("fails when the key is not a RSA private key", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "1.pem"), []byte("invalid"), 0o600))
+
+		_, _, err := latest(dir)
+		assert.ErrorIs(t, err, ErrKeyInvalid)
+	})
+
+	t.Run("succeeds reading the highest version", func(t *testing.T) {
+		dir := t.TempDir()
+		writeKey(t, dir, 2)
+		expected := writeKey(t, dir, 10)
+		writeKey(t, dir, 9)
+
+		key, v, err := latest(dir)
+		require.NoError(t, err)
+		assert.Equal(t, 10, v)
+		assert.Equal(t, expected, key)
+	})
+}
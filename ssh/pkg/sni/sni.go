@@ -0,0 +1,128 @@
+// Package sni routes SSH connections wrapped in TLS to a namespace using the server name the client presents on the
+// handshake, e.g. "dev.ssh.shellhub.io" is routed to the namespace "dev".
+package sni
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	"github.com/shellhub-io/shellhub/pkg/cache"
+)
+
+// DefaultDomain is the domain under which the namespaces are exposed by default.
+const DefaultDomain = "ssh.shellhub.io"
+
+// TTL is how long a server name resolved to a tenant is kept in the cache.
+const TTL = 60 * time.Second
+
+var (
+	ErrServerNameMissing = errors.New("the client did not present a server name")
+	ErrServerNameInvalid = errors.New("the server name does not belong to the domain")
+	ErrNamespaceNotFound = errors.New("there is no namespace for the server name")
+)
+
+// Resolver resolves the server names presented on TLS handshakes to tenants.
+type Resolver struct {
+	api    internalclient.Client
+	cache  cache.Cache
+	domain string
+
+	// tenants holds the tenant resolved for each connection until it is taken by [Resolver.Tenant].
+	tenants sync.Map
+}
+
+// NewResolver creates a [Resolver] for the server names under domain. When domain is empty, [DefaultDomain] is used.
+func NewResolver(api internalclient.Client, cache cache.Cache, domain string) *Resolver {
+	if domain == "" {
+		domain = DefaultDomain
+	}
+
+	return &Resolver{
+		api:    api,
+		cache:  cache,
+		domain: strings.ToLower(strings.Trim(domain, ".")),
+	}
+}
+
+// namespace extracts the namespace's name from a server name in the form "{namespace}.{domain}".
+func (r *Resolver) namespace(serverName string) (string, error) {
+	if serverName == "" {
+		return "", ErrServerNameMissing
+	}
+
+	name, found := strings.CutSuffix(strings.ToLower(serverName), "."+r.domain)
+	if !found || name == "" || strings.Contains(name, ".") {
+		return "", ErrServerNameInvalid
+	}
+
+	return name, nil
+}
+
+// Resolve returns the tenant of the namespace addressed by serverName.
+func (r *Resolver) Resolve(ctx context.Context, serverName string) (string, error) {
+	name, err := r.namespace(serverName)
+	if err != nil {
+		return "", err
+	}
+
+	key := "sni/" + name
+
+	var tenant string
+	// NOTICE: failing to read from the cache is not fatal, as the namespace can still be looked up from the API.
+	if err := r.cache.Get(ctx, key, &tenant); err == nil && tenant != "" {
+		return tenant, nil
+	}
+
+	namespace, err := r.api.NamespaceLookupByName(name)
+	if err != nil {
+		if errors.Is(err, internalclient.ErrNotFound) {
+			return "", ErrNamespaceNotFound
+		}
+
+		return "", err
+	}
+
+	r.cache.Set(ctx, key, namespace.TenantID, TTL) //nolint:errcheck
+
+	return namespace.TenantID, nil
+}
+
+// TLSConfig returns a copy of base that resolves the server name presented by each client, failing the handshake when
+// it cannot be resolved. The resolved tenant is retrieved with [Resolver.Tenant].
+func (r *Resolver) TLSConfig(base *tls.Config) *tls.Config {
+	config := base.Clone()
+	config.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		tenant, err := r.Resolve(hello.Context(), hello.ServerName)
+		if err != nil {
+			return nil, err
+		}
+
+		r.tenants.Store(hello.Conn, tenant)
+
+		return nil, nil
+	}
+
+	return config
+}
+
+// Tenant returns the tenant resolved on the handshake of conn. It must be called once the handshake is complete, as
+// the tenant is forgotten after being returned.
+func (r *Resolver) Tenant(conn *tls.Conn) (string, bool) {
+	value, ok := r.tenants.LoadAndDelete(conn.NetConn())
+	if !ok {
+		return "", false
+	}
+
+	return value.(string), true
+}
+
+// Forget discards the tenant resolved for conn, if any. It must be called when the handshake of conn fails.
+func (r *Resolver) Forget(conn net.Conn) {
+	r.tenants.Delete(conn)
+}
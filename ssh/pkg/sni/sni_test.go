@@ -0,0 +1,236 @@
+package sni
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	clientmocks "github.com/shellhub-io/shellhub/pkg/api/internalclient/mocks"
+	cachemocks "github.com/shellhub-io/shellhub/pkg/cache/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func certificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "*." + DefaultDomain},
+		DNSNames:     []string{"*." + DefaultDomain},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestResolve(t *testing.T) {
+	cases := []struct {
+		description   string
+		serverName    string
+		requiredMocks func(api *clientmocks.Client, cache *cachemocks.Cache)
+		tenant        string
+		err           error
+	}{
+		{
+			description:   "fails when the server name is missing",
+			serverName:    "",
+			requiredMocks: func(_ *clientmocks.Client, _ *cachemocks.Cache) {},
+			err:           ErrServerNameMissing,
+		},
+		{
+			description:   "fails when the server name is outside the domain",
+			serverName:    "dev.example.com",
+			requiredMocks: func(_ *clientmocks.Client, _ *cachemocks.Cache) {},
+			err:           ErrServerNameInvalid,
+		},
+		{
+			description:   "fails when the server name is the domain itself",
+			serverName:    DefaultDomain,
+			requiredMocks: func(_ *clientmocks.Client, _ *cachemocks.Cache) {},
+			err:           ErrServerNameInvalid,
+		},
+		{
+			description:   "fails when the server name has more than one label before the domain",
+			serverName:    "a.dev." + DefaultDomain,
+			requiredMocks: func(_ *clientmocks.Client, _ *cachemocks.Cache) {},
+			err:           ErrServerNameInvalid,
+		},
+		{
+			description: "fails when the namespace does not exist",
+			serverName:  "dev." + DefaultDomain,
+			requiredMocks: func(api *clientmocks.Client, cache *cachemocks.Cache) {
+				cache.On("Get", mock.Anything, "sni/dev", mock.Anything).Return(nil).Once()
+				api.On("NamespaceLookupByName", "dev").Return(nil, internalclient.ErrNotFound).Once()
+			},
+			err: ErrNamespaceNotFound,
+		},
+		{
+			description: "fails when the namespace cannot be looked up",
+			serverName:  "dev." + DefaultDomain,
+			requiredMocks: func(api *clientmocks.Client, cache *cachemocks.Cache) {
+				cache.On("Get", mock.Anything, "sni/dev", mock.Anything).Return(nil).Once()
+				api.On("NamespaceLookupByName", "dev").Return(nil, internalclient.ErrUnknown).Once()
+			},
+			err: internalclient.ErrUnknown,
+		},
+		{
+			description: "succeeds from the cache",
+			serverName:  "DEV." + DefaultDomain,
+			requiredMocks: func(_ *clientmocks.Client, cache *cachemocks.Cache) {
+				cache.On("Get", mock.Anything, "sni/dev", mock.Anything).
+					Run(func(args mock.Arguments) {
+						*args.Get(2).(*string) = "00000000-0000-4000-0000-000000000000"
+					}).
+					Return(nil).
+					Once()
+			},
+			tenant: "00000000-0000-4000-0000-000000000000",
+		},
+		{
+			description: "succeeds from the API, caching the tenant",
+			serverName:  "dev." + DefaultDomain,
+			requiredMocks: func(api *clientmocks.Client, cache *cachemocks.Cache) {
+				cache.On("Get", mock.Anything, "sni/dev", mock.Anything).Return(errors.New("error")).Once()
+				api.On("NamespaceLookupByName", "dev").
+					Return(&models.Namespace{Name: "dev", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+				cache.On("Set", mock.Anything, "sni/dev", "00000000-0000-4000-0000-000000000000", TTL).Return(nil).Once()
+			},
+			tenant: "00000000-0000-4000-0000-000000000000",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			api := new(clientmocks.Client)
+			cache := new(cachemocks.Cache)
+			tc.requiredMocks(api, cache)
+
+			tenant, err := NewResolver(api, cache, "").Resolve(context.Background(), tc.serverName)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.tenant, tenant)
+
+			api.AssertExpectations(t)
+			cache.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	cases := []struct {
+		description   string
+		serverName    string
+		requiredMocks func(api *clientmocks.Client, cache *cachemocks.Cache)
+		tenant        string
+		fails         bool
+	}{
+		{
+			description:   "fails the handshake when the server name is outside the domain",
+			serverName:    "dev.example.com",
+			requiredMocks: func(_ *clientmocks.Client, _ *cachemocks.Cache) {},
+			fails:         true,
+		},
+		{
+			description: "fails the handshake when the namespace does not exist",
+			serverName:  "unknown." + DefaultDomain,
+			requiredMocks: func(api *clientmocks.Client, cache *cachemocks.Cache) {
+				cache.On("Get", mock.Anything, "sni/unknown", mock.Anything).Return(nil).Once()
+				api.On("NamespaceLookupByName", "unknown").Return(nil, internalclient.ErrNotFound).Once()
+			},
+			fails: true,
+		},
+		{
+			description: "routes the connection to the namespace's tenant",
+			serverName:  "dev." + DefaultDomain,
+			requiredMocks: func(api *clientmocks.Client, cache *cachemocks.Cache) {
+				cache.On("Get", mock.Anything, "sni/dev", mock.Anything).Return(nil).Once()
+				api.On("NamespaceLookupByName", "dev").
+					Return(&models.Namespace{Name: "dev", TenantID: "00000000-0000-4000-0000-000000000000"}, nil).
+					Once()
+				cache.On("Set", mock.Anything, "sni/dev", "00000000-0000-4000-0000-000000000000", TTL).Return(nil).Once()
+			},
+			tenant: "00000000-0000-4000-0000-000000000000",
+		},
+		{
+			description: "routes another connection to its own namespace's tenant",
+			serverName:  "prod." + DefaultDomain,
+			requiredMocks: func(api *clientmocks.Client, cache *cachemocks.Cache) {
+				cache.On("Get", mock.Anything, "sni/prod", mock.Anything).Return(nil).Once()
+				api.On("NamespaceLookupByName", "prod").
+					Return(&models.Namespace{Name: "prod", TenantID: "00000000-0000-4001-0000-000000000000"}, nil).
+					Once()
+				cache.On("Set", mock.Anything, "sni/prod", "00000000-0000-4001-0000-000000000000", TTL).Return(nil).Once()
+			},
+			tenant: "00000000-0000-4001-0000-000000000000",
+		},
+	}
+
+	cert := certificate(t)
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			api := new(clientmocks.Client)
+			cache := new(cachemocks.Cache)
+			tc.requiredMocks(api, cache)
+
+			resolver := NewResolver(api, cache, DefaultDomain)
+
+			serverConn, clientConn := net.Pipe()
+			defer serverConn.Close()
+			defer clientConn.Close()
+
+			server := tls.Server(serverConn, resolver.TLSConfig(&tls.Config{ // nolint: exhaustruct
+				Certificates: []tls.Certificate{cert},
+			}))
+			client := tls.Client(clientConn, &tls.Config{ // nolint: exhaustruct
+				ServerName:         tc.serverName,
+				InsecureSkipVerify: true, // nolint: gosec
+			})
+
+			go func() {
+				client.Handshake() //nolint:errcheck
+				// NOTICE: unblocks the server when the client rejects the handshake.
+				clientConn.Close()
+			}()
+
+			err := server.Handshake()
+			if tc.fails {
+				assert.Error(t, err)
+				resolver.Forget(serverConn)
+			} else {
+				require.NoError(t, err)
+			}
+
+			tenant, ok := resolver.Tenant(server)
+			assert.Equal(t, !tc.fails, ok)
+			assert.Equal(t, tc.tenant, tenant)
+
+			// The tenant is forgotten once taken.
+			_, ok = resolver.Tenant(server)
+			assert.False(t, ok)
+
+			api.AssertExpectations(t)
+			cache.AssertExpectations(t)
+		})
+	}
+}
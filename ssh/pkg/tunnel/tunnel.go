@@ -1,6 +1,7 @@
 package tunnel
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
@@ -8,13 +9,19 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	"github.com/shellhub-io/shellhub/pkg/httptunnel"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/ssh/session"
 	log "github.com/sirupsen/logrus"
 )
 
+// DefaultPingTimeout is the time to wait for a device to answer a ping when no timeout is informed.
+const DefaultPingTimeout = 5 * time.Second
+
 type Tunnel struct {
 	Tunnel *httptunnel.Tunnel
 	API    internalclient.Client
@@ -61,7 +68,7 @@ func NewTunnel(connection, dial string) *Tunnel {
 		tenant := parts[0]
 		uid := parts[1]
 
-		if err := tunnel.API.DevicesOffline(uid); err != nil {
+		if err := tunnel.API.ForTenant(tenant).DevicesOffline(uid); err != nil {
 			log.WithError(err).
 				WithFields(log.Fields{
 					"uid":       uid,
@@ -167,6 +174,85 @@ func NewTunnel(connection, dial string) *Tunnel {
 		return nil
 	})
 
+	// `/sessions/live` lists the sessions currently handled by this server, filtered by the namespace informed on
+	// "X-Tenant-ID" header.
+	tunnel.router.GET("/sessions/live", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, session.Live(c.Request().Header.Get("X-Tenant-ID")))
+	})
+
+	// `/sessions/:uid/transfer` asks the session's client to transfer the session to another namespace member, closing
+	// it when the client accepts. The session must belong to the namespace informed on "X-Tenant-ID" header.
+	tunnel.router.POST("/sessions/:uid/transfer", func(c echo.Context) error {
+		var data struct {
+			UID      string `param:"uid"`
+			MemberID string `json:"member_id"`
+		}
+
+		if err := c.Bind(&data); err != nil {
+			return err
+		}
+
+		sess, ok := session.Get(data.UID)
+		if !ok || sess.Device == nil || sess.Device.TenantID != c.Request().Header.Get("X-Tenant-ID") {
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		switch err := sess.Transfer(data.MemberID); {
+		case errors.Is(err, session.ErrTransferRejected):
+			return c.NoContent(http.StatusForbidden)
+		case errors.Is(err, session.ErrTransferTimeout):
+			return c.NoContent(http.StatusRequestTimeout)
+		case err != nil:
+			return err
+		}
+
+		return c.NoContent(http.StatusOK)
+	})
+
+	// `/sessions/revoke` closes the sessions of the namespace informed on "X-Tenant-ID" header that were authenticated
+	// with the revoked public key, identified by its fingerprint.
+	tunnel.router.POST("/sessions/revoke", func(c echo.Context) error {
+		var data struct {
+			Fingerprint string `json:"fingerprint"`
+		}
+
+		if err := c.Bind(&data); err != nil {
+			return err
+		}
+
+		tenant := c.Request().Header.Get("X-Tenant-ID")
+		if tenant == "" || data.Fingerprint == "" {
+			return c.NoContent(http.StatusBadRequest)
+		}
+
+		return c.JSON(http.StatusOK, map[string]int{"revoked": session.Revoke(tenant, data.Fingerprint)})
+	})
+
+	// `/devices/:uid/ping` checks if the device is reachable, sending a request to its agent through a new connection of
+	// its tunnel and measuring how long the agent takes to answer it.
+	tunnel.router.GET("/devices/:uid/ping", func(c echo.Context) error {
+		var data struct {
+			UID     string `param:"uid"`
+			Timeout int    `query:"timeout"`
+		}
+
+		if err := c.Bind(&data); err != nil {
+			return err
+		}
+
+		timeout := time.Duration(data.Timeout) * time.Millisecond
+		if timeout <= 0 {
+			timeout = DefaultPingTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+		defer cancel()
+
+		tenant := c.Request().Header.Get("X-Tenant-ID")
+
+		return c.JSON(http.StatusOK, tunnel.Ping(ctx, fmt.Sprintf("%s:%s", tenant, data.UID)))
+	})
+
 	tunnel.router.GET("/healthcheck", func(c echo.Context) error {
 		return c.String(http.StatusOK, "OK")
 	})
@@ -182,3 +268,48 @@ func (t *Tunnel) GetRouter() *echo.Echo {
 func (t *Tunnel) Dial(ctx context.Context, key string) (net.Conn, error) {
 	return t.Tunnel.Dial(ctx, key)
 }
+
+// Ping checks if the device identified by key is reachable, returning the time taken by its agent to answer a request
+// sent through a new connection of its tunnel. A failure to reach the device is reported in the result, not as an
+// error.
+func (t *Tunnel) Ping(ctx context.Context, key string) *models.DevicePingResult {
+	fail := func(err error) *models.DevicePingResult {
+		log.WithError(err).WithField("key", key).Debug("failed to ping the device")
+
+		return &models.DevicePingResult{Reachable: false, Error: err.Error()}
+	}
+
+	conn, err := t.Dial(ctx, key)
+	if err != nil {
+		return fail(err)
+	}
+
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline) //nolint:errcheck
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/ping", nil)
+	if err != nil {
+		return fail(err)
+	}
+
+	// NOTICE: only the round trip of the request is timed, as the connection could be established without the agent
+	// being able to answer on it.
+	start := time.Now()
+
+	if err := req.Write(conn); err != nil {
+		return fail(err)
+	}
+
+	res, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return fail(err)
+	}
+
+	res.Body.Close()
+
+	// NOTICE: the agents without the ping route answer it as not found, what is still a round trip to them.
+	return &models.DevicePingResult{Reachable: true, LatencyMS: int(time.Since(start).Milliseconds())}
+}
@@ -2,5 +2,6 @@
 //
 // This package includes two authentication methods: [PasswordHandler] and [PublicKeyHandler].
 // [PasswordHandler] is the second authentication method tried by the server to connect the client to the agent,
-// while [PublicKeyHandler] is the first authentication method attempted.
+// while [PublicKeyHandler] is the first authentication method attempted. [KeyboardInteractiveHandler] doesn't
+// authenticate, only showing to the client why its public key was refused.
 package auth
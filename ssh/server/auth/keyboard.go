@@ -0,0 +1,20 @@
+package auth
+
+import (
+	gliderssh "github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// refusalKey is the context key of the error that refused a public key which the client must be told about.
+const refusalKey = "auth_refusal"
+
+// KeyboardInteractiveHandler never authenticates a connection. As the result of a public key authentication cannot
+// carry a message, it's used to show to the client why its public key was refused, when the reason was stored by
+// [PublicKeyHandler], without asking anything.
+func KeyboardInteractiveHandler(ctx gliderssh.Context, challenger gossh.KeyboardInteractiveChallenge) bool {
+	if err, ok := ctx.Value(refusalKey).(error); ok {
+		_, _ = challenger(ctx.User(), err.Error()+"\n", nil, nil)
+	}
+
+	return false
+}
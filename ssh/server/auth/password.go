@@ -30,6 +30,12 @@ func PasswordHandler(ctx gliderssh.Context, passwd string) bool {
 		return false
 	}
 
+	if err := sess.CheckDeviceExpiry(); err != nil {
+		logger.WithError(err).Warn("failed to authenticate on device as its access has expired")
+
+		return false
+	}
+
 	if err := sess.Auth(ctx, session.AuthPassword(passwd)); err != nil {
 		logger.Warn("failed to authenticate on device using password")
 
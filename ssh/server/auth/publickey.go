@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"net"
 
 	gliderssh "github.com/gliderlabs/ssh"
@@ -30,8 +31,19 @@ func PublicKeyHandler(ctx gliderssh.Context, publicKey gliderssh.PublicKey) bool
 		return false
 	}
 
+	if err := sess.CheckDeviceExpiry(); err != nil {
+		logger.WithError(err).Warn("failed to authenticate on device as its access has expired")
+
+		return false
+	}
+
 	if err := sess.Auth(ctx, session.AuthPublicKey(publicKey)); err != nil {
-		logger.Warn("failed to authenticate on device using public key")
+		logger.WithError(err).Warn("failed to authenticate on device using public key")
+
+		// NOTICE: the member access schedule's errors tell the client why the key was refused and when it's allowed.
+		if errors.Is(err, session.ErrMemberAccessSchedule) || errors.Is(err, session.ErrMemberAccessScheduleKey) {
+			ctx.SetValue(refusalKey, err)
+		}
 
 		return false
 	}
@@ -0,0 +1,57 @@
+package channels
+
+import (
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// MaxDurationMessage is sent to the client when its session is disconnected for reaching the maximum duration.
+const MaxDurationMessage = "\r\nMaximum session duration reached. Disconnecting.\r\n"
+
+// durationLimit closes expired once the deadline of a session's channel passes.
+type durationLimit struct {
+	max time.Duration
+	// deadline is when the limit elapses, what is zero when there is no limit.
+	deadline time.Time
+	timer    *time.Timer
+	expired  chan struct{}
+}
+
+// newDurationLimit elapses at deadline, which is usually when the session started plus max, so the limit isn't
+// restarted by opening a new channel or resuming a shell. A deadline already passed elapses right away. When max is
+// zero, the limit never elapses.
+func newDurationLimit(max time.Duration, deadline time.Time) *durationLimit {
+	l := &durationLimit{max: max, expired: make(chan struct{})}
+	if max > 0 {
+		l.deadline = deadline
+		l.timer = time.AfterFunc(time.Until(deadline), func() { close(l.expired) })
+	}
+
+	return l
+}
+
+// reset moves the deadline to max from now, unless the limit has already elapsed.
+func (l *durationLimit) reset() {
+	// NOTICE: a timer created by [time.AfterFunc] runs its function again when reset after firing, what would close
+	// expired twice.
+	if l.timer != nil && l.timer.Stop() {
+		l.deadline = time.Now().Add(l.max)
+		l.timer.Reset(l.max)
+	}
+}
+
+func (l *durationLimit) stop() {
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+}
+
+// disconnectExceeded tells the client its session reached the maximum duration, closing both its channel and the
+// agent's.
+func disconnectExceeded(client, agent gossh.Channel) {
+	client.Write([]byte(MaxDurationMessage)) //nolint:errcheck
+
+	agent.Close()
+	client.Close()
+}
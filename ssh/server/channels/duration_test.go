@@ -0,0 +1,109 @@
+package channels
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// writtenChannel is a fake [gossh.Channel] keeping what is written to it.
+type writtenChannel struct {
+	gossh.Channel
+	written bytes.Buffer
+	closed  bool
+}
+
+func (c *writtenChannel) Write(data []byte) (int, error) {
+	return c.written.Write(data)
+}
+
+func (c *writtenChannel) Close() error {
+	c.closed = true
+
+	return nil
+}
+
+// elapsed reports whether the limit elapses within timeout.
+func elapsed(limit *durationLimit, timeout time.Duration) bool {
+	select {
+	case <-limit.expired:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func TestDurationLimit(t *testing.T) {
+	t.Run("elapses when the session exceeds the limit", func(t *testing.T) {
+		limit := newDurationLimit(50*time.Millisecond, time.Now().Add(50*time.Millisecond))
+		defer limit.stop()
+
+		assert.True(t, elapsed(limit, time.Second))
+	})
+
+	t.Run("doesn't elapse when the session doesn't exceed the limit", func(t *testing.T) {
+		limit := newDurationLimit(time.Second, time.Now().Add(time.Second))
+		limit.stop()
+
+		assert.False(t, elapsed(limit, 100*time.Millisecond))
+	})
+
+	t.Run("doesn't elapse when there is no limit", func(t *testing.T) {
+		limit := newDurationLimit(0, time.Time{})
+		defer limit.stop()
+
+		assert.False(t, elapsed(limit, 100*time.Millisecond))
+	})
+
+	t.Run("doesn't elapse while it's reset", func(t *testing.T) {
+		limit := newDurationLimit(100*time.Millisecond, time.Now().Add(100*time.Millisecond))
+		defer limit.stop()
+
+		for i := 0; i < 5; i++ {
+			time.Sleep(40 * time.Millisecond)
+			limit.reset()
+		}
+
+		assert.False(t, elapsed(limit, 50*time.Millisecond))
+		assert.True(t, elapsed(limit, time.Second))
+	})
+
+	t.Run("elapses right away when the deadline has already passed", func(t *testing.T) {
+		limit := newDurationLimit(time.Hour, time.Now().Add(-time.Minute))
+		defer limit.stop()
+
+		assert.True(t, elapsed(limit, 100*time.Millisecond))
+	})
+
+	t.Run("moves the deadline when it's reset", func(t *testing.T) {
+		limit := newDurationLimit(time.Hour, time.Now().Add(time.Minute))
+		defer limit.stop()
+
+		limit.reset()
+
+		assert.WithinDuration(t, time.Now().Add(time.Hour), limit.deadline, time.Second)
+	})
+
+	t.Run("isn't reset after it elapses", func(t *testing.T) {
+		limit := newDurationLimit(10*time.Millisecond, time.Now().Add(10*time.Millisecond))
+		defer limit.stop()
+
+		assert.True(t, elapsed(limit, time.Second))
+		assert.NotPanics(t, limit.reset)
+		assert.True(t, elapsed(limit, time.Second))
+	})
+}
+
+func TestDisconnectExceeded(t *testing.T) {
+	client, agent := new(writtenChannel), new(writtenChannel)
+
+	disconnectExceeded(client, agent)
+
+	assert.Equal(t, MaxDurationMessage, client.written.String())
+	assert.Empty(t, agent.written.String())
+	assert.True(t, client.closed)
+	assert.True(t, agent.closed)
+}
@@ -0,0 +1,43 @@
+package channels
+
+import (
+	"sort"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// envRequest is the payload of an [EnvRequestType] request.
+type envRequest struct {
+	Name  string
+	Value string
+}
+
+// injectEnv sends to the agent, as [EnvRequestType] requests, the default environment variables not set by the client
+// on the channel. The variables are sent sorted by name and the ones refused by [models.ValidateEnvVar] are skipped.
+func injectEnv(agent gossh.Channel, defaults map[string]string, set map[string]bool) error {
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		if !set[name] {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := defaults[name]
+		if err := models.ValidateEnvVar(name, value); err != nil {
+			log.WithError(err).WithField("name", name).Warn("skipping a default environment variable not allowed")
+
+			continue
+		}
+
+		if _, err := agent.SendRequest(EnvRequestType, false, gossh.Marshal(&envRequest{Name: name, Value: value})); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package channels
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// requestsChannel is a fake [gossh.Channel] keeping the requests sent through it.
+type requestsChannel struct {
+	gossh.Channel
+	requests []envRequest
+	err      error
+}
+
+func (c *requestsChannel) SendRequest(name string, _ bool, payload []byte) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+
+	var env envRequest
+	if name == EnvRequestType && gossh.Unmarshal(payload, &env) == nil {
+		c.requests = append(c.requests, env)
+	}
+
+	return true, nil
+}
+
+func TestInjectEnv(t *testing.T) {
+	cases := []struct {
+		description string
+		defaults    map[string]string
+		set         map[string]bool
+		expected    []envRequest
+	}{
+		{
+			description: "sends nothing when there are no default variables",
+			defaults:    nil,
+			set:         map[string]bool{},
+			expected:    nil,
+		},
+		{
+			description: "sends the default variables sorted by name",
+			defaults:    map[string]string{"TERM": "xterm-256color", "LANG": "C.UTF-8"},
+			set:         map[string]bool{},
+			expected:    []envRequest{{Name: "LANG", Value: "C.UTF-8"}, {Name: "TERM", Value: "xterm-256color"}},
+		},
+		{
+			description: "skips the variables set by the client",
+			defaults:    map[string]string{"TERM": "xterm-256color", "LANG": "C.UTF-8"},
+			set:         map[string]bool{"TERM": true},
+			expected:    []envRequest{{Name: "LANG", Value: "C.UTF-8"}},
+		},
+		{
+			description: "skips the variables not allowed",
+			defaults:    map[string]string{"LD_PRELOAD": "/tmp/lib.so", "PATH": "/tmp", "TERM": "xterm-256color"},
+			set:         map[string]bool{},
+			expected:    []envRequest{{Name: "TERM", Value: "xterm-256color"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			agent := &requestsChannel{}
+
+			assert.NoError(t, injectEnv(agent, tc.defaults, tc.set))
+			assert.Equal(t, tc.expected, agent.requests)
+		})
+	}
+
+	t.Run("fails when the request can't be sent", func(t *testing.T) {
+		agent := &requestsChannel{err: errors.New("error")}
+
+		assert.EqualError(t, injectEnv(agent, map[string]string{"TERM": "xterm-256color"}, map[string]bool{}), "error")
+	})
+}
@@ -0,0 +1,58 @@
+package channels
+
+import (
+	"sync/atomic"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// idleChannel is a [gossh.Channel] that tracks the last time data was read from it.
+type idleChannel struct {
+	gossh.Channel
+	last atomic.Int64
+}
+
+func newIdleChannel(channel gossh.Channel) *idleChannel {
+	c := &idleChannel{Channel: channel}
+	c.last.Store(time.Now().UnixNano())
+
+	return c
+}
+
+func (c *idleChannel) Read(data []byte) (int, error) {
+	read, err := c.Channel.Read(data)
+	if read > 0 {
+		c.last.Store(time.Now().UnixNano())
+	}
+
+	return read, err
+}
+
+// idle returns how long the channel is without data being read from it.
+func (c *idleChannel) idle() time.Duration {
+	return time.Since(time.Unix(0, c.last.Load()))
+}
+
+// watchIdle closes the channel when no data is read from it for max, what is checked until done is closed. It reports
+// whether the channel was closed due inactivity.
+func watchIdle(channel *idleChannel, max time.Duration, done <-chan struct{}) bool {
+	timer := time.NewTimer(max)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-done:
+			return false
+		case <-timer.C:
+			idle := channel.idle()
+			if idle >= max {
+				channel.Close()
+
+				return true
+			}
+
+			timer.Reset(max - idle)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package channels
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// channel is a fake [gossh.Channel] reading from data.
+type channel struct {
+	gossh.Channel
+	data   chan []byte
+	closed chan struct{}
+}
+
+func newChannel() *channel {
+	return &channel{data: make(chan []byte), closed: make(chan struct{})}
+}
+
+func (c *channel) Read(p []byte) (int, error) {
+	return copy(p, <-c.data), nil
+}
+
+func (c *channel) Close() error {
+	close(c.closed)
+
+	return nil
+}
+
+func TestWatchIdle(t *testing.T) {
+	t.Run("closes the channel when no data is read", func(t *testing.T) {
+		c := newChannel()
+
+		assert.True(t, watchIdle(newIdleChannel(c), 50*time.Millisecond, make(chan struct{})))
+		assert.Eventually(t, func() bool {
+			select {
+			case <-c.closed:
+				return true
+			default:
+				return false
+			}
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("keeps the channel while data is read", func(t *testing.T) {
+		c := newChannel()
+		idle := newIdleChannel(c)
+
+		done := make(chan struct{})
+		go func() {
+			for i := 0; i < 5; i++ {
+				c.data <- []byte("data")
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			close(done)
+		}()
+
+		go func() {
+			buffer := make([]byte, 4)
+			for i := 0; i < 5; i++ {
+				idle.Read(buffer) //nolint:errcheck
+			}
+		}()
+
+		assert.False(t, watchIdle(idle, 50*time.Millisecond, done))
+	})
+}
@@ -0,0 +1,144 @@
+package channels
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/envs"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/ssh/session"
+	log "github.com/sirupsen/logrus"
+)
+
+// recorder records the output of a shell. When there's no data in either direction, neither the client's input nor
+// the shell's output, for the pause, the recording is paused until data flows again, what is marked on the recording by
+// a [models.SessionRecordedTypeGap] frame with how long it was paused. Pausing only compresses the recording's
+// timeline: every output is recorded.
+//
+// A nil recorder records nothing.
+type recorder struct {
+	// write writes a frame to the recording.
+	write func(frame *models.SessionRecorded)
+	// pause is how long the session can stay idle before the recording is paused. When zero, it's never paused.
+	pause time.Duration
+
+	mu             sync.Mutex
+	lastActivityAt time.Time
+	// paused is how long the recording was paused so far.
+	paused time.Duration
+}
+
+func newRecorder(pause time.Duration, write func(frame *models.SessionRecorded)) *recorder {
+	return &recorder{write: write, pause: pause, lastActivityAt: clock.Now()}
+}
+
+// newSessionRecorder creates the recorder of the shell on the session's channel with the ID channel, or nil when the
+// instance doesn't support session recording or the session's policy doesn't require it.
+func newSessionRecorder(sess *session.Session, channel int, opts DefaultSessionHandlerOptions) *recorder {
+	policy := sess.Policy()
+	if !(envs.IsEnterprise() || envs.IsCloud()) || !policy.Record {
+		return nil
+	}
+
+	return newRecorder(policy.RecordingIdlePause, func(frame *models.SessionRecorded) {
+		frame.UID = sess.UID
+		frame.Namespace = sess.Lookup["domain"]
+		frame.Width = int(sess.Pty.Columns)
+		frame.Height = int(sess.Pty.Rows)
+		frame.Channel = channel
+
+		sess.Record(frame, opts.RecordURL) //nolint:errcheck
+	})
+}
+
+// idle reports whether the session is idle long enough to pause the recording at now.
+func (r *recorder) idle(now time.Time) bool {
+	return r.pause > 0 && now.Sub(r.lastActivityAt) >= r.pause
+}
+
+// resume marks the session as active at now. When the recording was paused, it resumes with a gap frame lasting from
+// the moment the pause started until now.
+func (r *recorder) resume(now time.Time) {
+	if r.idle(now) {
+		gap := now.Sub(r.lastActivityAt.Add(r.pause))
+		r.paused += gap
+
+		r.write(&models.SessionRecorded{
+			Type:       models.SessionRecordedTypeGap,
+			DurationMS: gap.Milliseconds(),
+			Time:       now,
+		})
+	}
+
+	r.lastActivityAt = now
+}
+
+// record writes the output to the recording, resuming it when paused.
+func (r *recorder) record(output []byte) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := clock.Now()
+	r.resume(now)
+
+	r.write(&models.SessionRecorded{Message: string(output), Time: now})
+}
+
+// activity reports the client sent data, resuming the recording when paused.
+func (r *recorder) activity() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resume(clock.Now())
+}
+
+// input wraps the client's input, reporting its activity on every read.
+func (r *recorder) input(reader io.Reader) io.Reader {
+	if r == nil {
+		return reader
+	}
+
+	return &activityReader{Reader: reader, recorder: r}
+}
+
+// finish reports how long the recording was paused to the session.
+func (r *recorder) finish(sess *session.Session) {
+	if r == nil || r.pause <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	paused := r.paused
+	r.mu.Unlock()
+
+	if err := sess.RecordingPaused(paused.Milliseconds()); err != nil {
+		log.WithError(err).
+			WithFields(log.Fields{"session": sess.UID, "sshid": sess.SSHID}).
+			Warn("failed to report how long the recording was paused")
+	}
+}
+
+// activityReader is an [io.Reader] that reports the activity of the client on its recorder.
+type activityReader struct {
+	io.Reader
+	recorder *recorder
+}
+
+func (a *activityReader) Read(p []byte) (int, error) {
+	read, err := a.Reader.Read(p)
+	if read > 0 {
+		a.recorder.activity()
+	}
+
+	return read, err
+}
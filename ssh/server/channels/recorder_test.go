@@ -0,0 +1,152 @@
+package channels
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmocks "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// at sets the clock to the offset from the start of the recording.
+	var now time.Time
+	at := func(offset time.Duration) {
+		now = start.Add(offset)
+	}
+
+	clockMock := new(clockmocks.Clock)
+	clockMock.On("Now").Return(func() time.Time { return now })
+
+	backend := clock.DefaultBackend
+	clock.DefaultBackend = clockMock
+	t.Cleanup(func() {
+		clock.DefaultBackend = backend
+	})
+
+	t.Run("records every frame when the pause is disabled", func(t *testing.T) {
+		frames := []models.SessionRecorded{}
+
+		at(0)
+		rec := newRecorder(0, func(frame *models.SessionRecorded) {
+			frames = append(frames, *frame)
+		})
+
+		at(time.Hour)
+		rec.record([]byte("output"))
+		rec.activity()
+
+		assert.Equal(t, []models.SessionRecorded{{Message: "output", Time: start.Add(time.Hour)}}, frames)
+		assert.Equal(t, time.Duration(0), rec.paused)
+	})
+
+	t.Run("inserts a gap frame when the client resumes after being idle", func(t *testing.T) {
+		frames := []models.SessionRecorded{}
+
+		at(0)
+		rec := newRecorder(5*time.Second, func(frame *models.SessionRecorded) {
+			frames = append(frames, *frame)
+		})
+
+		at(time.Second)
+		rec.activity()
+		rec.record([]byte("a"))
+
+		// NOTICE: the recording is paused five seconds after the last activity, at 6s.
+		at(10 * time.Second)
+		rec.activity()
+		rec.record([]byte("b"))
+
+		assert.Equal(t, []models.SessionRecorded{
+			{Message: "a", Time: start.Add(time.Second)},
+			{Type: models.SessionRecordedTypeGap, DurationMS: 4000, Time: start.Add(10 * time.Second)},
+			{Message: "b", Time: start.Add(10 * time.Second)},
+		}, frames)
+		assert.Equal(t, 4*time.Second, rec.paused)
+	})
+
+	t.Run("records the output while the client is idle", func(t *testing.T) {
+		frames := []models.SessionRecorded{}
+
+		at(0)
+		rec := newRecorder(5*time.Second, func(frame *models.SessionRecorded) {
+			frames = append(frames, *frame)
+		})
+
+		// NOTICE: the output keeps the session active, like a "tail -f", even without input from the client.
+		at(time.Second)
+		rec.activity()
+		at(4 * time.Second)
+		rec.record([]byte("a"))
+		at(8 * time.Second)
+		rec.record([]byte("b"))
+
+		// NOTICE: the output after an idle period, like a "sleep 61; cat file", is still recorded after a gap.
+		at(20 * time.Second)
+		rec.record([]byte("c"))
+
+		assert.Equal(t, []models.SessionRecorded{
+			{Message: "a", Time: start.Add(4 * time.Second)},
+			{Message: "b", Time: start.Add(8 * time.Second)},
+			{Type: models.SessionRecordedTypeGap, DurationMS: 7000, Time: start.Add(20 * time.Second)},
+			{Message: "c", Time: start.Add(20 * time.Second)},
+		}, frames)
+		assert.Equal(t, 7*time.Second, rec.paused)
+	})
+
+	t.Run("accumulates the duration of every pause", func(t *testing.T) {
+		frames := []models.SessionRecorded{}
+
+		at(0)
+		rec := newRecorder(time.Second, func(frame *models.SessionRecorded) {
+			frames = append(frames, *frame)
+		})
+
+		at(3 * time.Second)
+		rec.activity()
+		at(3500 * time.Millisecond)
+		rec.activity()
+		at(10 * time.Second)
+		rec.activity()
+
+		assert.Equal(t, []models.SessionRecorded{
+			{Type: models.SessionRecordedTypeGap, DurationMS: 2000, Time: start.Add(3 * time.Second)},
+			{Type: models.SessionRecordedTypeGap, DurationMS: 5500, Time: start.Add(10 * time.Second)},
+		}, frames)
+		assert.Equal(t, 7500*time.Millisecond, rec.paused)
+	})
+
+	t.Run("reports the activity of the client's input", func(t *testing.T) {
+		frames := []models.SessionRecorded{}
+
+		at(0)
+		rec := newRecorder(time.Second, func(frame *models.SessionRecorded) {
+			frames = append(frames, *frame)
+		})
+
+		at(2 * time.Second)
+		data, err := io.ReadAll(rec.input(bytes.NewReader([]byte("ls\n"))))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("ls\n"), data)
+
+		assert.Equal(t, []models.SessionRecorded{
+			{Type: models.SessionRecordedTypeGap, DurationMS: 1000, Time: start.Add(2 * time.Second)},
+		}, frames)
+	})
+
+	t.Run("records nothing when nil", func(t *testing.T) {
+		var rec *recorder
+
+		rec.record([]byte("output"))
+		rec.activity()
+
+		reader := bytes.NewReader(nil)
+		assert.Equal(t, io.Reader(reader), rec.input(reader))
+	})
+}
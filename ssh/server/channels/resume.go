@@ -0,0 +1,150 @@
+package channels
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shellhub-io/shellhub/ssh/session"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// ResumeRequestType is sent by a client, instead of a "shell" request, to reattach a new session's channel to a shell
+// detached from its previous client after a network drop. The payload is the detached session's UID encoded as an SSH
+// string.
+const ResumeRequestType = "resume@shellhub.io"
+
+var (
+	ErrResumeNotFound  = errors.New("there is no detached session with this UID")
+	ErrResumeForbidden = errors.New("the detached session belongs to another user or device")
+	ErrResumeNoKey     = errors.New("only sessions authenticated with a public key can be resumed")
+)
+
+// relay forwards the output of a resumable shell to the client currently attached to it. While no client is attached,
+// the output is dropped, keeping the shell running on the agent.
+type relay struct {
+	mu     sync.Mutex
+	client gossh.Channel
+	// done is closed when the agent's output ends.
+	done chan struct{}
+	// recorder records the shell's output, across the clients attached to it.
+	recorder *recorder
+}
+
+func newRelay(client gossh.Channel) *relay {
+	return &relay{client: client, done: make(chan struct{})}
+}
+
+// Write writes to the attached client. Failing to write means the client went away, so the client is detached.
+func (r *relay) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.client != nil {
+		if _, err := r.client.Write(p); err != nil {
+			r.client = nil
+		}
+	}
+
+	return len(p), nil
+}
+
+func (r *relay) attach(client gossh.Channel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.client = client
+}
+
+func (r *relay) detach() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.client = nil
+}
+
+// close closes the writing side of the attached client and reports the agent's output has ended.
+func (r *relay) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.client != nil {
+		r.client.CloseWrite() //nolint:errcheck
+	}
+
+	close(r.done)
+}
+
+// ended reports whether the agent's output has ended.
+func (r *relay) ended() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// detached is the agent's side of a shell whose client went away.
+type detached struct {
+	// session is the session the shell was detached from.
+	session   *session.Session
+	agent     gossh.Channel
+	agentReqs <-chan *gossh.Request
+	relay     *relay
+	// deadline is when the shell reaches the maximum duration of the session that started it, so resuming it doesn't
+	// restart the limit. It is zero when there is no limit.
+	deadline time.Time
+	// finish closes the agent's channel and finishes every session that served the shell.
+	finish func()
+}
+
+// detachedSessions holds the detached shells, indexed by the UID of the session they were detached from, while they
+// wait for a client to reattach.
+//
+// NOTICE: the detached shells are held by the process, as their agent's channels are, so a client can only resume a
+// session on the same SSH server instance it was detached from. Every server in the process shares them, what is safe
+// as the session's UID is unique.
+var detachedSessions sync.Map
+
+// detach keeps the shell running on the agent for the grace period. When no client reattaches to it in time, the shell
+// is finished.
+func detach(d *detached, grace time.Duration) {
+	detachedSessions.Store(d.session.UID, d)
+
+	time.AfterFunc(grace, func() {
+		if _, ok := detachedSessions.LoadAndDelete(d.session.UID); ok {
+			d.finish()
+		}
+	})
+}
+
+// reattach takes the shell detached from the session uid to be served by sess. Only the user connected to the same
+// device, authenticated with the same public key, can reattach to it.
+//
+// As the password and the magic key don't identify who is connecting, but only the device's user, sessions
+// authenticated with them can't be resumed.
+func reattach(uid string, sess *session.Session) (*detached, error) {
+	if sess.Fingerprint == "" {
+		return nil, ErrResumeNoKey
+	}
+
+	value, ok := detachedSessions.Load(uid)
+	if !ok {
+		return nil, ErrResumeNotFound
+	}
+
+	d := value.(*detached)
+	if d.session.Device.UID != sess.Device.UID ||
+		d.session.Target.Username != sess.Target.Username ||
+		d.session.Fingerprint != sess.Fingerprint {
+		return nil, ErrResumeForbidden
+	}
+
+	// NOTICE: the grace period may expire between loading and deleting the detached shell.
+	if _, ok := detachedSessions.LoadAndDelete(uid); !ok {
+		return nil, ErrResumeNotFound
+	}
+
+	return d, nil
+}
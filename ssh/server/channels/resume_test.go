@@ -0,0 +1,103 @@
+package channels
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/ssh/pkg/target"
+	"github.com/shellhub-io/shellhub/ssh/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSession(uid, device, username, fingerprint string) *session.Session {
+	return &session.Session{
+		UID: uid,
+		Data: session.Data{
+			Device:      &models.Device{UID: device},
+			Target:      &target.Target{Username: username},
+			Fingerprint: fingerprint,
+		},
+	}
+}
+
+func TestReattach(t *testing.T) {
+	cases := []struct {
+		description string
+		uid         string
+		sess        *session.Session
+		expected    error
+	}{
+		{
+			description: "fails when there is no detached session with the UID",
+			uid:         "nonexistent",
+			sess:        newTestSession("new", "device", "root", "fingerprint"),
+			expected:    ErrResumeNotFound,
+		},
+		{
+			description: "fails when the user is not the same",
+			uid:         "detached",
+			sess:        newTestSession("new", "device", "other", "fingerprint"),
+			expected:    ErrResumeForbidden,
+		},
+		{
+			description: "fails when the device is not the same",
+			uid:         "detached",
+			sess:        newTestSession("new", "other", "root", "fingerprint"),
+			expected:    ErrResumeForbidden,
+		},
+		{
+			description: "fails when the public key is not the same",
+			uid:         "detached",
+			sess:        newTestSession("new", "device", "root", "other"),
+			expected:    ErrResumeForbidden,
+		},
+		{
+			description: "fails when the session was not authenticated with a public key",
+			uid:         "detached",
+			sess:        newTestSession("new", "device", "root", ""),
+			expected:    ErrResumeNoKey,
+		},
+		{
+			description: "succeeds when the same user reattaches on the same device",
+			uid:         "detached",
+			sess:        newTestSession("new", "device", "root", "fingerprint"),
+			expected:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			finished := false
+			detach(&detached{
+				session: newTestSession("detached", "device", "root", "fingerprint"),
+				finish:  func() { finished = true },
+			}, time.Minute)
+			t.Cleanup(func() {
+				detachedSessions.Delete("detached")
+			})
+
+			d, err := reattach(tc.uid, tc.sess)
+			assert.Equal(t, tc.expected, err)
+			assert.Equal(t, tc.expected == nil, d != nil)
+			assert.False(t, finished)
+		})
+	}
+}
+
+func TestDetachExpires(t *testing.T) {
+	finished := make(chan struct{})
+	detach(&detached{
+		session: newTestSession("expired", "device", "root", "fingerprint"),
+		finish:  func() { close(finished) },
+	}, 10*time.Millisecond)
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("the detached session was not finished after its grace period")
+	}
+
+	_, err := reattach("expired", newTestSession("new", "device", "root", "fingerprint"))
+	assert.Equal(t, ErrResumeNotFound, err)
+}
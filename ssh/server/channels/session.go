@@ -3,8 +3,10 @@ package channels
 import (
 	"strings"
 	"sync"
+	"time"
 
 	gliderssh "github.com/gliderlabs/ssh"
+	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/ssh/session"
 	log "github.com/sirupsen/logrus"
 	gossh "golang.org/x/crypto/ssh"
@@ -49,6 +51,11 @@ const (
 	//
 	// https://www.rfc-editor.org/rfc/rfc4254#section-6.7
 	WindowChangeRequestType = "window-change"
+	// Environment variables may be passed to the shell/command to be started later.  Uncontrolled setting of
+	// environment variables in a privileged process can be a security hazard.
+	//
+	// https://www.rfc-editor.org/rfc/rfc4254#section-6.4
+	EnvRequestType = "env"
 	// In a defined interval, the Agent sends a keepalive request to maintain the session apoint, even when no data is
 	// send.
 	KeepAliveRequestType = KeepAliveRequestTypePrefix + "@shellhub.io"
@@ -61,6 +68,13 @@ const (
 
 type DefaultSessionHandlerOptions struct {
 	RecordURL string
+	// MaxAgentChannels is the maximum number of channels a session can open on the agent at the same time. When less
+	// than one, there is no limit.
+	MaxAgentChannels int
+	// ResumeGrace is how long a shell with a pty is kept running on the agent after its client goes away, waiting for
+	// the same user, with the same public key, to reattach to it through a [ResumeRequestType] request. When zero,
+	// sessions cannot be resumed.
+	ResumeGrace time.Duration
 }
 
 // DefaultSessionHandler is the default handler for session's channel.
@@ -73,12 +87,30 @@ func DefaultSessionHandler(opts DefaultSessionHandlerOptions) gliderssh.ChannelH
 	return func(_ *gliderssh.Server, conn *gossh.ServerConn, newChan gossh.NewChannel, ctx gliderssh.Context) {
 		sess, _ := session.ObtainSession(ctx)
 
+		// parked is set when the channel leaves its shell detached on the agent, waiting to be resumed, and resumed
+		// finishes the sessions that served the shell before this channel reattached to it.
+		var parked bool
+		var resumed func()
+
+		handled := make(chan struct{})
+		defer close(handled)
+
 		go func() {
 			// NOTICE: As [gossh.ServerConn] is shared by all channels calls, close it after a channel close block any
 			// other channel involkation. To avoid it, we wait for the connection be closed to finish the sesison.
 			conn.Wait() //nolint:errcheck
 
+			// NOTICE: a detached shell is finished only when its grace period expires.
+			<-handled
+			if parked {
+				return
+			}
+
 			sess.Finish() //nolint:errcheck
+
+			if resumed != nil {
+				resumed()
+			}
 		}()
 
 		logger := log.WithFields(
@@ -96,6 +128,20 @@ func DefaultSessionHandler(opts DefaultSessionHandlerOptions) gliderssh.ChannelH
 			newChan.Reject(gossh.ConnectionFailed, msg) //nolint:errcheck
 		}
 
+		if !sess.AcquireChannel(opts.MaxAgentChannels) {
+			logger.WithField("max_agent_channels", opts.MaxAgentChannels).
+				Warn("rejecting the channel because the limit of channels opened on agent was reached")
+
+			newChan.Reject(gossh.ResourceShortage, "the limit of channels opened on agent was reached") //nolint:errcheck
+
+			return
+		}
+
+		defer sess.ReleaseChannel()
+
+		// channelID identifies the channel's recording, as each channel of the session can run its own shell.
+		channelID := sess.NextChannelID()
+
 		logger.Info("session channel started")
 		defer logger.Info("session channel done")
 
@@ -108,6 +154,21 @@ func DefaultSessionHandler(opts DefaultSessionHandlerOptions) gliderssh.ChannelH
 
 		defer client.Close()
 
+		// NOTICE: the session policy comes from the device, taking precedence over its namespace's settings, so a
+		// sensitive device can enforce stricter rules than the rest of the namespace.
+		policy := sess.Policy()
+
+		if policy.MaxIdle > 0 {
+			idle := newIdleChannel(client)
+			client = idle
+
+			go func() {
+				if watchIdle(idle, policy.MaxIdle, handled) {
+					logger.WithField("max_idle", policy.MaxIdle).Info("session channel closed due inactivity")
+				}
+			}()
+		}
+
 		agent, agentReqs, err := sess.AgentClient.OpenChannel(SessionChannel, nil)
 		if err != nil {
 			reject(err, "failed to open the 'session' channel on agent")
@@ -115,7 +176,61 @@ func DefaultSessionHandler(opts DefaultSessionHandlerOptions) gliderssh.ChannelH
 			return
 		}
 
-		defer agent.Close()
+		// NOTICE: the maximum duration is counted from the moment the session started, so opening a new channel on the
+		// same connection doesn't restart it.
+		limit := newDurationLimit(policy.MaxDuration, sess.StartedAt.Add(policy.MaxDuration))
+		defer func() {
+			limit.stop()
+		}()
+
+		// exceeded is set when the channel is disconnected for reaching the maximum duration, what also prevents its
+		// shell from being detached to be resumed later.
+		var exceeded bool
+
+		// relay is set when the channel serves a resumable shell.
+		var relay *relay
+
+		// started is set once a shell, an exec or a subsystem is started on the channel.
+		var started bool
+
+		// env are the names of the environment variables set by the client on the channel.
+		env := make(map[string]bool)
+
+		defer func() {
+			if exceeded || relay == nil || opts.ResumeGrace <= 0 || relay.ended() {
+				agent.Close()
+
+				return
+			}
+
+			relay.detach()
+
+			previous := resumed
+			finish := func() {
+				agent.Close()
+
+				sess.Finish() //nolint:errcheck
+
+				if previous != nil {
+					previous()
+				}
+			}
+
+			detach(&detached{
+				session:   sess,
+				agent:     agent,
+				agentReqs: agentReqs,
+				relay:     relay,
+				deadline:  limit.deadline,
+				finish:    finish,
+			}, opts.ResumeGrace)
+
+			parked = true
+
+			logger.WithField("grace", opts.ResumeGrace).Info("session detached from its client, waiting to be resumed")
+		}()
+
+		globalReqs := sess.AgentGlobalReqs
 
 		var wg sync.WaitGroup
 
@@ -125,7 +240,19 @@ func DefaultSessionHandler(opts DefaultSessionHandlerOptions) gliderssh.ChannelH
 				logger.Info("context has done")
 
 				return
-			case req, ok := <-sess.AgentGlobalReqs:
+			case <-limit.expired:
+				logger.WithField("max_duration", policy.MaxDuration).Info("session channel closed for reaching the maximum duration")
+
+				exceeded = true
+
+				disconnectExceeded(client, agent)
+
+				if err := sess.MaxDurationExceeded(); err != nil {
+					logger.WithError(err).Warn("failed to mark the session as exceeding the maximum duration")
+				}
+
+				return
+			case req, ok := <-globalReqs:
 				if !ok {
 					logger.Trace("global requests is closed")
 
@@ -158,6 +285,12 @@ func DefaultSessionHandler(opts DefaultSessionHandlerOptions) gliderssh.ChannelH
 
 						return
 					}
+
+					// NOTICE: the agent sends keepalives periodically, regardless of the user's activity, so resetting
+					// on them only disconnects the sessions whose agent stopped answering, not the idle ones.
+					if policy.MaxDurationKeepalive {
+						limit.reset()
+					}
 				default:
 					if req.WantReply {
 						if err := req.Reply(false, nil); err != nil {
@@ -174,42 +307,181 @@ func DefaultSessionHandler(opts DefaultSessionHandlerOptions) gliderssh.ChannelH
 
 				logger.Debugf("request from client to agent: %s", req.Type)
 
-				ok, err := agent.SendRequest(req.Type, req.WantReply, req.Payload)
-				if err != nil {
-					logger.WithError(err).Error("failed to send the request from client to agent")
+				// NOTICE: the resume request is handled by the server, reattaching this channel to a detached shell
+				// instead of starting a new one on the agent.
+				if req.Type == ResumeRequestType {
+					var payload struct {
+						UID string
+					}
+
+					if opts.ResumeGrace <= 0 || started || gossh.Unmarshal(req.Payload, &payload) != nil {
+						req.Reply(false, nil) //nolint:errcheck
+
+						continue
+					}
+
+					d, err := reattach(payload.UID, sess)
+					if err != nil {
+						logger.WithError(err).WithField("resumed", payload.UID).Warn("failed to resume the session")
+
+						req.Reply(false, nil) //nolint:errcheck
+
+						continue
+					}
+
+					agent.Close()
+
+					agent, agentReqs, globalReqs = d.agent, d.agentReqs, d.session.AgentGlobalReqs
+					relay, resumed = d.relay, d.finish
+
+					// NOTICE: the resumed shell keeps the deadline of the session that started it.
+					if !d.deadline.IsZero() {
+						limit.stop()
+						limit = newDurationLimit(policy.MaxDuration, d.deadline)
+					}
+
+					started = true
+
+					relay.attach(client)
+
+					wg.Add(1)
+					go func() {
+						<-relay.done
+						wg.Done()
+					}()
+
+					go forward(sess, client, agent, relay.recorder)
+
+					if err := req.Reply(true, nil); err != nil {
+						logger.WithError(err).Error("failed to reply the client for the resume request")
+
+						return
+					}
+
+					logger.WithField("resumed", payload.UID).Info("session resumed")
 
 					continue
 				}
 
-				switch req.Type {
-				case ShellRequestType, ExecRequestType, SubsystemRequestType:
-					// Once the session has been set up, a program is started at the remote end.  The program can be a
-					// shell, an application program, or a subsystem with a host-independent name.  **Only one of these
-					// requests can succeed per channel.**
-					//
-					// https://www.rfc-editor.org/rfc/rfc4254#section-6.5
-					if sess.Handled && req.Type == ShellRequestType {
-						logger.Warn("fail to start a new session before ending the previous one")
+				if req.Type == SubsystemRequestType {
+					var payload struct {
+						Subsystem string
+					}
 
-						if err := req.Reply(false, nil); err != nil {
-							logger.WithError(err).Error("failed to reply the client when data pipe already started")
-						}
+					if err := gossh.Unmarshal(req.Payload, &payload); err != nil || !policy.AllowsSubsystem(payload.Subsystem) {
+						logger.WithField("subsystem", payload.Subsystem).Warn("rejecting a subsystem not allowed by the session policy")
+
+						req.Reply(false, nil) //nolint:errcheck
+
+						continue
+					}
+				}
+
+				// NOTICE: a device restricted to allowed commands refuses interactive shells, as any command could be
+				// run through them. Every command attempted on the device is logged, allowed or not.
+				if req.Type == ShellRequestType && policy.RestrictsCommands() {
+					logger.WithFields(log.Fields{"audit": true, "allowed": false}).
+						Warn("rejecting an interactive shell on a device restricted to allowed commands")
+
+					req.Reply(false, nil) //nolint:errcheck
+
+					continue
+				}
+
+				if req.Type == ExecRequestType {
+					var payload struct {
+						Command string
+					}
+
+					err := gossh.Unmarshal(req.Payload, &payload)
+					allowed := err == nil && policy.AllowsCommand(payload.Command)
+
+					logger.WithFields(log.Fields{"audit": true, "command": payload.Command, "allowed": allowed}).
+						Info("command execution attempted")
+
+					if !allowed {
+						req.Reply(false, nil) //nolint:errcheck
+
+						continue
+					}
+				}
+
+				// Once the session has been set up, a program is started at the remote end.  The program can be a shell, an
+				// application program, or a subsystem with a host-independent name.  **Only one of these requests can
+				// succeed per channel.**
+				//
+				// https://www.rfc-editor.org/rfc/rfc4254#section-6.5
+				//
+				// NOTICE: the check is per channel, so each channel of the same connection can start its own program.
+				program := req.Type == ShellRequestType || req.Type == ExecRequestType || req.Type == SubsystemRequestType
+				if started && program {
+					logger.Warn("fail to start a new session before ending the previous one")
+
+					if err := req.Reply(false, nil); err != nil {
+						logger.WithError(err).Error("failed to reply the client when data pipe already started")
+					}
+
+					continue
+				}
+
+				// NOTICE: the environment variables set by the client pass the same filter as the namespace's default ones,
+				// what are only set when the client doesn't set them itself.
+				if req.Type == EnvRequestType {
+					var payload envRequest
+					if err := gossh.Unmarshal(req.Payload, &payload); err != nil || models.ValidateEnvVar(payload.Name, payload.Value) != nil {
+						logger.WithField("name", payload.Name).Warn("rejecting an environment variable not allowed")
+
+						req.Reply(false, nil) //nolint:errcheck
 
 						continue
 					}
 
+					env[payload.Name] = true
+				}
+
+				if program {
+					if err := injectEnv(agent, policy.DefaultEnvVars, env); err != nil {
+						logger.WithError(err).Warn("failed to set the namespace's default environment variables")
+					}
+				}
+
+				ok, err := agent.SendRequest(req.Type, req.WantReply, req.Payload)
+				if err != nil {
+					logger.WithError(err).Error("failed to send the request from client to agent")
+
+					continue
+				}
+
+				switch req.Type {
+				case ShellRequestType, ExecRequestType, SubsystemRequestType:
 					if err := req.Reply(ok, nil); err != nil {
 						logger.WithError(err).Error("failed to reply the client with right response for pipe request type")
 
 						return
 					}
 
+					// NOTICE: a program refused by the agent isn't started, so the client can still request another
+					// one on the channel.
+					if !ok {
+						logger.WithField("type", req.Type).Warn("the agent refused to start the program")
+
+						continue
+					}
+
+					started = true
+
 					logger.Info("session type set")
 
-					if req.Type == ShellRequestType && sess.Pty.Term != "" {
+					// NOTICE: the announcement for a command goes to stderr, leaving its stdout untouched.
+					switch {
+					case req.Type == ShellRequestType && sess.Pty.Term != "":
 						if err := sess.Announce(client); err != nil {
 							logger.WithError(err).Warn("failed to get the namespace announcement")
 						}
+					case req.Type == ExecRequestType && policy.AnnounceOnExec:
+						if err := sess.Announce(client.Stderr()); err != nil {
+							logger.WithError(err).Warn("failed to get the namespace announcement")
+						}
 					}
 
 					// The server SHOULD NOT halt the execution of the protocol stack when starting a shell or a
@@ -217,6 +489,21 @@ func DefaultSessionHandler(opts DefaultSessionHandlerOptions) gliderssh.ChannelH
 					// encrypted tunnel.
 					//
 					// https://www.rfc-editor.org/rfc/rfc4254#section-6.5
+					if req.Type == ShellRequestType && opts.ResumeGrace > 0 && sess.Pty.Term != "" {
+						relay = newRelay(client)
+						relay.recorder = newSessionRecorder(sess, channelID, opts)
+
+						wg.Add(1)
+						go func() {
+							<-relay.done
+							wg.Done()
+						}()
+
+						go pipeResumable(sess, client, agent, relay)
+
+						continue
+					}
+
 					wg.Add(1)
 					go func() {
 						ch := make(chan bool)
@@ -225,7 +512,7 @@ func DefaultSessionHandler(opts DefaultSessionHandlerOptions) gliderssh.ChannelH
 							wg.Done()
 						}()
 
-						pipe(ctx, sess, client, agent, req.Type, opts, ch)
+						pipe(sess, client, agent, req.Type, channelID, opts, ch)
 					}()
 				case PtyRequestType:
 					var pty session.Pty
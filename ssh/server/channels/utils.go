@@ -6,26 +6,12 @@ import (
 	"sync"
 
 	"github.com/Masterminds/semver"
-	gliderssh "github.com/gliderlabs/ssh"
-	"github.com/shellhub-io/shellhub/pkg/envs"
-	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/ssh/session"
 	log "github.com/sirupsen/logrus"
 	gossh "golang.org/x/crypto/ssh"
 )
 
-func pipe(ctx gliderssh.Context, sess *session.Session, client gossh.Channel, agent gossh.Channel, req string, opts DefaultSessionHandlerOptions, ch chan bool) {
-	defer func() {
-		ctx.Lock()
-		sess.Handled = false
-		ctx.Unlock()
-	}()
-
-	// NOTICE: avoid multiple pipe data in same channel due to protocol limitaion.
-	ctx.Lock()
-	sess.Handled = true
-	ctx.Unlock()
-
+func pipe(sess *session.Session, client gossh.Channel, agent gossh.Channel, req string, channel int, opts DefaultSessionHandlerOptions, ch chan bool) {
 	defer log.
 		WithFields(log.Fields{"session": sess.UID, "sshid": sess.SSHID}).
 		Trace("data pipe between client and agent has done")
@@ -37,7 +23,12 @@ func pipe(ctx gliderssh.Context, sess *session.Session, client gossh.Channel, ag
 	wg := new(sync.WaitGroup)
 	wg.Add(2)
 
-	c := io.MultiReader(client, client.Stderr())
+	var rec *recorder
+	if req == ShellRequestType {
+		rec = newSessionRecorder(sess, channel, opts)
+	}
+
+	c := rec.input(io.MultiReader(client, client.Stderr()))
 	a := io.MultiReader(agent, agent.Stderr())
 
 	go func() {
@@ -75,17 +66,7 @@ func pipe(ctx gliderssh.Context, sess *session.Session, client gossh.Channel, ag
 					break
 				}
 
-				if envs.IsEnterprise() || envs.IsCloud() {
-					message := string(buffer[:read])
-
-					sess.Record(&models.SessionRecorded{ //nolint:errcheck
-						UID:       sess.UID,
-						Namespace: sess.Lookup["domain"],
-						Message:   message,
-						Width:     int(sess.Pty.Columns),
-						Height:    int(sess.Pty.Rows),
-					}, opts.RecordURL)
-				}
+				rec.record(buffer[:read])
 			}
 		} else {
 			if _, err := io.Copy(client, a); err != nil && err != io.EOF {
@@ -121,4 +102,57 @@ func pipe(ctx gliderssh.Context, sess *session.Session, client gossh.Channel, ag
 	}()
 
 	wg.Wait()
+
+	rec.finish(sess)
+}
+
+// pipeResumable pipes the data of a shell that can be resumed. Unlike [pipe], the agent's output goes through the relay,
+// what outlives the client, and the end of the client's input doesn't close the agent's input, keeping the shell
+// running on the agent until it is reattached or its grace period expires.
+func pipeResumable(sess *session.Session, client gossh.Channel, agent gossh.Channel, relay *relay) {
+	if err := sess.Type(ShellRequestType); err != nil {
+		log.WithError(err).Warn("failed to set the session type")
+	}
+
+	rec := relay.recorder
+
+	go func() {
+		defer rec.finish(sess)
+		defer relay.close()
+
+		a := io.MultiReader(agent, agent.Stderr())
+
+		buffer := make([]byte, 1024)
+		for {
+			read, err := a.Read(buffer)
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				log.WithError(err).
+					WithFields(log.Fields{"session": sess.UID, "sshid": sess.SSHID}).
+					Warning("failed to read from stdout in pty client")
+
+				break
+			}
+
+			relay.Write(buffer[:read]) //nolint:errcheck
+
+			rec.record(buffer[:read])
+		}
+	}()
+
+	forward(sess, client, agent, rec)
+}
+
+// forward copies the client's input to the agent of a resumable shell, reporting its activity to the shell's recorder.
+func forward(sess *session.Session, client gossh.Channel, agent gossh.Channel, rec *recorder) {
+	if _, err := io.Copy(agent, rec.input(io.MultiReader(client, client.Stderr()))); err != nil && err != io.EOF {
+		log.WithError(err).
+			WithFields(log.Fields{"session": sess.UID, "sshid": sess.SSHID}).
+			Error("failed on coping data from client to agent")
+	}
+
+	log.Trace("client channel data copy done")
 }
@@ -1,6 +1,9 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -8,7 +11,10 @@ import (
 
 	gliderssh "github.com/gliderlabs/ssh"
 	"github.com/pires/go-proxyproto"
+	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	"github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/httptunnel"
+	"github.com/shellhub-io/shellhub/ssh/pkg/sni"
 	"github.com/shellhub-io/shellhub/ssh/pkg/target"
 	"github.com/shellhub-io/shellhub/ssh/server/auth"
 	"github.com/shellhub-io/shellhub/ssh/server/channels"
@@ -24,23 +30,82 @@ type Options struct {
 	// Agents 0.5.x or earlier do not validate the public key request and may panic.
 	// Please refer to: https://github.com/shellhub-io/shellhub/issues/3453
 	AllowPublickeyAccessBelow060 bool
+	// MaxAgentChannels is the maximum number of channels a single connection can open on the agent at the same time.
+	MaxAgentChannels int
+	// SessionResumeGrace is how long a shell is kept running on the agent after its client drops, waiting to be
+	// resumed. When zero, sessions cannot be resumed.
+	SessionResumeGrace time.Duration
+	// SNIRoutingEnabled wraps the SSH connections in TLS, routing each one to the namespace addressed by the server
+	// name presented on its handshake, in the form "{namespace}.{SNIDomain}".
+	SNIRoutingEnabled bool
+	// SNIDomain is the domain under which the namespaces are addressed. When empty, [sni.DefaultDomain] is used.
+	SNIDomain string
+	// SNICertificate and SNIKey are the paths to the TLS certificate and its private key used when SNIRoutingEnabled
+	// is set.
+	SNICertificate string
+	SNIKey         string
 }
 
 type Server struct {
 	sshd   *gliderssh.Server
 	opts   *Options
 	tunnel *httptunnel.Tunnel
+	// sni is only set when SNI routing is enabled.
+	sni *sni.Resolver
+	// cache is shared by the sessions to reuse what they ask the API, like the evaluations of the public keys. When
+	// nil, nothing is cached.
+	cache cache.Cache
+	// api is the client the sessions talk to the API through.
+	api internalclient.Client
+}
+
+// WithSNIResolver sets the resolver used to route connections by the server name presented on their TLS handshake.
+func (s *Server) WithSNIResolver(resolver *sni.Resolver) *Server {
+	s.sni = resolver
+
+	return s
+}
+
+// WithPublicKeyEvalCache sets the cache the evaluations of the public keys are kept on.
+func (s *Server) WithPublicKeyEvalCache(cache cache.Cache) *Server {
+	s.cache = cache
+
+	return s
+}
+
+// WithInternalClient sets the client the sessions talk to the API through, like one routing each tenant to the API
+// server serving it.
+func (s *Server) WithInternalClient(api internalclient.Client) *Server {
+	s.api = api
+
+	return s
 }
 
 func NewServer(opts *Options, tunnel *httptunnel.Tunnel) *Server {
 	server := &Server{ // nolint: exhaustruct
 		opts:   opts,
 		tunnel: tunnel,
+		api:    internalclient.NewClient(),
 	}
 
 	server.sshd = &gliderssh.Server{ // nolint: exhaustruct
 		Addr: ":2222",
 		ConnCallback: func(ctx gliderssh.Context, conn net.Conn) net.Conn {
+			if tlsConn, ok := conn.(*tls.Conn); ok && server.sni != nil {
+				tenant, err := server.handshake(ctx, tlsConn)
+				if err != nil {
+					log.WithError(err).
+						WithField("remote", conn.RemoteAddr().String()).
+						Info("failed to route the connection by its server name")
+
+					return nil
+				}
+
+				// NOTICE: the tenant is set before any authentication handler runs, restricting the connection to the
+				// devices of the namespace addressed by the client.
+				ctx.SetValue("tenant_id", tenant)
+			}
+
 			ctx.SetValue("conn", conn)
 
 			return conn
@@ -61,7 +126,7 @@ func NewServer(opts *Options, tunnel *httptunnel.Tunnel) *Server {
 				return fmt.Sprintf("%s is not a valid SSHID\n", ctx.User())
 			}
 
-			sess, err := session.NewSession(ctx, tunnel)
+			sess, err := session.NewSession(ctx, tunnel, server.api, server.cache)
 			if err != nil {
 				logger.WithError(err).Error("failed to create the session")
 
@@ -77,6 +142,11 @@ func NewServer(opts *Options, tunnel *httptunnel.Tunnel) *Server {
 			if err := sess.Evaluate(ctx); err != nil {
 				logger.WithError(err).Error("destination device has a firewall to blocked it or a billing issue")
 
+				// NOTICE: the access schedule's error tells the client when the connection is allowed.
+				if errors.Is(err, session.ErrAccessSchedule) {
+					return fmt.Sprintf("%s\n", err)
+				}
+
 				return fmt.Sprintf("you cannot access %s due a policy rule\n", target.Data)
 			}
 
@@ -84,6 +154,9 @@ func NewServer(opts *Options, tunnel *httptunnel.Tunnel) *Server {
 		},
 		PasswordHandler:  auth.PasswordHandler,
 		PublicKeyHandler: auth.PublicKeyHandler,
+		// NOTICE: the keyboard-interactive method is tried by the clients after the public keys, what is the only way
+		// left to tell them why a public key was refused.
+		KeyboardInteractiveHandler: auth.KeyboardInteractiveHandler,
 		// Channels form the foundation of secure communication between clients and servers in SSH connections. A
 		// channel, in the context of SSH, is a logical conduit through which data travels securely between the client
 		// and the server. SSH channels serve as the infrastructure for executing commands, establishing shell sessions,
@@ -91,7 +164,9 @@ func NewServer(opts *Options, tunnel *httptunnel.Tunnel) *Server {
 		ChannelHandlers: map[string]gliderssh.ChannelHandler{
 			channels.SessionChannel: channels.DefaultSessionHandler(
 				channels.DefaultSessionHandlerOptions{
-					RecordURL: opts.RecordURL,
+					RecordURL:        opts.RecordURL,
+					MaxAgentChannels: opts.MaxAgentChannels,
+					ResumeGrace:      opts.SessionResumeGrace,
 				},
 			),
 			channels.DirectTCPIPChannel: channels.DefaultDirectTCPIPHandler,
@@ -115,6 +190,29 @@ func NewServer(opts *Options, tunnel *httptunnel.Tunnel) *Server {
 	return server
 }
 
+// handshake performs the TLS handshake of conn, returning the tenant resolved from the server name presented by the
+// client.
+func (s *Server) handshake(ctx context.Context, conn *tls.Conn) (string, error) {
+	if s.opts.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.ConnectTimeout)
+		defer cancel()
+	}
+
+	if err := conn.HandshakeContext(ctx); err != nil {
+		s.sni.Forget(conn.NetConn())
+
+		return "", err
+	}
+
+	tenant, ok := s.sni.Tenant(conn)
+	if !ok {
+		return "", sni.ErrNamespaceNotFound
+	}
+
+	return tenant, nil
+}
+
 func (s *Server) ListenAndServe() error {
 	log.WithFields(log.Fields{
 		"addr": s.sshd.Addr,
@@ -130,5 +228,27 @@ func (s *Server) ListenAndServe() error {
 	proxy := &proxyproto.Listener{Listener: list} // nolint: exhaustruct
 	defer proxy.Close()
 
+	if s.opts.SNIRoutingEnabled {
+		if s.sni == nil {
+			return errors.New("SNI routing is enabled but there is no resolver")
+		}
+
+		certificate, err := tls.LoadX509KeyPair(s.opts.SNICertificate, s.opts.SNIKey)
+		if err != nil {
+			log.WithError(err).Error("failed to load the TLS certificate used to route by SNI")
+
+			return err
+		}
+
+		config := s.sni.TLSConfig(&tls.Config{ // nolint: exhaustruct
+			Certificates: []tls.Certificate{certificate},
+			MinVersion:   tls.VersionTLS12,
+		})
+
+		log.WithField("domain", s.opts.SNIDomain).Info("routing connections by SNI")
+
+		return s.sshd.Serve(tls.NewListener(proxy, config))
+	}
+
 	return s.sshd.Serve(proxy)
 }
@@ -6,7 +6,9 @@ import (
 
 	"github.com/Masterminds/semver"
 	gliderssh "github.com/gliderlabs/ssh"
+	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/ssh/pkg/magickey"
+	log "github.com/sirupsen/logrus"
 	gossh "golang.org/x/crypto/ssh"
 )
 
@@ -33,6 +35,10 @@ type Auth interface {
 	// Evaluate evaluates the session's context, returning an error if there's something
 	// possibly broken. It's not always necessary.
 	Evaluate(*Session) error
+
+	// Fingerprint returns what identifies the client's credential, recorded on the session
+	// once it's authenticated.
+	Fingerprint() string
 }
 
 type publicKeyAuth struct {
@@ -47,6 +53,10 @@ func (*publicKeyAuth) Method() authMethod {
 	return AuthMethodPublicKey
 }
 
+func (p *publicKeyAuth) Fingerprint() string {
+	return gossh.FingerprintLegacyMD5(p.pk)
+}
+
 func (*publicKeyAuth) Auth() authFunc {
 	return func(session *Session, config *gossh.ClientConfig) error {
 		privateKey, err := session.api.CreatePrivateKey()
@@ -94,24 +104,44 @@ func (p *publicKeyAuth) Evaluate(session *Session) error {
 		}
 	}
 
-	fingerprint := gossh.FingerprintLegacyMD5(p.pk)
-
-	magic, err := gossh.NewPublicKey(&magickey.GetRerefence().PublicKey)
+	magic, err := magickey.IsMagic(p.pk)
 	if err != nil {
 		return err
 	}
 
-	if gossh.FingerprintLegacyMD5(magic) != fingerprint {
-		if _, err = session.api.GetPublicKey(fingerprint, session.Device.TenantID); err != nil {
+	if !magic {
+		fingerprint := gossh.FingerprintLegacyMD5(p.pk)
+
+		eval, err := session.evaluatePublicKey(fingerprint)
+		if err != nil {
+			return err
+		}
+
+		if err := session.checkMemberAccessSchedule(eval.CreatedBy); err != nil {
 			return err
 		}
 
-		if ok, err := session.api.EvaluateKey(fingerprint, session.Device, session.Data.Target.Username); !ok || err != nil {
+		if !eval.Allowed {
 			return ErrEvaluatePublicKey
 		}
+
+		if !models.MatchIPCIDR(eval.AllowedIPs, session.IPAddress) {
+			defer log.WithFields(log.Fields{
+				"uid":         session.UID,
+				"sshid":       session.SSHID,
+				"fingerprint": fingerprint,
+				"ip":          session.IPAddress,
+				"audit":       true,
+			}).Warn("the allowed IPs of the public key blocked this connection")
+
+			return ErrPublicKeyIPNotAllowed
+		}
+
+		// NOTICE: the fingerprint is kept to close the session when the public key is revoked.
+		session.Fingerprint = fingerprint
 	}
 
-	return err
+	return nil
 }
 
 type passwordAuth struct {
@@ -126,6 +156,10 @@ func (*passwordAuth) Method() authMethod {
 	return AuthMethodPassword
 }
 
+func (*passwordAuth) Fingerprint() string {
+	return models.SessionClientFingerprintPassword
+}
+
 func (p *passwordAuth) Auth() authFunc {
 	return func(session *Session, config *gossh.ClientConfig) error {
 		config.Auth = []gossh.AuthMethod{
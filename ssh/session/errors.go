@@ -8,11 +8,20 @@ var (
 	ErrFirewallBlock           = fmt.Errorf("you cannot connect to this device because a firewall rule block your connection")
 	ErrFirewallConnection      = fmt.Errorf("failed to communicate to the firewall")
 	ErrFirewallUnknown         = fmt.Errorf("failed to evaluate the firewall rule")
+	ErrAccessSchedule          = fmt.Errorf("you cannot connect to this device outside the access schedule of its namespace")
+	ErrAccessScheduleUnknown   = fmt.Errorf("failed to evaluate the access schedule of the namespace")
+	ErrMemberAccessSchedule    = fmt.Errorf("you cannot connect to this device with this public key outside the access schedule of its member")
+	ErrMemberAccessScheduleKey = fmt.Errorf("you cannot connect to this device with this public key as it doesn't belong to a member of the namespace, what is required when its members have access schedules")
+	ErrDeviceExpired           = fmt.Errorf("you cannot connect to this device because its access to the namespace has expired")
+	ErrDeviceExpiryUnknown     = fmt.Errorf("failed to evaluate the expiry of the device")
 	ErrHost                    = fmt.Errorf("failed to get the device address")
 	ErrFindDevice              = fmt.Errorf("failed to find the device")
 	ErrDial                    = fmt.Errorf("failed to connect to device agent, please check the device connection")
+	ErrHostKeyMismatch         = fmt.Errorf("the device host key does not match its trusted host key")
+	ErrTenantMismatch          = fmt.Errorf("the device does not belong to the namespace addressed by the connection")
 	ErrInvalidVersion          = fmt.Errorf("failed to parse device version")
 	ErrUnsuportedPublicKeyAuth = fmt.Errorf("connections using public keys are not permitted when the agent version is 0.5.x or earlier")
 	ErrUnexpectedAuthMethod    = fmt.Errorf("failed to authenticate the session due to a unexpected method")
 	ErrEvaluatePublicKey       = fmt.Errorf("failed to evaluate the provided public key")
+	ErrPublicKeyIPNotAllowed   = fmt.Errorf("you cannot connect to this device with this public key from your IP address")
 )
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	log "github.com/sirupsen/logrus"
+)
+
+// keyEval is the evaluation of a public key to access a device with a username.
+type keyEval struct {
+	// Allowed reports whether the public key is allowed to access the device with the username.
+	Allowed bool `json:"allowed"`
+	// CreatedBy is the member who created the public key, whose access schedule is still checked on each
+	// authentication.
+	CreatedBy string `json:"created_by"`
+	// AllowedIPs are the IP addresses and networks the public key can be used from, allowing any IP when empty.
+	AllowedIPs []string `json:"allowed_ips"`
+}
+
+// evaluatePublicKey evaluates the public key with fingerprint to access the session's device with its username,
+// using the evaluation cached for [cache.PublicKeyEvalTTL] when there's one. When the session has no cache, the public
+// key is always evaluated by the API.
+func (s *Session) evaluatePublicKey(fingerprint string) (*keyEval, error) {
+	keyEvalCache := s.cache
+	if keyEvalCache == nil {
+		return s.requestPublicKeyEval(fingerprint)
+	}
+
+	ctx := context.Background()
+	key := cache.PublicKeyEvalKey(fingerprint, s.Device.TenantID, s.Device.UID, s.Device.Name, s.Device.Tags, s.Data.Target.Username)
+	version := cache.PublicKeyEvalVersionKey(fingerprint, s.Device.TenantID)
+
+	var cached *keyEval
+	// NOTICE: failing to read from the cache is not fatal, as the public key can still be evaluated by the API.
+	if err := keyEvalCache.Get(ctx, key, &cached); err == nil && cached != nil {
+		return cached, nil
+	}
+
+	var before string
+	keyEvalCache.Get(ctx, version, &before) //nolint:errcheck
+
+	eval, err := s.requestPublicKeyEval(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := keyEvalCache.Set(ctx, key, eval, cache.PublicKeyEvalTTL); err != nil {
+		log.WithError(err).WithField("uid", s.UID).Warn("failed to cache the evaluation of the public key")
+
+		return eval, nil
+	}
+
+	// NOTICE: when the public key changed while it was evaluated, the evaluation can be stale. As the invalidation
+	// changes the version before deleting the cached evaluations, checking it after caching is enough to not keep it.
+	var after string
+	if err := keyEvalCache.Get(ctx, version, &after); err != nil || after != before {
+		keyEvalCache.Delete(ctx, key) //nolint:errcheck
+	}
+
+	return eval, nil
+}
+
+// requestPublicKeyEval evaluates the public key with fingerprint through the API.
+func (s *Session) requestPublicKeyEval(fingerprint string) (*keyEval, error) {
+	key, err := s.api.GetPublicKey(fingerprint, s.Device.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.api.EvaluateKey(fingerprint, s.Device, s.Data.Target.Username)
+	if err != nil {
+		return nil, ErrEvaluatePublicKey
+	}
+
+	return &keyEval{Allowed: ok, CreatedBy: key.CreatedBy, AllowedIPs: key.AllowedIPs}, nil
+}
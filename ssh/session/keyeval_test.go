@@ -0,0 +1,321 @@
+package session
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	clientmocks "github.com/shellhub-io/shellhub/pkg/api/internalclient/mocks"
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/ssh/pkg/target"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// memoryCache is an in-memory [cache.Cache], safe for concurrent use, implementing only what's needed to cache what the
+// sessions ask the API.
+type memoryCache struct {
+	cache.Cache
+
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: make(map[string]interface{})}
+}
+
+func (m *memoryCache) Get(_ context.Context, key string, value interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stored, ok := m.values[key]; ok {
+		reflect.ValueOf(value).Elem().Set(reflect.ValueOf(stored))
+	}
+
+	return nil
+}
+
+func (m *memoryCache) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.values[key] = value
+
+	return nil
+}
+
+func (m *memoryCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.values, key)
+
+	return nil
+}
+
+func (m *memoryCache) DeletePrefix(_ context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key := range m.values {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.values, key)
+		}
+	}
+
+	return nil
+}
+
+// invalidate invalidates the evaluations of the public key like the API does when it's deleted or its tags change.
+func (m *memoryCache) invalidate(fingerprint, tenant string, version int) {
+	ctx := context.Background()
+
+	m.Set(ctx, cache.PublicKeyEvalVersionKey(fingerprint, tenant), strconv.Itoa(version), cache.PublicKeyEvalTTL) //nolint:errcheck
+	m.DeletePrefix(ctx, cache.PublicKeyEvalPrefix(fingerprint, tenant))                                           //nolint:errcheck
+}
+
+func newKeyEvalSession(api *clientmocks.Client, c cache.Cache) *Session {
+	sess := &Session{UID: "uid", api: api, cache: c}
+	sess.Device = &models.Device{UID: "device", TenantID: "tenant"}
+	sess.Data.Target = &target.Target{Username: "root"}
+
+	return sess
+}
+
+func TestEvaluatePublicKey(t *testing.T) {
+	t.Run("evaluates the public key on the API when the cache is disabled", func(t *testing.T) {
+		api := new(clientmocks.Client)
+		sess := newKeyEvalSession(api, nil)
+
+		api.On("GetPublicKey", "fingerprint", "tenant").Return(&models.PublicKey{CreatedBy: "member"}, nil).Twice()
+		api.On("EvaluateKey", "fingerprint", sess.Device, "root").Return(true, nil).Twice()
+
+		for i := 0; i < 2; i++ {
+			eval, err := sess.evaluatePublicKey("fingerprint")
+			require.NoError(t, err)
+			assert.Equal(t, &keyEval{Allowed: true, CreatedBy: "member"}, eval)
+		}
+
+		api.AssertExpectations(t)
+	})
+
+	t.Run("uses the cached evaluation after the first one", func(t *testing.T) {
+		api := new(clientmocks.Client)
+		sess := newKeyEvalSession(api, newMemoryCache())
+
+		api.On("GetPublicKey", "fingerprint", "tenant").Return(&models.PublicKey{CreatedBy: "member"}, nil).Once()
+		api.On("EvaluateKey", "fingerprint", sess.Device, "root").Return(false, nil).Once()
+
+		for i := 0; i < 3; i++ {
+			eval, err := sess.evaluatePublicKey("fingerprint")
+			require.NoError(t, err)
+			assert.Equal(t, &keyEval{Allowed: false, CreatedBy: "member"}, eval)
+		}
+
+		api.AssertExpectations(t)
+	})
+
+	t.Run("does not cache the evaluations that fail", func(t *testing.T) {
+		api := new(clientmocks.Client)
+		sess := newKeyEvalSession(api, newMemoryCache())
+
+		api.On("GetPublicKey", "fingerprint", "tenant").Return(nil, errors.New("error")).Once()
+		api.On("GetPublicKey", "fingerprint", "tenant").Return(&models.PublicKey{}, nil).Once()
+		api.On("EvaluateKey", "fingerprint", sess.Device, "root").Return(false, errors.New("error")).Once()
+
+		_, err := sess.evaluatePublicKey("fingerprint")
+		assert.Equal(t, errors.New("error"), err)
+
+		_, err = sess.evaluatePublicKey("fingerprint")
+		assert.Equal(t, ErrEvaluatePublicKey, err)
+
+		api.AssertExpectations(t)
+	})
+
+	t.Run("evaluates the public key again after it's invalidated", func(t *testing.T) {
+		memory := newMemoryCache()
+		api := new(clientmocks.Client)
+		sess := newKeyEvalSession(api, memory)
+
+		api.On("GetPublicKey", "fingerprint", "tenant").Return(&models.PublicKey{}, nil).Twice()
+		api.On("EvaluateKey", "fingerprint", sess.Device, "root").Return(true, nil).Once()
+		api.On("EvaluateKey", "fingerprint", sess.Device, "root").Return(false, nil).Once()
+
+		eval, err := sess.evaluatePublicKey("fingerprint")
+		require.NoError(t, err)
+		assert.True(t, eval.Allowed)
+
+		memory.invalidate("fingerprint", "tenant", 1)
+
+		eval, err = sess.evaluatePublicKey("fingerprint")
+		require.NoError(t, err)
+		assert.False(t, eval.Allowed)
+
+		api.AssertExpectations(t)
+	})
+
+	t.Run("evaluates the public key again after the device is renamed or its tags change", func(t *testing.T) {
+		api := new(clientmocks.Client)
+		sess := newKeyEvalSession(api, newMemoryCache())
+
+		api.On("GetPublicKey", "fingerprint", "tenant").Return(&models.PublicKey{}, nil).Times(3)
+		api.On("EvaluateKey", "fingerprint", testifymock.Anything, "root").Return(true, nil).Once()
+		api.On("EvaluateKey", "fingerprint", testifymock.Anything, "root").Return(false, nil).Once()
+		api.On("EvaluateKey", "fingerprint", testifymock.Anything, "root").Return(true, nil).Once()
+
+		eval, err := sess.evaluatePublicKey("fingerprint")
+		require.NoError(t, err)
+		assert.True(t, eval.Allowed)
+
+		sess.Device.Name = "renamed"
+
+		eval, err = sess.evaluatePublicKey("fingerprint")
+		require.NoError(t, err)
+		assert.False(t, eval.Allowed)
+
+		sess.Device.Tags = []string{"production"}
+
+		eval, err = sess.evaluatePublicKey("fingerprint")
+		require.NoError(t, err)
+		assert.True(t, eval.Allowed)
+
+		api.AssertExpectations(t)
+	})
+
+	t.Run("discards the evaluation when the public key is invalidated while it's evaluated", func(t *testing.T) {
+		memory := newMemoryCache()
+		api := new(clientmocks.Client)
+		sess := newKeyEvalSession(api, memory)
+
+		api.On("GetPublicKey", "fingerprint", "tenant").Return(&models.PublicKey{}, nil).Twice()
+		api.On("EvaluateKey", "fingerprint", sess.Device, "root").
+			Run(func(testifymock.Arguments) { memory.invalidate("fingerprint", "tenant", 1) }).
+			Return(true, nil).
+			Once()
+		api.On("EvaluateKey", "fingerprint", sess.Device, "root").Return(false, nil).Once()
+
+		eval, err := sess.evaluatePublicKey("fingerprint")
+		require.NoError(t, err)
+		assert.True(t, eval.Allowed)
+
+		eval, err = sess.evaluatePublicKey("fingerprint")
+		require.NoError(t, err)
+		assert.False(t, eval.Allowed)
+
+		api.AssertExpectations(t)
+	})
+}
+
+func TestEvaluatePublicKeyConcurrently(t *testing.T) {
+	const workers = 32
+
+	memory := newMemoryCache()
+
+	api := new(clientmocks.Client)
+
+	// NOTICE: the public key is allowed until it's invalidated for the last time, when it's denied.
+	var denied atomic.Bool
+	var calls atomic.Int64
+
+	api.On("GetPublicKey", "fingerprint", "tenant").Return(&models.PublicKey{}, nil)
+	api.On("EvaluateKey", "fingerprint", testifymock.Anything, "root").
+		Return(func(string, *models.Device, string) bool {
+			calls.Add(1)
+
+			return !denied.Load()
+		}, nil)
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			sess := newKeyEvalSession(api, memory)
+			for j := 0; j < 10; j++ {
+				_, err := sess.evaluatePublicKey("fingerprint")
+				assert.NoError(t, err)
+
+				if j == 5 {
+					memory.invalidate("fingerprint", "tenant", i)
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	assert.Less(t, calls.Load(), int64(workers*10))
+
+	denied.Store(true)
+	memory.invalidate("fingerprint", "tenant", workers)
+
+	eval, err := newKeyEvalSession(api, memory).evaluatePublicKey("fingerprint")
+	require.NoError(t, err)
+	assert.False(t, eval.Allowed)
+}
+
+func TestPublicKeyAuthEvaluateAllowedIPs(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	pk, err := gossh.NewPublicKey(private.Public())
+	require.NoError(t, err)
+
+	fingerprint := gossh.FingerprintLegacyMD5(pk)
+
+	cases := []struct {
+		description string
+		allowedIPs  []string
+		ip          string
+		expected    error
+	}{
+		{
+			description: "succeeds when the public key has no allowed IPs",
+			allowedIPs:  nil,
+			ip:          "203.0.113.7",
+			expected:    nil,
+		},
+		{
+			description: "succeeds when the IP is allowed",
+			allowedIPs:  []string{"10.0.0.0/8", "203.0.113.7"},
+			ip:          "203.0.113.7",
+			expected:    nil,
+		},
+		{
+			description: "fails when the IP isn't allowed",
+			allowedIPs:  []string{"10.0.0.0/8"},
+			ip:          "203.0.113.7",
+			expected:    ErrPublicKeyIPNotAllowed,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			api := new(clientmocks.Client)
+			sess := newKeyEvalSession(api, nil)
+			sess.Device.Info = &models.DeviceInfo{Version: "latest"}
+			sess.IPAddress = tc.ip
+
+			api.On("GetPublicKey", fingerprint, "tenant").Return(&models.PublicKey{PublicKeyFields: models.PublicKeyFields{AllowedIPs: tc.allowedIPs}}, nil).Once()
+			api.On("EvaluateKey", fingerprint, sess.Device, "root").Return(true, nil).Once()
+			api.On("NamespaceLookup", "tenant").Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
+
+			assert.Equal(t, tc.expected, AuthPublicKey(pk).Evaluate(sess))
+
+			api.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package session
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// lookupNamespace looks up the namespace of the session's device, using the one cached for [cache.NamespaceLookupTTL]
+// when there's one. When the session has no cache, the namespace is always looked up on the API.
+//
+// NOTICE: the API deletes the cached namespace when it changes, but a lookup made concurrently with a change can still
+// cache the namespace as it was before, what is bounded by the TTL.
+func (s *Session) lookupNamespace() (*models.Namespace, []error) {
+	if s.cache == nil {
+		return s.api.NamespaceLookup(s.Device.TenantID)
+	}
+
+	ctx := context.Background()
+	key := cache.NamespaceLookupKey(s.Device.TenantID)
+
+	var cached *models.Namespace
+	// NOTICE: failing to read from the cache is not fatal, as the namespace can still be looked up on the API.
+	if err := s.cache.Get(ctx, key, &cached); err == nil && cached != nil {
+		return cached, nil
+	}
+
+	namespace, errs := s.api.NamespaceLookup(s.Device.TenantID)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err := s.cache.Set(ctx, key, namespace, cache.NamespaceLookupTTL); err != nil {
+		log.WithError(err).WithField("uid", s.UID).Warn("failed to cache the namespace")
+	}
+
+	return namespace, nil
+}
@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	clientmocks "github.com/shellhub-io/shellhub/pkg/api/internalclient/mocks"
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupNamespace(t *testing.T) {
+	namespace := &models.Namespace{TenantID: "tenant", Name: "namespace"}
+
+	t.Run("looks up the namespace on the API when the cache is disabled", func(t *testing.T) {
+		api := new(clientmocks.Client)
+		sess := newKeyEvalSession(api, nil)
+
+		api.On("NamespaceLookup", "tenant").Return(namespace, nil).Twice()
+
+		for i := 0; i < 2; i++ {
+			ns, errs := sess.lookupNamespace()
+			require.Empty(t, errs)
+			assert.Equal(t, namespace, ns)
+		}
+
+		api.AssertExpectations(t)
+	})
+
+	t.Run("uses the cached namespace after the first lookup", func(t *testing.T) {
+		api := new(clientmocks.Client)
+		sess := newKeyEvalSession(api, newMemoryCache())
+
+		api.On("NamespaceLookup", "tenant").Return(namespace, nil).Once()
+
+		for i := 0; i < 3; i++ {
+			ns, errs := sess.lookupNamespace()
+			require.Empty(t, errs)
+			assert.Equal(t, namespace, ns)
+		}
+
+		api.AssertExpectations(t)
+	})
+
+	t.Run("does not cache the lookups that fail", func(t *testing.T) {
+		api := new(clientmocks.Client)
+		sess := newKeyEvalSession(api, newMemoryCache())
+
+		api.On("NamespaceLookup", "tenant").Return(nil, []error{errors.New("error")}).Once()
+		api.On("NamespaceLookup", "tenant").Return(namespace, nil).Once()
+
+		_, errs := sess.lookupNamespace()
+		assert.Equal(t, []error{errors.New("error")}, errs)
+
+		ns, errs := sess.lookupNamespace()
+		require.Empty(t, errs)
+		assert.Equal(t, namespace, ns)
+
+		api.AssertExpectations(t)
+	})
+
+	t.Run("looks up the namespace again once the API invalidates it", func(t *testing.T) {
+		api := new(clientmocks.Client)
+		c := newMemoryCache()
+		sess := newKeyEvalSession(api, c)
+
+		changed := &models.Namespace{TenantID: "tenant", Name: "namespace", Settings: &models.NamespaceSettings{}}
+		api.On("NamespaceLookup", "tenant").Return(namespace, nil).Once()
+		api.On("NamespaceLookup", "tenant").Return(changed, nil).Once()
+
+		ns, _ := sess.lookupNamespace()
+		assert.Equal(t, namespace, ns)
+
+		c.Delete(context.Background(), cache.NamespaceLookupKey("tenant")) //nolint:errcheck
+
+		ns, _ = sess.lookupNamespace()
+		assert.Equal(t, changed, ns)
+
+		api.AssertExpectations(t)
+	})
+}
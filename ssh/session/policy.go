@@ -0,0 +1,192 @@
+package session
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// Policy is the policy applied to a session, resolved from its namespace's settings and its device's session policy,
+// what takes precedence over the namespace's settings.
+type Policy struct {
+	// MaxIdle is how long the session's channels can stay without input from the client. When zero, there is no limit.
+	MaxIdle time.Duration
+	// Record reports whether the session's output must be recorded.
+	Record bool
+	// RecordingIdlePause is how long the session can stay without data in either direction before its recording's
+	// timeline is paused, until data flows again. When zero, the recording is never paused.
+	RecordingIdlePause time.Duration
+	// AllowedSubsystems are the only subsystems that can be requested. When empty, any subsystem is allowed.
+	AllowedSubsystems []string
+	// AllowedCommands are glob patterns of the only commands that can be executed, matched argument by argument. When
+	// not empty, interactive shells and subsystems are refused; when empty, any command is allowed.
+	AllowedCommands []string
+	// DefaultEnvVars are the environment variables set on the session's channels, unless the client sets them itself.
+	DefaultEnvVars map[string]string
+	// AnnounceOnExec reports whether the connection announcement is also shown to the channels executing a command.
+	AnnounceOnExec bool
+	// MaxDuration is how long, from its start, the session can last before its channels are disconnected. When zero,
+	// there is no limit.
+	MaxDuration time.Duration
+	// MaxDurationKeepalive restarts MaxDuration on every keepalive from the agent. As the agent sends them
+	// periodically, regardless of the user's activity, it only disconnects the sessions whose agent stopped sending
+	// them; MaxIdle is what disconnects the idle ones.
+	MaxDurationKeepalive bool
+}
+
+// AllowsSubsystem checks if the subsystem can be requested on the session. When the commands are restricted, every
+// subsystem is refused, as a subsystem like "sftp" would give access beyond the allowed commands.
+func (p *Policy) AllowsSubsystem(name string) bool {
+	if p.RestrictsCommands() {
+		return false
+	}
+
+	return len(p.AllowedSubsystems) == 0 || slices.Contains(p.AllowedSubsystems, name)
+}
+
+// RestrictsCommands reports whether only the allowed commands can be executed on the session, refusing interactive
+// shells and subsystems.
+func (p *Policy) RestrictsCommands() bool {
+	return len(p.AllowedCommands) > 0
+}
+
+// commandMetachars are the characters interpreted by the shell the commands are executed through, which could chain,
+// substitute or redirect commands beyond the allowed ones.
+const commandMetachars = ";&|`$()<>\\'\"\n\r"
+
+// AllowsCommand checks if the command can be executed on the session, matching it against the allowed commands'
+// patterns. Commands with shell metacharacters, or with a ".." path segment, are always refused.
+func (p *Policy) AllowsCommand(command string) bool {
+	if !p.RestrictsCommands() {
+		return true
+	}
+
+	if strings.ContainsAny(command, commandMetachars) {
+		return false
+	}
+
+	args := strings.Fields(command)
+	for _, arg := range args {
+		if slices.Contains(strings.Split(arg, "/"), "..") {
+			return false
+		}
+	}
+
+	for _, pattern := range p.AllowedCommands {
+		if matchArgs(strings.Fields(pattern), args) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchArgs reports whether the arguments match the pattern's ones. A pattern's argument that is only "*" matches any
+// sequence of arguments, including none, while any other is matched against a single argument by [matchArg].
+func matchArgs(pattern, args []string) bool {
+	// NOTICE: on a mismatch, the last "*" seen is made to match one more argument, instead of backtracking through
+	// every "*" of the pattern.
+	i, j := 0, 0
+	star, mark := -1, 0
+	for j < len(args) {
+		switch {
+		case i < len(pattern) && pattern[i] == "*":
+			star, mark = i, j
+			i++
+		case i < len(pattern) && matchArg(pattern[i], args[j]):
+			i++
+			j++
+		case star >= 0:
+			mark++
+			i, j = star+1, mark
+		default:
+			return false
+		}
+	}
+
+	for i < len(pattern) && pattern[i] == "*" {
+		i++
+	}
+
+	return i == len(pattern)
+}
+
+// matchArg reports whether the argument matches the glob pattern, where "*" matches any sequence of characters,
+// including none, and "?" any single character. Unlike [path.Match], "*" also matches "/".
+func matchArg(pattern, arg string) bool {
+	p, a := []rune(pattern), []rune(arg)
+
+	i, j := 0, 0
+	star, mark := -1, 0
+	for j < len(a) {
+		switch {
+		case i < len(p) && p[i] == '*':
+			star, mark = i, j
+			i++
+		case i < len(p) && (p[i] == '?' || p[i] == a[j]):
+			i++
+			j++
+		case star >= 0:
+			mark++
+			i, j = star+1, mark
+		default:
+			return false
+		}
+	}
+
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+
+	return i == len(p)
+}
+
+// resolvePolicy merges the device's session policy over the namespace's settings. When the namespace is nil, what
+// happens when it cannot be retrieved, its recording is considered enabled.
+func resolvePolicy(namespace *models.Namespace, device *models.Device) *Policy {
+	policy := &Policy{Record: true}
+	if namespace != nil && namespace.Settings != nil {
+		policy.Record = namespace.Settings.SessionRecord
+		policy.RecordingIdlePause = time.Duration(namespace.Settings.RecordingIdlePauseMS) * time.Millisecond
+		policy.DefaultEnvVars = namespace.Settings.DefaultEnvVars
+		policy.AnnounceOnExec = namespace.Settings.ExecAnnouncement
+		policy.MaxDuration = time.Duration(namespace.Settings.MaxSessionDurationMinutes) * time.Minute
+		policy.MaxDurationKeepalive = namespace.Settings.MaxSessionKeepaliveEnabled
+	}
+
+	if device != nil {
+		policy.AllowedCommands = device.AllowedCommands
+	}
+
+	if device == nil || device.SessionPolicy == nil {
+		return policy
+	}
+
+	policy.MaxIdle = time.Duration(device.SessionPolicy.MaxIdle) * time.Second
+	policy.Record = policy.Record || device.SessionPolicy.ForceRecording
+	policy.AllowedSubsystems = device.SessionPolicy.AllowedSubsystems
+
+	return policy
+}
+
+// Policy returns the policy applied to the session. It's resolved once, on the first call, consulting the namespace's
+// settings on the API.
+func (s *Session) Policy() *Policy {
+	s.policyOnce.Do(func() {
+		namespace, errs := s.lookupNamespace()
+		if len(errs) > 0 {
+			log.WithError(errs[0]).
+				WithFields(log.Fields{"uid": s.UID, "tenant_id": s.Device.TenantID}).
+				Warn("unable to retrieve the namespace's settings to resolve the session policy")
+
+			namespace = nil
+		}
+
+		s.policy = resolvePolicy(namespace, s.Device)
+	})
+
+	return s.policy
+}
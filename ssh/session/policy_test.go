@@ -0,0 +1,228 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePolicy(t *testing.T) {
+	cases := []struct {
+		description string
+		namespace   *models.Namespace
+		device      *models.Device
+		expected    *Policy
+	}{
+		{
+			description: "records when the namespace is unknown",
+			namespace:   nil,
+			device:      &models.Device{},
+			expected:    &Policy{Record: true},
+		},
+		{
+			description: "follows the namespace's settings when the device has no policy",
+			namespace:   &models.Namespace{Settings: &models.NamespaceSettings{SessionRecord: false}},
+			device:      &models.Device{},
+			expected:    &Policy{Record: false},
+		},
+		{
+			description: "forces the recording when the namespace's recording is disabled",
+			namespace:   &models.Namespace{Settings: &models.NamespaceSettings{SessionRecord: false}},
+			device:      &models.Device{SessionPolicy: &models.DeviceSessionPolicy{ForceRecording: true}},
+			expected:    &Policy{Record: true},
+		},
+		{
+			description: "keeps the namespace's recording when the device does not force it",
+			namespace:   &models.Namespace{Settings: &models.NamespaceSettings{SessionRecord: true}},
+			device:      &models.Device{SessionPolicy: &models.DeviceSessionPolicy{ForceRecording: false}},
+			expected:    &Policy{Record: true},
+		},
+		{
+			description: "applies the device's max idle and allowed subsystems",
+			namespace:   &models.Namespace{Settings: &models.NamespaceSettings{SessionRecord: true}},
+			device: &models.Device{SessionPolicy: &models.DeviceSessionPolicy{
+				MaxIdle:           300,
+				AllowedSubsystems: []string{"sftp"},
+			}},
+			expected: &Policy{MaxIdle: 5 * time.Minute, Record: true, AllowedSubsystems: []string{"sftp"}},
+		},
+		{
+			description: "applies the namespace's recording idle pause",
+			namespace:   &models.Namespace{Settings: &models.NamespaceSettings{SessionRecord: true, RecordingIdlePauseMS: 30000}},
+			device:      &models.Device{},
+			expected:    &Policy{Record: true, RecordingIdlePause: 30 * time.Second},
+		},
+		{
+			description: "applies the device's allowed commands",
+			namespace:   &models.Namespace{Settings: &models.NamespaceSettings{SessionRecord: true}},
+			device:      &models.Device{AllowedCommands: []string{"uptime"}},
+			expected:    &Policy{Record: true, AllowedCommands: []string{"uptime"}},
+		},
+		{
+			description: "applies the namespace's default environment variables",
+			namespace:   &models.Namespace{Settings: &models.NamespaceSettings{DefaultEnvVars: map[string]string{"TERM": "xterm-256color"}}},
+			device:      &models.Device{},
+			expected:    &Policy{DefaultEnvVars: map[string]string{"TERM": "xterm-256color"}},
+		},
+		{
+			description: "applies the namespace's announcement on exec",
+			namespace:   &models.Namespace{Settings: &models.NamespaceSettings{ExecAnnouncement: true}},
+			device:      &models.Device{},
+			expected:    &Policy{AnnounceOnExec: true},
+		},
+		{
+			description: "applies the namespace's maximum session duration",
+			namespace:   &models.Namespace{Settings: &models.NamespaceSettings{MaxSessionDurationMinutes: 90, MaxSessionKeepaliveEnabled: true}},
+			device:      &models.Device{},
+			expected:    &Policy{MaxDuration: 90 * time.Minute, MaxDurationKeepalive: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, resolvePolicy(tc.namespace, tc.device))
+		})
+	}
+}
+
+func TestPolicyAllowsSubsystem(t *testing.T) {
+	assert.True(t, (&Policy{}).AllowsSubsystem("sftp"))
+	assert.True(t, (&Policy{AllowedSubsystems: []string{"sftp"}}).AllowsSubsystem("sftp"))
+	assert.False(t, (&Policy{AllowedSubsystems: []string{"sftp"}}).AllowsSubsystem("netconf"))
+	assert.False(t, (&Policy{AllowedCommands: []string{"uptime"}}).AllowsSubsystem("sftp"))
+	assert.False(t, (&Policy{AllowedSubsystems: []string{"sftp"}, AllowedCommands: []string{"uptime"}}).AllowsSubsystem("sftp"))
+}
+
+func TestPolicyAllowsCommand(t *testing.T) {
+	assert.False(t, (&Policy{}).RestrictsCommands())
+	assert.True(t, (&Policy{}).AllowsCommand("rm -rf /"))
+	assert.True(t, (&Policy{AllowedCommands: []string{"uptime"}}).RestrictsCommands())
+	assert.True(t, (&Policy{AllowedCommands: []string{"uptime"}}).AllowsCommand("  uptime "))
+	assert.True(t, (&Policy{AllowedCommands: []string{"tail  -n ?"}}).AllowsCommand("tail -n\t5"))
+	assert.True(t, (&Policy{AllowedCommands: []string{"uptime", "systemctl status *"}}).AllowsCommand("systemctl status nginx"))
+	assert.False(t, (&Policy{AllowedCommands: []string{"uptime", "systemctl status *"}}).AllowsCommand("systemctl stop nginx"))
+}
+
+func TestPolicyAllowsCommandRefusesBypasses(t *testing.T) {
+	policy := &Policy{AllowedCommands: []string{"systemctl status *", "cat /var/log/*"}}
+
+	cases := []struct {
+		description string
+		command     string
+	}{
+		{description: "refuses chaining with a semicolon", command: "systemctl status x; cat /etc/shadow"},
+		{description: "refuses command substitution", command: "systemctl status $(cat /etc/shadow)"},
+		{description: "refuses variable expansion", command: "systemctl status $HOME"},
+		{description: "refuses backticks", command: "systemctl status `cat /etc/shadow`"},
+		{description: "refuses pipes", command: "systemctl status x | sh"},
+		{description: "refuses logical operators", command: "systemctl status x && sh"},
+		{description: "refuses background jobs", command: "systemctl status x & sh"},
+		{description: "refuses redirections", command: "systemctl status x > /etc/passwd"},
+		{description: "refuses newlines", command: "systemctl status x\nsh"},
+		{description: "refuses carriage returns", command: "systemctl status x\rsh"},
+		{description: "refuses quotes", command: "cat '/var/log/x /etc/shadow'"},
+		{description: "refuses escapes", command: "cat /var/log/x\\ /etc/shadow"},
+		{description: "refuses parent directories", command: "cat /var/log/../../etc/shadow"},
+		{description: "refuses a parent directory at the end", command: "cat /var/log/.."},
+		{description: "refuses extra arguments out of a star's argument", command: "cat /var/log/x /etc/shadow"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.False(t, policy.AllowsCommand(tc.command))
+		})
+	}
+
+	assert.True(t, policy.AllowsCommand("systemctl status nginx sshd"))
+	assert.True(t, policy.AllowsCommand("cat /var/log/nginx/access..log"))
+}
+
+func TestMatchArgs(t *testing.T) {
+	cases := []struct {
+		description string
+		pattern     []string
+		args        []string
+		expected    bool
+	}{
+		{
+			description: "matches an exact command",
+			pattern:     []string{"uptime"},
+			args:        []string{"uptime"},
+			expected:    true,
+		},
+		{
+			description: "does not match a command with extra arguments",
+			pattern:     []string{"uptime"},
+			args:        []string{"uptime", "-p"},
+			expected:    false,
+		},
+		{
+			description: "matches any sequence with a star",
+			pattern:     []string{"cat", "/var/log/*"},
+			args:        []string{"cat", "/var/log/nginx/access.log"},
+			expected:    true,
+		},
+		{
+			description: "matches an empty sequence with a star",
+			pattern:     []string{"ls*"},
+			args:        []string{"ls"},
+			expected:    true,
+		},
+		{
+			description: "matches a single character with a question mark",
+			pattern:     []string{"tail", "-n", "?"},
+			args:        []string{"tail", "-n", "5"},
+			expected:    true,
+		},
+		{
+			description: "does not match many characters with a question mark",
+			pattern:     []string{"tail", "-n", "?"},
+			args:        []string{"tail", "-n", "50"},
+			expected:    false,
+		},
+		{
+			description: "matches multiple stars",
+			pattern:     []string{"docker", "*", "--name", "*"},
+			args:        []string{"docker", "run", "--rm", "--name", "web"},
+			expected:    true,
+		},
+		{
+			description: "does not match when the suffix differs",
+			pattern:     []string{"*.log"},
+			args:        []string{"cat", "access.txt"},
+			expected:    false,
+		},
+		{
+			description: "does not match another argument with a star inside an argument",
+			pattern:     []string{"cat", "/var/log/*"},
+			args:        []string{"cat", "/var/log/syslog", "/etc/shadow"},
+			expected:    false,
+		},
+		{
+			description: "matches many arguments with a star argument",
+			pattern:     []string{"systemctl", "status", "*"},
+			args:        []string{"systemctl", "status", "nginx", "sshd"},
+			expected:    true,
+		},
+		{
+			description: "matches no argument with a star argument",
+			pattern:     []string{"systemctl", "status", "*"},
+			args:        []string{"systemctl", "status"},
+			expected:    true,
+		},
+		{
+			description: "matches anything with a single star",
+			pattern:     []string{"*"},
+			args:        []string{"whoami"},
+			expected:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, matchArgs(tc.pattern, tc.args))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// registry holds every session handled by this SSH server at the moment, indexed by its UID. Sessions are added to it
+// as soon as they are created, before being persisted on the API, and removed when its connection closes.
+var registry sync.Map
+
+// track adds the session to the registry.
+func track(session *Session) {
+	registry.Store(session.UID, session)
+}
+
+// untrack removes the session from the registry.
+func untrack(session *Session) {
+	registry.Delete(session.UID)
+}
+
+// Get returns the session with the specified UID from the registry.
+func Get(uid string) (*Session, bool) {
+	value, ok := registry.Load(uid)
+	if !ok {
+		return nil, false
+	}
+
+	return value.(*Session), true
+}
+
+// Live lists the sessions of a namespace currently in the registry, sorted by their start time. When tenant is empty,
+// sessions from every namespace are listed.
+func Live(tenant string) []models.LiveSession {
+	sessions := make([]models.LiveSession, 0)
+
+	registry.Range(func(_, value any) bool {
+		session := value.(*Session)
+		if session.Device == nil || (tenant != "" && session.Device.TenantID != tenant) {
+			return true
+		}
+
+		sessions = append(sessions, models.LiveSession{
+			UID:       session.UID,
+			DeviceUID: models.UID(session.Device.UID),
+			TenantID:  session.Device.TenantID,
+			Username:  session.Target.Username,
+			IPAddress: session.IPAddress,
+			StartedAt: session.StartedAt,
+			InMemory:  true,
+		})
+
+		return true
+	})
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+	})
+
+	return sessions
+}
+
+// Revoke closes the sessions of a namespace in the registry that were authenticated with the public key with the
+// specified fingerprint, returning how many were closed.
+func Revoke(tenant, fingerprint string) int {
+	revoked := 0
+
+	registry.Range(func(_, value any) bool {
+		session := value.(*Session)
+		if session.Device == nil || session.Device.TenantID != tenant || session.Fingerprint != fingerprint {
+			return true
+		}
+
+		if err := session.Revoke(); err != nil {
+			log.WithError(err).WithField("uid", session.UID).Warn("failed to close the session of a revoked public key")
+		}
+
+		revoked++
+
+		return true
+	})
+
+	return revoked
+}
@@ -0,0 +1,21 @@
+package session
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// Revoke closes the session because the public key it was authenticated with was revoked, like when it's removed from
+// the namespace. The session is finished and the client's connection closed, so the client can't keep using it.
+func (s *Session) Revoke() error {
+	log.WithFields(log.Fields{"uid": s.UID, "fingerprint": s.Fingerprint}).Info("closing the session of a revoked public key")
+
+	err := s.Finish()
+
+	if s.client != nil {
+		if closeErr := s.client.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,58 @@
+package session
+
+import (
+	"sync"
+	"testing"
+
+	clientmocks "github.com/shellhub-io/shellhub/pkg/api/internalclient/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/ssh/pkg/target"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevoke(t *testing.T) {
+	api := new(clientmocks.Client)
+
+	// newSession tracks an open session of the namespace with tenant, authenticated with the public key with
+	// fingerprint.
+	newSession := func(uid, tenant, fingerprint string) (*Session, *client) {
+		c := &client{}
+		s := &Session{
+			UID:    uid,
+			api:    api,
+			client: c,
+			once:   new(sync.Once),
+			Data: Data{
+				Target:      &target.Target{Username: "root"},
+				Device:      &models.Device{UID: "device", TenantID: tenant},
+				Fingerprint: fingerprint,
+			},
+		}
+
+		track(s)
+		t.Cleanup(func() { untrack(s) })
+
+		return s, c
+	}
+
+	_, revoked := newSession("revoked", "tenant", "fingerprint")
+	_, otherKey := newSession("other-key", "tenant", "other")
+	_, otherNamespace := newSession("other-namespace", "other", "fingerprint")
+	_, password := newSession("password", "tenant", "")
+
+	api.On("FinishSession", "revoked").Return(nil).Once()
+
+	assert.Equal(t, 1, Revoke("tenant", "fingerprint"))
+
+	assert.True(t, revoked.closed)
+	_, ok := Get("revoked")
+	assert.False(t, ok)
+
+	for uid, c := range map[string]*client{"other-key": otherKey, "other-namespace": otherNamespace, "password": password} {
+		assert.False(t, c.closed)
+		_, ok := Get(uid)
+		assert.True(t, ok)
+	}
+
+	api.AssertExpectations(t)
+}
@@ -3,15 +3,19 @@ package session
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	gliderssh "github.com/gliderlabs/ssh"
 	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/envs"
 	"github.com/shellhub-io/shellhub/pkg/httptunnel"
@@ -54,14 +58,17 @@ type Data struct {
 	Lookup map[string]string
 	// Pty is the PTY dimension.
 	Pty Pty
-	// Handled check if the session is already handling a "shell", "exec" or a "subsystem".
-	Handled bool
+	// Fingerprint is the fingerprint of the public key the session was authenticated with. It's empty when the
+	// session was authenticated otherwise, like with a password or the magic key.
+	Fingerprint string
 }
 
 // TODO: implement [io.Read] and [io.Write] on session to simplify the data piping.
 type Session struct {
 	// UID is the session's UID.
 	UID string
+	// StartedAt is the moment the session was created on the SSH server.
+	StartedAt time.Time
 
 	// AgentConn is the connection between the Server and Agent.
 	AgentConn net.Conn
@@ -72,20 +79,41 @@ type Session struct {
 
 	api    internalclient.Client
 	tunnel *httptunnel.Tunnel
+	// cache keeps what the session asks the API and can be reused by the next ones, like the evaluations of the
+	// public keys. When nil, nothing is cached.
+	cache cache.Cache
+	// client is the connection with the session's client, used to send it out-of-band requests.
+	client requester
+
+	policyOnce sync.Once
+	policy     *Policy
 
 	once *sync.Once
 
+	// channels is the number of channels currently opened on the agent by this session.
+	channels atomic.Int32
+	// channelIDs is the number of channels ever opened by this session, what identifies each one.
+	channelIDs atomic.Int32
+
 	Data
 }
 
 // NewSession creates a new Session but differs from [New] as it only creates
 // the session without registering, connecting to the agent and etc.
 //
-// It's designed to be used within New.
-func NewSession(ctx gliderssh.Context, tunnel *httptunnel.Tunnel) (*Session, error) {
+// It's designed to be used within New. The session talks to the API through api, bound to the API server serving the
+// device's tenant once the device is found. The cache c, when not nil, is shared by the sessions to reuse what they
+// ask the API.
+func NewSession(ctx gliderssh.Context, tunnel *httptunnel.Tunnel, api internalclient.Client, c cache.Cache) (*Session, error) {
 	snap := getSnapshot(ctx)
 
-	api := internalclient.NewClient()
+	// NOTICE: a connection routed by the server name presented on its TLS handshake already knows its tenant, so even
+	// the device is looked up on the API server serving it.
+	tenant, _ := ctx.Value("tenant_id").(string)
+	if tenant != "" {
+		api = api.ForTenant(tenant)
+	}
+
 	sshid := ctx.User()
 
 	target, err := target.NewTarget(sshid)
@@ -119,6 +147,14 @@ func NewSession(ctx gliderssh.Context, tunnel *httptunnel.Tunnel) (*Session, err
 		return nil, errs[0]
 	}
 
+	// When the connection was routed to a namespace by the server name presented on its TLS handshake, only the
+	// devices of that namespace can be reached through it.
+	if tenant != "" && tenant != device.TenantID {
+		return nil, ErrTenantMismatch
+	}
+
+	api = api.ForTenant(device.TenantID)
+
 	hos, err := host.NewHost(ctx.RemoteAddr().String())
 	if err != nil {
 		log.WithError(err).
@@ -128,9 +164,11 @@ func NewSession(ctx gliderssh.Context, tunnel *httptunnel.Tunnel) (*Session, err
 	}
 
 	session := &Session{
-		UID:    ctx.SessionID(),
-		api:    api,
-		tunnel: tunnel,
+		UID:       ctx.SessionID(),
+		StartedAt: clock.Now(),
+		api:       api,
+		tunnel:    tunnel,
+		cache:     c,
 		Data: Data{
 			IPAddress: hos.Host,
 			Target:    target,
@@ -141,11 +179,24 @@ func NewSession(ctx gliderssh.Context, tunnel *httptunnel.Tunnel) (*Session, err
 		once: new(sync.Once),
 	}
 
+	if conn, ok := ctx.Value(gliderssh.ContextKeyConn).(gossh.Conn); ok {
+		session.client = conn
+	}
+
 	session.Data.Lookup["username"] = target.Username
 	session.Data.Lookup["ip_address"] = hos.Host
 
 	snap.save(session, StateCreated)
 
+	track(session)
+	go func() {
+		// NOTICE: the session can be dropped before being finished, like when the device cannot be reached, so we
+		// rely on the connection's context to remove it from the registry.
+		<-ctx.Done()
+
+		untrack(session)
+	}()
+
 	return session, nil
 }
 
@@ -169,6 +220,101 @@ func (s *Session) checkFirewall() (bool, error) {
 	return true, nil
 }
 
+// checkAccessSchedule checks if the connection happens inside the access schedule of the device's namespace.
+func (s *Session) checkAccessSchedule() (bool, error) {
+	namespace, errs := s.lookupNamespace()
+	if len(errs) > 0 {
+		defer log.WithError(errs[0]).WithFields(log.Fields{
+			"uid":   s.UID,
+			"sshid": s.SSHID,
+		}).Info("failed to get the namespace on access schedule evaluation")
+
+		return false, ErrAccessScheduleUnknown
+	}
+
+	if namespace.Settings == nil || namespace.Settings.AccessSchedule == nil {
+		return true, nil
+	}
+
+	if schedule := namespace.Settings.AccessSchedule; !schedule.Allows(clock.Now()) {
+		defer log.WithFields(log.Fields{
+			"uid":   s.UID,
+			"sshid": s.SSHID,
+			"audit": true,
+		}).Info("the access schedule blocked this connection")
+
+		return false, fmt.Errorf("%w; allowed windows are %s", ErrAccessSchedule, schedule)
+	}
+
+	return true, nil
+}
+
+// checkMemberAccessSchedule checks if the connection happens inside the access schedule of the namespace's member who
+// created the public key used to authenticate. When the namespace has members with access schedules, public keys
+// without a known creator, or whose creator isn't a member anymore, are refused, as the schedule that would restrict
+// them cannot be known.
+func (s *Session) checkMemberAccessSchedule(memberID string) error {
+	namespace, errs := s.lookupNamespace()
+	if len(errs) > 0 {
+		defer log.WithError(errs[0]).WithFields(log.Fields{
+			"uid":   s.UID,
+			"sshid": s.SSHID,
+		}).Info("failed to get the namespace on member access schedule evaluation")
+
+		return ErrAccessScheduleUnknown
+	}
+
+	member, ok := namespace.FindMember(memberID)
+	if !ok {
+		scheduled := slices.ContainsFunc(namespace.Members, func(m models.Member) bool { return m.AccessSchedule != nil })
+		if !scheduled {
+			return nil
+		}
+
+		defer log.WithFields(log.Fields{
+			"uid":    s.UID,
+			"sshid":  s.SSHID,
+			"member": memberID,
+			"audit":  true,
+		}).Info("the public key without a known member was refused as the namespace has member access schedules")
+
+		return ErrMemberAccessScheduleKey
+	}
+
+	if schedule := member.AccessSchedule; schedule != nil && !schedule.Allows(clock.Now()) {
+		defer log.WithFields(log.Fields{
+			"uid":    s.UID,
+			"sshid":  s.SSHID,
+			"member": memberID,
+			"audit":  true,
+		}).Info("the access schedule of the member blocked this connection")
+
+		return fmt.Errorf("%w; allowed windows are %s", ErrMemberAccessSchedule, schedule)
+	}
+
+	return nil
+}
+
+// CheckDeviceExpiry checks if the device's access to its namespace has not ended, what refuses the connection.
+func (s *Session) CheckDeviceExpiry() error {
+	if err := s.api.CheckDeviceExpiry(s.Device.UID); err != nil {
+		defer log.WithError(err).WithFields(log.Fields{
+			"uid":    s.UID,
+			"sshid":  s.SSHID,
+			"device": s.Device.UID,
+			"audit":  true,
+		}).Info("the device expiry blocked this connection")
+
+		if errors.Is(err, internalclient.ErrDeviceExpired) {
+			return ErrDeviceExpired
+		}
+
+		return ErrDeviceExpiryUnknown
+	}
+
+	return nil
+}
+
 func (s *Session) checkBilling() (bool, error) {
 	device, err := s.api.GetDevice(s.Device.UID)
 	if err != nil {
@@ -213,22 +359,37 @@ func (s *Session) register() error {
 	return nil
 }
 
-// Authenticate marks the session as authenticated on the API.
+// Authenticate marks the session as authenticated on the API, recording the fingerprint of the client's credential.
 //
 // It returns an error if authentication fails.
-func (s *Session) authenticate() error {
+func (s *Session) authenticate(fingerprint string) error {
 	value := true
 
 	return s.api.UpdateSession(s.UID, &models.SessionUpdate{
-		Authenticated: &value,
+		Authenticated:     &value,
+		ClientFingerprint: &fingerprint,
 	})
 }
 
+// verifyHostKey checks the host key presented by the agent against the device's trusted host key. Devices without a
+// trusted host key accept any host key.
+func (s *Session) verifyHostKey(_ string, _ net.Addr, key gossh.PublicKey) error {
+	if err := s.Device.VerifyHostKey(key); err != nil {
+		log.WithError(err).
+			WithFields(log.Fields{"session": s.UID, "device": s.Device.UID}).
+			Warn("device presented an untrusted host key")
+
+		return ErrHostKeyMismatch
+	}
+
+	return nil
+}
+
 // connect connects the session's client to the session's agent.
 func (s *Session) connect(ctx gliderssh.Context, authOpt authFunc) error {
 	config := &gossh.ClientConfig{
 		User:            s.Target.Username,
-		HostKeyCallback: gossh.InsecureIgnoreHostKey(), // nolint: gosec
+		HostKeyCallback: s.verifyHostKey,
 	}
 
 	if err := authOpt(s, config); err != nil {
@@ -307,15 +468,17 @@ func (s *Session) Dial(ctx gliderssh.Context) error {
 func (s *Session) Evaluate(ctx gliderssh.Context) error {
 	snap := getSnapshot(ctx)
 
-	if envs.IsCloud() || envs.IsEnterprise() {
-		if ok, err := s.checkFirewall(); err != nil || !ok {
-			return err
-		}
+	if ok, err := s.checkAccessSchedule(); err != nil || !ok {
+		return err
+	}
 
-		if envs.HasBilling() {
-			if ok, err := s.checkBilling(); err != nil || !ok {
-				return err
-			}
+	if ok, err := s.checkFirewall(); err != nil || !ok {
+		return err
+	}
+
+	if (envs.IsCloud() || envs.IsEnterprise()) && envs.HasBilling() {
+		if ok, err := s.checkBilling(); err != nil || !ok {
+			return err
 		}
 	}
 
@@ -364,7 +527,7 @@ func (s *Session) Auth(ctx gliderssh.Context, auth Auth) error {
 			return err
 		}
 
-		if err := sess.authenticate(); err != nil {
+		if err := sess.authenticate(auth.Fingerprint()); err != nil {
 			return err
 		}
 	default:
@@ -395,18 +558,20 @@ func (s *Session) KeepAlive() error {
 }
 
 // Announce is a custom message provided by the end user that can be printed when a new connection within the namespace
-// is established.
+// is established. It's written to w, with the line breaks of a terminal when the session has a pty.
 //
-// Returns the announcement or an error, if any. If no announcement is set, it returns an empty string.
-func (s *Session) Announce(client gossh.Channel) error {
-	if _, err := client.Write([]byte(
-		"Connected to " + s.SSHID + " via ShellHub.\n\r",
-	)); err != nil {
+// Returns an error, if any. If no announcement is set, only the connection message is written.
+func (s *Session) Announce(w io.Writer) error {
+	newline := "\n"
+	if s.Pty.Term != "" {
+		newline = "\n\r"
+	}
+
+	if _, err := io.WriteString(w, "Connected to "+s.SSHID+" via ShellHub."+newline); err != nil {
 		return err
 	}
 
-	namespace, errs := s.api.
-		NamespaceLookup(s.Device.TenantID)
+	namespace, errs := s.lookupNamespace()
 	if len(errs) > 0 {
 		log.WithError(errs[0]).Warn("unable to retrieve the namespace's connection announcement")
 
@@ -419,7 +584,7 @@ func (s *Session) Announce(client gossh.Channel) error {
 		return nil
 	}
 
-	if _, err := client.Write([]byte("Announcement:\n\r")); err != nil {
+	if _, err := io.WriteString(w, "Announcement:"+newline); err != nil {
 		return err
 	}
 
@@ -428,7 +593,7 @@ func (s *Session) Announce(client gossh.Channel) error {
 		return r == ' ' || r == '\n' || r == '\t'
 	})
 
-	if _, err := client.Write([]byte("    " + strings.ReplaceAll(announcement, "\n", "\n\r    ") + "\n\r")); err != nil {
+	if _, err := io.WriteString(w, "    "+strings.ReplaceAll(announcement, "\n", newline+"    ")+newline); err != nil {
 		return err
 	}
 
@@ -438,6 +603,8 @@ func (s *Session) Announce(client gossh.Channel) error {
 // Finish terminate the session between Agent and Client, sending a request to Agent to closes it.
 func (s *Session) Finish() (err error) {
 	s.once.Do(func() {
+		defer untrack(s)
+
 		if s.AgentConn != nil {
 			request, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/ssh/close/%s", s.UID), nil)
 
@@ -474,3 +641,52 @@ func (s *Session) Type(kind string) error {
 		Type: &kind,
 	})
 }
+
+// RecordingPaused reports how long, in milliseconds, the session's recording was paused while it was idle.
+func (s *Session) RecordingPaused(duration int64) error {
+	return s.api.UpdateSession(s.UID, &models.SessionUpdate{
+		RecordingPausedDuration: &duration,
+	})
+}
+
+// MaxDurationExceeded marks the session as disconnected for lasting longer than its maximum duration.
+func (s *Session) MaxDurationExceeded() error {
+	exceeded := true
+
+	return s.api.UpdateSession(s.UID, &models.SessionUpdate{
+		MaxDurationExceeded: &exceeded,
+	})
+}
+
+// AcquireChannel reserves a slot to open a new channel on the agent. It reports false when the session already
+// reached max opened channels, what means the channel must not be opened. When max is less than one, there is no limit.
+//
+// Every successful call must be followed by a [Session.ReleaseChannel] when the channel is closed.
+func (s *Session) AcquireChannel(max int) bool {
+	if max < 1 {
+		s.channels.Add(1)
+
+		return true
+	}
+
+	for {
+		current := s.channels.Load()
+		if current >= int32(max) {
+			return false
+		}
+
+		if s.channels.CompareAndSwap(current, current+1) {
+			return true
+		}
+	}
+}
+
+// NextChannelID returns the ID of a new channel of the session, unique within it, starting from 0.
+func (s *Session) NextChannelID() int {
+	return int(s.channelIDs.Add(1) - 1)
+}
+
+// ReleaseChannel frees a slot reserved by [Session.AcquireChannel].
+func (s *Session) ReleaseChannel() {
+	s.channels.Add(-1)
+}
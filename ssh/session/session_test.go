@@ -0,0 +1,354 @@
+package session
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	clientmocks "github.com/shellhub-io/shellhub/pkg/api/internalclient/mocks"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmocks "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestCheckAccessSchedule(t *testing.T) {
+	// 2024-01-01 was a Monday.
+	now := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	clockMock := new(clockmocks.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	schedule := func(timezone string) *models.AccessSchedule {
+		return &models.AccessSchedule{
+			Timezone:       timezone,
+			AllowedWindows: []models.TimeWindow{{DaysOfWeek: []int{1}, StartTime: "09:00", EndTime: "18:00"}},
+		}
+	}
+
+	cases := []struct {
+		description   string
+		requiredMocks func(api *clientmocks.Client)
+		ok            bool
+		err           error
+	}{
+		{
+			description: "fails when the namespace cannot be retrieved",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(nil, []error{internalclient.ErrUnknown}).
+					Once()
+			},
+			ok:  false,
+			err: ErrAccessScheduleUnknown,
+		},
+		{
+			description: "fails when the connection is outside the access schedule",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(&models.Namespace{Settings: &models.NamespaceSettings{AccessSchedule: schedule("")}}, nil).
+					Once()
+			},
+			ok:  false,
+			err: ErrAccessSchedule,
+		},
+		{
+			description: "succeeds when the connection is inside the access schedule in its timezone",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(&models.Namespace{Settings: &models.NamespaceSettings{AccessSchedule: schedule("America/Sao_Paulo")}}, nil).
+					Once()
+			},
+			ok:  true,
+			err: nil,
+		},
+		{
+			description: "succeeds when the namespace has no access schedule",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(&models.Namespace{Settings: &models.NamespaceSettings{}}, nil).
+					Once()
+			},
+			ok:  true,
+			err: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			api := new(clientmocks.Client)
+			tc.requiredMocks(api)
+
+			sess := &Session{api: api}
+			sess.Device = &models.Device{TenantID: "00000000-0000-4000-0000-000000000000"}
+
+			ok, err := sess.checkAccessSchedule()
+			assert.Equal(t, tc.ok, ok)
+			assert.ErrorIs(t, err, tc.err)
+
+			api.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCheckMemberAccessSchedule(t *testing.T) {
+	// 2024-01-01 was a Monday.
+	now := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	clockMock := new(clockmocks.Clock)
+	clock.DefaultBackend = clockMock
+	clockMock.On("Now").Return(now)
+
+	namespace := func(schedule *models.AccessSchedule) *models.Namespace {
+		return &models.Namespace{
+			Settings: &models.NamespaceSettings{},
+			Members:  []models.Member{{ID: "member", AccessSchedule: schedule}},
+		}
+	}
+
+	cases := []struct {
+		description   string
+		member        string
+		requiredMocks func(api *clientmocks.Client)
+		err           error
+	}{
+		{
+			description: "succeeds when the public key has no known creator and no member has an access schedule",
+			member:      "",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(namespace(nil), nil).
+					Once()
+			},
+			err: nil,
+		},
+		{
+			description: "fails when the public key has no known creator and a member has an access schedule",
+			member:      "",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(namespace(&models.AccessSchedule{
+						AllowedWindows: []models.TimeWindow{{DaysOfWeek: []int{1}, StartTime: "09:00", EndTime: "18:00"}},
+					}), nil).
+					Once()
+			},
+			err: ErrMemberAccessScheduleKey,
+		},
+		{
+			description: "fails when the namespace cannot be retrieved",
+			member:      "member",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(nil, []error{internalclient.ErrUnknown}).
+					Once()
+			},
+			err: ErrAccessScheduleUnknown,
+		},
+		{
+			description: "fails when the connection is outside the member's access schedule",
+			member:      "member",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(namespace(&models.AccessSchedule{
+						AllowedWindows: []models.TimeWindow{
+							{DaysOfWeek: []int{1}, StartTime: "09:00", EndTime: "12:00"},
+							{DaysOfWeek: []int{1}, StartTime: "13:00", EndTime: "18:00"},
+						},
+					}), nil).
+					Once()
+			},
+			err: ErrMemberAccessSchedule,
+		},
+		{
+			description: "succeeds when the connection is inside one of the member's windows in its timezone",
+			member:      "member",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(namespace(&models.AccessSchedule{
+						Timezone: "America/Sao_Paulo",
+						AllowedWindows: []models.TimeWindow{
+							{DaysOfWeek: []int{1}, StartTime: "09:00", EndTime: "12:00"},
+							{DaysOfWeek: []int{1}, StartTime: "13:00", EndTime: "18:00"},
+						},
+					}), nil).
+					Once()
+			},
+			err: nil,
+		},
+		{
+			description: "succeeds when the member has no access schedule",
+			member:      "member",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(namespace(nil), nil).
+					Once()
+			},
+			err: nil,
+		},
+		{
+			description: "fails when the creator is not a member anymore and a member has an access schedule",
+			member:      "removed",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+					Return(namespace(&models.AccessSchedule{
+						AllowedWindows: []models.TimeWindow{{DaysOfWeek: []int{2}, StartTime: "09:00", EndTime: "18:00"}},
+					}), nil).
+					Once()
+			},
+			err: ErrMemberAccessScheduleKey,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			api := new(clientmocks.Client)
+			tc.requiredMocks(api)
+
+			sess := &Session{api: api}
+			sess.Device = &models.Device{TenantID: "00000000-0000-4000-0000-000000000000"}
+
+			err := sess.checkMemberAccessSchedule(tc.member)
+			assert.ErrorIs(t, err, tc.err)
+
+			api.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCheckDeviceExpiry(t *testing.T) {
+	cases := []struct {
+		description   string
+		requiredMocks func(api *clientmocks.Client)
+		err           error
+	}{
+		{
+			description: "fails when the expiry cannot be evaluated",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("CheckDeviceExpiry", "device").
+					Return(internalclient.ErrConnectionFailed).
+					Once()
+			},
+			err: ErrDeviceExpiryUnknown,
+		},
+		{
+			description: "fails when the device access has expired",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("CheckDeviceExpiry", "device").
+					Return(internalclient.ErrDeviceExpired).
+					Once()
+			},
+			err: ErrDeviceExpired,
+		},
+		{
+			description: "succeeds when the device access has not expired",
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("CheckDeviceExpiry", "device").
+					Return(nil).
+					Once()
+			},
+			err: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			api := new(clientmocks.Client)
+			tc.requiredMocks(api)
+
+			sess := &Session{api: api}
+			sess.Device = &models.Device{UID: "device"}
+
+			assert.Equal(t, tc.err, sess.CheckDeviceExpiry())
+
+			api.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	key, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	pk, err := gossh.NewPublicKey(key)
+	require.NoError(t, err)
+
+	authenticated := true
+
+	cases := []struct {
+		description string
+		auth        Auth
+		fingerprint string
+	}{
+		{
+			description: "records the fingerprint of the public key",
+			auth:        AuthPublicKey(pk),
+			fingerprint: gossh.FingerprintLegacyMD5(pk),
+		},
+		{
+			description: "records the password marker",
+			auth:        AuthPassword("password"),
+			fingerprint: models.SessionClientFingerprintPassword,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.NotEmpty(t, tc.auth.Fingerprint())
+
+			api := new(clientmocks.Client)
+			api.On("UpdateSession", "uid", &models.SessionUpdate{Authenticated: &authenticated, ClientFingerprint: &tc.fingerprint}).
+				Return(nil).
+				Once()
+
+			sess := &Session{UID: "uid", api: api}
+			assert.NoError(t, sess.authenticate(tc.auth.Fingerprint()))
+
+			api.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAnnounce(t *testing.T) {
+	cases := []struct {
+		description string
+		term        string
+		expected    string
+	}{
+		{
+			description: "writes the announcement with the line breaks of a terminal when the session has a pty",
+			term:        "xterm",
+			expected:    "Connected to user@device via ShellHub.\n\rAnnouncement:\n\r    hello\n\r    world\n\r",
+		},
+		{
+			description: "writes the announcement with plain line breaks when the session has no pty",
+			term:        "",
+			expected:    "Connected to user@device via ShellHub.\nAnnouncement:\n    hello\n    world\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			api := new(clientmocks.Client)
+			api.On("NamespaceLookup", "00000000-0000-4000-0000-000000000000").
+				Return(&models.Namespace{Settings: &models.NamespaceSettings{ConnectionAnnouncement: "hello\nworld\n"}}, nil).
+				Once()
+
+			sess := &Session{api: api}
+			sess.SSHID = "user@device"
+			sess.Pty = Pty{Term: tc.term}
+			sess.Device = &models.Device{TenantID: "00000000-0000-4000-0000-000000000000"}
+
+			var out strings.Builder
+			require.NoError(t, sess.Announce(&out))
+			assert.Equal(t, tc.expected, out.String())
+
+			api.AssertExpectations(t)
+		})
+	}
+}
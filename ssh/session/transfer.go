@@ -0,0 +1,67 @@
+package session
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// TransferRequestType is the out-of-band request sent to the session's client to ask it to accept a transfer.
+const TransferRequestType = "session-transfer-request"
+
+var (
+	ErrTransferRejected = errors.New("the client rejected the session transfer")
+	ErrTransferTimeout  = errors.New("the client did not answer the session transfer in time")
+)
+
+// transferTimeout is how long the session's client has to answer a transfer request.
+var transferTimeout = models.SessionTransferTimeout
+
+// requester sends out-of-band requests to the session's client. It's satisfied by [gossh.Conn].
+type requester interface {
+	SendRequest(name string, wantReply bool, payload []byte) (bool, []byte, error)
+	Close() error
+}
+
+// transferRequest is the payload of the [TransferRequestType] request.
+type transferRequest struct {
+	// Member is the ID of the namespace member who receives the session.
+	Member string
+}
+
+// Transfer asks the session's client to hand the session over to a namespace member. When the client accepts it
+// within the transfer timeout, the session is finished and the client's connection closed, leaving the member to open
+// a new one. Otherwise, the session goes on untouched.
+func (s *Session) Transfer(member string) error {
+	if s.client == nil {
+		return ErrTransferRejected
+	}
+
+	answer := make(chan bool, 1)
+	go func() {
+		ok, _, err := s.client.SendRequest(TransferRequestType, true, gossh.Marshal(&transferRequest{Member: member}))
+		if err != nil {
+			log.WithError(err).WithField("uid", s.UID).Warn("failed to send the session transfer request")
+		}
+
+		answer <- err == nil && ok
+	}()
+
+	select {
+	case ok := <-answer:
+		if !ok {
+			return ErrTransferRejected
+		}
+	case <-time.After(transferTimeout):
+		return ErrTransferTimeout
+	}
+
+	if err := s.Finish(); err != nil {
+		return err
+	}
+
+	return s.client.Close()
+}
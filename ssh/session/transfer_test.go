@@ -0,0 +1,117 @@
+package session
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	clientmocks "github.com/shellhub-io/shellhub/pkg/api/internalclient/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/ssh/pkg/target"
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// client is a fake session's client answering the requests with reply.
+type client struct {
+	reply  func(name string, payload []byte) (bool, error)
+	closed bool
+}
+
+func (c *client) SendRequest(name string, _ bool, payload []byte) (bool, []byte, error) {
+	ok, err := c.reply(name, payload)
+
+	return ok, nil, err
+}
+
+func (c *client) Close() error {
+	c.closed = true
+
+	return nil
+}
+
+func TestTransfer(t *testing.T) {
+	transferTimeout = 50 * time.Millisecond
+	t.Cleanup(func() {
+		transferTimeout = models.SessionTransferTimeout
+	})
+
+	cases := []struct {
+		description   string
+		reply         func(name string, payload []byte) (bool, error)
+		requiredMocks func(api *clientmocks.Client)
+		closed        bool
+		err           error
+	}{
+		{
+			description: "fails when the client rejects the transfer",
+			reply: func(_ string, _ []byte) (bool, error) {
+				return false, nil
+			},
+			requiredMocks: func(_ *clientmocks.Client) {},
+			closed:        false,
+			err:           ErrTransferRejected,
+		},
+		{
+			description: "fails when the request cannot be sent to the client",
+			reply: func(_ string, _ []byte) (bool, error) {
+				return false, errors.New("error")
+			},
+			requiredMocks: func(_ *clientmocks.Client) {},
+			closed:        false,
+			err:           ErrTransferRejected,
+		},
+		{
+			description: "fails when the client does not answer in time",
+			reply: func(_ string, _ []byte) (bool, error) {
+				time.Sleep(200 * time.Millisecond)
+
+				return true, nil
+			},
+			requiredMocks: func(_ *clientmocks.Client) {},
+			closed:        false,
+			err:           ErrTransferTimeout,
+		},
+		{
+			description: "succeeds closing the session when the client accepts the transfer",
+			reply: func(name string, payload []byte) (bool, error) {
+				var req transferRequest
+				if err := gossh.Unmarshal(payload, &req); err != nil {
+					return false, err
+				}
+
+				return name == TransferRequestType && req.Member == "member", nil
+			},
+			requiredMocks: func(api *clientmocks.Client) {
+				api.On("FinishSession", "uid").Return(nil).Once()
+			},
+			closed: true,
+			err:    nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			api := new(clientmocks.Client)
+			tc.requiredMocks(api)
+
+			c := &client{reply: tc.reply}
+			s := &Session{
+				UID:    "uid",
+				api:    api,
+				client: c,
+				once:   new(sync.Once),
+				Data: Data{
+					Target: &target.Target{Username: "root"},
+					Device: &models.Device{UID: "device"},
+				},
+			}
+
+			assert.ErrorIs(t, s.Transfer("member"), tc.err)
+			assert.Equal(t, tc.closed, c.closed)
+
+			api.AssertExpectations(t)
+		})
+	}
+}
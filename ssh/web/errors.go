@@ -57,3 +57,13 @@ var (
 )
 
 var ErrCreditialsNoPassword = errors.New("this creditials does not have a password defined")
+
+var (
+	ErrWebRTCOffer         = errors.New("failed to parse the SDP offer")
+	ErrWebRTCCandidate     = errors.New("failed to parse the ICE candidate")
+	ErrWebRTCPeerNotFound  = errors.New("failed to find the peer connection")
+	ErrWebRTCPeerExists    = errors.New("the terminal already has a peer connection")
+	ErrWebRTCNegotiation   = errors.New("failed to negotiate the peer connection")
+	ErrWebRTCDataChannel   = errors.New("failed to open the data channel")
+	ErrWebRTCGatheringDone = errors.New("the ICE gathering was interrupted")
+)
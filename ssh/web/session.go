@@ -2,7 +2,6 @@ package web
 
 import (
 	"bytes"
-	"crypto/rsa"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -29,20 +28,20 @@ func (b *BannerError) Error() string {
 	return b.Message
 }
 
-// getAuth gets the authentication methods from credentials.
-func getAuth(creds *Credentials, magicKey *rsa.PrivateKey) ([]ssh.AuthMethod, error) {
+// getAuth gets the authentication methods from credentials, asking the API through api.
+func getAuth(api internalclient.Client, creds *Credentials) ([]ssh.AuthMethod, error) {
 	if creds.isPassword() {
 		return []ssh.AuthMethod{ssh.Password(creds.Password)}, nil
 	}
 
-	cli := internalclient.NewClient()
-
 	// Trys to get a device from the API.
-	device, err := cli.GetDevice(creds.Device)
+	device, err := api.GetDevice(creds.Device)
 	if err != nil {
 		return nil, ErrFindDevice
 	}
 
+	cli := api.ForTenant(device.TenantID)
+
 	// Trys to get a public key from the API.
 	key, err := cli.GetPublicKey(creds.Fingerprint, device.TenantID)
 	if err != nil {
@@ -76,7 +75,7 @@ func getAuth(creds *Credentials, magicKey *rsa.PrivateKey) ([]ssh.AuthMethod, er
 		return nil, ErrVerifyPublicKey
 	}
 
-	signer, err := ssh.NewSignerFromKey(magicKey)
+	signer, err := magickey.Signer()
 	if err != nil {
 		return nil, ErrSignerPublicKey
 	}
@@ -84,7 +83,7 @@ func getAuth(creds *Credentials, magicKey *rsa.PrivateKey) ([]ssh.AuthMethod, er
 	return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil
 }
 
-func newSession(conn *Conn, creds *Credentials, dim Dimensions, info Info) error {
+func newSession(api internalclient.Client, conn *Conn, creds *Credentials, dim Dimensions, info Info) error {
 	log.WithFields(log.Fields{
 		"user":   creds.Username,
 		"device": creds.Device,
@@ -100,7 +99,7 @@ func newSession(conn *Conn, creds *Credentials, dim Dimensions, info Info) error
 	}).Info("handling web client request end")
 
 	user := fmt.Sprintf("%s@%s", creds.Username, creds.Device)
-	auth, err := getAuth(creds, magickey.GetRerefence())
+	auth, err := getAuth(api, creds)
 	if err != nil {
 		return ErrGetAuth
 	}
@@ -6,17 +6,27 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/pion/webrtc/v3"
+	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	"github.com/shellhub-io/shellhub/ssh/pkg/magickey"
 	"github.com/shellhub-io/shellhub/ssh/web/pkg/token"
 	"golang.org/x/net/websocket"
 )
 
-// NewSSHServerBridge creates routes into a [echo.Router] to connect a webscoket to SSH using Shell session.
-func NewSSHServerBridge(router *echo.Echo) {
+// NewSSHServerBridge creates routes into a [echo.Router] to connect a webscoket to SSH using Shell session. The public
+// keys of the web sessions are evaluated through api. When webRTCEnabled is true, the sessions can also be connected
+// through a WebRTC data channel, negotiated on [WebRTCOfferRoute] and [WebRTCICERoute].
+func NewSSHServerBridge(router *echo.Echo, api internalclient.Client, webRTCEnabled bool) {
 	const WebsocketSSHBridgeRoute = "/ws/ssh"
 
 	manager := newManager(30 * time.Second)
 
+	if webRTCEnabled {
+		newWebRTCBridge(webrtc.SettingEngine{}, manager, func(conn *Conn, creds *Credentials, dim Dimensions, info Info) error {
+			return newSession(api, conn, creds, dim, info)
+		}).register(router)
+	}
+
 	// NOTICE: this is the route that users send your credentials securely.
 	router.Add(http.MethodPost, WebsocketSSHBridgeRoute, echo.WrapHandler(
 		http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
@@ -105,6 +115,7 @@ func NewSSHServerBridge(router *echo.Echo) {
 		creds.decryptPassword(magickey.GetRerefence()) //nolint:errcheck
 
 		if err := newSession(
+			api,
 			conn,
 			creds,
 			Dimensions{cols, rows},
@@ -0,0 +1,191 @@
+package web
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pion/webrtc/v3"
+	"github.com/shellhub-io/shellhub/ssh/pkg/magickey"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// WebRTCOfferRoute is the route that receives the SDP offer of a browser terminal, answering it with the server's
+	// one. The uid is the token issued to the terminal's credentials by the POST to the WebSocket bridge route, as the
+	// session only gets its UID once it's established.
+	WebRTCOfferRoute = "/api/sessions/:uid/webrtc/offer"
+	// WebRTCICERoute is the route that receives the ICE candidates trickled by a browser terminal after its offer.
+	WebRTCICERoute = "/api/sessions/:uid/webrtc/ice"
+)
+
+// WebRTCDataChannelLabel is the label of the data channel, opened by the browser, that carries the terminal's
+// messages, the same ones sent through the WebSocket, and the output of its shell.
+const WebRTCDataChannelLabel = "ssh-channel"
+
+// WebRTCOffer is the body of the request to [WebRTCOfferRoute].
+type WebRTCOffer struct {
+	SDP  webrtc.SessionDescription `json:"sdp"`
+	Cols int                       `json:"cols"`
+	Rows int                       `json:"rows"`
+}
+
+// sessionFunc opens a shell, on the device set on the credentials, proxying the messages received from conn to it and
+// its output back to conn until it ends.
+type sessionFunc func(conn *Conn, creds *Credentials, dim Dimensions, info Info) error
+
+// webrtcBridge connects the browser terminals to SSH through WebRTC data channels, avoiding the buffering of the
+// WebSocket.
+type webrtcBridge struct {
+	api     *webrtc.API
+	manager *manager
+	// peers maps the uid of each terminal to its [webrtc.PeerConnection], until it's closed.
+	peers   *sync.Map
+	session sessionFunc
+}
+
+// newWebRTCBridge creates a [webrtcBridge] for the credentials saved on manager, opening the shells through session.
+func newWebRTCBridge(engine webrtc.SettingEngine, manager *manager, session sessionFunc) *webrtcBridge {
+	// NOTICE: the data channels are detached to be used as a [Socket] by [Conn], like the WebSocket.
+	engine.DetachDataChannels()
+
+	return &webrtcBridge{
+		api:     webrtc.NewAPI(webrtc.WithSettingEngine(engine)),
+		manager: manager,
+		peers:   new(sync.Map),
+		session: session,
+	}
+}
+
+// register adds the signaling routes of the bridge into router.
+func (b *webrtcBridge) register(router *echo.Echo) {
+	router.Add(http.MethodPost, WebRTCOfferRoute, b.offer)
+	router.Add(http.MethodPost, WebRTCICERoute, b.candidate)
+}
+
+type webrtcFail struct {
+	Error string `json:"error"`
+}
+
+// offer answers the SDP offer of a terminal, opening its shell once the browser opens the [WebRTCDataChannelLabel]
+// data channel. The answer is only sent after the server's ICE candidates are gathered, so they are all on it.
+func (b *webrtcBridge) offer(c echo.Context) error {
+	uid := c.Param("uid")
+
+	creds, ok := b.manager.get(uid)
+	if !ok {
+		return c.JSON(http.StatusNotFound, webrtcFail{Error: ErrBridgeCredentialsNotFound.Error()})
+	}
+
+	var req WebRTCOffer
+	if err := c.Bind(&req); err != nil || req.SDP.Type != webrtc.SDPTypeOffer {
+		return c.JSON(http.StatusBadRequest, webrtcFail{Error: ErrWebRTCOffer.Error()})
+	}
+
+	ip, err := getIP(c.Request())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, webrtcFail{Error: ErrWebSocketGetIP.Error()})
+	}
+
+	peer, err := b.api.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, webrtcFail{Error: ErrWebRTCNegotiation.Error()})
+	}
+
+	if _, loaded := b.peers.LoadOrStore(uid, peer); loaded {
+		peer.Close() //nolint:errcheck
+
+		return c.JSON(http.StatusConflict, webrtcFail{Error: ErrWebRTCPeerExists.Error()})
+	}
+
+	logger := log.WithFields(log.Fields{"user": creds.Username, "device": creds.Device, "ip": ip})
+
+	peer.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		switch state { //nolint:exhaustive
+		case webrtc.PeerConnectionStateFailed, webrtc.PeerConnectionStateClosed:
+			b.peers.CompareAndDelete(uid, peer)
+			peer.Close() //nolint:errcheck
+		}
+	})
+
+	peer.OnDataChannel(func(channel *webrtc.DataChannel) {
+		if channel.Label() != WebRTCDataChannelLabel {
+			channel.Close() //nolint:errcheck
+
+			return
+		}
+
+		channel.OnOpen(func() {
+			socket, err := channel.Detach()
+			if err != nil {
+				logger.WithError(err).Error(ErrWebRTCDataChannel.Error())
+				peer.Close() //nolint:errcheck
+
+				return
+			}
+
+			go func() {
+				defer peer.Close() //nolint:errcheck
+
+				conn := NewConn(socket)
+				defer conn.Close()
+
+				creds.decryptPassword(magickey.GetRerefence()) //nolint:errcheck
+
+				if err := b.session(conn, creds, Dimensions{req.Cols, req.Rows}, Info{IP: ip}); err != nil {
+					conn.Write([]byte(err.Error())) //nolint:errcheck
+				}
+			}()
+		})
+	})
+
+	// fail closes the peer connection, as the terminal can't use it, responding with err.
+	fail := func(status int, err error) error {
+		b.peers.CompareAndDelete(uid, peer)
+		peer.Close() //nolint:errcheck
+
+		return c.JSON(status, webrtcFail{Error: err.Error()})
+	}
+
+	if err := peer.SetRemoteDescription(req.SDP); err != nil {
+		return fail(http.StatusBadRequest, ErrWebRTCOffer)
+	}
+
+	answer, err := peer.CreateAnswer(nil)
+	if err != nil {
+		return fail(http.StatusInternalServerError, ErrWebRTCNegotiation)
+	}
+
+	gathered := webrtc.GatheringCompletePromise(peer)
+
+	if err := peer.SetLocalDescription(answer); err != nil {
+		return fail(http.StatusInternalServerError, ErrWebRTCNegotiation)
+	}
+
+	select {
+	case <-gathered:
+	case <-c.Request().Context().Done():
+		return fail(http.StatusRequestTimeout, ErrWebRTCGatheringDone)
+	}
+
+	return c.JSON(http.StatusOK, peer.LocalDescription())
+}
+
+// candidate adds an ICE candidate trickled by a terminal to its peer connection.
+func (b *webrtcBridge) candidate(c echo.Context) error {
+	value, ok := b.peers.Load(c.Param("uid"))
+	if !ok {
+		return c.JSON(http.StatusNotFound, webrtcFail{Error: ErrWebRTCPeerNotFound.Error()})
+	}
+
+	var candidate webrtc.ICECandidateInit
+	if err := c.Bind(&candidate); err != nil {
+		return c.JSON(http.StatusBadRequest, webrtcFail{Error: ErrWebRTCCandidate.Error()})
+	}
+
+	if err := value.(*webrtc.PeerConnection).AddICECandidate(candidate); err != nil {
+		return c.JSON(http.StatusBadRequest, webrtcFail{Error: ErrWebRTCCandidate.Error()})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,160 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWebRTCServer serves a [webrtcBridge] whose sessions echo the input messages received through the data channel,
+// with the credentials saved to the uid "uid".
+func newWebRTCServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	manager := newManager(time.Minute)
+	manager.save("uid", &Credentials{Device: "device", Username: "root"})
+
+	engine := webrtc.SettingEngine{}
+	engine.SetIncludeLoopbackCandidate(true)
+
+	bridge := newWebRTCBridge(engine, manager, func(conn *Conn, _ *Credentials, _ Dimensions, _ Info) error {
+		for {
+			var message Message
+			if _, err := conn.ReadMessage(&message); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+
+				return err
+			}
+
+			if message.Kind == messageKindInput {
+				if _, err := conn.Write(message.Data.([]byte)); err != nil {
+					return err
+				}
+			}
+		}
+	})
+
+	router := echo.New()
+	bridge.register(router)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// post sends body, as JSON, to the path of server.
+func post(t *testing.T, server *httptest.Server, path string, body any) *http.Response {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+path, bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Real-Ip", "127.0.0.1")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { res.Body.Close() })
+
+	return res
+}
+
+func TestWebRTCBridge(t *testing.T) {
+	t.Run("fails to answer an offer for unknown credentials", func(t *testing.T) {
+		server := newWebRTCServer(t)
+
+		res := post(t, server, "/api/sessions/unknown/webrtc/offer", WebRTCOffer{SDP: webrtc.SessionDescription{Type: webrtc.SDPTypeOffer}})
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("fails to answer a description that is not an offer", func(t *testing.T) {
+		server := newWebRTCServer(t)
+
+		res := post(t, server, "/api/sessions/uid/webrtc/offer", WebRTCOffer{SDP: webrtc.SessionDescription{Type: webrtc.SDPTypeAnswer}})
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("fails to add a candidate without a peer connection", func(t *testing.T) {
+		server := newWebRTCServer(t)
+
+		res := post(t, server, "/api/sessions/uid/webrtc/ice", webrtc.ICECandidateInit{Candidate: "candidate"})
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("succeeds delivering the bytes through the data channel", func(t *testing.T) {
+		server := newWebRTCServer(t)
+
+		engine := webrtc.SettingEngine{}
+		engine.SetIncludeLoopbackCandidate(true)
+
+		client, err := webrtc.NewAPI(webrtc.WithSettingEngine(engine)).NewPeerConnection(webrtc.Configuration{})
+		require.NoError(t, err)
+		t.Cleanup(func() { client.Close() })
+
+		// NOTICE: the client's candidates are trickled through the ICE route once the offer is answered, as the server
+		// only knows the peer connection from then.
+		candidates := make(chan webrtc.ICECandidateInit, 16)
+		client.OnICECandidate(func(candidate *webrtc.ICECandidate) {
+			if candidate != nil {
+				candidates <- candidate.ToJSON()
+			}
+		})
+
+		channel, err := client.CreateDataChannel(WebRTCDataChannelLabel, nil)
+		require.NoError(t, err)
+
+		received := make(chan []byte, 1)
+		channel.OnMessage(func(message webrtc.DataChannelMessage) {
+			received <- message.Data
+		})
+
+		channel.OnOpen(func() {
+			input, _ := json.Marshal(Message{Kind: messageKindInput, Data: []byte("echo")})
+			channel.Send(input) //nolint:errcheck
+		})
+
+		offer, err := client.CreateOffer(nil)
+		require.NoError(t, err)
+		require.NoError(t, client.SetLocalDescription(offer))
+
+		res := post(t, server, "/api/sessions/uid/webrtc/offer", WebRTCOffer{SDP: *client.LocalDescription(), Cols: 80, Rows: 24})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var answer webrtc.SessionDescription
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&answer))
+		require.NoError(t, client.SetRemoteDescription(answer))
+
+		res = post(t, server, "/api/sessions/uid/webrtc/offer", WebRTCOffer{SDP: *client.LocalDescription()})
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case candidate := <-candidates:
+				res := post(t, server, "/api/sessions/uid/webrtc/ice", candidate)
+				assert.Equal(t, http.StatusNoContent, res.StatusCode)
+			case data := <-received:
+				assert.Equal(t, []byte("echo"), data)
+
+				return
+			case <-timeout:
+				t.Fatal("timed out waiting for the bytes through the data channel")
+			}
+		}
+	})
+}
@@ -273,6 +273,139 @@ func TestSSH(t *testing.T) {
 				require.Error(t, err)
 			},
 		},
+		{
+			name: "fail to authenticate when a firewall rule denies the connection",
+			run: func(t *testing.T, environment *Environment, device *models.Device) {
+				ctx := context.Background()
+
+				// NOTICE: the rule only matches this test's device, as the rules are kept for the next tests.
+				rules := requests.FirewallRuleBulkCreate{
+					Mode: "fail_on_conflict",
+					Rules: []requests.FirewallRuleCreate{{FirewallRuleFields: models.FirewallRuleFields{
+						Priority: 1,
+						Action:   models.FirewallPolicyDeny,
+						Active:   true,
+						SourceIP: ".*",
+						Username: ".*",
+						Filter:   models.FirewallFilter{Hostname: "^" + device.Name + "$"},
+					}}},
+				}
+
+				resp, err := environment.services.R(ctx).
+					SetBody(&rules).
+					Post(fmt.Sprintf("/api/namespaces/%s/firewall/bulk", ShellHubNamespace))
+				require.Equal(t, 200, resp.StatusCode())
+				require.NoError(t, err)
+
+				privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+				require.NoError(t, err)
+
+				publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+				require.NoError(t, err)
+
+				resp, err = environment.services.R(ctx).
+					SetBody(&requests.PublicKeyCreate{
+						Name:     "firewall",
+						Username: ".*",
+						Data:     ssh.MarshalAuthorizedKey(publicKey),
+						Filter:   requests.PublicKeyFilter{Hostname: ".*"},
+					}).
+					Post("/api/sshkeys/public-keys")
+				require.Equal(t, 200, resp.StatusCode())
+				require.NoError(t, err)
+
+				signer, err := ssh.NewSignerFromKey(privateKey)
+				require.NoError(t, err)
+
+				for _, method := range []ssh.AuthMethod{ssh.Password(ShellHubAgentPassword), ssh.PublicKeys(signer)} {
+					config := &ssh.ClientConfig{
+						User:            fmt.Sprintf("%s@%s.%s", ShellHubAgentUsername, ShellHubNamespaceName, device.Name),
+						Auth:            []ssh.AuthMethod{method},
+						HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+					}
+
+					_, err = ssh.Dial("tcp", fmt.Sprintf("localhost:%s", environment.services.Env("SHELLHUB_SSH_PORT")), config)
+					require.Error(t, err)
+				}
+			},
+		},
+		{
+			name: "fail to authenticate when the namespace denies the connections by default",
+			run: func(t *testing.T, environment *Environment, device *models.Device) {
+				ctx := context.Background()
+
+				setPolicy := func(policy string) {
+					resp, err := environment.services.R(ctx).
+						SetBody(map[string]string{"default_firewall_policy": policy}).
+						Patch(fmt.Sprintf("/api/namespaces/%s/settings", ShellHubNamespace))
+					require.Equal(t, 200, resp.StatusCode())
+					require.NoError(t, err)
+				}
+
+				setPolicy(models.FirewallPolicyDeny)
+				t.Cleanup(func() {
+					setPolicy(models.FirewallPolicyAllow)
+				})
+
+				config := &ssh.ClientConfig{
+					User: fmt.Sprintf("%s@%s.%s", ShellHubAgentUsername, ShellHubNamespaceName, device.Name),
+					Auth: []ssh.AuthMethod{
+						ssh.Password(ShellHubAgentPassword),
+					},
+					HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+				}
+
+				_, err := ssh.Dial("tcp", fmt.Sprintf("localhost:%s", environment.services.Env("SHELLHUB_SSH_PORT")), config)
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "fail to authenticate when a firewall rule denies the connections to a device group",
+			run: func(t *testing.T, environment *Environment, device *models.Device) {
+				ctx := context.Background()
+
+				group := models.DeviceGroup{}
+
+				resp, err := environment.services.R(ctx).
+					SetBody(&requests.DeviceGroupCreate{Name: "firewall-" + device.Name, Devices: []string{device.UID}}).
+					SetResult(&group).
+					Post("/api/device-groups")
+				require.Equal(t, 200, resp.StatusCode())
+				require.NoError(t, err)
+
+				// NOTICE: the rule matches any hostname, but only the group's members, so the next tests' devices
+				// aren't denied.
+				rules := requests.FirewallRuleBulkCreate{
+					Mode: "fail_on_conflict",
+					Rules: []requests.FirewallRuleCreate{{FirewallRuleFields: models.FirewallRuleFields{
+						Priority:       2,
+						Action:         models.FirewallPolicyDeny,
+						Active:         true,
+						SourceIP:       ".*",
+						Username:       ".*",
+						Filter:         models.FirewallFilter{Hostname: ".*"},
+						DeviceGroupUID: group.UID,
+					}}},
+				}
+
+				resp, err = environment.services.R(ctx).
+					SetBody(&rules).
+					Post(fmt.Sprintf("/api/namespaces/%s/firewall/bulk", ShellHubNamespace))
+				require.Equal(t, 200, resp.StatusCode())
+				require.NoError(t, err)
+
+				config := &ssh.ClientConfig{
+					User: fmt.Sprintf("%s@%s.%s", ShellHubAgentUsername, ShellHubNamespaceName, device.Name),
+					Auth: []ssh.AuthMethod{
+						ssh.Password(ShellHubAgentPassword),
+					},
+					HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+				}
+
+				_, err = ssh.Dial("tcp", fmt.Sprintf("localhost:%s", environment.services.Env("SHELLHUB_SSH_PORT")), config)
+				require.Error(t, err)
+			},
+		},
 		{
 			name: "connection SHELL with Pty",
 			run: func(t *testing.T, environment *Environment, device *models.Device) {